@@ -0,0 +1,106 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// AcuseMensajeDAO maneja las operaciones de base de datos para los acuses de entrega y
+// lectura de los mensajes
+type AcuseMensajeDAO struct{}
+
+// NuevoAcuseMensajeDAO crea una nueva instancia de AcuseMensajeDAO
+func NuevoAcuseMensajeDAO() *AcuseMensajeDAO {
+	return &AcuseMensajeDAO{}
+}
+
+// Guardar crea o sustituye el acuse de un destinatario de un mensaje
+func (dao *AcuseMensajeDAO) Guardar(ctx context.Context, q Querier, acuse *model.AcuseMensaje) error {
+	query := `REPLACE INTO acuse_mensaje (mensaje_id, usuario_id, estado, actualizado_at)
+              VALUES (?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		acuse.MensajeID().String(),
+		acuse.UsuarioID().String(),
+		string(acuse.Estado()),
+		acuse.ActualizadoAt(),
+	)
+
+	return err
+}
+
+// BuscarPorClave recupera el acuse de un destinatario de un mensaje. Devuelve nil si no
+// existe.
+func (dao *AcuseMensajeDAO) BuscarPorClave(ctx context.Context, q Querier, mensajeID, usuarioID uuid.UUID) (*model.AcuseMensaje, error) {
+	query := `SELECT mensaje_id, usuario_id, estado, actualizado_at
+              FROM acuse_mensaje
+              WHERE mensaje_id = ? AND usuario_id = ?`
+
+	rows, err := q.QueryContext(ctx, query, mensajeID.String(), usuarioID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	acuses, err := dao.escanearAcuses(rows)
+	if err != nil || len(acuses) == 0 {
+		return nil, err
+	}
+	return acuses[0], nil
+}
+
+// BuscarPorMensajeID recupera los acuses de todos los destinatarios de un mensaje
+func (dao *AcuseMensajeDAO) BuscarPorMensajeID(ctx context.Context, q Querier, mensajeID uuid.UUID) ([]*model.AcuseMensaje, error) {
+	query := `SELECT mensaje_id, usuario_id, estado, actualizado_at
+              FROM acuse_mensaje
+              WHERE mensaje_id = ?
+              ORDER BY usuario_id`
+
+	rows, err := q.QueryContext(ctx, query, mensajeID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dao.escanearAcuses(rows)
+}
+
+// escanearAcuses convierte las filas leídas en acuses
+func (dao *AcuseMensajeDAO) escanearAcuses(rows *sql.Rows) ([]*model.AcuseMensaje, error) {
+	var acuses []*model.AcuseMensaje
+	for rows.Next() {
+		var (
+			mensajeIDStr, usuarioIDStr, estado string
+			actualizadoAt                      time.Time
+		)
+		if err := rows.Scan(&mensajeIDStr, &usuarioIDStr, &estado, &actualizadoAt); err != nil {
+			return nil, err
+		}
+
+		mensajeID, err := uuid.Parse(mensajeIDStr)
+		if err != nil {
+			return nil, err
+		}
+		usuarioID, err := uuid.Parse(usuarioIDStr)
+		if err != nil {
+			return nil, err
+		}
+
+		acuse, err := model.NewAcuseMensaje(mensajeID, usuarioID, model.EstadoEntrega(estado), actualizadoAt)
+		if err != nil {
+			return nil, err
+		}
+		acuses = append(acuses, acuse)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return acuses, nil
+}
@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestAcuses_NoLeidosPorMarcador comprueba que los no leídos de cada conversación son
+// los mensajes de otros usuarios posteriores al marcador en orden (timestamp, id), sin
+// contar los eliminados, y que los acuses se sustituyen y se borran con su mensaje
+func TestAcuses_NoLeidosPorMarcador(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	ahora := time.Now().UTC().Truncate(time.Second)
+
+	anaID, betoID := uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{anaID, betoID} {
+		crearUsuarioPrueba(t, dbPool, id)
+	}
+	mensajeDAO := NuevoMensajeDAO()
+	directo := func(id string, remitenteID, destinoID uuid.UUID, ts time.Time) *model.MensajeServidor {
+		t.Helper()
+		m, err := model.NewMensajeDirecto(uuid.MustParse(id), remitenteID, destinoID, "hola", ts, uuid.Nil)
+		require.NoError(t, err)
+		require.NoError(t, mensajeDAO.Crear(ctx, dbPool, m))
+		return m
+	}
+	primero := directo("00000000-0000-0000-0000-000000000001", anaID, betoID, ahora)
+	mismoInstante := directo("00000000-0000-0000-0000-000000000002", anaID, betoID, ahora)
+	directo("00000000-0000-0000-0000-000000000003", anaID, betoID, ahora.Add(time.Second))
+	eliminado := directo("00000000-0000-0000-0000-000000000004", anaID, betoID, ahora.Add(2*time.Second))
+	require.NoError(t, eliminado.Eliminar(ahora.Add(time.Minute)))
+	require.NoError(t, mensajeDAO.Actualizar(ctx, dbPool, eliminado))
+	directo("00000000-0000-0000-0000-000000000005", betoID, anaID, ahora.Add(3*time.Second))
+
+	marcadorDAO := NuevoMarcadorLecturaDAO()
+	noLeidos, err := marcadorDAO.ContarNoLeidos(ctx, dbPool, betoID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{anaID: 3}, noLeidos)
+
+	// El marcador en el primero deja sin leer el del mismo instante con id mayor
+	marcador, err := model.NewMarcadorLectura(betoID, anaID, primero, ahora)
+	require.NoError(t, err)
+	require.NoError(t, marcadorDAO.Guardar(ctx, dbPool, marcador))
+	noLeidos, err = marcadorDAO.ContarNoLeidos(ctx, dbPool, betoID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{anaID: 2}, noLeidos)
+
+	_, err = marcador.Avanzar(mismoInstante, ahora.Add(time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, marcadorDAO.Guardar(ctx, dbPool, marcador))
+	noLeidos, err = marcadorDAO.ContarNoLeidos(ctx, dbPool, betoID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{anaID: 1}, noLeidos)
+	leido, err := marcadorDAO.BuscarPorClave(ctx, dbPool, betoID, anaID)
+	require.NoError(t, err)
+	require.NotNil(t, leido)
+	assert.Equal(t, mismoInstante.ID(), leido.MensajeID())
+	assert.True(t, leido.Timestamp().Equal(ahora))
+
+	// Ana solo tiene sin leer el mensaje de Beto
+	noLeidos, err = marcadorDAO.ContarNoLeidos(ctx, dbPool, anaID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{betoID: 1}, noLeidos)
+	sinMarcador, err := marcadorDAO.BuscarPorClave(ctx, dbPool, anaID, betoID)
+	require.NoError(t, err)
+	assert.Nil(t, sinMarcador)
+
+	acuseDAO := NuevoAcuseMensajeDAO()
+	for _, estado := range []model.EstadoEntrega{model.EntregaEntregado, model.EntregaLeido} {
+		acuse, err := model.NewAcuseMensaje(primero.ID(), betoID, estado, ahora)
+		require.NoError(t, err)
+		require.NoError(t, acuseDAO.Guardar(ctx, dbPool, acuse))
+	}
+	acuses, err := acuseDAO.BuscarPorMensajeID(ctx, dbPool, primero.ID())
+	require.NoError(t, err)
+	require.Len(t, acuses, 1)
+	assert.Equal(t, model.EntregaLeido, acuses[0].Estado())
+
+	// Los acuses se borran con su mensaje; el marcador se conserva
+	require.NoError(t, mensajeDAO.Eliminar(ctx, dbPool, primero.ID()))
+	acuses, err = acuseDAO.BuscarPorMensajeID(ctx, dbPool, primero.ID())
+	require.NoError(t, err)
+	assert.Empty(t, acuses)
+	leido, err = marcadorDAO.BuscarPorClave(ctx, dbPool, betoID, anaID)
+	require.NoError(t, err)
+	assert.NotNil(t, leido)
+}
@@ -1,32 +1,29 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 	"model"
-	"pool"
 )
 
 // ArchivoDAO maneja las operaciones de base de datos para archivos
-type ArchivoDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type ArchivoDAO struct{}
 
 // NuevoArchivoDAO crea una nueva instancia de ArchivoDAO
-func NuevoArchivoDAO(dbPool *pool.DBConnectionPool) *ArchivoDAO {
-	return &ArchivoDAO{
-		dbPool: dbPool,
-	}
+func NuevoArchivoDAO() *ArchivoDAO {
+	return &ArchivoDAO{}
 }
 
 // Crear inserta un nuevo archivo en la base de datos
-func (dao *ArchivoDAO) Crear(archivo *model.ArchivoMetadata) error {
-	query := `INSERT INTO archivos (id, nombre_original, tamano_bytes, ruta, subido_por, fecha_subida) 
+func (dao *ArchivoDAO) Crear(ctx context.Context, q Querier, archivo *model.ArchivoMetadata) error {
+	query := `INSERT INTO archivo_metadata (id, nombre_original, tamano_bytes, ruta_almacen, subido_por, fecha_subida) 
               VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		archivo.ID().String(),
 		archivo.NombreOriginal(),
@@ -40,9 +37,9 @@ func (dao *ArchivoDAO) Crear(archivo *model.ArchivoMetadata) error {
 }
 
 // GetByID busca un archivo por su ID
-func (dao *ArchivoDAO) GetByID(id uuid.UUID) (*model.ArchivoMetadata, error) {
-	query := `SELECT id, nombre_original, tamano_bytes, ruta, subido_por, fecha_subida 
-              FROM archivos 
+func (dao *ArchivoDAO) GetByID(ctx context.Context, q Querier, id uuid.UUID) (*model.ArchivoMetadata, error) {
+	query := `SELECT id, nombre_original, tamano_bytes, ruta_almacen, subido_por, fecha_subida 
+              FROM archivo_metadata 
               WHERE id = ?`
 
 	var idStr, subidoPorStr string
@@ -50,7 +47,7 @@ func (dao *ArchivoDAO) GetByID(id uuid.UUID) (*model.ArchivoMetadata, error) {
 	var tamanoBytes int64
 	var fechaSubida time.Time
 
-	err := dao.dbPool.DB().QueryRow(query, id.String()).Scan(
+	err := q.QueryRowContext(ctx, query, id.String()).Scan(
 		&idStr,
 		&nombreOriginal,
 		&tamanoBytes,
@@ -87,12 +84,13 @@ func (dao *ArchivoDAO) GetByID(id uuid.UUID) (*model.ArchivoMetadata, error) {
 }
 
 // Update actualiza un archivo existente en la base de datos
-func (dao *ArchivoDAO) Update(archivo *model.ArchivoMetadata) error {
-	query := `UPDATE archivos 
-              SET nombre_original = ?, tamano_bytes = ?, ruta = ?, subido_por = ?, fecha_subida = ? 
+func (dao *ArchivoDAO) Update(ctx context.Context, q Querier, archivo *model.ArchivoMetadata) error {
+	query := `UPDATE archivo_metadata 
+              SET nombre_original = ?, tamano_bytes = ?, ruta_almacen = ?, subido_por = ?, fecha_subida = ? 
               WHERE id = ?`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		archivo.NombreOriginal(),
 		archivo.TamanoBytes(),
@@ -106,20 +104,20 @@ func (dao *ArchivoDAO) Update(archivo *model.ArchivoMetadata) error {
 }
 
 // Delete elimina un archivo por su ID
-func (dao *ArchivoDAO) Delete(id uuid.UUID) error {
-	query := `DELETE FROM archivos WHERE id = ?`
+func (dao *ArchivoDAO) Delete(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM archivo_metadata WHERE id = ?`
 
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
 // GetByUploader obtiene archivos subidos por un usuario específico
-func (dao *ArchivoDAO) GetByUploader(uploaderID uuid.UUID) ([]*model.ArchivoMetadata, error) {
-	query := `SELECT id, nombre_original, tamano_bytes, ruta, subido_por, fecha_subida 
-              FROM archivos 
+func (dao *ArchivoDAO) GetByUploader(ctx context.Context, q Querier, uploaderID uuid.UUID) ([]*model.ArchivoMetadata, error) {
+	query := `SELECT id, nombre_original, tamano_bytes, ruta_almacen, subido_por, fecha_subida 
+              FROM archivo_metadata 
               WHERE subido_por = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, uploaderID.String())
+	rows, err := q.QueryContext(ctx, query, uploaderID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -1,60 +1,62 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	"github.com/google/uuid"
 	"model"
-	"pool"
 )
 
 // CanalDAO maneja las operaciones de base de datos para entidades CanalServidor
-type CanalDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type CanalDAO struct{}
 
 // NuevoCanalDAO crea una nueva instancia de CanalDAO
-func NuevoCanalDAO(dbPool *pool.DBConnectionPool) *CanalDAO {
-	return &CanalDAO{dbPool: dbPool}
+func NuevoCanalDAO() *CanalDAO {
+	return &CanalDAO{}
 }
 
 // Crear guarda un nuevo canal en la base de datos
-func (dao *CanalDAO) Crear(canal *model.CanalServidor) error {
-	query := `INSERT INTO canales (id, nombre, descripcion, tipo)
-              VALUES (?, ?, ?, ?)`
+func (dao *CanalDAO) Crear(ctx context.Context, q Querier, canal *model.CanalServidor) error {
+	query := `INSERT INTO canal_servidor (id, nombre, descripcion, tipo, version_hlc)
+              VALUES (?, ?, ?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		canal.ID().String(),
 		canal.Nombre(),
 		canal.Descripcion(),
 		string(canal.Tipo()), // Convertimos CanalTipo a string
+		nullableHLC(canal.Version()),
 	)
 
 	return err
 }
 
 // BuscarPorID recupera un canal por su ID
-func (dao *CanalDAO) BuscarPorID(id uuid.UUID) (*model.CanalServidor, error) {
-	query := `SELECT id, nombre, descripcion, tipo
-              FROM canales WHERE id = ?`
+func (dao *CanalDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.CanalServidor, error) {
+	query := `SELECT id, nombre, descripcion, tipo, version_hlc
+              FROM canal_servidor WHERE id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearCanal(row)
 }
 
 // Actualizar actualiza un canal existente en la base de datos
-func (dao *CanalDAO) Actualizar(canal *model.CanalServidor) error {
-	query := `UPDATE canales 
-              SET nombre = ?, descripcion = ?, tipo = ?
+func (dao *CanalDAO) Actualizar(ctx context.Context, q Querier, canal *model.CanalServidor) error {
+	query := `UPDATE canal_servidor 
+              SET nombre = ?, descripcion = ?, tipo = ?, version_hlc = ?
               WHERE id = ?`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		canal.Nombre(),
 		canal.Descripcion(),
 		string(canal.Tipo()), // Convertimos CanalTipo a string
+		nullableHLC(canal.Version()),
 		canal.ID().String(),
 	)
 
@@ -62,9 +64,9 @@ func (dao *CanalDAO) Actualizar(canal *model.CanalServidor) error {
 }
 
 // Eliminar elimina un canal de la base de datos
-func (dao *CanalDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM canales WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *CanalDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM canal_servidor WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
@@ -72,9 +74,10 @@ func (dao *CanalDAO) Eliminar(id uuid.UUID) error {
 func (dao *CanalDAO) escanearCanal(row *sql.Row) (*model.CanalServidor, error) {
 	var (
 		idStr, nombre, descripcion, tipoStr string
+		versionStr                          sql.NullString
 	)
 
-	if err := row.Scan(&idStr, &nombre, &descripcion, &tipoStr); err != nil {
+	if err := row.Scan(&idStr, &nombre, &descripcion, &tipoStr, &versionStr); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Canal no encontrado
 		}
@@ -98,15 +101,15 @@ func (dao *CanalDAO) escanearCanal(row *sql.Row) (*model.CanalServidor, error) {
 		return nil, fmt.Errorf("tipo de canal desconocido: %s", tipoStr)
 	}
 
-	return model.NewCanalServidor(parsedID, nombre, descripcion, tipo)
+	return construirCanalVersionado(parsedID, nombre, descripcion, tipo, versionStr)
 }
 
 // BuscarTodos recupera todos los canales de la base de datos
-func (dao *CanalDAO) BuscarTodos() ([]*model.CanalServidor, error) {
-	query := `SELECT id, nombre, descripcion, tipo 
-              FROM canales`
+func (dao *CanalDAO) BuscarTodos(ctx context.Context, q Querier) ([]*model.CanalServidor, error) {
+	query := `SELECT id, nombre, descripcion, tipo, version_hlc
+              FROM canal_servidor`
 
-	rows, err := dao.dbPool.DB().Query(query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -132,9 +135,10 @@ func (dao *CanalDAO) BuscarTodos() ([]*model.CanalServidor, error) {
 func (dao *CanalDAO) escanearFilaCanal(rows *sql.Rows) (*model.CanalServidor, error) {
 	var (
 		idStr, nombre, descripcion, tipoStr string
+		versionStr                          sql.NullString
 	)
 
-	if err := rows.Scan(&idStr, &nombre, &descripcion, &tipoStr); err != nil {
+	if err := rows.Scan(&idStr, &nombre, &descripcion, &tipoStr, &versionStr); err != nil {
 		return nil, err
 	}
 
@@ -154,5 +158,21 @@ func (dao *CanalDAO) escanearFilaCanal(rows *sql.Rows) (*model.CanalServidor, er
 		return nil, fmt.Errorf("tipo de canal desconocido: %s", tipoStr)
 	}
 
-	return model.NewCanalServidor(parsedID, nombre, descripcion, tipo)
+	return construirCanalVersionado(parsedID, nombre, descripcion, tipo, versionStr)
+}
+
+// construirCanalVersionado crea el CanalServidor y le asigna la versión HLC leída
+func construirCanalVersionado(id uuid.UUID, nombre, descripcion string, tipo model.CanalTipo, versionStr sql.NullString) (*model.CanalServidor, error) {
+	canal, err := model.NewCanalServidor(id, nombre, descripcion, tipo)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := parseNullableHLC(versionStr)
+	if err != nil {
+		return nil, err
+	}
+	canal.SetVersion(version)
+
+	return canal, nil
 }
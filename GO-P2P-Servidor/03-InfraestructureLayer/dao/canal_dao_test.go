@@ -13,9 +13,10 @@ func TestCanalDAO_Crear(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 
 	// Datos de prueba
 	id := uuid.New()
@@ -30,13 +31,13 @@ func TestCanalDAO_Crear(t *testing.T) {
 	}
 
 	// Ejecutar operación
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Verificar que se creó correctamente consultándolo
-	canalObtenido, err := canalDAO.BuscarPorID(id)
+	canalObtenido, err := canalDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error consultando canal creado: %v", err)
 	}
@@ -65,7 +66,7 @@ func TestCanalDAO_Crear(t *testing.T) {
 	// Limpiar después de la prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -78,9 +79,10 @@ func TestCanalDAO_BuscarPorID(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 
 	// Crear canal de prueba
 	id := uuid.New()
@@ -95,13 +97,13 @@ func TestCanalDAO_BuscarPorID(t *testing.T) {
 	}
 
 	// Guardarlo en la base de datos
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Ejecutar operación que estamos probando
-	canalObtenido, err := canalDAO.BuscarPorID(id)
+	canalObtenido, err := canalDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error en BuscarPorID: %v", err)
 	}
@@ -131,7 +133,7 @@ func TestCanalDAO_BuscarPorID(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -144,9 +146,10 @@ func TestCanalDAO_BuscarTodos(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 
 	// Crear canales de prueba
 	id1 := uuid.New()
@@ -163,18 +166,18 @@ func TestCanalDAO_BuscarTodos(t *testing.T) {
 	}
 
 	// Guardar canales en la base de datos
-	err = canalDAO.Crear(canal1)
+	err = canalDAO.Crear(ctx, dbPool, canal1)
 	if err != nil {
 		t.Fatalf("Error creando canal 1: %v", err)
 	}
 
-	err = canalDAO.Crear(canal2)
+	err = canalDAO.Crear(ctx, dbPool, canal2)
 	if err != nil {
 		t.Fatalf("Error creando canal 2: %v", err)
 	}
 
 	// Ejecutar operación
-	canales, err := canalDAO.BuscarTodos()
+	canales, err := canalDAO.BuscarTodos(ctx, dbPool)
 	if err != nil {
 		t.Fatalf("Error en BuscarTodos: %v", err)
 	}
@@ -214,7 +217,7 @@ func TestCanalDAO_BuscarTodos(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id IN (?, ?)",
+		"DELETE FROM canal_servidor WHERE id IN (?, ?)",
 		id1.String(), id2.String(),
 	)
 	if err != nil {
@@ -227,9 +230,10 @@ func TestCanalDAO_Actualizar(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 
 	// Crear canal de prueba
 	id := uuid.New()
@@ -243,7 +247,7 @@ func TestCanalDAO_Actualizar(t *testing.T) {
 	}
 
 	// Guardarlo en la base de datos
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
@@ -259,13 +263,13 @@ func TestCanalDAO_Actualizar(t *testing.T) {
 	}
 
 	// Ejecutar operación de actualización
-	err = canalDAO.Actualizar(canalActualizado)
+	err = canalDAO.Actualizar(ctx, dbPool, canalActualizado)
 	if err != nil {
 		t.Fatalf("Error actualizando canal: %v", err)
 	}
 
 	// Verificar que se actualizó correctamente
-	canalRecuperado, err := canalDAO.BuscarPorID(id)
+	canalRecuperado, err := canalDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error recuperando canal actualizado: %v", err)
 	}
@@ -289,7 +293,7 @@ func TestCanalDAO_Actualizar(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -302,9 +306,10 @@ func TestCanalDAO_Eliminar(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 
 	// Crear canal de prueba
 	id := uuid.New()
@@ -318,13 +323,13 @@ func TestCanalDAO_Eliminar(t *testing.T) {
 	}
 
 	// Guardarlo en la base de datos
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Verificar que se creó correctamente
-	canalVerificacion, err := canalDAO.BuscarPorID(id)
+	canalVerificacion, err := canalDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error verificando creación de canal: %v", err)
 	}
@@ -333,13 +338,13 @@ func TestCanalDAO_Eliminar(t *testing.T) {
 	}
 
 	// Ejecutar operación de eliminación
-	err = canalDAO.Eliminar(id)
+	err = canalDAO.Eliminar(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error eliminando canal: %v", err)
 	}
 
 	// Verificar que se eliminó correctamente
-	canalEliminado, err := canalDAO.BuscarPorID(id)
+	canalEliminado, err := canalDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error consultando canal eliminado: %v", err)
 	}
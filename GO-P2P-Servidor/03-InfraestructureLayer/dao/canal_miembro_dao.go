@@ -1,55 +1,55 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
-	"pool"
 
 	"github.com/google/uuid"
 	"model"
 )
 
 // CanalMiembroDAO maneja las operaciones de base de datos para entidades CanalMiembro
-type CanalMiembroDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type CanalMiembroDAO struct{}
 
 // NuevoCanalMiembroDAO crea una nueva instancia de CanalMiembroDAO
-func NuevoCanalMiembroDAO(dbPool *pool.DBConnectionPool) *CanalMiembroDAO {
-	return &CanalMiembroDAO{dbPool: dbPool}
+func NuevoCanalMiembroDAO() *CanalMiembroDAO {
+	return &CanalMiembroDAO{}
 }
 
 // Guardar persiste un miembro de canal en la base de datos
-func (dao *CanalMiembroDAO) Guardar(miembro *model.CanalMiembro) error {
-	query := `INSERT INTO canal_miembros (canal_id, usuario_id, rol)
-              VALUES (?, ?, ?)`
+func (dao *CanalMiembroDAO) Guardar(ctx context.Context, q Querier, miembro *model.CanalMiembro) error {
+	query := `INSERT INTO canal_miembro (canal_id, usuario_id, rol, version_hlc)
+              VALUES (?, ?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		miembro.CanalID().String(),
 		miembro.UsuarioID().String(),
 		miembro.Rol(),
+		nullableHLC(miembro.Version()),
 	)
 
 	return err
 }
 
 // BuscarPorIDs recupera un miembro de canal por su canal_id y usuario_id
-func (dao *CanalMiembroDAO) BuscarPorIDs(canalID, usuarioID uuid.UUID) (*model.CanalMiembro, error) {
-	query := `SELECT canal_id, usuario_id, rol
-              FROM canal_miembros 
+func (dao *CanalMiembroDAO) BuscarPorIDs(ctx context.Context, q Querier, canalID, usuarioID uuid.UUID) (*model.CanalMiembro, error) {
+	query := `SELECT canal_id, usuario_id, rol, version_hlc
+              FROM canal_miembro 
               WHERE canal_id = ? AND usuario_id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, canalID.String(), usuarioID.String())
+	row := q.QueryRowContext(ctx, query, canalID.String(), usuarioID.String())
 	return dao.escanearCanalMiembro(row)
 }
 
 // BuscarPorCanalID recupera todos los miembros de un canal específico
-func (dao *CanalMiembroDAO) BuscarPorCanalID(canalID uuid.UUID) ([]*model.CanalMiembro, error) {
-	query := `SELECT canal_id, usuario_id, rol
-              FROM canal_miembros 
+func (dao *CanalMiembroDAO) BuscarPorCanalID(ctx context.Context, q Querier, canalID uuid.UUID) ([]*model.CanalMiembro, error) {
+	query := `SELECT canal_id, usuario_id, rol, version_hlc
+              FROM canal_miembro 
               WHERE canal_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, canalID.String())
+	rows, err := q.QueryContext(ctx, query, canalID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -59,12 +59,12 @@ func (dao *CanalMiembroDAO) BuscarPorCanalID(canalID uuid.UUID) ([]*model.CanalM
 }
 
 // BuscarPorUsuarioID recupera todos los canales a los que pertenece un usuario
-func (dao *CanalMiembroDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*model.CanalMiembro, error) {
-	query := `SELECT canal_id, usuario_id, rol
-              FROM canal_miembros 
+func (dao *CanalMiembroDAO) BuscarPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID) ([]*model.CanalMiembro, error) {
+	query := `SELECT canal_id, usuario_id, rol, version_hlc
+              FROM canal_miembro 
               WHERE usuario_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, usuarioID.String())
+	rows, err := q.QueryContext(ctx, query, usuarioID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -74,14 +74,16 @@ func (dao *CanalMiembroDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*model.Ca
 }
 
 // Actualizar actualiza el rol de un miembro de canal
-func (dao *CanalMiembroDAO) Actualizar(miembro *model.CanalMiembro) error {
-	query := `UPDATE canal_miembros 
-              SET rol = ?
+func (dao *CanalMiembroDAO) Actualizar(ctx context.Context, q Querier, miembro *model.CanalMiembro) error {
+	query := `UPDATE canal_miembro 
+              SET rol = ?, version_hlc = ?
               WHERE canal_id = ? AND usuario_id = ?`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		miembro.Rol(),
+		nullableHLC(miembro.Version()),
 		miembro.CanalID().String(),
 		miembro.UsuarioID().String(),
 	)
@@ -90,11 +92,11 @@ func (dao *CanalMiembroDAO) Actualizar(miembro *model.CanalMiembro) error {
 }
 
 // Eliminar elimina un miembro de canal
-func (dao *CanalMiembroDAO) Eliminar(canalID, usuarioID uuid.UUID) error {
-	query := `DELETE FROM canal_miembros 
+func (dao *CanalMiembroDAO) Eliminar(ctx context.Context, q Querier, canalID, usuarioID uuid.UUID) error {
+	query := `DELETE FROM canal_miembro 
               WHERE canal_id = ? AND usuario_id = ?`
 
-	_, err := dao.dbPool.DB().Exec(query, canalID.String(), usuarioID.String())
+	_, err := q.ExecContext(ctx, query, canalID.String(), usuarioID.String())
 	return err
 }
 
@@ -104,9 +106,10 @@ func (dao *CanalMiembroDAO) escanearCanalMiembro(row *sql.Row) (*model.CanalMiem
 		canalIDStr   string
 		usuarioIDStr string
 		rol          string
+		versionStr   sql.NullString
 	)
 
-	if err := row.Scan(&canalIDStr, &usuarioIDStr, &rol); err != nil {
+	if err := row.Scan(&canalIDStr, &usuarioIDStr, &rol, &versionStr); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Miembro de canal no encontrado
 		}
@@ -123,7 +126,7 @@ func (dao *CanalMiembroDAO) escanearCanalMiembro(row *sql.Row) (*model.CanalMiem
 		return nil, err
 	}
 
-	return model.NewCanalMiembro(canalID, usuarioID, rol)
+	return construirMiembroVersionado(canalID, usuarioID, rol, versionStr)
 }
 
 // escanearMultiplesCanalMiembros escanea múltiples filas en objetos CanalMiembro
@@ -135,9 +138,10 @@ func (dao *CanalMiembroDAO) escanearMultiplesCanalMiembros(rows *sql.Rows) ([]*m
 			canalIDStr   string
 			usuarioIDStr string
 			rol          string
+			versionStr   sql.NullString
 		)
 
-		if err := rows.Scan(&canalIDStr, &usuarioIDStr, &rol); err != nil {
+		if err := rows.Scan(&canalIDStr, &usuarioIDStr, &rol, &versionStr); err != nil {
 			return nil, err
 		}
 
@@ -151,7 +155,7 @@ func (dao *CanalMiembroDAO) escanearMultiplesCanalMiembros(rows *sql.Rows) ([]*m
 			return nil, err
 		}
 
-		miembro, err := model.NewCanalMiembro(canalID, usuarioID, rol)
+		miembro, err := construirMiembroVersionado(canalID, usuarioID, rol, versionStr)
 		if err != nil {
 			return nil, err
 		}
@@ -165,3 +169,19 @@ func (dao *CanalMiembroDAO) escanearMultiplesCanalMiembros(rows *sql.Rows) ([]*m
 
 	return miembros, nil
 }
+
+// construirMiembroVersionado crea el CanalMiembro y le asigna la versión HLC leída
+func construirMiembroVersionado(canalID, usuarioID uuid.UUID, rol string, versionStr sql.NullString) (*model.CanalMiembro, error) {
+	miembro, err := model.NewCanalMiembro(canalID, usuarioID, rol)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := parseNullableHLC(versionStr)
+	if err != nil {
+		return nil, err
+	}
+	miembro.SetVersion(version)
+
+	return miembro, nil
+}
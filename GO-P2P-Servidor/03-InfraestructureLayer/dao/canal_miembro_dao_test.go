@@ -13,11 +13,13 @@ func TestCanalMiembroDAO_GuardarCanalMiembro(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal primero ya que es necesario para la relación
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	usuarioID := uuid.New() // ID del usuario que será miembro
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 
 	// Crear canal en la base de datos
 	canal, err := model.NewCanalServidor(canalID, "Canal Prueba Miembros", "Canal para probar miembros", model.CanalPublico)
@@ -25,13 +27,13 @@ func TestCanalMiembroDAO_GuardarCanalMiembro(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de miembros
-	canalMiembroDAO := NuevoCanalMiembroDAO(dbPool)
+	canalMiembroDAO := NuevoCanalMiembroDAO()
 
 	// Crear miembro de prueba
 	rol := "ADMIN" // Roles posibles: ADMIN, MODERADOR, MIEMBRO, etc.
@@ -41,13 +43,13 @@ func TestCanalMiembroDAO_GuardarCanalMiembro(t *testing.T) {
 	}
 
 	// Ejecutar operación de guardar
-	err = canalMiembroDAO.Guardar(miembro)
+	err = canalMiembroDAO.Guardar(ctx, dbPool, miembro)
 	if err != nil {
 		t.Fatalf("Error guardando miembro: %v", err)
 	}
 
 	// Verificar que se guardó correctamente
-	miembroRecuperado, err := canalMiembroDAO.BuscarPorIDs(canalID, usuarioID)
+	miembroRecuperado, err := canalMiembroDAO.BuscarPorIDs(ctx, dbPool, canalID, usuarioID)
 	if err != nil {
 		t.Fatalf("Error recuperando miembro: %v", err)
 	}
@@ -71,7 +73,7 @@ func TestCanalMiembroDAO_GuardarCanalMiembro(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canal_miembros WHERE canal_id = ? AND usuario_id = ?",
+		"DELETE FROM canal_miembro WHERE canal_id = ? AND usuario_id = ?",
 		canalID.String(), usuarioID.String(),
 	)
 	if err != nil {
@@ -80,7 +82,7 @@ func TestCanalMiembroDAO_GuardarCanalMiembro(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -93,11 +95,13 @@ func TestCanalMiembroDAO_ObtenerCanalMiembro(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal primero ya que es necesario para la relación
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 
 	// Crear canal en la base de datos
 	canal, err := model.NewCanalServidor(canalID, "Canal para Obtener Miembro", "Canal para probar ObtenerCanalMiembro", model.CanalPrivado)
@@ -105,13 +109,13 @@ func TestCanalMiembroDAO_ObtenerCanalMiembro(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de miembros
-	canalMiembroDAO := NuevoCanalMiembroDAO(dbPool)
+	canalMiembroDAO := NuevoCanalMiembroDAO()
 
 	// Crear y guardar miembro de prueba
 	rol := "MODERADOR"
@@ -120,13 +124,13 @@ func TestCanalMiembroDAO_ObtenerCanalMiembro(t *testing.T) {
 		t.Fatalf("Error creando objeto miembro: %v", err)
 	}
 
-	err = canalMiembroDAO.Guardar(miembro)
+	err = canalMiembroDAO.Guardar(ctx, dbPool, miembro)
 	if err != nil {
 		t.Fatalf("Error guardando miembro: %v", err)
 	}
 
 	// Ejecutar operación a probar
-	miembroObtenido, err := canalMiembroDAO.BuscarPorIDs(canalID, usuarioID)
+	miembroObtenido, err := canalMiembroDAO.BuscarPorIDs(ctx, dbPool, canalID, usuarioID)
 	if err != nil {
 		t.Fatalf("Error en BuscarPorIDs: %v", err)
 	}
@@ -151,7 +155,7 @@ func TestCanalMiembroDAO_ObtenerCanalMiembro(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canal_miembros WHERE canal_id = ? AND usuario_id = ?",
+		"DELETE FROM canal_miembro WHERE canal_id = ? AND usuario_id = ?",
 		canalID.String(), usuarioID.String(),
 	)
 	if err != nil {
@@ -160,7 +164,7 @@ func TestCanalMiembroDAO_ObtenerCanalMiembro(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -173,9 +177,10 @@ func TestCanalMiembroDAO_ObtenerMiembrosCanal(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal para las pruebas
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 
 	canal, err := model.NewCanalServidor(canalID, "Canal para Miembros", "Canal para probar ObtenerMiembrosCanal", model.CanalPublico)
@@ -183,17 +188,19 @@ func TestCanalMiembroDAO_ObtenerMiembrosCanal(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de miembros
-	canalMiembroDAO := NuevoCanalMiembroDAO(dbPool)
+	canalMiembroDAO := NuevoCanalMiembroDAO()
 
 	// Crear varios miembros para el canal
 	usuarioID1 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID1)
 	usuarioID2 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID2)
 
 	miembro1, err := model.NewCanalMiembro(canalID, usuarioID1, "ADMIN")
 	if err != nil {
@@ -206,18 +213,18 @@ func TestCanalMiembroDAO_ObtenerMiembrosCanal(t *testing.T) {
 	}
 
 	// Guardar los miembros
-	err = canalMiembroDAO.Guardar(miembro1)
+	err = canalMiembroDAO.Guardar(ctx, dbPool, miembro1)
 	if err != nil {
 		t.Fatalf("Error guardando miembro 1: %v", err)
 	}
 
-	err = canalMiembroDAO.Guardar(miembro2)
+	err = canalMiembroDAO.Guardar(ctx, dbPool, miembro2)
 	if err != nil {
 		t.Fatalf("Error guardando miembro 2: %v", err)
 	}
 
 	// Ejecutar operación a probar
-	miembros, err := canalMiembroDAO.BuscarPorCanalID(canalID)
+	miembros, err := canalMiembroDAO.BuscarPorCanalID(ctx, dbPool, canalID)
 	if err != nil {
 		t.Fatalf("Error en BuscarPorCanalID: %v", err)
 	}
@@ -255,7 +262,7 @@ func TestCanalMiembroDAO_ObtenerMiembrosCanal(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canal_miembros WHERE canal_id = ?",
+		"DELETE FROM canal_miembro WHERE canal_id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -264,7 +271,7 @@ func TestCanalMiembroDAO_ObtenerMiembrosCanal(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -277,24 +284,26 @@ func TestCanalMiembroDAO_ActualizarRol(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal para las pruebas
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 
 	canal, err := model.NewCanalServidor(canalID, "Canal para ActualizarRol", "Canal para probar la actualización de rol", model.CanalPublico)
 	if err != nil {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de miembros
-	canalMiembroDAO := NuevoCanalMiembroDAO(dbPool)
+	canalMiembroDAO := NuevoCanalMiembroDAO()
 
 	// Crear y guardar miembro con rol inicial
 	rolInicial := "MIEMBRO"
@@ -303,13 +312,13 @@ func TestCanalMiembroDAO_ActualizarRol(t *testing.T) {
 		t.Fatalf("Error creando miembro: %v", err)
 	}
 
-	err = canalMiembroDAO.Guardar(miembro)
+	err = canalMiembroDAO.Guardar(ctx, dbPool, miembro)
 	if err != nil {
 		t.Fatalf("Error guardando miembro: %v", err)
 	}
 
 	// Verificar que se guardó correctamente con el rol inicial
-	miembroInicial, err := canalMiembroDAO.BuscarPorIDs(canalID, usuarioID)
+	miembroInicial, err := canalMiembroDAO.BuscarPorIDs(ctx, dbPool, canalID, usuarioID)
 	if err != nil {
 		t.Fatalf("Error buscando miembro: %v", err)
 	}
@@ -330,13 +339,13 @@ func TestCanalMiembroDAO_ActualizarRol(t *testing.T) {
 	}
 
 	// Ejecutar operación de actualización
-	err = canalMiembroDAO.Actualizar(miembroActualizado)
+	err = canalMiembroDAO.Actualizar(ctx, dbPool, miembroActualizado)
 	if err != nil {
 		t.Fatalf("Error actualizando rol: %v", err)
 	}
 
 	// Verificar que el rol se actualizó correctamente
-	miembroFinal, err := canalMiembroDAO.BuscarPorIDs(canalID, usuarioID)
+	miembroFinal, err := canalMiembroDAO.BuscarPorIDs(ctx, dbPool, canalID, usuarioID)
 	if err != nil {
 		t.Fatalf("Error buscando miembro actualizado: %v", err)
 	}
@@ -352,7 +361,7 @@ func TestCanalMiembroDAO_ActualizarRol(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canal_miembros WHERE canal_id = ? AND usuario_id = ?",
+		"DELETE FROM canal_miembro WHERE canal_id = ? AND usuario_id = ?",
 		canalID.String(), usuarioID.String(),
 	)
 	if err != nil {
@@ -361,7 +370,7 @@ func TestCanalMiembroDAO_ActualizarRol(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -374,24 +383,26 @@ func TestCanalMiembroDAO_EliminarMiembro(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal para las pruebas
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 
 	canal, err := model.NewCanalServidor(canalID, "Canal para EliminarMiembro", "Canal para probar la eliminación de miembros", model.CanalPublico)
 	if err != nil {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de miembros
-	canalMiembroDAO := NuevoCanalMiembroDAO(dbPool)
+	canalMiembroDAO := NuevoCanalMiembroDAO()
 
 	// Crear y guardar miembro 
 	miembro, err := model.NewCanalMiembro(canalID, usuarioID, "MIEMBRO")
@@ -399,13 +410,13 @@ func TestCanalMiembroDAO_EliminarMiembro(t *testing.T) {
 		t.Fatalf("Error creando miembro: %v", err)
 	}
 
-	err = canalMiembroDAO.Guardar(miembro)
+	err = canalMiembroDAO.Guardar(ctx, dbPool, miembro)
 	if err != nil {
 		t.Fatalf("Error guardando miembro: %v", err)
 	}
 
 	// Verificar que se guardó correctamente
-	miembroGuardado, err := canalMiembroDAO.BuscarPorIDs(canalID, usuarioID)
+	miembroGuardado, err := canalMiembroDAO.BuscarPorIDs(ctx, dbPool, canalID, usuarioID)
 	if err != nil {
 		t.Fatalf("Error buscando miembro: %v", err)
 	}
@@ -415,13 +426,13 @@ func TestCanalMiembroDAO_EliminarMiembro(t *testing.T) {
 	}
 
 	// Ejecutar operación de eliminación
-	err = canalMiembroDAO.Eliminar(canalID, usuarioID)
+	err = canalMiembroDAO.Eliminar(ctx, dbPool, canalID, usuarioID)
 	if err != nil {
 		t.Fatalf("Error eliminando miembro: %v", err)
 	}
 
 	// Verificar que se eliminó correctamente
-	miembroEliminado, err := canalMiembroDAO.BuscarPorIDs(canalID, usuarioID)
+	miembroEliminado, err := canalMiembroDAO.BuscarPorIDs(ctx, dbPool, canalID, usuarioID)
 	if err != nil {
 		t.Fatalf("Error buscando miembro eliminado: %v", err)
 	}
@@ -433,7 +444,7 @@ func TestCanalMiembroDAO_EliminarMiembro(t *testing.T) {
 	// Limpiar el canal de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
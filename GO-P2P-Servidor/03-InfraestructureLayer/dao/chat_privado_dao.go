@@ -1,28 +1,27 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
-	"pool"
 
 	"github.com/google/uuid"
 	"model"
 )
 
 // ChatPrivadoDAO maneja las operaciones de base de datos para entidades ChatPrivado
-type ChatPrivadoDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type ChatPrivadoDAO struct{}
 
 // NuevoChatPrivadoDAO crea una nueva instancia de ChatPrivadoDAO
-func NuevoChatPrivadoDAO(dbPool *pool.DBConnectionPool) *ChatPrivadoDAO {
-	return &ChatPrivadoDAO{dbPool: dbPool}
+func NuevoChatPrivadoDAO() *ChatPrivadoDAO {
+	return &ChatPrivadoDAO{}
 }
 
 // Guardar persiste un chat privado en la base de datos
-func (dao *ChatPrivadoDAO) Guardar(chat *model.ChatPrivado) error {
-	query := `INSERT INTO chats_privados (id) VALUES (?)`
+func (dao *ChatPrivadoDAO) Guardar(ctx context.Context, q Querier, chat *model.ChatPrivado) error {
+	query := `INSERT INTO chat_privado (id) VALUES (?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		chat.ID().String(),
 	)
@@ -31,18 +30,18 @@ func (dao *ChatPrivadoDAO) Guardar(chat *model.ChatPrivado) error {
 }
 
 // BuscarPorID recupera un chat privado por su ID
-func (dao *ChatPrivadoDAO) BuscarPorID(id uuid.UUID) (*model.ChatPrivado, error) {
-	query := `SELECT id FROM chats_privados WHERE id = ?`
+func (dao *ChatPrivadoDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.ChatPrivado, error) {
+	query := `SELECT id FROM chat_privado WHERE id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearChatPrivado(row)
 }
 
 // BuscarTodos recupera todos los chats privados
-func (dao *ChatPrivadoDAO) BuscarTodos() ([]*model.ChatPrivado, error) {
-	query := `SELECT id FROM chats_privados`
+func (dao *ChatPrivadoDAO) BuscarTodos(ctx context.Context, q Querier) ([]*model.ChatPrivado, error) {
+	query := `SELECT id FROM chat_privado`
 
-	rows, err := dao.dbPool.DB().Query(query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -52,9 +51,9 @@ func (dao *ChatPrivadoDAO) BuscarTodos() ([]*model.ChatPrivado, error) {
 }
 
 // Eliminar elimina un chat privado
-func (dao *ChatPrivadoDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM chats_privados WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *ChatPrivadoDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM chat_privado WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
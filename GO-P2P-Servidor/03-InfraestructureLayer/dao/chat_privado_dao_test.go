@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
@@ -14,9 +15,10 @@ func TestChatPrivadoDAO_Guardar(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
 
 	// Crear un nuevo chat privado para la prueba
 	chatID := uuid.New()
@@ -24,13 +26,13 @@ func TestChatPrivadoDAO_Guardar(t *testing.T) {
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
 
 	// Verificar que el chat se haya guardado correctamente
-	guardado, err := chatDAO.BuscarPorID(chatID)
+	guardado, err := chatDAO.BuscarPorID(ctx, dbPool, chatID)
 	require.NoError(t, err)
 	assert.NotNil(t, guardado)
 	assert.Equal(t, chatID, guardado.ID())
@@ -41,9 +43,10 @@ func TestChatPrivadoDAO_BuscarPorID(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
 
 	// Crear un nuevo chat privado para la prueba
 	chatID := uuid.New()
@@ -51,11 +54,11 @@ func TestChatPrivadoDAO_BuscarPorID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardarlo primero en la base de datos
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	encontrado, err := chatDAO.BuscarPorID(chatID)
+	encontrado, err := chatDAO.BuscarPorID(ctx, dbPool, chatID)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
@@ -66,7 +69,7 @@ func TestChatPrivadoDAO_BuscarPorID(t *testing.T) {
 
 	// Buscar un chat que no existe
 	idInexistente := uuid.New()
-	noEncontrado, err := chatDAO.BuscarPorID(idInexistente)
+	noEncontrado, err := chatDAO.BuscarPorID(ctx, dbPool, idInexistente)
 
 	// Verificar que no haya errores pero el resultado sea nil
 	assert.NoError(t, err)
@@ -78,9 +81,10 @@ func TestChatPrivadoDAO_BuscarTodos(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
 
 	// Crear varios chats privados para la prueba
 	chat1, err := model.NewChatPrivado(uuid.New())
@@ -93,17 +97,17 @@ func TestChatPrivadoDAO_BuscarTodos(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardarlos en la base de datos
-	err = chatDAO.Guardar(chat1)
+	err = chatDAO.Guardar(ctx, dbPool, chat1)
 	require.NoError(t, err)
 	
-	err = chatDAO.Guardar(chat2)
+	err = chatDAO.Guardar(ctx, dbPool, chat2)
 	require.NoError(t, err)
 	
-	err = chatDAO.Guardar(chat3)
+	err = chatDAO.Guardar(ctx, dbPool, chat3)
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	chats, err := chatDAO.BuscarTodos()
+	chats, err := chatDAO.BuscarTodos(ctx, dbPool)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
@@ -127,9 +131,10 @@ func TestChatPrivadoDAO_Eliminar(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
 
 	// Crear un nuevo chat privado para la prueba
 	chatID := uuid.New()
@@ -137,22 +142,22 @@ func TestChatPrivadoDAO_Eliminar(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardarlo primero en la base de datos
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Verificar que existe antes de eliminar
-	encontrado, err := chatDAO.BuscarPorID(chatID)
+	encontrado, err := chatDAO.BuscarPorID(ctx, dbPool, chatID)
 	require.NoError(t, err)
 	assert.NotNil(t, encontrado)
 
 	// Ejecutar la operación que queremos probar
-	err = chatDAO.Eliminar(chatID)
+	err = chatDAO.Eliminar(ctx, dbPool, chatID)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
 
 	// Verificar que el chat ya no existe
-	eliminado, err := chatDAO.BuscarPorID(chatID)
+	eliminado, err := chatDAO.BuscarPorID(ctx, dbPool, chatID)
 	assert.NoError(t, err)
 	assert.Nil(t, eliminado)
 }
@@ -1,29 +1,28 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
-	"pool"
 
 	"github.com/google/uuid"
 	"model"
 )
 
 // ChatPrivadoUsuarioDAO maneja las operaciones de base de datos para entidades ChatPrivadoUsuario
-type ChatPrivadoUsuarioDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type ChatPrivadoUsuarioDAO struct{}
 
 // NuevoChatPrivadoUsuarioDAO crea una nueva instancia de ChatPrivadoUsuarioDAO
-func NuevoChatPrivadoUsuarioDAO(dbPool *pool.DBConnectionPool) *ChatPrivadoUsuarioDAO {
-	return &ChatPrivadoUsuarioDAO{dbPool: dbPool}
+func NuevoChatPrivadoUsuarioDAO() *ChatPrivadoUsuarioDAO {
+	return &ChatPrivadoUsuarioDAO{}
 }
 
 // Guardar persiste una relación entre chat privado y usuario en la base de datos
-func (dao *ChatPrivadoUsuarioDAO) Guardar(chatUsuario *model.ChatPrivadoUsuario) error {
-	query := `INSERT INTO chat_privado_usuarios (chat_privado_id, usuario_id)
+func (dao *ChatPrivadoUsuarioDAO) Guardar(ctx context.Context, q Querier, chatUsuario *model.ChatPrivadoUsuario) error {
+	query := `INSERT INTO chat_privado_usuario (chat_id, usuario_id)
               VALUES (?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		chatUsuario.ChatPrivadoID().String(),
 		chatUsuario.UsuarioID().String(),
@@ -33,22 +32,22 @@ func (dao *ChatPrivadoUsuarioDAO) Guardar(chatUsuario *model.ChatPrivadoUsuario)
 }
 
 // BuscarPorIDs recupera una relación entre chat privado y usuario por sus IDs
-func (dao *ChatPrivadoUsuarioDAO) BuscarPorIDs(chatPrivadoID, usuarioID uuid.UUID) (*model.ChatPrivadoUsuario, error) {
-	query := `SELECT chat_privado_id, usuario_id
-              FROM chat_privado_usuarios 
-              WHERE chat_privado_id = ? AND usuario_id = ?`
+func (dao *ChatPrivadoUsuarioDAO) BuscarPorIDs(ctx context.Context, q Querier, chatPrivadoID, usuarioID uuid.UUID) (*model.ChatPrivadoUsuario, error) {
+	query := `SELECT chat_id, usuario_id
+              FROM chat_privado_usuario 
+              WHERE chat_id = ? AND usuario_id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, chatPrivadoID.String(), usuarioID.String())
+	row := q.QueryRowContext(ctx, query, chatPrivadoID.String(), usuarioID.String())
 	return dao.escanearChatPrivadoUsuario(row)
 }
 
 // BuscarPorChatPrivadoID recupera todos los usuarios de un chat privado específico
-func (dao *ChatPrivadoUsuarioDAO) BuscarPorChatPrivadoID(chatPrivadoID uuid.UUID) ([]*model.ChatPrivadoUsuario, error) {
-	query := `SELECT chat_privado_id, usuario_id
-              FROM chat_privado_usuarios 
-              WHERE chat_privado_id = ?`
+func (dao *ChatPrivadoUsuarioDAO) BuscarPorChatPrivadoID(ctx context.Context, q Querier, chatPrivadoID uuid.UUID) ([]*model.ChatPrivadoUsuario, error) {
+	query := `SELECT chat_id, usuario_id
+              FROM chat_privado_usuario 
+              WHERE chat_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, chatPrivadoID.String())
+	rows, err := q.QueryContext(ctx, query, chatPrivadoID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -58,12 +57,12 @@ func (dao *ChatPrivadoUsuarioDAO) BuscarPorChatPrivadoID(chatPrivadoID uuid.UUID
 }
 
 // BuscarPorUsuarioID recupera todos los chats privados de un usuario específico
-func (dao *ChatPrivadoUsuarioDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*model.ChatPrivadoUsuario, error) {
-	query := `SELECT chat_privado_id, usuario_id
-              FROM chat_privado_usuarios 
+func (dao *ChatPrivadoUsuarioDAO) BuscarPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID) ([]*model.ChatPrivadoUsuario, error) {
+	query := `SELECT chat_id, usuario_id
+              FROM chat_privado_usuario 
               WHERE usuario_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, usuarioID.String())
+	rows, err := q.QueryContext(ctx, query, usuarioID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -73,15 +72,15 @@ func (dao *ChatPrivadoUsuarioDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*mo
 }
 
 // BuscarChatEntreUsuarios busca un chat privado entre dos usuarios específicos
-func (dao *ChatPrivadoUsuarioDAO) BuscarChatEntreUsuarios(usuarioID1, usuarioID2 uuid.UUID) (*model.ChatPrivado, error) {
+func (dao *ChatPrivadoUsuarioDAO) BuscarChatEntreUsuarios(ctx context.Context, q Querier, usuarioID1, usuarioID2 uuid.UUID) (*model.ChatPrivado, error) {
 	query := `
-		SELECT c1.chat_privado_id
-		FROM chat_privado_usuarios c1
-		JOIN chat_privado_usuarios c2 ON c1.chat_privado_id = c2.chat_privado_id
+		SELECT c1.chat_id
+		FROM chat_privado_usuario c1
+		JOIN chat_privado_usuario c2 ON c1.chat_id = c2.chat_id
 		WHERE c1.usuario_id = ? AND c2.usuario_id = ?
 	`
 
-	row := dao.dbPool.DB().QueryRow(query, usuarioID1.String(), usuarioID2.String())
+	row := q.QueryRowContext(ctx, query, usuarioID1.String(), usuarioID2.String())
 	
 	var chatPrivadoIDStr string
 	if err := row.Scan(&chatPrivadoIDStr); err != nil {
@@ -100,18 +99,18 @@ func (dao *ChatPrivadoUsuarioDAO) BuscarChatEntreUsuarios(usuarioID1, usuarioID2
 }
 
 // Eliminar elimina una relación entre chat privado y usuario
-func (dao *ChatPrivadoUsuarioDAO) Eliminar(chatPrivadoID, usuarioID uuid.UUID) error {
-	query := `DELETE FROM chat_privado_usuarios 
-              WHERE chat_privado_id = ? AND usuario_id = ?`
+func (dao *ChatPrivadoUsuarioDAO) Eliminar(ctx context.Context, q Querier, chatPrivadoID, usuarioID uuid.UUID) error {
+	query := `DELETE FROM chat_privado_usuario 
+              WHERE chat_id = ? AND usuario_id = ?`
 
-	_, err := dao.dbPool.DB().Exec(query, chatPrivadoID.String(), usuarioID.String())
+	_, err := q.ExecContext(ctx, query, chatPrivadoID.String(), usuarioID.String())
 	return err
 }
 
 // EliminarPorChatPrivadoID elimina todas las relaciones de un chat privado específico
-func (dao *ChatPrivadoUsuarioDAO) EliminarPorChatPrivadoID(chatPrivadoID uuid.UUID) error {
-	query := `DELETE FROM chat_privado_usuarios WHERE chat_privado_id = ?`
-	_, err := dao.dbPool.DB().Exec(query, chatPrivadoID.String())
+func (dao *ChatPrivadoUsuarioDAO) EliminarPorChatPrivadoID(ctx context.Context, q Querier, chatPrivadoID uuid.UUID) error {
+	query := `DELETE FROM chat_privado_usuario WHERE chat_id = ?`
+	_, err := q.ExecContext(ctx, query, chatPrivadoID.String())
 	return err
 }
 
@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
@@ -14,10 +15,11 @@ func TestChatPrivadoUsuarioDAO_Guardar(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
-	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
 
 	// Crear un chat privado para la prueba
 	chatID := uuid.New()
@@ -25,22 +27,23 @@ func TestChatPrivadoUsuarioDAO_Guardar(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar el chat privado
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Crear un ChatPrivadoUsuario
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 	chatUsuario, err := model.NewChatPrivadoUsuario(chatID, usuarioID)
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	err = chatUsuarioDAO.Guardar(chatUsuario)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario)
 	
 	// Verificar que no haya errores
 	assert.NoError(t, err)
 
 	// Verificar que se haya guardado correctamente
-	guardado, err := chatUsuarioDAO.BuscarPorIDs(chatID, usuarioID)
+	guardado, err := chatUsuarioDAO.BuscarPorIDs(ctx, dbPool, chatID, usuarioID)
 	require.NoError(t, err)
 	assert.NotNil(t, guardado)
 	assert.Equal(t, chatID, guardado.ChatPrivadoID())
@@ -52,10 +55,11 @@ func TestChatPrivadoUsuarioDAO_BuscarPorIDs(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
-	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
 
 	// Crear un chat privado para la prueba
 	chatID := uuid.New()
@@ -63,18 +67,19 @@ func TestChatPrivadoUsuarioDAO_BuscarPorIDs(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar el chat privado
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Crear y guardar un ChatPrivadoUsuario
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 	chatUsuario, err := model.NewChatPrivadoUsuario(chatID, usuarioID)
 	require.NoError(t, err)
-	err = chatUsuarioDAO.Guardar(chatUsuario)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario)
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	encontrado, err := chatUsuarioDAO.BuscarPorIDs(chatID, usuarioID)
+	encontrado, err := chatUsuarioDAO.BuscarPorIDs(ctx, dbPool, chatID, usuarioID)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
@@ -86,7 +91,7 @@ func TestChatPrivadoUsuarioDAO_BuscarPorIDs(t *testing.T) {
 
 	// Buscar un chat usuario que no existe
 	idInexistente := uuid.New()
-	noEncontrado, err := chatUsuarioDAO.BuscarPorIDs(chatID, idInexistente)
+	noEncontrado, err := chatUsuarioDAO.BuscarPorIDs(ctx, dbPool, chatID, idInexistente)
 
 	// Verificar que no haya errores pero el resultado sea nil
 	assert.NoError(t, err)
@@ -98,10 +103,11 @@ func TestChatPrivadoUsuarioDAO_BuscarPorChatPrivadoID(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
-	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
 
 	// Crear un chat privado para la prueba
 	chatID := uuid.New()
@@ -109,13 +115,16 @@ func TestChatPrivadoUsuarioDAO_BuscarPorChatPrivadoID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar el chat privado
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Crear y guardar varios usuarios para el chat
 	usuarioID1 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID1)
 	usuarioID2 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID2)
 	usuarioID3 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID3)
 
 	chatUsuario1, err := model.NewChatPrivadoUsuario(chatID, usuarioID1)
 	require.NoError(t, err)
@@ -127,17 +136,17 @@ func TestChatPrivadoUsuarioDAO_BuscarPorChatPrivadoID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar los usuarios del chat
-	err = chatUsuarioDAO.Guardar(chatUsuario1)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario1)
 	require.NoError(t, err)
 
-	err = chatUsuarioDAO.Guardar(chatUsuario2)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario2)
 	require.NoError(t, err)
 
-	err = chatUsuarioDAO.Guardar(chatUsuario3)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario3)
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	usuarios, err := chatUsuarioDAO.BuscarPorChatPrivadoID(chatID)
+	usuarios, err := chatUsuarioDAO.BuscarPorChatPrivadoID(ctx, dbPool, chatID)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
@@ -166,10 +175,11 @@ func TestChatPrivadoUsuarioDAO_BuscarPorUsuarioID(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
-	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
 
 	// Crear varios chats privados para la prueba
 	chatID1 := uuid.New()
@@ -186,17 +196,18 @@ func TestChatPrivadoUsuarioDAO_BuscarPorUsuarioID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar los chats privados
-	err = chatDAO.Guardar(chatPrivado1)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado1)
 	require.NoError(t, err)
 
-	err = chatDAO.Guardar(chatPrivado2)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado2)
 	require.NoError(t, err)
 
-	err = chatDAO.Guardar(chatPrivado3)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado3)
 	require.NoError(t, err)
 
 	// Crear un usuario para la prueba
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 
 	// Asociar el usuario a los chats
 	chatUsuario1, err := model.NewChatPrivadoUsuario(chatID1, usuarioID)
@@ -209,17 +220,17 @@ func TestChatPrivadoUsuarioDAO_BuscarPorUsuarioID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar las asociaciones
-	err = chatUsuarioDAO.Guardar(chatUsuario1)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario1)
 	require.NoError(t, err)
 
-	err = chatUsuarioDAO.Guardar(chatUsuario2)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario2)
 	require.NoError(t, err)
 
-	err = chatUsuarioDAO.Guardar(chatUsuario3)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario3)
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	chats, err := chatUsuarioDAO.BuscarPorUsuarioID(usuarioID)
+	chats, err := chatUsuarioDAO.BuscarPorUsuarioID(ctx, dbPool, usuarioID)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
@@ -248,10 +259,11 @@ func TestChatPrivadoUsuarioDAO_BuscarChatEntreUsuarios(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
-	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
 
 	// Crear un chat privado para la prueba
 	chatID := uuid.New()
@@ -259,12 +271,14 @@ func TestChatPrivadoUsuarioDAO_BuscarChatEntreUsuarios(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar el chat privado
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Crear dos usuarios para la prueba
 	usuarioID1 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID1)
 	usuarioID2 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID2)
 
 	// Asociar los usuarios al chat
 	chatUsuario1, err := model.NewChatPrivadoUsuario(chatID, usuarioID1)
@@ -274,14 +288,14 @@ func TestChatPrivadoUsuarioDAO_BuscarChatEntreUsuarios(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar las asociaciones
-	err = chatUsuarioDAO.Guardar(chatUsuario1)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario1)
 	require.NoError(t, err)
 
-	err = chatUsuarioDAO.Guardar(chatUsuario2)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario2)
 	require.NoError(t, err)
 
 	// Ejecutar la operación que queremos probar
-	chatEncontrado, err := chatUsuarioDAO.BuscarChatEntreUsuarios(usuarioID1, usuarioID2)
+	chatEncontrado, err := chatUsuarioDAO.BuscarChatEntreUsuarios(ctx, dbPool, usuarioID1, usuarioID2)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
@@ -292,7 +306,7 @@ func TestChatPrivadoUsuarioDAO_BuscarChatEntreUsuarios(t *testing.T) {
 
 	// Probar con usuarios que no tienen un chat en común
 	usuarioSinChat := uuid.New()
-	chatNoEncontrado, err := chatUsuarioDAO.BuscarChatEntreUsuarios(usuarioID1, usuarioSinChat)
+	chatNoEncontrado, err := chatUsuarioDAO.BuscarChatEntreUsuarios(ctx, dbPool, usuarioID1, usuarioSinChat)
 
 	// Verificar que no haya errores pero no se encontró chat
 	assert.NoError(t, err)
@@ -304,10 +318,11 @@ func TestChatPrivadoUsuarioDAO_Eliminar(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
-	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
 
 	// Crear un chat privado para la prueba
 	chatID := uuid.New()
@@ -315,33 +330,34 @@ func TestChatPrivadoUsuarioDAO_Eliminar(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar el chat privado
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Crear un usuario para la prueba
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 
 	// Asociar el usuario al chat
 	chatUsuario, err := model.NewChatPrivadoUsuario(chatID, usuarioID)
 	require.NoError(t, err)
 
 	// Guardar la asociación
-	err = chatUsuarioDAO.Guardar(chatUsuario)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario)
 	require.NoError(t, err)
 
 	// Verificar que existe antes de eliminar
-	encontrado, err := chatUsuarioDAO.BuscarPorIDs(chatID, usuarioID)
+	encontrado, err := chatUsuarioDAO.BuscarPorIDs(ctx, dbPool, chatID, usuarioID)
 	require.NoError(t, err)
 	assert.NotNil(t, encontrado)
 
 	// Ejecutar la operación que queremos probar
-	err = chatUsuarioDAO.Eliminar(chatID, usuarioID)
+	err = chatUsuarioDAO.Eliminar(ctx, dbPool, chatID, usuarioID)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
 
 	// Verificar que ya no existe la asociación
-	eliminado, err := chatUsuarioDAO.BuscarPorIDs(chatID, usuarioID)
+	eliminado, err := chatUsuarioDAO.BuscarPorIDs(ctx, dbPool, chatID, usuarioID)
 	assert.NoError(t, err)
 	assert.Nil(t, eliminado)
 }
@@ -351,10 +367,11 @@ func TestChatPrivadoUsuarioDAO_EliminarPorChatPrivadoID(t *testing.T) {
 	// Configuración de la prueba
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear el DAO
-	chatDAO := NuevoChatPrivadoDAO(dbPool)
-	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO(dbPool)
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
 
 	// Crear un chat privado para la prueba
 	chatID := uuid.New()
@@ -362,13 +379,16 @@ func TestChatPrivadoUsuarioDAO_EliminarPorChatPrivadoID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar el chat privado
-	err = chatDAO.Guardar(chatPrivado)
+	err = chatDAO.Guardar(ctx, dbPool, chatPrivado)
 	require.NoError(t, err)
 
 	// Crear varios usuarios para el chat
 	usuarioID1 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID1)
 	usuarioID2 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID2)
 	usuarioID3 := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID3)
 
 	// Asociar los usuarios al chat
 	chatUsuario1, err := model.NewChatPrivadoUsuario(chatID, usuarioID1)
@@ -381,28 +401,28 @@ func TestChatPrivadoUsuarioDAO_EliminarPorChatPrivadoID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Guardar las asociaciones
-	err = chatUsuarioDAO.Guardar(chatUsuario1)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario1)
 	require.NoError(t, err)
 
-	err = chatUsuarioDAO.Guardar(chatUsuario2)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario2)
 	require.NoError(t, err)
 
-	err = chatUsuarioDAO.Guardar(chatUsuario3)
+	err = chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario3)
 	require.NoError(t, err)
 
 	// Verificar que existen antes de eliminar
-	usuarios, err := chatUsuarioDAO.BuscarPorChatPrivadoID(chatID)
+	usuarios, err := chatUsuarioDAO.BuscarPorChatPrivadoID(ctx, dbPool, chatID)
 	require.NoError(t, err)
 	assert.Equal(t, 3, len(usuarios))
 
 	// Ejecutar la operación que queremos probar
-	err = chatUsuarioDAO.EliminarPorChatPrivadoID(chatID)
+	err = chatUsuarioDAO.EliminarPorChatPrivadoID(ctx, dbPool, chatID)
 
 	// Verificar que no haya errores
 	assert.NoError(t, err)
 
 	// Verificar que ya no existen las asociaciones
-	usuariosEliminados, err := chatUsuarioDAO.BuscarPorChatPrivadoID(chatID)
+	usuariosEliminados, err := chatUsuarioDAO.BuscarPorChatPrivadoID(ctx, dbPool, chatID)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(usuariosEliminados))
 }
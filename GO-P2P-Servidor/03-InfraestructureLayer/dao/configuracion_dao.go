@@ -1,28 +1,28 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"model"
 )
 
 // ConfigMySQLDAO handles database operations for ConfiguracionServidor entities
-type ConfigMySQLDAO struct {
-	db *sql.DB
-}
+type ConfigMySQLDAO struct{}
 
 // NewConfigMySQLDAO creates a new ConfigMySQLDAO instance
-func NewConfigMySQLDAO(db *sql.DB) *ConfigMySQLDAO {
-	return &ConfigMySQLDAO{db: db}
+func NewConfigMySQLDAO() *ConfigMySQLDAO {
+	return &ConfigMySQLDAO{}
 }
 
 // Save persists a configuration to the database (create or update)
-func (dao *ConfigMySQLDAO) Save(config *model.ConfiguracionServidor) error {
+func (dao *ConfigMySQLDAO) Save(ctx context.Context, q Querier, config *model.ConfiguracionServidor) error {
 	query := `REPLACE INTO configuracion_servidor 
               (max_conexiones, parametros_mysql, rutas_logs)
               VALUES (?, ?, ?)`
 
-	_, err := dao.db.Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		config.MaxConexiones(),
 		config.ParametrosMySQL(),
@@ -33,20 +33,21 @@ func (dao *ConfigMySQLDAO) Save(config *model.ConfiguracionServidor) error {
 }
 
 // GetConfig retrieves the server configuration from the database
-func (dao *ConfigMySQLDAO) GetConfig() (*model.ConfiguracionServidor, error) {
+func (dao *ConfigMySQLDAO) GetConfig(ctx context.Context, q Querier) (*model.ConfiguracionServidor, error) {
 	query := `SELECT max_conexiones, parametros_mysql, rutas_logs 
               FROM configuracion_servidor LIMIT 1`
 
-	row := dao.db.QueryRow(query)
+	row := q.QueryRowContext(ctx, query)
 	return dao.scanConfig(row)
 }
 
 // Update updates an existing configuration
-func (dao *ConfigMySQLDAO) Update(config *model.ConfiguracionServidor) error {
+func (dao *ConfigMySQLDAO) Update(ctx context.Context, q Querier, config *model.ConfiguracionServidor) error {
 	query := `UPDATE configuracion_servidor SET 
               max_conexiones = ?, parametros_mysql = ?, rutas_logs = ?`
 
-	_, err := dao.db.Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		config.MaxConexiones(),
 		config.ParametrosMySQL(),
@@ -0,0 +1,556 @@
+package dao
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+	"pool"
+)
+
+// contrato registra qué métodos de los DAO ejercita la prueba de contrato del esquema
+type contrato struct {
+	t      *testing.T
+	usados map[string]bool
+}
+
+// ok marca el método como ejercitado y exige que no haya devuelto error
+func (c *contrato) ok(dao interface{}, metodo string, err error) {
+	c.t.Helper()
+	nombre := reflect.TypeOf(dao).Elem().Name() + "." + metodo
+	c.usados[nombre] = true
+	require.NoError(c.t, err, nombre)
+}
+
+// sinEjercitar devuelve los métodos exportados de los DAO que la prueba no llamó
+func (c *contrato) sinEjercitar(daos ...interface{}) []string {
+	var faltan []string
+	for _, dao := range daos {
+		tipo := reflect.TypeOf(dao)
+		for i := 0; i < tipo.NumMethod(); i++ {
+			nombre := tipo.Elem().Name() + "." + tipo.Method(i).Name
+			if !c.usados[nombre] {
+				faltan = append(faltan, nombre)
+			}
+		}
+	}
+	sort.Strings(faltan)
+	return faltan
+}
+
+// TestContratoEsquema migra una base de datos vacía y ejecuta todos los métodos de todos
+// los DAO contra ella, de modo que un nombre de tabla o columna que no exista en las
+// migraciones haga fallar la prueba. Con DAO_TEST_DB_CONFIG se ejecuta contra MySQL.
+func TestContratoEsquema(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	c := &contrato{t: t, usados: make(map[string]bool)}
+	ahora := time.Now().UTC().Truncate(time.Second)
+
+	usuarioDAO := NuevoUsuarioDAO()
+	canalDAO := NuevoCanalDAO()
+	miembroDAO := NuevoCanalMiembroDAO()
+	invitacionDAO := NuevoInvitacionCanalDAO()
+	notificacionDAO := NuevoNotificacionDAO()
+	chatDAO := NuevoChatPrivadoDAO()
+	chatUsuarioDAO := NuevoChatPrivadoUsuarioDAO()
+	archivoDAO := NuevoArchivoDAO()
+	mensajeDAO := NuevoMensajeDAO()
+	nodoDAO := NuevoNodoDAO()
+	heartbeatDAO := NewHeartbeatLogMySQLDAO()
+	replicaDAO := NewReplicaEventMySQLDAO()
+	enrutadoDAO := NuevoMensajeEnrutadoDAO()
+	edicionDAO := NuevoEdicionMensajeDAO()
+	reaccionDAO := NuevoReaccionMensajeDAO()
+	acuseDAO := NuevoAcuseMensajeDAO()
+	marcadorDAO := NuevoMarcadorLecturaDAO()
+	pendienteDAO := NuevoEventoPendienteDAO()
+	programadoDAO := NuevoMensajeProgramadoDAO()
+	mencionDAO := NuevoMencionDAO()
+	silencioDAO := NuevoSilencioMencionDAO()
+	fijadoDAO := NuevoFijadoCanalDAO()
+	logDAO := NuevoEntradaLogDAO()
+	configDAO := NewConfigMySQLDAO()
+
+	// Usuarios
+	remitenteID, destinoID := uuid.New(), uuid.New()
+	remitente, err := model.NewUsuarioServidor(remitenteID, "remitente", "remitente@prueba.com", "hash", "", "127.0.0.1", ahora)
+	require.NoError(t, err)
+	c.ok(usuarioDAO, "Crear", usuarioDAO.Crear(ctx, dbPool, remitente))
+	crearUsuarioPrueba(t, dbPool, destinoID)
+
+	usuario, err := usuarioDAO.BuscarPorID(ctx, dbPool, remitenteID)
+	c.ok(usuarioDAO, "BuscarPorID", err)
+	require.NotNil(t, usuario)
+	usuario, err = usuarioDAO.BuscarPorEmail(ctx, dbPool, "remitente@prueba.com")
+	c.ok(usuarioDAO, "BuscarPorEmail", err)
+	require.NotNil(t, usuario)
+	usuario.SetConnected(true)
+	c.ok(usuarioDAO, "Actualizar", usuarioDAO.Actualizar(ctx, dbPool, usuario))
+	usuarios, err := usuarioDAO.BuscarTodos(ctx, dbPool)
+	c.ok(usuarioDAO, "BuscarTodos", err)
+	assert.NotEmpty(t, usuarios)
+
+	// Canales, miembros e invitaciones
+	canalID := uuid.New()
+	canal, err := model.NewCanalServidor(canalID, "contrato", "Canal del contrato", model.CanalPublico)
+	require.NoError(t, err)
+	c.ok(canalDAO, "Crear", canalDAO.Crear(ctx, dbPool, canal))
+	canal, err = canalDAO.BuscarPorID(ctx, dbPool, canalID)
+	c.ok(canalDAO, "BuscarPorID", err)
+	require.NotNil(t, canal)
+	c.ok(canalDAO, "Actualizar", canalDAO.Actualizar(ctx, dbPool, canal))
+	canales, err := canalDAO.BuscarTodos(ctx, dbPool)
+	c.ok(canalDAO, "BuscarTodos", err)
+	assert.NotEmpty(t, canales)
+
+	miembro, err := model.NewCanalMiembro(canalID, remitenteID, "ADMIN")
+	require.NoError(t, err)
+	c.ok(miembroDAO, "Guardar", miembroDAO.Guardar(ctx, dbPool, miembro))
+	miembro, err = miembroDAO.BuscarPorIDs(ctx, dbPool, canalID, remitenteID)
+	c.ok(miembroDAO, "BuscarPorIDs", err)
+	require.NotNil(t, miembro)
+	miembros, err := miembroDAO.BuscarPorCanalID(ctx, dbPool, canalID)
+	c.ok(miembroDAO, "BuscarPorCanalID", err)
+	assert.Len(t, miembros, 1)
+	miembros, err = miembroDAO.BuscarPorUsuarioID(ctx, dbPool, remitenteID)
+	c.ok(miembroDAO, "BuscarPorUsuarioID", err)
+	assert.Len(t, miembros, 1)
+	c.ok(miembroDAO, "Actualizar", miembroDAO.Actualizar(ctx, dbPool, miembro))
+
+	invitacionID := uuid.New()
+	invitacion, err := model.NewInvitacionCanal(invitacionID, canalID, destinoID, model.InvitacionPendiente, ahora)
+	require.NoError(t, err)
+	c.ok(invitacionDAO, "Guardar", invitacionDAO.Guardar(ctx, dbPool, invitacion))
+	invitacion, err = invitacionDAO.BuscarPorID(ctx, dbPool, invitacionID)
+	c.ok(invitacionDAO, "BuscarPorID", err)
+	require.NotNil(t, invitacion)
+	invitaciones, err := invitacionDAO.BuscarPorCanalID(ctx, dbPool, canalID)
+	c.ok(invitacionDAO, "BuscarPorCanalID", err)
+	assert.Len(t, invitaciones, 1)
+	invitaciones, err = invitacionDAO.BuscarPorDestinatarioID(ctx, dbPool, destinoID)
+	c.ok(invitacionDAO, "BuscarPorDestinatarioID", err)
+	assert.Len(t, invitaciones, 1)
+	invitaciones, err = invitacionDAO.BuscarPorEstado(ctx, dbPool, model.InvitacionPendiente)
+	c.ok(invitacionDAO, "BuscarPorEstado", err)
+	assert.Len(t, invitaciones, 1)
+	require.NoError(t, invitacion.CambiarEstado(model.InvitacionAceptada))
+	c.ok(invitacionDAO, "Actualizar", invitacionDAO.Actualizar(ctx, dbPool, invitacion))
+
+	// Notificaciones
+	notificacionID := uuid.New()
+	notificacion, err := model.NewNotificacion(notificacionID, destinoID, "Invitación al canal", ahora, invitacionID)
+	require.NoError(t, err)
+	c.ok(notificacionDAO, "Guardar", notificacionDAO.Guardar(ctx, dbPool, notificacion))
+	notificacion, err = notificacionDAO.BuscarPorID(ctx, dbPool, notificacionID)
+	c.ok(notificacionDAO, "BuscarPorID", err)
+	require.NotNil(t, notificacion)
+	notificaciones, err := notificacionDAO.BuscarPorUsuarioID(ctx, dbPool, destinoID)
+	c.ok(notificacionDAO, "BuscarPorUsuarioID", err)
+	assert.Len(t, notificaciones, 1)
+	paginaNotificaciones, err := notificacionDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, destinoID, model.ConsultaPagina{})
+	c.ok(notificacionDAO, "BuscarPaginaPorUsuarioID", err)
+	assert.Len(t, paginaNotificaciones.Elementos, 1)
+	notificaciones, err = notificacionDAO.BuscarNoLeidas(ctx, dbPool, destinoID)
+	c.ok(notificacionDAO, "BuscarNoLeidas", err)
+	assert.Len(t, notificaciones, 1)
+	notificaciones, err = notificacionDAO.BuscarPorInvitacionID(ctx, dbPool, invitacionID)
+	c.ok(notificacionDAO, "BuscarPorInvitacionID", err)
+	assert.Len(t, notificaciones, 1)
+	c.ok(notificacionDAO, "ActualizarEstadoLeido", notificacionDAO.ActualizarEstadoLeido(ctx, dbPool, notificacionID, true))
+	c.ok(notificacionDAO, "MarcarTodasComoLeidas", notificacionDAO.MarcarTodasComoLeidas(ctx, dbPool, destinoID))
+
+	// Chats privados
+	chatID := uuid.New()
+	chat, err := model.NewChatPrivado(chatID)
+	require.NoError(t, err)
+	c.ok(chatDAO, "Guardar", chatDAO.Guardar(ctx, dbPool, chat))
+	chat, err = chatDAO.BuscarPorID(ctx, dbPool, chatID)
+	c.ok(chatDAO, "BuscarPorID", err)
+	require.NotNil(t, chat)
+	chats, err := chatDAO.BuscarTodos(ctx, dbPool)
+	c.ok(chatDAO, "BuscarTodos", err)
+	assert.Len(t, chats, 1)
+
+	for _, usuarioID := range []uuid.UUID{remitenteID, destinoID} {
+		chatUsuario, err := model.NewChatPrivadoUsuario(chatID, usuarioID)
+		require.NoError(t, err)
+		c.ok(chatUsuarioDAO, "Guardar", chatUsuarioDAO.Guardar(ctx, dbPool, chatUsuario))
+	}
+	chatUsuario, err := chatUsuarioDAO.BuscarPorIDs(ctx, dbPool, chatID, remitenteID)
+	c.ok(chatUsuarioDAO, "BuscarPorIDs", err)
+	require.NotNil(t, chatUsuario)
+	chatUsuarios, err := chatUsuarioDAO.BuscarPorChatPrivadoID(ctx, dbPool, chatID)
+	c.ok(chatUsuarioDAO, "BuscarPorChatPrivadoID", err)
+	assert.Len(t, chatUsuarios, 2)
+	chatUsuarios, err = chatUsuarioDAO.BuscarPorUsuarioID(ctx, dbPool, destinoID)
+	c.ok(chatUsuarioDAO, "BuscarPorUsuarioID", err)
+	assert.Len(t, chatUsuarios, 1)
+	chat, err = chatUsuarioDAO.BuscarChatEntreUsuarios(ctx, dbPool, remitenteID, destinoID)
+	c.ok(chatUsuarioDAO, "BuscarChatEntreUsuarios", err)
+	require.NotNil(t, chat)
+	assert.Equal(t, chatID, chat.ID())
+
+	// Archivos
+	archivoID := uuid.New()
+	archivo, err := model.NewArchivoMetadata(archivoID, "informe.pdf", 2048, "/datos/informe.pdf", remitenteID, ahora)
+	require.NoError(t, err)
+	c.ok(archivoDAO, "Crear", archivoDAO.Crear(ctx, dbPool, archivo))
+	archivo, err = archivoDAO.GetByID(ctx, dbPool, archivoID)
+	c.ok(archivoDAO, "GetByID", err)
+	require.NotNil(t, archivo)
+	c.ok(archivoDAO, "Update", archivoDAO.Update(ctx, dbPool, archivo))
+	archivos, err := archivoDAO.GetByUploader(ctx, dbPool, remitenteID)
+	c.ok(archivoDAO, "GetByUploader", err)
+	assert.Len(t, archivos, 1)
+
+	// Mensajes de los tres destinos: canal, usuario y chat privado
+	mensajeCanal, err := model.NewMensajeCanal(uuid.New(), remitenteID, canalID, "al canal", ahora, uuid.Nil)
+	require.NoError(t, err)
+	mensajeDirecto, err := model.NewMensajeDirecto(uuid.New(), remitenteID, destinoID, "directo", ahora, uuid.Nil)
+	require.NoError(t, err)
+	mensajeChat, err := model.NewMensajeChatPrivado(uuid.New(), remitenteID, chatID, "al chat", ahora, archivoID)
+	require.NoError(t, err)
+	for _, mensaje := range []*model.MensajeServidor{mensajeCanal, mensajeDirecto, mensajeChat} {
+		c.ok(mensajeDAO, "Crear", mensajeDAO.Crear(ctx, dbPool, mensaje))
+	}
+	mensaje, err := mensajeDAO.BuscarPorID(ctx, dbPool, mensajeChat.ID())
+	c.ok(mensajeDAO, "BuscarPorID", err)
+	require.NotNil(t, mensaje)
+	assert.Equal(t, chatID, mensaje.ChatPrivadoID())
+	assert.Equal(t, archivoID, mensaje.ArchivoID())
+	c.ok(mensajeDAO, "Actualizar", mensajeDAO.Actualizar(ctx, dbPool, mensaje))
+	paginaMensajes, err := mensajeDAO.BuscarPorCanalID(ctx, dbPool, canalID, model.ConsultaPagina{})
+	c.ok(mensajeDAO, "BuscarPorCanalID", err)
+	assert.Len(t, paginaMensajes.Elementos, 1)
+	paginaMensajes, err = mensajeDAO.BuscarPorChatPrivadoID(ctx, dbPool, chatID, model.ConsultaPagina{})
+	c.ok(mensajeDAO, "BuscarPorChatPrivadoID", err)
+	assert.Len(t, paginaMensajes.Elementos, 1)
+	paginaMensajes, err = mensajeDAO.BuscarMensajesDirectos(ctx, dbPool, remitenteID, destinoID, model.ConsultaPagina{})
+	c.ok(mensajeDAO, "BuscarMensajesDirectos", err)
+	assert.Len(t, paginaMensajes.Elementos, 1)
+	paginaMensajes, err = mensajeDAO.BuscarPorRemitenteID(ctx, dbPool, remitenteID, model.ConsultaPagina{})
+	c.ok(mensajeDAO, "BuscarPorRemitenteID", err)
+	assert.Len(t, paginaMensajes.Elementos, 3)
+	mensajes, err := mensajeDAO.BuscarPorRangoFechas(ctx, dbPool, ahora.Add(-time.Minute), ahora.Add(time.Minute))
+	c.ok(mensajeDAO, "BuscarPorRangoFechas", err)
+	assert.Len(t, mensajes, 3)
+
+	// Búsqueda de texto: FULLTEXT en MySQL, índice invertido en memoria en SQLite
+	filtroBusqueda := FiltroBusquedaMensajes{
+		UsuarioID: remitenteID,
+		Terminos:  []string{"chat"},
+		Filtro: model.FiltroBusqueda{
+			RemitenteID: remitenteID,
+			Desde:       ahora.Add(-time.Minute),
+			Hasta:       ahora.Add(time.Minute),
+			ConAdjunto:  true,
+		},
+	}
+	if dbPool.Driver() == pool.DriverSQLite {
+		indice := NuevoIndiceMensajes()
+		require.NoError(t, indice.Cargar(ctx, dbPool))
+		filtroBusqueda.Candidatos = indice.Buscar(filtroBusqueda.Terminos)
+	}
+	paginaMensajes, err = mensajeDAO.Buscar(ctx, dbPool, filtroBusqueda, model.ConsultaPagina{})
+	c.ok(mensajeDAO, "Buscar", err)
+	assert.Len(t, paginaMensajes.Elementos, 1)
+
+	// Hilos: una respuesta al mensaje del canal
+	respuesta, err := model.NewMensajeCanal(uuid.New(), destinoID, canalID, "respuesta", ahora, uuid.Nil)
+	require.NoError(t, err)
+	require.NoError(t, respuesta.ResponderA(mensajeCanal))
+	require.NoError(t, mensajeDAO.Crear(ctx, dbPool, respuesta))
+	paginaMensajes, err = mensajeDAO.BuscarRespuestas(ctx, dbPool, mensajeCanal.ID(), model.ConsultaPagina{})
+	c.ok(mensajeDAO, "BuscarRespuestas", err)
+	require.Len(t, paginaMensajes.Elementos, 1)
+	assert.Equal(t, mensajeCanal.ID(), paginaMensajes.Elementos[0].PadreID())
+	paginaHilos, err := mensajeDAO.BuscarHilosPorCanalID(ctx, dbPool, canalID, model.ConsultaPagina{})
+	c.ok(mensajeDAO, "BuscarHilosPorCanalID", err)
+	require.Len(t, paginaHilos.Elementos, 1)
+	assert.Equal(t, 1, paginaHilos.Elementos[0].Respuestas())
+	hilo, err := mensajeDAO.BuscarResumenHilo(ctx, dbPool, mensajeCanal.ID())
+	c.ok(mensajeDAO, "BuscarResumenHilo", err)
+	require.NotNil(t, hilo)
+	assert.True(t, hilo.UltimaRespuesta().Equal(ahora))
+
+	// Reacciones: una vigente y otra retirada
+	for _, emoji := range []string{"👍", "🎉"} {
+		reaccion, err := model.NewReaccionMensaje(mensajeCanal.ID(), remitenteID, emoji, ahora)
+		require.NoError(t, err)
+		c.ok(reaccionDAO, "Guardar", reaccionDAO.Guardar(ctx, dbPool, reaccion))
+	}
+	reaccion, err := reaccionDAO.BuscarPorClave(ctx, dbPool, mensajeCanal.ID(), remitenteID, "🎉")
+	c.ok(reaccionDAO, "BuscarPorClave", err)
+	require.NotNil(t, reaccion)
+	require.NoError(t, reaccion.Retirar(ahora))
+	require.NoError(t, reaccionDAO.Guardar(ctx, dbPool, reaccion))
+	conteos, err := reaccionDAO.ContarPorMensajes(ctx, dbPool, []uuid.UUID{mensajeCanal.ID()})
+	c.ok(reaccionDAO, "ContarPorMensajes", err)
+	assert.Equal(t, []model.ConteoReaccion{{Emoji: "👍", Total: 1}}, conteos[mensajeCanal.ID()])
+	reacciones, err := reaccionDAO.BuscarPorRangoTiempo(ctx, dbPool, ahora.Add(-time.Minute), ahora.Add(time.Minute))
+	c.ok(reaccionDAO, "BuscarPorRangoTiempo", err)
+	assert.Len(t, reacciones, 2)
+
+	// Acuses de entrega y marcadores de lectura
+	acuse, err := model.NewAcuseMensaje(mensajeDirecto.ID(), destinoID, model.EntregaEntregado, ahora)
+	require.NoError(t, err)
+	c.ok(acuseDAO, "Guardar", acuseDAO.Guardar(ctx, dbPool, acuse))
+	acuse, err = acuseDAO.BuscarPorClave(ctx, dbPool, mensajeDirecto.ID(), destinoID)
+	c.ok(acuseDAO, "BuscarPorClave", err)
+	require.NotNil(t, acuse)
+	assert.Equal(t, model.EntregaEntregado, acuse.Estado())
+	acuses, err := acuseDAO.BuscarPorMensajeID(ctx, dbPool, mensajeDirecto.ID())
+	c.ok(acuseDAO, "BuscarPorMensajeID", err)
+	assert.Len(t, acuses, 1)
+	noLeidos, err := marcadorDAO.ContarNoLeidos(ctx, dbPool, destinoID)
+	c.ok(marcadorDAO, "ContarNoLeidos", err)
+	assert.Equal(t, map[uuid.UUID]int{remitenteID: 1, chatID: 1}, noLeidos)
+	marcador, err := model.NewMarcadorLectura(remitenteID, canalID, respuesta, ahora)
+	require.NoError(t, err)
+	c.ok(marcadorDAO, "Guardar", marcadorDAO.Guardar(ctx, dbPool, marcador))
+	marcador, err = marcadorDAO.BuscarPorClave(ctx, dbPool, remitenteID, canalID)
+	c.ok(marcadorDAO, "BuscarPorClave", err)
+	require.NotNil(t, marcador)
+	assert.Equal(t, respuesta.ID(), marcador.MensajeID())
+	noLeidos, err = marcadorDAO.ContarNoLeidos(ctx, dbPool, remitenteID)
+	require.NoError(t, err)
+	assert.Empty(t, noLeidos)
+
+	// Cola de eventos pendientes
+	for i := 0; i < 2; i++ {
+		pendiente, err := model.NewEventoPendiente(uuid.Must(uuid.NewV7()), destinoID, "MENSAJE", []byte(`{"n":1}`), ahora)
+		require.NoError(t, err)
+		c.ok(pendienteDAO, "Crear", pendienteDAO.Crear(ctx, dbPool, pendiente))
+	}
+	pendientes, err := pendienteDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, destinoID, model.ConsultaPagina{})
+	c.ok(pendienteDAO, "BuscarPaginaPorUsuarioID", err)
+	require.Len(t, pendientes.Elementos, 2)
+	assert.JSONEq(t, `{"n":1}`, string(pendientes.Elementos[0].Datos()))
+	recortados, err := pendienteDAO.Recortar(ctx, dbPool, destinoID, 1)
+	c.ok(pendienteDAO, "Recortar", err)
+	assert.EqualValues(t, 1, recortados)
+	borrados, err := pendienteDAO.EliminarHasta(ctx, dbPool, destinoID, pendientes.Elementos[1].Posicion())
+	c.ok(pendienteDAO, "EliminarHasta", err)
+	assert.EqualValues(t, 1, borrados)
+	enCola, err := pendienteDAO.ContarPorUsuarioID(ctx, dbPool, destinoID)
+	c.ok(pendienteDAO, "ContarPorUsuarioID", err)
+	assert.Zero(t, enCola)
+
+	// Mensajes programados y con caducidad
+	nodoProgramadoID := uuid.New()
+	programado, err := model.NewMensajeProgramado(uuid.New(), nodoProgramadoID, remitenteID, uuid.Nil, canalID, "más tarde", uuid.Nil, ahora, time.Minute)
+	require.NoError(t, err)
+	c.ok(programadoDAO, "Crear", programadoDAO.Crear(ctx, dbPool, programado))
+	vencidos, err := programadoDAO.BuscarVencidos(ctx, dbPool, nodoProgramadoID, ahora, 10)
+	c.ok(programadoDAO, "BuscarVencidos", err)
+	require.Len(t, vencidos, 1)
+	require.NoError(t, programado.Reservar())
+	reservado, err := programadoDAO.ActualizarEstado(ctx, dbPool, programado, model.ProgramadoPendiente)
+	c.ok(programadoDAO, "ActualizarEstado", err)
+	assert.True(t, reservado)
+	programado, err = programadoDAO.BuscarPorID(ctx, dbPool, programado.ID())
+	c.ok(programadoDAO, "BuscarPorID", err)
+	require.NotNil(t, programado)
+	assert.Equal(t, model.ProgramadoEnviando, programado.Estado())
+	paginaProgramados, err := programadoDAO.BuscarPaginaPorRemitenteID(ctx, dbPool, remitenteID, model.ConsultaPagina{})
+	c.ok(programadoDAO, "BuscarPaginaPorRemitenteID", err)
+	assert.Len(t, paginaProgramados.Elementos, 1)
+
+	// Menciones y silencios de menciones
+	mencion, err := model.NewMencion(uuid.New(), destinoID, mensajeCanal.ID(), canalID, remitenteID, model.MencionCanal, ahora, uuid.Nil)
+	require.NoError(t, err)
+	c.ok(mencionDAO, "Crear", mencionDAO.Crear(ctx, dbPool, mencion))
+	leidaMencion, err := mencionDAO.BuscarPorClave(ctx, dbPool, destinoID, mensajeCanal.ID())
+	c.ok(mencionDAO, "BuscarPorClave", err)
+	require.NotNil(t, leidaMencion)
+	paginaMenciones, err := mencionDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, destinoID, model.ConsultaPagina{})
+	c.ok(mencionDAO, "BuscarPaginaPorUsuarioID", err)
+	assert.Len(t, paginaMenciones.Elementos, 1)
+	silencio, err := model.NewSilencioMenciones(destinoID, canalID, model.SilencioCanal, ahora.Add(time.Hour), ahora)
+	require.NoError(t, err)
+	c.ok(silencioDAO, "Guardar", silencioDAO.Guardar(ctx, dbPool, silencio))
+	leidoSilencio, err := silencioDAO.BuscarPorClave(ctx, dbPool, destinoID, canalID)
+	c.ok(silencioDAO, "BuscarPorClave", err)
+	require.NotNil(t, leidoSilencio)
+	silencios, err := silencioDAO.BuscarPorUsuarioID(ctx, dbPool, destinoID)
+	c.ok(silencioDAO, "BuscarPorUsuarioID", err)
+	assert.Len(t, silencios, 1)
+
+	// Mensajes y enlaces fijados
+	fijado, err := model.NewFijadoCanal(canalID, mensajeCanal.ID(), "", "", remitenteID, ahora)
+	require.NoError(t, err)
+	c.ok(fijadoDAO, "Guardar", fijadoDAO.Guardar(ctx, dbPool, fijado))
+	leidoFijado, err := fijadoDAO.BuscarPorID(ctx, dbPool, fijado.ID())
+	c.ok(fijadoDAO, "BuscarPorID", err)
+	require.NotNil(t, leidoFijado)
+	fijados, err := fijadoDAO.BuscarVigentesPorCanalID(ctx, dbPool, canalID)
+	c.ok(fijadoDAO, "BuscarVigentesPorCanalID", err)
+	assert.Len(t, fijados, 1)
+	conteoFijados, err := fijadoDAO.ContarVigentesPorCanales(ctx, dbPool, []uuid.UUID{canalID})
+	c.ok(fijadoDAO, "ContarVigentesPorCanales", err)
+	assert.Equal(t, 1, conteoFijados[canalID])
+
+	// Edición y borrado lógico con su historial
+	edicion, err := model.NewEdicionMensaje(uuid.New(), mensajeCanal.ID(), remitenteID, model.EdicionContenido, mensajeCanal.Contenido(), ahora)
+	require.NoError(t, err)
+	require.NoError(t, mensajeCanal.Editar("al canal, corregido", ahora))
+	require.NoError(t, mensajeCanal.Eliminar(ahora))
+	require.NoError(t, mensajeDAO.Actualizar(ctx, dbPool, mensajeCanal))
+	c.ok(edicionDAO, "Crear", edicionDAO.Crear(ctx, dbPool, edicion))
+	leida, err := edicionDAO.BuscarPorID(ctx, dbPool, edicion.ID())
+	c.ok(edicionDAO, "BuscarPorID", err)
+	require.NotNil(t, leida)
+	assert.Equal(t, "al canal", leida.ContenidoAnterior())
+	historial, err := edicionDAO.BuscarPorMensajeID(ctx, dbPool, mensajeCanal.ID())
+	c.ok(edicionDAO, "BuscarPorMensajeID", err)
+	assert.Len(t, historial, 1)
+	mensaje, err = mensajeDAO.BuscarPorID(ctx, dbPool, mensajeCanal.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "al canal, corregido", mensaje.Contenido())
+	assert.True(t, mensaje.Editado())
+	assert.True(t, mensaje.Eliminado())
+
+	// Nodos, heartbeats, eventos de réplica y mensajes enrutados
+	nodoID, vecinoID := uuid.New(), uuid.New()
+	for i, id := range []uuid.UUID{nodoID, vecinoID} {
+		nodo, err := model.NewPeer(id, "10.0.0."+string(rune('1'+i))+":7000", model.NodoConectado)
+		require.NoError(t, err)
+		c.ok(nodoDAO, "Guardar", nodoDAO.Guardar(ctx, dbPool, nodo))
+	}
+	nodo, err := nodoDAO.BuscarPorID(ctx, dbPool, nodoID)
+	c.ok(nodoDAO, "BuscarPorID", err)
+	require.NotNil(t, nodo)
+	c.ok(nodoDAO, "Actualizar", nodoDAO.Actualizar(ctx, dbPool, nodo))
+	c.ok(nodoDAO, "ActualizarUltimaSync", nodoDAO.ActualizarUltimaSync(ctx, dbPool, nodoID, ahora))
+	nodos, err := nodoDAO.BuscarTodos(ctx, dbPool)
+	c.ok(nodoDAO, "BuscarTodos", err)
+	assert.Len(t, nodos, 2)
+	nodos, err = nodoDAO.BuscarPorEstado(ctx, dbPool, model.NodoConectado)
+	c.ok(nodoDAO, "BuscarPorEstado", err)
+	assert.Len(t, nodos, 2)
+
+	heartbeatID := uuid.New()
+	heartbeat, err := model.NewHeartbeatLog(heartbeatID, nodoID, ahora, ahora.Add(time.Millisecond))
+	require.NoError(t, err)
+	c.ok(heartbeatDAO, "Save", heartbeatDAO.Save(ctx, dbPool, heartbeat))
+	heartbeat, err = heartbeatDAO.FindByID(ctx, dbPool, heartbeatID)
+	c.ok(heartbeatDAO, "FindByID", err)
+	require.NotNil(t, heartbeat)
+	heartbeats, err := heartbeatDAO.FindAll(ctx, dbPool)
+	c.ok(heartbeatDAO, "FindAll", err)
+	assert.Len(t, heartbeats, 1)
+	heartbeats, err = heartbeatDAO.FindByNodoID(ctx, dbPool, nodoID)
+	c.ok(heartbeatDAO, "FindByNodoID", err)
+	assert.Len(t, heartbeats, 1)
+	heartbeats, err = heartbeatDAO.FindByTimeRange(ctx, dbPool, ahora.Add(-time.Minute), ahora.Add(time.Minute))
+	c.ok(heartbeatDAO, "FindByTimeRange", err)
+	assert.Len(t, heartbeats, 1)
+
+	eventoID := uuid.New()
+	evento, err := model.NewReplicaEvent(eventoID, "mensaje", mensajeCanal.ID(), ahora, nodoID)
+	require.NoError(t, err)
+	c.ok(replicaDAO, "Create", replicaDAO.Create(ctx, dbPool, evento))
+	evento, err = replicaDAO.FindByID(ctx, dbPool, eventoID)
+	c.ok(replicaDAO, "FindByID", err)
+	require.NotNil(t, evento)
+	eventos, err := replicaDAO.FindAll(ctx, dbPool)
+	c.ok(replicaDAO, "FindAll", err)
+	assert.Len(t, eventos, 1)
+	eventos, err = replicaDAO.FindByEntidadID(ctx, dbPool, mensajeCanal.ID())
+	c.ok(replicaDAO, "FindByEntidadID", err)
+	assert.Len(t, eventos, 1)
+	eventos, err = replicaDAO.FindByOrigenNodoID(ctx, dbPool, nodoID)
+	c.ok(replicaDAO, "FindByOrigenNodoID", err)
+	assert.Len(t, eventos, 1)
+	eventos, err = replicaDAO.FindByEntidadTipo(ctx, dbPool, "mensaje")
+	c.ok(replicaDAO, "FindByEntidadTipo", err)
+	assert.Len(t, eventos, 1)
+
+	enrutado, err := model.NewRoutedMessageSalto(mensajeCanal.ID(), nodoID, vecinoID, ahora)
+	require.NoError(t, err)
+	c.ok(enrutadoDAO, "Crear", enrutadoDAO.Crear(ctx, dbPool, enrutado))
+	enrutados, err := enrutadoDAO.BuscarPorMensajeID(ctx, dbPool, mensajeCanal.ID())
+	c.ok(enrutadoDAO, "BuscarPorMensajeID", err)
+	require.Len(t, enrutados, 1)
+	assert.Equal(t, nodoID, enrutados[0].NodoAnteriorID())
+	enrutados, err = enrutadoDAO.BuscarTodos(ctx, dbPool)
+	c.ok(enrutadoDAO, "BuscarTodos", err)
+	assert.Len(t, enrutados, 1)
+	enrutados, err = enrutadoDAO.BuscarPorNodoDestinoID(ctx, dbPool, vecinoID)
+	c.ok(enrutadoDAO, "BuscarPorNodoDestinoID", err)
+	assert.Len(t, enrutados, 1)
+	paginaEnrutados, err := enrutadoDAO.BuscarPaginaPorNodoDestinoID(ctx, dbPool, vecinoID, model.ConsultaPagina{})
+	c.ok(enrutadoDAO, "BuscarPaginaPorNodoDestinoID", err)
+	assert.Len(t, paginaEnrutados.Elementos, 1)
+	enrutados, err = enrutadoDAO.BuscarPorRangoTiempo(ctx, dbPool, ahora.Add(-time.Minute), ahora.Add(time.Minute))
+	c.ok(enrutadoDAO, "BuscarPorRangoTiempo", err)
+	assert.Len(t, enrutados, 1)
+
+	// Auditoría y configuración
+	logID := uuid.New()
+	entrada, err := model.NewLogEntry(logID, model.EventoLogin, "inicio de sesión", ahora, remitenteID)
+	require.NoError(t, err)
+	c.ok(logDAO, "Crear", logDAO.Crear(ctx, dbPool, entrada))
+	entrada, err = logDAO.BuscarPorID(ctx, dbPool, logID)
+	c.ok(logDAO, "BuscarPorID", err)
+	require.NotNil(t, entrada)
+	entradas, err := logDAO.BuscarPorUsuarioID(ctx, dbPool, remitenteID)
+	c.ok(logDAO, "BuscarPorUsuarioID", err)
+	assert.Len(t, entradas, 1)
+	entradas, err = logDAO.BuscarPorTipoEvento(ctx, dbPool, model.EventoLogin)
+	c.ok(logDAO, "BuscarPorTipoEvento", err)
+	assert.Len(t, entradas, 1)
+	entradas, err = logDAO.BuscarTodos(ctx, dbPool)
+	c.ok(logDAO, "BuscarTodos", err)
+	assert.Len(t, entradas, 1)
+	paginaEntradas, err := logDAO.BuscarPagina(ctx, dbPool, FiltroEntradaLog{
+		UsuarioID:   remitenteID,
+		TiposEvento: []model.EventoTipo{model.EventoLogin, model.EventoMensaje},
+		Desde:       ahora.Add(-time.Minute),
+		Hasta:       ahora.Add(time.Minute),
+	}, model.ConsultaPagina{})
+	c.ok(logDAO, "BuscarPagina", err)
+	assert.Len(t, paginaEntradas.Elementos, 1)
+
+	config, err := model.NewConfiguracionServidor(100, "{}", "/var/log")
+	require.NoError(t, err)
+	c.ok(configDAO, "Save", configDAO.Save(ctx, dbPool, config))
+	config, err = configDAO.GetConfig(ctx, dbPool)
+	c.ok(configDAO, "GetConfig", err)
+	require.NotNil(t, config)
+	assert.Equal(t, 100, config.MaxConexiones())
+	c.ok(configDAO, "Update", configDAO.Update(ctx, dbPool, config))
+
+	// Eliminaciones, de las filas dependientes a las referenciadas
+	c.ok(logDAO, "Eliminar", logDAO.Eliminar(ctx, dbPool, logID))
+	c.ok(enrutadoDAO, "Eliminar", enrutadoDAO.Eliminar(ctx, dbPool, mensajeCanal.ID()))
+	c.ok(replicaDAO, "Delete", replicaDAO.Delete(ctx, dbPool, eventoID))
+	c.ok(heartbeatDAO, "Delete", heartbeatDAO.Delete(ctx, dbPool, heartbeatID))
+	c.ok(nodoDAO, "Eliminar", nodoDAO.Eliminar(ctx, dbPool, vecinoID))
+	c.ok(mensajeDAO, "Eliminar", mensajeDAO.Eliminar(ctx, dbPool, mensajeChat.ID()))
+	c.ok(archivoDAO, "Delete", archivoDAO.Delete(ctx, dbPool, archivoID))
+	c.ok(chatUsuarioDAO, "Eliminar", chatUsuarioDAO.Eliminar(ctx, dbPool, chatID, destinoID))
+	c.ok(chatUsuarioDAO, "EliminarPorChatPrivadoID", chatUsuarioDAO.EliminarPorChatPrivadoID(ctx, dbPool, chatID))
+	c.ok(chatDAO, "Eliminar", chatDAO.Eliminar(ctx, dbPool, chatID))
+	c.ok(notificacionDAO, "Eliminar", notificacionDAO.Eliminar(ctx, dbPool, notificacionID))
+	c.ok(invitacionDAO, "Eliminar", invitacionDAO.Eliminar(ctx, dbPool, invitacionID))
+	c.ok(miembroDAO, "Eliminar", miembroDAO.Eliminar(ctx, dbPool, canalID, remitenteID))
+	c.ok(canalDAO, "Eliminar", canalDAO.Eliminar(ctx, dbPool, canalID))
+	c.ok(usuarioDAO, "Eliminar", usuarioDAO.Eliminar(ctx, dbPool, destinoID))
+
+	faltan := c.sinEjercitar(
+		usuarioDAO, canalDAO, miembroDAO, invitacionDAO, notificacionDAO, chatDAO,
+		chatUsuarioDAO, archivoDAO, mensajeDAO, nodoDAO, heartbeatDAO, replicaDAO,
+		enrutadoDAO, logDAO, configDAO, edicionDAO, reaccionDAO, acuseDAO, marcadorDAO,
+		pendienteDAO, programadoDAO, mencionDAO, silencioDAO, fijadoDAO,
+	)
+	assert.Empty(t, faltan, "métodos de DAO sin cubrir por la prueba de contrato")
+}
@@ -0,0 +1,117 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// EdicionMensajeDAO maneja las operaciones de base de datos para el historial de
+// ediciones y borrados de los mensajes
+type EdicionMensajeDAO struct{}
+
+// NuevoEdicionMensajeDAO crea una nueva instancia de EdicionMensajeDAO
+func NuevoEdicionMensajeDAO() *EdicionMensajeDAO {
+	return &EdicionMensajeDAO{}
+}
+
+// Crear persiste una entrada del historial de un mensaje
+func (dao *EdicionMensajeDAO) Crear(ctx context.Context, q Querier, edicion *model.EdicionMensaje) error {
+	query := `INSERT INTO mensaje_edicion (id, mensaje_id, editor_id, tipo,
+              contenido_anterior, fecha, version_hlc)
+              VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		edicion.ID().String(),
+		edicion.MensajeID().String(),
+		edicion.EditorID().String(),
+		string(edicion.Tipo()),
+		edicion.ContenidoAnterior(),
+		edicion.Fecha(),
+		nullableHLC(edicion.Version()),
+	)
+
+	return err
+}
+
+// BuscarPorID recupera una entrada del historial por su ID
+func (dao *EdicionMensajeDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.EdicionMensaje, error) {
+	query := `SELECT id, mensaje_id, editor_id, tipo, contenido_anterior, fecha, version_hlc
+              FROM mensaje_edicion WHERE id = ?`
+
+	rows, err := q.QueryContext(ctx, query, id.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ediciones, err := dao.escanearEdiciones(rows)
+	if err != nil || len(ediciones) == 0 {
+		return nil, err
+	}
+	return ediciones[0], nil
+}
+
+// BuscarPorMensajeID recupera el historial de un mensaje en orden cronológico
+func (dao *EdicionMensajeDAO) BuscarPorMensajeID(ctx context.Context, q Querier, mensajeID uuid.UUID) ([]*model.EdicionMensaje, error) {
+	query := `SELECT id, mensaje_id, editor_id, tipo, contenido_anterior, fecha, version_hlc
+              FROM mensaje_edicion WHERE mensaje_id = ?
+              ORDER BY fecha, id`
+
+	rows, err := q.QueryContext(ctx, query, mensajeID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dao.escanearEdiciones(rows)
+}
+
+// escanearEdiciones convierte las filas leídas en entradas del historial
+func (dao *EdicionMensajeDAO) escanearEdiciones(rows *sql.Rows) ([]*model.EdicionMensaje, error) {
+	var ediciones []*model.EdicionMensaje
+	for rows.Next() {
+		var (
+			idStr, mensajeIDStr, editorIDStr, tipo, contenidoAnterior string
+			fecha                                                     time.Time
+			versionStr                                                sql.NullString
+		)
+		if err := rows.Scan(&idStr, &mensajeIDStr, &editorIDStr, &tipo, &contenidoAnterior, &fecha, &versionStr); err != nil {
+			return nil, err
+		}
+
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, err
+		}
+		mensajeID, err := uuid.Parse(mensajeIDStr)
+		if err != nil {
+			return nil, err
+		}
+		editorID, err := uuid.Parse(editorIDStr)
+		if err != nil {
+			return nil, err
+		}
+		version, err := parseNullableHLC(versionStr)
+		if err != nil {
+			return nil, err
+		}
+
+		edicion, err := model.NewEdicionMensaje(id, mensajeID, editorID, model.TipoEdicion(tipo), contenidoAnterior, fecha)
+		if err != nil {
+			return nil, err
+		}
+		edicion.SetVersion(version)
+		ediciones = append(ediciones, edicion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ediciones, nil
+}
@@ -1,56 +1,65 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
-	"pool"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"model"
 )
 
-// EntradaLogDAO maneja las operaciones de base de datos para entidades LogEntry (EntradaLog)
-type EntradaLogDAO struct {
-	dbPool *pool.DBConnectionPool
+// FiltroEntradaLog restringe las entradas de log de BuscarPagina. Los campos con su
+// valor cero no filtran; el rango de fechas es [Desde, Hasta).
+type FiltroEntradaLog struct {
+	UsuarioID   uuid.UUID
+	TiposEvento []model.EventoTipo
+	Desde       time.Time
+	Hasta       time.Time
 }
 
+// EntradaLogDAO maneja las operaciones de base de datos para entidades LogEntry (EntradaLog)
+type EntradaLogDAO struct{}
+
 // NuevoEntradaLogDAO crea una nueva instancia de EntradaLogDAO
-func NuevoEntradaLogDAO(dbPool *pool.DBConnectionPool) *EntradaLogDAO {
-	return &EntradaLogDAO{dbPool: dbPool}
+func NuevoEntradaLogDAO() *EntradaLogDAO {
+	return &EntradaLogDAO{}
 }
 
 // Crear persiste una nueva entrada de log en la base de datos
-func (dao *EntradaLogDAO) Crear(log *model.LogEntry) error {
-	query := `INSERT INTO log_entries (id, tipo_evento, detalle, timestamp, usuario_id)
+func (dao *EntradaLogDAO) Crear(ctx context.Context, q Querier, log *model.LogEntry) error {
+	query := `INSERT INTO log_entry (id, tipo_evento, detalle, timestamp, usuario_id)
               VALUES (?, ?, ?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		log.ID().String(),
 		string(log.TipoEvento()), // EventoTipo es de tipo string
 		log.Detalle(),
 		log.Timestamp(),
-		log.UsuarioID().String(),
+		nullableUUID(log.UsuarioID()),
 	)
 
 	return err
 }
 
 // BuscarPorID recupera una entrada de log por su ID
-func (dao *EntradaLogDAO) BuscarPorID(id uuid.UUID) (*model.LogEntry, error) {
+func (dao *EntradaLogDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.LogEntry, error) {
 	query := `SELECT id, tipo_evento, detalle, timestamp, usuario_id
-              FROM log_entries WHERE id = ?`
+              FROM log_entry WHERE id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearEntradaLog(row)
 }
 
 // BuscarPorUsuarioID recupera todas las entradas de log para un usuario específico
-func (dao *EntradaLogDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*model.LogEntry, error) {
+func (dao *EntradaLogDAO) BuscarPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID) ([]*model.LogEntry, error) {
 	query := `SELECT id, tipo_evento, detalle, timestamp, usuario_id
-              FROM log_entries WHERE usuario_id = ?`
+              FROM log_entry WHERE usuario_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, usuarioID.String())
+	rows, err := q.QueryContext(ctx, query, usuarioID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -60,11 +69,11 @@ func (dao *EntradaLogDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*model.LogE
 }
 
 // BuscarPorTipoEvento recupera todas las entradas de log de un tipo específico
-func (dao *EntradaLogDAO) BuscarPorTipoEvento(tipoEvento model.EventoTipo) ([]*model.LogEntry, error) {
+func (dao *EntradaLogDAO) BuscarPorTipoEvento(ctx context.Context, q Querier, tipoEvento model.EventoTipo) ([]*model.LogEntry, error) {
 	query := `SELECT id, tipo_evento, detalle, timestamp, usuario_id
-              FROM log_entries WHERE tipo_evento = ?`
+              FROM log_entry WHERE tipo_evento = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, string(tipoEvento))
+	rows, err := q.QueryContext(ctx, query, string(tipoEvento))
 	if err != nil {
 		return nil, err
 	}
@@ -74,11 +83,11 @@ func (dao *EntradaLogDAO) BuscarPorTipoEvento(tipoEvento model.EventoTipo) ([]*m
 }
 
 // BuscarTodos recupera todas las entradas de log de la base de datos
-func (dao *EntradaLogDAO) BuscarTodos() ([]*model.LogEntry, error) {
+func (dao *EntradaLogDAO) BuscarTodos(ctx context.Context, q Querier) ([]*model.LogEntry, error) {
 	query := `SELECT id, tipo_evento, detalle, timestamp, usuario_id
-              FROM log_entries`
+              FROM log_entry`
 
-	rows, err := dao.dbPool.DB().Query(query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -87,20 +96,63 @@ func (dao *EntradaLogDAO) BuscarTodos() ([]*model.LogEntry, error) {
 	return dao.escanearMultiplesEntradasLog(rows)
 }
 
+// BuscarPagina recupera una página de las entradas de log que cumplen el filtro,
+// ordenadas por (timestamp, id)
+func (dao *EntradaLogDAO) BuscarPagina(ctx context.Context, q Querier, filtro FiltroEntradaLog, consulta model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	query := `SELECT id, tipo_evento, detalle, timestamp, usuario_id
+              FROM log_entry WHERE 1 = 1`
+	var args []interface{}
+
+	if filtro.UsuarioID != uuid.Nil {
+		query += " AND usuario_id = ?"
+		args = append(args, filtro.UsuarioID.String())
+	}
+	if len(filtro.TiposEvento) > 0 {
+		query += " AND tipo_evento IN (?" + strings.Repeat(", ?", len(filtro.TiposEvento)-1) + ")"
+		for _, tipo := range filtro.TiposEvento {
+			args = append(args, string(tipo))
+		}
+	}
+	if !filtro.Desde.IsZero() {
+		query += " AND timestamp >= ?"
+		args = append(args, filtro.Desde)
+	}
+	if !filtro.Hasta.IsZero() {
+		query += " AND timestamp < ?"
+		args = append(args, filtro.Hasta)
+	}
+
+	clausula, argsPagina := clausulaPagina("timestamp", "id", consulta)
+	rows, err := q.QueryContext(ctx, query+clausula, append(args, argsPagina...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entradas, err := dao.escanearMultiplesEntradasLog(rows)
+	if err != nil {
+		return nil, err
+	}
+	return model.NuevaPagina(entradas, consulta, func(e *model.LogEntry) model.Cursor {
+		return model.NewCursor(e.Timestamp(), e.ID())
+	}), nil
+}
+
 // Eliminar elimina una entrada de log de la base de datos
-func (dao *EntradaLogDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM log_entries WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *EntradaLogDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM log_entry WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
 // Método auxiliar para escanear una fila en una entrada de log
 func (dao *EntradaLogDAO) escanearEntradaLog(row *sql.Row) (*model.LogEntry, error) {
 	var (
-		idStr, usuarioIDStr string
-		tipoEventoStr       string
-		detalle             string
-		timestamp           time.Time
+		idStr         string
+		usuarioIDStr  sql.NullString // NULL en eventos sin usuario
+		tipoEventoStr string
+		detalle       string
+		timestamp     time.Time
 	)
 
 	if err := row.Scan(&idStr, &tipoEventoStr, &detalle, &timestamp, &usuarioIDStr); err != nil {
@@ -115,7 +167,7 @@ func (dao *EntradaLogDAO) escanearEntradaLog(row *sql.Row) (*model.LogEntry, err
 		return nil, err
 	}
 
-	usuarioID, err := uuid.Parse(usuarioIDStr)
+	usuarioID, err := parseNullableUUID(usuarioIDStr)
 	if err != nil {
 		return nil, err
 	}
@@ -132,10 +184,11 @@ func (dao *EntradaLogDAO) escanearMultiplesEntradasLog(rows *sql.Rows) ([]*model
 
 	for rows.Next() {
 		var (
-			idStr, usuarioIDStr string
-			tipoEventoStr       string
-			detalle             string
-			timestamp           time.Time
+			idStr         string
+			usuarioIDStr  sql.NullString
+			tipoEventoStr string
+			detalle       string
+			timestamp     time.Time
 		)
 
 		if err := rows.Scan(&idStr, &tipoEventoStr, &detalle, &timestamp, &usuarioIDStr); err != nil {
@@ -147,7 +200,7 @@ func (dao *EntradaLogDAO) escanearMultiplesEntradasLog(rows *sql.Rows) ([]*model
 			return nil, err
 		}
 
-		usuarioID, err := uuid.Parse(usuarioIDStr)
+		usuarioID, err := parseNullableUUID(usuarioIDStr)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// EventoPendienteDAO maneja las operaciones de base de datos para la cola de eventos
+// pendientes de los usuarios desconectados
+type EventoPendienteDAO struct{}
+
+// NuevoEventoPendienteDAO crea una nueva instancia de EventoPendienteDAO
+func NuevoEventoPendienteDAO() *EventoPendienteDAO {
+	return &EventoPendienteDAO{}
+}
+
+// Crear añade un evento a la cola de su destinatario
+func (dao *EventoPendienteDAO) Crear(ctx context.Context, q Querier, evento *model.EventoPendiente) error {
+	query := `INSERT INTO evento_pendiente (id, usuario_id, evento, datos, fecha)
+              VALUES (?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		evento.ID().String(),
+		evento.UsuarioID().String(),
+		evento.Evento(),
+		string(evento.Datos()),
+		evento.Fecha(),
+	)
+
+	return err
+}
+
+// BuscarPaginaPorUsuarioID recupera una página de la cola de un usuario, ordenada por
+// (fecha, id)
+func (dao *EventoPendienteDAO) BuscarPaginaPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.EventoPendiente], error) {
+	query := `SELECT id, usuario_id, evento, datos, fecha
+              FROM evento_pendiente WHERE usuario_id = ?`
+
+	clausula, args := clausulaPagina("fecha", "id", consulta)
+	rows, err := q.QueryContext(ctx, query+clausula, append([]interface{}{usuarioID.String()}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	eventos, err := dao.escanearEventos(rows)
+	if err != nil {
+		return nil, err
+	}
+	return model.NuevaPagina(eventos, consulta, (*model.EventoPendiente).Posicion), nil
+}
+
+// ContarPorUsuarioID devuelve cuántos eventos tiene la cola de un usuario
+func (dao *EventoPendienteDAO) ContarPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM evento_pendiente WHERE usuario_id = ?`
+
+	var total int
+	err := q.QueryRowContext(ctx, query, usuarioID.String()).Scan(&total)
+	return total, err
+}
+
+// EliminarHasta borra de la cola de un usuario los eventos en la posición del cursor o
+// anteriores y devuelve cuántos se borraron
+func (dao *EventoPendienteDAO) EliminarHasta(ctx context.Context, q Querier, usuarioID uuid.UUID, cursor model.Cursor) (int64, error) {
+	query := `DELETE FROM evento_pendiente
+              WHERE usuario_id = ? AND (fecha < ? OR (fecha = ? AND id <= ?))`
+
+	res, err := q.ExecContext(ctx, query, usuarioID.String(), cursor.Timestamp(), cursor.Timestamp(), cursor.ID().String())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Recortar deja en la cola de un usuario solo los conservar eventos más recientes y
+// devuelve cuántos se borraron. Localiza primero el más reciente de los que sobran, ya
+// que MySQL no admite LIMIT en una subconsulta de DELETE.
+func (dao *EventoPendienteDAO) Recortar(ctx context.Context, q Querier, usuarioID uuid.UUID, conservar int) (int64, error) {
+	query := `SELECT fecha, id FROM evento_pendiente WHERE usuario_id = ?
+              ORDER BY fecha DESC, id DESC LIMIT 1 OFFSET ?`
+
+	var (
+		fecha time.Time
+		idStr string
+	)
+	err := q.QueryRowContext(ctx, query, usuarioID.String(), conservar).Scan(&fecha, &idStr)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return 0, err
+	}
+	return dao.EliminarHasta(ctx, q, usuarioID, model.NewCursor(fecha, id))
+}
+
+// escanearEventos convierte las filas leídas en eventos pendientes
+func (dao *EventoPendienteDAO) escanearEventos(rows *sql.Rows) ([]*model.EventoPendiente, error) {
+	var eventos []*model.EventoPendiente
+	for rows.Next() {
+		var (
+			idStr, usuarioIDStr, evento, datos string
+			fecha                              time.Time
+		)
+		if err := rows.Scan(&idStr, &usuarioIDStr, &evento, &datos, &fecha); err != nil {
+			return nil, err
+		}
+
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, err
+		}
+		usuarioID, err := uuid.Parse(usuarioIDStr)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := model.NewEventoPendiente(id, usuarioID, evento, []byte(datos), fecha)
+		if err != nil {
+			return nil, err
+		}
+		eventos = append(eventos, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return eventos, nil
+}
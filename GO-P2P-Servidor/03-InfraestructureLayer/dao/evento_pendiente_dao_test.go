@@ -0,0 +1,79 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestEventoPendiente_ColaPorUsuario comprueba que la cola se pagina en orden (fecha, id)
+// sin mezclar usuarios, que EliminarHasta borra hasta el cursor incluido y que Recortar
+// conserva los más recientes
+func TestEventoPendiente_ColaPorUsuario(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	ahora := time.Now().UTC().Truncate(time.Second)
+	pendienteDAO := NuevoEventoPendienteDAO()
+
+	anaID, betoID := uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{anaID, betoID} {
+		crearUsuarioPrueba(t, dbPool, id)
+	}
+	encolar := func(usuarioID uuid.UUID, fecha time.Time) *model.EventoPendiente {
+		t.Helper()
+		e, err := model.NewEventoPendiente(uuid.Must(uuid.NewV7()), usuarioID, "MENSAJE", []byte(`{}`), fecha)
+		require.NoError(t, err)
+		require.NoError(t, pendienteDAO.Crear(ctx, dbPool, e))
+		return e
+	}
+	// Los dos primeros en el mismo instante se ordenan por su id v7
+	var eventos []*model.EventoPendiente
+	for _, fecha := range []time.Time{ahora, ahora, ahora.Add(time.Second), ahora.Add(2 * time.Second)} {
+		eventos = append(eventos, encolar(anaID, fecha))
+	}
+	encolar(betoID, ahora)
+
+	consulta, err := model.NewConsultaPagina("", model.PaginaPosteriores, 3)
+	require.NoError(t, err)
+	pagina, err := pendienteDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, anaID, consulta)
+	require.NoError(t, err)
+	require.Len(t, pagina.Elementos, 3)
+	assert.True(t, pagina.HayPosteriores)
+	for i, e := range pagina.Elementos {
+		assert.Equal(t, eventos[i].ID(), e.ID())
+	}
+
+	consulta, err = model.NewConsultaPagina(pagina.Siguiente, model.PaginaPosteriores, 3)
+	require.NoError(t, err)
+	pagina, err = pendienteDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, anaID, consulta)
+	require.NoError(t, err)
+	require.Len(t, pagina.Elementos, 1)
+	assert.Equal(t, eventos[3].ID(), pagina.Elementos[0].ID())
+	assert.False(t, pagina.HayPosteriores)
+
+	borrados, err := pendienteDAO.EliminarHasta(ctx, dbPool, anaID, eventos[1].Posicion())
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, borrados)
+
+	recortados, err := pendienteDAO.Recortar(ctx, dbPool, anaID, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, recortados)
+	recortados, err = pendienteDAO.Recortar(ctx, dbPool, anaID, 1)
+	require.NoError(t, err)
+	assert.Zero(t, recortados)
+
+	pagina, err = pendienteDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, anaID, model.ConsultaPagina{})
+	require.NoError(t, err)
+	require.Len(t, pagina.Elementos, 1)
+	assert.Equal(t, eventos[3].ID(), pagina.Elementos[0].ID())
+
+	enCola, err := pendienteDAO.ContarPorUsuarioID(ctx, dbPool, betoID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, enCola)
+}
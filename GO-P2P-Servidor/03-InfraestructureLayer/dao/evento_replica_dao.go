@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
 	"github.com/google/uuid"
 	"model"
@@ -8,47 +9,47 @@ import (
 )
 
 // ReplicaEventMySQLDAO handles database operations for ReplicaEvent entities
-type ReplicaEventMySQLDAO struct {
-	db *sql.DB
-}
+type ReplicaEventMySQLDAO struct{}
 
 // NewReplicaEventMySQLDAO creates a new ReplicaEventMySQLDAO instance
-func NewReplicaEventMySQLDAO(db *sql.DB) *ReplicaEventMySQLDAO {
-	return &ReplicaEventMySQLDAO{db: db}
+func NewReplicaEventMySQLDAO() *ReplicaEventMySQLDAO {
+	return &ReplicaEventMySQLDAO{}
 }
 
 // Create persists a new replica event to the database
-func (dao *ReplicaEventMySQLDAO) Create(event *model.ReplicaEvent) error {
-	query := `INSERT INTO replica_events (id, entidad_tipo, entidad_id, evento_at, origen_nodo_id)
-              VALUES (?, ?, ?, ?, ?)`
+func (dao *ReplicaEventMySQLDAO) Create(ctx context.Context, q Querier, event *model.ReplicaEvent) error {
+	query := `INSERT INTO replica_event (id, entidad_tipo, entidad_id, evento_at, origen_nodo_id, version_hlc)
+              VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := dao.db.Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		event.ID().String(),
 		event.EntidadTipo(),
 		event.EntidadID().String(),
 		event.EventoAt(),
 		event.OrigenNodoID().String(),
+		nullableHLC(event.Version()),
 	)
 
 	return err
 }
 
 // FindByID retrieves a replica event by its ID
-func (dao *ReplicaEventMySQLDAO) FindByID(id uuid.UUID) (*model.ReplicaEvent, error) {
-	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id
-              FROM replica_events WHERE id = ?`
+func (dao *ReplicaEventMySQLDAO) FindByID(ctx context.Context, q Querier, id uuid.UUID) (*model.ReplicaEvent, error) {
+	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id, version_hlc
+              FROM replica_event WHERE id = ?`
 
-	row := dao.db.QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.scanReplicaEvent(row)
 }
 
 // FindAll retrieves all replica events from the database
-func (dao *ReplicaEventMySQLDAO) FindAll() ([]*model.ReplicaEvent, error) {
-	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id
-              FROM replica_events`
+func (dao *ReplicaEventMySQLDAO) FindAll(ctx context.Context, q Querier) ([]*model.ReplicaEvent, error) {
+	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id, version_hlc
+              FROM replica_event`
 
-	rows, err := dao.db.Query(query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -58,11 +59,11 @@ func (dao *ReplicaEventMySQLDAO) FindAll() ([]*model.ReplicaEvent, error) {
 }
 
 // FindByEntidadID retrieves all replica events for a specific entity
-func (dao *ReplicaEventMySQLDAO) FindByEntidadID(entidadID uuid.UUID) ([]*model.ReplicaEvent, error) {
-	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id
-              FROM replica_events WHERE entidad_id = ?`
+func (dao *ReplicaEventMySQLDAO) FindByEntidadID(ctx context.Context, q Querier, entidadID uuid.UUID) ([]*model.ReplicaEvent, error) {
+	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id, version_hlc
+              FROM replica_event WHERE entidad_id = ?`
 
-	rows, err := dao.db.Query(query, entidadID.String())
+	rows, err := q.QueryContext(ctx, query, entidadID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -72,11 +73,11 @@ func (dao *ReplicaEventMySQLDAO) FindByEntidadID(entidadID uuid.UUID) ([]*model.
 }
 
 // FindByOrigenNodoID retrieves all replica events from a specific origin node
-func (dao *ReplicaEventMySQLDAO) FindByOrigenNodoID(origenNodoID uuid.UUID) ([]*model.ReplicaEvent, error) {
-	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id
-              FROM replica_events WHERE origen_nodo_id = ?`
+func (dao *ReplicaEventMySQLDAO) FindByOrigenNodoID(ctx context.Context, q Querier, origenNodoID uuid.UUID) ([]*model.ReplicaEvent, error) {
+	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id, version_hlc
+              FROM replica_event WHERE origen_nodo_id = ?`
 
-	rows, err := dao.db.Query(query, origenNodoID.String())
+	rows, err := q.QueryContext(ctx, query, origenNodoID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -86,11 +87,11 @@ func (dao *ReplicaEventMySQLDAO) FindByOrigenNodoID(origenNodoID uuid.UUID) ([]*
 }
 
 // FindByEntidadTipo retrieves all replica events of a specific entity type
-func (dao *ReplicaEventMySQLDAO) FindByEntidadTipo(entidadTipo string) ([]*model.ReplicaEvent, error) {
-	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id
-              FROM replica_events WHERE entidad_tipo = ?`
+func (dao *ReplicaEventMySQLDAO) FindByEntidadTipo(ctx context.Context, q Querier, entidadTipo string) ([]*model.ReplicaEvent, error) {
+	query := `SELECT id, entidad_tipo, entidad_id, evento_at, origen_nodo_id, version_hlc
+              FROM replica_event WHERE entidad_tipo = ?`
 
-	rows, err := dao.db.Query(query, entidadTipo)
+	rows, err := q.QueryContext(ctx, query, entidadTipo)
 	if err != nil {
 		return nil, err
 	}
@@ -100,9 +101,9 @@ func (dao *ReplicaEventMySQLDAO) FindByEntidadTipo(entidadTipo string) ([]*model
 }
 
 // Delete removes a replica event from the database
-func (dao *ReplicaEventMySQLDAO) Delete(id uuid.UUID) error {
-	query := `DELETE FROM replica_events WHERE id = ?`
-	_, err := dao.db.Exec(query, id.String())
+func (dao *ReplicaEventMySQLDAO) Delete(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM replica_event WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
@@ -112,9 +113,10 @@ func (dao *ReplicaEventMySQLDAO) scanReplicaEvent(row *sql.Row) (*model.ReplicaE
 		idStr, entidadIDStr, origenNodoIDStr string
 		entidadTipo                          string
 		eventoAt                             time.Time
+		versionStr                           sql.NullString
 	)
 
-	if err := row.Scan(&idStr, &entidadTipo, &entidadIDStr, &eventoAt, &origenNodoIDStr); err != nil {
+	if err := row.Scan(&idStr, &entidadTipo, &entidadIDStr, &eventoAt, &origenNodoIDStr, &versionStr); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Replica event not found
 		}
@@ -136,7 +138,7 @@ func (dao *ReplicaEventMySQLDAO) scanReplicaEvent(row *sql.Row) (*model.ReplicaE
 		return nil, err
 	}
 
-	return model.NewReplicaEvent(id, entidadTipo, entidadID, eventoAt, origenNodoID)
+	return buildReplicaEvent(id, entidadTipo, entidadID, eventoAt, origenNodoID, versionStr)
 }
 
 // Helper method to scan multiple replica events
@@ -148,9 +150,10 @@ func (dao *ReplicaEventMySQLDAO) scanMultipleReplicaEvents(rows *sql.Rows) ([]*m
 			idStr, entidadIDStr, origenNodoIDStr string
 			entidadTipo                          string
 			eventoAt                             time.Time
+			versionStr                           sql.NullString
 		)
 
-		if err := rows.Scan(&idStr, &entidadTipo, &entidadIDStr, &eventoAt, &origenNodoIDStr); err != nil {
+		if err := rows.Scan(&idStr, &entidadTipo, &entidadIDStr, &eventoAt, &origenNodoIDStr, &versionStr); err != nil {
 			return nil, err
 		}
 
@@ -169,7 +172,7 @@ func (dao *ReplicaEventMySQLDAO) scanMultipleReplicaEvents(rows *sql.Rows) ([]*m
 			return nil, err
 		}
 
-		event, err := model.NewReplicaEvent(id, entidadTipo, entidadID, eventoAt, origenNodoID)
+		event, err := buildReplicaEvent(id, entidadTipo, entidadID, eventoAt, origenNodoID, versionStr)
 		if err != nil {
 			return nil, err
 		}
@@ -183,3 +186,15 @@ func (dao *ReplicaEventMySQLDAO) scanMultipleReplicaEvents(rows *sql.Rows) ([]*m
 
 	return events, nil
 }
+
+// buildReplicaEvent creates the domain event, carrying the HLC version when the row has one
+func buildReplicaEvent(id uuid.UUID, entidadTipo string, entidadID uuid.UUID, eventoAt time.Time, origenNodoID uuid.UUID, versionStr sql.NullString) (*model.ReplicaEvent, error) {
+	version, err := parseNullableHLC(versionStr)
+	if err != nil {
+		return nil, err
+	}
+	if version.IsZero() {
+		return model.NewReplicaEvent(id, entidadTipo, entidadID, eventoAt, origenNodoID)
+	}
+	return model.NewReplicaEventVersionado(id, entidadTipo, entidadID, eventoAt, origenNodoID, version)
+}
@@ -0,0 +1,184 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// FijadoCanalDAO maneja las operaciones de base de datos para los mensajes y enlaces
+// fijados en los canales
+type FijadoCanalDAO struct{}
+
+// NuevoFijadoCanalDAO crea una nueva instancia de FijadoCanalDAO
+func NuevoFijadoCanalDAO() *FijadoCanalDAO {
+	return &FijadoCanalDAO{}
+}
+
+// Guardar crea o sustituye un elemento fijado, vigente o retirado
+func (dao *FijadoCanalDAO) Guardar(ctx context.Context, q Querier, fijado *model.FijadoCanal) error {
+	query := `REPLACE INTO fijado_canal (id, canal_id, mensaje_id, url, titulo, fijado_por,
+              fijado_en, retirado_por, retirado_en, version_hlc)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var url interface{}
+	if fijado.EsEnlace() {
+		url = fijado.URL()
+	}
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		fijado.ID().String(),
+		fijado.CanalID().String(),
+		nullableUUID(fijado.MensajeID()),
+		url,
+		fijado.Titulo(),
+		fijado.FijadoPor().String(),
+		fijado.FijadoEn(),
+		nullableUUID(fijado.RetiradoPor()),
+		nullableTime(fijado.RetiradoEn()),
+		nullableHLC(fijado.Version()),
+	)
+
+	return err
+}
+
+// BuscarPorID recupera un elemento fijado, vigente o retirado. Devuelve nil si no existe.
+func (dao *FijadoCanalDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.FijadoCanal, error) {
+	query := `SELECT id, canal_id, mensaje_id, url, titulo, fijado_por, fijado_en,
+              retirado_por, retirado_en, version_hlc
+              FROM fijado_canal
+              WHERE id = ?`
+
+	rows, err := q.QueryContext(ctx, query, id.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fijados, err := dao.escanearFijados(rows)
+	if err != nil || len(fijados) == 0 {
+		return nil, err
+	}
+	return fijados[0], nil
+}
+
+// BuscarVigentesPorCanalID recupera los elementos fijados de un canal que no se han
+// retirado, del fijado más recientemente al más antiguo
+func (dao *FijadoCanalDAO) BuscarVigentesPorCanalID(ctx context.Context, q Querier, canalID uuid.UUID) ([]*model.FijadoCanal, error) {
+	query := `SELECT id, canal_id, mensaje_id, url, titulo, fijado_por, fijado_en,
+              retirado_por, retirado_en, version_hlc
+              FROM fijado_canal
+              WHERE canal_id = ? AND retirado_en IS NULL
+              ORDER BY fijado_en DESC, id DESC`
+
+	rows, err := q.QueryContext(ctx, query, canalID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dao.escanearFijados(rows)
+}
+
+// ContarVigentesPorCanales devuelve el número de elementos fijados sin retirar de cada
+// canal que tiene alguno
+func (dao *FijadoCanalDAO) ContarVigentesPorCanales(ctx context.Context, q Querier, canalIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	conteos := make(map[uuid.UUID]int)
+	if len(canalIDs) == 0 {
+		return conteos, nil
+	}
+
+	query := `SELECT canal_id, COUNT(*)
+              FROM fijado_canal
+              WHERE retirado_en IS NULL
+              AND canal_id IN (?` + strings.Repeat(", ?", len(canalIDs)-1) + `)
+              GROUP BY canal_id`
+	args := make([]interface{}, 0, len(canalIDs))
+	for _, id := range canalIDs {
+		args = append(args, id.String())
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			canalIDStr string
+			total      int
+		)
+		if err := rows.Scan(&canalIDStr, &total); err != nil {
+			return nil, err
+		}
+		canalID, err := uuid.Parse(canalIDStr)
+		if err != nil {
+			return nil, err
+		}
+		conteos[canalID] = total
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return conteos, nil
+}
+
+// escanearFijados convierte las filas leídas en elementos fijados
+func (dao *FijadoCanalDAO) escanearFijados(rows *sql.Rows) ([]*model.FijadoCanal, error) {
+	var fijados []*model.FijadoCanal
+	for rows.Next() {
+		var (
+			idStr, canalIDStr, titulo, fijadoPorStr       string
+			mensajeIDStr, url, retiradoPorStr, versionStr sql.NullString
+			fijadoEn                                      time.Time
+			retiradoEn                                    sql.NullTime
+		)
+		if err := rows.Scan(&idStr, &canalIDStr, &mensajeIDStr, &url, &titulo, &fijadoPorStr,
+			&fijadoEn, &retiradoPorStr, &retiradoEn, &versionStr); err != nil {
+			return nil, err
+		}
+
+		canalID, err := uuid.Parse(canalIDStr)
+		if err != nil {
+			return nil, err
+		}
+		mensajeID, err := parseNullableUUID(mensajeIDStr)
+		if err != nil {
+			return nil, err
+		}
+		fijadoPor, err := uuid.Parse(fijadoPorStr)
+		if err != nil {
+			return nil, err
+		}
+		retiradoPor, err := parseNullableUUID(retiradoPorStr)
+		if err != nil {
+			return nil, err
+		}
+		version, err := parseNullableHLC(versionStr)
+		if err != nil {
+			return nil, err
+		}
+
+		// El id se deriva del canal y del elemento, así que no se lee de la fila
+		fijado, err := model.NewFijadoCanal(canalID, mensajeID, url.String, titulo, fijadoPor, fijadoEn)
+		if err != nil {
+			return nil, err
+		}
+		fijado.SetRetirada(retiradoPor, retiradoEn.Time)
+		fijado.SetVersion(version)
+		fijados = append(fijados, fijado)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return fijados, nil
+}
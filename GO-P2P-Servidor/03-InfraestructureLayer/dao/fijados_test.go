@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestFijados_VigentesYRetirada comprueba que un canal lista y cuenta solo sus elementos
+// fijados vigentes, del más reciente al más antiguo, que un enlace conserva su URL y su
+// título y que volver a fijar un elemento retirado sustituye su fila
+func TestFijados_VigentesYRetirada(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	ahora := time.Now().UTC().Truncate(time.Second)
+	fijadoDAO := NuevoFijadoCanalDAO()
+
+	anaID, betoID := uuid.New(), uuid.New()
+	crearUsuarioPrueba(t, dbPool, anaID)
+	crearUsuarioPrueba(t, dbPool, betoID)
+	canalID, otroCanalID, mensajeID := uuid.New(), uuid.New(), uuid.New()
+
+	fijar := func(canalID, mensajeID uuid.UUID, enlace, titulo string, at time.Time) *model.FijadoCanal {
+		t.Helper()
+		f, err := model.NewFijadoCanal(canalID, mensajeID, enlace, titulo, anaID, at)
+		require.NoError(t, err)
+		require.NoError(t, fijadoDAO.Guardar(ctx, dbPool, f))
+		return f
+	}
+	mensaje := fijar(canalID, mensajeID, "", "", ahora)
+	enlace := fijar(canalID, uuid.Nil, "https://ejemplo.com/guia", "Guía", ahora.Add(time.Minute))
+	fijar(otroCanalID, mensajeID, "", "", ahora)
+
+	fijados, err := fijadoDAO.BuscarVigentesPorCanalID(ctx, dbPool, canalID)
+	require.NoError(t, err)
+	require.Len(t, fijados, 2)
+	assert.Equal(t, enlace.ID(), fijados[0].ID())
+	assert.Equal(t, "https://ejemplo.com/guia", fijados[0].URL())
+	assert.Equal(t, "Guía", fijados[0].Titulo())
+	assert.Equal(t, mensajeID, fijados[1].MensajeID())
+	assert.Equal(t, anaID, fijados[1].FijadoPor())
+
+	// Un elemento retirado se conserva, pero deja de listarse y de contarse
+	require.NoError(t, mensaje.Retirar(betoID, ahora.Add(2*time.Minute)))
+	version, err := model.NewHLC(ahora.Add(2*time.Minute).UnixNano(), 1, uuid.New())
+	require.NoError(t, err)
+	mensaje.SetVersion(version)
+	require.NoError(t, fijadoDAO.Guardar(ctx, dbPool, mensaje))
+	leido, err := fijadoDAO.BuscarPorID(ctx, dbPool, mensaje.ID())
+	require.NoError(t, err)
+	require.NotNil(t, leido)
+	assert.False(t, leido.Vigente())
+	assert.Equal(t, betoID, leido.RetiradoPor())
+	assert.Equal(t, mensaje.Version(), leido.Version())
+
+	conteos, err := fijadoDAO.ContarVigentesPorCanales(ctx, dbPool, []uuid.UUID{canalID, otroCanalID, uuid.New()})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{canalID: 1, otroCanalID: 1}, conteos)
+
+	// Volver a fijarlo sustituye la fila retirada
+	fijar(canalID, mensajeID, "", "", ahora.Add(3*time.Minute))
+	fijados, err = fijadoDAO.BuscarVigentesPorCanalID(ctx, dbPool, canalID)
+	require.NoError(t, err)
+	require.Len(t, fijados, 2)
+	assert.Equal(t, mensaje.ID(), fijados[0].ID())
+	assert.True(t, fijados[0].Vigente())
+
+	ausente, err := fijadoDAO.BuscarPorID(ctx, dbPool, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, ausente)
+}
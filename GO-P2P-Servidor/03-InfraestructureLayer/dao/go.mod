@@ -3,6 +3,7 @@ module dao
 go 1.21
 
 replace (
+	migration => ../migration
 	model => ../../04-DomainLayer/model
 	pool => ../pool
 )
@@ -10,7 +11,9 @@ replace (
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/google/uuid v1.6.0
+	github.com/sirupsen/logrus v1.9.3
 	github.com/stretchr/testify v1.10.0
+	migration v0.0.0-00010101000000-000000000000
 	model v0.0.0-00010101000000-000000000000
 	pool v0.0.0-00010101000000-000000000000
 )
@@ -18,8 +21,8 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-sql-driver/mysql v1.7.1 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/sirupsen/logrus v1.9.3 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
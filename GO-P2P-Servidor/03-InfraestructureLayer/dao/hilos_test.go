@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestHilos_ResumenYRespuestas comprueba el recuento de respuestas y la fecha de la
+// última, que las respuestas eliminadas no cuentan y que solo se listan los hilos con
+// alguna respuesta
+func TestHilos_ResumenYRespuestas(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	base := time.Now().UTC().Truncate(time.Second)
+
+	usuarioID, canalID := uuid.New(), uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
+	canal, err := model.NewCanalServidor(canalID, "hilos", "Canal con hilos", model.CanalPublico)
+	require.NoError(t, err)
+	require.NoError(t, NuevoCanalDAO().Crear(ctx, dbPool, canal))
+
+	mensajeDAO := NuevoMensajeDAO()
+	crear := func(contenido string, at time.Time, padre *model.MensajeServidor) *model.MensajeServidor {
+		t.Helper()
+		m, err := model.NewMensajeCanal(uuid.New(), usuarioID, canalID, contenido, at, uuid.Nil)
+		require.NoError(t, err)
+		if padre != nil {
+			require.NoError(t, m.ResponderA(padre))
+		}
+		require.NoError(t, mensajeDAO.Crear(ctx, dbPool, m))
+		return m
+	}
+	conHilo := crear("con hilo", base, nil)
+	sinHilo := crear("sin hilo", base.Add(time.Second), nil)
+	primera := crear("primera", base.Add(time.Minute), conHilo)
+	crear("segunda", base.Add(2*time.Minute), conHilo)
+	borrada := crear("borrada", base.Add(3*time.Minute), conHilo)
+	require.NoError(t, borrada.Eliminar(base.Add(4*time.Minute)))
+	require.NoError(t, mensajeDAO.Actualizar(ctx, dbPool, borrada))
+
+	hilo, err := mensajeDAO.BuscarResumenHilo(ctx, dbPool, conHilo.ID())
+	require.NoError(t, err)
+	require.NotNil(t, hilo)
+	assert.Equal(t, conHilo.ID(), hilo.Raiz().ID())
+	assert.Equal(t, 2, hilo.Respuestas())
+	assert.True(t, hilo.UltimaRespuesta().Equal(base.Add(2*time.Minute)), "última respuesta %v", hilo.UltimaRespuesta())
+
+	vacio, err := mensajeDAO.BuscarResumenHilo(ctx, dbPool, sinHilo.ID())
+	require.NoError(t, err)
+	require.NotNil(t, vacio)
+	assert.Zero(t, vacio.Respuestas())
+	assert.True(t, vacio.UltimaRespuesta().IsZero())
+
+	inexistente, err := mensajeDAO.BuscarResumenHilo(ctx, dbPool, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, inexistente)
+
+	hilos, err := mensajeDAO.BuscarHilosPorCanalID(ctx, dbPool, canalID, model.ConsultaPagina{})
+	require.NoError(t, err)
+	require.Len(t, hilos.Elementos, 1)
+	assert.Equal(t, conHilo.ID(), hilos.Elementos[0].Raiz().ID())
+
+	// Las respuestas, incluida la eliminada, se recorren en orden cronológico
+	consulta, err := model.NewConsultaPagina("", model.PaginaPosteriores, 2)
+	require.NoError(t, err)
+	respuestas, err := mensajeDAO.BuscarRespuestas(ctx, dbPool, conHilo.ID(), consulta)
+	require.NoError(t, err)
+	require.Len(t, respuestas.Elementos, 2)
+	assert.Equal(t, primera.ID(), respuestas.Elementos[0].ID())
+	assert.True(t, respuestas.HayPosteriores)
+
+	// Borrar la raíz borra sus respuestas
+	require.NoError(t, mensajeDAO.Eliminar(ctx, dbPool, conHilo.ID()))
+	respuesta, err := mensajeDAO.BuscarPorID(ctx, dbPool, primera.ID())
+	require.NoError(t, err)
+	assert.Nil(t, respuesta)
+}
@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// IndiceMensajes es un índice invertido en memoria del contenido de los mensajes no
+// eliminados: para cada término de model.TerminosBusqueda guarda los IDs de los mensajes
+// que lo contienen. Sustituye al índice FULLTEXT de MySQL en SQLite, que no lo tiene.
+// Se carga desde la base de datos en la primera búsqueda y a partir de entonces debe
+// recibir cada alta, cambio o borrado de mensajes.
+type IndiceMensajes struct {
+	mu       sync.RWMutex
+	cargado  bool
+	mensajes map[string]map[uuid.UUID]struct{} // término -> IDs de mensajes
+	terminos map[uuid.UUID][]string            // ID de mensaje -> términos indexados
+}
+
+// NuevoIndiceMensajes crea un índice vacío, sin cargar
+func NuevoIndiceMensajes() *IndiceMensajes {
+	return &IndiceMensajes{
+		mensajes: make(map[string]map[uuid.UUID]struct{}),
+		terminos: make(map[uuid.UUID][]string),
+	}
+}
+
+// Cargar indexa los mensajes no eliminados de la base de datos si el índice aún no se
+// ha cargado
+func (i *IndiceMensajes) Cargar(ctx context.Context, q Querier) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.cargado {
+		return nil
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT id, contenido FROM mensaje_servidor WHERE eliminado_at IS NULL`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idStr, contenido string
+		if err := rows.Scan(&idStr, &contenido); err != nil {
+			return err
+		}
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return err
+		}
+		i.indexar(id, contenido)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	i.cargado = true
+	return nil
+}
+
+// Indexar sustituye el contenido indexado de un mensaje; un mensaje eliminado se quita
+// del índice. No hace nada mientras el índice no se haya cargado.
+func (i *IndiceMensajes) Indexar(m *model.MensajeServidor) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.cargado {
+		return
+	}
+	i.quitar(m.ID())
+	if !m.Eliminado() {
+		i.indexar(m.ID(), m.Contenido())
+	}
+}
+
+// Quitar elimina un mensaje del índice
+func (i *IndiceMensajes) Quitar(id uuid.UUID) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.quitar(id)
+}
+
+// Buscar devuelve los IDs de los mensajes que contienen todos los términos. Nunca
+// devuelve nil, para que FiltroBusquedaMensajes.Candidatos siempre restrinja.
+func (i *IndiceMensajes) Buscar(terminos []string) []uuid.UUID {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	ids := []uuid.UUID{}
+	if len(terminos) == 0 {
+		return ids
+	}
+	// Se recorre la lista más corta y se comprueba en las demás
+	menor := i.mensajes[terminos[0]]
+	for _, t := range terminos[1:] {
+		if len(i.mensajes[t]) < len(menor) {
+			menor = i.mensajes[t]
+		}
+	}
+	for id := range menor {
+		todos := true
+		for _, t := range terminos {
+			if _, ok := i.mensajes[t][id]; !ok {
+				todos = false
+				break
+			}
+		}
+		if todos {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// indexar añade los términos del contenido de un mensaje; requiere el cerrojo de escritura
+func (i *IndiceMensajes) indexar(id uuid.UUID, contenido string) {
+	terminos := model.TerminosBusqueda(contenido)
+	for _, t := range terminos {
+		if i.mensajes[t] == nil {
+			i.mensajes[t] = make(map[uuid.UUID]struct{})
+		}
+		i.mensajes[t][id] = struct{}{}
+	}
+	i.terminos[id] = terminos
+}
+
+// quitar elimina los términos indexados de un mensaje; requiere el cerrojo de escritura
+func (i *IndiceMensajes) quitar(id uuid.UUID) {
+	for _, t := range i.terminos[id] {
+		delete(i.mensajes[t], id)
+		if len(i.mensajes[t]) == 0 {
+			delete(i.mensajes, t)
+		}
+	}
+	delete(i.terminos, id)
+}
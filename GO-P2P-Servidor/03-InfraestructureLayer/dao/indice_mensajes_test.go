@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestBuscarMensajes_Visibilidad comprueba que la búsqueda con el índice en memoria solo
+// devuelve los mensajes de los canales y chats privados del usuario y sus directos, y
+// que el índice sigue los cambios de contenido y los borrados lógicos
+func TestBuscarMensajes_Visibilidad(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	ahora := time.Now().UTC().Truncate(time.Second)
+
+	anaID, betoID, carlaID := uuid.New(), uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{anaID, betoID, carlaID} {
+		crearUsuarioPrueba(t, dbPool, id)
+	}
+
+	canalID, ajenoID := uuid.New(), uuid.New()
+	for i, id := range []uuid.UUID{canalID, ajenoID} {
+		canal, err := model.NewCanalServidor(id, "busqueda"+string(rune('a'+i)), "Canal de búsqueda", model.CanalPublico)
+		require.NoError(t, err)
+		require.NoError(t, NuevoCanalDAO().Crear(ctx, dbPool, canal))
+	}
+	miembro, err := model.NewCanalMiembro(canalID, anaID, "MEMBER")
+	require.NoError(t, err)
+	require.NoError(t, NuevoCanalMiembroDAO().Guardar(ctx, dbPool, miembro))
+
+	chatID, chatAjenoID := uuid.New(), uuid.New()
+	for id, participantes := range map[uuid.UUID][]uuid.UUID{chatID: {anaID, carlaID}, chatAjenoID: {betoID, carlaID}} {
+		chat, err := model.NewChatPrivado(id)
+		require.NoError(t, err)
+		require.NoError(t, NuevoChatPrivadoDAO().Guardar(ctx, dbPool, chat))
+		for _, usuarioID := range participantes {
+			participante, err := model.NewChatPrivadoUsuario(id, usuarioID)
+			require.NoError(t, err)
+			require.NoError(t, NuevoChatPrivadoUsuarioDAO().Guardar(ctx, dbPool, participante))
+		}
+	}
+
+	mensajeDAO := NuevoMensajeDAO()
+	crear := func(m *model.MensajeServidor, err error) *model.MensajeServidor {
+		t.Helper()
+		require.NoError(t, err)
+		require.NoError(t, mensajeDAO.Crear(ctx, dbPool, m))
+		return m
+	}
+	enCanal := crear(model.NewMensajeCanal(uuid.New(), betoID, canalID, "Presupuesto del canal", ahora, uuid.Nil))
+	crear(model.NewMensajeCanal(uuid.New(), betoID, ajenoID, "Presupuesto secreto", ahora, uuid.Nil))
+	enChat := crear(model.NewMensajeChatPrivado(uuid.New(), carlaID, chatID, "presupuesto del chat", ahora.Add(time.Second), uuid.Nil))
+	crear(model.NewMensajeChatPrivado(uuid.New(), carlaID, chatAjenoID, "presupuesto ajeno", ahora, uuid.Nil))
+	directo := crear(model.NewMensajeDirecto(uuid.New(), carlaID, anaID, "¿Viste el PRESUPUESTO?", ahora.Add(2*time.Second), uuid.Nil))
+	crear(model.NewMensajeDirecto(uuid.New(), betoID, carlaID, "presupuesto privado", ahora, uuid.Nil))
+	borrado := crear(model.NewMensajeCanal(uuid.New(), anaID, canalID, "presupuesto borrado", ahora, uuid.Nil))
+
+	indice := NuevoIndiceMensajes()
+	require.NoError(t, indice.Cargar(ctx, dbPool))
+	buscar := func(texto string, filtro model.FiltroBusqueda) []uuid.UUID {
+		t.Helper()
+		terminos := model.TerminosBusqueda(texto)
+		pagina, err := mensajeDAO.Buscar(ctx, dbPool, FiltroBusquedaMensajes{
+			UsuarioID:  anaID,
+			Terminos:   terminos,
+			Candidatos: indice.Buscar(terminos),
+			Filtro:     filtro,
+		}, model.ConsultaPagina{})
+		require.NoError(t, err)
+		return idsMensajes(pagina.Elementos)
+	}
+
+	assert.ElementsMatch(t, []uuid.UUID{enCanal.ID(), enChat.ID(), directo.ID(), borrado.ID()}, buscar("presupuesto", model.FiltroBusqueda{}))
+	assert.Equal(t, []uuid.UUID{enChat.ID()}, buscar("presupuesto chat", model.FiltroBusqueda{}))
+	assert.Empty(t, buscar("presupuesto inexistente", model.FiltroBusqueda{}))
+	assert.ElementsMatch(t, []uuid.UUID{enCanal.ID(), borrado.ID()}, buscar("presupuesto", model.FiltroBusqueda{CanalID: canalID}))
+	assert.Equal(t, []uuid.UUID{enChat.ID(), directo.ID()}, buscar("presupuesto", model.FiltroBusqueda{RemitenteID: carlaID}))
+	assert.Equal(t, []uuid.UUID{directo.ID()}, buscar("presupuesto", model.FiltroBusqueda{Desde: ahora.Add(2 * time.Second)}))
+	assert.Empty(t, buscar("presupuesto", model.FiltroBusqueda{ConAdjunto: true}))
+
+	// Las ediciones y los borrados lógicos se reflejan en el índice
+	require.NoError(t, enCanal.Editar("Gastos del canal", ahora.Add(time.Minute)))
+	require.NoError(t, borrado.Eliminar(ahora.Add(time.Minute)))
+	for _, m := range []*model.MensajeServidor{enCanal, borrado} {
+		require.NoError(t, mensajeDAO.Actualizar(ctx, dbPool, m))
+		indice.Indexar(m)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{enChat.ID(), directo.ID()}, buscar("presupuesto", model.FiltroBusqueda{}))
+	assert.Equal(t, []uuid.UUID{enCanal.ID()}, buscar("gastos", model.FiltroBusqueda{}))
+}
@@ -1,8 +1,8 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
-	"pool"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,21 +10,20 @@ import (
 )
 
 // InvitacionCanalDAO maneja las operaciones de base de datos para entidades InvitacionCanal
-type InvitacionCanalDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type InvitacionCanalDAO struct{}
 
 // NuevoInvitacionCanalDAO crea una nueva instancia de InvitacionCanalDAO
-func NuevoInvitacionCanalDAO(dbPool *pool.DBConnectionPool) *InvitacionCanalDAO {
-	return &InvitacionCanalDAO{dbPool: dbPool}
+func NuevoInvitacionCanalDAO() *InvitacionCanalDAO {
+	return &InvitacionCanalDAO{}
 }
 
 // Guardar persiste una invitación de canal en la base de datos
-func (dao *InvitacionCanalDAO) Guardar(invitacion *model.InvitacionCanal) error {
-	query := `INSERT INTO invitaciones_canal (id, canal_id, destinatario_id, estado, fecha_envio)
+func (dao *InvitacionCanalDAO) Guardar(ctx context.Context, q Querier, invitacion *model.InvitacionCanal) error {
+	query := `INSERT INTO invitacion_canal (id, canal_id, destinatario_id, estado, fecha_envio)
               VALUES (?, ?, ?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		invitacion.ID().String(),
 		invitacion.CanalID().String(),
@@ -37,20 +36,20 @@ func (dao *InvitacionCanalDAO) Guardar(invitacion *model.InvitacionCanal) error
 }
 
 // BuscarPorID recupera una invitación de canal por su ID
-func (dao *InvitacionCanalDAO) BuscarPorID(id uuid.UUID) (*model.InvitacionCanal, error) {
+func (dao *InvitacionCanalDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.InvitacionCanal, error) {
 	query := `SELECT id, canal_id, destinatario_id, estado, fecha_envio
-              FROM invitaciones_canal WHERE id = ?`
+              FROM invitacion_canal WHERE id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearInvitacionCanal(row)
 }
 
 // BuscarPorCanalID recupera todas las invitaciones para un canal específico
-func (dao *InvitacionCanalDAO) BuscarPorCanalID(canalID uuid.UUID) ([]*model.InvitacionCanal, error) {
+func (dao *InvitacionCanalDAO) BuscarPorCanalID(ctx context.Context, q Querier, canalID uuid.UUID) ([]*model.InvitacionCanal, error) {
 	query := `SELECT id, canal_id, destinatario_id, estado, fecha_envio
-              FROM invitaciones_canal WHERE canal_id = ?`
+              FROM invitacion_canal WHERE canal_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, canalID.String())
+	rows, err := q.QueryContext(ctx, query, canalID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -60,11 +59,11 @@ func (dao *InvitacionCanalDAO) BuscarPorCanalID(canalID uuid.UUID) ([]*model.Inv
 }
 
 // BuscarPorDestinatarioID recupera todas las invitaciones para un destinatario específico
-func (dao *InvitacionCanalDAO) BuscarPorDestinatarioID(destinatarioID uuid.UUID) ([]*model.InvitacionCanal, error) {
+func (dao *InvitacionCanalDAO) BuscarPorDestinatarioID(ctx context.Context, q Querier, destinatarioID uuid.UUID) ([]*model.InvitacionCanal, error) {
 	query := `SELECT id, canal_id, destinatario_id, estado, fecha_envio
-              FROM invitaciones_canal WHERE destinatario_id = ?`
+              FROM invitacion_canal WHERE destinatario_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, destinatarioID.String())
+	rows, err := q.QueryContext(ctx, query, destinatarioID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -74,11 +73,11 @@ func (dao *InvitacionCanalDAO) BuscarPorDestinatarioID(destinatarioID uuid.UUID)
 }
 
 // BuscarPorEstado recupera todas las invitaciones con un estado específico
-func (dao *InvitacionCanalDAO) BuscarPorEstado(estado model.EstadoInvitacion) ([]*model.InvitacionCanal, error) {
+func (dao *InvitacionCanalDAO) BuscarPorEstado(ctx context.Context, q Querier, estado model.EstadoInvitacion) ([]*model.InvitacionCanal, error) {
 	query := `SELECT id, canal_id, destinatario_id, estado, fecha_envio
-              FROM invitaciones_canal WHERE estado = ?`
+              FROM invitacion_canal WHERE estado = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, string(estado))
+	rows, err := q.QueryContext(ctx, query, string(estado))
 	if err != nil {
 		return nil, err
 	}
@@ -88,12 +87,13 @@ func (dao *InvitacionCanalDAO) BuscarPorEstado(estado model.EstadoInvitacion) ([
 }
 
 // Actualizar actualiza el estado de una invitación de canal
-func (dao *InvitacionCanalDAO) Actualizar(invitacion *model.InvitacionCanal) error {
-	query := `UPDATE invitaciones_canal 
+func (dao *InvitacionCanalDAO) Actualizar(ctx context.Context, q Querier, invitacion *model.InvitacionCanal) error {
+	query := `UPDATE invitacion_canal 
               SET estado = ? 
               WHERE id = ?`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		string(invitacion.Estado()),
 		invitacion.ID().String(),
@@ -103,9 +103,9 @@ func (dao *InvitacionCanalDAO) Actualizar(invitacion *model.InvitacionCanal) err
 }
 
 // Eliminar elimina una invitación de canal
-func (dao *InvitacionCanalDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM invitaciones_canal WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *InvitacionCanalDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM invitacion_canal WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
@@ -14,11 +14,13 @@ func TestInvitacionCanalDAO_Crear(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal primero ya que es necesario para la relación
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	destinatarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, destinatarioID)
 
 	// Crear canal en la base de datos
 	canal, err := model.NewCanalServidor(canalID, "Canal para Invitaciones", "Canal para probar invitaciones", model.CanalPublico)
@@ -26,13 +28,13 @@ func TestInvitacionCanalDAO_Crear(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de invitaciones
-	invitacionDAO := NuevoInvitacionCanalDAO(dbPool)
+	invitacionDAO := NuevoInvitacionCanalDAO()
 
 	// Crear una invitación
 	id := uuid.New()
@@ -45,13 +47,13 @@ func TestInvitacionCanalDAO_Crear(t *testing.T) {
 	}
 
 	// Ejecutar operación a probar
-	err = invitacionDAO.Guardar(invitacion)
+	err = invitacionDAO.Guardar(ctx, dbPool, invitacion)
 	if err != nil {
 		t.Fatalf("Error guardando invitación: %v", err)
 	}
 
 	// Verificar que se guardó correctamente
-	invitacionRecuperada, err := invitacionDAO.BuscarPorID(id)
+	invitacionRecuperada, err := invitacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error recuperando invitación: %v", err)
 	}
@@ -80,7 +82,7 @@ func TestInvitacionCanalDAO_Crear(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM invitaciones_canal WHERE id = ?",
+		"DELETE FROM invitacion_canal WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -89,7 +91,7 @@ func TestInvitacionCanalDAO_Crear(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -102,11 +104,13 @@ func TestInvitacionCanalDAO_ObtenerPorID(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal para las pruebas
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	destinatarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, destinatarioID)
 
 	// Crear canal en la base de datos
 	canal, err := model.NewCanalServidor(canalID, "Canal para BuscarPorID", "Canal para probar BuscarPorID", model.CanalPublico)
@@ -114,13 +118,13 @@ func TestInvitacionCanalDAO_ObtenerPorID(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de invitaciones
-	invitacionDAO := NuevoInvitacionCanalDAO(dbPool)
+	invitacionDAO := NuevoInvitacionCanalDAO()
 
 	// Crear y guardar una invitación
 	id := uuid.New()
@@ -132,13 +136,13 @@ func TestInvitacionCanalDAO_ObtenerPorID(t *testing.T) {
 		t.Fatalf("Error creando objeto invitación: %v", err)
 	}
 
-	err = invitacionDAO.Guardar(invitacion)
+	err = invitacionDAO.Guardar(ctx, dbPool, invitacion)
 	if err != nil {
 		t.Fatalf("Error guardando invitación: %v", err)
 	}
 
 	// Ejecutar operación a probar
-	invitacionRecuperada, err := invitacionDAO.BuscarPorID(id)
+	invitacionRecuperada, err := invitacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error buscando invitación por ID: %v", err)
 	}
@@ -168,7 +172,7 @@ func TestInvitacionCanalDAO_ObtenerPorID(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM invitaciones_canal WHERE id = ?",
+		"DELETE FROM invitacion_canal WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -177,7 +181,7 @@ func TestInvitacionCanalDAO_ObtenerPorID(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -190,11 +194,13 @@ func TestInvitacionCanalDAO_ObtenerPorDestinatario(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal para las pruebas
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	destinatarioID := uuid.New() // Este será el destinatario para todas las invitaciones
+	crearUsuarioPrueba(t, dbPool, destinatarioID)
 
 	// Crear canal en la base de datos
 	canal, err := model.NewCanalServidor(canalID, "Canal para Destinatario", "Canal para probar BuscarPorDestinatarioID", model.CanalPublico)
@@ -202,13 +208,13 @@ func TestInvitacionCanalDAO_ObtenerPorDestinatario(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de invitaciones
-	invitacionDAO := NuevoInvitacionCanalDAO(dbPool)
+	invitacionDAO := NuevoInvitacionCanalDAO()
 
 	// Crear varias invitaciones para el mismo destinatario
 	id1 := uuid.New()
@@ -228,18 +234,18 @@ func TestInvitacionCanalDAO_ObtenerPorDestinatario(t *testing.T) {
 	}
 
 	// Guardar las invitaciones
-	err = invitacionDAO.Guardar(invitacion1)
+	err = invitacionDAO.Guardar(ctx, dbPool, invitacion1)
 	if err != nil {
 		t.Fatalf("Error guardando invitación 1: %v", err)
 	}
 
-	err = invitacionDAO.Guardar(invitacion2)
+	err = invitacionDAO.Guardar(ctx, dbPool, invitacion2)
 	if err != nil {
 		t.Fatalf("Error guardando invitación 2: %v", err)
 	}
 
 	// Ejecutar operación a probar
-	invitaciones, err := invitacionDAO.BuscarPorDestinatarioID(destinatarioID)
+	invitaciones, err := invitacionDAO.BuscarPorDestinatarioID(ctx, dbPool, destinatarioID)
 	if err != nil {
 		t.Fatalf("Error buscando invitaciones por destinatario: %v", err)
 	}
@@ -277,7 +283,7 @@ func TestInvitacionCanalDAO_ObtenerPorDestinatario(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM invitaciones_canal WHERE id IN (?, ?)",
+		"DELETE FROM invitacion_canal WHERE id IN (?, ?)",
 		id1.String(), id2.String(),
 	)
 	if err != nil {
@@ -286,7 +292,7 @@ func TestInvitacionCanalDAO_ObtenerPorDestinatario(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -299,11 +305,13 @@ func TestInvitacionCanalDAO_Actualizar(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal para las pruebas
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	destinatarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, destinatarioID)
 
 	// Crear canal en la base de datos
 	canal, err := model.NewCanalServidor(canalID, "Canal para Actualizar", "Canal para probar Actualizar", model.CanalPublico)
@@ -311,13 +319,13 @@ func TestInvitacionCanalDAO_Actualizar(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de invitaciones
-	invitacionDAO := NuevoInvitacionCanalDAO(dbPool)
+	invitacionDAO := NuevoInvitacionCanalDAO()
 
 	// Crear y guardar una invitación con estado inicial
 	id := uuid.New()
@@ -329,13 +337,13 @@ func TestInvitacionCanalDAO_Actualizar(t *testing.T) {
 		t.Fatalf("Error creando objeto invitación: %v", err)
 	}
 
-	err = invitacionDAO.Guardar(invitacion)
+	err = invitacionDAO.Guardar(ctx, dbPool, invitacion)
 	if err != nil {
 		t.Fatalf("Error guardando invitación: %v", err)
 	}
 
 	// Verificar que se guardó con el estado inicial
-	invitacionInicial, err := invitacionDAO.BuscarPorID(id)
+	invitacionInicial, err := invitacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error recuperando invitación inicial: %v", err)
 	}
@@ -352,13 +360,13 @@ func TestInvitacionCanalDAO_Actualizar(t *testing.T) {
 	}
 
 	// Ejecutar operación a probar
-	err = invitacionDAO.Actualizar(invitacionActualizada)
+	err = invitacionDAO.Actualizar(ctx, dbPool, invitacionActualizada)
 	if err != nil {
 		t.Fatalf("Error actualizando invitación: %v", err)
 	}
 
 	// Verificar que se actualizó correctamente
-	invitacionFinal, err := invitacionDAO.BuscarPorID(id)
+	invitacionFinal, err := invitacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error recuperando invitación actualizada: %v", err)
 	}
@@ -370,7 +378,7 @@ func TestInvitacionCanalDAO_Actualizar(t *testing.T) {
 	// Limpiar datos de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM invitaciones_canal WHERE id = ?",
+		"DELETE FROM invitacion_canal WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -379,7 +387,7 @@ func TestInvitacionCanalDAO_Actualizar(t *testing.T) {
 
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
@@ -392,11 +400,13 @@ func TestInvitacionCanalDAO_Eliminar(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear un canal para las pruebas
-	canalDAO := NuevoCanalDAO(dbPool)
+	canalDAO := NuevoCanalDAO()
 	canalID := uuid.New()
 	destinatarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, destinatarioID)
 
 	// Crear canal en la base de datos
 	canal, err := model.NewCanalServidor(canalID, "Canal para Eliminar", "Canal para probar Eliminar", model.CanalPublico)
@@ -404,13 +414,13 @@ func TestInvitacionCanalDAO_Eliminar(t *testing.T) {
 		t.Fatalf("Error creando objeto canal: %v", err)
 	}
 
-	err = canalDAO.Crear(canal)
+	err = canalDAO.Crear(ctx, dbPool, canal)
 	if err != nil {
 		t.Fatalf("Error creando canal en la base de datos: %v", err)
 	}
 
 	// Crear instancia del DAO de invitaciones
-	invitacionDAO := NuevoInvitacionCanalDAO(dbPool)
+	invitacionDAO := NuevoInvitacionCanalDAO()
 
 	// Crear y guardar una invitación
 	id := uuid.New()
@@ -422,13 +432,13 @@ func TestInvitacionCanalDAO_Eliminar(t *testing.T) {
 		t.Fatalf("Error creando objeto invitación: %v", err)
 	}
 
-	err = invitacionDAO.Guardar(invitacion)
+	err = invitacionDAO.Guardar(ctx, dbPool, invitacion)
 	if err != nil {
 		t.Fatalf("Error guardando invitación: %v", err)
 	}
 
 	// Verificar que se guardó correctamente
-	invitacionGuardada, err := invitacionDAO.BuscarPorID(id)
+	invitacionGuardada, err := invitacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error recuperando invitación: %v", err)
 	}
@@ -438,13 +448,13 @@ func TestInvitacionCanalDAO_Eliminar(t *testing.T) {
 	}
 
 	// Ejecutar operación a probar
-	err = invitacionDAO.Eliminar(id)
+	err = invitacionDAO.Eliminar(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error eliminando invitación: %v", err)
 	}
 
 	// Verificar que se eliminó correctamente
-	invitacionEliminada, err := invitacionDAO.BuscarPorID(id)
+	invitacionEliminada, err := invitacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error buscando invitación eliminada: %v", err)
 	}
@@ -456,7 +466,7 @@ func TestInvitacionCanalDAO_Eliminar(t *testing.T) {
 	// Limpiar el canal de prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM canales WHERE id = ?",
+		"DELETE FROM canal_servidor WHERE id = ?",
 		canalID.String(),
 	)
 	if err != nil {
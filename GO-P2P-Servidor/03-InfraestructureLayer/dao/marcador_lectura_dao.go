@@ -0,0 +1,113 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// MarcadorLecturaDAO maneja las operaciones de base de datos para los marcadores del
+// último mensaje leído por cada usuario en cada conversación
+type MarcadorLecturaDAO struct{}
+
+// NuevoMarcadorLecturaDAO crea una nueva instancia de MarcadorLecturaDAO
+func NuevoMarcadorLecturaDAO() *MarcadorLecturaDAO {
+	return &MarcadorLecturaDAO{}
+}
+
+// Guardar crea o sustituye el marcador de un usuario en una conversación
+func (dao *MarcadorLecturaDAO) Guardar(ctx context.Context, q Querier, marcador *model.MarcadorLectura) error {
+	query := `REPLACE INTO marcador_lectura (usuario_id, conversacion_id, mensaje_id,
+              mensaje_timestamp, actualizado_at)
+              VALUES (?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		marcador.UsuarioID().String(),
+		marcador.ConversacionID().String(),
+		marcador.MensajeID().String(),
+		marcador.Timestamp(),
+		marcador.ActualizadoAt(),
+	)
+
+	return err
+}
+
+// BuscarPorClave recupera el marcador de un usuario en una conversación. Devuelve nil si
+// el usuario no ha leído nada en ella.
+func (dao *MarcadorLecturaDAO) BuscarPorClave(ctx context.Context, q Querier, usuarioID, conversacionID uuid.UUID) (*model.MarcadorLectura, error) {
+	query := `SELECT usuario_id, conversacion_id, mensaje_id, mensaje_timestamp, actualizado_at
+              FROM marcador_lectura
+              WHERE usuario_id = ? AND conversacion_id = ?`
+
+	var (
+		usuarioIDStr, conversacionIDStr, mensajeIDStr string
+		timestamp, actualizadoAt                      time.Time
+	)
+	err := q.QueryRowContext(ctx, query, usuarioID.String(), conversacionID.String()).Scan(
+		&usuarioIDStr, &conversacionIDStr, &mensajeIDStr, &timestamp, &actualizadoAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mensajeID, err := uuid.Parse(mensajeIDStr)
+	if err != nil {
+		return nil, err
+	}
+	return model.RestaurarMarcadorLectura(usuarioID, conversacionID, mensajeID, timestamp, actualizadoAt)
+}
+
+// ContarNoLeidos devuelve, para cada conversación del usuario con mensajes sin leer, el
+// número de mensajes no eliminados de otros usuarios posteriores a su marcador, o todos
+// si no tiene marcador. Las conversaciones son los canales de los que es miembro, los
+// chats privados en los que participa y, para los mensajes directos recibidos, el
+// remitente.
+func (dao *MarcadorLecturaDAO) ContarNoLeidos(ctx context.Context, q Querier, usuarioID uuid.UUID) (map[uuid.UUID]int, error) {
+	query := `SELECT COALESCE(m.canal_id, m.chat_privado_id, m.remitente_id), COUNT(*)
+              FROM mensaje_servidor m
+              LEFT JOIN marcador_lectura ml ON ml.usuario_id = ?
+              AND ml.conversacion_id = COALESCE(m.canal_id, m.chat_privado_id, m.remitente_id)
+              WHERE m.eliminado_at IS NULL AND m.remitente_id <> ?
+              AND (m.destino_usuario_id = ?
+              OR m.canal_id IN (SELECT canal_id FROM canal_miembro WHERE usuario_id = ?)
+              OR m.chat_privado_id IN (SELECT chat_id FROM chat_privado_usuario WHERE usuario_id = ?))
+              AND (ml.mensaje_id IS NULL OR m.timestamp > ml.mensaje_timestamp
+              OR (m.timestamp = ml.mensaje_timestamp AND m.id > ml.mensaje_id))
+              GROUP BY COALESCE(m.canal_id, m.chat_privado_id, m.remitente_id)`
+	id := usuarioID.String()
+
+	rows, err := q.QueryContext(ctx, query, id, id, id, id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conteos := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var (
+			conversacionIDStr string
+			total             int
+		)
+		if err := rows.Scan(&conversacionIDStr, &total); err != nil {
+			return nil, err
+		}
+		conversacionID, err := uuid.Parse(conversacionIDStr)
+		if err != nil {
+			return nil, err
+		}
+		conteos[conversacionID] = total
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return conteos, nil
+}
@@ -0,0 +1,124 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// columnasMencion son las columnas que leen todas las consultas de mencion, en el
+// orden que espera escanearMenciones
+const columnasMencion = `id, usuario_id, mensaje_id, canal_id, remitente_id, tipo, fecha, notificacion_id`
+
+// MencionDAO maneja las operaciones de base de datos para el feed de menciones de
+// cada usuario
+type MencionDAO struct{}
+
+// NuevoMencionDAO crea una nueva instancia de MencionDAO
+func NuevoMencionDAO() *MencionDAO {
+	return &MencionDAO{}
+}
+
+// Crear persiste una mención. Falla si el usuario ya tiene una mención del mismo mensaje.
+func (dao *MencionDAO) Crear(ctx context.Context, q Querier, m *model.Mencion) error {
+	query := `INSERT INTO mencion (` + columnasMencion + `)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		m.ID().String(),
+		m.UsuarioID().String(),
+		m.MensajeID().String(),
+		m.CanalID().String(),
+		m.RemitenteID().String(),
+		string(m.Tipo()),
+		m.Fecha(),
+		nullableUUID(m.NotificacionID()),
+	)
+
+	return err
+}
+
+// BuscarPorClave recupera la mención de un usuario en un mensaje; devuelve nil si no existe
+func (dao *MencionDAO) BuscarPorClave(ctx context.Context, q Querier, usuarioID, mensajeID uuid.UUID) (*model.Mencion, error) {
+	query := `SELECT ` + columnasMencion + `
+              FROM mencion WHERE usuario_id = ? AND mensaje_id = ?`
+
+	rows, err := q.QueryContext(ctx, query, usuarioID.String(), mensajeID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	menciones, err := dao.escanearMenciones(rows)
+	if err != nil || len(menciones) == 0 {
+		return nil, err
+	}
+	return menciones[0], nil
+}
+
+// BuscarPaginaPorUsuarioID recupera una página del feed de menciones de un usuario,
+// ordenada por (fecha, id)
+func (dao *MencionDAO) BuscarPaginaPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.Mencion], error) {
+	query := `SELECT ` + columnasMencion + `
+              FROM mencion WHERE usuario_id = ?`
+
+	clausula, args := clausulaPagina("fecha", "id", consulta)
+	rows, err := q.QueryContext(ctx, query+clausula, append([]interface{}{usuarioID.String()}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	menciones, err := dao.escanearMenciones(rows)
+	if err != nil {
+		return nil, err
+	}
+	return model.NuevaPagina(menciones, consulta, func(m *model.Mencion) model.Cursor {
+		return model.NewCursor(m.Fecha(), m.ID())
+	}), nil
+}
+
+// escanearMenciones convierte las filas leídas en menciones
+func (dao *MencionDAO) escanearMenciones(rows *sql.Rows) ([]*model.Mencion, error) {
+	var menciones []*model.Mencion
+	for rows.Next() {
+		var (
+			idStr, usuarioIDStr, mensajeIDStr, canalIDStr, remitenteIDStr, tipo string
+			fecha                                                               time.Time
+			notificacionIDStr                                                   sql.NullString
+		)
+		if err := rows.Scan(
+			&idStr, &usuarioIDStr, &mensajeIDStr, &canalIDStr, &remitenteIDStr, &tipo, &fecha, &notificacionIDStr,
+		); err != nil {
+			return nil, err
+		}
+
+		ids := make([]uuid.UUID, 5)
+		for i, s := range []string{idStr, usuarioIDStr, mensajeIDStr, canalIDStr, remitenteIDStr} {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = id
+		}
+		notificacionID, err := parseNullableUUID(notificacionIDStr)
+		if err != nil {
+			return nil, err
+		}
+		m, err := model.NewMencion(ids[0], ids[1], ids[2], ids[3], ids[4], model.TipoMencion(tipo), fecha, notificacionID)
+		if err != nil {
+			return nil, err
+		}
+		menciones = append(menciones, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return menciones, nil
+}
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestMenciones_FeedYSilencios comprueba que cada usuario tiene como mucho una mención
+// por mensaje, que su feed se pagina en orden cronológico y que volver a configurar un
+// silencio sustituye el anterior
+func TestMenciones_FeedYSilencios(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	ahora := time.Now().UTC().Truncate(time.Second)
+	mencionDAO := NuevoMencionDAO()
+	silencioDAO := NuevoSilencioMencionDAO()
+
+	anaID, betoID, canalID := uuid.New(), uuid.New(), uuid.New()
+	crearUsuarioPrueba(t, dbPool, anaID)
+	crearUsuarioPrueba(t, dbPool, betoID)
+
+	var mensajeIDs []uuid.UUID
+	for i := 0; i < 3; i++ {
+		mensajeID := uuid.New()
+		m, err := model.NewMencion(uuid.New(), anaID, mensajeID, canalID, betoID, model.MencionUsuario, ahora.Add(time.Duration(i)*time.Minute), uuid.New())
+		require.NoError(t, err)
+		require.NoError(t, mencionDAO.Crear(ctx, dbPool, m))
+		mensajeIDs = append(mensajeIDs, mensajeID)
+	}
+	repetida, err := model.NewMencion(uuid.New(), anaID, mensajeIDs[0], canalID, betoID, model.MencionCanal, ahora, uuid.Nil)
+	require.NoError(t, err)
+	assert.Error(t, mencionDAO.Crear(ctx, dbPool, repetida))
+
+	consulta, err := model.NewConsultaPagina("", model.PaginaPosteriores, 2)
+	require.NoError(t, err)
+	pagina, err := mencionDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, anaID, consulta)
+	require.NoError(t, err)
+	require.Len(t, pagina.Elementos, 2)
+	assert.Equal(t, mensajeIDs[0], pagina.Elementos[0].MensajeID())
+	assert.True(t, pagina.HayPosteriores)
+	consulta, err = model.NewConsultaPagina(pagina.Siguiente, model.PaginaPosteriores, 2)
+	require.NoError(t, err)
+	pagina, err = mencionDAO.BuscarPaginaPorUsuarioID(ctx, dbPool, anaID, consulta)
+	require.NoError(t, err)
+	require.Len(t, pagina.Elementos, 1)
+	assert.Equal(t, mensajeIDs[2], pagina.Elementos[0].MensajeID())
+
+	leida, err := mencionDAO.BuscarPorClave(ctx, dbPool, anaID, mensajeIDs[1])
+	require.NoError(t, err)
+	require.NotNil(t, leida)
+	assert.Equal(t, model.MencionUsuario, leida.Tipo())
+	leida, err = mencionDAO.BuscarPorClave(ctx, dbPool, betoID, mensajeIDs[1])
+	require.NoError(t, err)
+	assert.Nil(t, leida)
+
+	// La preferencia general usa el UUID nulo como canal
+	general, err := model.NewSilencioMenciones(anaID, uuid.Nil, model.SilencioCanal, time.Time{}, ahora)
+	require.NoError(t, err)
+	require.NoError(t, silencioDAO.Guardar(ctx, dbPool, general))
+	enCanal, err := model.NewSilencioMenciones(anaID, canalID, model.SilencioTodo, ahora.Add(time.Hour), ahora)
+	require.NoError(t, err)
+	require.NoError(t, silencioDAO.Guardar(ctx, dbPool, enCanal))
+	reactivado, err := model.NewSilencioMenciones(anaID, canalID, model.SilencioNinguno, time.Time{}, ahora.Add(time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, silencioDAO.Guardar(ctx, dbPool, reactivado))
+
+	silencios, err := silencioDAO.BuscarPorUsuarioID(ctx, dbPool, anaID)
+	require.NoError(t, err)
+	require.Len(t, silencios, 2)
+	assert.Equal(t, uuid.Nil, silencios[0].CanalID())
+	assert.Equal(t, model.SilencioNinguno, silencios[1].Nivel())
+	assert.True(t, silencios[1].Hasta().IsZero())
+
+	leido, err := silencioDAO.BuscarPorClave(ctx, dbPool, anaID, canalID)
+	require.NoError(t, err)
+	require.NotNil(t, leido)
+	assert.True(t, leido.Actualizado().Equal(ahora.Add(time.Minute)))
+	leido, err = silencioDAO.BuscarPorClave(ctx, dbPool, betoID, canalID)
+	require.NoError(t, err)
+	assert.Nil(t, leido)
+}
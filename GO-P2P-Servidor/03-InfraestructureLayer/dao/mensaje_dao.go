@@ -1,31 +1,44 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"model"
-	"pool"
 )
 
-// MensajeDAO maneja las operaciones de base de datos para entidades MensajeServidor
-type MensajeDAO struct {
-	dbPool *pool.DBConnectionPool
+// FiltroBusquedaMensajes restringe los mensajes de Buscar. Solo se devuelven mensajes
+// no eliminados que UsuarioID puede ver. El texto se busca con el índice FULLTEXT de
+// MySQL a partir de Terminos o, si Candidatos no es nil, se limita a esos IDs, ya
+// seleccionados por un IndiceMensajes en los backends sin FULLTEXT.
+type FiltroBusquedaMensajes struct {
+	UsuarioID  uuid.UUID
+	Terminos   []string
+	Candidatos []uuid.UUID
+	Filtro     model.FiltroBusqueda
 }
 
+// MensajeDAO maneja las operaciones de base de datos para entidades MensajeServidor
+type MensajeDAO struct{}
+
 // NuevoMensajeDAO crea una nueva instancia de MensajeDAO
-func NuevoMensajeDAO(dbPool *pool.DBConnectionPool) *MensajeDAO {
-	return &MensajeDAO{dbPool: dbPool}
+func NuevoMensajeDAO() *MensajeDAO {
+	return &MensajeDAO{}
 }
 
 // Crear persiste un nuevo mensaje en la base de datos
-func (dao *MensajeDAO) Crear(mensaje *model.MensajeServidor) error {
-	query := `INSERT INTO mensajes (id, remitente_id, destino_usuario_id, canal_id, 
-              chat_privado_id, contenido, timestamp, archivo_id)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-
-	_, err := dao.dbPool.DB().Exec(
+func (dao *MensajeDAO) Crear(ctx context.Context, q Querier, mensaje *model.MensajeServidor) error {
+	query := `INSERT INTO mensaje_servidor (id, remitente_id, destino_usuario_id, canal_id, 
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		mensaje.ID().String(),
 		mensaje.RemitenteID().String(),
@@ -35,66 +48,154 @@ func (dao *MensajeDAO) Crear(mensaje *model.MensajeServidor) error {
 		mensaje.Contenido(),
 		mensaje.Timestamp(),
 		nullableUUID(mensaje.ArchivoID()),
+		nullableHLC(mensaje.Version()),
+		nullableTime(mensaje.EditadoAt()),
+		nullableTime(mensaje.EliminadoAt()),
+		nullableUUID(mensaje.PadreID()),
+	)
+
+	return err
+}
+
+// Actualizar modifica el contenido, el adjunto, la versión y las marcas de edición y
+// borrado de un mensaje existente
+func (dao *MensajeDAO) Actualizar(ctx context.Context, q Querier, mensaje *model.MensajeServidor) error {
+	query := `UPDATE mensaje_servidor SET contenido = ?, archivo_id = ?, version_hlc = ?,
+              editado_at = ?, eliminado_at = ?
+              WHERE id = ?`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		mensaje.Contenido(),
+		nullableUUID(mensaje.ArchivoID()),
+		nullableHLC(mensaje.Version()),
+		nullableTime(mensaje.EditadoAt()),
+		nullableTime(mensaje.EliminadoAt()),
+		mensaje.ID().String(),
 	)
 
 	return err
 }
 
 // BuscarPorID recupera un mensaje por su ID
-func (dao *MensajeDAO) BuscarPorID(id uuid.UUID) (*model.MensajeServidor, error) {
+func (dao *MensajeDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.MensajeServidor, error) {
 	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
-              chat_privado_id, contenido, timestamp, archivo_id 
-              FROM mensajes WHERE id = ?`
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor WHERE id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearMensaje(row)
 }
 
-// BuscarPorCanalID recupera mensajes de un canal específico
-func (dao *MensajeDAO) BuscarPorCanalID(canalID uuid.UUID, limite int) ([]*model.MensajeServidor, error) {
+// BuscarPorCanalID recupera una página de los mensajes de un canal
+func (dao *MensajeDAO) BuscarPorCanalID(ctx context.Context, q Querier, canalID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor WHERE canal_id = ?`
+
+	return dao.buscarPagina(ctx, q, query, consulta, canalID.String())
+}
+
+// BuscarPorChatPrivadoID recupera una página de los mensajes de un chat privado
+func (dao *MensajeDAO) BuscarPorChatPrivadoID(ctx context.Context, q Querier, chatPrivadoID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor WHERE chat_privado_id = ?`
+
+	return dao.buscarPagina(ctx, q, query, consulta, chatPrivadoID.String())
+}
+
+// BuscarMensajesDirectos recupera una página de los mensajes directos entre dos usuarios
+func (dao *MensajeDAO) BuscarMensajesDirectos(ctx context.Context, q Querier, remitenteID, destinatarioID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor 
+              WHERE ((remitente_id = ? AND destino_usuario_id = ?) 
+              OR (remitente_id = ? AND destino_usuario_id = ?))`
+
+	return dao.buscarPagina(ctx, q, query, consulta,
+		remitenteID.String(), destinatarioID.String(),
+		destinatarioID.String(), remitenteID.String())
+}
+
+// BuscarPorRemitenteID recupera una página de los mensajes enviados por un usuario
+func (dao *MensajeDAO) BuscarPorRemitenteID(ctx context.Context, q Querier, remitenteID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor WHERE remitente_id = ?`
+
+	return dao.buscarPagina(ctx, q, query, consulta, remitenteID.String())
+}
+
+// BuscarRespuestas recupera una página de las respuestas del hilo de un mensaje
+func (dao *MensajeDAO) BuscarRespuestas(ctx context.Context, q Querier, padreID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
 	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
-              chat_privado_id, contenido, timestamp, archivo_id 
-              FROM mensajes WHERE canal_id = ? 
-              ORDER BY timestamp DESC LIMIT ?`
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor WHERE padre_id = ?`
+
+	return dao.buscarPagina(ctx, q, query, consulta, padreID.String())
+}
+
+// BuscarHilosPorCanalID recupera una página, ordenada por el mensaje raíz, de los hilos
+// de un canal que tienen alguna respuesta no eliminada
+func (dao *MensajeDAO) BuscarHilosPorCanalID(ctx context.Context, q Querier, canalID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.ResumenHilo], error) {
+	query := consultaHilos + ` WHERE m.canal_id = ? AND m.padre_id IS NULL
+              GROUP BY m.id HAVING COUNT(r.id) > 0`
+	clausula, argsPagina := clausulaPagina("m.timestamp", "m.id", consulta)
 
-	rows, err := dao.dbPool.DB().Query(query, canalID.String(), limite)
+	rows, err := q.QueryContext(ctx, query+clausula, append([]interface{}{canalID.String()}, argsPagina...)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return dao.escanearFilasMensajes(rows)
+	var hilos []*model.ResumenHilo
+	for rows.Next() {
+		hilo, err := dao.escanearResumenHilo(rows)
+		if err != nil {
+			return nil, err
+		}
+		hilos = append(hilos, hilo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return model.NuevaPagina(hilos, consulta, func(h *model.ResumenHilo) model.Cursor {
+		return cursorMensaje(h.Raiz())
+	}), nil
 }
 
-// BuscarPorChatPrivadoID recupera mensajes de un chat privado
-func (dao *MensajeDAO) BuscarPorChatPrivadoID(chatPrivadoID uuid.UUID, limite int) ([]*model.MensajeServidor, error) {
-	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
-              chat_privado_id, contenido, timestamp, archivo_id 
-              FROM mensajes WHERE chat_privado_id = ? 
-              ORDER BY timestamp DESC LIMIT ?`
-
-	rows, err := dao.dbPool.DB().Query(query, chatPrivadoID.String(), limite)
+// BuscarResumenHilo recupera el resumen del hilo de un mensaje raíz, tenga o no respuestas
+func (dao *MensajeDAO) BuscarResumenHilo(ctx context.Context, q Querier, padreID uuid.UUID) (*model.ResumenHilo, error) {
+	rows, err := q.QueryContext(ctx, consultaHilos+` WHERE m.id = ? GROUP BY m.id`, padreID.String())
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return dao.escanearFilasMensajes(rows)
+	if !rows.Next() {
+		return nil, rows.Err() // Mensaje no encontrado
+	}
+	return dao.escanearResumenHilo(rows)
 }
 
-// BuscarMensajesDirectos recupera mensajes directos entre dos usuarios
-func (dao *MensajeDAO) BuscarMensajesDirectos(remitenteID, destinatarioID uuid.UUID, limite int) ([]*model.MensajeServidor, error) {
+// BuscarPorRangoFechas recupera los mensajes con timestamp en [desde, hasta)
+func (dao *MensajeDAO) BuscarPorRangoFechas(ctx context.Context, q Querier, desde, hasta time.Time) ([]*model.MensajeServidor, error) {
 	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
-              chat_privado_id, contenido, timestamp, archivo_id 
-              FROM mensajes 
-              WHERE (remitente_id = ? AND destino_usuario_id = ?) 
-              OR (remitente_id = ? AND destino_usuario_id = ?) 
-              ORDER BY timestamp DESC LIMIT ?`
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor 
+              WHERE timestamp >= ? AND timestamp < ? 
+              ORDER BY timestamp, id`
 
-	rows, err := dao.dbPool.DB().Query(query,
-		remitenteID.String(), destinatarioID.String(),
-		destinatarioID.String(), remitenteID.String(),
-		limite)
+	rows, err := q.QueryContext(ctx, query, desde, hasta)
 	if err != nil {
 		return nil, err
 	}
@@ -103,13 +204,106 @@ func (dao *MensajeDAO) BuscarMensajesDirectos(remitenteID, destinatarioID uuid.U
 	return dao.escanearFilasMensajes(rows)
 }
 
+// Buscar recupera una página de los mensajes visibles para un usuario que cumplen el
+// filtro: los de los canales de los que es miembro, los de los chats privados en los que
+// participa y los directos que envió o recibió
+func (dao *MensajeDAO) Buscar(ctx context.Context, q Querier, filtro FiltroBusquedaMensajes, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	if filtro.Candidatos != nil && len(filtro.Candidatos) == 0 {
+		return model.NuevaPagina(nil, consulta, cursorMensaje), nil
+	}
+
+	usuarioID := filtro.UsuarioID.String()
+	query := `SELECT id, remitente_id, destino_usuario_id, canal_id, 
+              chat_privado_id, contenido, timestamp, archivo_id, version_hlc,
+              editado_at, eliminado_at, padre_id
+              FROM mensaje_servidor 
+              WHERE eliminado_at IS NULL
+              AND (remitente_id = ? OR destino_usuario_id = ?
+              OR canal_id IN (SELECT canal_id FROM canal_miembro WHERE usuario_id = ?)
+              OR chat_privado_id IN (SELECT chat_id FROM chat_privado_usuario WHERE usuario_id = ?))`
+	args := []interface{}{usuarioID, usuarioID, usuarioID, usuarioID}
+
+	if filtro.Candidatos != nil {
+		query += " AND id IN (?" + strings.Repeat(", ?", len(filtro.Candidatos)-1) + ")"
+		for _, id := range filtro.Candidatos {
+			args = append(args, id.String())
+		}
+	} else if len(filtro.Terminos) > 0 {
+		// En modo booleano el prefijo + exige que aparezcan todos los términos
+		query += " AND MATCH(contenido) AGAINST (? IN BOOLEAN MODE)"
+		args = append(args, "+"+strings.Join(filtro.Terminos, " +"))
+	}
+	if filtro.Filtro.RemitenteID != uuid.Nil {
+		query += " AND remitente_id = ?"
+		args = append(args, filtro.Filtro.RemitenteID.String())
+	}
+	if filtro.Filtro.CanalID != uuid.Nil {
+		query += " AND canal_id = ?"
+		args = append(args, filtro.Filtro.CanalID.String())
+	}
+	if !filtro.Filtro.Desde.IsZero() {
+		query += " AND timestamp >= ?"
+		args = append(args, filtro.Filtro.Desde)
+	}
+	if !filtro.Filtro.Hasta.IsZero() {
+		query += " AND timestamp < ?"
+		args = append(args, filtro.Filtro.Hasta)
+	}
+	if filtro.Filtro.ConAdjunto {
+		query += " AND archivo_id IS NOT NULL"
+	}
+
+	return dao.buscarPagina(ctx, q, query, consulta, args...)
+}
+
 // Eliminar elimina un mensaje de la base de datos
-func (dao *MensajeDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM mensajes WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *MensajeDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM mensaje_servidor WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
+// buscarPagina completa la consulta con la paginación por (timestamp, id) y construye la página
+func (dao *MensajeDAO) buscarPagina(ctx context.Context, q Querier, query string, consulta model.ConsultaPagina, args ...interface{}) (*model.Pagina[*model.MensajeServidor], error) {
+	clausula, argsPagina := clausulaPagina("timestamp", "id", consulta)
+
+	rows, err := q.QueryContext(ctx, query+clausula, append(args, argsPagina...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mensajes, err := dao.escanearFilasMensajes(rows)
+	if err != nil {
+		return nil, err
+	}
+	return model.NuevaPagina(mensajes, consulta, cursorMensaje), nil
+}
+
+// consultaHilos selecciona cada mensaje raíz junto con el número de respuestas no
+// eliminadas de su hilo y el momento de la última; se completa con WHERE y GROUP BY m.id
+const consultaHilos = `SELECT m.id, m.remitente_id, m.destino_usuario_id, m.canal_id, 
+              m.chat_privado_id, m.contenido, m.timestamp, m.archivo_id, m.version_hlc,
+              m.editado_at, m.eliminado_at, m.padre_id, COUNT(r.id), MAX(r.timestamp)
+              FROM mensaje_servidor m
+              LEFT JOIN mensaje_servidor r ON r.padre_id = m.id AND r.eliminado_at IS NULL`
+
+// escanearResumenHilo escanea una fila de consultaHilos
+func (dao *MensajeDAO) escanearResumenHilo(rows *sql.Rows) (*model.ResumenHilo, error) {
+	var respuestas int
+	var ultima tiempoAgregado
+	raiz, err := dao.escanearFilaMensaje(rows, &respuestas, &ultima)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewResumenHilo(raiz, respuestas, ultima.Time)
+}
+
+// cursorMensaje devuelve la posición de un mensaje en los listados paginados
+func cursorMensaje(m *model.MensajeServidor) model.Cursor {
+	return model.NewCursor(m.Timestamp(), m.ID())
+}
+
 // escanearMensaje es un método auxiliar para escanear una fila en un mensaje
 func (dao *MensajeDAO) escanearMensaje(row *sql.Row) (*model.MensajeServidor, error) {
 	var (
@@ -117,6 +311,9 @@ func (dao *MensajeDAO) escanearMensaje(row *sql.Row) (*model.MensajeServidor, er
 		destinoUsuarioIDStr, canalIDStr, chatPrivadoIDStr, archivoIDStr sql.NullString
 		contenido                                                       string
 		timestamp                                                       time.Time
+		versionStr                                                      sql.NullString
+		editadoAt, eliminadoAt                                          sql.NullTime
+		padreIDStr                                                      sql.NullString
 	)
 
 	if err := row.Scan(
@@ -128,6 +325,10 @@ func (dao *MensajeDAO) escanearMensaje(row *sql.Row) (*model.MensajeServidor, er
 		&contenido,
 		&timestamp,
 		&archivoIDStr,
+		&versionStr,
+		&editadoAt,
+		&eliminadoAt,
+		&padreIDStr,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Mensaje no encontrado
@@ -135,22 +336,37 @@ func (dao *MensajeDAO) escanearMensaje(row *sql.Row) (*model.MensajeServidor, er
 		return nil, err
 	}
 
-	return dao.construirMensajeDesdeValoresEscaneados(
+	mensaje, err := dao.construirMensajeDesdeValoresEscaneados(
 		idStr, remitenteIDStr, destinoUsuarioIDStr,
 		canalIDStr, chatPrivadoIDStr, contenido,
 		timestamp, archivoIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	padreID, err := parseNullableUUID(padreIDStr)
+	if err != nil {
+		return nil, err
+	}
+	mensaje.SetPadreID(padreID)
+	mensaje.SetEdicion(editadoAt.Time, eliminadoAt.Time)
+	return asignarVersionMensaje(mensaje, versionStr)
 }
 
-// escanearFilaMensaje es un método auxiliar para escanear una fila de mensajes
-func (dao *MensajeDAO) escanearFilaMensaje(rows *sql.Rows) (*model.MensajeServidor, error) {
+// escanearFilaMensaje es un método auxiliar para escanear una fila de mensajes. Los
+// destinos de extra reciben las columnas que la consulta añade tras las del mensaje.
+func (dao *MensajeDAO) escanearFilaMensaje(rows *sql.Rows, extra ...interface{}) (*model.MensajeServidor, error) {
 	var (
 		idStr, remitenteIDStr                                           string
 		destinoUsuarioIDStr, canalIDStr, chatPrivadoIDStr, archivoIDStr sql.NullString
 		contenido                                                       string
 		timestamp                                                       time.Time
+		versionStr                                                      sql.NullString
+		editadoAt, eliminadoAt                                          sql.NullTime
+		padreIDStr                                                      sql.NullString
 	)
 
-	if err := rows.Scan(
+	destinos := []interface{}{
 		&idStr,
 		&remitenteIDStr,
 		&destinoUsuarioIDStr,
@@ -159,14 +375,30 @@ func (dao *MensajeDAO) escanearFilaMensaje(rows *sql.Rows) (*model.MensajeServid
 		&contenido,
 		&timestamp,
 		&archivoIDStr,
-	); err != nil {
+		&versionStr,
+		&editadoAt,
+		&eliminadoAt,
+		&padreIDStr,
+	}
+	if err := rows.Scan(append(destinos, extra...)...); err != nil {
 		return nil, err
 	}
 
-	return dao.construirMensajeDesdeValoresEscaneados(
+	mensaje, err := dao.construirMensajeDesdeValoresEscaneados(
 		idStr, remitenteIDStr, destinoUsuarioIDStr,
 		canalIDStr, chatPrivadoIDStr, contenido,
 		timestamp, archivoIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	padreID, err := parseNullableUUID(padreIDStr)
+	if err != nil {
+		return nil, err
+	}
+	mensaje.SetPadreID(padreID)
+	mensaje.SetEdicion(editadoAt.Time, eliminadoAt.Time)
+	return asignarVersionMensaje(mensaje, versionStr)
 }
 
 // escanearFilasMensajes es un método auxiliar para escanear múltiples filas
@@ -207,6 +439,13 @@ func (dao *MensajeDAO) construirMensajeDesdeValoresEscaneados(
 
 	var destinoUsuarioID, canalID, chatPrivadoID, archivoID uuid.UUID
 
+	if archivoIDStr.Valid {
+		archivoID, err = uuid.Parse(archivoIDStr.String)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if destinoUsuarioIDStr.Valid {
 		destinoUsuarioID, err = uuid.Parse(destinoUsuarioIDStr.String)
 		if err != nil {
@@ -231,6 +470,52 @@ func (dao *MensajeDAO) construirMensajeDesdeValoresEscaneados(
 	return nil, sql.ErrNoRows
 }
 
+// asignarVersionMensaje asigna al mensaje la versión HLC leída de la base de datos
+func asignarVersionMensaje(mensaje *model.MensajeServidor, versionStr sql.NullString) (*model.MensajeServidor, error) {
+	version, err := parseNullableHLC(versionStr)
+	if err != nil {
+		return nil, err
+	}
+	mensaje.SetVersion(version)
+	return mensaje, nil
+}
+
+// formatosTiempoTexto son los formatos en que llega como texto una fecha sin tipo
+// declarado: el de go-sqlite3 al guardar time.Time y los de MySQL sin parseTime
+var formatosTiempoTexto = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// tiempoAgregado lee el resultado de MAX o MIN sobre una columna TIMESTAMP. MySQL lo
+// devuelve como fecha, pero SQLite pierde el tipo declarado en las expresiones y lo
+// devuelve como texto.
+type tiempoAgregado struct {
+	sql.NullTime
+}
+
+// Scan implementa sql.Scanner
+func (t *tiempoAgregado) Scan(valor interface{}) error {
+	var texto string
+	switch v := valor.(type) {
+	case string:
+		texto = v
+	case []byte:
+		texto = string(v)
+	default:
+		return t.NullTime.Scan(valor)
+	}
+	for _, formato := range formatosTiempoTexto {
+		if parsed, err := time.Parse(formato, texto); err == nil {
+			t.Time, t.Valid = parsed, true
+			return nil
+		}
+	}
+	return fmt.Errorf("fecha agregada con formato desconocido: %q", texto)
+}
+
 // Helper para manejar UUID que pueden ser nulas en la base de datos
 func nullableUUID(id uuid.UUID) interface{} {
 	if id == uuid.Nil {
@@ -238,3 +523,35 @@ func nullableUUID(id uuid.UUID) interface{} {
 	}
 	return id.String()
 }
+
+// Helper para leer UUID que pueden ser nulas en la base de datos
+func parseNullableUUID(s sql.NullString) (uuid.UUID, error) {
+	if !s.Valid {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(s.String)
+}
+
+// Helper para manejar instantes opcionales, NULL si el instante es cero
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// Helper para persistir versiones HLC, NULL si la entidad no está versionada
+func nullableHLC(version model.HLC) interface{} {
+	if version.IsZero() {
+		return nil
+	}
+	return version.String()
+}
+
+// Helper para leer versiones HLC que pueden ser nulas en la base de datos
+func parseNullableHLC(s sql.NullString) (model.HLC, error) {
+	if !s.Valid {
+		return model.HLC{}, nil
+	}
+	return model.ParseHLC(s.String)
+}
@@ -1,31 +1,31 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
 	"github.com/google/uuid"
 	"model"
-	"pool"
 	"time"
 )
 
 // MensajeEnrutadoDAO maneja las operaciones de base de datos para entidades RoutedMessage (MensajeEnrutado)
-type MensajeEnrutadoDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type MensajeEnrutadoDAO struct{}
 
 // NuevoMensajeEnrutadoDAO crea una nueva instancia de MensajeEnrutadoDAO
-func NuevoMensajeEnrutadoDAO(dbPool *pool.DBConnectionPool) *MensajeEnrutadoDAO {
-	return &MensajeEnrutadoDAO{dbPool: dbPool}
+func NuevoMensajeEnrutadoDAO() *MensajeEnrutadoDAO {
+	return &MensajeEnrutadoDAO{}
 }
 
 // Crear persiste un nuevo mensaje enrutado en la base de datos
-func (dao *MensajeEnrutadoDAO) Crear(mensaje *model.RoutedMessage) error {
-	query := `INSERT INTO routed_messages (mensaje_id, nodo_destino_id, enruta_at)
-              VALUES (?, ?, ?)`
+func (dao *MensajeEnrutadoDAO) Crear(ctx context.Context, q Querier, mensaje *model.RoutedMessage) error {
+	query := `INSERT INTO routed_message (mensaje_id, nodo_anterior_id, nodo_destino_id, enruta_at)
+              VALUES (?, ?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		mensaje.MensajeID().String(),
+		nullableUUID(mensaje.NodoAnteriorID()),
 		mensaje.NodoDestinoID().String(),
 		mensaje.EnrutaAt(),
 	)
@@ -33,21 +33,13 @@ func (dao *MensajeEnrutadoDAO) Crear(mensaje *model.RoutedMessage) error {
 	return err
 }
 
-// BuscarPorMensajeID recupera un mensaje enrutado por su ID de mensaje
-func (dao *MensajeEnrutadoDAO) BuscarPorMensajeID(mensajeID uuid.UUID) (*model.RoutedMessage, error) {
-	query := `SELECT mensaje_id, nodo_destino_id, enruta_at
-              FROM routed_messages WHERE mensaje_id = ?`
-
-	row := dao.dbPool.DB().QueryRow(query, mensajeID.String())
-	return dao.escanearMensajeEnrutado(row)
-}
+// BuscarPorMensajeID recupera todos los saltos registrados para un mensaje, en orden de envío
+func (dao *MensajeEnrutadoDAO) BuscarPorMensajeID(ctx context.Context, q Querier, mensajeID uuid.UUID) ([]*model.RoutedMessage, error) {
+	query := `SELECT mensaje_id, nodo_anterior_id, nodo_destino_id, enruta_at
+              FROM routed_message WHERE mensaje_id = ?
+              ORDER BY enruta_at`
 
-// BuscarTodos recupera todos los mensajes enrutados de la base de datos
-func (dao *MensajeEnrutadoDAO) BuscarTodos() ([]*model.RoutedMessage, error) {
-	query := `SELECT mensaje_id, nodo_destino_id, enruta_at
-              FROM routed_messages`
-
-	rows, err := dao.dbPool.DB().Query(query)
+	rows, err := q.QueryContext(ctx, query, mensajeID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -56,12 +48,12 @@ func (dao *MensajeEnrutadoDAO) BuscarTodos() ([]*model.RoutedMessage, error) {
 	return dao.escanearMultiplesMensajesEnrutados(rows)
 }
 
-// BuscarPorNodoDestinoID recupera todos los mensajes enrutados para un nodo destino específico
-func (dao *MensajeEnrutadoDAO) BuscarPorNodoDestinoID(nodoDestinoID uuid.UUID) ([]*model.RoutedMessage, error) {
-	query := `SELECT mensaje_id, nodo_destino_id, enruta_at
-              FROM routed_messages WHERE nodo_destino_id = ?`
+// BuscarTodos recupera todos los mensajes enrutados de la base de datos
+func (dao *MensajeEnrutadoDAO) BuscarTodos(ctx context.Context, q Querier) ([]*model.RoutedMessage, error) {
+	query := `SELECT mensaje_id, nodo_anterior_id, nodo_destino_id, enruta_at
+              FROM routed_message`
 
-	rows, err := dao.dbPool.DB().Query(query, nodoDestinoID.String())
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -70,13 +62,12 @@ func (dao *MensajeEnrutadoDAO) BuscarPorNodoDestinoID(nodoDestinoID uuid.UUID) (
 	return dao.escanearMultiplesMensajesEnrutados(rows)
 }
 
-// BuscarPorRangoTiempo recupera mensajes enrutados dentro de un rango de tiempo específico
-func (dao *MensajeEnrutadoDAO) BuscarPorRangoTiempo(inicio, fin time.Time) ([]*model.RoutedMessage, error) {
-	query := `SELECT mensaje_id, nodo_destino_id, enruta_at
-              FROM routed_messages
-              WHERE enruta_at BETWEEN ? AND ?`
+// BuscarPorNodoDestinoID recupera todos los mensajes enrutados para un nodo destino específico
+func (dao *MensajeEnrutadoDAO) BuscarPorNodoDestinoID(ctx context.Context, q Querier, nodoDestinoID uuid.UUID) ([]*model.RoutedMessage, error) {
+	query := `SELECT mensaje_id, nodo_anterior_id, nodo_destino_id, enruta_at
+              FROM routed_message WHERE nodo_destino_id = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, inicio, fin)
+	rows, err := q.QueryContext(ctx, query, nodoDestinoID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -85,38 +76,49 @@ func (dao *MensajeEnrutadoDAO) BuscarPorRangoTiempo(inicio, fin time.Time) ([]*m
 	return dao.escanearMultiplesMensajesEnrutados(rows)
 }
 
-// Eliminar elimina un mensaje enrutado de la base de datos
-func (dao *MensajeEnrutadoDAO) Eliminar(mensajeID uuid.UUID) error {
-	query := `DELETE FROM routed_messages WHERE mensaje_id = ?`
-	_, err := dao.dbPool.DB().Exec(query, mensajeID.String())
-	return err
-}
-
-// escanearMensajeEnrutado escanea una fila en un objeto RoutedMessage (MensajeEnrutado)
-func (dao *MensajeEnrutadoDAO) escanearMensajeEnrutado(row *sql.Row) (*model.RoutedMessage, error) {
-	var (
-		mensajeIDStr, nodoDestinoIDStr string
-		enrutaAt                       time.Time
-	)
+// BuscarPaginaPorNodoDestinoID recupera una página de los mensajes enrutados hacia un
+// nodo, ordenados por (enruta_at, mensaje_id): cada mensaje se enruta una sola vez a
+// cada destino, así que el par identifica la fila dentro del nodo
+func (dao *MensajeEnrutadoDAO) BuscarPaginaPorNodoDestinoID(ctx context.Context, q Querier, nodoDestinoID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.RoutedMessage], error) {
+	query := `SELECT mensaje_id, nodo_anterior_id, nodo_destino_id, enruta_at
+              FROM routed_message WHERE nodo_destino_id = ?`
 
-	if err := row.Scan(&mensajeIDStr, &nodoDestinoIDStr, &enrutaAt); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // Mensaje enrutado no encontrado
-		}
+	clausula, args := clausulaPagina("enruta_at", "mensaje_id", consulta)
+	rows, err := q.QueryContext(ctx, query+clausula, append([]interface{}{nodoDestinoID.String()}, args...)...)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	mensajeID, err := uuid.Parse(mensajeIDStr)
+	mensajes, err := dao.escanearMultiplesMensajesEnrutados(rows)
 	if err != nil {
 		return nil, err
 	}
+	return model.NuevaPagina(mensajes, consulta, func(m *model.RoutedMessage) model.Cursor {
+		return model.NewCursor(m.EnrutaAt(), m.MensajeID())
+	}), nil
+}
 
-	nodoDestinoID, err := uuid.Parse(nodoDestinoIDStr)
+// BuscarPorRangoTiempo recupera mensajes enrutados dentro de un rango de tiempo específico
+func (dao *MensajeEnrutadoDAO) BuscarPorRangoTiempo(ctx context.Context, q Querier, inicio, fin time.Time) ([]*model.RoutedMessage, error) {
+	query := `SELECT mensaje_id, nodo_anterior_id, nodo_destino_id, enruta_at
+              FROM routed_message
+              WHERE enruta_at BETWEEN ? AND ?`
+
+	rows, err := q.QueryContext(ctx, query, inicio, fin)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	return dao.escanearMultiplesMensajesEnrutados(rows)
+}
 
-	return model.NewRoutedMessage(mensajeID, nodoDestinoID, enrutaAt)
+// Eliminar elimina un mensaje enrutado de la base de datos
+func (dao *MensajeEnrutadoDAO) Eliminar(ctx context.Context, q Querier, mensajeID uuid.UUID) error {
+	query := `DELETE FROM routed_message WHERE mensaje_id = ?`
+	_, err := q.ExecContext(ctx, query, mensajeID.String())
+	return err
 }
 
 // escanearMultiplesMensajesEnrutados escanea múltiples filas en objetos RoutedMessage (MensajeEnrutado)
@@ -126,24 +128,15 @@ func (dao *MensajeEnrutadoDAO) escanearMultiplesMensajesEnrutados(rows *sql.Rows
 	for rows.Next() {
 		var (
 			mensajeIDStr, nodoDestinoIDStr string
+			nodoAnteriorIDStr              sql.NullString
 			enrutaAt                       time.Time
 		)
 
-		if err := rows.Scan(&mensajeIDStr, &nodoDestinoIDStr, &enrutaAt); err != nil {
-			return nil, err
-		}
-
-		mensajeID, err := uuid.Parse(mensajeIDStr)
-		if err != nil {
-			return nil, err
-		}
-
-		nodoDestinoID, err := uuid.Parse(nodoDestinoIDStr)
-		if err != nil {
+		if err := rows.Scan(&mensajeIDStr, &nodoAnteriorIDStr, &nodoDestinoIDStr, &enrutaAt); err != nil {
 			return nil, err
 		}
 
-		mensaje, err := model.NewRoutedMessage(mensajeID, nodoDestinoID, enrutaAt)
+		mensaje, err := construirMensajeEnrutado(mensajeIDStr, nodoAnteriorIDStr, nodoDestinoIDStr, enrutaAt)
 		if err != nil {
 			return nil, err
 		}
@@ -157,3 +150,28 @@ func (dao *MensajeEnrutadoDAO) escanearMultiplesMensajesEnrutados(rows *sql.Rows
 
 	return mensajes, nil
 }
+
+// construirMensajeEnrutado reconstruye un RoutedMessage; los saltos con nodo anterior
+// pertenecen a rutas de varios saltos
+func construirMensajeEnrutado(mensajeIDStr string, nodoAnteriorIDStr sql.NullString, nodoDestinoIDStr string, enrutaAt time.Time) (*model.RoutedMessage, error) {
+	mensajeID, err := uuid.Parse(mensajeIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	nodoDestinoID, err := uuid.Parse(nodoDestinoIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !nodoAnteriorIDStr.Valid {
+		return model.NewRoutedMessage(mensajeID, nodoDestinoID, enrutaAt)
+	}
+
+	nodoAnteriorID, err := uuid.Parse(nodoAnteriorIDStr.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewRoutedMessageSalto(mensajeID, nodoAnteriorID, nodoDestinoID, enrutaAt)
+}
@@ -0,0 +1,215 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// columnasMensajeProgramado son las columnas que leen todas las consultas de
+// mensaje_programado, en el orden que espera escanearProgramados
+const columnasMensajeProgramado = `id, nodo_id, remitente_id, destino_usuario_id, canal_id, contenido,
+              archivo_id, enviar_en, duracion_segundos, estado, mensaje_id, expira_en`
+
+// MensajeProgramadoDAO maneja las operaciones de base de datos para los mensajes
+// programados y con caducidad
+type MensajeProgramadoDAO struct{}
+
+// NuevoMensajeProgramadoDAO crea una nueva instancia de MensajeProgramadoDAO
+func NuevoMensajeProgramadoDAO() *MensajeProgramadoDAO {
+	return &MensajeProgramadoDAO{}
+}
+
+// Crear persiste un mensaje programado con su estado actual
+func (dao *MensajeProgramadoDAO) Crear(ctx context.Context, q Querier, p *model.MensajeProgramado) error {
+	query := `INSERT INTO mensaje_programado (` + columnasMensajeProgramado + `)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		p.ID().String(),
+		p.NodoID().String(),
+		p.RemitenteID().String(),
+		nullableUUID(p.DestinoUsuarioID()),
+		nullableUUID(p.CanalID()),
+		p.Contenido(),
+		nullableUUID(p.ArchivoID()),
+		p.EnviarEn(),
+		int64(p.Duracion()/time.Second),
+		string(p.Estado()),
+		nullableUUID(p.MensajeID()),
+		nullableTime(p.ExpiraEn()),
+	)
+
+	return err
+}
+
+// BuscarPorID recupera un mensaje programado; devuelve nil si no existe
+func (dao *MensajeProgramadoDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.MensajeProgramado, error) {
+	query := `SELECT ` + columnasMensajeProgramado + `
+              FROM mensaje_programado WHERE id = ?`
+
+	rows, err := q.QueryContext(ctx, query, id.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	programados, err := dao.escanearProgramados(rows)
+	if err != nil || len(programados) == 0 {
+		return nil, err
+	}
+	return programados[0], nil
+}
+
+// ActualizarEstado guarda el estado, el mensaje enviado y la expiración de p solo si
+// la fila sigue en el estado desde. Devuelve false si otro cambio se adelantó, lo que
+// permite reservar un mensaje para enviarlo una única vez.
+func (dao *MensajeProgramadoDAO) ActualizarEstado(ctx context.Context, q Querier, p *model.MensajeProgramado, desde model.EstadoProgramado) (bool, error) {
+	query := `UPDATE mensaje_programado SET estado = ?, mensaje_id = ?, expira_en = ?
+              WHERE id = ? AND estado = ?`
+
+	res, err := q.ExecContext(
+		ctx,
+		query,
+		string(p.Estado()),
+		nullableUUID(p.MensajeID()),
+		nullableTime(p.ExpiraEn()),
+		p.ID().String(),
+		string(desde),
+	)
+	if err != nil {
+		return false, err
+	}
+	filas, err := res.RowsAffected()
+	return filas == 1, err
+}
+
+// BuscarVencidos recupera, en orden de envío, hasta limite mensajes de los que es
+// responsable nodoID y que requieren acción en at: los pendientes cuya fecha de envío
+// llegó, los enviados cuya expiración venció y los que quedaron reservados sin enviar
+func (dao *MensajeProgramadoDAO) BuscarVencidos(ctx context.Context, q Querier, nodoID uuid.UUID, at time.Time, limite int) ([]*model.MensajeProgramado, error) {
+	query := `SELECT ` + columnasMensajeProgramado + `
+              FROM mensaje_programado
+              WHERE nodo_id = ? AND (
+                    (estado = ? AND enviar_en <= ?)
+                 OR (estado = ? AND expira_en <= ?)
+                 OR estado = ?)
+              ORDER BY enviar_en, id LIMIT ?`
+
+	rows, err := q.QueryContext(
+		ctx,
+		query,
+		nodoID.String(),
+		string(model.ProgramadoPendiente), at,
+		string(model.ProgramadoEnviado), at,
+		string(model.ProgramadoEnviando),
+		limite,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dao.escanearProgramados(rows)
+}
+
+// BuscarPaginaPorRemitenteID recupera una página de los mensajes programados por un
+// usuario, ordenada por (enviar_en, id)
+func (dao *MensajeProgramadoDAO) BuscarPaginaPorRemitenteID(ctx context.Context, q Querier, remitenteID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeProgramado], error) {
+	query := `SELECT ` + columnasMensajeProgramado + `
+              FROM mensaje_programado WHERE remitente_id = ?`
+
+	clausula, args := clausulaPagina("enviar_en", "id", consulta)
+	rows, err := q.QueryContext(ctx, query+clausula, append([]interface{}{remitenteID.String()}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	programados, err := dao.escanearProgramados(rows)
+	if err != nil {
+		return nil, err
+	}
+	return model.NuevaPagina(programados, consulta, func(p *model.MensajeProgramado) model.Cursor {
+		return model.NewCursor(p.EnviarEn(), p.ID())
+	}), nil
+}
+
+// escanearProgramados convierte las filas leídas en mensajes programados
+func (dao *MensajeProgramadoDAO) escanearProgramados(rows *sql.Rows) ([]*model.MensajeProgramado, error) {
+	var programados []*model.MensajeProgramado
+	for rows.Next() {
+		var (
+			idStr, nodoIDStr, remitenteIDStr, contenido, estado  string
+			destinoIDStr, canalIDStr, archivoIDStr, mensajeIDStr sql.NullString
+			enviarEn                                             time.Time
+			duracion                                             int64
+			expiraEn                                             sql.NullTime
+		)
+		if err := rows.Scan(
+			&idStr, &nodoIDStr, &remitenteIDStr, &destinoIDStr, &canalIDStr, &contenido,
+			&archivoIDStr, &enviarEn, &duracion, &estado, &mensajeIDStr, &expiraEn,
+		); err != nil {
+			return nil, err
+		}
+
+		p, err := construirMensajeProgramado(idStr, nodoIDStr, remitenteIDStr, destinoIDStr, canalIDStr, archivoIDStr, contenido, enviarEn, duracion)
+		if err != nil {
+			return nil, err
+		}
+		mensajeID, err := parseNullableUUID(mensajeIDStr)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.SetEstado(model.EstadoProgramado(estado), mensajeID, expiraEn.Time); err != nil {
+			return nil, err
+		}
+		programados = append(programados, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return programados, nil
+}
+
+// construirMensajeProgramado reconstruye un MensajeProgramado pendiente a partir de
+// las columnas leídas; el estado se asigna después
+func construirMensajeProgramado(
+	idStr, nodoIDStr, remitenteIDStr string,
+	destinoIDStr, canalIDStr, archivoIDStr sql.NullString,
+	contenido string,
+	enviarEn time.Time,
+	duracion int64,
+) (*model.MensajeProgramado, error) {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, err
+	}
+	nodoID, err := uuid.Parse(nodoIDStr)
+	if err != nil {
+		return nil, err
+	}
+	remitenteID, err := uuid.Parse(remitenteIDStr)
+	if err != nil {
+		return nil, err
+	}
+	destinoID, err := parseNullableUUID(destinoIDStr)
+	if err != nil {
+		return nil, err
+	}
+	canalID, err := parseNullableUUID(canalIDStr)
+	if err != nil {
+		return nil, err
+	}
+	archivoID, err := parseNullableUUID(archivoIDStr)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewMensajeProgramado(id, nodoID, remitenteID, destinoID, canalID, contenido, archivoID, enviarEn, time.Duration(duracion)*time.Second)
+}
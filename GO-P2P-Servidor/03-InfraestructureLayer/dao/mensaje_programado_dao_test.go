@@ -0,0 +1,79 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestMensajeProgramado_VencidosYReserva comprueba que BuscarVencidos solo devuelve los
+// mensajes del nodo que requieren acción y que ActualizarEstado reserva cada mensaje
+// una sola vez
+func TestMensajeProgramado_VencidosYReserva(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	ahora := time.Now().UTC().Truncate(time.Second)
+	programadoDAO := NuevoMensajeProgramadoDAO()
+
+	remitenteID, destinoID, nodoID := uuid.New(), uuid.New(), uuid.New()
+	crearUsuarioPrueba(t, dbPool, remitenteID)
+	programar := func(nodo uuid.UUID, enviarEn time.Time, duracion time.Duration) *model.MensajeProgramado {
+		t.Helper()
+		p, err := model.NewMensajeProgramado(uuid.New(), nodo, remitenteID, destinoID, uuid.Nil, "hola", uuid.Nil, enviarEn, duracion)
+		require.NoError(t, err)
+		require.NoError(t, programadoDAO.Crear(ctx, dbPool, p))
+		return p
+	}
+
+	vencido := programar(nodoID, ahora.Add(-time.Minute), time.Minute)
+	programar(nodoID, ahora.Add(time.Hour), 0)
+	programar(uuid.New(), ahora.Add(-time.Minute), 0)
+
+	vencidos, err := programadoDAO.BuscarVencidos(ctx, dbPool, nodoID, ahora, 10)
+	require.NoError(t, err)
+	require.Len(t, vencidos, 1)
+	assert.Equal(t, vencido.ID(), vencidos[0].ID())
+	assert.Equal(t, time.Minute, vencidos[0].Duracion())
+
+	// Dos copias compiten por reservar el mismo mensaje: solo una lo consigue
+	otraCopia := *vencidos[0]
+	require.NoError(t, vencido.Reservar())
+	require.NoError(t, otraCopia.Reservar())
+	reservado, err := programadoDAO.ActualizarEstado(ctx, dbPool, vencido, model.ProgramadoPendiente)
+	require.NoError(t, err)
+	assert.True(t, reservado)
+	reservado, err = programadoDAO.ActualizarEstado(ctx, dbPool, &otraCopia, model.ProgramadoPendiente)
+	require.NoError(t, err)
+	assert.False(t, reservado)
+
+	// Un mensaje reservado que no llegó a enviarse sigue requiriendo acción
+	vencidos, err = programadoDAO.BuscarVencidos(ctx, dbPool, nodoID, ahora, 10)
+	require.NoError(t, err)
+	require.Len(t, vencidos, 1)
+	assert.Equal(t, model.ProgramadoEnviando, vencidos[0].Estado())
+
+	// Enviado, vuelve a vencer al llegar su expiración
+	mensajeID := uuid.New()
+	require.NoError(t, vencido.Enviado(mensajeID, ahora))
+	reservado, err = programadoDAO.ActualizarEstado(ctx, dbPool, vencido, model.ProgramadoEnviando)
+	require.NoError(t, err)
+	require.True(t, reservado)
+	vencidos, err = programadoDAO.BuscarVencidos(ctx, dbPool, nodoID, ahora, 10)
+	require.NoError(t, err)
+	assert.Empty(t, vencidos)
+	vencidos, err = programadoDAO.BuscarVencidos(ctx, dbPool, nodoID, ahora.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, vencidos, 1)
+	assert.Equal(t, mensajeID, vencidos[0].MensajeID())
+	assert.True(t, vencidos[0].ExpiraEn().Equal(ahora.Add(time.Minute)))
+
+	leido, err := programadoDAO.BuscarPorID(ctx, dbPool, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, leido)
+}
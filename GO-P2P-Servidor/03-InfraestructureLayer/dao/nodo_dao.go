@@ -1,28 +1,29 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
+	"time"
+
 	"github.com/google/uuid"
 	"model"
-	"pool"
 )
 
 // NodoDAO maneja las operaciones de base de datos para entidades Peer (Nodo)
-type NodoDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type NodoDAO struct{}
 
 // NuevoNodoDAO crea una nueva instancia de NodoDAO
-func NuevoNodoDAO(dbPool *pool.DBConnectionPool) *NodoDAO {
-	return &NodoDAO{dbPool: dbPool}
+func NuevoNodoDAO() *NodoDAO {
+	return &NodoDAO{}
 }
 
 // Guardar persiste un nodo en la base de datos
-func (dao *NodoDAO) Guardar(nodo *model.Peer) error {
-	query := `INSERT INTO peers (id_nodo, direccion, estado)
+func (dao *NodoDAO) Guardar(ctx context.Context, q Querier, nodo *model.Peer) error {
+	query := `INSERT INTO peer (id_nodo, direccion, estado)
               VALUES (?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		nodo.IDNodo().String(),
 		nodo.Direccion(),
@@ -33,20 +34,20 @@ func (dao *NodoDAO) Guardar(nodo *model.Peer) error {
 }
 
 // BuscarPorID recupera un nodo por su ID
-func (dao *NodoDAO) BuscarPorID(id uuid.UUID) (*model.Peer, error) {
-	query := `SELECT id_nodo, direccion, estado
-              FROM peers WHERE id_nodo = ?`
+func (dao *NodoDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.Peer, error) {
+	query := `SELECT id_nodo, direccion, estado, ultima_sync_at
+              FROM peer WHERE id_nodo = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearNodo(row)
 }
 
 // BuscarTodos recupera todos los nodos de la base de datos
-func (dao *NodoDAO) BuscarTodos() ([]*model.Peer, error) {
-	query := `SELECT id_nodo, direccion, estado
-              FROM peers`
+func (dao *NodoDAO) BuscarTodos(ctx context.Context, q Querier) ([]*model.Peer, error) {
+	query := `SELECT id_nodo, direccion, estado, ultima_sync_at
+              FROM peer`
 
-	rows, err := dao.dbPool.DB().Query(query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -56,11 +57,12 @@ func (dao *NodoDAO) BuscarTodos() ([]*model.Peer, error) {
 }
 
 // Actualizar actualiza un nodo existente
-func (dao *NodoDAO) Actualizar(nodo *model.Peer) error {
-	query := `UPDATE peers SET direccion = ?, estado = ?
+func (dao *NodoDAO) Actualizar(ctx context.Context, q Querier, nodo *model.Peer) error {
+	query := `UPDATE peer SET direccion = ?, estado = ?
               WHERE id_nodo = ?`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		nodo.Direccion(),
 		string(nodo.Estado()), // NodoEstado es un tipo string
@@ -71,18 +73,25 @@ func (dao *NodoDAO) Actualizar(nodo *model.Peer) error {
 }
 
 // Eliminar elimina un nodo de la base de datos
-func (dao *NodoDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM peers WHERE id_nodo = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *NodoDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM peer WHERE id_nodo = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
+	return err
+}
+
+// ActualizarUltimaSync registra la última sincronización anti-entropía exitosa con el nodo
+func (dao *NodoDAO) ActualizarUltimaSync(ctx context.Context, q Querier, id uuid.UUID, momento time.Time) error {
+	query := `UPDATE peer SET ultima_sync_at = ? WHERE id_nodo = ?`
+	_, err := q.ExecContext(ctx, query, momento, id.String())
 	return err
 }
 
 // BuscarPorEstado recupera todos los nodos con un estado específico
-func (dao *NodoDAO) BuscarPorEstado(estado model.NodoEstado) ([]*model.Peer, error) {
-	query := `SELECT id_nodo, direccion, estado
-              FROM peers WHERE estado = ?`
+func (dao *NodoDAO) BuscarPorEstado(ctx context.Context, q Querier, estado model.NodoEstado) ([]*model.Peer, error) {
+	query := `SELECT id_nodo, direccion, estado, ultima_sync_at
+              FROM peer WHERE estado = ?`
 
-	rows, err := dao.dbPool.DB().Query(query, string(estado))
+	rows, err := q.QueryContext(ctx, query, string(estado))
 	if err != nil {
 		return nil, err
 	}
@@ -94,12 +103,13 @@ func (dao *NodoDAO) BuscarPorEstado(estado model.NodoEstado) ([]*model.Peer, err
 // escanearNodo escanea una fila en un objeto Peer (Nodo)
 func (dao *NodoDAO) escanearNodo(row *sql.Row) (*model.Peer, error) {
 	var (
-		idStr     string
-		direccion string
-		estadoStr string
+		idStr      string
+		direccion  string
+		estadoStr  string
+		ultimaSync sql.NullTime
 	)
 
-	if err := row.Scan(&idStr, &direccion, &estadoStr); err != nil {
+	if err := row.Scan(&idStr, &direccion, &estadoStr, &ultimaSync); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Nodo no encontrado
 		}
@@ -114,7 +124,7 @@ func (dao *NodoDAO) escanearNodo(row *sql.Row) (*model.Peer, error) {
 	// Convertir el string a NodoEstado
 	estado := model.NodoEstado(estadoStr)
 
-	return model.NewPeer(id, direccion, estado)
+	return construirNodo(id, direccion, estado, ultimaSync)
 }
 
 // escanearMultiplesNodos escanea múltiples filas en objetos Peer (Nodo)
@@ -123,12 +133,13 @@ func (dao *NodoDAO) escanearMultiplesNodos(rows *sql.Rows) ([]*model.Peer, error
 
 	for rows.Next() {
 		var (
-			idStr     string
-			direccion string
-			estadoStr string
+			idStr      string
+			direccion  string
+			estadoStr  string
+			ultimaSync sql.NullTime
 		)
 
-		if err := rows.Scan(&idStr, &direccion, &estadoStr); err != nil {
+		if err := rows.Scan(&idStr, &direccion, &estadoStr, &ultimaSync); err != nil {
 			return nil, err
 		}
 
@@ -140,7 +151,7 @@ func (dao *NodoDAO) escanearMultiplesNodos(rows *sql.Rows) ([]*model.Peer, error
 		// Convertir el string a NodoEstado
 		estado := model.NodoEstado(estadoStr)
 
-		nodo, err := model.NewPeer(id, direccion, estado)
+		nodo, err := construirNodo(id, direccion, estado, ultimaSync)
 		if err != nil {
 			return nil, err
 		}
@@ -154,3 +165,15 @@ func (dao *NodoDAO) escanearMultiplesNodos(rows *sql.Rows) ([]*model.Peer, error
 
 	return nodos, nil
 }
+
+// construirNodo crea el Peer y le asigna la última sincronización si existe
+func construirNodo(id uuid.UUID, direccion string, estado model.NodoEstado, ultimaSync sql.NullTime) (*model.Peer, error) {
+	nodo, err := model.NewPeer(id, direccion, estado)
+	if err != nil {
+		return nil, err
+	}
+	if ultimaSync.Valid {
+		nodo.SetUltimaSync(ultimaSync.Time)
+	}
+	return nodo, nil
+}
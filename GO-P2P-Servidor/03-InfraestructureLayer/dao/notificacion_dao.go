@@ -1,8 +1,8 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
-	"pool"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,18 +10,16 @@ import (
 )
 
 // NotificacionDAO maneja las operaciones de base de datos para entidades Notificacion
-type NotificacionDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type NotificacionDAO struct{}
 
 // NuevoNotificacionDAO crea una nueva instancia de NotificacionDAO
-func NuevoNotificacionDAO(dbPool *pool.DBConnectionPool) *NotificacionDAO {
-	return &NotificacionDAO{dbPool: dbPool}
+func NuevoNotificacionDAO() *NotificacionDAO {
+	return &NotificacionDAO{}
 }
 
 // Guardar persiste una notificación en la base de datos
-func (dao *NotificacionDAO) Guardar(notificacion *model.Notificacion) error {
-	query := `INSERT INTO notificaciones (id, usuario_id, contenido, fecha, leido, invitacion_id)
+func (dao *NotificacionDAO) Guardar(ctx context.Context, q Querier, notificacion *model.Notificacion) error {
+	query := `INSERT INTO notificacion (id, usuario_id, contenido, fecha, leido, invitacion_id)
               VALUES (?, ?, ?, ?, ?, ?)`
 
 	// Si invitacionID es uuid.Nil, se guardará como NULL en la base de datos
@@ -31,7 +29,8 @@ func (dao *NotificacionDAO) Guardar(notificacion *model.Notificacion) error {
 		invitacionIDStr = &id
 	}
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		notificacion.ID().String(),
 		notificacion.UsuarioID().String(),
@@ -45,20 +44,20 @@ func (dao *NotificacionDAO) Guardar(notificacion *model.Notificacion) error {
 }
 
 // BuscarPorID recupera una notificación por su ID
-func (dao *NotificacionDAO) BuscarPorID(id uuid.UUID) (*model.Notificacion, error) {
+func (dao *NotificacionDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.Notificacion, error) {
 	query := `SELECT id, usuario_id, contenido, fecha, leido, invitacion_id
-              FROM notificaciones WHERE id = ?`
+              FROM notificacion WHERE id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearNotificacion(row)
 }
 
 // BuscarPorUsuarioID recupera todas las notificaciones para un usuario específico
-func (dao *NotificacionDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*model.Notificacion, error) {
+func (dao *NotificacionDAO) BuscarPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID) ([]*model.Notificacion, error) {
 	query := `SELECT id, usuario_id, contenido, fecha, leido, invitacion_id
-              FROM notificaciones WHERE usuario_id = ? ORDER BY fecha DESC`
+              FROM notificacion WHERE usuario_id = ? ORDER BY fecha DESC`
 
-	rows, err := dao.dbPool.DB().Query(query, usuarioID.String())
+	rows, err := q.QueryContext(ctx, query, usuarioID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -67,12 +66,34 @@ func (dao *NotificacionDAO) BuscarPorUsuarioID(usuarioID uuid.UUID) ([]*model.No
 	return dao.escanearMultiplesNotificaciones(rows)
 }
 
+// BuscarPaginaPorUsuarioID recupera una página de las notificaciones de un usuario,
+// ordenadas por (fecha, id)
+func (dao *NotificacionDAO) BuscarPaginaPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.Notificacion], error) {
+	query := `SELECT id, usuario_id, contenido, fecha, leido, invitacion_id
+              FROM notificacion WHERE usuario_id = ?`
+
+	clausula, args := clausulaPagina("fecha", "id", consulta)
+	rows, err := q.QueryContext(ctx, query+clausula, append([]interface{}{usuarioID.String()}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notificaciones, err := dao.escanearMultiplesNotificaciones(rows)
+	if err != nil {
+		return nil, err
+	}
+	return model.NuevaPagina(notificaciones, consulta, func(n *model.Notificacion) model.Cursor {
+		return model.NewCursor(n.Fecha(), n.ID())
+	}), nil
+}
+
 // BuscarNoLeidas recupera todas las notificaciones no leídas para un usuario
-func (dao *NotificacionDAO) BuscarNoLeidas(usuarioID uuid.UUID) ([]*model.Notificacion, error) {
+func (dao *NotificacionDAO) BuscarNoLeidas(ctx context.Context, q Querier, usuarioID uuid.UUID) ([]*model.Notificacion, error) {
 	query := `SELECT id, usuario_id, contenido, fecha, leido, invitacion_id
-              FROM notificaciones WHERE usuario_id = ? AND leido = false ORDER BY fecha DESC`
+              FROM notificacion WHERE usuario_id = ? AND leido = false ORDER BY fecha DESC`
 
-	rows, err := dao.dbPool.DB().Query(query, usuarioID.String())
+	rows, err := q.QueryContext(ctx, query, usuarioID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -82,11 +103,11 @@ func (dao *NotificacionDAO) BuscarNoLeidas(usuarioID uuid.UUID) ([]*model.Notifi
 }
 
 // BuscarPorInvitacionID recupera todas las notificaciones relacionadas con una invitación
-func (dao *NotificacionDAO) BuscarPorInvitacionID(invitacionID uuid.UUID) ([]*model.Notificacion, error) {
+func (dao *NotificacionDAO) BuscarPorInvitacionID(ctx context.Context, q Querier, invitacionID uuid.UUID) ([]*model.Notificacion, error) {
 	query := `SELECT id, usuario_id, contenido, fecha, leido, invitacion_id
-              FROM notificaciones WHERE invitacion_id = ? ORDER BY fecha DESC`
+              FROM notificacion WHERE invitacion_id = ? ORDER BY fecha DESC`
 
-	rows, err := dao.dbPool.DB().Query(query, invitacionID.String())
+	rows, err := q.QueryContext(ctx, query, invitacionID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -96,23 +117,23 @@ func (dao *NotificacionDAO) BuscarPorInvitacionID(invitacionID uuid.UUID) ([]*mo
 }
 
 // ActualizarEstadoLeido actualiza el estado de leído de una notificación
-func (dao *NotificacionDAO) ActualizarEstadoLeido(id uuid.UUID, leido bool) error {
-	query := `UPDATE notificaciones SET leido = ? WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, leido, id.String())
+func (dao *NotificacionDAO) ActualizarEstadoLeido(ctx context.Context, q Querier, id uuid.UUID, leido bool) error {
+	query := `UPDATE notificacion SET leido = ? WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, leido, id.String())
 	return err
 }
 
 // MarcarTodasComoLeidas marca todas las notificaciones de un usuario como leídas
-func (dao *NotificacionDAO) MarcarTodasComoLeidas(usuarioID uuid.UUID) error {
-	query := `UPDATE notificaciones SET leido = true WHERE usuario_id = ? AND leido = false`
-	_, err := dao.dbPool.DB().Exec(query, usuarioID.String())
+func (dao *NotificacionDAO) MarcarTodasComoLeidas(ctx context.Context, q Querier, usuarioID uuid.UUID) error {
+	query := `UPDATE notificacion SET leido = true WHERE usuario_id = ? AND leido = false`
+	_, err := q.ExecContext(ctx, query, usuarioID.String())
 	return err
 }
 
 // Eliminar elimina una notificación
-func (dao *NotificacionDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM notificaciones WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *NotificacionDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM notificacion WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
@@ -15,13 +15,15 @@ func TestNotificacionDAO_Guardar(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	notificacionDAO := NuevoNotificacionDAO(dbPool)
+	notificacionDAO := NuevoNotificacionDAO()
 
 	// Crear notificación de prueba
 	id := uuid.New()
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 	contenido := "Notificación de prueba para test"
 	fecha := time.Now()
 	// UUID vacío para invitacionID ya que no necesitamos asociarlo a una invitación
@@ -34,13 +36,13 @@ func TestNotificacionDAO_Guardar(t *testing.T) {
 	}
 
 	// Guardar notificación en la base de datos
-	err = notificacionDAO.Guardar(notificacion)
+	err = notificacionDAO.Guardar(ctx, dbPool, notificacion)
 	if err != nil {
 		t.Fatalf("Error al guardar notificación: %v", err)
 	}
 
 	// Verificar que se guardó correctamente reobteniendo por ID
-	reobtenida, err := notificacionDAO.BuscarPorID(id)
+	reobtenida, err := notificacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error al obtener notificación guardada: %v", err)
 	}
@@ -65,7 +67,7 @@ func TestNotificacionDAO_Guardar(t *testing.T) {
 	// Eliminar la notificación después de la prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM notificaciones WHERE id = ?",
+		"DELETE FROM notificacion WHERE id = ?",
 		notificacion.ID().String(),
 	)
 	if err != nil {
@@ -78,13 +80,15 @@ func TestNotificacionDAO_BuscarPorID(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	notificacionDAO := NuevoNotificacionDAO(dbPool)
+	notificacionDAO := NuevoNotificacionDAO()
 
 	// Crear notificación de prueba
 	id := uuid.New()
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 	contenido := "Notificación para buscar por ID"
 	fecha := time.Now()
 	invitacionID := uuid.Nil
@@ -96,13 +100,13 @@ func TestNotificacionDAO_BuscarPorID(t *testing.T) {
 	}
 
 	// Guardar notificación en la base de datos
-	err = notificacionDAO.Guardar(notificacion)
+	err = notificacionDAO.Guardar(ctx, dbPool, notificacion)
 	if err != nil {
 		t.Fatalf("Error al guardar notificación: %v", err)
 	}
 
 	// Buscar la notificación por ID
-	reobtenida, err := notificacionDAO.BuscarPorID(id)
+	reobtenida, err := notificacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error al buscar notificación por ID: %v", err)
 	}
@@ -127,7 +131,7 @@ func TestNotificacionDAO_BuscarPorID(t *testing.T) {
 	// Limpiar después de la prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM notificaciones WHERE id = ?",
+		"DELETE FROM notificacion WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -140,12 +144,14 @@ func TestNotificacionDAO_BuscarPorUsuarioID(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	notificacionDAO := NuevoNotificacionDAO(dbPool)
+	notificacionDAO := NuevoNotificacionDAO()
 
 	// Generar un ID de usuario al azar para esta prueba
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 
 	// Crear varias notificaciones para el mismo usuario
 	notificaciones := make([]*model.Notificacion, 0)
@@ -162,7 +168,7 @@ func TestNotificacionDAO_BuscarPorUsuarioID(t *testing.T) {
 		}
 
 		// Guardar notificación en la base de datos
-		err = notificacionDAO.Guardar(notificacion)
+		err = notificacionDAO.Guardar(ctx, dbPool, notificacion)
 		if err != nil {
 			t.Fatalf("Error al guardar notificación %d: %v", i+1, err)
 		}
@@ -171,7 +177,7 @@ func TestNotificacionDAO_BuscarPorUsuarioID(t *testing.T) {
 	}
 
 	// Buscar las notificaciones por usuario ID
-	resultados, err := notificacionDAO.BuscarPorUsuarioID(usuarioID)
+	resultados, err := notificacionDAO.BuscarPorUsuarioID(ctx, dbPool, usuarioID)
 	if err != nil {
 		t.Fatalf("Error al buscar notificaciones por usuario ID: %v", err)
 	}
@@ -186,7 +192,7 @@ func TestNotificacionDAO_BuscarPorUsuarioID(t *testing.T) {
 	for _, notif := range notificaciones {
 		_, err = dbPool.ExecContext(
 			context.Background(),
-			"DELETE FROM notificaciones WHERE id = ?",
+			"DELETE FROM notificacion WHERE id = ?",
 			notif.ID().String(),
 		)
 		if err != nil {
@@ -200,13 +206,15 @@ func TestNotificacionDAO_ActualizarEstadoLeido(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	notificacionDAO := NuevoNotificacionDAO(dbPool)
+	notificacionDAO := NuevoNotificacionDAO()
 
 	// Crear notificación de prueba (inicialmente no leída)
 	id := uuid.New()
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 	contenido := "Notificación para actualizar estado"
 	fecha := time.Now()
 	invitacionID := uuid.Nil
@@ -223,19 +231,19 @@ func TestNotificacionDAO_ActualizarEstadoLeido(t *testing.T) {
 	}
 
 	// Guardar notificación en la base de datos
-	err = notificacionDAO.Guardar(notificacion)
+	err = notificacionDAO.Guardar(ctx, dbPool, notificacion)
 	if err != nil {
 		t.Fatalf("Error al guardar notificación: %v", err)
 	}
 
 	// Marcar como leída
-	err = notificacionDAO.ActualizarEstadoLeido(id, true)
+	err = notificacionDAO.ActualizarEstadoLeido(ctx, dbPool, id, true)
 	if err != nil {
 		t.Fatalf("Error al actualizar estado leído: %v", err)
 	}
 
 	// Verificar que se actualizó correctamente
-	reobtenida, err := notificacionDAO.BuscarPorID(id)
+	reobtenida, err := notificacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error al obtener notificación actualizada: %v", err)
 	}
@@ -247,7 +255,7 @@ func TestNotificacionDAO_ActualizarEstadoLeido(t *testing.T) {
 	// Limpiar después de la prueba
 	_, err = dbPool.ExecContext(
 		context.Background(),
-		"DELETE FROM notificaciones WHERE id = ?",
+		"DELETE FROM notificacion WHERE id = ?",
 		id.String(),
 	)
 	if err != nil {
@@ -260,13 +268,15 @@ func TestNotificacionDAO_Eliminar(t *testing.T) {
 	// Inicializar conexión a la base de datos
 	dbPool := setupTestDB(t)
 	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
 
 	// Crear instancia del DAO
-	notificacionDAO := NuevoNotificacionDAO(dbPool)
+	notificacionDAO := NuevoNotificacionDAO()
 
 	// Crear notificación de prueba
 	id := uuid.New()
 	usuarioID := uuid.New()
+	crearUsuarioPrueba(t, dbPool, usuarioID)
 	contenido := "Notificación para eliminar"
 	fecha := time.Now()
 	invitacionID := uuid.Nil
@@ -278,19 +288,19 @@ func TestNotificacionDAO_Eliminar(t *testing.T) {
 	}
 
 	// Guardar notificación en la base de datos
-	err = notificacionDAO.Guardar(notificacion)
+	err = notificacionDAO.Guardar(ctx, dbPool, notificacion)
 	if err != nil {
 		t.Fatalf("Error al guardar notificación: %v", err)
 	}
 
 	// Eliminar la notificación
-	err = notificacionDAO.Eliminar(id)
+	err = notificacionDAO.Eliminar(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error al eliminar notificación: %v", err)
 	}
 
 	// Verificar que ya no existe
-	reobtenida, err := notificacionDAO.BuscarPorID(id)
+	reobtenida, err := notificacionDAO.BuscarPorID(ctx, dbPool, id)
 	if err != nil {
 		t.Fatalf("Error al buscar notificación eliminada: %v", err)
 	}
@@ -0,0 +1,29 @@
+package dao
+
+import (
+	"model"
+)
+
+// clausulaPagina completa una consulta cuyo WHERE ya filtra las filas del listado con la
+// condición de conjunto de claves sobre (columnaTiempo, columnaID), el orden de la
+// dirección pedida y un LIMIT de una fila más que la página, para saber si hay más.
+// La comparación se expande en lugar de usar (a, b) < (?, ?) para que MySQL use el
+// índice y la consulta sea la misma en SQLite.
+func clausulaPagina(columnaTiempo, columnaID string, consulta model.ConsultaPagina) (string, []interface{}) {
+	comparador, orden := "<", "DESC"
+	if consulta.Direccion() == model.PaginaPosteriores {
+		comparador, orden = ">", "ASC"
+	}
+
+	var clausula string
+	var args []interface{}
+	if cursor := consulta.Cursor(); !cursor.IsZero() {
+		clausula = " AND (" + columnaTiempo + " " + comparador + " ? OR (" +
+			columnaTiempo + " = ? AND " + columnaID + " " + comparador + " ?))"
+		args = append(args, cursor.Timestamp(), cursor.Timestamp(), cursor.ID().String())
+	}
+
+	clausula += " ORDER BY " + columnaTiempo + " " + orden + ", " + columnaID + " " + orden + " LIMIT ?"
+	args = append(args, consulta.Limite()+1)
+	return clausula, args
+}
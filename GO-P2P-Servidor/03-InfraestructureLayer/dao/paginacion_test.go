@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestPaginacion_MensajesCanal recorre los mensajes de un canal en páginas hacia atrás y
+// hacia delante y comprueba que el orden (timestamp, id) se respeta sin saltos ni
+// repeticiones aunque varios mensajes compartan timestamp
+func TestPaginacion_MensajesCanal(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+
+	remitenteID, canalID := uuid.New(), uuid.New()
+	crearUsuarioPrueba(t, dbPool, remitenteID)
+	canal, err := model.NewCanalServidor(canalID, "paginado", "Canal paginado", model.CanalPublico)
+	require.NoError(t, err)
+	require.NoError(t, NuevoCanalDAO().Crear(ctx, dbPool, canal))
+
+	mensajeDAO := NuevoMensajeDAO()
+	base := time.Now().UTC().Truncate(time.Second)
+	var esperados []*model.MensajeServidor
+	for i, segundos := range []int{0, 1, 1, 1, 2, 3, 3} {
+		mensaje, err := model.NewMensajeCanal(uuid.New(), remitenteID, canalID, "mensaje", base.Add(time.Duration(segundos)*time.Second), uuid.Nil)
+		require.NoError(t, err, "mensaje %d", i)
+		require.NoError(t, mensajeDAO.Crear(ctx, dbPool, mensaje))
+		esperados = append(esperados, mensaje)
+	}
+	sort.Slice(esperados, func(i, j int) bool {
+		if !esperados[i].Timestamp().Equal(esperados[j].Timestamp()) {
+			return esperados[i].Timestamp().Before(esperados[j].Timestamp())
+		}
+		return esperados[i].ID().String() < esperados[j].ID().String()
+	})
+
+	// Hacia atrás desde el mensaje más reciente
+	var haciaAtras []uuid.UUID
+	cursor := ""
+	for paginas := 0; ; paginas++ {
+		require.Less(t, paginas, len(esperados), "la paginación no termina")
+		consulta, err := model.NewConsultaPagina(cursor, model.PaginaAnteriores, 2)
+		require.NoError(t, err)
+		pagina, err := mensajeDAO.BuscarPorCanalID(ctx, dbPool, canalID, consulta)
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, m := range pagina.Elementos {
+			ids = append(ids, m.ID())
+		}
+		haciaAtras = append(ids, haciaAtras...)
+		if !pagina.HayAnteriores {
+			break
+		}
+		cursor = pagina.Anterior
+	}
+	assert.Equal(t, idsMensajes(esperados), haciaAtras)
+
+	// Hacia delante desde el más antiguo
+	var haciaDelante []uuid.UUID
+	cursor = ""
+	for paginas := 0; ; paginas++ {
+		require.Less(t, paginas, len(esperados), "la paginación no termina")
+		consulta, err := model.NewConsultaPagina(cursor, model.PaginaPosteriores, 3)
+		require.NoError(t, err)
+		pagina, err := mensajeDAO.BuscarPorCanalID(ctx, dbPool, canalID, consulta)
+		require.NoError(t, err)
+		for _, m := range pagina.Elementos {
+			haciaDelante = append(haciaDelante, m.ID())
+		}
+		if !pagina.HayPosteriores {
+			break
+		}
+		cursor = pagina.Siguiente
+	}
+	assert.Equal(t, idsMensajes(esperados), haciaDelante)
+}
+
+func idsMensajes(mensajes []*model.MensajeServidor) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, m := range mensajes {
+		ids = append(ids, m.ID())
+	}
+	return ids
+}
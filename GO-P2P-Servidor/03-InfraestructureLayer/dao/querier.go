@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+
+	"pool"
+)
+
+// Querier ejecuta las consultas de los DAO. Lo implementan *pool.DBConnectionPool, que
+// registra métricas y reparte las lecturas entre réplicas, y *sql.Tx, con el que varias
+// operaciones de distintos DAO forman una sola transacción.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+var (
+	_ Querier = (*pool.DBConnectionPool)(nil)
+	_ Querier = (*sql.Tx)(nil)
+)
+
+// txKey es la clave de contexto con la transacción de la unidad de trabajo en curso
+type txKey struct{}
+
+// transaccionEnCurso es la transacción de un intento de la unidad de trabajo y las
+// acciones que esperan a que se confirme
+type transaccionEnCurso struct {
+	tx          *sql.Tx
+	alConfirmar []func()
+}
+
+// conTransaccion devuelve un contexto que lleva la transacción
+func conTransaccion(ctx context.Context, t *transaccionEnCurso) context.Context {
+	return context.WithValue(ctx, txKey{}, t)
+}
+
+// transaccionDesdeContexto devuelve la transacción que lleva el contexto, si la hay
+func transaccionDesdeContexto(ctx context.Context) (*transaccionEnCurso, bool) {
+	t, ok := ctx.Value(txKey{}).(*transaccionEnCurso)
+	return t, ok
+}
+
+// AlConfirmar ejecuta fn cuando se confirme la transacción que lleva el contexto, o en
+// el acto si no lleva ninguna. Si la transacción se revierte, fn no se ejecuta. Sirve
+// para reflejar fuera de la base de datos, por ejemplo en una caché, solo lo que
+// realmente se guardó.
+func AlConfirmar(ctx context.Context, fn func()) {
+	if t, ok := transaccionDesdeContexto(ctx); ok {
+		t.alConfirmar = append(t.alConfirmar, fn)
+		return
+	}
+	fn()
+}
+
+// QuerierDesdeContexto devuelve la transacción de la unidad de trabajo en curso o, si el
+// contexto no lleva ninguna, el pool. Los repositorios lo usan para que sus operaciones
+// participen en la transacción de quien los llama.
+func QuerierDesdeContexto(ctx context.Context, dbPool *pool.DBConnectionPool) Querier {
+	if t, ok := transaccionDesdeContexto(ctx); ok {
+		return t.tx
+	}
+	return dbPool
+}
@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// ReaccionMensajeDAO maneja las operaciones de base de datos para las reacciones con
+// emoji de los mensajes
+type ReaccionMensajeDAO struct{}
+
+// NuevoReaccionMensajeDAO crea una nueva instancia de ReaccionMensajeDAO
+func NuevoReaccionMensajeDAO() *ReaccionMensajeDAO {
+	return &ReaccionMensajeDAO{}
+}
+
+// Guardar crea o sustituye la reacción de un usuario a un mensaje con un emoji
+func (dao *ReaccionMensajeDAO) Guardar(ctx context.Context, q Querier, reaccion *model.ReaccionMensaje) error {
+	query := `REPLACE INTO reaccion_mensaje (mensaje_id, usuario_id, emoji, fecha,
+              retirada_at, version_hlc)
+              VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		reaccion.MensajeID().String(),
+		reaccion.UsuarioID().String(),
+		reaccion.Emoji(),
+		reaccion.Fecha(),
+		nullableTime(reaccion.RetiradaAt()),
+		nullableHLC(reaccion.Version()),
+	)
+
+	return err
+}
+
+// BuscarPorClave recupera la reacción de un usuario a un mensaje con un emoji, vigente
+// o retirada. Devuelve nil si no existe.
+func (dao *ReaccionMensajeDAO) BuscarPorClave(ctx context.Context, q Querier, mensajeID, usuarioID uuid.UUID, emoji string) (*model.ReaccionMensaje, error) {
+	query := `SELECT mensaje_id, usuario_id, emoji, fecha, retirada_at, version_hlc
+              FROM reaccion_mensaje
+              WHERE mensaje_id = ? AND usuario_id = ? AND emoji = ?`
+
+	rows, err := q.QueryContext(ctx, query, mensajeID.String(), usuarioID.String(), emoji)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reacciones, err := dao.escanearReacciones(rows)
+	if err != nil || len(reacciones) == 0 {
+		return nil, err
+	}
+	return reacciones[0], nil
+}
+
+// BuscarPorRangoTiempo recupera las reacciones, vigentes o retiradas, cuyo último cambio
+// está en [desde, hasta)
+func (dao *ReaccionMensajeDAO) BuscarPorRangoTiempo(ctx context.Context, q Querier, desde, hasta time.Time) ([]*model.ReaccionMensaje, error) {
+	query := `SELECT mensaje_id, usuario_id, emoji, fecha, retirada_at, version_hlc
+              FROM reaccion_mensaje
+              WHERE COALESCE(retirada_at, fecha) >= ? AND COALESCE(retirada_at, fecha) < ?
+              ORDER BY mensaje_id, usuario_id, emoji`
+
+	rows, err := q.QueryContext(ctx, query, desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dao.escanearReacciones(rows)
+}
+
+// ContarPorMensajes devuelve, para cada mensaje con reacciones vigentes, el número de
+// reacciones por emoji, de más a menos usado y a igualdad por orden de aparición
+func (dao *ReaccionMensajeDAO) ContarPorMensajes(ctx context.Context, q Querier, mensajeIDs []uuid.UUID) (map[uuid.UUID][]model.ConteoReaccion, error) {
+	conteos := make(map[uuid.UUID][]model.ConteoReaccion)
+	if len(mensajeIDs) == 0 {
+		return conteos, nil
+	}
+
+	query := `SELECT mensaje_id, emoji, COUNT(*)
+              FROM reaccion_mensaje
+              WHERE retirada_at IS NULL
+              AND mensaje_id IN (?` + strings.Repeat(", ?", len(mensajeIDs)-1) + `)
+              GROUP BY mensaje_id, emoji
+              ORDER BY mensaje_id, COUNT(*) DESC, MIN(fecha), emoji`
+	args := make([]interface{}, 0, len(mensajeIDs))
+	for _, id := range mensajeIDs {
+		args = append(args, id.String())
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			mensajeIDStr, emoji string
+			total               int
+		)
+		if err := rows.Scan(&mensajeIDStr, &emoji, &total); err != nil {
+			return nil, err
+		}
+		mensajeID, err := uuid.Parse(mensajeIDStr)
+		if err != nil {
+			return nil, err
+		}
+		conteos[mensajeID] = append(conteos[mensajeID], model.ConteoReaccion{Emoji: emoji, Total: total})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return conteos, nil
+}
+
+// escanearReacciones convierte las filas leídas en reacciones
+func (dao *ReaccionMensajeDAO) escanearReacciones(rows *sql.Rows) ([]*model.ReaccionMensaje, error) {
+	var reacciones []*model.ReaccionMensaje
+	for rows.Next() {
+		var (
+			mensajeIDStr, usuarioIDStr, emoji string
+			fecha                             time.Time
+			retiradaAt                        sql.NullTime
+			versionStr                        sql.NullString
+		)
+		if err := rows.Scan(&mensajeIDStr, &usuarioIDStr, &emoji, &fecha, &retiradaAt, &versionStr); err != nil {
+			return nil, err
+		}
+
+		mensajeID, err := uuid.Parse(mensajeIDStr)
+		if err != nil {
+			return nil, err
+		}
+		usuarioID, err := uuid.Parse(usuarioIDStr)
+		if err != nil {
+			return nil, err
+		}
+		version, err := parseNullableHLC(versionStr)
+		if err != nil {
+			return nil, err
+		}
+
+		reaccion, err := model.NewReaccionMensaje(mensajeID, usuarioID, emoji, fecha)
+		if err != nil {
+			return nil, err
+		}
+		reaccion.SetRetiradaAt(retiradaAt.Time)
+		reaccion.SetVersion(version)
+		reacciones = append(reacciones, reaccion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reacciones, nil
+}
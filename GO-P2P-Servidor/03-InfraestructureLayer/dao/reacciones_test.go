@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// TestReacciones_ConteoYRetirada comprueba que el recuento por emoji solo incluye las
+// reacciones vigentes, ordenadas de más a menos usadas, que volver a reaccionar sustituye
+// la fila retirada y que los emoji se distinguen byte a byte
+func TestReacciones_ConteoYRetirada(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+	ahora := time.Now().UTC().Truncate(time.Second)
+
+	anaID, betoID, carlaID := uuid.New(), uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{anaID, betoID, carlaID} {
+		crearUsuarioPrueba(t, dbPool, id)
+	}
+	mensajeDAO := NuevoMensajeDAO()
+	var mensajes []*model.MensajeServidor
+	for _, contenido := range []string{"primero", "segundo", "sin reacciones"} {
+		m, err := model.NewMensajeDirecto(uuid.New(), anaID, betoID, contenido, ahora, uuid.Nil)
+		require.NoError(t, err)
+		require.NoError(t, mensajeDAO.Crear(ctx, dbPool, m))
+		mensajes = append(mensajes, m)
+	}
+
+	reaccionDAO := NuevoReaccionMensajeDAO()
+	reaccionar := func(m *model.MensajeServidor, usuarioID uuid.UUID, emoji string, at time.Time) *model.ReaccionMensaje {
+		t.Helper()
+		r, err := model.NewReaccionMensaje(m.ID(), usuarioID, emoji, at)
+		require.NoError(t, err)
+		require.NoError(t, reaccionDAO.Guardar(ctx, dbPool, r))
+		return r
+	}
+	reaccionar(mensajes[0], anaID, "🎉", ahora)
+	reaccionar(mensajes[0], anaID, "👍", ahora.Add(time.Second))
+	reaccionar(mensajes[0], betoID, "👍", ahora.Add(2*time.Second))
+	reaccionar(mensajes[0], carlaID, "👍🏽", ahora.Add(3*time.Second))
+	retirada := reaccionar(mensajes[1], carlaID, "❤️", ahora)
+	require.NoError(t, retirada.Retirar(ahora.Add(time.Minute)))
+	require.NoError(t, reaccionDAO.Guardar(ctx, dbPool, retirada))
+
+	ids := []uuid.UUID{mensajes[0].ID(), mensajes[1].ID(), mensajes[2].ID()}
+	conteos, err := reaccionDAO.ContarPorMensajes(ctx, dbPool, ids)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID][]model.ConteoReaccion{
+		mensajes[0].ID(): {{Emoji: "👍", Total: 2}, {Emoji: "🎉", Total: 1}, {Emoji: "👍🏽", Total: 1}},
+	}, conteos)
+
+	leida, err := reaccionDAO.BuscarPorClave(ctx, dbPool, mensajes[1].ID(), carlaID, "❤️")
+	require.NoError(t, err)
+	require.NotNil(t, leida)
+	assert.False(t, leida.Vigente())
+	assert.True(t, leida.RetiradaAt().Equal(ahora.Add(time.Minute)))
+
+	// Volver a reaccionar sustituye la reacción retirada
+	reaccionar(mensajes[1], carlaID, "❤️", ahora.Add(2*time.Minute))
+	conteos, err = reaccionDAO.ContarPorMensajes(ctx, dbPool, ids)
+	require.NoError(t, err)
+	assert.Equal(t, []model.ConteoReaccion{{Emoji: "❤️", Total: 1}}, conteos[mensajes[1].ID()])
+
+	// El rango usa el último cambio: la retirada o, si está vigente, el alta
+	cambios, err := reaccionDAO.BuscarPorRangoTiempo(ctx, dbPool, ahora.Add(time.Minute), ahora.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, cambios, 1)
+	assert.Equal(t, "❤️", cambios[0].Emoji())
+
+	// Las reacciones se borran con su mensaje
+	require.NoError(t, mensajeDAO.Eliminar(ctx, dbPool, mensajes[0].ID()))
+	conteos, err = reaccionDAO.ContarPorMensajes(ctx, dbPool, ids[:1])
+	require.NoError(t, err)
+	assert.Empty(t, conteos)
+}
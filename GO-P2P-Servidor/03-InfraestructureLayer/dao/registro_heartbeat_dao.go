@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
 	"github.com/google/uuid"
 	"model"
@@ -8,21 +9,20 @@ import (
 )
 
 // HeartbeatLogMySQLDAO handles database operations for HeartbeatLog entities
-type HeartbeatLogMySQLDAO struct {
-	db *sql.DB
-}
+type HeartbeatLogMySQLDAO struct{}
 
 // NewHeartbeatLogMySQLDAO creates a new HeartbeatLogMySQLDAO instance
-func NewHeartbeatLogMySQLDAO(db *sql.DB) *HeartbeatLogMySQLDAO {
-	return &HeartbeatLogMySQLDAO{db: db}
+func NewHeartbeatLogMySQLDAO() *HeartbeatLogMySQLDAO {
+	return &HeartbeatLogMySQLDAO{}
 }
 
 // Save persists a heartbeat log to the database
-func (dao *HeartbeatLogMySQLDAO) Save(log *model.HeartbeatLog) error {
-	query := `INSERT INTO heartbeat_logs (id, nodo_id, enviado_at, recibido_at)
+func (dao *HeartbeatLogMySQLDAO) Save(ctx context.Context, q Querier, log *model.HeartbeatLog) error {
+	query := `INSERT INTO heartbeat_log (id, nodo_id, enviado_at, recibido_at)
               VALUES (?, ?, ?, ?)`
 
-	_, err := dao.db.Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		log.ID().String(),
 		log.NodoID().String(),
@@ -34,20 +34,20 @@ func (dao *HeartbeatLogMySQLDAO) Save(log *model.HeartbeatLog) error {
 }
 
 // FindByID retrieves a heartbeat log by its ID
-func (dao *HeartbeatLogMySQLDAO) FindByID(id uuid.UUID) (*model.HeartbeatLog, error) {
+func (dao *HeartbeatLogMySQLDAO) FindByID(ctx context.Context, q Querier, id uuid.UUID) (*model.HeartbeatLog, error) {
 	query := `SELECT id, nodo_id, enviado_at, recibido_at
-              FROM heartbeat_logs WHERE id = ?`
+              FROM heartbeat_log WHERE id = ?`
 
-	row := dao.db.QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.scanHeartbeatLog(row)
 }
 
 // FindAll retrieves all heartbeat logs from the database
-func (dao *HeartbeatLogMySQLDAO) FindAll() ([]*model.HeartbeatLog, error) {
+func (dao *HeartbeatLogMySQLDAO) FindAll(ctx context.Context, q Querier) ([]*model.HeartbeatLog, error) {
 	query := `SELECT id, nodo_id, enviado_at, recibido_at
-              FROM heartbeat_logs`
+              FROM heartbeat_log`
 
-	rows, err := dao.db.Query(query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -57,11 +57,11 @@ func (dao *HeartbeatLogMySQLDAO) FindAll() ([]*model.HeartbeatLog, error) {
 }
 
 // FindByNodoID retrieves all heartbeat logs for a specific node
-func (dao *HeartbeatLogMySQLDAO) FindByNodoID(nodoID uuid.UUID) ([]*model.HeartbeatLog, error) {
+func (dao *HeartbeatLogMySQLDAO) FindByNodoID(ctx context.Context, q Querier, nodoID uuid.UUID) ([]*model.HeartbeatLog, error) {
 	query := `SELECT id, nodo_id, enviado_at, recibido_at
-              FROM heartbeat_logs WHERE nodo_id = ?`
+              FROM heartbeat_log WHERE nodo_id = ?`
 
-	rows, err := dao.db.Query(query, nodoID.String())
+	rows, err := q.QueryContext(ctx, query, nodoID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +71,12 @@ func (dao *HeartbeatLogMySQLDAO) FindByNodoID(nodoID uuid.UUID) ([]*model.Heartb
 }
 
 // FindByTimeRange retrieves heartbeat logs within a specific time range
-func (dao *HeartbeatLogMySQLDAO) FindByTimeRange(start, end time.Time) ([]*model.HeartbeatLog, error) {
+func (dao *HeartbeatLogMySQLDAO) FindByTimeRange(ctx context.Context, q Querier, start, end time.Time) ([]*model.HeartbeatLog, error) {
 	query := `SELECT id, nodo_id, enviado_at, recibido_at
-              FROM heartbeat_logs 
+              FROM heartbeat_log 
               WHERE enviado_at BETWEEN ? AND ?`
 
-	rows, err := dao.db.Query(query, start, end)
+	rows, err := q.QueryContext(ctx, query, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -86,9 +86,9 @@ func (dao *HeartbeatLogMySQLDAO) FindByTimeRange(start, end time.Time) ([]*model
 }
 
 // Delete removes a heartbeat log from the database
-func (dao *HeartbeatLogMySQLDAO) Delete(id uuid.UUID) error {
-	query := `DELETE FROM heartbeat_logs WHERE id = ?`
-	_, err := dao.db.Exec(query, id.String())
+func (dao *HeartbeatLogMySQLDAO) Delete(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM heartbeat_log WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
@@ -0,0 +1,105 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// SilencioMencionDAO maneja las operaciones de base de datos para las preferencias de
+// silencio de menciones de los usuarios
+type SilencioMencionDAO struct{}
+
+// NuevoSilencioMencionDAO crea una nueva instancia de SilencioMencionDAO
+func NuevoSilencioMencionDAO() *SilencioMencionDAO {
+	return &SilencioMencionDAO{}
+}
+
+// Guardar crea o sustituye la preferencia de un usuario para un canal o, con canal
+// uuid.Nil, para todos
+func (dao *SilencioMencionDAO) Guardar(ctx context.Context, q Querier, s *model.SilencioMenciones) error {
+	query := `REPLACE INTO silencio_mencion (usuario_id, canal_id, nivel, hasta, actualizado_at)
+              VALUES (?, ?, ?, ?, ?)`
+
+	_, err := q.ExecContext(
+		ctx,
+		query,
+		s.UsuarioID().String(),
+		s.CanalID().String(),
+		string(s.Nivel()),
+		nullableTime(s.Hasta()),
+		s.Actualizado(),
+	)
+
+	return err
+}
+
+// BuscarPorClave recupera la preferencia de un usuario para un canal; devuelve nil si
+// no la ha configurado
+func (dao *SilencioMencionDAO) BuscarPorClave(ctx context.Context, q Querier, usuarioID, canalID uuid.UUID) (*model.SilencioMenciones, error) {
+	query := `SELECT usuario_id, canal_id, nivel, hasta, actualizado_at
+              FROM silencio_mencion WHERE usuario_id = ? AND canal_id = ?`
+
+	rows, err := q.QueryContext(ctx, query, usuarioID.String(), canalID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	silencios, err := dao.escanearSilencios(rows)
+	if err != nil || len(silencios) == 0 {
+		return nil, err
+	}
+	return silencios[0], nil
+}
+
+// BuscarPorUsuarioID recupera todas las preferencias de un usuario, la general primero
+func (dao *SilencioMencionDAO) BuscarPorUsuarioID(ctx context.Context, q Querier, usuarioID uuid.UUID) ([]*model.SilencioMenciones, error) {
+	query := `SELECT usuario_id, canal_id, nivel, hasta, actualizado_at
+              FROM silencio_mencion WHERE usuario_id = ? ORDER BY canal_id`
+
+	rows, err := q.QueryContext(ctx, query, usuarioID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return dao.escanearSilencios(rows)
+}
+
+// escanearSilencios convierte las filas leídas en preferencias de silencio
+func (dao *SilencioMencionDAO) escanearSilencios(rows *sql.Rows) ([]*model.SilencioMenciones, error) {
+	var silencios []*model.SilencioMenciones
+	for rows.Next() {
+		var (
+			usuarioIDStr, canalIDStr, nivel string
+			hasta                           sql.NullTime
+			actualizado                     time.Time
+		)
+		if err := rows.Scan(&usuarioIDStr, &canalIDStr, &nivel, &hasta, &actualizado); err != nil {
+			return nil, err
+		}
+
+		usuarioID, err := uuid.Parse(usuarioIDStr)
+		if err != nil {
+			return nil, err
+		}
+		canalID, err := uuid.Parse(canalIDStr)
+		if err != nil {
+			return nil, err
+		}
+		s, err := model.NewSilencioMenciones(usuarioID, canalID, model.NivelSilencio(nivel), hasta.Time, actualizado)
+		if err != nil {
+			return nil, err
+		}
+		silencios = append(silencios, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return silencios, nil
+}
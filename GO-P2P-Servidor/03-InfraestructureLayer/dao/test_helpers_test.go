@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"migration"
+	"model"
+	"pool"
+)
+
+// testDBConfigEnv es la variable de entorno con la ruta a un db_config.yaml para
+// ejecutar las pruebas contra una base de datos real (por ejemplo ../pool/db_config.yaml)
+const testDBConfigEnv = "DAO_TEST_DB_CONFIG"
+
+// setupTestDB configura una conexión a la base de datos para pruebas con el esquema
+// que crean las migraciones. Por defecto usa una base de datos SQLite en memoria,
+// propia de cada prueba; con DAO_TEST_DB_CONFIG se usa la base de datos descrita en
+// ese archivo, cuyo esquema se elimina y se vuelve a crear.
+func setupTestDB(t *testing.T) *pool.DBConnectionPool {
+	var dbPool *pool.DBConnectionPool
+	var err error
+	if configPath := os.Getenv(testDBConfigEnv); configPath != "" {
+		dbPool, err = pool.NewDBConnectionPool(configPath)
+	} else {
+		dbPool, err = pool.NewSQLiteMemoryPool()
+	}
+	if err != nil {
+		t.Fatalf("Error inicializando pool de conexiones: %v", err)
+	}
+	
+	// Verificar que la conexión funciona usando un contexto con timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	
+	err = dbPool.PingContext(ctx)
+	if err != nil {
+		t.Fatalf("Error conectando a la base de datos: %v", err)
+	}
+
+	// Crear el esquema real aplicando las migraciones
+	err = migrateTestDB(dbPool, ctx)
+	if err != nil {
+		t.Fatalf("Error migrando la base de datos de pruebas: %v", err)
+	}
+	
+	return dbPool
+}
+
+// cleanupTestDB realiza limpieza después de las pruebas
+func cleanupTestDB(t *testing.T, dbPool *pool.DBConnectionPool) {
+	// El esquema se recrea al inicio de cada prueba, así que no se eliminan las tablas
+
+	// Cerrar la conexión
+	if dbPool != nil {
+		if err := dbPool.Close(); err != nil {
+			t.Logf("Error cerrando pool de conexiones: %v", err)
+		}
+	}
+}
+
+// migrateTestDB elimina el esquema existente y aplica todas las migraciones embebidas
+func migrateTestDB(dbPool *pool.DBConnectionPool, ctx context.Context) error {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	migrator := migration.NewMigrator(dbPool).WithLogger(log)
+	if err := migrator.LoadEmbeddedMigrations(); err != nil {
+		return fmt.Errorf("error cargando migraciones: %w", err)
+	}
+	if err := migrator.DropSchemaForTesting(ctx); err != nil {
+		return fmt.Errorf("error eliminando esquema: %w", err)
+	}
+	return migrator.Run(ctx)
+}
+
+// crearUsuarioPrueba guarda un usuario con el ID dado para satisfacer las claves
+// foráneas de las tablas que referencian usuario_servidor
+func crearUsuarioPrueba(t *testing.T, q Querier, id uuid.UUID) {
+	t.Helper()
+	usuario, err := model.NewUsuarioServidor(id, "usuario_"+id.String()[:8], id.String()[:8]+"@prueba.com", "hash", "", "127.0.0.1", time.Now())
+	if err != nil {
+		t.Fatalf("Error creando objeto usuario: %v", err)
+	}
+	if err := NuevoUsuarioDAO().Crear(context.Background(), q, usuario); err != nil {
+		t.Fatalf("Error guardando usuario de prueba: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"pool"
+)
+
+// Valores por defecto de los reintentos ante interbloqueos
+const (
+	defaultMaxReintentos   = 3
+	defaultEsperaReintento = 50 * time.Millisecond
+)
+
+// UnidadDeTrabajo ejecuta operaciones de varios DAO en una sola transacción y la repite
+// entera si la base de datos la aborta por un interbloqueo
+type UnidadDeTrabajo struct {
+	dbPool          *pool.DBConnectionPool
+	maxReintentos   int
+	esperaReintento time.Duration
+}
+
+// NuevaUnidadDeTrabajo crea una unidad de trabajo sobre el pool dado
+func NuevaUnidadDeTrabajo(dbPool *pool.DBConnectionPool) *UnidadDeTrabajo {
+	return &UnidadDeTrabajo{
+		dbPool:          dbPool,
+		maxReintentos:   defaultMaxReintentos,
+		esperaReintento: defaultEsperaReintento,
+	}
+}
+
+// ConReintentos configura cuántas veces se repite una transacción abortada por
+// interbloqueo y la espera antes del primer reintento, que se duplica en cada intento
+func (u *UnidadDeTrabajo) ConReintentos(maxReintentos int, espera time.Duration) *UnidadDeTrabajo {
+	u.maxReintentos = maxReintentos
+	u.esperaReintento = espera
+	return u
+}
+
+// Ejecutar ejecuta fn en una transacción y la confirma si fn no devuelve error; en caso
+// contrario la revierte. fn recibe la transacción como Querier y un contexto que la lleva,
+// de modo que los repositorios llamados con ese contexto participan en ella.
+//
+// Si el contexto ya lleva una transacción, fn se ejecuta dentro de ella y es la unidad de
+// trabajo externa quien confirma, revierte o reintenta. fn puede ejecutarse varias veces,
+// así que sus efectos fuera de la base de datos deben registrarse con AlConfirmar.
+func (u *UnidadDeTrabajo) Ejecutar(ctx context.Context, fn func(ctx context.Context, q Querier) error) error {
+	if t, ok := transaccionDesdeContexto(ctx); ok {
+		return fn(ctx, t.tx)
+	}
+
+	espera := u.esperaReintento
+	for intento := 0; ; intento++ {
+		err := u.ejecutarTransaccion(ctx, fn)
+		if err == nil || !EsInterbloqueo(err) || intento >= u.maxReintentos {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(espera):
+		}
+		espera *= 2
+	}
+}
+
+// ejecutarTransaccion ejecuta un intento de la unidad de trabajo
+func (u *UnidadDeTrabajo) ejecutarTransaccion(ctx context.Context, fn func(ctx context.Context, q Querier) error) (err error) {
+	tx, err := u.dbPool.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	enCurso := &transaccionEnCurso{tx: tx}
+	if err := fn(conTransaccion(ctx, enCurso), tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			return fmt.Errorf("%w (error revirtiendo transacción: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	for _, accion := range enCurso.alConfirmar {
+		accion()
+	}
+	return nil
+}
+
+// EsInterbloqueo indica si el error aborta la transacción por un conflicto de bloqueos
+// que se resuelve repitiéndola: interbloqueo o espera de bloqueo agotada en MySQL
+// (1213, 1205) y base de datos o tabla bloqueada en SQLite
+func EsInterbloqueo(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Error 1213") ||
+		strings.Contains(msg, "Error 1205") ||
+		strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "database table is locked")
+}
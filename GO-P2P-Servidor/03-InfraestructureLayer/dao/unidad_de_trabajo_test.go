@@ -0,0 +1,197 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"model"
+)
+
+// crearCanalConMiembro crea un usuario, un canal y su membresía de administrador con el
+// Querier dado
+func crearCanalConMiembro(ctx context.Context, q Querier, canalID, usuarioID uuid.UUID) error {
+	usuario, err := model.NewUsuarioServidor(usuarioID, "usuario_"+usuarioID.String()[:8], usuarioID.String()[:8]+"@prueba.com", "hash", "", "127.0.0.1", time.Now())
+	if err != nil {
+		return err
+	}
+	if err := NuevoUsuarioDAO().Crear(ctx, q, usuario); err != nil {
+		return err
+	}
+	canal, err := model.NewCanalServidor(canalID, "Canal "+canalID.String()[:8], "Canal de la unidad de trabajo", model.CanalPublico)
+	if err != nil {
+		return err
+	}
+	if err := NuevoCanalDAO().Crear(ctx, q, canal); err != nil {
+		return err
+	}
+	miembro, err := model.NewCanalMiembro(canalID, usuarioID, "ADMIN")
+	if err != nil {
+		return err
+	}
+	return NuevoCanalMiembroDAO().Guardar(ctx, q, miembro)
+}
+
+// TestUnidadDeTrabajo_Confirma verifica que las operaciones de varios DAO se confirman juntas
+func TestUnidadDeTrabajo_Confirma(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+
+	canalID, usuarioID := uuid.New(), uuid.New()
+	err := NuevaUnidadDeTrabajo(dbPool).Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+		return crearCanalConMiembro(ctx, q, canalID, usuarioID)
+	})
+	require.NoError(t, err)
+
+	canal, err := NuevoCanalDAO().BuscarPorID(ctx, dbPool, canalID)
+	require.NoError(t, err)
+	assert.NotNil(t, canal)
+	miembro, err := NuevoCanalMiembroDAO().BuscarPorIDs(ctx, dbPool, canalID, usuarioID)
+	require.NoError(t, err)
+	assert.NotNil(t, miembro)
+}
+
+// TestUnidadDeTrabajo_Revierte verifica que un error deshace todas las operaciones
+func TestUnidadDeTrabajo_Revierte(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+
+	canalID := uuid.New()
+	fallo := errors.New("fallo al enviar invitaciones")
+	err := NuevaUnidadDeTrabajo(dbPool).Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+		if err := crearCanalConMiembro(ctx, q, canalID, uuid.New()); err != nil {
+			return err
+		}
+		return fallo
+	})
+	assert.ErrorIs(t, err, fallo)
+
+	canal, err := NuevoCanalDAO().BuscarPorID(ctx, dbPool, canalID)
+	require.NoError(t, err)
+	assert.Nil(t, canal, "el canal no debe existir tras revertir")
+	miembros, err := NuevoCanalMiembroDAO().BuscarPorCanalID(ctx, dbPool, canalID)
+	require.NoError(t, err)
+	assert.Empty(t, miembros)
+}
+
+// TestUnidadDeTrabajo_ReintentaInterbloqueo verifica que una transacción abortada por
+// interbloqueo se repite entera y que otros errores no se reintentan
+func TestUnidadDeTrabajo_ReintentaInterbloqueo(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+
+	unidad := NuevaUnidadDeTrabajo(dbPool).ConReintentos(3, time.Millisecond)
+	canalID := uuid.New()
+	intentos := 0
+	err := unidad.Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+		intentos++
+		if err := crearCanalConMiembro(ctx, q, canalID, uuid.New()); err != nil {
+			return err
+		}
+		if intentos < 3 {
+			return fmt.Errorf("Error 1213 (40001): Deadlock found when trying to get lock")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, intentos)
+
+	// Los intentos abortados se revirtieron: solo queda el miembro del último
+	miembros, err := NuevoCanalMiembroDAO().BuscarPorCanalID(ctx, dbPool, canalID)
+	require.NoError(t, err)
+	assert.Len(t, miembros, 1)
+
+	// Agotados los reintentos se devuelve el error
+	intentos = 0
+	err = unidad.Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+		intentos++
+		return errors.New("database is locked")
+	})
+	assert.True(t, EsInterbloqueo(err))
+	assert.Equal(t, 4, intentos)
+
+	intentos = 0
+	err = unidad.Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+		intentos++
+		return errors.New("Error 1062: Duplicate entry")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, intentos)
+}
+
+// TestUnidadDeTrabajo_Anidada verifica que una unidad de trabajo dentro de otra usa su
+// transacción y que QuerierDesdeContexto la devuelve
+func TestUnidadDeTrabajo_Anidada(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+
+	assert.Equal(t, Querier(dbPool), QuerierDesdeContexto(ctx, dbPool))
+
+	unidad := NuevaUnidadDeTrabajo(dbPool)
+	canalID := uuid.New()
+	fallo := errors.New("fallo externo")
+	err := unidad.Ejecutar(ctx, func(ctx context.Context, externa Querier) error {
+		assert.Equal(t, externa, QuerierDesdeContexto(ctx, dbPool))
+		err := unidad.Ejecutar(ctx, func(ctx context.Context, interna Querier) error {
+			assert.Equal(t, externa, interna)
+			return crearCanalConMiembro(ctx, interna, canalID, uuid.New())
+		})
+		require.NoError(t, err)
+		return fallo
+	})
+	assert.ErrorIs(t, err, fallo)
+
+	// La unidad interna no confirmó por su cuenta: el fallo externo la deshizo
+	canal, err := NuevoCanalDAO().BuscarPorID(ctx, dbPool, canalID)
+	require.NoError(t, err)
+	assert.Nil(t, canal)
+}
+
+// TestUnidadDeTrabajo_AlConfirmar verifica que las acciones registradas con AlConfirmar
+// se ejecutan solo tras confirmar, una vez por intento que confirma, y en el acto fuera
+// de una transacción
+func TestUnidadDeTrabajo_AlConfirmar(t *testing.T) {
+	dbPool := setupTestDB(t)
+	defer cleanupTestDB(t, dbPool)
+	ctx := context.Background()
+
+	var acciones []string
+	AlConfirmar(ctx, func() { acciones = append(acciones, "sin transacción") })
+	assert.Equal(t, []string{"sin transacción"}, acciones)
+
+	unidad := NuevaUnidadDeTrabajo(dbPool).ConReintentos(1, time.Millisecond)
+	acciones = nil
+	err := unidad.Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+		AlConfirmar(ctx, func() { acciones = append(acciones, "revertida") })
+		return errors.New("fallo")
+	})
+	assert.Error(t, err)
+	assert.Empty(t, acciones, "una transacción revertida no ejecuta sus acciones")
+
+	intentos := 0
+	err = unidad.Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+		intentos++
+		AlConfirmar(ctx, func() { acciones = append(acciones, fmt.Sprintf("intento %d", intentos)) })
+		// La unidad anidada registra en la transacción externa
+		require.NoError(t, unidad.Ejecutar(ctx, func(ctx context.Context, q Querier) error {
+			AlConfirmar(ctx, func() { acciones = append(acciones, "anidada") })
+			return nil
+		}))
+		assert.Empty(t, acciones, "nada se ejecuta antes de confirmar")
+		if intentos == 1 {
+			return errors.New("database is locked")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"intento 2", "anidada"}, acciones)
+}
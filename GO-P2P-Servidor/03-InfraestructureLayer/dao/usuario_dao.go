@@ -1,31 +1,30 @@
 package dao
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 	"model"
-	"pool"
 )
 
 // UsuarioDAO gestiona las operaciones de base de datos para entidades UsuarioServidor
-type UsuarioDAO struct {
-	dbPool *pool.DBConnectionPool
-}
+type UsuarioDAO struct{}
 
 // NuevoUsuarioDAO crea una nueva instancia de UsuarioDAO
-func NuevoUsuarioDAO(dbPool *pool.DBConnectionPool) *UsuarioDAO {
-	return &UsuarioDAO{dbPool: dbPool}
+func NuevoUsuarioDAO() *UsuarioDAO {
+	return &UsuarioDAO{}
 }
 
 // Crear persiste un nuevo usuario en la base de datos
-func (dao *UsuarioDAO) Crear(usuario *model.UsuarioServidor) error {
-	query := `INSERT INTO usuarios (id, nombre_usuario, email, contrasena_hasheada, 
-              foto_url, ip_registrada, fecha_registro, is_connected)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+func (dao *UsuarioDAO) Crear(ctx context.Context, q Querier, usuario *model.UsuarioServidor) error {
+	query := `INSERT INTO usuario_servidor (id, nombre_usuario, email, contrasena_hash, 
+              foto_url, ip_registrada, fecha_registro, is_connected, version_hlc)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		usuario.ID().String(),
 		usuario.NombreUsuario(),
@@ -35,39 +34,41 @@ func (dao *UsuarioDAO) Crear(usuario *model.UsuarioServidor) error {
 		usuario.IPRegistrada(),
 		usuario.FechaRegistro(),
 		usuario.IsConnected(),
+		nullableHLC(usuario.Version()),
 	)
 
 	return err
 }
 
 // BuscarPorID recupera un usuario por su ID
-func (dao *UsuarioDAO) BuscarPorID(id uuid.UUID) (*model.UsuarioServidor, error) {
-	query := `SELECT id, nombre_usuario, email, contrasena_hasheada, 
-              foto_url, ip_registrada, fecha_registro, is_connected 
-              FROM usuarios WHERE id = ?`
+func (dao *UsuarioDAO) BuscarPorID(ctx context.Context, q Querier, id uuid.UUID) (*model.UsuarioServidor, error) {
+	query := `SELECT id, nombre_usuario, email, contrasena_hash, 
+              foto_url, ip_registrada, fecha_registro, is_connected, version_hlc
+              FROM usuario_servidor WHERE id = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, id.String())
+	row := q.QueryRowContext(ctx, query, id.String())
 	return dao.escanearUsuario(row)
 }
 
 // BuscarPorEmail recupera un usuario por su correo electrónico
-func (dao *UsuarioDAO) BuscarPorEmail(email string) (*model.UsuarioServidor, error) {
-	query := `SELECT id, nombre_usuario, email, contrasena_hasheada, 
-              foto_url, ip_registrada, fecha_registro, is_connected 
-              FROM usuarios WHERE email = ?`
+func (dao *UsuarioDAO) BuscarPorEmail(ctx context.Context, q Querier, email string) (*model.UsuarioServidor, error) {
+	query := `SELECT id, nombre_usuario, email, contrasena_hash, 
+              foto_url, ip_registrada, fecha_registro, is_connected, version_hlc
+              FROM usuario_servidor WHERE email = ?`
 
-	row := dao.dbPool.DB().QueryRow(query, email)
+	row := q.QueryRowContext(ctx, query, email)
 	return dao.escanearUsuario(row)
 }
 
 // Actualizar actualiza un usuario existente en la base de datos
-func (dao *UsuarioDAO) Actualizar(usuario *model.UsuarioServidor) error {
-	query := `UPDATE usuarios 
-              SET nombre_usuario = ?, email = ?, contrasena_hasheada = ?,
-              foto_url = ?, ip_registrada = ?, is_connected = ?
+func (dao *UsuarioDAO) Actualizar(ctx context.Context, q Querier, usuario *model.UsuarioServidor) error {
+	query := `UPDATE usuario_servidor 
+              SET nombre_usuario = ?, email = ?, contrasena_hash = ?,
+              foto_url = ?, ip_registrada = ?, is_connected = ?, version_hlc = ?
               WHERE id = ?`
 
-	_, err := dao.dbPool.DB().Exec(
+	_, err := q.ExecContext(
+		ctx,
 		query,
 		usuario.NombreUsuario(),
 		usuario.Email(),
@@ -75,6 +76,7 @@ func (dao *UsuarioDAO) Actualizar(usuario *model.UsuarioServidor) error {
 		usuario.FotoURL(),
 		usuario.IPRegistrada(),
 		usuario.IsConnected(),
+		nullableHLC(usuario.Version()),
 		usuario.ID().String(),
 	)
 
@@ -82,19 +84,19 @@ func (dao *UsuarioDAO) Actualizar(usuario *model.UsuarioServidor) error {
 }
 
 // Eliminar borra un usuario de la base de datos
-func (dao *UsuarioDAO) Eliminar(id uuid.UUID) error {
-	query := `DELETE FROM usuarios WHERE id = ?`
-	_, err := dao.dbPool.DB().Exec(query, id.String())
+func (dao *UsuarioDAO) Eliminar(ctx context.Context, q Querier, id uuid.UUID) error {
+	query := `DELETE FROM usuario_servidor WHERE id = ?`
+	_, err := q.ExecContext(ctx, query, id.String())
 	return err
 }
 
 // BuscarTodos recupera todos los usuarios de la base de datos
-func (dao *UsuarioDAO) BuscarTodos() ([]*model.UsuarioServidor, error) {
-	query := `SELECT id, nombre_usuario, email, contrasena_hasheada, 
-              foto_url, ip_registrada, fecha_registro, is_connected 
-              FROM usuarios`
+func (dao *UsuarioDAO) BuscarTodos(ctx context.Context, q Querier) ([]*model.UsuarioServidor, error) {
+	query := `SELECT id, nombre_usuario, email, contrasena_hash, 
+              foto_url, ip_registrada, fecha_registro, is_connected, version_hlc
+              FROM usuario_servidor`
 
-	rows, err := dao.dbPool.DB().Query(query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -120,9 +122,11 @@ func (dao *UsuarioDAO) BuscarTodos() ([]*model.UsuarioServidor, error) {
 func (dao *UsuarioDAO) escanearUsuario(row *sql.Row) (*model.UsuarioServidor, error) {
 	var (
 		idStr, nombreUsuario, email, contrasenaHasheada string
-		fotoURL, ipRegistrada                           string
+		ipRegistrada                                    string
+		fotoURL                                         sql.NullString // NULL en usuarios sin foto, como el administrador inicial
 		fechaRegistro                                   time.Time
 		isConnected                                     bool
+		versionStr                                      sql.NullString
 	)
 
 	if err := row.Scan(
@@ -134,6 +138,7 @@ func (dao *UsuarioDAO) escanearUsuario(row *sql.Row) (*model.UsuarioServidor, er
 		&ipRegistrada,
 		&fechaRegistro,
 		&isConnected,
+		&versionStr,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Usuario no encontrado
@@ -151,7 +156,7 @@ func (dao *UsuarioDAO) escanearUsuario(row *sql.Row) (*model.UsuarioServidor, er
 		nombreUsuario,
 		email,
 		contrasenaHasheada,
-		fotoURL,
+		fotoURL.String,
 		ipRegistrada,
 		fechaRegistro,
 	)
@@ -164,6 +169,12 @@ func (dao *UsuarioDAO) escanearUsuario(row *sql.Row) (*model.UsuarioServidor, er
 		usuario.SetConnected(true)
 	}
 
+	version, err := parseNullableHLC(versionStr)
+	if err != nil {
+		return nil, err
+	}
+	usuario.SetVersion(version)
+
 	return usuario, nil
 }
 
@@ -171,9 +182,11 @@ func (dao *UsuarioDAO) escanearUsuario(row *sql.Row) (*model.UsuarioServidor, er
 func (dao *UsuarioDAO) escanearUsuarioFila(rows *sql.Rows) (*model.UsuarioServidor, error) {
 	var (
 		idStr, nombreUsuario, email, contrasenaHasheada string
-		fotoURL, ipRegistrada                           string
+		ipRegistrada                                    string
+		fotoURL                                         sql.NullString // NULL en usuarios sin foto, como el administrador inicial
 		fechaRegistro                                   time.Time
 		isConnected                                     bool
+		versionStr                                      sql.NullString
 	)
 
 	if err := rows.Scan(
@@ -185,6 +198,7 @@ func (dao *UsuarioDAO) escanearUsuarioFila(rows *sql.Rows) (*model.UsuarioServid
 		&ipRegistrada,
 		&fechaRegistro,
 		&isConnected,
+		&versionStr,
 	); err != nil {
 		return nil, err
 	}
@@ -199,7 +213,7 @@ func (dao *UsuarioDAO) escanearUsuarioFila(rows *sql.Rows) (*model.UsuarioServid
 		nombreUsuario,
 		email,
 		contrasenaHasheada,
-		fotoURL,
+		fotoURL.String,
 		ipRegistrada,
 		fechaRegistro,
 	)
@@ -212,4 +226,11 @@ func (dao *UsuarioDAO) escanearUsuarioFila(rows *sql.Rows) (*model.UsuarioServid
 		usuario.SetConnected(true)
 	}
 
-	return usuario, nil}
+	version, err := parseNullableHLC(versionStr)
+	if err != nil {
+		return nil, err
+	}
+	usuario.SetVersion(version)
+
+	return usuario, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -21,9 +22,18 @@ func main() {
 	showStatus := flag.Bool("status", false, "Solo mostrar estado actual de migraciones")
 	verbose := flag.Bool("verbose", false, "Mostrar logs detallados")
 	timeout := flag.Int("timeout", 60, "Tiempo máximo en segundos para ejecutar las migraciones")
+	down := flag.Int("down", 0, "Revertir las últimas N migraciones aplicadas")
+	toVersion := flag.Int("to", -1, "Migrar hacia arriba o hacia abajo hasta la versión indicada (0 = revertir todas)")
+	lockTimeout := flag.Int("lock-timeout", 60, "Segundos de espera al lock de migraciones de otro proceso (0 = no esperar, negativo = no tomar el lock)")
+	verify := flag.Bool("verify", false, "Verificar checksums y comparar el esquema real con el esperado")
 	
 	flag.Parse()
 	
+	if *down > 0 && *toVersion >= 0 {
+		fmt.Println("Los flags -down y -to son excluyentes")
+		os.Exit(2)
+	}
+	
 	// Configurar logger
 	log := logrus.New()
 	if *verbose {
@@ -49,7 +59,8 @@ func main() {
 		WithDryRun(*dryRun).
 		WithStrictMode(*strict).
 		WithForceVersion(*forceVersion).
-		WithAllowOutOfOrder(*outOfOrder)
+		WithAllowOutOfOrder(*outOfOrder).
+		WithLockTimeout(time.Duration(*lockTimeout) * time.Second)
 	
 	// Cargar migraciones
 	if err := migrator.LoadEmbeddedMigrations(); err != nil {
@@ -62,6 +73,21 @@ func main() {
 		return
 	}
 	
+	// Si solo queremos verificar
+	if *verify {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*timeout)*time.Second)
+		report, err := migrator.Verify(ctx)
+		cancel()
+		if err != nil {
+			log.WithError(err).Fatal("Error verificando migraciones")
+		}
+		showVerifyReport(report)
+		if !report.OK() {
+			os.Exit(1)
+		}
+		return
+	}
+	
 	// Ejecutar migraciones con timeout
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*timeout)*time.Second)
 	defer cancel()
@@ -72,8 +98,21 @@ func main() {
 	}
 	
 	startTime := time.Now()
-	if err := migrator.Run(ctx); err != nil {
-		log.WithError(err).Fatal("Error ejecutando migraciones")
+	switch {
+	case *down > 0:
+		log.Infof("Revirtiendo las últimas %d migraciones...", *down)
+		if err := migrator.Rollback(ctx, *down); err != nil {
+			log.WithError(err).Fatal("Error revirtiendo migraciones")
+		}
+	case *toVersion >= 0:
+		log.Infof("Migrando hasta la versión %d...", *toVersion)
+		if err := migrator.MigrateTo(ctx, *toVersion); err != nil {
+			log.WithError(err).Fatal("Error migrando a la versión indicada")
+		}
+	default:
+		if err := migrator.Run(ctx); err != nil {
+			log.WithError(err).Fatal("Error ejecutando migraciones")
+		}
 	}
 	
 	elapsedTime := time.Since(startTime)
@@ -92,9 +131,9 @@ func showMigrationStatus(migrator *migration.Migrator, log *logrus.Logger) {
 	}
 	
 	fmt.Println("\nEstado actual de las migraciones:")
-	fmt.Println("+----------+---------------+----------------------------------------+----------+")
-	fmt.Println("| Versión  | Estado        | Descripción                            | Aplicada |")
-	fmt.Println("+----------+---------------+----------------------------------------+----------+")
+	fmt.Println("+----------+------+---------------+----------------------------------------+---------------------+")
+	fmt.Println("| Versión  | Tipo | Estado        | Descripción                            | Aplicada            |")
+	fmt.Println("+----------+------+---------------+----------------------------------------+---------------------+")
 	
 	for _, m := range migrations {
 		estado := "PENDIENTE"
@@ -103,6 +142,9 @@ func showMigrationStatus(migrator *migration.Migrator, log *logrus.Logger) {
 			estado = "APLICADA"
 			aplicada = m.AppliedAt.Format("2006-01-02 15:04:05")
 		}
+		if m.Edited() {
+			estado = "MODIFICADA"
+		}
 		
 		// Truncar descripción si es muy larga
 		desc := m.Description
@@ -110,13 +152,36 @@ func showMigrationStatus(migrator *migration.Migrator, log *logrus.Logger) {
 			desc = desc[:37] + "..."
 		}
 		
-		fmt.Printf("| %-8d | %-13s | %-38s | %-8s |\n", 
-			m.Version, estado, desc, aplicada)
+		fmt.Printf("| %-8d | %-4s | %-13s | %-38s | %-19s |\n", 
+			m.Version, m.Kind(), estado, desc, aplicada)
 	}
 	
-	fmt.Println("+----------+---------------+----------------------------------------+----------+")
+	fmt.Println("+----------+------+---------------+----------------------------------------+---------------------+")
 	
 	// Mostrar versión actual
 	currentVersion := migrator.GetCurrentVersion()
 	fmt.Printf("\nVersión actual de la base de datos: %d\n\n", currentVersion)
 }
+
+func showVerifyReport(report *migration.VerifyReport) {
+	if report.OK() {
+		fmt.Println("\nVerificación correcta: migraciones y esquema coinciden")
+		return
+	}
+	
+	fmt.Println("\nResultado de la verificación:")
+	for _, m := range report.Edited {
+		fmt.Printf("  MODIFICADA    %d %s (checksum aplicado %s, actual %s)\n",
+			m.Version, m.Description, m.AppliedChecksum[:12], m.Checksum[:12])
+	}
+	for _, m := range report.Missing {
+		fmt.Printf("  FALTANTE      %d %s (anterior a la versión actual y sin aplicar)\n", m.Version, m.Description)
+	}
+	for _, version := range report.Unknown {
+		fmt.Printf("  DESCONOCIDA   %d (registrada en la base de datos sin script)\n", version)
+	}
+	for _, drift := range report.Drift {
+		fmt.Printf("  DIVERGENCIA   %s\n", drift)
+	}
+	fmt.Println()
+}
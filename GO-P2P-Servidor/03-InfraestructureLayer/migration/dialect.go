@@ -0,0 +1,105 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pool"
+)
+
+// sqliteMigrationsDir contiene las versiones SQLite de los scripts que usan sintaxis
+// exclusiva de MySQL; el resto de scripts de migrations se comparte entre dialectos
+const sqliteMigrationsDir = "migrations/sqlite"
+
+// isSQLite indica si el migrador trabaja sobre una base de datos SQLite
+func (m *Migrator) isSQLite() bool {
+	return m.dbPool != nil && m.dbPool.Driver() == pool.DriverSQLite
+}
+
+// isMissingTableError reconoce el error de tabla inexistente de MySQL y de SQLite
+func isMissingTableError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "doesn't exist") || strings.Contains(msg, "no such table")
+}
+
+// isMissingColumnError reconoce el error de columna inexistente de MySQL y de SQLite
+func isMissingColumnError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Unknown column") || strings.Contains(msg, "no such column")
+}
+
+// columnExists indica si la tabla tiene la columna dada
+func (m *Migrator) columnExists(ctx context.Context, table, column string) (bool, error) {
+	query := `
+		SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?
+	`
+	if m.isSQLite() {
+		query = "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?"
+	}
+
+	var count int
+	if err := m.dbPool.DB().QueryRowContext(ctx, query, table, column).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// schemaColumnsQuery devuelve la consulta que lista (tabla, columna) del esquema actual
+func (m *Migrator) schemaColumnsQuery() string {
+	if m.isSQLite() {
+		return `
+			SELECT t.name, c.name
+			FROM sqlite_master t, pragma_table_info(t.name) c
+			WHERE t.type = 'table' AND t.name NOT LIKE 'sqlite_%'
+		`
+	}
+	return `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = DATABASE()
+	`
+}
+
+// dropSQLiteSchema elimina todas las tablas de una base de datos SQLite. El pragma
+// foreign_keys es propio de cada conexión, así que todo se hace en una conexión dedicada.
+func (m *Migrator) dropSQLiteSchema(ctx context.Context) error {
+	conn, err := m.dbPool.DB().Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error obteniendo conexión: %w", err)
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return fmt.Errorf("error consultando tablas: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return fmt.Errorf("error leyendo nombre de tabla: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error consultando tablas: %w", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("error desactivando foreign keys: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+
+	for _, table := range tables {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, table)); err != nil {
+			m.log.WithError(err).Errorf("Error eliminando tabla %s", table)
+		}
+	}
+
+	m.log.Infof("Esquema eliminado: %d tablas eliminadas", len(tables))
+	return nil
+}
@@ -0,0 +1,83 @@
+package migration
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pool"
+)
+
+// newSQLiteMigrator crea un migrador sobre una base de datos SQLite en memoria
+func newSQLiteMigrator(t *testing.T) (*Migrator, *pool.DBConnectionPool) {
+	t.Helper()
+	dbPool, err := pool.NewSQLiteMemoryPool()
+	require.NoError(t, err)
+	t.Cleanup(func() { dbPool.Close() })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	migrator := NewMigrator(dbPool).WithLogger(log)
+	require.NoError(t, migrator.LoadEmbeddedMigrations())
+	return migrator, dbPool
+}
+
+// TestLoadEmbeddedMigrations_SQLite verifica que los scripts de SQLite sustituyen a los de MySQL
+func TestLoadEmbeddedMigrations_SQLite(t *testing.T) {
+	mysqlMigrator := newTestMigrator()
+	require.NoError(t, mysqlMigrator.LoadEmbeddedMigrations())
+	sqliteMigrator, _ := newSQLiteMigrator(t)
+
+	require.Equal(t, len(mysqlMigrator.Status()), len(sqliteMigrator.Status()))
+
+	// La migración inicial usa ALTER TABLE ... ADD CONSTRAINT en MySQL
+	initialMySQL := mysqlMigrator.GetMigrationByVersion(20250507000001)
+	initialSQLite := sqliteMigrator.GetMigrationByVersion(20250507000001)
+	assert.Contains(t, initialMySQL.SQL, "ADD CONSTRAINT fk_msg_archivo")
+	assert.NotContains(t, initialSQLite.SQL, "ADD CONSTRAINT")
+	assert.NotContains(t, initialSQLite.DownSQL, "DROP FOREIGN KEY")
+	assert.NotEqual(t, initialMySQL.Checksum, initialSQLite.Checksum)
+
+	// Los datos iniciales son SQL portable y se comparten
+	seedMySQL := mysqlMigrator.GetMigrationByVersion(20250507000002)
+	seedSQLite := sqliteMigrator.GetMigrationByVersion(20250507000002)
+	assert.Equal(t, seedMySQL.SQL, seedSQLite.SQL)
+	assert.Equal(t, seedMySQL.DownSQL, seedSQLite.DownSQL)
+}
+
+// TestMigrator_SQLite aplica, verifica y revierte todas las migraciones embebidas en SQLite
+func TestMigrator_SQLite(t *testing.T) {
+	ctx := context.Background()
+	migrator, dbPool := newSQLiteMigrator(t)
+
+	require.NoError(t, migrator.Run(ctx))
+	for _, migration := range migrator.Status() {
+		assert.True(t, migration.Applied, "migración %d sin aplicar", migration.Version)
+	}
+
+	// El esquema real coincide con el que describen los scripts
+	report, err := migrator.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "verificación con diferencias: %+v", report)
+
+	// El CHECK reconstruido de log_entry admite CONFLICTO
+	_, err = dbPool.ExecContext(ctx,
+		"INSERT INTO log_entry (id, tipo_evento, detalle) VALUES ('c1', 'CONFLICTO', 'detalle')")
+	require.NoError(t, err)
+
+	// Una segunda ejecución no tiene nada pendiente
+	again := NewMigrator(dbPool).WithLogger(migrator.log)
+	require.NoError(t, again.LoadEmbeddedMigrations())
+	require.NoError(t, again.Run(ctx))
+
+	require.NoError(t, migrator.MigrateTo(ctx, 0))
+	var tables int
+	require.NoError(t, dbPool.DB().QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name <> ?", migrator.tableName).Scan(&tables))
+	assert.Zero(t, tables, "la reversión completa debe eliminar todas las tablas")
+
+	require.NoError(t, migrator.DropSchemaForTesting(ctx))
+}
@@ -0,0 +1,66 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// GoMigrationFunc es una migración escrita en Go. Recibe la transacción en la que el
+// migrador también registra la migración, de modo que ambas cosas se confirman juntas.
+type GoMigrationFunc func(ctx context.Context, tx *sql.Tx) error
+
+// IsGo indica si la migración está escrita en Go en lugar de SQL
+func (mig *Migration) IsGo() bool {
+	return mig.Up != nil
+}
+
+// Kind devuelve el tipo de migración para mostrarlo en el estado
+func (mig *Migration) Kind() string {
+	if mig.IsGo() {
+		return "GO"
+	}
+	return "SQL"
+}
+
+// RegisterGoMigration añade una migración escrita en Go, que se ordena por versión junto
+// con las migraciones SQL. down puede ser nil si la migración no se puede revertir.
+// Como no hay script que comparar, su checksum se deriva de la versión y la descripción.
+func (m *Migrator) RegisterGoMigration(version int, description string, up, down GoMigrationFunc) error {
+	if version <= 0 {
+		return fmt.Errorf("versión inválida para migración Go: %d", version)
+	}
+	if description == "" {
+		return fmt.Errorf("descripción vacía en migración Go %d", version)
+	}
+	if up == nil {
+		return fmt.Errorf("la migración Go %d no tiene función up", version)
+	}
+	if existing := m.GetMigrationByVersion(version); existing != nil {
+		return fmt.Errorf("versión %d ya registrada como migración %s: %s", version, existing.Kind(), existing.Description)
+	}
+
+	m.migrations = append(m.migrations, &Migration{
+		Version:     version,
+		Description: description,
+		Checksum:    checksumSQL(fmt.Sprintf("go:%d:%s", version, description)),
+		Up:          up,
+		Down:        down,
+	})
+	m.sortMigrations()
+	return nil
+}
+
+// runGoMigration ejecuta una función de migración, convirtiendo un pánico en error
+// para que la transacción se revierta
+func runGoMigration(ctx context.Context, tx *sql.Tx, fn GoMigrationFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pánico en migración Go: %v", r)
+		}
+	}()
+	if err := fn(ctx, tx); err != nil {
+		return fmt.Errorf("error ejecutando migración Go: %w", err)
+	}
+	return nil
+}
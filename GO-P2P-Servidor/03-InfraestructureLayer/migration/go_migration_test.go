@@ -0,0 +1,87 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopGoMigration(ctx context.Context, tx *sql.Tx) error { return nil }
+
+// TestRegisterGoMigration verifica el orden conjunto con las migraciones SQL y la validación
+func TestRegisterGoMigration(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"1_create_a.sql": "CREATE TABLE a (id INT);",
+		"3_create_b.sql": "CREATE TABLE b (id INT);",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	migrator := newTestMigrator()
+	require.NoError(t, migrator.RegisterGoMigration(2, "backfill a", noopGoMigration, nil))
+	require.NoError(t, migrator.LoadMigrationsFromDir(dir))
+	require.NoError(t, migrator.RegisterGoMigration(4, "split config", noopGoMigration, noopGoMigration))
+
+	status := migrator.Status()
+	require.Len(t, status, 4)
+	var kinds []string
+	for i, m := range status {
+		assert.Equal(t, i+1, m.Version)
+		kinds = append(kinds, m.Kind())
+	}
+	assert.Equal(t, []string{"SQL", "GO", "SQL", "GO"}, kinds)
+	assert.False(t, status[1].HasDown())
+	assert.True(t, status[3].HasDown())
+	assert.Len(t, status[1].Checksum, 64)
+
+	// Versiones repetidas y registros incompletos
+	assert.Error(t, migrator.RegisterGoMigration(3, "duplicada", noopGoMigration, nil))
+	assert.Error(t, migrator.RegisterGoMigration(5, "sin up", nil, nil))
+	assert.Error(t, migrator.RegisterGoMigration(0, "versión cero", noopGoMigration, nil))
+	assert.Error(t, migrator.RegisterGoMigration(6, "", noopGoMigration, nil))
+
+	// Un script SQL con la versión de una migración Go se ignora
+	migrator.addScript(2, "otra", "DROP TABLE a;", false)
+	assert.True(t, migrator.GetMigrationByVersion(2).IsGo())
+	assert.Empty(t, migrator.GetMigrationByVersion(2).SQL)
+}
+
+// TestPlanRollback_GoMigration verifica que una migración Go sin Down bloquea la reversión
+func TestPlanRollback_GoMigration(t *testing.T) {
+	migrator := newTestMigrator()
+	require.NoError(t, migrator.RegisterGoMigration(1, "sin down", noopGoMigration, nil))
+	require.NoError(t, migrator.RegisterGoMigration(2, "con down", noopGoMigration, noopGoMigration))
+	for _, m := range migrator.Status() {
+		m.Applied = true
+	}
+
+	plan, err := migrator.planRollback(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, plan[0].Version)
+
+	_, err = migrator.planRollback(2)
+	assert.Error(t, err)
+}
+
+// TestRunGoMigration verifica que errores y pánicos se devuelven como error
+func TestRunGoMigration(t *testing.T) {
+	errBackfill := errors.New("fallo de backfill")
+	err := runGoMigration(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		return errBackfill
+	})
+	assert.ErrorIs(t, err, errBackfill)
+
+	err = runGoMigration(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		panic("fila inesperada")
+	})
+	assert.ErrorContains(t, err, "fila inesperada")
+
+	assert.NoError(t, runGoMigration(context.Background(), nil, noopGoMigration))
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrMigrationLocked indica que otro proceso mantiene el lock de migraciones
+var ErrMigrationLocked = errors.New("otro proceso está ejecutando migraciones")
+
+// LockDisabled, pasado a WithLockTimeout, ejecuta las migraciones sin tomar el lock.
+// Solo es seguro si ningún otro proceso puede migrar la misma base de datos a la vez.
+const LockDisabled time.Duration = -1
+
+// releaseTimeout limita la liberación del lock, que se hace con un contexto propio
+// para poder liberarlo también cuando el contexto de la migración ya se canceló
+const releaseTimeout = 5 * time.Second
+
+// migrationLock es un lock advisory de MySQL (GET_LOCK) ligado a una conexión dedicada:
+// el lock pertenece a la sesión, así que debe liberarse en esa misma conexión
+type migrationLock struct {
+	conn *sql.Conn
+	name string
+}
+
+// withLock ejecuta fn mientras mantiene el lock de migraciones de la base de datos actual.
+// No se bloquea en modo simulación, ya que no se aplican cambios, ni con un timeout
+// negativo como LockDisabled. SQLite no tiene locks advisory: cada migración se aplica
+// en una transacción que bloquea la base de datos para escritura, y una segunda
+// ejecución choca con la clave de la tabla de control.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if m.dryRun || m.lockTimeout < 0 || m.isSQLite() {
+		return fn()
+	}
+
+	lock, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.releaseLock(lock)
+
+	return fn()
+}
+
+// acquireLock espera hasta lockTimeout a obtener el lock, informando de quién lo tiene.
+// Con lockTimeout 0 lo intenta una sola vez sin esperar.
+func (m *Migrator) acquireLock(ctx context.Context) (*migrationLock, error) {
+	conn, err := m.dbPool.DB().Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo conexión para el lock de migraciones: %w", err)
+	}
+
+	// GET_LOCK es global al servidor: el nombre incluye la base de datos
+	lock := &migrationLock{conn: conn}
+	if err := conn.QueryRowContext(ctx, "SELECT CONCAT(DATABASE(), '.', ?)", m.tableName).Scan(&lock.name); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error calculando nombre del lock de migraciones: %w", err)
+	}
+
+	if holder := m.lockHolder(ctx, conn, lock.name); holder != "" {
+		m.log.Infof("Lock de migraciones '%s' tomado por %s, esperando hasta %s", lock.name, holder, m.lockTimeout)
+	}
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lock.name, lockTimeoutSeconds(m.lockTimeout)).Scan(&acquired)
+	if err != nil || !acquired.Valid || acquired.Int64 != 1 {
+		holder := m.lockHolder(context.Background(), conn, lock.name)
+		discardConn(conn)
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo lock de migraciones: %w", err)
+		}
+		if holder == "" {
+			holder = "otro proceso"
+		}
+		return nil, fmt.Errorf("%w: lock '%s' tomado por %s tras esperar %s", ErrMigrationLocked, lock.name, holder, m.lockTimeout)
+	}
+
+	m.log.Infof("Lock de migraciones '%s' obtenido", lock.name)
+	return lock, nil
+}
+
+// releaseLock libera el lock; si no puede confirmarlo descarta la conexión, lo que
+// cierra la sesión en el servidor y libera el lock igualmente
+func (m *Migrator) releaseLock(lock *migrationLock) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	var released sql.NullInt64
+	err := lock.conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", lock.name).Scan(&released)
+	if err != nil || !released.Valid || released.Int64 != 1 {
+		m.log.WithError(err).Warnf("No se pudo liberar el lock de migraciones '%s', cerrando la conexión", lock.name)
+		discardConn(lock.conn)
+		return
+	}
+
+	lock.conn.Close()
+	m.log.Infof("Lock de migraciones '%s' liberado", lock.name)
+}
+
+// lockHolder describe la sesión que tiene el lock, o "" si está libre
+func (m *Migrator) lockHolder(ctx context.Context, conn *sql.Conn, name string) string {
+	var connectionID sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?)", name).Scan(&connectionID); err != nil || !connectionID.Valid {
+		return ""
+	}
+
+	// Sin el privilegio PROCESS solo se ven las sesiones propias
+	var user, host string
+	query := "SELECT USER, HOST FROM information_schema.PROCESSLIST WHERE ID = ?"
+	if err := conn.QueryRowContext(ctx, query, connectionID.Int64).Scan(&user, &host); err != nil {
+		return fmt.Sprintf("la conexión %d", connectionID.Int64)
+	}
+	return fmt.Sprintf("la conexión %d (%s@%s)", connectionID.Int64, user, host)
+}
+
+// discardConn cierra la conexión física en lugar de devolverla al pool, para que
+// ninguna sesión reutilizada conserve el lock
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+// lockTimeoutSeconds convierte el timeout al número de segundos que espera GET_LOCK;
+// 0 intenta tomar el lock sin esperar
+func lockTimeoutSeconds(timeout time.Duration) int {
+	if timeout <= 0 {
+		return 0
+	}
+	return int(math.Max(1, math.Ceil(timeout.Seconds())))
+}
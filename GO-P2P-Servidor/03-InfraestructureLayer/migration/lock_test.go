@@ -0,0 +1,41 @@
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLockTimeoutSeconds verifica la conversión del timeout a segundos de GET_LOCK
+func TestLockTimeoutSeconds(t *testing.T) {
+	testCases := []struct {
+		timeout time.Duration
+		want    int
+	}{
+		{60 * time.Second, 60},
+		{1500 * time.Millisecond, 2},
+		{10 * time.Millisecond, 1},
+		{0, 0},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, lockTimeoutSeconds(tc.timeout), "timeout %s", tc.timeout)
+	}
+}
+
+// TestWithLock_SinBloqueo verifica que la simulación y LockDisabled no tocan la base de datos
+func TestWithLock_SinBloqueo(t *testing.T) {
+	for _, migrator := range []*Migrator{
+		newTestMigrator().WithDryRun(true).WithLockTimeout(time.Minute),
+		newTestMigrator().WithLockTimeout(LockDisabled),
+	} {
+		called := false
+		err := migrator.withLock(context.Background(), func() error {
+			called = true
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.True(t, called)
+	}
+}
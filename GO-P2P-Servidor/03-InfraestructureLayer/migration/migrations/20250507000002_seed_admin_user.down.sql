@@ -0,0 +1,12 @@
+/*--------------------------------------------------------------------
+  Reversión de los datos iniciales
+--------------------------------------------------------------------*/
+
+DELETE FROM configuracion_servidor WHERE id = 1;
+
+DELETE FROM canal_miembro
+ WHERE usuario_id = 'c78f9214-12a1-4aab-8810-0152a8780a7d'
+   AND canal_id   = 'd2a54c86-faee-44d5-b46c-fec88e12528e';
+
+DELETE FROM canal_servidor   WHERE id = 'd2a54c86-faee-44d5-b46c-fec88e12528e';
+DELETE FROM usuario_servidor WHERE id = 'c78f9214-12a1-4aab-8810-0152a8780a7d';
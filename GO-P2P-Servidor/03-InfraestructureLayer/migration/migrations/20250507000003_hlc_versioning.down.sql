@@ -0,0 +1,16 @@
+/*--------------------------------------------------------------------
+  Reversión del versionado HLC: los eventos CONFLICTO no caben en el
+  CHECK original y se descartan
+--------------------------------------------------------------------*/
+
+DELETE FROM log_entry WHERE tipo_evento = 'CONFLICTO';
+ALTER TABLE log_entry DROP CHECK chk_log_entry_tipo_evento;
+ALTER TABLE log_entry
+  ADD CONSTRAINT log_entry_chk_1
+  CHECK (tipo_evento IN ('LOGIN','MENSAJE','ARCHIVO','CANAL'));
+
+ALTER TABLE replica_event    DROP COLUMN version_hlc;
+ALTER TABLE mensaje_servidor DROP COLUMN version_hlc;
+ALTER TABLE canal_miembro    DROP COLUMN version_hlc;
+ALTER TABLE canal_servidor   DROP COLUMN version_hlc;
+ALTER TABLE usuario_servidor DROP COLUMN version_hlc;
@@ -0,0 +1,21 @@
+/*--------------------------------------------------------------------
+  Migración para versionar entidades replicadas con HLC
+  (Hybrid Logical Clock) y registrar conflictos en la auditoría
+--------------------------------------------------------------------*/
+
+-- Formato de version_hlc: "<wall_ns>.<logico>@<nodo_uuid>"
+-- NULL en filas creadas antes del versionado
+ALTER TABLE usuario_servidor ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE canal_servidor   ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE canal_miembro    ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE mensaje_servidor ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE replica_event    ADD COLUMN version_hlc VARCHAR(80) NULL;
+
+/*--------------------------------------------------------------------
+  EventoTipo : LOGIN | MENSAJE | ARCHIVO | CANAL | CONFLICTO
+  MySQL 8 nombra el CHECK sin nombre de log_entry como log_entry_chk_1
+--------------------------------------------------------------------*/
+ALTER TABLE log_entry DROP CHECK log_entry_chk_1;
+ALTER TABLE log_entry
+  ADD CONSTRAINT chk_log_entry_tipo_evento
+  CHECK (tipo_evento IN ('LOGIN','MENSAJE','ARCHIVO','CANAL','CONFLICTO'));
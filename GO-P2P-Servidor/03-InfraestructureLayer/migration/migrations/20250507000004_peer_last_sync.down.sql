@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Reversión de la última sincronización por peer
+--------------------------------------------------------------------*/
+
+ALTER TABLE peer DROP COLUMN ultima_sync_at;
@@ -0,0 +1,6 @@
+/*--------------------------------------------------------------------
+  Migración para registrar la última sincronización anti-entropía
+  completada con éxito con cada peer (visible en el dashboard)
+--------------------------------------------------------------------*/
+
+ALTER TABLE peer ADD COLUMN ultima_sync_at TIMESTAMP NULL;
@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Reversión del salto anterior en routed_message
+--------------------------------------------------------------------*/
+
+ALTER TABLE routed_message DROP COLUMN nodo_anterior_id;
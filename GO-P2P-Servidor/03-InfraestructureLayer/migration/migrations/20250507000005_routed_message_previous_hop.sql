@@ -0,0 +1,8 @@
+/*--------------------------------------------------------------------
+  Migración para enrutamiento de varios saltos: cada fila de
+  routed_message guarda desde qué nodo llegó el mensaje, de modo que
+  las filas de un mensaje forman su ruta completa
+--------------------------------------------------------------------*/
+
+-- NULL en los envíos directos desde el nodo de origen
+ALTER TABLE routed_message ADD COLUMN nodo_anterior_id CHAR(36) NULL;
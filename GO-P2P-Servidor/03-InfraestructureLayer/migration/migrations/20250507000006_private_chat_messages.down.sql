@@ -0,0 +1,13 @@
+/*--------------------------------------------------------------------
+  Reversión de los mensajes de chat privado: no caben en el CHECK
+  original y se descartan
+--------------------------------------------------------------------*/
+
+DELETE FROM mensaje_servidor WHERE destino_usuario_id IS NULL AND canal_id IS NULL;
+ALTER TABLE mensaje_servidor DROP CHECK chk_mensaje_servidor_destino;
+ALTER TABLE mensaje_servidor
+  ADD CONSTRAINT mensaje_servidor_chk_1
+  CHECK (
+        (destino_usuario_id IS NULL AND canal_id IS NOT NULL)
+     OR (destino_usuario_id IS NOT NULL AND canal_id IS NULL)
+  );
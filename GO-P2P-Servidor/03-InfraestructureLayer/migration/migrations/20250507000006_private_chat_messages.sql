@@ -0,0 +1,16 @@
+/*--------------------------------------------------------------------
+  Migración para admitir mensajes de chat privado: el CHECK inicial
+  exige destino de usuario o canal, pero un mensaje de chat privado
+  solo lleva chat_privado_id. Cada mensaje tiene exactamente uno de
+  los tres destinos.
+  MySQL 8 nombra el CHECK sin nombre de mensaje_servidor como
+  mensaje_servidor_chk_1
+--------------------------------------------------------------------*/
+ALTER TABLE mensaje_servidor DROP CHECK mensaje_servidor_chk_1;
+ALTER TABLE mensaje_servidor
+  ADD CONSTRAINT chk_mensaje_servidor_destino
+  CHECK (
+        (destino_usuario_id IS NOT NULL AND canal_id IS NULL     AND chat_privado_id IS NULL)
+     OR (destino_usuario_id IS NULL     AND canal_id IS NOT NULL AND chat_privado_id IS NULL)
+     OR (destino_usuario_id IS NULL     AND canal_id IS NULL     AND chat_privado_id IS NOT NULL)
+  );
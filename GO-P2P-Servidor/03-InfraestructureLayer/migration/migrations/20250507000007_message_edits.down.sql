@@ -0,0 +1,9 @@
+/*--------------------------------------------------------------------
+  Reversión de la edición y el borrado de mensajes: los mensajes
+  eliminados se descartan, ya que sin eliminado_at volverían a verse
+--------------------------------------------------------------------*/
+
+DROP TABLE mensaje_edicion;
+DELETE FROM mensaje_servidor WHERE eliminado_at IS NOT NULL;
+ALTER TABLE mensaje_servidor DROP COLUMN eliminado_at;
+ALTER TABLE mensaje_servidor DROP COLUMN editado_at;
@@ -0,0 +1,28 @@
+/*--------------------------------------------------------------------
+  Migración para editar y eliminar mensajes: mensaje_servidor guarda
+  la última edición y el borrado lógico, y mensaje_edicion conserva el
+  contenido anterior a cada cambio junto con quién lo hizo
+--------------------------------------------------------------------*/
+
+-- NULL si el mensaje no se editó o no se eliminó
+ALTER TABLE mensaje_servidor ADD COLUMN editado_at   TIMESTAMP NULL;
+ALTER TABLE mensaje_servidor ADD COLUMN eliminado_at TIMESTAMP NULL;
+
+/*--------------------------------------------------------------------
+  TipoEdicion : EDICION | ELIMINACION
+  version_hlc es la versión que adquirió el mensaje con el cambio
+--------------------------------------------------------------------*/
+CREATE TABLE IF NOT EXISTS mensaje_edicion (
+  id                 CHAR(36)    PRIMARY KEY,
+  mensaje_id         CHAR(36)    NOT NULL,
+  editor_id          CHAR(36)    NOT NULL,
+  tipo               VARCHAR(16) NOT NULL,
+  contenido_anterior TEXT        NOT NULL,
+  fecha              TIMESTAMP   NOT NULL,
+  version_hlc        VARCHAR(80) NULL,
+  CONSTRAINT chk_mensaje_edicion_tipo CHECK (tipo IN ('EDICION','ELIMINACION')),
+  FOREIGN KEY (mensaje_id) REFERENCES mensaje_servidor(id) ON DELETE CASCADE,
+  FOREIGN KEY (editor_id)  REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_edicion_mensaje ON mensaje_edicion(mensaje_id, fecha);
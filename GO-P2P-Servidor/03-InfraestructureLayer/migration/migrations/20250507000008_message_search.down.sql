@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Reversión del índice FULLTEXT de mensaje_servidor
+--------------------------------------------------------------------*/
+
+ALTER TABLE mensaje_servidor DROP INDEX ft_msg_contenido;
@@ -0,0 +1,7 @@
+/*--------------------------------------------------------------------
+  Migración para la búsqueda de mensajes: índice FULLTEXT sobre el
+  contenido, usado con MATCH ... AGAINST en modo booleano. InnoDB no
+  indexa palabras más cortas que innodb_ft_min_token_size (3 por
+  defecto), el mismo mínimo que model.LongitudMinimaTermino.
+--------------------------------------------------------------------*/
+ALTER TABLE mensaje_servidor ADD FULLTEXT INDEX ft_msg_contenido (contenido);
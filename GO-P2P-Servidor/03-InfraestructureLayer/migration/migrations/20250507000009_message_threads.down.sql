@@ -0,0 +1,8 @@
+/*--------------------------------------------------------------------
+  Reversión de los hilos de mensajes: las respuestas quedan como
+  mensajes sueltos de su canal o conversación
+--------------------------------------------------------------------*/
+
+ALTER TABLE mensaje_servidor DROP FOREIGN KEY fk_msg_padre;
+DROP INDEX idx_msg_padre ON mensaje_servidor;
+ALTER TABLE mensaje_servidor DROP COLUMN padre_id;
@@ -0,0 +1,15 @@
+/*--------------------------------------------------------------------
+  Migración para hilos de mensajes: una respuesta referencia el
+  mensaje raíz de su hilo. Los hilos tienen un solo nivel y van al
+  mismo destino que su raíz. Borrar la raíz borra sus respuestas.
+--------------------------------------------------------------------*/
+
+-- NULL si el mensaje no es una respuesta
+ALTER TABLE mensaje_servidor ADD COLUMN padre_id CHAR(36) NULL;
+
+-- Respuestas de un hilo en orden cronológico
+CREATE INDEX idx_msg_padre ON mensaje_servidor(padre_id, timestamp);
+
+ALTER TABLE mensaje_servidor
+  ADD CONSTRAINT fk_msg_padre
+  FOREIGN KEY (padre_id) REFERENCES mensaje_servidor(id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Reversión de las reacciones con emoji
+--------------------------------------------------------------------*/
+
+DROP TABLE reaccion_mensaje;
@@ -0,0 +1,19 @@
+/*--------------------------------------------------------------------
+  Migración para reacciones con emoji: una fila por (mensaje, usuario,
+  emoji). Al retirarse una reacción se conserva con retirada_at para
+  que la replicación entre nodos no la vuelva a añadir.
+--------------------------------------------------------------------*/
+
+-- utf8mb4_bin compara los emoji byte a byte: las intercalaciones por
+-- defecto igualan algunas variantes de tono o de presentación
+CREATE TABLE IF NOT EXISTS reaccion_mensaje (
+  mensaje_id  CHAR(36)    NOT NULL,
+  usuario_id  CHAR(36)    NOT NULL,
+  emoji       VARCHAR(16) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  fecha       TIMESTAMP   NOT NULL,
+  retirada_at TIMESTAMP   NULL,
+  version_hlc VARCHAR(80) NULL,
+  PRIMARY KEY (mensaje_id, usuario_id, emoji),
+  FOREIGN KEY (mensaje_id) REFERENCES mensaje_servidor(id) ON DELETE CASCADE,
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+/*--------------------------------------------------------------------
+  Reversión de los acuses de entrega y lectura
+--------------------------------------------------------------------*/
+
+DROP TABLE marcador_lectura;
+DROP TABLE acuse_mensaje;
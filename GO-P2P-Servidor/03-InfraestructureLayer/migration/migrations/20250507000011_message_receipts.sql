@@ -0,0 +1,33 @@
+/*--------------------------------------------------------------------
+  Migración para acuses de entrega y lectura: acuse_mensaje guarda el
+  estado de cada mensaje para cada destinatario y marcador_lectura el
+  último mensaje leído por cada usuario en cada conversación
+--------------------------------------------------------------------*/
+
+/*--------------------------------------------------------------------
+  EstadoEntrega : ENVIADO | ENTREGADO | LEIDO
+  Un destinatario sin fila está en ENVIADO
+--------------------------------------------------------------------*/
+CREATE TABLE IF NOT EXISTS acuse_mensaje (
+  mensaje_id     CHAR(36)    NOT NULL,
+  usuario_id     CHAR(36)    NOT NULL,
+  estado         VARCHAR(16) NOT NULL,
+  actualizado_at TIMESTAMP   NOT NULL,
+  PRIMARY KEY (mensaje_id, usuario_id),
+  CONSTRAINT chk_acuse_mensaje_estado CHECK (estado IN ('ENVIADO','ENTREGADO','LEIDO')),
+  FOREIGN KEY (mensaje_id) REFERENCES mensaje_servidor(id) ON DELETE CASCADE,
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
+
+-- conversacion_id es el canal, el chat privado o, en los mensajes
+-- directos, el otro usuario. El último mensaje leído no lleva clave
+-- foránea: si se borra, el marcador sigue en su (timestamp, id).
+CREATE TABLE IF NOT EXISTS marcador_lectura (
+  usuario_id        CHAR(36)  NOT NULL,
+  conversacion_id   CHAR(36)  NOT NULL,
+  mensaje_id        CHAR(36)  NOT NULL,
+  mensaje_timestamp TIMESTAMP NOT NULL,
+  actualizado_at    TIMESTAMP NOT NULL,
+  PRIMARY KEY (usuario_id, conversacion_id),
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Reversión de la cola de eventos pendientes
+--------------------------------------------------------------------*/
+
+DROP TABLE evento_pendiente;
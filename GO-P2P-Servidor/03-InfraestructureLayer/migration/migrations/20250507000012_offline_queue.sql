@@ -0,0 +1,18 @@
+/*--------------------------------------------------------------------
+  Migración para la cola de eventos pendientes: los eventos de cliente
+  dirigidos a un usuario desconectado se guardan hasta que, al volver
+  a entrar, confirma haberlos recibido
+--------------------------------------------------------------------*/
+
+-- id es un UUID v7, de modo que (fecha, id) conserva el orden de los
+-- eventos encolados en el mismo instante. datos es el JSON del evento.
+CREATE TABLE IF NOT EXISTS evento_pendiente (
+  id         CHAR(36)    PRIMARY KEY,
+  usuario_id CHAR(36)    NOT NULL,
+  evento     VARCHAR(32) NOT NULL,
+  datos      TEXT        NOT NULL,
+  fecha      TIMESTAMP   NOT NULL,
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_evento_pendiente_usuario ON evento_pendiente(usuario_id, fecha, id);
@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Reversión de los mensajes programados
+--------------------------------------------------------------------*/
+
+DROP TABLE mensaje_programado;
@@ -0,0 +1,33 @@
+/*--------------------------------------------------------------------
+  Migración para mensajes programados y con caducidad: cada fila es un
+  mensaje que su nodo responsable envía en enviar_en y, si tiene
+  duración, elimina para todos cuando vence expira_en
+--------------------------------------------------------------------*/
+
+/*--------------------------------------------------------------------
+  EstadoProgramado : PENDIENTE | CANCELADO | ENVIANDO | ENVIADO |
+                     FALLIDO | EXPIRADO
+  nodo_id es el único nodo que envía y expira el mensaje; los demás
+  guardan una copia. El destino y el mensaje enviado no llevan clave
+  foránea: la copia puede llegar antes que el canal o el mensaje.
+--------------------------------------------------------------------*/
+CREATE TABLE IF NOT EXISTS mensaje_programado (
+  id                 CHAR(36)    PRIMARY KEY,
+  nodo_id            CHAR(36)    NOT NULL,
+  remitente_id       CHAR(36)    NOT NULL,
+  destino_usuario_id CHAR(36)    NULL,
+  canal_id           CHAR(36)    NULL,
+  contenido          TEXT        NOT NULL,
+  archivo_id         CHAR(36)    NULL,
+  enviar_en          TIMESTAMP   NOT NULL,
+  duracion_segundos  INT         NOT NULL DEFAULT 0,
+  estado             VARCHAR(16) NOT NULL,
+  mensaje_id         CHAR(36)    NULL,
+  expira_en          TIMESTAMP   NULL,
+  CONSTRAINT chk_mensaje_programado_estado CHECK (estado IN ('PENDIENTE','CANCELADO','ENVIANDO','ENVIADO','FALLIDO','EXPIRADO')),
+  FOREIGN KEY (remitente_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_mensaje_programado_envio      ON mensaje_programado(nodo_id, estado, enviar_en);
+CREATE INDEX idx_mensaje_programado_expiracion ON mensaje_programado(nodo_id, estado, expira_en);
+CREATE INDEX idx_mensaje_programado_remitente  ON mensaje_programado(remitente_id, enviar_en, id);
@@ -0,0 +1,6 @@
+/*--------------------------------------------------------------------
+  Reversión de las menciones
+--------------------------------------------------------------------*/
+
+DROP TABLE silencio_mencion;
+DROP TABLE mencion;
@@ -0,0 +1,42 @@
+/*--------------------------------------------------------------------
+  Migración para menciones: mencion es el feed de menciones de cada
+  usuario y silencio_mencion sus preferencias para no recibir
+  notificaciones de menciones en un canal o en todos
+--------------------------------------------------------------------*/
+
+/*--------------------------------------------------------------------
+  TipoMencion : USUARIO | CANAL
+  Una fila por usuario y mensaje. El mensaje y el canal no llevan clave
+  foránea: la copia difundida puede llegar antes que ellos.
+--------------------------------------------------------------------*/
+CREATE TABLE IF NOT EXISTS mencion (
+  id              CHAR(36)    PRIMARY KEY,
+  usuario_id      CHAR(36)    NOT NULL,
+  mensaje_id      CHAR(36)    NOT NULL,
+  canal_id        CHAR(36)    NOT NULL,
+  remitente_id    CHAR(36)    NOT NULL,
+  tipo            VARCHAR(16) NOT NULL,
+  fecha           TIMESTAMP   NOT NULL,
+  notificacion_id CHAR(36)    NULL,
+  CONSTRAINT chk_mencion_tipo CHECK (tipo IN ('USUARIO','CANAL')),
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_mencion_mensaje ON mencion(usuario_id, mensaje_id);
+CREATE INDEX idx_mencion_usuario        ON mencion(usuario_id, fecha, id);
+
+/*--------------------------------------------------------------------
+  NivelSilencio : NINGUNO | CANAL | TODO
+  canal_id es el UUID nulo en la preferencia que se aplica a todos los
+  canales, para que forme parte de la clave primaria
+--------------------------------------------------------------------*/
+CREATE TABLE IF NOT EXISTS silencio_mencion (
+  usuario_id     CHAR(36)    NOT NULL,
+  canal_id       CHAR(36)    NOT NULL,
+  nivel          VARCHAR(16) NOT NULL,
+  hasta          TIMESTAMP   NULL,
+  actualizado_at TIMESTAMP   NOT NULL,
+  PRIMARY KEY (usuario_id, canal_id),
+  CONSTRAINT chk_silencio_mencion_nivel CHECK (nivel IN ('NINGUNO','CANAL','TODO')),
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Reversión de los elementos fijados en los canales
+--------------------------------------------------------------------*/
+
+DROP TABLE fijado_canal;
@@ -0,0 +1,28 @@
+/*--------------------------------------------------------------------
+  Migración para mensajes y enlaces fijados en los canales. Al
+  desfijarse un elemento se conserva con retirado_en para que la
+  replicación entre nodos no lo vuelva a fijar.
+--------------------------------------------------------------------*/
+
+/*--------------------------------------------------------------------
+  Cada fila fija un mensaje (mensaje_id) o un enlace (url), nunca los
+  dos. El id se deriva del canal y del elemento, así que coincide en
+  todos los nodos. El canal y el mensaje no llevan clave foránea: la
+  copia difundida puede llegar antes que ellos.
+--------------------------------------------------------------------*/
+CREATE TABLE IF NOT EXISTS fijado_canal (
+  id           CHAR(36)      PRIMARY KEY,
+  canal_id     CHAR(36)      NOT NULL,
+  mensaje_id   CHAR(36)      NULL,
+  url          VARCHAR(2048) NULL,
+  titulo       VARCHAR(200)  NOT NULL DEFAULT '',
+  fijado_por   CHAR(36)      NOT NULL,
+  fijado_en    TIMESTAMP     NOT NULL,
+  retirado_por CHAR(36)      NULL,
+  retirado_en  TIMESTAMP     NULL,
+  version_hlc  VARCHAR(80)   NULL,
+  CONSTRAINT chk_fijado_canal_objetivo CHECK ((mensaje_id IS NULL) <> (url IS NULL)),
+  FOREIGN KEY (fijado_por) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_fijado_canal ON fijado_canal(canal_id, fijado_en);
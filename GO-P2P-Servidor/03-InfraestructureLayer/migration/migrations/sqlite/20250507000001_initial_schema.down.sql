@@ -0,0 +1,20 @@
+/*--------------------------------------------------------------------
+  Reversión de la migración inicial (SQLite): elimina todas las tablas
+  en orden inverso a sus dependencias
+--------------------------------------------------------------------*/
+
+DROP TABLE IF EXISTS routed_message;
+DROP TABLE IF EXISTS replica_event;
+DROP TABLE IF EXISTS heartbeat_log;
+DROP TABLE IF EXISTS peer;
+DROP TABLE IF EXISTS configuracion_servidor;
+DROP TABLE IF EXISTS log_entry;
+DROP TABLE IF EXISTS mensaje_servidor;
+DROP TABLE IF EXISTS archivo_metadata;
+DROP TABLE IF EXISTS chat_privado_usuario;
+DROP TABLE IF EXISTS chat_privado;
+DROP TABLE IF EXISTS notificacion;
+DROP TABLE IF EXISTS invitacion_canal;
+DROP TABLE IF EXISTS canal_miembro;
+DROP TABLE IF EXISTS canal_servidor;
+DROP TABLE IF EXISTS usuario_servidor;
@@ -0,0 +1,28 @@
+/*--------------------------------------------------------------------
+  Reversión del versionado HLC (SQLite): los eventos CONFLICTO no
+  caben en el CHECK original y se descartan
+--------------------------------------------------------------------*/
+
+DELETE FROM log_entry WHERE tipo_evento = 'CONFLICTO';
+
+CREATE TABLE log_entry_anterior (
+  id          CHAR(36)   PRIMARY KEY,
+  tipo_evento VARCHAR(10) NOT NULL,
+  detalle     TEXT       NOT NULL,
+  timestamp   TIMESTAMP  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  usuario_id  CHAR(36),
+  CHECK (tipo_evento IN ('LOGIN','MENSAJE','ARCHIVO','CANAL')),
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE SET NULL
+);
+
+INSERT INTO log_entry_anterior (id, tipo_evento, detalle, timestamp, usuario_id)
+SELECT id, tipo_evento, detalle, timestamp, usuario_id FROM log_entry;
+
+DROP TABLE log_entry;
+ALTER TABLE log_entry_anterior RENAME TO log_entry;
+
+ALTER TABLE replica_event    DROP COLUMN version_hlc;
+ALTER TABLE mensaje_servidor DROP COLUMN version_hlc;
+ALTER TABLE canal_miembro    DROP COLUMN version_hlc;
+ALTER TABLE canal_servidor   DROP COLUMN version_hlc;
+ALTER TABLE usuario_servidor DROP COLUMN version_hlc;
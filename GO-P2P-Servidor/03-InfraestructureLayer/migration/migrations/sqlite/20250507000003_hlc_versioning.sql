@@ -0,0 +1,33 @@
+/*--------------------------------------------------------------------
+  Migración para versionar entidades replicadas con HLC
+  (Hybrid Logical Clock) y registrar conflictos en la auditoría (SQLite)
+--------------------------------------------------------------------*/
+
+-- Formato de version_hlc: "<wall_ns>.<logico>@<nodo_uuid>"
+-- NULL en filas creadas antes del versionado
+ALTER TABLE usuario_servidor ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE canal_servidor   ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE canal_miembro    ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE mensaje_servidor ADD COLUMN version_hlc VARCHAR(80) NULL;
+ALTER TABLE replica_event    ADD COLUMN version_hlc VARCHAR(80) NULL;
+
+/*--------------------------------------------------------------------
+  EventoTipo : LOGIN | MENSAJE | ARCHIVO | CANAL | CONFLICTO
+  SQLite no permite cambiar un CHECK: se reconstruye log_entry
+--------------------------------------------------------------------*/
+CREATE TABLE log_entry_nueva (
+  id          CHAR(36)   PRIMARY KEY,
+  tipo_evento VARCHAR(10) NOT NULL,
+  detalle     TEXT       NOT NULL,
+  timestamp   TIMESTAMP  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  usuario_id  CHAR(36),
+  CONSTRAINT chk_log_entry_tipo_evento
+  CHECK (tipo_evento IN ('LOGIN','MENSAJE','ARCHIVO','CANAL','CONFLICTO')),
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE SET NULL
+);
+
+INSERT INTO log_entry_nueva (id, tipo_evento, detalle, timestamp, usuario_id)
+SELECT id, tipo_evento, detalle, timestamp, usuario_id FROM log_entry;
+
+DROP TABLE log_entry;
+ALTER TABLE log_entry_nueva RENAME TO log_entry;
@@ -0,0 +1,68 @@
+/*--------------------------------------------------------------------
+  Migración para admitir mensajes de chat privado (SQLite)
+  SQLite no permite cambiar un CHECK: se reconstruye mensaje_servidor.
+  routed_message la referencia y borrarla eliminaría sus filas en
+  cascada, así que se guarda aparte y se reconstruye después.
+--------------------------------------------------------------------*/
+CREATE TABLE routed_message_copia (
+  mensaje_id       CHAR(36)  NOT NULL,
+  nodo_destino_id  CHAR(36)  NOT NULL,
+  enruta_at        TIMESTAMP NOT NULL,
+  nodo_anterior_id CHAR(36)  NULL
+);
+
+INSERT INTO routed_message_copia (mensaje_id, nodo_destino_id, enruta_at, nodo_anterior_id)
+SELECT mensaje_id, nodo_destino_id, enruta_at, nodo_anterior_id FROM routed_message;
+
+DROP TABLE routed_message;
+
+CREATE TABLE mensaje_servidor_nueva (
+  id                 CHAR(36)   PRIMARY KEY,
+  remitente_id       CHAR(36)   NOT NULL,
+  destino_usuario_id CHAR(36),
+  canal_id           CHAR(36),
+  chat_privado_id    CHAR(36),
+  contenido          TEXT       NOT NULL,
+  timestamp          TIMESTAMP  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  archivo_id         CHAR(36)   UNIQUE,
+  version_hlc        VARCHAR(80) NULL,
+  CONSTRAINT chk_mensaje_servidor_destino
+  CHECK (
+        (destino_usuario_id IS NOT NULL AND canal_id IS NULL     AND chat_privado_id IS NULL)
+     OR (destino_usuario_id IS NULL     AND canal_id IS NOT NULL AND chat_privado_id IS NULL)
+     OR (destino_usuario_id IS NULL     AND canal_id IS NULL     AND chat_privado_id IS NOT NULL)
+  ),
+  FOREIGN KEY (remitente_id)       REFERENCES usuario_servidor(id) ON DELETE CASCADE,
+  FOREIGN KEY (destino_usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE,
+  FOREIGN KEY (canal_id)           REFERENCES canal_servidor(id)   ON DELETE CASCADE,
+  FOREIGN KEY (chat_privado_id)    REFERENCES chat_privado(id)     ON DELETE CASCADE,
+  CONSTRAINT fk_msg_archivo
+  FOREIGN KEY (archivo_id)         REFERENCES archivo_metadata(id) ON DELETE SET NULL
+);
+
+INSERT INTO mensaje_servidor_nueva (id, remitente_id, destino_usuario_id, canal_id,
+  chat_privado_id, contenido, timestamp, archivo_id, version_hlc)
+SELECT id, remitente_id, destino_usuario_id, canal_id,
+  chat_privado_id, contenido, timestamp, archivo_id, version_hlc FROM mensaje_servidor;
+
+DROP TABLE mensaje_servidor;
+ALTER TABLE mensaje_servidor_nueva RENAME TO mensaje_servidor;
+
+CREATE INDEX idx_msg_remitente ON mensaje_servidor(remitente_id);
+CREATE INDEX idx_msg_canal     ON mensaje_servidor(canal_id);
+CREATE INDEX idx_msg_chat      ON mensaje_servidor(chat_privado_id);
+
+CREATE TABLE routed_message (
+  mensaje_id       CHAR(36)  NOT NULL,
+  nodo_destino_id  CHAR(36)  NOT NULL,
+  enruta_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  nodo_anterior_id CHAR(36)  NULL,
+  PRIMARY KEY (mensaje_id , nodo_destino_id),
+  FOREIGN KEY (mensaje_id)      REFERENCES mensaje_servidor(id) ON DELETE CASCADE,
+  FOREIGN KEY (nodo_destino_id) REFERENCES peer(id_nodo)        ON DELETE CASCADE
+);
+
+INSERT INTO routed_message (mensaje_id, nodo_destino_id, enruta_at, nodo_anterior_id)
+SELECT mensaje_id, nodo_destino_id, enruta_at, nodo_anterior_id FROM routed_message_copia;
+
+DROP TABLE routed_message_copia;
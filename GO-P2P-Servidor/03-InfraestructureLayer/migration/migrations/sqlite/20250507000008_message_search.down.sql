@@ -0,0 +1,3 @@
+/*--------------------------------------------------------------------
+  Reversión de la búsqueda de mensajes (SQLite): no hay nada que deshacer
+--------------------------------------------------------------------*/
@@ -0,0 +1,5 @@
+/*--------------------------------------------------------------------
+  Migración para la búsqueda de mensajes (SQLite)
+  SQLite no tiene índices FULLTEXT: la búsqueda usa el índice invertido
+  en memoria de dao.IndiceMensajes, así que no hay nada que crear.
+--------------------------------------------------------------------*/
@@ -0,0 +1,6 @@
+/*--------------------------------------------------------------------
+  Reversión de los hilos de mensajes (SQLite)
+--------------------------------------------------------------------*/
+
+DROP INDEX idx_msg_padre;
+ALTER TABLE mensaje_servidor DROP COLUMN padre_id;
@@ -0,0 +1,12 @@
+/*--------------------------------------------------------------------
+  Migración para hilos de mensajes (SQLite)
+  SQLite no admite ADD CONSTRAINT: la clave foránea se declara en la
+  propia columna, lo que permite añadirla sin reconstruir la tabla.
+--------------------------------------------------------------------*/
+
+-- NULL si el mensaje no es una respuesta
+ALTER TABLE mensaje_servidor ADD COLUMN padre_id CHAR(36) NULL
+  REFERENCES mensaje_servidor(id) ON DELETE CASCADE;
+
+-- Respuestas de un hilo en orden cronológico
+CREATE INDEX idx_msg_padre ON mensaje_servidor(padre_id, timestamp);
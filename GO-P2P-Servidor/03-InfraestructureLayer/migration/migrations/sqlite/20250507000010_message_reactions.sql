@@ -0,0 +1,17 @@
+/*--------------------------------------------------------------------
+  Migración para reacciones con emoji (SQLite)
+  SQLite compara el texto byte a byte por defecto y no admite
+  CHARACTER SET ni la intercalación utf8mb4_bin.
+--------------------------------------------------------------------*/
+
+CREATE TABLE IF NOT EXISTS reaccion_mensaje (
+  mensaje_id  CHAR(36)    NOT NULL,
+  usuario_id  CHAR(36)    NOT NULL,
+  emoji       VARCHAR(16) NOT NULL,
+  fecha       TIMESTAMP   NOT NULL,
+  retirada_at TIMESTAMP   NULL,
+  version_hlc VARCHAR(80) NULL,
+  PRIMARY KEY (mensaje_id, usuario_id, emoji),
+  FOREIGN KEY (mensaje_id) REFERENCES mensaje_servidor(id) ON DELETE CASCADE,
+  FOREIGN KEY (usuario_id) REFERENCES usuario_servidor(id) ON DELETE CASCADE
+);
@@ -2,10 +2,15 @@ package migration
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"database/sql"
 	"embed"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -16,16 +21,48 @@ import (
 	"pool"
 )
 
-//go:embed migrations/*.sql
+//go:embed migrations/*.sql migrations/sqlite/*.sql
 var migrationsFS embed.FS
 
+// ErrChecksumMismatch indica que el script de una migración aplicada cambió después de aplicarse
+var ErrChecksumMismatch = errors.New("migraciones aplicadas modificadas después de aplicarse")
+
+// Marcadores de sección para migraciones con up y down en el mismo archivo
+const (
+	upMarker   = "-- +migrate Up"
+	downMarker = "-- +migrate Down"
+)
+
 // Migration representa una migración de base de datos con versión y contenido SQL
 type Migration struct {
 	Version     int
 	Description string
 	SQL         string
+	DownSQL     string // Vacío si la migración no se puede revertir
+	Checksum    string // SHA-256 del script up
 	Applied     bool
 	AppliedAt   time.Time
+	// AppliedChecksum es el checksum registrado al aplicarla; vacío en filas anteriores a los checksums
+	AppliedChecksum string
+	// Up y Down sustituyen a SQL y DownSQL en las migraciones escritas en Go
+	Up   GoMigrationFunc
+	Down GoMigrationFunc
+}
+
+// HasDown indica si la migración tiene script de reversión
+func (mig *Migration) HasDown() bool {
+	return mig.Down != nil || strings.TrimSpace(mig.DownSQL) != ""
+}
+
+// Edited indica si el script up cambió después de aplicarse
+func (mig *Migration) Edited() bool {
+	return mig.Applied && mig.AppliedChecksum != "" && mig.AppliedChecksum != mig.Checksum
+}
+
+// checksumSQL calcula el checksum de un script, independiente del fin de línea
+func checksumSQL(script string) string {
+	sum := sha256.Sum256([]byte(strings.ReplaceAll(script, "\r\n", "\n")))
+	return hex.EncodeToString(sum[:])
 }
 
 // Migrator es el componente central que gestiona la aplicación de migraciones
@@ -38,6 +75,7 @@ type Migrator struct {
 	strictMode    bool
 	forceVersion  int
 	allowOutOfOrder bool
+	lockTimeout   time.Duration
 }
 
 // NewMigrator crea una nueva instancia del migrador
@@ -53,6 +91,7 @@ func NewMigrator(dbPool *pool.DBConnectionPool) *Migrator {
 		log:           logger,
 		tableName:     "schema_migrations",
 		strictMode:    true,
+		lockTimeout:   60 * time.Second,
 	}
 }
 
@@ -86,41 +125,60 @@ func (m *Migrator) WithAllowOutOfOrder(allow bool) *Migrator {
 	return m
 }
 
-// LoadEmbeddedMigrations carga las migraciones desde los archivos SQL embebidos
+// WithLockTimeout configura cuánto se espera al lock de migraciones (0 = intentarlo una
+// vez sin esperar, LockDisabled = no tomarlo)
+func (m *Migrator) WithLockTimeout(timeout time.Duration) *Migrator {
+	m.lockTimeout = timeout
+	return m
+}
+
+// LoadEmbeddedMigrations carga las migraciones desde los archivos SQL embebidos.
+// En SQLite los scripts de migrations/sqlite sustituyen a los de la misma versión.
 func (m *Migrator) LoadEmbeddedMigrations() error {
-	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err := m.loadEmbeddedDir("migrations", false); err != nil {
+		return err
+	}
+	if m.isSQLite() {
+		if err := m.loadEmbeddedDir(sqliteMigrationsDir, true); err != nil {
+			return err
+		}
+	}
+
+	m.sortMigrations()
+	return nil
+}
+
+// loadEmbeddedDir registra los scripts de un directorio embebido. Con override, el
+// primer script de cada versión descarta el up y el down cargados antes para ella.
+func (m *Migrator) loadEmbeddedDir(dir string, override bool) error {
+	entries, err := fs.ReadDir(migrationsFS, dir)
 	if err != nil {
 		return fmt.Errorf("error leyendo directorio de migraciones: %w", err)
 	}
 
+	replaced := make(map[int]bool)
 	for _, entry := range entries {
 		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
 			file := entry.Name()
-			version, description, err := parseFilename(file)
+			version, description, down, err := parseScriptFilename(file)
 			if err != nil {
 				m.log.WithError(err).Warnf("Omitiendo archivo inválido: %s", file)
 				continue
 			}
 
-			content, err := fs.ReadFile(migrationsFS, filepath.Join("migrations", file))
+			content, err := fs.ReadFile(migrationsFS, path.Join(dir, file))
 			if err != nil {
 				return fmt.Errorf("error leyendo archivo de migración %s: %w", file, err)
 			}
 
-			m.migrations = append(m.migrations, &Migration{
-				Version:     version,
-				Description: description,
-				SQL:         string(content),
-				Applied:     false,
-			})
+			if existing := m.GetMigrationByVersion(version); override && existing != nil && !replaced[version] {
+				existing.SQL, existing.DownSQL = "", ""
+				replaced[version] = true
+			}
+			m.addScript(version, description, string(content), down)
 		}
 	}
 
-	// Ordenar por versión
-	sort.Slice(m.migrations, func(i, j int) bool {
-		return m.migrations[i].Version < m.migrations[j].Version
-	})
-
 	return nil
 }
 
@@ -166,7 +224,7 @@ func (m *Migrator) LoadMigrationsFromDir(dirPath string) error {
 		filename := entry.Name()
 
 		// Parsear nombre para obtener versión y descripción
-		version, description, err := parseFilename(filename)
+		version, description, down, err := parseScriptFilename(filename)
 		if err != nil {
 			m.log.WithError(err).Warnf("Ignorando archivo con formato inválido: %s", filename)
 			continue
@@ -179,22 +237,13 @@ func (m *Migrator) LoadMigrationsFromDir(dirPath string) error {
 			return fmt.Errorf("error leyendo archivo de migración %s: %w", filename, err)
 		}
 
-		// Crear estructura de migración
-		migration := &Migration{
-			Version:     version,
-			Description: description,
-			SQL:         string(sqlContent),
-			Applied:     false,
-		}
-
-		m.migrations = append(m.migrations, migration)
-		m.log.Debugf("Cargada migración: %d %s", version, description)
+		// Registrar el script como up o down de su migración
+		m.addScript(version, description, string(sqlContent), down)
+		m.log.Debugf("Cargado script de migración: %d %s (down: %t)", version, description, down)
 	}
 
 	// Ordenar migraciones por versión
-	sort.Slice(m.migrations, func(i, j int) bool {
-		return m.migrations[i].Version < m.migrations[j].Version
-	})
+	m.sortMigrations()
 
 	m.log.Infof("Cargadas %d migraciones desde directorio", len(m.migrations))
 	return nil
@@ -209,7 +258,7 @@ func (m *Migrator) LoadExternalMigrations(dirPath string) error {
 
 	for _, entry := range entries {
 		file := filepath.Base(entry)
-		version, description, err := parseFilename(file)
+		version, description, down, err := parseScriptFilename(file)
 		if err != nil {
 			m.log.WithError(err).Warnf("Omitiendo archivo inválido: %s", file)
 			continue
@@ -220,20 +269,78 @@ func (m *Migrator) LoadExternalMigrations(dirPath string) error {
 			return fmt.Errorf("error leyendo archivo de migración %s: %w", file, err)
 		}
 
-		m.migrations = append(m.migrations, &Migration{
-			Version:     version,
-			Description: description,
-			SQL:         string(content),
-			Applied:     false,
-		})
+		m.addScript(version, description, string(content), down)
+	}
+
+	m.sortMigrations()
+	return nil
+}
+
+// addScript registra un script up o down. Un archivo up puede traer también su down
+// tras el marcador "-- +migrate Down"; un archivo *.down.sql aporta solo el down.
+func (m *Migrator) addScript(version int, description, content string, down bool) {
+	migration := m.GetMigrationByVersion(version)
+	if migration == nil {
+		migration = &Migration{Version: version, Description: description}
+		m.migrations = append(m.migrations, migration)
+	}
+	if migration.IsGo() {
+		m.log.Warnf("Omitiendo script SQL con la versión %d, ya registrada como migración Go", version)
+		return
 	}
 
-	// Ordenar por versión
+	if down {
+		migration.DownSQL = content
+		return
+	}
+
+	up, downSection := splitUpDown(content)
+	migration.Description = description
+	migration.SQL = up
+	migration.Checksum = checksumSQL(up)
+	if downSection != "" {
+		migration.DownSQL = downSection
+	}
+}
+
+// sortMigrations descarta los down sin migración up y ordena por versión
+func (m *Migrator) sortMigrations() {
+	loaded := m.migrations[:0]
+	for _, migration := range m.migrations {
+		if !migration.IsGo() && strings.TrimSpace(migration.SQL) == "" {
+			m.log.Warnf("Omitiendo script down sin migración up: %d %s", migration.Version, migration.Description)
+			continue
+		}
+		loaded = append(loaded, migration)
+	}
+	m.migrations = loaded
+
 	sort.Slice(m.migrations, func(i, j int) bool {
 		return m.migrations[i].Version < m.migrations[j].Version
 	})
+}
 
-	return nil
+// splitUpDown separa las secciones up y down de un archivo de migración.
+// Sin marcadores todo el contenido es up.
+func splitUpDown(content string) (string, string) {
+	var up, down []string
+	inDown := false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.EqualFold(strings.TrimSpace(line), upMarker):
+			inDown = false
+			continue
+		case strings.EqualFold(strings.TrimSpace(line), downMarker):
+			inDown = true
+			continue
+		}
+		if inDown {
+			down = append(down, line)
+		} else {
+			up = append(up, line)
+		}
+	}
+	return strings.Join(up, "\n"), strings.TrimSpace(strings.Join(down, "\n"))
 }
 
 // CreateMigrationsTable crea la tabla de control de migraciones si no existe
@@ -242,7 +349,8 @@ func (m *Migrator) CreateMigrationsTable(ctx context.Context) error {
 		CREATE TABLE IF NOT EXISTS %s (
 			version     BIGINT       NOT NULL PRIMARY KEY,
 			description VARCHAR(255) NOT NULL,
-			applied_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+			applied_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			checksum    CHAR(64)     NULL
 		)
 	`, m.tableName)
 
@@ -257,53 +365,136 @@ func (m *Migrator) CreateMigrationsTable(ctx context.Context) error {
 		return fmt.Errorf("error creando tabla de control de migraciones: %w", err)
 	}
 
+	// Las tablas creadas antes de los checksums no tienen la columna
+	exists, err := m.columnExists(ctx, m.tableName, "checksum")
+	if err != nil {
+		return fmt.Errorf("error comprobando columna checksum: %w", err)
+	}
+	if !exists {
+		alter := fmt.Sprintf("ALTER TABLE %s ADD COLUMN checksum CHAR(64) NULL", m.tableName)
+		if _, err := m.dbPool.ExecContext(ctx, alter); err != nil {
+			return fmt.Errorf("error añadiendo columna checksum: %w", err)
+		}
+	}
+
 	m.log.Infof("Tabla de migraciones '%s' verificada/creada", m.tableName)
 	return nil
 }
 
-// LoadAppliedMigrations carga el estado de migraciones aplicadas desde la BD
+// appliedRecord es una fila de la tabla de control de migraciones
+type appliedRecord struct {
+	Description string
+	AppliedAt   time.Time
+	Checksum    string
+}
+
+// LoadAppliedMigrations carga el estado de migraciones aplicadas desde la BD y verifica
+// que sus scripts no hayan cambiado desde que se aplicaron. En modo estricto un script
+// modificado es un error; en otro caso solo se advierte.
 func (m *Migrator) LoadAppliedMigrations(ctx context.Context) error {
-	query := fmt.Sprintf("SELECT version, description, applied_at FROM %s", m.tableName)
+	applied, err := m.loadAppliedRecords(ctx)
+	if err != nil {
+		return err
+	}
+	m.markApplied(applied)
+
+	// Las filas anteriores a los checksums toman el del script actual
+	if err := m.backfillChecksums(ctx); err != nil {
+		return err
+	}
+
+	var edited []string
+	for _, migration := range m.migrations {
+		if migration.Edited() {
+			edited = append(edited, strconv.Itoa(migration.Version))
+		}
+	}
+	if len(edited) > 0 {
+		err := fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(edited, ", "))
+		if m.strictMode {
+			return err
+		}
+		m.log.WithError(err).Warn("Continuando por modo no estricto")
+	}
+
+	m.log.Infof("Migraciones previas cargadas: %d aplicadas", len(applied))
+	return nil
+}
+
+// loadAppliedRecords lee la tabla de control, creándola si no existe. Se lee de la
+// base de datos principal: una réplica con retraso no vería las últimas migraciones.
+func (m *Migrator) loadAppliedRecords(ctx context.Context) (map[int]appliedRecord, error) {
+	ctx = pool.WithConsistentRead(ctx)
+	query := fmt.Sprintf("SELECT version, description, applied_at, checksum FROM %s", m.tableName)
 	
 	rows, err := m.dbPool.QueryContext(ctx, query)
+	if err != nil && isMissingColumnError(err) {
+		// Tabla anterior a los checksums en modo simulación, donde no se altera
+		query = fmt.Sprintf("SELECT version, description, applied_at, NULL FROM %s", m.tableName)
+		rows, err = m.dbPool.QueryContext(ctx, query)
+	}
 	if err != nil {
 		// Si el error es que la tabla no existe, intentamos crearla
-		if strings.Contains(err.Error(), "doesn't exist") {
+		if isMissingTableError(err) {
 			m.log.Info("Tabla de migraciones no encontrada, creándola...")
 			if err := m.CreateMigrationsTable(ctx); err != nil {
-				return err
+				return nil, err
 			}
-			return nil // No hay migraciones aplicadas aún
+			return map[int]appliedRecord{}, nil // No hay migraciones aplicadas aún
 		}
-		return fmt.Errorf("error consultando migraciones aplicadas: %w", err)
+		return nil, fmt.Errorf("error consultando migraciones aplicadas: %w", err)
 	}
 	defer rows.Close()
 
 	// Crear un mapa de versiones aplicadas
-	applied := make(map[int]time.Time)
+	applied := make(map[int]appliedRecord)
 	for rows.Next() {
 		var version int
-		var description string
-		var appliedAt time.Time
-		if err := rows.Scan(&version, &description, &appliedAt); err != nil {
-			return fmt.Errorf("error leyendo migración aplicada: %w", err)
+		var record appliedRecord
+		var checksum sql.NullString
+		if err := rows.Scan(&version, &record.Description, &record.AppliedAt, &checksum); err != nil {
+			return nil, fmt.Errorf("error leyendo migración aplicada: %w", err)
 		}
-		applied[version] = appliedAt
+		record.Checksum = checksum.String
+		applied[version] = record
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo migraciones aplicadas: %w", err)
+	}
+	return applied, nil
+}
 
-	// Actualizar el estado de las migraciones cargadas
+// markApplied actualiza el estado de las migraciones cargadas
+func (m *Migrator) markApplied(applied map[int]appliedRecord) {
 	for _, migration := range m.migrations {
-		if appliedAt, exists := applied[migration.Version]; exists {
-			migration.Applied = true
-			migration.AppliedAt = appliedAt
-		}
+		record, exists := applied[migration.Version]
+		migration.Applied = exists
+		migration.AppliedAt = record.AppliedAt
+		migration.AppliedChecksum = record.Checksum
 	}
+}
 
-	m.log.Infof("Migraciones previas cargadas: %d aplicadas", len(applied))
+// backfillChecksums registra el checksum actual de las migraciones aplicadas sin checksum
+func (m *Migrator) backfillChecksums(ctx context.Context) error {
+	update := fmt.Sprintf("UPDATE %s SET checksum = ? WHERE version = ? AND checksum IS NULL", m.tableName)
+	for _, migration := range m.migrations {
+		if !migration.Applied || migration.AppliedChecksum != "" {
+			continue
+		}
+		if m.dryRun {
+			m.log.Infof("Modo simulación: se registraría el checksum de la migración %d", migration.Version)
+			continue
+		}
+		if _, err := m.dbPool.ExecContext(ctx, update, migration.Checksum, migration.Version); err != nil {
+			return fmt.Errorf("error registrando checksum de la migración %d: %w", migration.Version, err)
+		}
+		migration.AppliedChecksum = migration.Checksum
+		m.log.Infof("Checksum registrado para la migración %d", migration.Version)
+	}
 	return nil
 }
 
-// Run ejecuta las migraciones pendientes
+// Run ejecuta las migraciones pendientes bajo el lock de migraciones
 func (m *Migrator) Run(ctx context.Context) error {
 	// Verificar que tenemos migraciones
 	if len(m.migrations) == 0 {
@@ -311,6 +502,11 @@ func (m *Migrator) Run(ctx context.Context) error {
 		return nil
 	}
 
+	return m.withLock(ctx, func() error { return m.run(ctx) })
+}
+
+// run ejecuta las migraciones pendientes; requiere el lock de migraciones
+func (m *Migrator) run(ctx context.Context) error {
 	// Primero crear tabla de migraciones si no existe
 	if err := m.CreateMigrationsTable(ctx); err != nil {
 		return err
@@ -370,6 +566,10 @@ func (m *Migrator) applyMigration(ctx context.Context, migration *Migration) err
 	
 	// Modo simulación, solo mostrar SQL
 	if m.dryRun {
+		if migration.IsGo() {
+			m.log.Info("Modo simulación: se ejecutaría la función Go de la migración")
+			return nil
+		}
 		m.log.Info("Modo simulación: SQL que se ejecutaría:")
 		m.log.Info(migration.SQL)
 		return nil
@@ -388,87 +588,244 @@ func (m *Migrator) applyMigration(ctx context.Context, migration *Migration) err
 		}
 	}()
 	
-	// Ejecutar el SQL de la migración dividiendo por instrucciones
-	// y eliminando comentarios para no confundir los delimitadores
-	lines := strings.Split(migration.SQL, "\n")
-	var cleanedLines []string
+	if migration.IsGo() {
+		err = runGoMigration(ctx, tx, migration.Up)
+	} else {
+		err = m.execScript(ctx, tx, migration.SQL)
+	}
+	if err != nil {
+		return err
+	}
 	
-	inCommentBlock := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		
-		// Manejo de bloques de comentarios
-		if strings.HasPrefix(line, "/*") {
-			inCommentBlock = true
-			continue
-		}
-		
-		if inCommentBlock {
-			if strings.Contains(line, "*/") {
-				inCommentBlock = false
-			}
-			continue
-		}
-		
-		// Ignorar líneas de comentario
-		if strings.HasPrefix(line, "--") {
-			continue
-		}
-		
-		// Eliminar comentarios al final de línea
-		commentIdx := strings.Index(line, "--")
-		if commentIdx >= 0 {
-			line = line[:commentIdx]
-		}
-		
-		// Añadir la línea si no está vacía
-		if line != "" {
-			cleanedLines = append(cleanedLines, line)
-		}
+	// Registrar la migración como aplicada
+	insertQuery := fmt.Sprintf("INSERT INTO %s (version, description, checksum) VALUES (?, ?, ?)", m.tableName)
+	if _, err = tx.ExecContext(ctx, insertQuery, migration.Version, migration.Description, migration.Checksum); err != nil {
+		return fmt.Errorf("error registrando migración como aplicada: %w", err)
 	}
 	
-	// Unir todas las líneas en un solo string
-	cleanedSQL := strings.Join(cleanedLines, " ")
+	// Confirmar transacción
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando transacción: %w", err)
+	}
 	
-	// Separar correctamente las instrucciones SQL respetando las comillas
-	statements := m.splitSQLStatements(cleanedSQL)
+	// Actualizar estado de la migración
+	migration.Applied = true
+	migration.AppliedAt = time.Now()
+	migration.AppliedChecksum = migration.Checksum
+	
+	m.log.Infof("Migración %d aplicada exitosamente", migration.Version)
+	return nil
+}
+
+// execScript ejecuta un script SQL dentro de la transacción dada
+func (m *Migrator) execScript(ctx context.Context, tx *sql.Tx, script string) error {
+	statements, err := splitStatements(script)
+	if err != nil {
+		return fmt.Errorf("error analizando SQL: %w", err)
+	}
 	
 	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
-		}
-		
 		m.log.Debugf("Ejecutando instrucción:\n%s", stmt)
 		
-		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
 			return fmt.Errorf("error ejecutando SQL: %w\n\nEn instrucción:\n%s", err, stmt)
 		}
 	}
 	
-	// Registrar la migración como aplicada
-	insertQuery := fmt.Sprintf("INSERT INTO %s (version, description) VALUES (?, ?)", m.tableName)
-	if _, err = tx.ExecContext(ctx, insertQuery, migration.Version, migration.Description); err != nil {
-		return fmt.Errorf("error registrando migración como aplicada: %w", err)
+	return nil
+}
+
+// rollbackMigration revierte una migración aplicada ejecutando su script down
+func (m *Migrator) rollbackMigration(ctx context.Context, migration *Migration) error {
+	if !migration.HasDown() {
+		return fmt.Errorf("la migración %d no tiene script down", migration.Version)
+	}
+	
+	m.log.Infof("Revirtiendo migración %d: %s", migration.Version, migration.Description)
+	
+	// Modo simulación, solo mostrar SQL
+	if m.dryRun {
+		if migration.IsGo() {
+			m.log.Info("Modo simulación: se ejecutaría la función Go de reversión")
+			return nil
+		}
+		m.log.Info("Modo simulación: SQL que se ejecutaría:")
+		m.log.Info(migration.DownSQL)
+		return nil
+	}
+	
+	tx, err := m.dbPool.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	
+	// Asegurar que hacemos rollback en caso de error
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	
+	if migration.IsGo() {
+		err = runGoMigration(ctx, tx, migration.Down)
+	} else {
+		err = m.execScript(ctx, tx, migration.DownSQL)
+	}
+	if err != nil {
+		return err
+	}
+	
+	// Borrar la migración de la tabla de control
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE version = ?", m.tableName)
+	if _, err = tx.ExecContext(ctx, deleteQuery, migration.Version); err != nil {
+		return fmt.Errorf("error eliminando registro de migración: %w", err)
 	}
 	
-	// Confirmar transacción
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("error confirmando transacción: %w", err)
 	}
 	
-	// Actualizar estado de la migración
-	migration.Applied = true
-	migration.AppliedAt = time.Now()
+	migration.Applied = false
+	migration.AppliedAt = time.Time{}
+	migration.AppliedChecksum = ""
 	
-	m.log.Infof("Migración %d aplicada exitosamente", migration.Version)
+	m.log.Infof("Migración %d revertida exitosamente", migration.Version)
 	return nil
 }
 
-// rollbackMigration revierte una migración específica (si está implementado el down)
-func (m *Migrator) rollbackMigration(ctx context.Context, migration *Migration) error {
-	// Implementación de rollback para futuras versiones
-	return fmt.Errorf("rollback no implementado")
+// Rollback revierte las últimas steps migraciones aplicadas, de la más reciente a la más antigua.
+// No revierte ninguna si alguna de ellas carece de script down.
+func (m *Migrator) Rollback(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func() error { return m.rollback(ctx, steps) })
+}
+
+// rollback revierte las últimas steps migraciones; requiere el lock de migraciones
+func (m *Migrator) rollback(ctx context.Context, steps int) error {
+	if err := m.CreateMigrationsTable(ctx); err != nil {
+		return err
+	}
+	if err := m.LoadAppliedMigrations(ctx); err != nil {
+		return err
+	}
+	
+	toRevert, err := m.planRollback(steps)
+	if err != nil {
+		return err
+	}
+	return m.revertAll(ctx, toRevert)
+}
+
+// MigrateTo lleva el esquema exactamente a la versión indicada, aplicando las migraciones
+// pendientes hasta ella o revirtiendo las posteriores. La versión 0 revierte todas.
+func (m *Migrator) MigrateTo(ctx context.Context, version int) error {
+	return m.withLock(ctx, func() error { return m.migrateTo(ctx, version) })
+}
+
+// migrateTo lleva el esquema a la versión indicada; requiere el lock de migraciones
+func (m *Migrator) migrateTo(ctx context.Context, version int) error {
+	if err := m.CreateMigrationsTable(ctx); err != nil {
+		return err
+	}
+	if err := m.LoadAppliedMigrations(ctx); err != nil {
+		return err
+	}
+	
+	toApply, toRevert, err := m.planMigrateTo(version)
+	if err != nil {
+		return err
+	}
+	
+	if err := m.revertAll(ctx, toRevert); err != nil {
+		return err
+	}
+	for _, migration := range toApply {
+		if err := m.applyMigration(ctx, migration); err != nil {
+			return fmt.Errorf("error aplicando migración %d: %w", migration.Version, err)
+		}
+	}
+	
+	m.log.WithFields(logrus.Fields{
+		"target":   version,
+		"applied":  len(toApply),
+		"reverted": len(toRevert),
+	}).Info("Migración a versión completada")
+	return nil
+}
+
+// revertAll revierte en orden las migraciones dadas, que ya deben venir validadas
+func (m *Migrator) revertAll(ctx context.Context, migrations []*Migration) error {
+	for _, migration := range migrations {
+		if err := m.rollbackMigration(ctx, migration); err != nil {
+			return fmt.Errorf("error revirtiendo migración %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// planRollback devuelve las últimas steps migraciones aplicadas, de la más reciente a la más antigua
+func (m *Migrator) planRollback(steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("número de pasos inválido: %d", steps)
+	}
+	applied := m.appliedDescending()
+	if steps > len(applied) {
+		return nil, fmt.Errorf("solo hay %d migraciones aplicadas, no se pueden revertir %d", len(applied), steps)
+	}
+	toRevert := applied[:steps]
+	if err := checkDownScripts(toRevert); err != nil {
+		return nil, err
+	}
+	return toRevert, nil
+}
+
+// planMigrateTo calcula las migraciones a aplicar (en orden ascendente) y a revertir
+// (en orden descendente) para llegar a la versión indicada
+func (m *Migrator) planMigrateTo(version int) ([]*Migration, []*Migration, error) {
+	if version < 0 || (version > 0 && m.GetMigrationByVersion(version) == nil) {
+		return nil, nil, fmt.Errorf("versión de destino desconocida: %d", version)
+	}
+	
+	var toApply, toRevert []*Migration
+	for _, migration := range m.migrations {
+		if !migration.Applied && migration.Version <= version {
+			toApply = append(toApply, migration)
+		}
+	}
+	for _, migration := range m.appliedDescending() {
+		if migration.Version > version {
+			toRevert = append(toRevert, migration)
+		}
+	}
+	
+	if err := checkDownScripts(toRevert); err != nil {
+		return nil, nil, err
+	}
+	return toApply, toRevert, nil
+}
+
+// appliedDescending devuelve las migraciones aplicadas de la más reciente a la más antigua
+func (m *Migrator) appliedDescending() []*Migration {
+	var applied []*Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if m.migrations[i].Applied {
+			applied = append(applied, m.migrations[i])
+		}
+	}
+	return applied
+}
+
+// checkDownScripts falla si alguna de las migraciones no tiene script down
+func checkDownScripts(migrations []*Migration) error {
+	var missing []string
+	for _, migration := range migrations {
+		if !migration.HasDown() {
+			missing = append(missing, strconv.Itoa(migration.Version))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("faltan scripts down para las migraciones: %s", strings.Join(missing, ", "))
+	}
+	return nil
 }
 
 // Status devuelve el estado actual de las migraciones
@@ -476,6 +833,16 @@ func (m *Migrator) Status() []*Migration {
 	return m.migrations
 }
 
+// parseScriptFilename extrae versión y descripción de un archivo up o *.down.sql
+func parseScriptFilename(filename string) (int, string, bool, error) {
+	if strings.HasSuffix(filename, ".down.sql") {
+		version, description, err := parseFilename(strings.TrimSuffix(filename, ".down.sql") + ".sql")
+		return version, description, true, err
+	}
+	version, description, err := parseFilename(filename)
+	return version, description, false, err
+}
+
 // parseFilename extrae versión y descripción del nombre del archivo
 func parseFilename(filename string) (int, string, error) {
 	// Verificar extensión
@@ -530,56 +897,13 @@ func (m *Migrator) GetMigrationByVersion(version int) *Migration {
 	return nil
 }
 
-// splitSQLStatements divide un string de SQL en instrucciones individuales
-// respetando las comillas para no dividir incorrectamente por punto y coma
-// dentro de cadenas de texto
-func (m *Migrator) splitSQLStatements(sql string) []string {
-	var statements []string
-	var currentStmt strings.Builder
-	
-	// Estados para el parsing
-	inQuote := false
-	lastChar := ' '
-	
-	for _, char := range sql {
-		// Manejar comillas (detectar cuando estamos dentro de una cadena)
-		if char == '\'' && lastChar != '\\' {
-			inQuote = !inQuote
-		}
-		
-		// Si encontramos un punto y coma fuera de comillas, es un separador de instrucciones
-		if char == ';' && !inQuote {
-			currentStmt.WriteRune(char) // Incluir el punto y coma en la instrucción
-			stmtText := strings.TrimSpace(currentStmt.String())
-			
-			if stmtText != "" && stmtText != ";" {
-				statements = append(statements, stmtText)
-			}
-			
-			currentStmt.Reset()
-		} else {
-			currentStmt.WriteRune(char)
-		}
-		
-		lastChar = char
-	}
-	
-	// No olvidar la última instrucción si no termina con punto y coma
-	finalStmt := strings.TrimSpace(currentStmt.String())
-	if finalStmt != "" {
-		// Asegurarse de que termine con punto y coma
-		if !strings.HasSuffix(finalStmt, ";") {
-			finalStmt += ";"
-		}
-		statements = append(statements, finalStmt)
-	}
-	
-	return statements
-}
-
 // DropSchemaForTesting elimina todas las tablas (solo para pruebas)
 func (m *Migrator) DropSchemaForTesting(ctx context.Context) error {
 	// Este método solo debe usarse en entornos de prueba
+	if m.isSQLite() {
+		return m.dropSQLiteSchema(ctx)
+	}
+
 	// Verificar si estamos conectados a MySQL
 	var dbName string
 	if err := m.dbPool.DB().QueryRowContext(ctx, "SELECT DATABASE()").Scan(&dbName); err != nil {
@@ -598,7 +922,7 @@ func (m *Migrator) DropSchemaForTesting(ctx context.Context) error {
 		WHERE table_schema = DATABASE()
 	`
 	
-	rows, err := m.dbPool.QueryContext(ctx, query)
+	rows, err := m.dbPool.QueryContext(pool.WithConsistentRead(ctx), query)
 	if err != nil {
 		return fmt.Errorf("error consultando tablas: %w", err)
 	}
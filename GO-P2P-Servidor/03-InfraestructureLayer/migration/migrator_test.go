@@ -3,6 +3,9 @@ package migration
 import (
 	"context"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -117,3 +120,129 @@ func TestCreateMigrationsTable(t *testing.T) {
 
 // Nota: Para pruebas más completas que requieran verificar la interacción con la base de datos,
 // se podría implementar un mock de DBConnectionPool o usar una base de datos en memoria como SQLite.
+
+// newTestMigrator crea un migrador sin conexión con un logger silencioso
+func newTestMigrator() *Migrator {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return &Migrator{
+		log:        log,
+		tableName:  "schema_migrations",
+		migrations: make([]*Migration, 0),
+	}
+}
+
+// TestSplitUpDown verifica la separación de secciones up y down en un mismo archivo
+func TestSplitUpDown(t *testing.T) {
+	up, down := splitUpDown("CREATE TABLE a (id INT);")
+	assert.Equal(t, "CREATE TABLE a (id INT);", up)
+	assert.Empty(t, down)
+
+	up, down = splitUpDown("-- +migrate Up\nCREATE TABLE a (id INT);\n-- +migrate Down\nDROP TABLE a;\n")
+	assert.Equal(t, "CREATE TABLE a (id INT);", strings.TrimSpace(up))
+	assert.Equal(t, "DROP TABLE a;", down)
+}
+
+// TestLoadMigrationsFromDir_DownScripts verifica la carga de down en línea y en archivos *.down.sql
+func TestLoadMigrationsFromDir_DownScripts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"1_create_a.sql":      "CREATE TABLE a (id INT);\n-- +migrate Down\nDROP TABLE a;",
+		"2_create_b.sql":      "CREATE TABLE b (id INT);",
+		"2_create_b.down.sql": "DROP TABLE b;",
+		"3_seed_a.sql":        "INSERT INTO a VALUES (1);",
+		"4_orphan.down.sql":   "DROP TABLE c;",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	migrator := newTestMigrator()
+	require.NoError(t, migrator.LoadMigrationsFromDir(dir))
+
+	migrations := migrator.Status()
+	require.Len(t, migrations, 3, "el down huérfano no debe crear una migración")
+	assert.Equal(t, "create a", migrations[0].Description)
+	assert.Equal(t, "DROP TABLE a;", migrations[0].DownSQL)
+	assert.NotContains(t, migrations[0].SQL, "DROP TABLE")
+	assert.Equal(t, "create b", migrations[1].Description)
+	assert.Equal(t, "DROP TABLE b;", migrations[1].DownSQL)
+	assert.False(t, migrations[2].HasDown())
+}
+
+// TestEmbeddedMigrationsHaveDown verifica que todas las migraciones incluidas se pueden revertir
+func TestEmbeddedMigrationsHaveDown(t *testing.T) {
+	migrator := newTestMigrator()
+	require.NoError(t, migrator.LoadEmbeddedMigrations())
+	require.NotEmpty(t, migrator.Status())
+	for _, m := range migrator.Status() {
+		assert.True(t, m.HasDown(), "la migración %d no tiene script down", m.Version)
+	}
+}
+
+// TestPlanRollback verifica el orden de reversión y el rechazo si falta un down
+func TestPlanRollback(t *testing.T) {
+	migrator := newTestMigrator()
+	migrator.migrations = []*Migration{
+		{Version: 1, SQL: "x", Applied: true},
+		{Version: 2, SQL: "x", DownSQL: "y", Applied: true},
+		{Version: 3, SQL: "x", DownSQL: "y", Applied: true},
+		{Version: 4, SQL: "x", DownSQL: "y"},
+	}
+
+	plan, err := migrator.planRollback(2)
+	require.NoError(t, err)
+	require.Len(t, plan, 2)
+	assert.Equal(t, 3, plan[0].Version)
+	assert.Equal(t, 2, plan[1].Version)
+
+	_, err = migrator.planRollback(3)
+	assert.ErrorContains(t, err, "1", "debe rechazar revertir una migración sin down")
+
+	_, err = migrator.planRollback(4)
+	assert.Error(t, err, "no hay tantas migraciones aplicadas")
+
+	_, err = migrator.planRollback(0)
+	assert.Error(t, err)
+}
+
+// TestPlanMigrateTo verifica la planificación hacia arriba y hacia abajo
+func TestPlanMigrateTo(t *testing.T) {
+	migrator := newTestMigrator()
+	migrator.migrations = []*Migration{
+		{Version: 1, SQL: "x", Applied: true},
+		{Version: 2, SQL: "x", DownSQL: "y", Applied: true},
+		{Version: 3, SQL: "x", DownSQL: "y", Applied: true},
+		{Version: 4, SQL: "x", DownSQL: "y"},
+		{Version: 5, SQL: "x", DownSQL: "y"},
+	}
+
+	// Hacia arriba
+	toApply, toRevert, err := migrator.planMigrateTo(4)
+	require.NoError(t, err)
+	assert.Empty(t, toRevert)
+	require.Len(t, toApply, 1)
+	assert.Equal(t, 4, toApply[0].Version)
+
+	// Hacia abajo
+	toApply, toRevert, err = migrator.planMigrateTo(1)
+	require.NoError(t, err)
+	assert.Empty(t, toApply)
+	require.Len(t, toRevert, 2)
+	assert.Equal(t, 3, toRevert[0].Version)
+	assert.Equal(t, 2, toRevert[1].Version)
+
+	// Versión actual: nada que hacer
+	toApply, toRevert, err = migrator.planMigrateTo(3)
+	require.NoError(t, err)
+	assert.Empty(t, toApply)
+	assert.Empty(t, toRevert)
+
+	// La migración 1 no tiene down
+	_, _, err = migrator.planMigrateTo(0)
+	assert.Error(t, err)
+
+	// Versión inexistente
+	_, _, err = migrator.planMigrateTo(42)
+	assert.Error(t, err)
+}
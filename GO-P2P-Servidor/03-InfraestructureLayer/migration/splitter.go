@@ -0,0 +1,153 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultDelimiter es el delimitador de instrucciones inicial, como en el cliente mysql
+const defaultDelimiter = ";"
+
+// splitStatements divide un script MySQL en instrucciones individuales sin el delimitador final.
+// Entiende cadenas con comillas simples y dobles (con escapes \ y comilla duplicada),
+// identificadores entre comillas invertidas, comentarios "-- ", "#" y "/* */", y el comando
+// DELIMITER del cliente mysql para scripts con procedimientos o triggers.
+// Los comentarios se eliminan salvo los ejecutables "/*! */" y las pistas del optimizador "/*+ */".
+func splitStatements(script string) ([]string, error) {
+	var statements []string
+	var current strings.Builder
+	delimiter := defaultDelimiter
+	line := 1
+
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+
+		// DELIMITER solo se reconoce al principio de una línea y de una instrucción
+		if strings.TrimSpace(current.String()) == "" && atLineStart(script, i) && isDelimiterCommand(script[i:]) {
+			end := lineEnd(script, i)
+			fields := strings.Fields(script[i+len("DELIMITER") : end])
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("línea %d: DELIMITER sin delimitador", line)
+			}
+			delimiter = fields[0]
+			current.Reset()
+			i = end
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(script[i:], delimiter):
+			flush()
+			i += len(delimiter)
+
+		case c == '\'' || c == '"' || c == '`':
+			end, err := skipQuoted(script, i)
+			if err != nil {
+				return nil, fmt.Errorf("línea %d: %w", line, err)
+			}
+			current.WriteString(script[i:end])
+			line += strings.Count(script[i:end], "\n")
+			i = end
+
+		case c == '#' || isDashComment(script[i:]):
+			// El salto de línea se procesa como texto normal
+			current.WriteByte(' ')
+			i = lineEnd(script, i)
+
+		case strings.HasPrefix(script[i:], "/*"):
+			closing := strings.Index(script[i+2:], "*/")
+			if closing < 0 {
+				return nil, fmt.Errorf("línea %d: comentario /* sin cerrar", line)
+			}
+			comment := script[i : i+2+closing+2]
+			if strings.HasPrefix(comment, "/*!") || strings.HasPrefix(comment, "/*+") {
+				current.WriteString(comment)
+			} else {
+				current.WriteByte(' ')
+			}
+			line += strings.Count(comment, "\n")
+			i += len(comment)
+
+		default:
+			if c == '\n' {
+				line++
+			}
+			current.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	return statements, nil
+}
+
+// skipQuoted devuelve la posición siguiente al cierre de la cadena o identificador que empieza en start
+func skipQuoted(script string, start int) (int, error) {
+	quote := script[start]
+	for j := start + 1; j < len(script); j++ {
+		switch script[j] {
+		case '\\':
+			// Las comillas invertidas no admiten escapes con barra
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			// Comilla duplicada: forma parte del contenido
+			if j+1 < len(script) && script[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("comilla %c sin cerrar", quote)
+}
+
+// isDashComment indica si el texto empieza por un comentario "--", que en MySQL
+// requiere un espacio o fin de línea detrás
+func isDashComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+	return len(s) == 2 || s[2] == ' ' || s[2] == '\t' || s[2] == '\n' || s[2] == '\r'
+}
+
+// isDelimiterCommand indica si el texto empieza por el comando DELIMITER
+func isDelimiterCommand(s string) bool {
+	const command = "DELIMITER"
+	if len(s) <= len(command) || !strings.EqualFold(s[:len(command)], command) {
+		return false
+	}
+	next := s[len(command)]
+	return next == ' ' || next == '\t'
+}
+
+// atLineStart indica si solo hay espacios entre el inicio de la línea y la posición dada
+func atLineStart(script string, pos int) bool {
+	for j := pos - 1; j >= 0; j-- {
+		switch script[j] {
+		case '\n':
+			return true
+		case ' ', '\t', '\r':
+			continue
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// lineEnd devuelve la posición del salto de línea que termina la línea actual, o el final del script
+func lineEnd(script string, pos int) int {
+	if end := strings.IndexByte(script[pos:], '\n'); end >= 0 {
+		return pos + end
+	}
+	return len(script)
+}
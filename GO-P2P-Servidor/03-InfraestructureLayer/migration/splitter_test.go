@@ -0,0 +1,197 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitStatements verifica la división de scripts con casos difíciles
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		name    string
+		script  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "instrucciones simples",
+			script: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:   "última instrucción sin delimitador",
+			script: "SELECT 1;\nSELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "instrucciones vacías",
+			script: ";;\n  ;SELECT 1;;",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "punto y coma en cadena",
+			script: "INSERT INTO configuracion_servidor VALUES (1, 'max_connections=100;wait_timeout=300');",
+			want:   []string{"INSERT INTO configuracion_servidor VALUES (1, 'max_connections=100;wait_timeout=300')"},
+		},
+		{
+			name:   "guiones dentro de cadena",
+			script: "INSERT INTO t VALUES ('a -- no es comentario');",
+			want:   []string{"INSERT INTO t VALUES ('a -- no es comentario')"},
+		},
+		{
+			name:   "almohadilla dentro de cadena",
+			script: "INSERT INTO t VALUES ('#1; primero');",
+			want:   []string{"INSERT INTO t VALUES ('#1; primero')"},
+		},
+		{
+			name:   "comilla duplicada",
+			script: "INSERT INTO t VALUES ('it''s; ok');SELECT 2;",
+			want:   []string{"INSERT INTO t VALUES ('it''s; ok')", "SELECT 2"},
+		},
+		{
+			name:   "comilla escapada con barra",
+			script: `INSERT INTO t VALUES ('it\'s; ok', 'barra\\');SELECT 2;`,
+			want:   []string{`INSERT INTO t VALUES ('it\'s; ok', 'barra\\')`, "SELECT 2"},
+		},
+		{
+			name:   "comillas dobles",
+			script: `INSERT INTO t VALUES ("a;b", "c""d");`,
+			want:   []string{`INSERT INTO t VALUES ("a;b", "c""d")`},
+		},
+		{
+			name:   "identificador con comillas invertidas",
+			script: "CREATE TABLE `raro;--nombre` (`col\\` INT);",
+			want:   []string{"CREATE TABLE `raro;--nombre` (`col\\` INT)"},
+		},
+		{
+			name:   "cadena con salto de línea",
+			script: "INSERT INTO t VALUES ('línea 1;\nlínea 2');",
+			want:   []string{"INSERT INTO t VALUES ('línea 1;\nlínea 2')"},
+		},
+		{
+			name:   "comentario de línea con punto y coma",
+			script: "SELECT 1; -- comentario; con punto y coma\nSELECT 2;",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "comentario al final de una columna",
+			script: "CREATE TABLE t (\n  a INT, -- NULL si es canal\n  b INT\n);",
+			want:   []string{"CREATE TABLE t (\n  a INT,  \n  b INT\n)"},
+		},
+		{
+			name:   "comentario con almohadilla",
+			script: "# cabecera; con punto y coma\nSELECT 1;",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "doble guion sin espacio no es comentario",
+			script: "SELECT 1--1;",
+			want:   []string{"SELECT 1--1"},
+		},
+		{
+			name:   "doble guion al final del script",
+			script: "SELECT 1;\n--",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "comentario de bloque de varias líneas",
+			script: "/*----\n  Tabla; 'sin cerrar\n----*/\nCREATE TABLE a (id INT);",
+			want:   []string{"CREATE TABLE a (id INT)"},
+		},
+		{
+			name:   "comentario de bloque en medio",
+			script: "SELECT /* ; */ 1;",
+			want:   []string{"SELECT   1"},
+		},
+		{
+			name:   "comentario ejecutable y pista del optimizador",
+			script: "SELECT /*+ MAX_EXECUTION_TIME(1000) */ 1;\n/*!50001 SET @a = 1 */;",
+			want:   []string{"SELECT /*+ MAX_EXECUTION_TIME(1000) */ 1", "/*!50001 SET @a = 1 */"},
+		},
+		{
+			name: "procedimiento con DELIMITER",
+			script: "DELIMITER $$\n" +
+				"CREATE PROCEDURE limpiar()\nBEGIN\n  DELETE FROM a WHERE x = ';';\n  DELETE FROM b;\nEND$$\n" +
+				"DELIMITER ;\n" +
+				"CALL limpiar();",
+			want: []string{
+				"CREATE PROCEDURE limpiar()\nBEGIN\n  DELETE FROM a WHERE x = ';';\n  DELETE FROM b;\nEND",
+				"CALL limpiar()",
+			},
+		},
+		{
+			name: "trigger con DELIMITER en minúsculas",
+			script: "delimiter //\n" +
+				"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW\nBEGIN\n  SET NEW.x = 1; -- fijo\nEND //\n" +
+				"delimiter ;\n",
+			want: []string{"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW\nBEGIN\n  SET NEW.x = 1;  \nEND"},
+		},
+		{
+			name:   "DELIMITER dentro de una instrucción no es un comando",
+			script: "SELECT 'x' AS\nDELIMITER ;",
+			want:   []string{"SELECT 'x' AS\nDELIMITER"},
+		},
+		{
+			name:   "DELIMITER como parte de un identificador",
+			script: "DELIMITERS;",
+			want:   []string{"DELIMITERS"},
+		},
+		{
+			name:    "cadena sin cerrar",
+			script:  "SELECT 1;\nINSERT INTO t VALUES ('abierta);",
+			wantErr: true,
+		},
+		{
+			name:    "comentario de bloque sin cerrar",
+			script:  "SELECT 1; /* abierto",
+			wantErr: true,
+		},
+		{
+			name:    "DELIMITER sin delimitador",
+			script:  "DELIMITER   \nSELECT 1;",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := splitStatements(tc.script)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// TestSplitStatements_LineaDelError verifica que el error indica la línea
+func TestSplitStatements_LineaDelError(t *testing.T) {
+	_, err := splitStatements("SELECT 1;\n/* a\n b */\nSELECT 'x\n")
+	assert.ErrorContains(t, err, "línea 4")
+}
+
+// TestSplitStatements_Embedded verifica que todas las migraciones incluidas se pueden analizar
+func TestSplitStatements_Embedded(t *testing.T) {
+	migrator := newTestMigrator()
+	require.NoError(t, migrator.LoadEmbeddedMigrations())
+	for _, m := range migrator.Status() {
+		for _, script := range []string{m.SQL, m.DownSQL} {
+			statements, err := splitStatements(script)
+			require.NoError(t, err, "migración %d", m.Version)
+			for _, stmt := range statements {
+				assert.NotContains(t, stmt, "--", "migración %d: comentario sin eliminar", m.Version)
+			}
+		}
+	}
+
+	seed := migrator.GetMigrationByVersion(20250507000002)
+	require.NotNil(t, seed)
+	statements, err := splitStatements(seed.SQL)
+	require.NoError(t, err)
+	require.Len(t, statements, 4)
+	assert.Contains(t, statements[3], "'max_connections=100;wait_timeout=300'")
+}
@@ -0,0 +1,297 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pool"
+)
+
+// VerifyReport resume las diferencias entre los scripts de migración, la tabla de
+// control y el esquema real de la base de datos
+type VerifyReport struct {
+	Edited  []*Migration // Aplicadas cuyo script cambió después de aplicarse
+	Missing []*Migration // Sin aplicar con versión anterior a la actual
+	Unknown []int        // Registradas en la tabla de control sin script conocido
+	Drift   []string     // Diferencias entre information_schema y el esquema esperado
+}
+
+// OK indica si no se encontró ninguna diferencia
+func (r *VerifyReport) OK() bool {
+	return len(r.Edited) == 0 && len(r.Missing) == 0 && len(r.Unknown) == 0 && len(r.Drift) == 0
+}
+
+// Verify compara los scripts cargados con la tabla de control y con el esquema real.
+// A diferencia de LoadAppliedMigrations no falla ante scripts modificados: los reporta.
+func (m *Migrator) Verify(ctx context.Context) (*VerifyReport, error) {
+	applied, err := m.loadAppliedRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.markApplied(applied)
+
+	report := m.buildVerifyReport(applied)
+
+	drift, err := m.CheckSchemaDrift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Drift = drift
+	return report, nil
+}
+
+// buildVerifyReport clasifica las migraciones según su estado en la tabla de control
+func (m *Migrator) buildVerifyReport(applied map[int]appliedRecord) *VerifyReport {
+	report := &VerifyReport{}
+	current := m.GetCurrentVersion()
+	for _, migration := range m.migrations {
+		switch {
+		case migration.Edited():
+			report.Edited = append(report.Edited, migration)
+		case !migration.Applied && migration.Version < current:
+			report.Missing = append(report.Missing, migration)
+		}
+	}
+	for version := range applied {
+		if m.GetMigrationByVersion(version) == nil {
+			report.Unknown = append(report.Unknown, version)
+		}
+	}
+	sort.Ints(report.Unknown)
+	return report
+}
+
+// CheckSchemaDrift compara las tablas y columnas de information_schema (o del catálogo
+// de SQLite) con las que deberían haber producido las migraciones aplicadas
+func (m *Migrator) CheckSchemaDrift(ctx context.Context) ([]string, error) {
+	rows, err := m.dbPool.QueryContext(pool.WithConsistentRead(ctx), m.schemaColumnsQuery())
+	if err != nil {
+		return nil, fmt.Errorf("error consultando information_schema: %w", err)
+	}
+	defer rows.Close()
+
+	actual := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("error leyendo information_schema: %w", err)
+		}
+		table = strings.ToLower(table)
+		if actual[table] == nil {
+			actual[table] = make(map[string]bool)
+		}
+		actual[table][strings.ToLower(column)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo information_schema: %w", err)
+	}
+
+	// La tabla de control no la crea ninguna migración
+	delete(actual, strings.ToLower(m.tableName))
+
+	return diffSchemas(m.expectedSchema(), actual), nil
+}
+
+// expectedSchema reproduce el DDL de las migraciones aplicadas para obtener las
+// columnas de cada tabla. Solo interpreta CREATE, ALTER, RENAME y DROP TABLE; las
+// migraciones Go no se reflejan, por lo que deben limitarse a cambios de datos.
+func (m *Migrator) expectedSchema() map[string]map[string]bool {
+	schema := make(map[string]map[string]bool)
+	for _, migration := range m.migrations {
+		if !migration.Applied {
+			continue
+		}
+		// Un script que no se puede analizar fallará al aplicarse; aquí se omite
+		statements, _ := splitStatements(migration.SQL)
+		for _, stmt := range statements {
+			applyDDL(schema, stmt)
+		}
+	}
+	return schema
+}
+
+// applyDDL aplica una instrucción al esquema esperado
+func applyDDL(schema map[string]map[string]bool, stmt string) {
+	words := strings.Fields(stmt)
+	if len(words) < 3 || !strings.EqualFold(words[1], "TABLE") {
+		return
+	}
+
+	switch strings.ToUpper(words[0]) {
+	case "CREATE":
+		open := strings.Index(stmt, "(")
+		closing := strings.LastIndex(stmt, ")")
+		if open < 0 || closing < open {
+			return
+		}
+		name := tableName(skipIfExists(words[2:]))
+		columns := make(map[string]bool)
+		for _, def := range splitTopLevel(stmt[open+1 : closing]) {
+			fields := strings.Fields(def)
+			if len(fields) > 0 && !isConstraintKeyword(fields[0]) {
+				columns[identifier(fields[0])] = true
+			}
+		}
+		schema[name] = columns
+
+	case "DROP":
+		for _, name := range strings.Split(strings.Join(skipIfExists(words[2:]), " "), ",") {
+			delete(schema, identifier(strings.TrimSpace(name)))
+		}
+
+	case "RENAME":
+		// RENAME TABLE a TO b
+		if len(words) >= 5 && strings.EqualFold(words[3], "TO") {
+			from, to := identifier(words[2]), identifier(words[4])
+			schema[to] = schema[from]
+			delete(schema, from)
+		}
+
+	case "ALTER":
+		name := identifier(words[2])
+		columns := schema[name]
+		if columns == nil {
+			return
+		}
+		// ALTER TABLE a RENAME TO b renombra la tabla, no una columna
+		if len(words) >= 6 && strings.EqualFold(words[3], "RENAME") && strings.EqualFold(words[4], "TO") {
+			schema[identifier(words[5])] = columns
+			delete(schema, name)
+			return
+		}
+		rest := strings.TrimSpace(strings.SplitN(stmt, words[2], 2)[1])
+		for _, action := range splitTopLevel(rest) {
+			applyAlterAction(columns, strings.Fields(action))
+		}
+	}
+}
+
+// applyAlterAction aplica una acción de ALTER TABLE sobre las columnas de una tabla
+func applyAlterAction(columns map[string]bool, action []string) {
+	if len(action) < 2 {
+		return
+	}
+	verb := strings.ToUpper(action[0])
+	args := action[1:]
+	if strings.EqualFold(args[0], "COLUMN") {
+		args = args[1:]
+	} else if isConstraintKeyword(args[0]) {
+		return
+	}
+	if len(args) == 0 {
+		return
+	}
+
+	switch verb {
+	case "ADD":
+		columns[identifier(args[0])] = true
+	case "DROP":
+		delete(columns, identifier(args[0]))
+	case "RENAME":
+		// RENAME COLUMN a TO b
+		if len(args) >= 3 && strings.EqualFold(args[1], "TO") {
+			delete(columns, identifier(args[0]))
+			columns[identifier(args[2])] = true
+		}
+	case "CHANGE":
+		// CHANGE [COLUMN] viejo nuevo tipo
+		if len(args) >= 2 {
+			delete(columns, identifier(args[0]))
+			columns[identifier(args[1])] = true
+		}
+	}
+}
+
+// diffSchemas describe las diferencias entre el esquema esperado y el real
+func diffSchemas(expected, actual map[string]map[string]bool) []string {
+	var drift []string
+	for table, columns := range expected {
+		live, ok := actual[table]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("tabla faltante: %s", table))
+			continue
+		}
+		for column := range columns {
+			if !live[column] {
+				drift = append(drift, fmt.Sprintf("columna faltante: %s.%s", table, column))
+			}
+		}
+		for column := range live {
+			if !columns[column] {
+				drift = append(drift, fmt.Sprintf("columna inesperada: %s.%s", table, column))
+			}
+		}
+	}
+	for table := range actual {
+		if _, ok := expected[table]; !ok {
+			drift = append(drift, fmt.Sprintf("tabla inesperada: %s", table))
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// splitTopLevel divide por comas que no estén dentro de paréntesis ni comillas
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	var quote rune
+	start := 0
+	for i, char := range s {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')':
+			depth--
+		case char == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// skipIfExists omite el prefijo IF [NOT] EXISTS
+func skipIfExists(words []string) []string {
+	if len(words) > 0 && strings.EqualFold(words[0], "IF") {
+		for i, w := range words {
+			if strings.EqualFold(w, "EXISTS") {
+				return words[i+1:]
+			}
+		}
+	}
+	return words
+}
+
+// tableName extrae el nombre de tabla de "nombre (" o "nombre("
+func tableName(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	return identifier(strings.SplitN(words[0], "(", 2)[0])
+}
+
+// identifier normaliza un identificador quitando comillas invertidas
+func identifier(s string) string {
+	return strings.ToLower(strings.Trim(s, "`"))
+}
+
+// isConstraintKeyword indica si una definición es una restricción o índice en lugar de una columna
+func isConstraintKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "PRIMARY", "FOREIGN", "UNIQUE", "CHECK", "CONSTRAINT", "INDEX", "KEY", "FULLTEXT", "SPATIAL":
+		return true
+	}
+	return false
+}
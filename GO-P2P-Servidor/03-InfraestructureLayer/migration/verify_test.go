@@ -0,0 +1,151 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChecksum verifica el cálculo de checksums y la detección de scripts modificados
+func TestChecksum(t *testing.T) {
+	migrator := newTestMigrator()
+	migrator.addScript(1, "create a", "CREATE TABLE a (id INT);\n-- +migrate Down\nDROP TABLE a;", false)
+	migration := migrator.GetMigrationByVersion(1)
+	require.NotNil(t, migration)
+
+	assert.Len(t, migration.Checksum, 64)
+	assert.Equal(t, checksumSQL("CREATE TABLE a (id INT);\r\n"), checksumSQL("CREATE TABLE a (id INT);\n"),
+		"el fin de línea no debe afectar al checksum")
+
+	// Añadir o cambiar el down no altera el checksum del up
+	before := migration.Checksum
+	migrator.addScript(1, "create a", "DROP TABLE IF EXISTS a;", true)
+	assert.Equal(t, before, migration.Checksum)
+
+	migration.Applied = true
+	migration.AppliedChecksum = ""
+	assert.False(t, migration.Edited(), "sin checksum registrado no se puede saber si cambió")
+	migration.AppliedChecksum = before
+	assert.False(t, migration.Edited())
+
+	migrator.addScript(1, "create a", "CREATE TABLE a (id BIGINT);", false)
+	assert.True(t, migration.Edited())
+}
+
+// TestBuildVerifyReport verifica la clasificación de migraciones modificadas, faltantes y desconocidas
+func TestBuildVerifyReport(t *testing.T) {
+	migrator := newTestMigrator()
+	for version, script := range map[int]string{1: "A", 2: "B", 3: "C", 4: "D"} {
+		migrator.addScript(version, "m", script, false)
+	}
+	migrator.sortMigrations()
+
+	now := time.Now()
+	applied := map[int]appliedRecord{
+		1: {AppliedAt: now, Checksum: checksumSQL("A")},
+		3: {AppliedAt: now, Checksum: checksumSQL("C editada")},
+		9: {AppliedAt: now, Checksum: checksumSQL("borrada")},
+	}
+	migrator.markApplied(applied)
+	report := migrator.buildVerifyReport(applied)
+
+	require.Len(t, report.Edited, 1)
+	assert.Equal(t, 3, report.Edited[0].Version)
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, 2, report.Missing[0].Version)
+	assert.Equal(t, []int{9}, report.Unknown)
+	assert.False(t, report.OK())
+
+	assert.True(t, (&VerifyReport{}).OK())
+}
+
+// TestApplyDDL verifica la interpretación del DDL soportado
+func TestApplyDDL(t *testing.T) {
+	schema := make(map[string]map[string]bool)
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS a ( id CHAR(36) PRIMARY KEY, nombre VARCHAR(10) NOT NULL, total DECIMAL(10,2), CHECK (nombre IN ('x','y')), FOREIGN KEY (id) REFERENCES b(id) )",
+		"CREATE TABLE `b`(id INT, UNIQUE KEY uk (id))",
+		"ALTER TABLE a ADD COLUMN extra INT NULL, ADD CONSTRAINT fk FOREIGN KEY (extra) REFERENCES b(id), ADD UNIQUE (extra)",
+		"ALTER TABLE a DROP COLUMN total",
+		"ALTER TABLE a DROP CHECK a_chk_1",
+		"ALTER TABLE a RENAME COLUMN nombre TO titulo",
+		"ALTER TABLE a CHANGE extra adicional BIGINT",
+		"CREATE INDEX idx_a ON a(titulo)",
+		"RENAME TABLE b TO c",
+		"CREATE TABLE d (id INT)",
+		"DROP TABLE IF EXISTS d",
+	}
+	for _, stmt := range statements {
+		applyDDL(schema, stmt)
+	}
+
+	assert.Equal(t, map[string]map[string]bool{
+		"a": {"id": true, "titulo": true, "adicional": true},
+		"c": {"id": true},
+	}, schema)
+}
+
+// TestExpectedSchema_Embedded verifica el esquema esperado tras las migraciones incluidas
+func TestExpectedSchema_Embedded(t *testing.T) {
+	migrator := newTestMigrator()
+	require.NoError(t, migrator.LoadEmbeddedMigrations())
+	for _, m := range migrator.Status() {
+		m.Applied = true
+	}
+
+	schema := migrator.expectedSchema()
+	assert.Len(t, schema, 24)
+	assert.True(t, schema["peer"]["ultima_sync_at"])
+	assert.True(t, schema["routed_message"]["nodo_anterior_id"])
+	assert.True(t, schema["mensaje_servidor"]["version_hlc"])
+	assert.True(t, schema["mensaje_servidor"]["archivo_id"])
+	assert.False(t, schema["mensaje_servidor"]["fk_msg_archivo"])
+	assert.True(t, schema["mensaje_servidor"]["eliminado_at"])
+	assert.True(t, schema["mensaje_servidor"]["padre_id"])
+	assert.False(t, schema["mensaje_servidor"]["fk_msg_padre"])
+	assert.True(t, schema["mensaje_edicion"]["contenido_anterior"])
+	assert.False(t, schema["mensaje_edicion"]["chk_mensaje_edicion_tipo"])
+	assert.True(t, schema["reaccion_mensaje"]["emoji"])
+	assert.True(t, schema["reaccion_mensaje"]["retirada_at"])
+	assert.Len(t, schema["reaccion_mensaje"], 6)
+	assert.False(t, schema["acuse_mensaje"]["chk_acuse_mensaje_estado"])
+	assert.Len(t, schema["acuse_mensaje"], 4)
+	assert.True(t, schema["marcador_lectura"]["mensaje_timestamp"])
+	assert.Len(t, schema["marcador_lectura"], 5)
+	assert.True(t, schema["evento_pendiente"]["datos"])
+	assert.Len(t, schema["evento_pendiente"], 5)
+	assert.True(t, schema["mensaje_programado"]["expira_en"])
+	assert.False(t, schema["mensaje_programado"]["chk_mensaje_programado_estado"])
+	assert.Len(t, schema["mensaje_programado"], 12)
+	assert.True(t, schema["mencion"]["notificacion_id"])
+	assert.False(t, schema["mencion"]["chk_mencion_tipo"])
+	assert.Len(t, schema["mencion"], 8)
+	assert.True(t, schema["silencio_mencion"]["hasta"])
+	assert.Len(t, schema["silencio_mencion"], 5)
+	assert.True(t, schema["fijado_canal"]["retirado_en"])
+	assert.False(t, schema["fijado_canal"]["chk_fijado_canal_objetivo"])
+	assert.Len(t, schema["fijado_canal"], 10)
+	assert.Len(t, schema["usuario_servidor"], 9)
+}
+
+// TestDiffSchemas verifica el informe de divergencias
+func TestDiffSchemas(t *testing.T) {
+	expected := map[string]map[string]bool{
+		"a": {"id": true, "nombre": true},
+		"b": {"id": true},
+	}
+	actual := map[string]map[string]bool{
+		"a": {"id": true, "manual": true},
+		"z": {"id": true},
+	}
+	assert.Equal(t, []string{
+		"columna faltante: a.nombre",
+		"columna inesperada: a.manual",
+		"tabla faltante: b",
+		"tabla inesperada: z",
+	}, diffSchemas(expected, actual))
+
+	assert.Empty(t, diffSchemas(expected, expected))
+}
@@ -13,13 +13,27 @@ import (
 	"time"
 
 	"github.com/go-sql-driver/mysql"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
+// Drivers de base de datos soportados por el pool
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// SQLiteMemory es la ruta que selecciona una base de datos SQLite en memoria
+const SQLiteMemory = ":memory:"
+
 // DBConfig contiene toda la configuración para la conexión a la base de datos
 type DBConfig struct {
 	Database struct {
+		// Driver es mysql (por defecto) o sqlite
+		Driver string `yaml:"driver"`
+		// Path es el archivo de SQLite, o ":memory:" para una base de datos en memoria
+		Path     string `yaml:"path"`
 		Username string `yaml:"username"`
 		Password string `yaml:"password"`
 		Host     string `yaml:"host"`
@@ -34,6 +48,12 @@ type DBConfig struct {
 		MaxConnectionLifetime string `yaml:"max_connection_lifetime"`
 		MaxConnectionIdleTime string `yaml:"max_connection_idle_time"`
 	} `yaml:"pool"`
+	// Replicas son réplicas de lectura de la base de datos principal (solo MySQL)
+	Replicas    []ReplicaConfig `yaml:"replicas"`
+	Replication struct {
+		MaxLag        string `yaml:"max_lag"`
+		CheckInterval string `yaml:"check_interval"`
+	} `yaml:"replication"`
 }
 
 // TLSConfig contiene la configuración para conexiones seguras
@@ -43,11 +63,24 @@ type TLSConfig struct {
 }
 
 // DBConnectionPool es un wrapper sobre sql.DB que proporciona métricas
-// y gestión centralizada de conexiones a la base de datos MySQL.
+// y gestión centralizada de conexiones a la base de datos MySQL o SQLite.
+// Las escrituras y, por defecto, las lecturas van a la base de datos principal;
+// las lecturas de QueryContext y QueryRowContext con un contexto marcado con
+// WithReplicaRead se reparten entre las réplicas sanas.
 type DBConnectionPool struct {
 	db             *sql.DB
 	config         *DBConfig
+	driver         string
 	log            *logrus.Logger
+	primary        *dbBackend
+	replicas       []*dbBackend
+	nextReplica    uint64
+	maxReplicaLag  time.Duration
+	lagProbe       func(ctx context.Context, db *sql.DB) (time.Duration, error)
+	connLifetime   time.Duration
+	connIdleTime   time.Duration
+	stopMonitor    context.CancelFunc
+	monitorDone    chan struct{}
 	queryCount     int64
 	errorCount     int64
 	latencySum     int64
@@ -71,94 +104,174 @@ func LoadDBConfig(configPath string) (*DBConfig, error) {
 	return &config, nil
 }
 
-// NewDBConnectionPool crea un nuevo pool de conexiones a MySQL desde un archivo de configuración
+// NewDBConnectionPool crea un nuevo pool de conexiones desde un archivo de configuración
 func NewDBConnectionPool(configPath string) (*DBConnectionPool, error) {
 	config, err := LoadDBConfig(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("error cargando configuración: %w", err)
 	}
 
+	// Resolver rutas relativas al archivo de configuración
+	baseDir := filepath.Dir(configPath)
+	if config.Database.CertPath != "" && !filepath.IsAbs(config.Database.CertPath) {
+		config.Database.CertPath = filepath.Join(baseDir, config.Database.CertPath)
+	}
+	if path := config.Database.Path; path != "" && path != SQLiteMemory && !filepath.IsAbs(path) {
+		config.Database.Path = filepath.Join(baseDir, path)
+	}
+
+	return NewDBConnectionPoolFromConfig(config)
+}
+
+// NewSQLiteMemoryPool crea un pool sobre una base de datos SQLite en memoria, propia
+// de este pool. Pensado para pruebas y despliegues embebidos.
+func NewSQLiteMemoryPool() (*DBConnectionPool, error) {
+	config := &DBConfig{}
+	config.Database.Driver = DriverSQLite
+	config.Database.Path = SQLiteMemory
+	return NewDBConnectionPoolFromConfig(config)
+}
+
+// NewDBConnectionPoolFromConfig crea un nuevo pool de conexiones a partir de una configuración ya cargada
+func NewDBConnectionPoolFromConfig(config *DBConfig) (*DBConnectionPool, error) {
 	// Crear logger
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{})
 
-	// Registrar TLS si está habilitado
-	if config.Database.SSLMode == "REQUIRED" {
-		// Resolver ruta del certificado (relativa al archivo de configuración si no es absoluta)
-		certPath := config.Database.CertPath
-		if !filepath.IsAbs(certPath) {
-			certPath = filepath.Join(filepath.Dir(configPath), certPath)
-		}
-
-		tlsConfig := TLSConfig{
-			CertPath:           certPath,
-			InsecureSkipVerify: false,
-		}
-
-		if err := registerTLS(tlsConfig); err != nil {
-			return nil, fmt.Errorf("error configurando TLS: %w", err)
-		}
+	driver := config.Database.Driver
+	if driver == "" {
+		driver = DriverMySQL
 	}
 
-	// Construir DSN
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
-		config.Database.Username,
-		config.Database.Password,
-		config.Database.Host,
-		config.Database.Port,
-		config.Database.Database,
-	)
-
-	// Añadir TLS si está habilitado
-	if config.Database.SSLMode == "REQUIRED" {
-		dsn += "&tls=custom"
+	var dsn, driverName string
+	switch driver {
+	case DriverMySQL:
+		var err error
+		if dsn, err = mysqlDSN(config); err != nil {
+			return nil, err
+		}
+		driverName = "mysql"
+	case DriverSQLite:
+		dsn = sqliteDSN(config)
+		driverName = "sqlite3"
+	default:
+		return nil, fmt.Errorf("driver de base de datos no soportado: %q", driver)
 	}
 
 	// Abrir la conexión
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open(driverName, dsn)
 	if err != nil {
-		return nil, fmt.Errorf("error abriendo conexión a MySQL: %w", err)
+		return nil, fmt.Errorf("error abriendo conexión a %s: %w", driver, err)
 	}
 
-	// Configurar el pool
-	db.SetMaxOpenConns(config.Pool.MaxOpenConnections)
-	db.SetMaxIdleConns(config.Pool.MaxIdleConnections)
-
-	// Configurar tiempos máximos
-	connLifetime, err := time.ParseDuration(config.Pool.MaxConnectionLifetime)
-	if err != nil {
-		logger.Warnf("Error en configuración de MaxConnectionLifetime '%s': %v. Usando valor por defecto (1h).",
-			config.Pool.MaxConnectionLifetime, err)
-		connLifetime = time.Hour
+	pool := &DBConnectionPool{
+		db:       db,
+		config:   config,
+		driver:   driver,
+		log:      logger,
+		primary:  &dbBackend{name: RolePrimary, role: RolePrimary, db: db, healthy: 1},
+		lagProbe: replicaLag,
 	}
-	db.SetConnMaxLifetime(connLifetime)
 
-	idleTime, err := time.ParseDuration(config.Pool.MaxConnectionIdleTime)
-	if err != nil {
-		logger.Warnf("Error en configuración de MaxConnectionIdleTime '%s': %v. Usando valor por defecto (15m).",
-			config.Pool.MaxConnectionIdleTime, err)
-		idleTime = 15 * time.Minute
+	// Una base de datos en memoria desaparece al cerrarse su última conexión:
+	// sus conexiones no caducan y siempre queda al menos una inactiva
+	if driver == DriverSQLite && isSQLiteMemory(config) {
+		pool.configureConnections(db)
+		if config.Pool.MaxIdleConnections < 1 {
+			db.SetMaxIdleConns(1)
+		}
+	} else {
+		pool.parseDurations()
+		pool.configureConnections(db)
 	}
-	db.SetConnMaxIdleTime(idleTime)
 
 	// Verificar conexión
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("error verificando conexión a MySQL: %w", err)
+		return nil, fmt.Errorf("error verificando conexión a %s: %w", driver, err)
 	}
 
-	pool := &DBConnectionPool{
-		db:     db,
-		config: config,
-		log:    logger,
+	if err := pool.openReplicas(); err != nil {
+		pool.closeBackends()
+		return nil, err
 	}
+	pool.startReplicaMonitor()
 
-	logger.Info("Pool de conexiones a MySQL inicializado correctamente")
+	logger.Infof("Pool de conexiones a %s inicializado correctamente (%d réplicas)", driver, len(pool.replicas))
 	return pool, nil
 }
 
+// parseDurations interpreta los tiempos máximos de conexión y de la supervisión de réplicas
+func (p *DBConnectionPool) parseDurations() {
+	p.connLifetime = p.parseDuration("MaxConnectionLifetime", p.config.Pool.MaxConnectionLifetime, time.Hour)
+	p.connIdleTime = p.parseDuration("MaxConnectionIdleTime", p.config.Pool.MaxConnectionIdleTime, 15*time.Minute)
+}
+
+// parseDuration interpreta un tiempo de la configuración, usando el valor por defecto si es inválido
+func (p *DBConnectionPool) parseDuration(name, value string, defaultValue time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		p.log.Warnf("Error en configuración de %s '%s': %v. Usando valor por defecto (%s).",
+			name, value, err, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// configureConnections aplica los límites de la configuración a una base de datos del pool
+func (p *DBConnectionPool) configureConnections(db *sql.DB) {
+	db.SetMaxOpenConns(p.config.Pool.MaxOpenConnections)
+	db.SetMaxIdleConns(p.config.Pool.MaxIdleConnections)
+	db.SetConnMaxLifetime(p.connLifetime)
+	db.SetConnMaxIdleTime(p.connIdleTime)
+}
+
+// mysqlDSN construye el DSN de MySQL, registrando la configuración TLS si está habilitada
+func mysqlDSN(config *DBConfig) (string, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		config.Database.Username,
+		config.Database.Password,
+		config.Database.Host,
+		config.Database.Port,
+		config.Database.Database,
+	)
+
+	if config.Database.SSLMode == "REQUIRED" {
+		tlsConfig := TLSConfig{
+			CertPath:           config.Database.CertPath,
+			InsecureSkipVerify: false,
+		}
+		if err := registerTLS(tlsConfig); err != nil {
+			return "", fmt.Errorf("error configurando TLS: %w", err)
+		}
+		dsn += "&tls=custom"
+	}
+
+	return dsn, nil
+}
+
+// sqliteMemoryCount numera las bases de datos en memoria para que cada pool tenga la suya
+var sqliteMemoryCount int64
+
+// sqliteDSN construye el DSN de SQLite con claves foráneas activas, que SQLite
+// desactiva por defecto, y espera ante bloqueos en lugar de fallar de inmediato
+func sqliteDSN(config *DBConfig) string {
+	const params = "_foreign_keys=on&_busy_timeout=5000"
+	if isSQLiteMemory(config) {
+		// La caché compartida hace que todas las conexiones del pool vean la misma base de datos
+		name := fmt.Sprintf("memdb%d", atomic.AddInt64(&sqliteMemoryCount, 1))
+		return fmt.Sprintf("file:%s?mode=memory&cache=shared&%s", name, params)
+	}
+	return fmt.Sprintf("file:%s?%s", config.Database.Path, params)
+}
+
+// isSQLiteMemory indica si la configuración pide una base de datos SQLite en memoria
+func isSQLiteMemory(config *DBConfig) bool {
+	return config.Database.Path == "" || config.Database.Path == SQLiteMemory
+}
+
 // registerTLS registra una configuración TLS personalizada para MySQL
 func registerTLS(config TLSConfig) error {
 	rootCertPool := x509.NewCertPool()
@@ -188,6 +301,11 @@ func (p *DBConnectionPool) DB() *sql.DB {
 	return p.db
 }
 
+// Driver devuelve el driver de base de datos del pool (DriverMySQL o DriverSQLite)
+func (p *DBConnectionPool) Driver() string {
+	return p.driver
+}
+
 // Stats devuelve las estadísticas nativas del pool de conexiones
 func (p *DBConnectionPool) Stats() sql.DBStats {
 	return p.db.Stats()
@@ -195,8 +313,12 @@ func (p *DBConnectionPool) Stats() sql.DBStats {
 
 // Close cierra el pool de conexiones ordenadamente
 func (p *DBConnectionPool) Close() error {
-	p.log.Info("Cerrando pool de conexiones a MySQL")
-	return p.db.Close()
+	p.log.Infof("Cerrando pool de conexiones a %s", p.driver)
+	if p.stopMonitor != nil {
+		p.stopMonitor()
+		<-p.monitorDone
+	}
+	return p.closeBackends()
 }
 
 // ExecContext ejecuta una consulta SQL que no devuelve filas, con seguimiento de métricas
@@ -208,6 +330,7 @@ func (p *DBConnectionPool) ExecContext(ctx context.Context, query string, args .
 	start := time.Now()
 	result, err := p.db.ExecContext(ctx, query, args...)
 	elapsed := time.Since(start)
+	p.primary.record(elapsed, err)
 
 	// Actualizar métricas de latencia
 	atomic.AddInt64(&p.latencySum, int64(elapsed))
@@ -229,15 +352,18 @@ func (p *DBConnectionPool) ExecContext(ctx context.Context, query string, args .
 	return result, err
 }
 
-// QueryContext ejecuta una consulta SQL que devuelve filas, con seguimiento de métricas
+// QueryContext ejecuta una consulta SQL que devuelve filas, con seguimiento de métricas.
+// Se ejecuta en una réplica sana si el contexto admite lecturas de réplicas.
 func (p *DBConnectionPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	// Incrementar contador de consultas
 	atomic.AddInt64(&p.queryCount, 1)
 
 	// Medir tiempo de ejecución
+	backend := p.readBackend(ctx)
 	start := time.Now()
-	rows, err := p.db.QueryContext(ctx, query, args...)
+	rows, err := backend.db.QueryContext(ctx, query, args...)
 	elapsed := time.Since(start)
+	backend.record(elapsed, err)
 
 	// Actualizar métricas de latencia
 	atomic.AddInt64(&p.latencySum, int64(elapsed))
@@ -252,6 +378,7 @@ func (p *DBConnectionPool) QueryContext(ctx context.Context, query string, args
 		p.log.WithFields(logrus.Fields{
 			"query":   query,
 			"args":    args,
+			"backend": backend.name,
 			"elapsed": elapsed,
 		}).Error("Error ejecutando consulta SQL:", err)
 	}
@@ -259,15 +386,18 @@ func (p *DBConnectionPool) QueryContext(ctx context.Context, query string, args
 	return rows, err
 }
 
-// QueryRowContext ejecuta una consulta SQL que devuelve una sola fila
+// QueryRowContext ejecuta una consulta SQL que devuelve una sola fila.
+// Se ejecuta en una réplica sana si el contexto admite lecturas de réplicas.
 func (p *DBConnectionPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	// Incrementar contador de consultas
 	atomic.AddInt64(&p.queryCount, 1)
 
 	// Medir tiempo de ejecución (aunque no podemos saber si hay error hasta Scan())
+	backend := p.readBackend(ctx)
 	start := time.Now()
-	row := p.db.QueryRowContext(ctx, query, args...)
+	row := backend.db.QueryRowContext(ctx, query, args...)
 	elapsed := time.Since(start)
+	backend.record(elapsed, row.Err())
 
 	// Actualizar métricas de latencia
 	atomic.AddInt64(&p.latencySum, int64(elapsed))
@@ -339,8 +469,22 @@ func (p *DBConnectionPool) GetMetrics() map[string]interface{} {
 		avgLatency = time.Duration(latencySum / latencyCount)
 	}
 
+	// Métricas por backend: la principal primero y después las réplicas
+	backends := []map[string]interface{}{p.primary.metrics()}
+	healthyReplicas := 0
+	for _, replica := range p.replicas {
+		backends = append(backends, replica.metrics())
+		if replica.isHealthy() {
+			healthyReplicas++
+		}
+	}
+
 	// Crear mapa de métricas
 	metrics := map[string]interface{}{
+		"backends":           backends,
+		"replicas":           len(p.replicas),
+		"healthy_replicas":   healthyReplicas,
+		"driver":             p.driver,
 		"max_open_conns":     stats.MaxOpenConnections,
 		"open_conns":         stats.OpenConnections,
 		"in_use":             stats.InUse,
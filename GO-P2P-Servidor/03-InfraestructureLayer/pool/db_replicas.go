@@ -0,0 +1,303 @@
+package pool
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Valores por defecto de la supervisión de réplicas
+const (
+	defaultMaxReplicaLag        = 10 * time.Second
+	defaultReplicaCheckInterval = 5 * time.Second
+)
+
+// Roles de los backends del pool
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
+)
+
+// ReplicaConfig describe una réplica de lectura. Los campos vacíos toman el valor
+// de la base de datos principal.
+type ReplicaConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// consistentReadKey es la clave de contexto que indica si las lecturas deben ir a la
+// base de datos principal; sin ella lo hacen
+type consistentReadKey struct{}
+
+// WithReplicaRead marca el contexto para que sus lecturas puedan ir a una réplica. Solo
+// debe usarse en lecturas que toleran datos con el retraso de la replicación y cuyo
+// resultado no se vuelve a escribir.
+func WithReplicaRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentReadKey{}, false)
+}
+
+// WithConsistentRead marca el contexto para que las lecturas vayan a la base de datos
+// principal aunque un contexto padre admitiera réplicas
+func WithConsistentRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentReadKey{}, true)
+}
+
+// IsConsistentRead indica si las lecturas del contexto van a la base de datos principal,
+// es decir, si no se marcó con WithReplicaRead o se marcó después con WithConsistentRead
+func IsConsistentRead(ctx context.Context) bool {
+	consistent, ok := ctx.Value(consistentReadKey{}).(bool)
+	return !ok || consistent
+}
+
+// dbBackend es una base de datos del pool, principal o réplica, con sus propias métricas
+type dbBackend struct {
+	name         string
+	role         string
+	db           *sql.DB
+	healthy      int32 // 1 si la réplica recibe lecturas; la principal siempre
+	lagNanos     int64
+	queryCount   int64
+	errorCount   int64
+	latencySum   int64
+	latencyCount int64
+}
+
+// record acumula una consulta en las métricas del backend
+func (b *dbBackend) record(elapsed time.Duration, err error) {
+	atomic.AddInt64(&b.queryCount, 1)
+	atomic.AddInt64(&b.latencySum, int64(elapsed))
+	atomic.AddInt64(&b.latencyCount, 1)
+	if err != nil {
+		atomic.AddInt64(&b.errorCount, 1)
+	}
+}
+
+// isHealthy indica si el backend puede recibir lecturas
+func (b *dbBackend) isHealthy() bool {
+	return atomic.LoadInt32(&b.healthy) == 1
+}
+
+// metrics devuelve las métricas del backend para GetMetrics
+func (b *dbBackend) metrics() map[string]interface{} {
+	stats := b.db.Stats()
+
+	var avgLatency time.Duration
+	if count := atomic.LoadInt64(&b.latencyCount); count > 0 {
+		avgLatency = time.Duration(atomic.LoadInt64(&b.latencySum) / count)
+	}
+
+	metrics := map[string]interface{}{
+		"name":           b.name,
+		"role":           b.role,
+		"healthy":        b.isHealthy(),
+		"open_conns":     stats.OpenConnections,
+		"in_use":         stats.InUse,
+		"idle":           stats.Idle,
+		"wait_count":     stats.WaitCount,
+		"query_count":    atomic.LoadInt64(&b.queryCount),
+		"error_count":    atomic.LoadInt64(&b.errorCount),
+		"avg_latency_ns": avgLatency,
+	}
+	if b.role == RoleReplica {
+		metrics["lag"] = time.Duration(atomic.LoadInt64(&b.lagNanos))
+	}
+	return metrics
+}
+
+// openReplicas abre las réplicas configuradas con los mismos límites que la principal.
+// Una réplica inaccesible no impide arrancar: queda fuera hasta que la supervisión la admita.
+func (p *DBConnectionPool) openReplicas() error {
+	if len(p.config.Replicas) == 0 {
+		return nil
+	}
+	if p.driver != DriverMySQL {
+		return fmt.Errorf("las réplicas de lectura solo se admiten con MySQL")
+	}
+
+	for i, replica := range p.config.Replicas {
+		replicaConfig := *p.config
+		if replica.Host != "" {
+			replicaConfig.Database.Host = replica.Host
+		}
+		if replica.Port != 0 {
+			replicaConfig.Database.Port = replica.Port
+		}
+		if replica.Username != "" {
+			replicaConfig.Database.Username = replica.Username
+			replicaConfig.Database.Password = replica.Password
+		}
+
+		dsn, err := mysqlDSN(&replicaConfig)
+		if err != nil {
+			return err
+		}
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return fmt.Errorf("error abriendo réplica %d: %w", i, err)
+		}
+		p.configureConnections(db)
+
+		p.replicas = append(p.replicas, &dbBackend{
+			name: fmt.Sprintf("replica-%d %s:%d", i, replicaConfig.Database.Host, replicaConfig.Database.Port),
+			role: RoleReplica,
+			db:   db,
+		})
+	}
+	return nil
+}
+
+// readBackend elige dónde ejecutar una lectura: la siguiente réplica sana si el contexto
+// admite réplicas, y si no, o no hay ninguna sana, la principal
+func (p *DBConnectionPool) readBackend(ctx context.Context) *dbBackend {
+	if len(p.replicas) == 0 || IsConsistentRead(ctx) {
+		return p.primary
+	}
+	start := atomic.AddUint64(&p.nextReplica, 1)
+	for i := 0; i < len(p.replicas); i++ {
+		replica := p.replicas[(start+uint64(i))%uint64(len(p.replicas))]
+		if replica.isHealthy() {
+			return replica
+		}
+	}
+	return p.primary
+}
+
+// startReplicaMonitor comprueba las réplicas una vez antes de enviarles lecturas y
+// después periódicamente, retirando las que superan el retraso máximo y
+// readmitiendo las que se recuperan
+func (p *DBConnectionPool) startReplicaMonitor() {
+	if len(p.replicas) == 0 {
+		return
+	}
+
+	p.maxReplicaLag = defaultMaxReplicaLag
+	if p.config.Replication.MaxLag != "" {
+		p.maxReplicaLag = p.parseDuration("Replication.MaxLag", p.config.Replication.MaxLag, defaultMaxReplicaLag)
+	}
+	interval := defaultReplicaCheckInterval
+	if p.config.Replication.CheckInterval != "" {
+		interval = p.parseDuration("Replication.CheckInterval", p.config.Replication.CheckInterval, defaultReplicaCheckInterval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopMonitor = cancel
+	p.monitorDone = make(chan struct{})
+
+	p.checkReplicas(ctx, interval)
+	for _, replica := range p.replicas {
+		if !replica.isHealthy() {
+			p.log.Warnf("Réplica %s fuera de las lecturas hasta que se recupere", replica.name)
+		}
+	}
+
+	go func() {
+		defer close(p.monitorDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.checkReplicas(ctx, interval)
+			}
+		}
+	}()
+}
+
+// checkReplicas mide el retraso de cada réplica y actualiza si recibe lecturas
+func (p *DBConnectionPool) checkReplicas(ctx context.Context, timeout time.Duration) {
+	for _, replica := range p.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		lag, err := p.lagProbe(checkCtx, replica.db)
+		cancel()
+
+		healthy := err == nil && lag <= p.maxReplicaLag
+		atomic.StoreInt64(&replica.lagNanos, int64(lag))
+		was := atomic.SwapInt32(&replica.healthy, boolToInt32(healthy)) == 1
+
+		switch {
+		case was && !healthy && err != nil:
+			p.log.WithError(err).Warnf("Réplica %s retirada de las lecturas", replica.name)
+		case was && !healthy:
+			p.log.Warnf("Réplica %s retirada de las lecturas: retraso %s supera %s", replica.name, lag, p.maxReplicaLag)
+		case !was && healthy:
+			p.log.Infof("Réplica %s admitida para lecturas (retraso %s)", replica.name, lag)
+		}
+	}
+}
+
+// replicaLag consulta el retraso de replicación de una réplica MySQL. Usa SHOW REPLICA
+// STATUS (MySQL 8.0.22+) y, si no existe, SHOW SLAVE STATUS.
+func replicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, err
+		}
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return 0, fmt.Errorf("error consultando estado de replicación: %w", err)
+		}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("el servidor no está configurado como réplica")
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		// NULL indica que la replicación está detenida
+		if values[i] == nil {
+			return 0, fmt.Errorf("la replicación está detenida")
+		}
+		seconds, err := strconv.ParseInt(string(values[i]), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("retraso de replicación inválido %q: %w", values[i], err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("el estado de replicación no informa del retraso")
+}
+
+// closeBackends cierra la base de datos principal y las réplicas
+func (p *DBConnectionPool) closeBackends() error {
+	err := p.db.Close()
+	for _, replica := range p.replicas {
+		if closeErr := replica.db.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,181 @@
+package pool
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lagFalso sustituye la consulta de retraso de MySQL por valores fijados en la prueba
+type lagFalso struct {
+	mu      sync.Mutex
+	retraso map[*sql.DB]time.Duration
+	errores map[*sql.DB]error
+}
+
+func (l *lagFalso) fijar(db *sql.DB, retraso time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.retraso[db] = retraso
+	l.errores[db] = err
+}
+
+func (l *lagFalso) medir(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.retraso[db], l.errores[db]
+}
+
+// nuevaBaseOrigen abre una base SQLite en memoria cuya tabla origen contiene su nombre,
+// para saber qué backend respondió a una lectura
+func nuevaBaseOrigen(t *testing.T, nombre string) *DBConnectionPool {
+	t.Helper()
+	p, err := NewSQLiteMemoryPool()
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = p.ExecContext(ctx, "CREATE TABLE origen (nombre TEXT NOT NULL)")
+	require.NoError(t, err)
+	_, err = p.ExecContext(ctx, "INSERT INTO origen (nombre) VALUES (?)", nombre)
+	require.NoError(t, err)
+	return p
+}
+
+// nuevoPoolConReplicas crea un pool SQLite con dos réplicas SQLite cuyo retraso se
+// controla con el lagFalso devuelto. Las réplicas empiezan sanas.
+func nuevoPoolConReplicas(t *testing.T) (*DBConnectionPool, *lagFalso) {
+	t.Helper()
+	p := nuevaBaseOrigen(t, "primary")
+	lag := &lagFalso{retraso: make(map[*sql.DB]time.Duration), errores: make(map[*sql.DB]error)}
+	for _, nombre := range []string{"replica-0", "replica-1"} {
+		r := nuevaBaseOrigen(t, nombre)
+		p.replicas = append(p.replicas, &dbBackend{name: nombre, role: RoleReplica, db: r.db})
+		lag.fijar(r.db, time.Second, nil)
+	}
+	p.lagProbe = lag.medir
+	p.maxReplicaLag = 5 * time.Second
+	p.checkReplicas(context.Background(), time.Second)
+	t.Cleanup(func() { p.Close() })
+	return p, lag
+}
+
+// leerOrigen devuelve el nombre del backend que atendió la lectura
+func leerOrigen(t *testing.T, ctx context.Context, p *DBConnectionPool) string {
+	t.Helper()
+	var nombre string
+	require.NoError(t, p.QueryRowContext(ctx, "SELECT nombre FROM origen").Scan(&nombre))
+	return nombre
+}
+
+func TestIsConsistentRead(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, IsConsistentRead(ctx), "sin marca se lee de la principal")
+
+	replica := WithReplicaRead(ctx)
+	assert.False(t, IsConsistentRead(replica))
+	assert.True(t, IsConsistentRead(WithConsistentRead(replica)), "WithConsistentRead prevalece sobre un padre con réplicas")
+	assert.False(t, IsConsistentRead(WithReplicaRead(WithConsistentRead(ctx))))
+}
+
+func TestReplicas_LecturasPorDefectoEnLaPrincipal(t *testing.T) {
+	p, _ := nuevoPoolConReplicas(t)
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, "primary", leerOrigen(t, context.Background(), p))
+	}
+	assert.Equal(t, "primary", leerOrigen(t, WithConsistentRead(WithReplicaRead(context.Background())), p))
+}
+
+func TestReplicas_TurnoRotatorio(t *testing.T) {
+	p, _ := nuevoPoolConReplicas(t)
+	ctx := WithReplicaRead(context.Background())
+
+	vistas := map[string]int{}
+	anterior := ""
+	for i := 0; i < 6; i++ {
+		nombre := leerOrigen(t, ctx, p)
+		assert.NotEqual(t, anterior, nombre, "dos lecturas seguidas no deben ir a la misma réplica")
+		anterior = nombre
+		vistas[nombre]++
+	}
+	assert.Equal(t, map[string]int{"replica-0": 3, "replica-1": 3}, vistas)
+}
+
+func TestReplicas_RetiroYReadmisionPorRetraso(t *testing.T) {
+	p, lag := nuevoPoolConReplicas(t)
+	ctx := WithReplicaRead(context.Background())
+
+	// Un retraso mayor que el máximo retira la réplica de las lecturas
+	lag.fijar(p.replicas[1].db, time.Minute, nil)
+	p.checkReplicas(context.Background(), time.Second)
+	assert.False(t, p.replicas[1].isHealthy())
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, "replica-0", leerOrigen(t, ctx, p))
+	}
+
+	// Al recuperarse vuelve al turno
+	lag.fijar(p.replicas[1].db, 2*time.Second, nil)
+	p.checkReplicas(context.Background(), time.Second)
+	assert.True(t, p.replicas[1].isHealthy())
+	vistas := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		vistas[leerOrigen(t, ctx, p)] = true
+	}
+	assert.Equal(t, map[string]bool{"replica-0": true, "replica-1": true}, vistas)
+}
+
+func TestReplicas_SinReplicasSanasLeeDeLaPrincipal(t *testing.T) {
+	p, lag := nuevoPoolConReplicas(t)
+	ctx := WithReplicaRead(context.Background())
+
+	// Una réplica que no responde y otra con la replicación detenida
+	lag.fijar(p.replicas[0].db, 0, errors.New("sin conexión"))
+	lag.fijar(p.replicas[1].db, 0, errors.New("la replicación está detenida"))
+	p.checkReplicas(context.Background(), time.Second)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "primary", leerOrigen(t, ctx, p))
+	}
+}
+
+func TestReplicas_MetricasPorBackend(t *testing.T) {
+	p, lag := nuevoPoolConReplicas(t)
+	ctx := WithReplicaRead(context.Background())
+
+	lag.fijar(p.replicas[1].db, time.Minute, nil)
+	p.checkReplicas(context.Background(), time.Second)
+	for i := 0; i < 3; i++ {
+		leerOrigen(t, ctx, p)
+	}
+	leerOrigen(t, context.Background(), p)
+
+	metrics := p.GetMetrics()
+	assert.Equal(t, 2, metrics["replicas"])
+	assert.Equal(t, 1, metrics["healthy_replicas"])
+
+	backends, ok := metrics["backends"].([]map[string]interface{})
+	require.True(t, ok)
+	require.Len(t, backends, 3)
+
+	principal, r0, r1 := backends[0], backends[1], backends[2]
+	assert.Equal(t, RolePrimary, principal["role"])
+	assert.Equal(t, true, principal["healthy"])
+	assert.NotContains(t, principal, "lag")
+	// La principal atendió la creación de la tabla, la inserción y la lectura sin marca
+	assert.Equal(t, int64(3), principal["query_count"])
+
+	assert.Equal(t, "replica-0", r0["name"])
+	assert.Equal(t, RoleReplica, r0["role"])
+	assert.Equal(t, true, r0["healthy"])
+	assert.Equal(t, time.Second, r0["lag"])
+	assert.Equal(t, int64(3), r0["query_count"])
+
+	assert.Equal(t, "replica-1", r1["name"])
+	assert.Equal(t, false, r1["healthy"])
+	assert.Equal(t, time.Minute, r1["lag"])
+	assert.Equal(t, int64(0), r1["query_count"])
+}
@@ -196,7 +196,8 @@ func (p *PeerConn) SendFrame(frameType uint16, payload []byte) error {
 	return nil
 }
 
-// ReceiveFrame recibe un frame del peer
+// ReceiveFrame recibe un frame del peer. Un error de timeout significa que no empezó a
+// llegar ningún frame y no se consumió nada del flujo, así que se puede volver a llamar.
 func (p *PeerConn) ReceiveFrame() (*PeerFrame, error) {
 	if atomic.LoadInt32(&p.closed) != 0 {
 		return nil, fmt.Errorf("conexión cerrada")
@@ -217,7 +218,25 @@ func (p *PeerConn) ReceiveFrame() (*PeerFrame, error) {
 		return nil, err
 	}
 	
-	if _, err := io.ReadFull(p.conn, headerBuf); err != nil {
+	// Solo se tolera un timeout esperando el primer byte. Con el frame empezado, un
+	// timeout perdería los bytes ya leídos y desalinearía el flujo, así que el resto del
+	// frame tiene su propio plazo y, si vence, es un error de la conexión.
+	if _, err := io.ReadFull(p.conn, headerBuf[:1]); err != nil {
+		p.metrics.mu.Lock()
+		p.metrics.Errors++
+		p.metrics.LastError = err
+		p.metrics.mu.Unlock()
+		return nil, err
+	}
+	if err := p.conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		p.metrics.mu.Lock()
+		p.metrics.Errors++
+		p.metrics.LastError = err
+		p.metrics.mu.Unlock()
+		return nil, err
+	}
+	if _, err := io.ReadFull(p.conn, headerBuf[1:]); err != nil {
+		err = frameIncompleto(err)
 		p.metrics.mu.Lock()
 		p.metrics.Errors++
 		p.metrics.LastError = err
@@ -243,6 +262,7 @@ func (p *PeerConn) ReceiveFrame() (*PeerFrame, error) {
 	payload := make([]byte, payloadLen)
 	if payloadLen > 0 {
 		if _, err := io.ReadFull(p.conn, payload); err != nil {
+			err = frameIncompleto(err)
 			p.metrics.mu.Lock()
 			p.metrics.Errors++
 			p.metrics.LastError = err
@@ -265,6 +285,15 @@ func (p *PeerConn) ReceiveFrame() (*PeerFrame, error) {
 	}, nil
 }
 
+// frameIncompleto convierte un timeout a mitad de frame en un error que no es net.Error,
+// para que quien lee no lo tome por inactividad y reintente sobre un flujo desalineado
+func frameIncompleto(err error) error {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return fmt.Errorf("frame incompleto: %v", err)
+	}
+	return err
+}
+
 // Close cierra la conexión con el peer
 func (p *PeerConn) Close() error {
 	if !atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
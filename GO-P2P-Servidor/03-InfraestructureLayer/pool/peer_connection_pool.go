@@ -8,6 +8,7 @@ import (
 	"math"
 	"math/rand"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -62,6 +63,8 @@ type PeerConnectionPool struct {
 	jitterFactor   float64
 	keepaliveInterval time.Duration
 	peerStateNotifier func(uuid.UUID, PeerState)
+	frameHandlers  map[uint16]func(uuid.UUID, []byte)
+	handlersMu     sync.RWMutex
 }
 
 // LoadPeerPoolConfig carga la configuración desde un archivo YAML
@@ -127,6 +130,7 @@ func NewPeerConnectionPool(configPath string) (*PeerConnectionPool, error) {
 		maxAttempts:    config.PeerPool.Reconnect.MaxAttempts,
 		jitterFactor:   config.PeerPool.Reconnect.JitterFactor,
 		keepaliveInterval: keepaliveInterval,
+		frameHandlers:  make(map[uint16]func(uuid.UUID, []byte)),
 	}
 
 	// Iniciar rutina de keepalive para todas las conexiones
@@ -142,6 +146,24 @@ func (p *PeerConnectionPool) SetPeerStateNotifier(notifier func(uuid.UUID, PeerS
 	p.peerStateNotifier = notifier
 }
 
+// SetFrameHandler registra la función que procesa los frames de un tipo recibidos de cualquier peer.
+// Los servicios de dominio la usan para sus protocolos (replicación, enrutamiento, presencia...)
+// sin conocer los detalles de la conexión.
+func (p *PeerConnectionPool) SetFrameHandler(frameType uint16, handler func(peerID uuid.UUID, payload []byte)) {
+	p.handlersMu.Lock()
+	defer p.handlersMu.Unlock()
+	p.frameHandlers[frameType] = handler
+}
+
+// SendTo envía un frame a un peer conectado
+func (p *PeerConnectionPool) SendTo(peerID uuid.UUID, frameType uint16, payload []byte) error {
+	conn, ok := p.Get(peerID)
+	if !ok {
+		return fmt.Errorf("peer %s no conectado", peerID)
+	}
+	return conn.SendFrame(frameType, payload)
+}
+
 // DialAndRegister conecta a un peer y lo registra en el pool
 func (p *PeerConnectionPool) DialAndRegister(peer PeerInfo) (*PeerConn, error) {
 	p.mu.Lock()
@@ -235,8 +257,9 @@ func (p *PeerConnectionPool) DialAndRegister(peer PeerInfo) (*PeerConn, error) {
 		"node_name": peer.NodeName,
 	}).Info("Peer conectado exitosamente")
 
-	// Iniciar monitoreo de esta conexión
+	// Iniciar monitoreo y lectura de frames de esta conexión
 	go p.monitorConnection(peer.ID)
+	go p.receiveLoop(peerConn)
 
 	return peerConn, nil
 }
@@ -379,6 +402,59 @@ func (p *PeerConnectionPool) monitorConnection(peerID uuid.UUID) {
 	}
 }
 
+// receiveLoop lee frames de una conexión y los despacha a los handlers registrados
+func (p *PeerConnectionPool) receiveLoop(conn *PeerConn) {
+	for {
+		select {
+		case <-conn.ctx.Done():
+			return
+		default:
+		}
+
+		frame, err := conn.ReceiveFrame()
+		if err != nil {
+			// Un timeout solo indica que no empezó ningún frame (ReceiveFrame no consume
+			// nada en ese caso); el keepalive detecta caídas
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			if conn.ctx.Err() != nil {
+				return
+			}
+			p.log.WithFields(logrus.Fields{
+				"peer_id": conn.ID,
+				"error":   err.Error(),
+			}).Error("Error leyendo frame de peer")
+
+			// Igual que en keepalive, cerrar para forzar una conexión nueva
+			go p.Close(conn.ID)
+			return
+		}
+
+		switch frame.Type {
+		case FrameTypeKeepAlive:
+			continue
+		case FrameTypeClose:
+			go p.Close(conn.ID)
+			return
+		}
+
+		p.handlersMu.RLock()
+		handler, exists := p.frameHandlers[frame.Type]
+		p.handlersMu.RUnlock()
+
+		if !exists {
+			p.log.WithFields(logrus.Fields{
+				"peer_id":    conn.ID,
+				"frame_type": frame.Type,
+			}).Debug("Frame sin handler registrado, descartado")
+			continue
+		}
+
+		handler(conn.ID, frame.Payload)
+	}
+}
+
 // calculateBackoff calcula el tiempo de espera para reconexión con backoff exponencial y jitter
 func (p *PeerConnectionPool) calculateBackoff(attempt int) time.Duration {
 	// Fórmula de backoff exponencial: baseDelay * 2^attempt
@@ -446,6 +522,15 @@ func (p *PeerConnectionPool) GetAllPeerIDs() []uuid.UUID {
 	return result
 }
 
+// PeerAddress devuelve la dirección host:puerto de un peer conectado
+func (p *PeerConnectionPool) PeerAddress(peerID uuid.UUID) (string, bool) {
+	conn, ok := p.Get(peerID)
+	if !ok {
+		return "", false
+	}
+	return net.JoinHostPort(conn.PeerInfo.Address, strconv.Itoa(conn.PeerInfo.Port)), true
+}
+
 // GetMetrics devuelve métricas del pool y de cada conexión
 func (p *PeerConnectionPool) GetMetrics() map[string]interface{} {
 	p.mu.RLock()
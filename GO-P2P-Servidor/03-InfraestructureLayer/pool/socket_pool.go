@@ -140,6 +140,14 @@ func (p *SocketPool) Get(id uuid.UUID) (net.Conn, bool) {
 	return client.Conn, true
 }
 
+// Connected indica si hay una conexión registrada para el ID, sin contarlo como actividad
+func (p *SocketPool) Connected(id uuid.UUID) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, exists := p.connections[id]
+	return exists
+}
+
 // Release libera una conexión del pool y la cierra
 func (p *SocketPool) Release(id uuid.UUID) {
 	p.mu.Lock()
@@ -148,6 +156,20 @@ func (p *SocketPool) Release(id uuid.UUID) {
 	p.closeConnectionLocked(id)
 }
 
+// Unregister libera la conexión del ID solo si sigue siendo conn: un Register posterior
+// con el mismo ID la habrá reemplazado y la nueva no se toca. Indica si se liberó.
+func (p *SocketPool) Unregister(id uuid.UUID, conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client, exists := p.connections[id]
+	if !exists || client.Conn != conn {
+		return false
+	}
+	p.closeConnectionLocked(id)
+	return true
+}
+
 // closeConnectionLocked cierra y elimina una conexión (debe ser llamado con el mutex adquirido)
 func (p *SocketPool) closeConnectionLocked(id uuid.UUID) {
 	client, exists := p.connections[id]
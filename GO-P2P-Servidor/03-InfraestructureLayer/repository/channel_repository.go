@@ -7,52 +7,66 @@ import (
 	"dao"
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // ChannelRepository implementa la interfaz IChannelRepository del dominio
 // utilizando los DAOs correspondientes para las operaciones de base de datos
 type ChannelRepository struct {
+	conexion
 	canalDAO           *dao.CanalDAO
 	invitacionCanalDAO *dao.InvitacionCanalDAO
 	canalMiembroDAO    *dao.CanalMiembroDAO
+	fijadoDAO          *dao.FijadoCanalDAO
 }
 
 // NewChannelRepository crea una nueva instancia de ChannelRepository
 func NewChannelRepository(
+	dbPool *pool.DBConnectionPool,
 	canalDAO *dao.CanalDAO,
 	invitacionCanalDAO *dao.InvitacionCanalDAO,
 	canalMiembroDAO *dao.CanalMiembroDAO,
 ) *ChannelRepository {
 	return &ChannelRepository{
+		conexion:           conexion{dbPool: dbPool},
 		canalDAO:           canalDAO,
 		invitacionCanalDAO: invitacionCanalDAO,
 		canalMiembroDAO:    canalMiembroDAO,
+		fijadoDAO:          dao.NuevoFijadoCanalDAO(),
 	}
 }
 
+// WithReplicaReads devuelve una copia del repositorio cuyas lecturas pueden ir a una
+// réplica. Solo para quien tolera el retraso de la replicación, como el dashboard.
+func (r *ChannelRepository) WithReplicaReads() *ChannelRepository {
+	copia := *r
+	copia.replica = true
+	return &copia
+}
+
 // Save persiste un canal en la base de datos
 func (r *ChannelRepository) Save(ctx context.Context, c *model.CanalServidor) error {
-	return r.canalDAO.Crear(c)
+	return r.canalDAO.Crear(ctx, r.querier(ctx), c)
 }
 
 // Update actualiza un canal existente en la base de datos
 func (r *ChannelRepository) Update(ctx context.Context, c *model.CanalServidor) error {
-	return r.canalDAO.Actualizar(c)
+	return r.canalDAO.Actualizar(ctx, r.querier(ctx), c)
 }
 
 // Delete elimina un canal de la base de datos
 func (r *ChannelRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.canalDAO.Eliminar(id)
+	return r.canalDAO.Eliminar(ctx, r.querier(ctx), id)
 }
 
 // FindByID busca un canal por su ID
 func (r *ChannelRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.CanalServidor, error) {
-	return r.canalDAO.BuscarPorID(id)
+	return r.canalDAO.BuscarPorID(ctx, r.querier(ctx), id)
 }
 
 // FindAll recupera todos los canales
 func (r *ChannelRepository) FindAll(ctx context.Context) ([]*model.CanalServidor, error) {
-	return r.canalDAO.BuscarTodos()
+	return r.canalDAO.BuscarTodos(ctx, r.querier(ctx))
 }
 
 // AddMember añade un miembro a un canal
@@ -61,17 +75,17 @@ func (r *ChannelRepository) AddMember(ctx context.Context, channelID, userID uui
 	if err != nil {
 		return err
 	}
-	return r.canalMiembroDAO.Guardar(miembro)
+	return r.canalMiembroDAO.Guardar(ctx, r.querier(ctx), miembro)
 }
 
 // RemoveMember elimina un miembro de un canal
 func (r *ChannelRepository) RemoveMember(ctx context.Context, channelID, userID uuid.UUID) error {
-	return r.canalMiembroDAO.Eliminar(channelID, userID)
+	return r.canalMiembroDAO.Eliminar(ctx, r.querier(ctx), channelID, userID)
 }
 
 // ListMembers lista los miembros de un canal
 func (r *ChannelRepository) ListMembers(ctx context.Context, channelID uuid.UUID) ([]uuid.UUID, error) {
-	miembros, err := r.canalMiembroDAO.BuscarPorCanalID(channelID)
+	miembros, err := r.canalMiembroDAO.BuscarPorCanalID(ctx, r.querier(ctx), channelID)
 	if err != nil {
 		return nil, err
 	}
@@ -84,17 +98,54 @@ func (r *ChannelRepository) ListMembers(ctx context.Context, channelID uuid.UUID
 	return userIDs, nil
 }
 
+// FindMembers lista los miembros de un canal con su rol y versión
+func (r *ChannelRepository) FindMembers(ctx context.Context, channelID uuid.UUID) ([]*model.CanalMiembro, error) {
+	return r.canalMiembroDAO.BuscarPorCanalID(ctx, r.querier(ctx), channelID)
+}
+
+// SaveMember inserta el miembro o actualiza su rol si ya pertenece al canal
+func (r *ChannelRepository) SaveMember(ctx context.Context, m *model.CanalMiembro) error {
+	existente, err := r.canalMiembroDAO.BuscarPorIDs(ctx, r.querier(ctx), m.CanalID(), m.UsuarioID())
+	if err != nil {
+		return err
+	}
+	if existente == nil {
+		return r.canalMiembroDAO.Guardar(ctx, r.querier(ctx), m)
+	}
+	return r.canalMiembroDAO.Actualizar(ctx, r.querier(ctx), m)
+}
+
 // SaveInvitation guarda una invitación a un canal
 func (r *ChannelRepository) SaveInvitation(ctx context.Context, inv *model.InvitacionCanal) error {
-	return r.invitacionCanalDAO.Guardar(inv)
+	return r.invitacionCanalDAO.Guardar(ctx, r.querier(ctx), inv)
 }
 
 // UpdateInvitation actualiza una invitación a un canal
 func (r *ChannelRepository) UpdateInvitation(ctx context.Context, inv *model.InvitacionCanal) error {
-	return r.invitacionCanalDAO.Actualizar(inv)
+	return r.invitacionCanalDAO.Actualizar(ctx, r.querier(ctx), inv)
 }
 
 // ListInvitations lista las invitaciones de un canal
 func (r *ChannelRepository) ListInvitations(ctx context.Context, channelID uuid.UUID) ([]*model.InvitacionCanal, error) {
-	return r.invitacionCanalDAO.BuscarPorCanalID(channelID)
+	return r.invitacionCanalDAO.BuscarPorCanalID(ctx, r.querier(ctx), channelID)
+}
+
+// SavePin guarda un elemento fijado, vigente o retirado
+func (r *ChannelRepository) SavePin(ctx context.Context, f *model.FijadoCanal) error {
+	return r.fijadoDAO.Guardar(ctx, r.querier(ctx), f)
+}
+
+// FindPin busca un elemento fijado por su ID, vigente o retirado
+func (r *ChannelRepository) FindPin(ctx context.Context, id uuid.UUID) (*model.FijadoCanal, error) {
+	return r.fijadoDAO.BuscarPorID(ctx, r.querier(ctx), id)
+}
+
+// ListPins lista los elementos fijados vigentes de un canal, los más recientes primero
+func (r *ChannelRepository) ListPins(ctx context.Context, channelID uuid.UUID) ([]*model.FijadoCanal, error) {
+	return r.fijadoDAO.BuscarVigentesPorCanalID(ctx, r.querier(ctx), channelID)
+}
+
+// CountPins cuenta los elementos fijados vigentes de cada canal
+func (r *ChannelRepository) CountPins(ctx context.Context, channelIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	return r.fijadoDAO.ContarVigentesPorCanales(ctx, r.querier(ctx), channelIDs)
 }
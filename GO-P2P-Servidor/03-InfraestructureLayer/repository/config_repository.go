@@ -4,17 +4,20 @@ import (
 	"context"
 	"dao"
 	"model"
+	"pool"
 )
 
 // ConfigRepository implementa la interfaz IConfigRepository del dominio
 // utilizando el ConfigMySQLDAO para las operaciones de base de datos
 type ConfigRepository struct {
+	conexion
 	configDAO *dao.ConfigMySQLDAO
 }
 
 // NewConfigRepository crea una nueva instancia de ConfigRepository
-func NewConfigRepository(configDAO *dao.ConfigMySQLDAO) *ConfigRepository {
+func NewConfigRepository(dbPool *pool.DBConnectionPool, configDAO *dao.ConfigMySQLDAO) *ConfigRepository {
 	return &ConfigRepository{
+		conexion:  conexion{dbPool: dbPool},
 		configDAO: configDAO,
 	}
 }
@@ -22,11 +25,11 @@ func NewConfigRepository(configDAO *dao.ConfigMySQLDAO) *ConfigRepository {
 // Get recupera la configuración del servidor de la base de datos
 func (r *ConfigRepository) Get(ctx context.Context) (*model.ConfiguracionServidor, error) {
 	// Utiliza el DAO para obtener la configuración
-	return r.configDAO.GetConfig()
+	return r.configDAO.GetConfig(ctx, r.querier(ctx))
 }
 
 // Update actualiza la configuración del servidor en la base de datos
 func (r *ConfigRepository) Update(ctx context.Context, cfg *model.ConfiguracionServidor) error {
 	// Utiliza el DAO para actualizar la configuración
-	return r.configDAO.Update(cfg)
+	return r.configDAO.Update(ctx, r.querier(ctx), cfg)
 }
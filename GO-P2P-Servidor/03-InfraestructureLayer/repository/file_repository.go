@@ -5,34 +5,37 @@ import (
 	"dao"
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // FileRepository implementa la interfaz IFileRepository
 type FileRepository struct {
+	conexion
 	dao *dao.ArchivoDAO
 }
 
 // NewFileRepository crea una nueva instancia del repositorio de archivos
-func NewFileRepository(dao *dao.ArchivoDAO) *FileRepository {
+func NewFileRepository(dbPool *pool.DBConnectionPool, dao *dao.ArchivoDAO) *FileRepository {
 	return &FileRepository{
-		dao: dao,
+		conexion: conexion{dbPool: dbPool},
+		dao:      dao,
 	}
 }
 
 // Save persiste los metadatos de un archivo
 func (r *FileRepository) Save(ctx context.Context, f *model.ArchivoMetadata) error {
 	// Utiliza el método Crear del DAO
-	return r.dao.Crear(f)
+	return r.dao.Crear(ctx, r.querier(ctx), f)
 }
 
 // Delete elimina un archivo por su ID
 func (r *FileRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.dao.Delete(id)
+	return r.dao.Delete(ctx, r.querier(ctx), id)
 }
 
 // FindByID busca un archivo por su ID
 func (r *FileRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ArchivoMetadata, error) {
-	return r.dao.GetByID(id)
+	return r.dao.GetByID(ctx, r.querier(ctx), id)
 }
 
 // FindByMessage busca archivos asociados a un mensaje específico
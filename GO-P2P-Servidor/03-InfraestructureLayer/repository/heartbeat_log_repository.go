@@ -2,45 +2,47 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"time"
 
 	"dao"
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // HeartbeatLogRepository implementa IHeartbeatLogRepository
 type HeartbeatLogRepository struct {
+	conexion
 	dao *dao.HeartbeatLogMySQLDAO
 }
 
 // NewHeartbeatLogRepository crea una nueva instancia del repositorio
-func NewHeartbeatLogRepository(db *sql.DB) *HeartbeatLogRepository {
+func NewHeartbeatLogRepository(dbPool *pool.DBConnectionPool) *HeartbeatLogRepository {
 	return &HeartbeatLogRepository{
-		dao: dao.NewHeartbeatLogMySQLDAO(db),
+		conexion: conexion{dbPool: dbPool},
+		dao:      dao.NewHeartbeatLogMySQLDAO(),
 	}
 }
 
 // Save guarda un registro de heartbeat
 func (r *HeartbeatLogRepository) Save(ctx context.Context, h *model.HeartbeatLog) error {
-	return r.dao.Save(h)
+	return r.dao.Save(ctx, r.querier(ctx), h)
 }
 
 // FindByPeer encuentra logs de heartbeat por ID del nodo
 func (r *HeartbeatLogRepository) FindByPeer(ctx context.Context, peerID uuid.UUID) ([]*model.HeartbeatLog, error) {
-	return r.dao.FindByNodoID(peerID)
+	return r.dao.FindByNodoID(ctx, r.querier(ctx), peerID)
 }
 
 // PruneOlderThan elimina logs más antiguos que una fecha determinada
 func (r *HeartbeatLogRepository) PruneOlderThan(ctx context.Context, cutoff time.Time) error {
-	logs, err := r.dao.FindByTimeRange(time.Time{}, cutoff)
+	logs, err := r.dao.FindByTimeRange(ctx, r.querier(ctx), time.Time{}, cutoff)
 	if err != nil {
 		return err
 	}
 
 	for _, log := range logs {
-		if err := r.dao.Delete(log.ID()); err != nil {
+		if err := r.dao.Delete(ctx, r.querier(ctx), log.ID()); err != nil {
 			return err
 		}
 	}
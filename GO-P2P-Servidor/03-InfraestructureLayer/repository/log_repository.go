@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"dao"
 	"database/sql"
 	"pool"
 	"time"
@@ -10,160 +11,117 @@ import (
 	"model"
 )
 
-// LogRepository implementa ILogRepository
+// LogRepository implementa ILogRepository; los listados paginados usan EntradaLogDAO
 type LogRepository struct {
-	dbPool *pool.DBConnectionPool
+	conexion
+	entradaLogDAO *dao.EntradaLogDAO
 }
 
 // NewLogRepository crea una nueva instancia del repositorio
 func NewLogRepository(dbPool *pool.DBConnectionPool) *LogRepository {
-	return &LogRepository{dbPool: dbPool}
+	return &LogRepository{conexion: conexion{dbPool: dbPool}, entradaLogDAO: dao.NuevoEntradaLogDAO()}
+}
+
+// WithReplicaReads devuelve una copia del repositorio cuyas lecturas pueden ir a una
+// réplica. Solo para quien tolera el retraso de la replicación, como el dashboard.
+func (r *LogRepository) WithReplicaReads() *LogRepository {
+	copia := *r
+	copia.replica = true
+	return &copia
 }
 
 // Save guarda un registro de log
 func (r *LogRepository) Save(ctx context.Context, entry *model.LogEntry) error {
-	query := `INSERT INTO logs (id, user_id, tipo_evento, descripcion, fecha)
+	query := `INSERT INTO log_entry (id, usuario_id, tipo_evento, detalle, timestamp)
               VALUES (?, ?, ?, ?, ?)`
 
-	_, err := r.dbPool.ExecContext(ctx, query,
+	_, err := r.querier(ctx).ExecContext(ctx, query,
 		entry.ID().String(),
-		entry.UserID().String(),
-		string(entry.EventType()),
-		entry.Description(),
-		entry.Date())
+		nullableUserID(entry.UsuarioID()),
+		string(entry.TipoEvento()),
+		entry.Detalle(),
+		entry.Timestamp())
 
 	return err
 }
 
 // Delete elimina un registro de log
 func (r *LogRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM logs WHERE id = ?`
-	_, err := r.dbPool.ExecContext(ctx, query, id.String())
+	query := `DELETE FROM log_entry WHERE id = ?`
+	_, err := r.querier(ctx).ExecContext(ctx, query, id.String())
 	return err
 }
 
 // FindByID busca un registro de log por ID
 func (r *LogRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.LogEntry, error) {
-	query := `SELECT id, user_id, tipo_evento, descripcion, fecha
-              FROM logs WHERE id = ?`
+	query := `SELECT id, usuario_id, tipo_evento, detalle, timestamp
+              FROM log_entry WHERE id = ?`
 
-	row := r.dbPool.QueryRowContext(ctx, query, id.String())
+	row := r.querier(ctx).QueryRowContext(ctx, query, id.String())
 
-	var idStr, userIDStr, tipoEventoStr, descripcion string
+	var idStr, tipoEventoStr, descripcion string
+	var userIDStr sql.NullString
 	var fecha time.Time
 
 	if err := row.Scan(&idStr, &userIDStr, &tipoEventoStr, &descripcion, &fecha); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	logID, err := uuid.Parse(idStr)
-	if err != nil {
-		return nil, err
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return nil, err
-	}
-
-	tipoEvento := model.EventoTipo(tipoEventoStr)
-
-	return model.NewLogEntry(logID, userID, tipoEvento, descripcion, fecha)
+	return buildLogEntry(idStr, userIDStr, tipoEventoStr, descripcion, fecha)
 }
 
-// ListAll lista todos los registros de log
-func (r *LogRepository) ListAll(ctx context.Context) ([]*model.LogEntry, error) {
-	query := `SELECT id, user_id, tipo_evento, descripcion, fecha FROM logs`
-	return r.executeScanQuery(ctx, query)
+// ListAll lista una página de todos los registros de log
+func (r *LogRepository) ListAll(ctx context.Context, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.entradaLogDAO.BuscarPagina(ctx, r.querier(ctx), dao.FiltroEntradaLog{}, page)
 }
 
-// ListByUser lista los registros de log de un usuario específico
-func (r *LogRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.LogEntry, error) {
-	query := `SELECT id, user_id, tipo_evento, descripcion, fecha 
-              FROM logs WHERE user_id = ?`
-
-	return r.executeScanQueryWithParam(ctx, query, userID.String())
+// ListByUser lista una página de los registros de log de un usuario específico
+func (r *LogRepository) ListByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.entradaLogDAO.BuscarPagina(ctx, r.querier(ctx), dao.FiltroEntradaLog{UsuarioID: userID}, page)
 }
 
-// ListByType lista los registros de log de un tipo específico
-func (r *LogRepository) ListByType(ctx context.Context, t model.EventoTipo) ([]*model.LogEntry, error) {
-	query := `SELECT id, user_id, tipo_evento, descripcion, fecha 
-              FROM logs WHERE tipo_evento = ?`
-
-	return r.executeScanQueryWithParam(ctx, query, string(t))
+// ListByType lista una página de los registros de log de un tipo específico
+func (r *LogRepository) ListByType(ctx context.Context, t model.EventoTipo, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.entradaLogDAO.BuscarPagina(ctx, r.querier(ctx), dao.FiltroEntradaLog{TiposEvento: []model.EventoTipo{t}}, page)
 }
 
-// ListByDateRange lista los registros de log en un rango de fechas
-func (r *LogRepository) ListByDateRange(ctx context.Context, from, to time.Time) ([]*model.LogEntry, error) {
-	query := `SELECT id, user_id, tipo_evento, descripcion, fecha 
-              FROM logs WHERE fecha BETWEEN ? AND ?`
-
-	rows, err := r.dbPool.QueryContext(ctx, query, from, to)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	return r.scanRows(rows)
+// ListByDateRange lista una página de los registros de log en el rango de fechas [from, to)
+func (r *LogRepository) ListByDateRange(ctx context.Context, from, to time.Time, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.entradaLogDAO.BuscarPagina(ctx, r.querier(ctx), dao.FiltroEntradaLog{Desde: from, Hasta: to}, page)
 }
 
-// Método auxiliar para ejecutar consultas y escanear resultados
-func (r *LogRepository) executeScanQuery(ctx context.Context, query string) ([]*model.LogEntry, error) {
-	rows, err := r.dbPool.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	return r.scanRows(rows)
+// ListFiltered lista una página de los registros de log que cumplen todos los criterios
+// dados; un usuario nil, ningún tipo o una fecha cero no filtran
+func (r *LogRepository) ListFiltered(ctx context.Context, userID uuid.UUID, types []model.EventoTipo, from, to time.Time, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	filtro := dao.FiltroEntradaLog{UsuarioID: userID, TiposEvento: types, Desde: from, Hasta: to}
+	return r.entradaLogDAO.BuscarPagina(ctx, r.querier(ctx), filtro, page)
 }
 
-// Método auxiliar para ejecutar consultas con un parámetro y escanear resultados
-func (r *LogRepository) executeScanQueryWithParam(ctx context.Context, query string, param string) ([]*model.LogEntry, error) {
-	rows, err := r.dbPool.QueryContext(ctx, query, param)
+// buildLogEntry convierte los valores escaneados en un LogEntry; el usuario es opcional
+func buildLogEntry(idStr string, userIDStr sql.NullString, tipoEventoStr, descripcion string, fecha time.Time) (*model.LogEntry, error) {
+	logID, err := uuid.Parse(idStr)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	return r.scanRows(rows)
-}
-
-// Método auxiliar para escanear filas de resultados
-func (r *LogRepository) scanRows(rows *sql.Rows) ([]*model.LogEntry, error) {
-	var logs []*model.LogEntry
-
-	for rows.Next() {
-		var idStr, userIDStr, tipoEventoStr, descripcion string
-		var fecha time.Time
 
-		if err := rows.Scan(&idStr, &userIDStr, &tipoEventoStr, &descripcion, &fecha); err != nil {
-			return nil, err
-		}
-
-		logID, err := uuid.Parse(idStr)
+	userID := uuid.Nil
+	if userIDStr.Valid {
+		userID, err = uuid.Parse(userIDStr.String)
 		if err != nil {
 			return nil, err
 		}
-
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			return nil, err
-		}
-
-		tipoEvento := model.EventoTipo(tipoEventoStr)
-
-		logEntry, err := model.NewLogEntry(logID, userID, tipoEvento, descripcion, fecha)
-		if err != nil {
-			return nil, err
-		}
-
-		logs = append(logs, logEntry)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+	return model.NewLogEntry(logID, model.EventoTipo(tipoEventoStr), descripcion, fecha, userID)
+}
 
-	return logs, nil
+// nullableUserID devuelve NULL para logs sin usuario asociado
+func nullableUserID(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id.String()
 }
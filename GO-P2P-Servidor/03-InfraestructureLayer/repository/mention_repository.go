@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"dao"
+	"github.com/google/uuid"
+	"model"
+	"pool"
+)
+
+// MentionRepository implementa la interfaz IMentionRepository
+type MentionRepository struct {
+	conexion
+	menciones *dao.MencionDAO
+	silencios *dao.SilencioMencionDAO
+}
+
+// NewMentionRepository crea una nueva instancia del repositorio
+func NewMentionRepository(dbPool *pool.DBConnectionPool, menciones *dao.MencionDAO, silencios *dao.SilencioMencionDAO) *MentionRepository {
+	return &MentionRepository{
+		conexion:  conexion{dbPool: dbPool},
+		menciones: menciones,
+		silencios: silencios,
+	}
+}
+
+// Save guarda una mención nueva o la copia recibida de otro nodo
+func (r *MentionRepository) Save(ctx context.Context, m *model.Mencion) error {
+	return r.menciones.Crear(ctx, r.querier(ctx), m)
+}
+
+// FindByKey busca la mención de un usuario en un mensaje
+func (r *MentionRepository) FindByKey(ctx context.Context, userID, messageID uuid.UUID) (*model.Mencion, error) {
+	return r.menciones.BuscarPorClave(ctx, r.querier(ctx), userID, messageID)
+}
+
+// FindByUser pagina el feed de menciones de un usuario
+func (r *MentionRepository) FindByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.Mencion], error) {
+	return r.menciones.BuscarPaginaPorUsuarioID(ctx, r.querier(ctx), userID, page)
+}
+
+// SaveMute guarda la preferencia de silencio de un usuario
+func (r *MentionRepository) SaveMute(ctx context.Context, s *model.SilencioMenciones) error {
+	return r.silencios.Guardar(ctx, r.querier(ctx), s)
+}
+
+// FindMute busca la preferencia de silencio de un usuario para un canal
+func (r *MentionRepository) FindMute(ctx context.Context, userID, channelID uuid.UUID) (*model.SilencioMenciones, error) {
+	return r.silencios.BuscarPorClave(ctx, r.querier(ctx), userID, channelID)
+}
+
+// FindMutes recupera todas las preferencias de silencio de un usuario
+func (r *MentionRepository) FindMutes(ctx context.Context, userID uuid.UUID) ([]*model.SilencioMenciones, error) {
+	return r.silencios.BuscarPorUsuarioID(ctx, r.querier(ctx), userID)
+}
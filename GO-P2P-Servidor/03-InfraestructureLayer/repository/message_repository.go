@@ -3,60 +3,217 @@ package repository
 import (
 	"context"
 	"dao"
-	"errors"
+	"time"
+
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // MessageRepository implements the IMessageRepository interface using MensajeDAO
 type MessageRepository struct {
-	mensajeDAO *dao.MensajeDAO
+	conexion
+	mensajeDAO  *dao.MensajeDAO
+	edicionDAO  *dao.EdicionMensajeDAO
+	reaccionDAO *dao.ReaccionMensajeDAO
+	acuseDAO    *dao.AcuseMensajeDAO
+	marcadorDAO *dao.MarcadorLecturaDAO
+	chatDAO     *dao.ChatPrivadoUsuarioDAO
+	// indice replaces the MySQL FULLTEXT index on SQLite; nil on MySQL
+	indice *dao.IndiceMensajes
 }
 
-// NewMessageRepository creates a new MessageRepository instance
-func NewMessageRepository(mensajeDAO *dao.MensajeDAO) *MessageRepository {
-	return &MessageRepository{mensajeDAO: mensajeDAO}
+// NewMessageRepository creates a new MessageRepository instance. On the SQLite backend
+// searches use an in-memory inverted index, so every write to mensaje_servidor must go
+// through this repository for the index to stay current. Writes made inside a unit of
+// work reach the index only once the transaction commits.
+func NewMessageRepository(dbPool *pool.DBConnectionPool, mensajeDAO *dao.MensajeDAO) *MessageRepository {
+	r := &MessageRepository{
+		conexion:    conexion{dbPool: dbPool},
+		mensajeDAO:  mensajeDAO,
+		edicionDAO:  dao.NuevoEdicionMensajeDAO(),
+		reaccionDAO: dao.NuevoReaccionMensajeDAO(),
+		acuseDAO:    dao.NuevoAcuseMensajeDAO(),
+		marcadorDAO: dao.NuevoMarcadorLecturaDAO(),
+		chatDAO:     dao.NuevoChatPrivadoUsuarioDAO(),
+	}
+	if dbPool != nil && dbPool.Driver() == pool.DriverSQLite {
+		r.indice = dao.NuevoIndiceMensajes()
+	}
+	return r
 }
 
 // Save persists a message to the database
 func (r *MessageRepository) Save(ctx context.Context, m *model.MensajeServidor) error {
-	return r.mensajeDAO.Crear(m)
+	if err := r.mensajeDAO.Crear(ctx, r.querier(ctx), m); err != nil {
+		return err
+	}
+	if r.indice != nil {
+		dao.AlConfirmar(ctx, func() { r.indice.Indexar(m) })
+	}
+	return nil
 }
 
-// Update updates an existing message
-// Note: MensajeDAO doesn't have an update method, so this is a stub
+// Update updates the content, attachment, version and edit/delete marks of an existing message
 func (r *MessageRepository) Update(ctx context.Context, m *model.MensajeServidor) error {
-	// This would require first deleting the message and then creating it again
-	// or implementing a proper update method in MensajeDAO
-	return errors.New("update operation not supported by underlying DAO")
+	if err := r.mensajeDAO.Actualizar(ctx, r.querier(ctx), m); err != nil {
+		return err
+	}
+	if r.indice != nil {
+		dao.AlConfirmar(ctx, func() { r.indice.Indexar(m) })
+	}
+	return nil
 }
 
 // Delete removes a message from the database
 func (r *MessageRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.mensajeDAO.Eliminar(id)
+	if err := r.mensajeDAO.Eliminar(ctx, r.querier(ctx), id); err != nil {
+		return err
+	}
+	if r.indice != nil {
+		dao.AlConfirmar(ctx, func() { r.indice.Quitar(id) })
+	}
+	return nil
 }
 
 // FindByID retrieves a message by its ID
 func (r *MessageRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.MensajeServidor, error) {
-	return r.mensajeDAO.BuscarPorID(id)
+	return r.mensajeDAO.BuscarPorID(ctx, r.querier(ctx), id)
+}
+
+// FindByChannel retrieves a page of the messages of a channel
+func (r *MessageRepository) FindByChannel(ctx context.Context, channelID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return r.mensajeDAO.BuscarPorCanalID(ctx, r.querier(ctx), channelID, page)
+}
+
+// FindByUser retrieves a page of the messages sent by a user
+func (r *MessageRepository) FindByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return r.mensajeDAO.BuscarPorRemitenteID(ctx, r.querier(ctx), userID, page)
+}
+
+// FindDirect retrieves a page of the direct messages between two users
+func (r *MessageRepository) FindDirect(ctx context.Context, a, b uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return r.mensajeDAO.BuscarMensajesDirectos(ctx, r.querier(ctx), a, b, page)
+}
+
+// FindByPrivateChat retrieves a page of the messages of a private chat
+func (r *MessageRepository) FindByPrivateChat(ctx context.Context, chatID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return r.mensajeDAO.BuscarPorChatPrivadoID(ctx, r.querier(ctx), chatID, page)
+}
+
+// FindChatParticipants retrieves the IDs of the users of a private chat
+func (r *MessageRepository) FindChatParticipants(ctx context.Context, chatID uuid.UUID) ([]uuid.UUID, error) {
+	relaciones, err := r.chatDAO.BuscarPorChatPrivadoID(ctx, r.querier(ctx), chatID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(relaciones))
+	for _, rel := range relaciones {
+		ids = append(ids, rel.UsuarioID())
+	}
+	return ids, nil
+}
+
+// FindByTimeRange retrieves the messages whose timestamp falls in [from, to)
+func (r *MessageRepository) FindByTimeRange(ctx context.Context, from, to time.Time) ([]*model.MensajeServidor, error) {
+	return r.mensajeDAO.BuscarPorRangoFechas(ctx, r.querier(ctx), from, to)
+}
+
+// FindReplies retrieves a page of the replies in the thread of a root message
+func (r *MessageRepository) FindReplies(ctx context.Context, parentID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return r.mensajeDAO.BuscarRespuestas(ctx, r.querier(ctx), parentID, page)
+}
+
+// FindThreads retrieves a page of the threads of a channel that have at least one reply
+func (r *MessageRepository) FindThreads(ctx context.Context, channelID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.ResumenHilo], error) {
+	return r.mensajeDAO.BuscarHilosPorCanalID(ctx, r.querier(ctx), channelID, page)
+}
+
+// FindThreadSummary retrieves the reply count and last reply time of a root message
+func (r *MessageRepository) FindThreadSummary(ctx context.Context, parentID uuid.UUID) (*model.ResumenHilo, error) {
+	return r.mensajeDAO.BuscarResumenHilo(ctx, r.querier(ctx), parentID)
+}
+
+// Search retrieves a page of the non-deleted messages visible to userID that contain
+// every term of query and match the filter. It uses the MySQL FULLTEXT index or, on
+// SQLite, the in-memory inverted index, loaded on the first search.
+func (r *MessageRepository) Search(ctx context.Context, userID uuid.UUID, query string, filter model.FiltroBusqueda, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	terminos := model.TerminosBusqueda(query)
+	if len(terminos) == 0 {
+		return nil, model.ErrBusquedaVacia
+	}
+	if err := filter.Validar(); err != nil {
+		return nil, err
+	}
+
+	filtro := dao.FiltroBusquedaMensajes{UsuarioID: userID, Terminos: terminos, Filtro: filter}
+	q := r.querier(ctx)
+	if r.indice != nil {
+		if err := r.indice.Cargar(ctx, q); err != nil {
+			return nil, err
+		}
+		filtro.Candidatos = r.indice.Buscar(terminos)
+	}
+	return r.mensajeDAO.Buscar(ctx, q, filtro, page)
+}
+
+// SaveEdit appends an entry to the edit history of a message
+func (r *MessageRepository) SaveEdit(ctx context.Context, e *model.EdicionMensaje) error {
+	return r.edicionDAO.Crear(ctx, r.querier(ctx), e)
+}
+
+// FindEdits retrieves the edit history of a message in chronological order
+func (r *MessageRepository) FindEdits(ctx context.Context, messageID uuid.UUID) ([]*model.EdicionMensaje, error) {
+	return r.edicionDAO.BuscarPorMensajeID(ctx, r.querier(ctx), messageID)
+}
+
+// SaveReaction creates or replaces the reaction of a user to a message with an emoji
+func (r *MessageRepository) SaveReaction(ctx context.Context, reaccion *model.ReaccionMensaje) error {
+	return r.reaccionDAO.Guardar(ctx, r.querier(ctx), reaccion)
+}
+
+// FindReaction retrieves a reaction, active or removed, by message, user and emoji
+func (r *MessageRepository) FindReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) (*model.ReaccionMensaje, error) {
+	return r.reaccionDAO.BuscarPorClave(ctx, r.querier(ctx), messageID, userID, emoji)
+}
+
+// CountReactions retrieves the number of active reactions per emoji of each message
+func (r *MessageRepository) CountReactions(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]model.ConteoReaccion, error) {
+	return r.reaccionDAO.ContarPorMensajes(ctx, r.querier(ctx), messageIDs)
+}
+
+// FindReactionsByTimeRange retrieves the reactions last changed within [from, to)
+func (r *MessageRepository) FindReactionsByTimeRange(ctx context.Context, from, to time.Time) ([]*model.ReaccionMensaje, error) {
+	return r.reaccionDAO.BuscarPorRangoTiempo(ctx, r.querier(ctx), from, to)
+}
+
+// SaveReceipt creates or replaces the delivery state of a message for one recipient
+func (r *MessageRepository) SaveReceipt(ctx context.Context, a *model.AcuseMensaje) error {
+	return r.acuseDAO.Guardar(ctx, r.querier(ctx), a)
+}
+
+// FindReceipt retrieves the delivery state of a message for one recipient
+func (r *MessageRepository) FindReceipt(ctx context.Context, messageID, userID uuid.UUID) (*model.AcuseMensaje, error) {
+	return r.acuseDAO.BuscarPorClave(ctx, r.querier(ctx), messageID, userID)
+}
+
+// FindReceipts retrieves the delivery states recorded for the recipients of a message
+func (r *MessageRepository) FindReceipts(ctx context.Context, messageID uuid.UUID) ([]*model.AcuseMensaje, error) {
+	return r.acuseDAO.BuscarPorMensajeID(ctx, r.querier(ctx), messageID)
 }
 
-// FindByChannel retrieves messages from a specific channel
-func (r *MessageRepository) FindByChannel(ctx context.Context, channelID uuid.UUID, limit, offset int) ([]*model.MensajeServidor, error) {
-	// Note: MensajeDAO.BuscarPorCanalID doesn't support offset,
-	// we'd need to enhance the DAO to support pagination properly
-	return r.mensajeDAO.BuscarPorCanalID(channelID, limit)
+// SaveReadMarker creates or replaces the last read message of a user in a conversation
+func (r *MessageRepository) SaveReadMarker(ctx context.Context, m *model.MarcadorLectura) error {
+	return r.marcadorDAO.Guardar(ctx, r.querier(ctx), m)
 }
 
-// FindByUser retrieves messages sent by a specific user
-func (r *MessageRepository) FindByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*model.MensajeServidor, error) {
-	// MensajeDAO doesn't provide a direct method to find messages by user
-	// This would need to be implemented in the DAO layer
-	return nil, errors.New("findByUser not implemented in the underlying DAO")
+// FindReadMarker retrieves the last read message of a user in a conversation
+func (r *MessageRepository) FindReadMarker(ctx context.Context, userID, conversationID uuid.UUID) (*model.MarcadorLectura, error) {
+	return r.marcadorDAO.BuscarPorClave(ctx, r.querier(ctx), userID, conversationID)
 }
 
-// FindDirect retrieves direct messages between two users
-func (r *MessageRepository) FindDirect(ctx context.Context, a, b uuid.UUID, limit, offset int) ([]*model.MensajeServidor, error) {
-	// Note: MensajeDAO.BuscarMensajesDirectos doesn't support offset
-	return r.mensajeDAO.BuscarMensajesDirectos(a, b, limit)
+// CountUnread retrieves the number of unread messages of each conversation of a user
+// that has any
+func (r *MessageRepository) CountUnread(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error) {
+	return r.marcadorDAO.ContarNoLeidos(ctx, r.querier(ctx), userID)
 }
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"dao"
+	"github.com/google/uuid"
+	"model"
+	"pool"
+)
+
+// NotificationRepository implementa la interfaz INotificationRepository
+type NotificationRepository struct {
+	conexion
+	dao *dao.NotificacionDAO
+}
+
+// NewNotificationRepository crea una nueva instancia del repositorio
+func NewNotificationRepository(dbPool *pool.DBConnectionPool, dao *dao.NotificacionDAO) *NotificationRepository {
+	return &NotificationRepository{
+		conexion: conexion{dbPool: dbPool},
+		dao:      dao,
+	}
+}
+
+// Save persiste una notificación
+func (r *NotificationRepository) Save(ctx context.Context, n *model.Notificacion) error {
+	return r.dao.Guardar(ctx, r.querier(ctx), n)
+}
+
+// FindByID busca una notificación por su ID
+func (r *NotificationRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Notificacion, error) {
+	return r.dao.BuscarPorID(ctx, r.querier(ctx), id)
+}
+
+// FindByUser recupera las notificaciones de un usuario
+func (r *NotificationRepository) FindByUser(ctx context.Context, userID uuid.UUID) ([]*model.Notificacion, error) {
+	return r.dao.BuscarPorUsuarioID(ctx, r.querier(ctx), userID)
+}
+
+// FindPageByUser pagina las notificaciones de un usuario
+func (r *NotificationRepository) FindPageByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.Notificacion], error) {
+	return r.dao.BuscarPaginaPorUsuarioID(ctx, r.querier(ctx), userID, page)
+}
+
+// MarkRead marca una notificación como leída
+func (r *NotificationRepository) MarkRead(ctx context.Context, id uuid.UUID) error {
+	return r.dao.ActualizarEstadoLeido(ctx, r.querier(ctx), id, true)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"dao"
+	"github.com/google/uuid"
+	"model"
+	"pool"
+)
+
+// OfflineQueueRepository implementa la interfaz IOfflineQueueRepository
+type OfflineQueueRepository struct {
+	conexion
+	dao *dao.EventoPendienteDAO
+}
+
+// NewOfflineQueueRepository crea una nueva instancia del repositorio
+func NewOfflineQueueRepository(dbPool *pool.DBConnectionPool, dao *dao.EventoPendienteDAO) *OfflineQueueRepository {
+	return &OfflineQueueRepository{
+		conexion: conexion{dbPool: dbPool},
+		dao:      dao,
+	}
+}
+
+// Enqueue añade un evento a la cola de su destinatario
+func (r *OfflineQueueRepository) Enqueue(ctx context.Context, e *model.EventoPendiente) error {
+	return r.dao.Crear(ctx, r.querier(ctx), e)
+}
+
+// CountPending cuenta los eventos en la cola de un usuario
+func (r *OfflineQueueRepository) CountPending(ctx context.Context, userID uuid.UUID) (int, error) {
+	return r.dao.ContarPorUsuarioID(ctx, r.querier(ctx), userID)
+}
+
+// FindPending pagina la cola de un usuario
+func (r *OfflineQueueRepository) FindPending(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.EventoPendiente], error) {
+	return r.dao.BuscarPaginaPorUsuarioID(ctx, r.querier(ctx), userID, page)
+}
+
+// AcknowledgeUpTo borra de la cola los eventos que el usuario confirmó haber recibido
+func (r *OfflineQueueRepository) AcknowledgeUpTo(ctx context.Context, userID uuid.UUID, position model.Cursor) (int64, error) {
+	return r.dao.EliminarHasta(ctx, r.querier(ctx), userID, position)
+}
+
+// Trim descarta los eventos más antiguos de la cola de un usuario
+func (r *OfflineQueueRepository) Trim(ctx context.Context, userID uuid.UUID, keep int) (int64, error) {
+	return r.dao.Recortar(ctx, r.querier(ctx), userID, keep)
+}
@@ -3,47 +3,78 @@ package repository
 import (
 	"context"
 	"dao"
+	"time"
+
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // PeerRepository implements the IPeerRepository interface using NodoDAO
 type PeerRepository struct {
+	conexion
 	nodoDAO *dao.NodoDAO
 }
 
 // NewPeerRepository creates a new PeerRepository instance
-func NewPeerRepository(nodoDAO *dao.NodoDAO) *PeerRepository {
-	return &PeerRepository{nodoDAO: nodoDAO}
+func NewPeerRepository(dbPool *pool.DBConnectionPool, nodoDAO *dao.NodoDAO) *PeerRepository {
+	return &PeerRepository{conexion: conexion{dbPool: dbPool}, nodoDAO: nodoDAO}
+}
+
+// WithReplicaReads returns a copy of the repository whose reads may be served by a read
+// replica. Only for callers that tolerate replication lag, such as the dashboard.
+func (r *PeerRepository) WithReplicaReads() *PeerRepository {
+	copia := *r
+	copia.replica = true
+	return &copia
 }
 
 // Save persists a peer to the database
 func (r *PeerRepository) Save(ctx context.Context, p *model.Peer) error {
 	// Context isn't used in the DAO, but we could add that functionality later
-	return r.nodoDAO.Guardar(p)
+	return r.nodoDAO.Guardar(ctx, r.querier(ctx), p)
 }
 
 // Update updates an existing peer in the database
 func (r *PeerRepository) Update(ctx context.Context, p *model.Peer) error {
-	return r.nodoDAO.Actualizar(p)
+	return r.nodoDAO.Actualizar(ctx, r.querier(ctx), p)
 }
 
 // Delete removes a peer from the database by ID
 func (r *PeerRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.nodoDAO.Eliminar(id)
+	return r.nodoDAO.Eliminar(ctx, r.querier(ctx), id)
 }
 
 // FindByID retrieves a peer by its ID
 func (r *PeerRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Peer, error) {
-	return r.nodoDAO.BuscarPorID(id)
+	return r.nodoDAO.BuscarPorID(ctx, r.querier(ctx), id)
 }
 
 // ListAll retrieves all peers from the database
 func (r *PeerRepository) ListAll(ctx context.Context) ([]*model.Peer, error) {
-	return r.nodoDAO.BuscarTodos()
+	return r.nodoDAO.BuscarTodos(ctx, r.querier(ctx))
 }
 
 // ListByState retrieves all peers with a specific state
 func (r *PeerRepository) ListByState(ctx context.Context, state model.NodoEstado) ([]*model.Peer, error) {
-	return r.nodoDAO.BuscarPorEstado(state)
+	return r.nodoDAO.BuscarPorEstado(ctx, r.querier(ctx), state)
+}
+
+// UpdateLastSync records the last successful anti-entropy sync with a peer. Peers are
+// only known to the connection pool, so the first sync inserts the peer's row.
+func (r *PeerRepository) UpdateLastSync(ctx context.Context, id uuid.UUID, address string, at time.Time) error {
+	q := r.querier(ctx)
+	p, err := r.nodoDAO.BuscarPorID(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		if p, err = model.NewPeer(id, address, model.NodoConectado); err != nil {
+			return err
+		}
+		if err := r.nodoDAO.Guardar(ctx, q, p); err != nil {
+			return err
+		}
+	}
+	return r.nodoDAO.ActualizarUltimaSync(ctx, q, id, at)
 }
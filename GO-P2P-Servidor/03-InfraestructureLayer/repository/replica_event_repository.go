@@ -5,33 +5,36 @@ import (
 	"dao"
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // ReplicaEventRepository implementa la interfaz IReplicaEventRepository
 type ReplicaEventRepository struct {
+	conexion
 	dao *dao.ReplicaEventMySQLDAO
 }
 
 // NewReplicaEventRepository crea una nueva instancia del repositorio
-func NewReplicaEventRepository(dao *dao.ReplicaEventMySQLDAO) *ReplicaEventRepository {
+func NewReplicaEventRepository(dbPool *pool.DBConnectionPool, dao *dao.ReplicaEventMySQLDAO) *ReplicaEventRepository {
 	return &ReplicaEventRepository{
-		dao: dao,
+		conexion: conexion{dbPool: dbPool},
+		dao:      dao,
 	}
 }
 
 // Save persiste un evento de réplica
 func (r *ReplicaEventRepository) Save(ctx context.Context, e *model.ReplicaEvent) error {
-	return r.dao.Create(e)
+	return r.dao.Create(ctx, r.querier(ctx), e)
 }
 
 // Delete elimina un evento de réplica por su ID
 func (r *ReplicaEventRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.dao.Delete(id)
+	return r.dao.Delete(ctx, r.querier(ctx), id)
 }
 
 // FindByID busca un evento de réplica por su ID
 func (r *ReplicaEventRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ReplicaEvent, error) {
-	return r.dao.FindByID(id)
+	return r.dao.FindByID(ctx, r.querier(ctx), id)
 }
 
 // ListPending retorna los eventos de réplica pendientes de procesamiento
@@ -40,7 +43,7 @@ func (r *ReplicaEventRepository) FindByID(ctx context.Context, id uuid.UUID) (*m
 func (r *ReplicaEventRepository) ListPending(ctx context.Context) ([]*model.ReplicaEvent, error) {
 	// TODO: Implementar cuando el DAO soporte consultar eventos por estado de procesamiento
 	// Por ahora, retorna todos los eventos (comportamiento temporal)
-	return r.dao.FindAll()
+	return r.dao.FindAll(ctx, r.querier(ctx))
 }
 
 // MarkProcessed marca un evento de réplica como procesado
@@ -5,43 +5,40 @@ import (
 	"dao"
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // RoutedMessageRepository implementa la interfaz IRoutedMessageRepository
 type RoutedMessageRepository struct {
+	conexion
 	dao *dao.MensajeEnrutadoDAO
 }
 
 // NewRoutedMessageRepository crea una nueva instancia del repositorio
-func NewRoutedMessageRepository(dao *dao.MensajeEnrutadoDAO) *RoutedMessageRepository {
+func NewRoutedMessageRepository(dbPool *pool.DBConnectionPool, dao *dao.MensajeEnrutadoDAO) *RoutedMessageRepository {
 	return &RoutedMessageRepository{
-		dao: dao,
+		conexion: conexion{dbPool: dbPool},
+		dao:      dao,
 	}
 }
 
 // Save persiste un mensaje enrutado
 func (r *RoutedMessageRepository) Save(ctx context.Context, message *model.RoutedMessage) error {
 	// El DAO actual no utiliza context, simplemente pasamos el mensaje
-	return r.dao.Crear(message)
+	return r.dao.Crear(ctx, r.querier(ctx), message)
 }
 
-// FindByMessage busca mensajes enrutados por ID de mensaje
-// Nota: Hay una discrepancia entre la interfaz (que retorna un slice) y el DAO (que retorna un único mensaje)
-// Esto se maneja devolviendo un slice que contiene el mensaje único o está vacío
+// FindByMessage busca todos los saltos registrados para un mensaje
 func (r *RoutedMessageRepository) FindByMessage(ctx context.Context, messageID uuid.UUID) ([]*model.RoutedMessage, error) {
-	message, err := r.dao.BuscarPorMensajeID(messageID)
-	if err != nil {
-		return nil, err
-	}
-
-	if message == nil {
-		return []*model.RoutedMessage{}, nil
-	}
-
-	return []*model.RoutedMessage{message}, nil
+	return r.dao.BuscarPorMensajeID(ctx, r.querier(ctx), messageID)
 }
 
 // DeleteByMessage elimina un mensaje enrutado por su ID de mensaje
 func (r *RoutedMessageRepository) DeleteByMessage(ctx context.Context, messageID uuid.UUID) error {
-	return r.dao.Eliminar(messageID)
+	return r.dao.Eliminar(ctx, r.querier(ctx), messageID)
+}
+
+// ListByDestination pagina los saltos enviados a un nodo
+func (r *RoutedMessageRepository) ListByDestination(ctx context.Context, nodeID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.RoutedMessage], error) {
+	return r.dao.BuscarPaginaPorNodoDestinoID(ctx, r.querier(ctx), nodeID, page)
 }
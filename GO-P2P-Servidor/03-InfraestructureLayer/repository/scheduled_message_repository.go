@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"dao"
+	"github.com/google/uuid"
+	"model"
+	"pool"
+	"time"
+)
+
+// ScheduledMessageRepository implementa la interfaz IScheduledMessageRepository
+type ScheduledMessageRepository struct {
+	conexion
+	dao *dao.MensajeProgramadoDAO
+}
+
+// NewScheduledMessageRepository crea una nueva instancia del repositorio
+func NewScheduledMessageRepository(dbPool *pool.DBConnectionPool, dao *dao.MensajeProgramadoDAO) *ScheduledMessageRepository {
+	return &ScheduledMessageRepository{
+		conexion: conexion{dbPool: dbPool},
+		dao:      dao,
+	}
+}
+
+// Save guarda un mensaje programado nuevo o la copia recibida de otro nodo
+func (r *ScheduledMessageRepository) Save(ctx context.Context, p *model.MensajeProgramado) error {
+	return r.dao.Crear(ctx, r.querier(ctx), p)
+}
+
+// FindByID busca un mensaje programado por su ID
+func (r *ScheduledMessageRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.MensajeProgramado, error) {
+	return r.dao.BuscarPorID(ctx, r.querier(ctx), id)
+}
+
+// UpdateState guarda el nuevo estado si el anterior no ha cambiado entretanto
+func (r *ScheduledMessageRepository) UpdateState(ctx context.Context, p *model.MensajeProgramado, from model.EstadoProgramado) (bool, error) {
+	return r.dao.ActualizarEstado(ctx, r.querier(ctx), p, from)
+}
+
+// FindDue recupera los mensajes del nodo que hay que enviar o eliminar
+func (r *ScheduledMessageRepository) FindDue(ctx context.Context, nodeID uuid.UUID, at time.Time, limit int) ([]*model.MensajeProgramado, error) {
+	return r.dao.BuscarVencidos(ctx, r.querier(ctx), nodeID, at, limit)
+}
+
+// FindBySender pagina los mensajes programados por un usuario
+func (r *ScheduledMessageRepository) FindBySender(ctx context.Context, senderID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeProgramado], error) {
+	return r.dao.BuscarPaginaPorRemitenteID(ctx, r.querier(ctx), senderID, page)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"dao"
+	"pool"
+)
+
+// conexion da a los repositorios el Querier con el que llamar a los DAO: la transacción
+// de la unidad de trabajo en curso o, fuera de ella, el pool. Con replica, las lecturas
+// hechas fuera de una transacción pueden ir a una réplica (pool.WithReplicaRead).
+type conexion struct {
+	dbPool  *pool.DBConnectionPool
+	replica bool
+}
+
+// querier devuelve el Querier para las operaciones hechas con ctx
+func (c conexion) querier(ctx context.Context) dao.Querier {
+	q := dao.QuerierDesdeContexto(ctx, c.dbPool)
+	if c.replica && q == dao.Querier(c.dbPool) {
+		return lecturaEnReplica{c.dbPool}
+	}
+	return q
+}
+
+// lecturaEnReplica es el pool con las lecturas marcadas para poder ir a una réplica; las
+// escrituras siguen yendo a la principal
+type lecturaEnReplica struct {
+	*pool.DBConnectionPool
+}
+
+// QueryContext reparte la consulta entre las réplicas sanas
+func (l lecturaEnReplica) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return l.DBConnectionPool.QueryContext(pool.WithReplicaRead(ctx), query, args...)
+}
+
+// QueryRowContext reparte la consulta entre las réplicas sanas
+func (l lecturaEnReplica) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return l.DBConnectionPool.QueryRowContext(pool.WithReplicaRead(ctx), query, args...)
+}
+
+// UnitOfWork implementa la interfaz IUnitOfWork del dominio sobre dao.UnidadDeTrabajo
+type UnitOfWork struct {
+	unidad *dao.UnidadDeTrabajo
+}
+
+// NewUnitOfWork crea una unidad de trabajo sobre el pool que usan los repositorios
+func NewUnitOfWork(dbPool *pool.DBConnectionPool) *UnitOfWork {
+	return &UnitOfWork{unidad: dao.NuevaUnidadDeTrabajo(dbPool)}
+}
+
+// Do ejecuta fn en una transacción, repitiéndola si se aborta por un interbloqueo
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return u.unidad.Ejecutar(ctx, func(ctx context.Context, _ dao.Querier) error {
+		return fn(ctx)
+	})
+}
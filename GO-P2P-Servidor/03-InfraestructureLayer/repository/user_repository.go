@@ -5,17 +5,20 @@ import (
 	"dao"
 	"github.com/google/uuid"
 	"model"
+	"pool"
 )
 
 // UserRepository implementa la interfaz IUserRepository del dominio
 // utilizando el UsuarioDAO para las operaciones de base de datos
 type UserRepository struct {
+	conexion
 	usuarioDAO *dao.UsuarioDAO
 }
 
 // NewUserRepository crea una nueva instancia de UserRepository
-func NewUserRepository(usuarioDAO *dao.UsuarioDAO) *UserRepository {
+func NewUserRepository(dbPool *pool.DBConnectionPool, usuarioDAO *dao.UsuarioDAO) *UserRepository {
 	return &UserRepository{
+		conexion:   conexion{dbPool: dbPool},
 		usuarioDAO: usuarioDAO,
 	}
 }
@@ -23,37 +26,37 @@ func NewUserRepository(usuarioDAO *dao.UsuarioDAO) *UserRepository {
 // Save persiste un usuario en la base de datos
 func (r *UserRepository) Save(ctx context.Context, u *model.UsuarioServidor) error {
 	// Usamos el método Crear del DAO
-	return r.usuarioDAO.Crear(u)
+	return r.usuarioDAO.Crear(ctx, r.querier(ctx), u)
 }
 
 // Update actualiza un usuario existente en la base de datos
 func (r *UserRepository) Update(ctx context.Context, u *model.UsuarioServidor) error {
 	// Usamos el método Actualizar del DAO
-	return r.usuarioDAO.Actualizar(u)
+	return r.usuarioDAO.Actualizar(ctx, r.querier(ctx), u)
 }
 
 // Delete elimina un usuario de la base de datos
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Usamos el método Eliminar del DAO
-	return r.usuarioDAO.Eliminar(id)
+	return r.usuarioDAO.Eliminar(ctx, r.querier(ctx), id)
 }
 
 // FindByID busca un usuario por su ID
 func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.UsuarioServidor, error) {
 	// Usamos el método BuscarPorID del DAO
-	return r.usuarioDAO.BuscarPorID(id)
+	return r.usuarioDAO.BuscarPorID(ctx, r.querier(ctx), id)
 }
 
 // FindAll recupera todos los usuarios
 func (r *UserRepository) FindAll(ctx context.Context) ([]*model.UsuarioServidor, error) {
 	// Usamos el método BuscarTodos del DAO
-	return r.usuarioDAO.BuscarTodos()
+	return r.usuarioDAO.BuscarTodos(ctx, r.querier(ctx))
 }
 
 // FindConnected recupera todos los usuarios conectados
 func (r *UserRepository) FindConnected(ctx context.Context) ([]*model.UsuarioServidor, error) {
 	// Obtenemos todos los usuarios primero
-	usuarios, err := r.usuarioDAO.BuscarTodos()
+	usuarios, err := r.usuarioDAO.BuscarTodos(ctx, r.querier(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -72,5 +75,5 @@ func (r *UserRepository) FindConnected(ctx context.Context) ([]*model.UsuarioSer
 // FindByEmail busca un usuario por su email
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.UsuarioServidor, error) {
 	// Usamos el método BuscarPorEmail del DAO
-	return r.usuarioDAO.BuscarPorEmail(email)
+	return r.usuarioDAO.BuscarPorEmail(ctx, r.querier(ctx), email)
 }
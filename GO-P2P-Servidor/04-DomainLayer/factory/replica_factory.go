@@ -14,12 +14,16 @@ var (
     ErrEntidadTipoVacio = errors.New("tipo de entidad vacío")
     ErrEntidadIDNil     = errors.New("id de entidad inválido")
     ErrOrigenNodoIDNil  = errors.New("id de nodo de origen inválido")
+    ErrVersionZero      = errors.New("versión HLC vacía")
 )
 
 // IReplicaFactory define la abstracción para crear ReplicaEvent
 type IReplicaFactory interface {
     // Create valida los parámetros y crea un ReplicaEvent
     Create(entidadTipo string, entidadID, origenNodoID uuid.UUID) (*model.ReplicaEvent, error)
+
+    // CreateVersioned crea un ReplicaEvent que transporta la versión HLC de la entidad
+    CreateVersioned(entidadTipo string, entidadID, origenNodoID uuid.UUID, version model.HLC) (*model.ReplicaEvent, error)
 }
 
 // ReplicaFactory es la implementación concreta de IReplicaFactory
@@ -49,3 +53,21 @@ func (f *ReplicaFactory) Create(entidadTipo string, entidadID, origenNodoID uuid
     // Llamar al constructor del modelo con todos los parámetros
     return model.NewReplicaEvent(id, entidadTipo, entidadID, eventoAt, origenNodoID)
 }
+
+// CreateVersioned valida los invariantes y crea un evento con la versión HLC de la entidad
+func (f *ReplicaFactory) CreateVersioned(entidadTipo string, entidadID, origenNodoID uuid.UUID, version model.HLC) (*model.ReplicaEvent, error) {
+    if entidadTipo == "" {
+        return nil, ErrEntidadTipoVacio
+    }
+    if entidadID == uuid.Nil {
+        return nil, ErrEntidadIDNil
+    }
+    if origenNodoID == uuid.Nil {
+        return nil, ErrOrigenNodoIDNil
+    }
+    if version.IsZero() {
+        return nil, ErrVersionZero
+    }
+
+    return model.NewReplicaEventVersionado(uuid.New(), entidadTipo, entidadID, time.Now().UTC(), origenNodoID, version)
+}
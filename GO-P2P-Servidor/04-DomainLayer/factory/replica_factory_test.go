@@ -7,6 +7,7 @@ import (
     "github.com/google/uuid"
     
     "factory"
+    "model"
 )
 
 func TestReplicaFactory_Create_Success(t *testing.T) {
@@ -53,3 +54,21 @@ func TestReplicaFactory_Create_Errors(t *testing.T) {
         t.Errorf("se esperaba ErrOrigenNodoIDNil, se obtuvo: %v", err)
     }
 }
+
+func TestReplicaFactory_CreateVersioned(t *testing.T) {
+    f := factory.NewReplicaFactory()
+    origenID := uuid.New()
+    version, _ := model.NewHLC(time.Now().UnixNano(), 2, origenID)
+
+    ev, err := f.CreateVersioned(model.EntidadCanal, uuid.New(), origenID, version)
+    if err != nil {
+        t.Fatalf("se esperaba nil error, se obtuvo: %v", err)
+    }
+    if ev.Version().Compare(version) != 0 {
+        t.Errorf("Version incorrecta. Esperado %v, obtenido %v", version, ev.Version())
+    }
+
+    if _, err := f.CreateVersioned(model.EntidadCanal, uuid.New(), origenID, model.HLC{}); err != factory.ErrVersionZero {
+        t.Errorf("se esperaba ErrVersionZero, se obtuvo: %v", err)
+    }
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para Actividad
+var (
+	ErrActividadUsuarioIDNil      = errors.New("id del usuario de la actividad inválido")
+	ErrActividadConversacionIDNil = errors.New("id de la conversación de la actividad inválido")
+	ErrActividadTipoInvalido      = errors.New("tipo de actividad inválido")
+	ErrActividadFechaZero         = errors.New("expiración de la actividad no puede ser cero")
+)
+
+// TipoActividad es lo que un usuario está haciendo en una conversación
+type TipoActividad string
+
+// Constantes para los tipos de actividad
+const (
+	ActividadEscribiendo TipoActividad = "ESCRIBIENDO" // Está escribiendo un mensaje
+	ActividadViendo      TipoActividad = "VIENDO"      // Tiene la conversación abierta
+)
+
+// Valid verifica si el valor es un tipo de actividad válido
+func (t TipoActividad) Valid() bool {
+	return t == ActividadEscribiendo || t == ActividadViendo
+}
+
+// Actividad es una actividad efímera de un usuario en una conversación: el canal, el
+// chat privado o, en los mensajes directos, el otro usuario. No se persiste: vive en
+// memoria hasta que el usuario la termina o vence sin que la renueve, de modo que un
+// cliente caído no deja la actividad colgada.
+type Actividad struct {
+	usuarioID      uuid.UUID
+	conversacionID uuid.UUID
+	tipo           TipoActividad
+	expira         time.Time // Momento a partir del cual deja de estar vigente
+}
+
+// NewActividad crea una Actividad vigente hasta expira validando sus invariantes
+func NewActividad(usuarioID, conversacionID uuid.UUID, tipo TipoActividad, expira time.Time) (*Actividad, error) {
+	if usuarioID == uuid.Nil {
+		return nil, ErrActividadUsuarioIDNil
+	}
+	if conversacionID == uuid.Nil {
+		return nil, ErrActividadConversacionIDNil
+	}
+	if !tipo.Valid() {
+		return nil, ErrActividadTipoInvalido
+	}
+	if expira.IsZero() {
+		return nil, ErrActividadFechaZero
+	}
+	return &Actividad{
+		usuarioID:      usuarioID,
+		conversacionID: conversacionID,
+		tipo:           tipo,
+		expira:         expira,
+	}, nil
+}
+
+// Getters
+func (a *Actividad) UsuarioID() uuid.UUID      { return a.usuarioID }
+func (a *Actividad) ConversacionID() uuid.UUID { return a.conversacionID }
+func (a *Actividad) Tipo() TipoActividad       { return a.tipo }
+func (a *Actividad) Expira() time.Time         { return a.expira }
+
+// Vigente indica si la actividad no ha vencido en el instante dado
+func (a *Actividad) Vigente(at time.Time) bool {
+	return at.Before(a.expira)
+}
+
+// Renovar alarga la actividad hasta expira; nunca la acorta
+func (a *Actividad) Renovar(expira time.Time) {
+	if expira.After(a.expira) {
+		a.expira = expira
+	}
+}
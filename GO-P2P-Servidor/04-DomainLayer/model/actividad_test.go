@@ -0,0 +1,66 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestTipoActividad(t *testing.T) {
+	if !model.ActividadEscribiendo.Valid() || !model.ActividadViendo.Valid() {
+		t.Error("ESCRIBIENDO y VIENDO deben ser válidos")
+	}
+	if model.TipoActividad("GRABANDO").Valid() || model.TipoActividad("").Valid() {
+		t.Error("solo ESCRIBIENDO y VIENDO son válidos")
+	}
+}
+
+func TestNewActividad(t *testing.T) {
+	usuarioID, conversacionID := uuid.New(), uuid.New()
+	expira := time.Now().Add(5 * time.Second)
+
+	a, err := model.NewActividad(usuarioID, conversacionID, model.ActividadEscribiendo, expira)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if a.UsuarioID() != usuarioID || a.ConversacionID() != conversacionID || a.Tipo() != model.ActividadEscribiendo || !a.Expira().Equal(expira) {
+		t.Errorf("campos inesperados: %v %v %s %v", a.UsuarioID(), a.ConversacionID(), a.Tipo(), a.Expira())
+	}
+
+	casos := []struct {
+		nombre                    string
+		usuarioID, conversacionID uuid.UUID
+		tipo                      model.TipoActividad
+		expira                    time.Time
+		esperado                  error
+	}{
+		{"usuario nil", uuid.Nil, conversacionID, model.ActividadViendo, expira, model.ErrActividadUsuarioIDNil},
+		{"conversación nil", usuarioID, uuid.Nil, model.ActividadViendo, expira, model.ErrActividadConversacionIDNil},
+		{"tipo inválido", usuarioID, conversacionID, "", expira, model.ErrActividadTipoInvalido},
+		{"expiración cero", usuarioID, conversacionID, model.ActividadViendo, time.Time{}, model.ErrActividadFechaZero},
+	}
+	for _, c := range casos {
+		if _, err := model.NewActividad(c.usuarioID, c.conversacionID, c.tipo, c.expira); err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
+
+func TestActividad_VigenciaYRenovacion(t *testing.T) {
+	ahora := time.Now()
+	a, _ := model.NewActividad(uuid.New(), uuid.New(), model.ActividadEscribiendo, ahora.Add(time.Second))
+
+	if !a.Vigente(ahora) || a.Vigente(ahora.Add(time.Second)) {
+		t.Error("la actividad vence en su expiración")
+	}
+	a.Renovar(ahora.Add(10 * time.Second))
+	if !a.Vigente(ahora.Add(5 * time.Second)) {
+		t.Error("renovar debe alargar la actividad")
+	}
+	a.Renovar(ahora.Add(2 * time.Second))
+	if !a.Expira().Equal(ahora.Add(10 * time.Second)) {
+		t.Errorf("renovar no debe acortar la actividad, expira %v", a.Expira())
+	}
+}
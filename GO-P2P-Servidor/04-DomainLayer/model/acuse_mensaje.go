@@ -0,0 +1,111 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para AcuseMensaje
+var (
+	ErrAcuseMensajeIDNil   = errors.New("id del mensaje del acuse inválido")
+	ErrAcuseUsuarioIDNil   = errors.New("id del destinatario del acuse inválido")
+	ErrAcuseEstadoInvalido = errors.New("estado de entrega inválido")
+	ErrAcuseFechaZero      = errors.New("fecha del acuse no puede ser cero")
+)
+
+// EstadoEntrega representa el punto al que ha llegado un mensaje para uno de sus
+// destinatarios
+type EstadoEntrega string
+
+// Constantes para los estados de entrega, en el orden en que se alcanzan
+const (
+	EntregaEnviado   EstadoEntrega = "ENVIADO"   // El servidor aceptó el mensaje
+	EntregaEntregado EstadoEntrega = "ENTREGADO" // Un dispositivo del destinatario lo recibió
+	EntregaLeido     EstadoEntrega = "LEIDO"     // El destinatario lo leyó
+)
+
+// Valid verifica si el valor es un estado de entrega válido
+func (e EstadoEntrega) Valid() bool {
+	switch e {
+	case EntregaEnviado, EntregaEntregado, EntregaLeido:
+		return true
+	default:
+		return false
+	}
+}
+
+// Supera indica si el estado es posterior a otro en el ciclo de entrega
+func (e EstadoEntrega) Supera(otro EstadoEntrega) bool {
+	return e.orden() > otro.orden()
+}
+
+// orden devuelve la posición del estado en el ciclo de entrega; 0 si no es válido
+func (e EstadoEntrega) orden() int {
+	switch e {
+	case EntregaEnviado:
+		return 1
+	case EntregaEntregado:
+		return 2
+	case EntregaLeido:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// AcuseMensaje es el estado de entrega de un mensaje para uno de sus destinatarios. Solo
+// avanza: un acuse de entrega que llega tras el de lectura no lo hace retroceder, lo que
+// permite aplicar los acuses en cualquier orden en todos los nodos.
+type AcuseMensaje struct {
+	mensajeID     uuid.UUID
+	usuarioID     uuid.UUID // Destinatario
+	estado        EstadoEntrega
+	actualizadoAt time.Time // Momento en que se alcanzó el estado
+}
+
+// NewAcuseMensaje crea un AcuseMensaje validando sus invariantes
+func NewAcuseMensaje(mensajeID, usuarioID uuid.UUID, estado EstadoEntrega, at time.Time) (*AcuseMensaje, error) {
+	if mensajeID == uuid.Nil {
+		return nil, ErrAcuseMensajeIDNil
+	}
+	if usuarioID == uuid.Nil {
+		return nil, ErrAcuseUsuarioIDNil
+	}
+	if !estado.Valid() {
+		return nil, ErrAcuseEstadoInvalido
+	}
+	if at.IsZero() {
+		return nil, ErrAcuseFechaZero
+	}
+	return &AcuseMensaje{
+		mensajeID:     mensajeID,
+		usuarioID:     usuarioID,
+		estado:        estado,
+		actualizadoAt: at,
+	}, nil
+}
+
+// Getters
+func (a *AcuseMensaje) MensajeID() uuid.UUID     { return a.mensajeID }
+func (a *AcuseMensaje) UsuarioID() uuid.UUID     { return a.usuarioID }
+func (a *AcuseMensaje) Estado() EstadoEntrega    { return a.estado }
+func (a *AcuseMensaje) ActualizadoAt() time.Time { return a.actualizadoAt }
+
+// Avanzar lleva el acuse al estado indicado si es posterior al actual. Devuelve false,
+// sin cambios, si el acuse ya estaba en ese estado o en uno posterior.
+func (a *AcuseMensaje) Avanzar(estado EstadoEntrega, at time.Time) (bool, error) {
+	if !estado.Valid() {
+		return false, ErrAcuseEstadoInvalido
+	}
+	if at.IsZero() {
+		return false, ErrAcuseFechaZero
+	}
+	if !estado.Supera(a.estado) {
+		return false, nil
+	}
+	a.estado = estado
+	a.actualizadoAt = at
+	return true, nil
+}
@@ -0,0 +1,85 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestEstadoEntrega(t *testing.T) {
+	for _, e := range []model.EstadoEntrega{model.EntregaEnviado, model.EntregaEntregado, model.EntregaLeido} {
+		if !e.Valid() {
+			t.Errorf("%s debe ser válido", e)
+		}
+	}
+	if model.EstadoEntrega("PERDIDO").Valid() {
+		t.Error("PERDIDO no debe ser válido")
+	}
+	if !model.EntregaLeido.Supera(model.EntregaEntregado) || !model.EntregaEntregado.Supera(model.EntregaEnviado) {
+		t.Error("los estados deben ordenarse ENVIADO < ENTREGADO < LEIDO")
+	}
+	if model.EntregaEntregado.Supera(model.EntregaLeido) || model.EntregaLeido.Supera(model.EntregaLeido) {
+		t.Error("un estado no supera a uno posterior ni a sí mismo")
+	}
+}
+
+func TestNewAcuseMensaje(t *testing.T) {
+	mensajeID, usuarioID := uuid.New(), uuid.New()
+	at := time.Now().UTC()
+
+	a, err := model.NewAcuseMensaje(mensajeID, usuarioID, model.EntregaEntregado, at)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if a.MensajeID() != mensajeID || a.UsuarioID() != usuarioID || a.Estado() != model.EntregaEntregado || !a.ActualizadoAt().Equal(at) {
+		t.Errorf("campos inesperados: %v %v %s %v", a.MensajeID(), a.UsuarioID(), a.Estado(), a.ActualizadoAt())
+	}
+
+	casos := []struct {
+		nombre               string
+		mensajeID, usuarioID uuid.UUID
+		estado               model.EstadoEntrega
+		at                   time.Time
+		esperado             error
+	}{
+		{"mensaje nil", uuid.Nil, usuarioID, model.EntregaLeido, at, model.ErrAcuseMensajeIDNil},
+		{"usuario nil", mensajeID, uuid.Nil, model.EntregaLeido, at, model.ErrAcuseUsuarioIDNil},
+		{"estado inválido", mensajeID, usuarioID, "", at, model.ErrAcuseEstadoInvalido},
+		{"fecha cero", mensajeID, usuarioID, model.EntregaLeido, time.Time{}, model.ErrAcuseFechaZero},
+	}
+	for _, c := range casos {
+		if _, err := model.NewAcuseMensaje(c.mensajeID, c.usuarioID, c.estado, c.at); err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
+
+func TestAcuseMensaje_Avanzar(t *testing.T) {
+	at := time.Now().UTC()
+	a, _ := model.NewAcuseMensaje(uuid.New(), uuid.New(), model.EntregaEnviado, at)
+
+	leido := at.Add(time.Minute)
+	if cambio, err := a.Avanzar(model.EntregaLeido, leido); err != nil || !cambio {
+		t.Fatalf("Avanzar a LEIDO: cambio=%v err=%v", cambio, err)
+	}
+	if a.Estado() != model.EntregaLeido || !a.ActualizadoAt().Equal(leido) {
+		t.Errorf("esperado LEIDO en %v, obtuvo %s en %v", leido, a.Estado(), a.ActualizadoAt())
+	}
+
+	// Un acuse de entrega tardío no hace retroceder al de lectura
+	if cambio, err := a.Avanzar(model.EntregaEntregado, leido.Add(time.Minute)); err != nil || cambio {
+		t.Errorf("Avanzar hacia atrás: cambio=%v err=%v", cambio, err)
+	}
+	if a.Estado() != model.EntregaLeido || !a.ActualizadoAt().Equal(leido) {
+		t.Errorf("el acuse no debe cambiar, obtuvo %s en %v", a.Estado(), a.ActualizadoAt())
+	}
+
+	if _, err := a.Avanzar("OTRO", leido); err != model.ErrAcuseEstadoInvalido {
+		t.Errorf("estado inválido: esperado %v, obtuvo %v", model.ErrAcuseEstadoInvalido, err)
+	}
+	if _, err := a.Avanzar(model.EntregaLeido, time.Time{}); err != model.ErrAcuseFechaZero {
+		t.Errorf("fecha cero: esperado %v, obtuvo %v", model.ErrAcuseFechaZero, err)
+	}
+}
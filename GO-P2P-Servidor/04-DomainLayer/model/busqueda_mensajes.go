@@ -0,0 +1,72 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para las búsquedas de mensajes
+var (
+	ErrBusquedaVacia         = errors.New("la búsqueda no contiene ningún término válido")
+	ErrRangoBusquedaInvalido = errors.New("la fecha inicial de la búsqueda es posterior a la final")
+)
+
+// LongitudMinimaTermino es el número mínimo de caracteres de un término de búsqueda.
+// Coincide con innodb_ft_min_token_size por defecto: MySQL no indexa palabras más
+// cortas y el índice en memoria las descarta igual para que ambos backends coincidan.
+const LongitudMinimaTermino = 3
+
+// FiltroBusqueda restringe una búsqueda de mensajes. Los campos con su valor cero no
+// filtran; el rango de fechas es [Desde, Hasta).
+type FiltroBusqueda struct {
+	RemitenteID uuid.UUID
+	CanalID     uuid.UUID
+	Desde       time.Time
+	Hasta       time.Time
+	ConAdjunto  bool // Solo mensajes con archivo adjunto
+}
+
+// Validar comprueba que el rango de fechas del filtro sea coherente
+func (f FiltroBusqueda) Validar() error {
+	if !f.Desde.IsZero() && !f.Hasta.IsZero() && f.Desde.After(f.Hasta) {
+		return ErrRangoBusquedaInvalido
+	}
+	return nil
+}
+
+// plegarAcentos sustituye las vocales acentuadas y la eñe por su letra base, como hace
+// la intercalación de MySQL al comparar palabras
+var plegarAcentos = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// TerminosBusqueda divide un texto en los términos que indexa y busca el sistema:
+// palabras de letras y dígitos en minúsculas y sin acentos, de al menos
+// LongitudMinimaTermino caracteres, sin repetir y en el orden en que aparecen.
+// Se usa tanto para indexar el contenido de los mensajes como para las consultas.
+func TerminosBusqueda(texto string) []string {
+	palabras := strings.FieldsFunc(plegarAcentos.Replace(strings.ToLower(texto)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terminos []string
+	vistos := make(map[string]bool, len(palabras))
+	for _, p := range palabras {
+		if utf8.RuneCountInString(p) < LongitudMinimaTermino || vistos[p] {
+			continue
+		}
+		vistos[p] = true
+		terminos = append(terminos, p)
+	}
+	return terminos
+}
@@ -0,0 +1,40 @@
+package model_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"model"
+)
+
+func TestTerminosBusqueda(t *testing.T) {
+	casos := []struct {
+		texto    string
+		esperado []string
+	}{
+		{"Reunión del LUNES, reunión a las 10:30", []string{"reunion", "del", "lunes", "las"}},
+		{"¿Año nuevo? ¡Pingüino!", []string{"ano", "nuevo", "pinguino"}},
+		{"informe_2025-v2.pdf", []string{"informe", "2025", "pdf"}},
+		{"a de ok", nil},
+		{"", nil},
+	}
+	for _, c := range casos {
+		if obtenido := model.TerminosBusqueda(c.texto); !reflect.DeepEqual(obtenido, c.esperado) {
+			t.Errorf("TerminosBusqueda(%q) = %v, esperado %v", c.texto, obtenido, c.esperado)
+		}
+	}
+}
+
+func TestFiltroBusqueda_Validar(t *testing.T) {
+	ahora := time.Now()
+	if err := (model.FiltroBusqueda{}).Validar(); err != nil {
+		t.Errorf("el filtro vacío debe ser válido, obtuvo %v", err)
+	}
+	if err := (model.FiltroBusqueda{Desde: ahora.Add(-time.Hour), Hasta: ahora}).Validar(); err != nil {
+		t.Errorf("rango válido rechazado: %v", err)
+	}
+	if err := (model.FiltroBusqueda{Desde: ahora, Hasta: ahora.Add(-time.Hour)}).Validar(); err != model.ErrRangoBusquedaInvalido {
+		t.Errorf("esperado %v, obtuvo %v", model.ErrRangoBusquedaInvalido, err)
+	}
+}
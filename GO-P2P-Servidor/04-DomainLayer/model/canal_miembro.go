@@ -18,6 +18,7 @@ type CanalMiembro struct {
 	canalID   uuid.UUID
 	usuarioID uuid.UUID
 	rol       string // "owner", "member", etc.
+	version   HLC    // Versión HLC del alta o cambio de rol
 }
 
 // NewCanalMiembro crea un nuevo CanalMiembro validando sus invariantes
@@ -45,3 +46,9 @@ func NewCanalMiembro(
 func (c *CanalMiembro) CanalID() uuid.UUID   { return c.canalID }
 func (c *CanalMiembro) UsuarioID() uuid.UUID { return c.usuarioID }
 func (c *CanalMiembro) Rol() string          { return c.rol }
+func (c *CanalMiembro) Version() HLC         { return c.version }
+
+// SetVersion asigna la marca HLC del alta o cambio de rol del miembro
+func (c *CanalMiembro) SetVersion(version HLC) {
+	c.version = version
+}
@@ -19,6 +19,7 @@ type CanalServidor struct {
     nombre      string
     descripcion string
     tipo        CanalTipo
+    version     HLC // Versión HLC de la última modificación replicada
 }
 
 // NewCanalServidor crea un CanalServidor validando sus invariantes.
@@ -49,3 +50,9 @@ func (c *CanalServidor) ID() uuid.UUID  { return c.id }
 func (c *CanalServidor) Nombre() string { return c.nombre }
 func (c *CanalServidor) Descripcion() string { return c.descripcion }
 func (c *CanalServidor) Tipo() CanalTipo { return c.tipo }
+func (c *CanalServidor) Version() HLC { return c.version }
+
+// SetVersion asigna la marca HLC de la última modificación del canal
+func (c *CanalServidor) SetVersion(version HLC) {
+    c.version = version
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para Cursor
+var (
+	ErrCursorInvalido = errors.New("cursor de paginación inválido")
+)
+
+// Cursor señala una posición en un listado ordenado por (timestamp, id). Es la clave
+// de la paginación por conjunto de claves (keyset): la página siguiente continúa justo
+// después del cursor aunque entretanto se inserten o borren filas, sin los saltos ni
+// repeticiones de LIMIT/OFFSET y sin recorrer las filas anteriores.
+type Cursor struct {
+	timestamp time.Time
+	id        uuid.UUID
+}
+
+// NewCursor crea el cursor de la fila con el timestamp y el id dados
+func NewCursor(timestamp time.Time, id uuid.UUID) Cursor {
+	return Cursor{timestamp: timestamp.UTC(), id: id}
+}
+
+// Getters
+func (c Cursor) Timestamp() time.Time { return c.timestamp }
+func (c Cursor) ID() uuid.UUID        { return c.id }
+
+// IsZero indica si el cursor no señala ninguna fila: la consulta empieza en un extremo.
+func (c Cursor) IsZero() bool {
+	return c.id == uuid.Nil && c.timestamp.IsZero()
+}
+
+// String codifica el cursor como una cadena opaca apta para URLs y mensajes de cliente.
+// Los clientes no deben interpretarla: solo devolverla para pedir la página contigua.
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	plano := strconv.FormatInt(c.timestamp.UnixNano(), 10) + "_" + c.id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(plano))
+}
+
+// ParseCursor reconstruye un cursor a partir de su representación String.
+// Una cadena vacía produce el cursor cero.
+func ParseCursor(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	plano, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrCursorInvalido
+	}
+	nanosStr, idStr, ok := strings.Cut(string(plano), "_")
+	if !ok {
+		return Cursor{}, ErrCursorInvalido
+	}
+	nanos, err := strconv.ParseInt(nanosStr, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrCursorInvalido
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil || id == uuid.Nil {
+		return Cursor{}, ErrCursorInvalido
+	}
+	return NewCursor(time.Unix(0, nanos), id), nil
+}
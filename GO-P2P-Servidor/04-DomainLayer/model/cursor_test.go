@@ -0,0 +1,45 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestCursor_StringYParse(t *testing.T) {
+	id := uuid.New()
+	momento := time.Date(2025, 5, 7, 10, 30, 0, 123456789, time.FixedZone("CEST", 2*3600))
+	cursor := model.NewCursor(momento, id)
+
+	leido, err := model.ParseCursor(cursor.String())
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	if !leido.Timestamp().Equal(momento) || leido.ID() != id {
+		t.Errorf("cursor leído %v/%v, esperado %v/%v", leido.Timestamp(), leido.ID(), momento, id)
+	}
+	if leido.Timestamp().Location() != time.UTC {
+		t.Errorf("el timestamp del cursor debe estar en UTC, obtuvo %v", leido.Timestamp().Location())
+	}
+}
+
+func TestCursor_Cero(t *testing.T) {
+	var cursor model.Cursor
+	if !cursor.IsZero() || cursor.String() != "" {
+		t.Errorf("el cursor cero debe ser vacío, obtuvo %q", cursor.String())
+	}
+	leido, err := model.ParseCursor("")
+	if err != nil || !leido.IsZero() {
+		t.Errorf("ParseCursor(\"\") = %v, %v; esperado cursor cero", leido, err)
+	}
+}
+
+func TestParseCursor_Invalido(t *testing.T) {
+	for _, s := range []string{"no-es-base64!", "c2luLXNlcGFyYWRvcg", "YWJjX2RlZg", "MV8wMDAwMDAwMC0wMDAwLTAwMDAtMDAwMC0wMDAwMDAwMDAwMDA"} {
+		if _, err := model.ParseCursor(s); err != model.ErrCursorInvalido {
+			t.Errorf("ParseCursor(%q): esperado ErrCursorInvalido, obtuvo %v", s, err)
+		}
+	}
+}
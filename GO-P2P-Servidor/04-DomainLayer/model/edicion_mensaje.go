@@ -0,0 +1,94 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para EdicionMensaje
+var (
+	ErrEdicionIDNil          = errors.New("id de edición inválido")
+	ErrEdicionMensajeIDNil   = errors.New("id del mensaje editado inválido")
+	ErrEdicionEditorIDNil    = errors.New("id del editor inválido")
+	ErrEdicionTipoInvalido   = errors.New("tipo de edición inválido")
+	ErrEdicionContenidoVacio = errors.New("contenido anterior de la edición vacío")
+	ErrEdicionFechaZero      = errors.New("fecha de edición no puede ser cero")
+)
+
+// TipoEdicion distingue los cambios registrados en el historial de un mensaje
+type TipoEdicion string
+
+const (
+	// EdicionContenido registra una modificación del texto del mensaje
+	EdicionContenido TipoEdicion = "EDICION"
+	// EdicionBorrado registra el borrado lógico del mensaje
+	EdicionBorrado TipoEdicion = "ELIMINACION"
+)
+
+// Valid comprueba que el TipoEdicion sea uno de los valores admitidos
+func (t TipoEdicion) Valid() bool {
+	return t == EdicionContenido || t == EdicionBorrado
+}
+
+// EdicionMensaje es una entrada del historial de un mensaje: el contenido que tenía
+// antes de una edición o del borrado, quién hizo el cambio y cuándo.
+type EdicionMensaje struct {
+	id                uuid.UUID
+	mensajeID         uuid.UUID
+	editorID          uuid.UUID // Autor del mensaje o administrador del canal
+	tipo              TipoEdicion
+	contenidoAnterior string
+	fecha             time.Time
+	version           HLC // Versión que adquirió el mensaje con este cambio
+}
+
+// NewEdicionMensaje crea una EdicionMensaje validando sus invariantes
+func NewEdicionMensaje(
+	id, mensajeID, editorID uuid.UUID,
+	tipo TipoEdicion,
+	contenidoAnterior string,
+	fecha time.Time,
+) (*EdicionMensaje, error) {
+	if id == uuid.Nil {
+		return nil, ErrEdicionIDNil
+	}
+	if mensajeID == uuid.Nil {
+		return nil, ErrEdicionMensajeIDNil
+	}
+	if editorID == uuid.Nil {
+		return nil, ErrEdicionEditorIDNil
+	}
+	if !tipo.Valid() {
+		return nil, ErrEdicionTipoInvalido
+	}
+	if contenidoAnterior == "" {
+		return nil, ErrEdicionContenidoVacio
+	}
+	if fecha.IsZero() {
+		return nil, ErrEdicionFechaZero
+	}
+	return &EdicionMensaje{
+		id:                id,
+		mensajeID:         mensajeID,
+		editorID:          editorID,
+		tipo:              tipo,
+		contenidoAnterior: contenidoAnterior,
+		fecha:             fecha,
+	}, nil
+}
+
+// Getters
+func (e *EdicionMensaje) ID() uuid.UUID             { return e.id }
+func (e *EdicionMensaje) MensajeID() uuid.UUID      { return e.mensajeID }
+func (e *EdicionMensaje) EditorID() uuid.UUID       { return e.editorID }
+func (e *EdicionMensaje) Tipo() TipoEdicion         { return e.tipo }
+func (e *EdicionMensaje) ContenidoAnterior() string { return e.contenidoAnterior }
+func (e *EdicionMensaje) Fecha() time.Time          { return e.fecha }
+func (e *EdicionMensaje) Version() HLC              { return e.version }
+
+// SetVersion asigna la versión HLC que adquirió el mensaje con este cambio
+func (e *EdicionMensaje) SetVersion(version HLC) {
+	e.version = version
+}
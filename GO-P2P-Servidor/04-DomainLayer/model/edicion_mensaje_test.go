@@ -0,0 +1,63 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewEdicionMensaje_Success(t *testing.T) {
+	id, mensajeID, editorID := uuid.New(), uuid.New(), uuid.New()
+	fecha := time.Now().UTC().Truncate(time.Second)
+
+	e, err := model.NewEdicionMensaje(id, mensajeID, editorID, model.EdicionContenido, "texto original", fecha)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if e.ID() != id || e.MensajeID() != mensajeID || e.EditorID() != editorID {
+		t.Errorf("IDs: obtuvo %v %v %v", e.ID(), e.MensajeID(), e.EditorID())
+	}
+	if e.Tipo() != model.EdicionContenido {
+		t.Errorf("Tipo: esperado %v, obtuvo %v", model.EdicionContenido, e.Tipo())
+	}
+	if e.ContenidoAnterior() != "texto original" {
+		t.Errorf("ContenidoAnterior: obtuvo %q", e.ContenidoAnterior())
+	}
+	if !e.Fecha().Equal(fecha) {
+		t.Errorf("Fecha: esperado %v, obtuvo %v", fecha, e.Fecha())
+	}
+	if !e.Version().IsZero() {
+		t.Errorf("Version: esperaba cero, obtuvo %v", e.Version())
+	}
+}
+
+func TestNewEdicionMensaje_Validation(t *testing.T) {
+	fecha := time.Now()
+	cases := []struct {
+		name     string
+		id       uuid.UUID
+		mensaje  uuid.UUID
+		editor   uuid.UUID
+		tipo     model.TipoEdicion
+		anterior string
+		fecha    time.Time
+		wantErr  error
+	}{
+		{"id nil", uuid.Nil, uuid.New(), uuid.New(), model.EdicionContenido, "x", fecha, model.ErrEdicionIDNil},
+		{"mensaje nil", uuid.New(), uuid.Nil, uuid.New(), model.EdicionContenido, "x", fecha, model.ErrEdicionMensajeIDNil},
+		{"editor nil", uuid.New(), uuid.New(), uuid.Nil, model.EdicionContenido, "x", fecha, model.ErrEdicionEditorIDNil},
+		{"tipo inválido", uuid.New(), uuid.New(), uuid.New(), "OTRO", "x", fecha, model.ErrEdicionTipoInvalido},
+		{"contenido vacío", uuid.New(), uuid.New(), uuid.New(), model.EdicionBorrado, "", fecha, model.ErrEdicionContenidoVacio},
+		{"fecha cero", uuid.New(), uuid.New(), uuid.New(), model.EdicionBorrado, "x", time.Time{}, model.ErrEdicionFechaZero},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := model.NewEdicionMensaje(c.id, c.mensaje, c.editor, c.tipo, c.anterior, c.fecha)
+			if err != c.wantErr {
+				t.Errorf("%s: esperado %v, obtuvo %v", c.name, c.wantErr, err)
+			}
+		})
+	}
+}
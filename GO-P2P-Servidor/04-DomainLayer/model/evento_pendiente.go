@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para EventoPendiente
+var (
+	ErrEventoPendienteIDNil        = errors.New("id del evento pendiente inválido")
+	ErrEventoPendienteUsuarioIDNil = errors.New("id del destinatario del evento pendiente inválido")
+	ErrEventoPendienteEventoVacio  = errors.New("tipo del evento pendiente vacío")
+	ErrEventoPendienteDatosVacios  = errors.New("datos del evento pendiente vacíos")
+	ErrEventoPendienteFechaZero    = errors.New("fecha del evento pendiente no puede ser cero")
+)
+
+// EventoPendiente es un evento de cliente que no se pudo entregar porque el usuario no
+// estaba conectado. Se guarda en la cola del usuario hasta que, tras volver a entrar,
+// confirma haberlo recibido. La cola se recorre en el orden (fecha, id); el id es un
+// UUID v7, así que los eventos encolados en el mismo instante conservan su orden.
+type EventoPendiente struct {
+	id        uuid.UUID
+	usuarioID uuid.UUID // Destinatario
+	evento    string    // Tipo de evento de cliente (MENSAJE, ACUSE, ...)
+	datos     []byte    // Datos del evento en JSON, tal como se habrían enviado
+	fecha     time.Time // Momento en que se encoló
+}
+
+// NewEventoPendiente crea un EventoPendiente validando sus invariantes
+func NewEventoPendiente(id, usuarioID uuid.UUID, evento string, datos []byte, fecha time.Time) (*EventoPendiente, error) {
+	if id == uuid.Nil {
+		return nil, ErrEventoPendienteIDNil
+	}
+	if usuarioID == uuid.Nil {
+		return nil, ErrEventoPendienteUsuarioIDNil
+	}
+	if evento == "" {
+		return nil, ErrEventoPendienteEventoVacio
+	}
+	if len(datos) == 0 {
+		return nil, ErrEventoPendienteDatosVacios
+	}
+	if fecha.IsZero() {
+		return nil, ErrEventoPendienteFechaZero
+	}
+	return &EventoPendiente{
+		id:        id,
+		usuarioID: usuarioID,
+		evento:    evento,
+		datos:     datos,
+		fecha:     fecha.UTC(),
+	}, nil
+}
+
+// Getters
+func (e *EventoPendiente) ID() uuid.UUID        { return e.id }
+func (e *EventoPendiente) UsuarioID() uuid.UUID { return e.usuarioID }
+func (e *EventoPendiente) Evento() string       { return e.evento }
+func (e *EventoPendiente) Datos() []byte        { return e.datos }
+func (e *EventoPendiente) Fecha() time.Time     { return e.fecha }
+
+// Posicion devuelve el cursor del evento en la cola de su destinatario
+func (e *EventoPendiente) Posicion() Cursor {
+	return NewCursor(e.fecha, e.id)
+}
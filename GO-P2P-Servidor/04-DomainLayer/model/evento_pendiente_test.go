@@ -0,0 +1,49 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewEventoPendiente(t *testing.T) {
+	id, usuarioID := uuid.Must(uuid.NewV7()), uuid.New()
+	datos := []byte(`{"id":"x"}`)
+	fecha := time.Date(2025, 5, 7, 10, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+
+	e, err := model.NewEventoPendiente(id, usuarioID, "MENSAJE", datos, fecha)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if e.ID() != id || e.UsuarioID() != usuarioID || e.Evento() != "MENSAJE" || string(e.Datos()) != string(datos) {
+		t.Errorf("campos inesperados: %v %v %s %s", e.ID(), e.UsuarioID(), e.Evento(), e.Datos())
+	}
+	if e.Fecha().Location() != time.UTC || !e.Fecha().Equal(fecha) {
+		t.Errorf("la fecha debe guardarse en UTC, obtuvo %v", e.Fecha())
+	}
+	if p := e.Posicion(); p.ID() != id || !p.Timestamp().Equal(fecha) {
+		t.Errorf("posición inesperada: %v %v", p.ID(), p.Timestamp())
+	}
+
+	casos := []struct {
+		nombre        string
+		id, usuarioID uuid.UUID
+		evento        string
+		datos         []byte
+		fecha         time.Time
+		esperado      error
+	}{
+		{"id nil", uuid.Nil, usuarioID, "MENSAJE", datos, fecha, model.ErrEventoPendienteIDNil},
+		{"usuario nil", id, uuid.Nil, "MENSAJE", datos, fecha, model.ErrEventoPendienteUsuarioIDNil},
+		{"evento vacío", id, usuarioID, "", datos, fecha, model.ErrEventoPendienteEventoVacio},
+		{"datos vacíos", id, usuarioID, "MENSAJE", nil, fecha, model.ErrEventoPendienteDatosVacios},
+		{"fecha cero", id, usuarioID, "MENSAJE", datos, time.Time{}, model.ErrEventoPendienteFechaZero},
+	}
+	for _, c := range casos {
+		if _, err := model.NewEventoPendiente(c.id, c.usuarioID, c.evento, c.datos, c.fecha); err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
@@ -8,6 +8,8 @@ const (
 	EventoMensaje EventoTipo = "MENSAJE"
 	EventoArchivo EventoTipo = "ARCHIVO"
 	EventoCanal   EventoTipo = "CANAL"
+	// EventoConflicto registra conflictos de replicación resueltos automáticamente
+	EventoConflicto EventoTipo = "CONFLICTO"
 )
 
 // Valid comprueba que el EventoTipo sea uno de los valores admitidos.
@@ -16,7 +18,8 @@ func (e EventoTipo) Valid() bool {
 	case EventoLogin,
 		EventoMensaje,
 		EventoArchivo,
-		EventoCanal:
+		EventoCanal,
+		EventoConflicto:
 		return true
 	default:
 		return false
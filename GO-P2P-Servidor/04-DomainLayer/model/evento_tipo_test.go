@@ -8,6 +8,7 @@ func TestEventoTipo_Valid(t *testing.T) {
         EventoMensaje,
         EventoArchivo,
         EventoCanal,
+        EventoConflicto,
     }
     for _, e := range validos {
         if !e.Valid() {
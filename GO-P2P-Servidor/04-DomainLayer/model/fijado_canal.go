@@ -0,0 +1,159 @@
+package model
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para FijadoCanal
+var (
+	ErrFijadoCanalIDNil   = errors.New("id del canal del elemento fijado inválido")
+	ErrFijadoSinObjetivo  = errors.New("el elemento fijado debe ser un mensaje o un enlace, no ambos ni ninguno")
+	ErrFijadoURLInvalida  = errors.New("el enlace fijado debe ser una URL http o https")
+	ErrFijadoTituloLargo  = errors.New("el título del enlace fijado es demasiado largo")
+	ErrFijadoUsuarioIDNil = errors.New("id del usuario que fija inválido")
+	ErrFijadoFechaZero    = errors.New("fecha del elemento fijado no puede ser cero")
+	ErrFijadoRetirado     = errors.New("el elemento ya no está fijado")
+)
+
+// Límites de los enlaces fijados
+const (
+	LongitudMaximaTituloFijado = 200  // Runas del título
+	LongitudMaximaURLFijada    = 2048 // Bytes de la URL
+)
+
+// FijadoCanal es un mensaje o un enlace fijado en un canal. Su id se deriva del canal y
+// del mensaje o la URL, así que el mismo elemento fijado en dos nodos es la misma
+// entidad; al desfijarlo se conserva con la marca de retirada para que la replicación
+// no lo vuelva a fijar.
+type FijadoCanal struct {
+	id          uuid.UUID
+	canalID     uuid.UUID
+	mensajeID   uuid.UUID // uuid.Nil si es un enlace
+	url         string    // Vacío si es un mensaje
+	titulo      string    // Opcional: título del enlace
+	fijadoPor   uuid.UUID
+	fijadoEn    time.Time
+	retiradoPor uuid.UUID // uuid.Nil mientras está fijado
+	retiradoEn  time.Time // Cero mientras está fijado
+	version     HLC       // Versión para last-writer-wins entre nodos
+}
+
+// IDFijado devuelve el id del elemento fijado en un canal: un mensaje o, con mensajeID
+// uuid.Nil, una URL
+func IDFijado(canalID, mensajeID uuid.UUID, enlace string) uuid.UUID {
+	if mensajeID != uuid.Nil {
+		return uuid.NewSHA1(canalID, []byte("mensaje:"+mensajeID.String()))
+	}
+	return uuid.NewSHA1(canalID, []byte("enlace:"+enlace))
+}
+
+// NewFijadoCanal crea un FijadoCanal vigente validando sus invariantes. Fija un mensaje
+// del canal o, con mensajeID uuid.Nil, un enlace con su título opcional.
+func NewFijadoCanal(
+	canalID, mensajeID uuid.UUID,
+	enlace, titulo string,
+	fijadoPor uuid.UUID,
+	fijadoEn time.Time,
+) (*FijadoCanal, error) {
+	if canalID == uuid.Nil {
+		return nil, ErrFijadoCanalIDNil
+	}
+	titulo = strings.TrimSpace(titulo)
+	if (mensajeID == uuid.Nil) == (enlace == "") || (mensajeID != uuid.Nil && titulo != "") {
+		return nil, ErrFijadoSinObjetivo
+	}
+	if enlace != "" && !urlValida(enlace) {
+		return nil, ErrFijadoURLInvalida
+	}
+	if utf8.RuneCountInString(titulo) > LongitudMaximaTituloFijado {
+		return nil, ErrFijadoTituloLargo
+	}
+	if fijadoPor == uuid.Nil {
+		return nil, ErrFijadoUsuarioIDNil
+	}
+	if fijadoEn.IsZero() {
+		return nil, ErrFijadoFechaZero
+	}
+	return &FijadoCanal{
+		id:        IDFijado(canalID, mensajeID, enlace),
+		canalID:   canalID,
+		mensajeID: mensajeID,
+		url:       enlace,
+		titulo:    titulo,
+		fijadoPor: fijadoPor,
+		fijadoEn:  fijadoEn.UTC(),
+	}, nil
+}
+
+// urlValida indica si el enlace es una URL absoluta http o https con host
+func urlValida(enlace string) bool {
+	if len(enlace) > LongitudMaximaURLFijada {
+		return false
+	}
+	u, err := url.Parse(enlace)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Getters
+func (f *FijadoCanal) ID() uuid.UUID          { return f.id }
+func (f *FijadoCanal) CanalID() uuid.UUID     { return f.canalID }
+func (f *FijadoCanal) MensajeID() uuid.UUID   { return f.mensajeID }
+func (f *FijadoCanal) URL() string            { return f.url }
+func (f *FijadoCanal) Titulo() string         { return f.titulo }
+func (f *FijadoCanal) FijadoPor() uuid.UUID   { return f.fijadoPor }
+func (f *FijadoCanal) FijadoEn() time.Time    { return f.fijadoEn }
+func (f *FijadoCanal) RetiradoPor() uuid.UUID { return f.retiradoPor }
+func (f *FijadoCanal) RetiradoEn() time.Time  { return f.retiradoEn }
+func (f *FijadoCanal) Version() HLC           { return f.version }
+
+// EsEnlace indica si el elemento fijado es un enlace y no un mensaje
+func (f *FijadoCanal) EsEnlace() bool { return f.mensajeID == uuid.Nil }
+
+// Vigente indica si el elemento sigue fijado
+func (f *FijadoCanal) Vigente() bool { return f.retiradoEn.IsZero() }
+
+// UltimoCambio devuelve el momento en que se desfijó el elemento o, si está vigente,
+// en que se fijó
+func (f *FijadoCanal) UltimoCambio() time.Time {
+	if f.Vigente() {
+		return f.fijadoEn
+	}
+	return f.retiradoEn
+}
+
+// Retirar desfija el elemento
+func (f *FijadoCanal) Retirar(por uuid.UUID, at time.Time) error {
+	if !f.Vigente() {
+		return ErrFijadoRetirado
+	}
+	if por == uuid.Nil {
+		return ErrFijadoUsuarioIDNil
+	}
+	if at.IsZero() {
+		return ErrFijadoFechaZero
+	}
+	f.retiradoPor = por
+	f.retiradoEn = at.UTC()
+	return nil
+}
+
+// SetRetirada restaura quién y cuándo desfijó el elemento, leídos de la base de datos o
+// de otro nodo; cero lo deja fijado
+func (f *FijadoCanal) SetRetirada(por uuid.UUID, at time.Time) {
+	f.retiradoPor = por
+	f.retiradoEn = at
+}
+
+// SetVersion asigna la versión HLC del último cambio del elemento fijado
+func (f *FijadoCanal) SetVersion(version HLC) {
+	f.version = version
+}
@@ -0,0 +1,88 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewFijadoCanal(t *testing.T) {
+	canalID, mensajeID, usuarioID := uuid.New(), uuid.New(), uuid.New()
+	fecha := time.Now()
+
+	mensaje, err := model.NewFijadoCanal(canalID, mensajeID, "", "", usuarioID, fecha)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if mensaje.EsEnlace() || !mensaje.Vigente() || mensaje.FijadoPor() != usuarioID || !mensaje.UltimoCambio().Equal(fecha) {
+		t.Errorf("campos inesperados: %v %v %v", mensaje.MensajeID(), mensaje.FijadoPor(), mensaje.FijadoEn())
+	}
+	enlace, err := model.NewFijadoCanal(canalID, uuid.Nil, "https://ejemplo.com/guia", "  Guía del canal ", usuarioID, fecha)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if !enlace.EsEnlace() || enlace.Titulo() != "Guía del canal" {
+		t.Errorf("enlace inesperado: %q %q", enlace.URL(), enlace.Titulo())
+	}
+
+	// El id solo depende del canal y del elemento, así que coincide entre nodos
+	otro, _ := model.NewFijadoCanal(canalID, mensajeID, "", "", uuid.New(), fecha.Add(time.Hour))
+	if otro.ID() != mensaje.ID() || otro.ID() == enlace.ID() || otro.ID() != model.IDFijado(canalID, mensajeID, "") {
+		t.Error("el id debe derivarse del canal y del elemento fijado")
+	}
+	enOtroCanal, _ := model.NewFijadoCanal(uuid.New(), mensajeID, "", "", usuarioID, fecha)
+	if enOtroCanal.ID() == mensaje.ID() {
+		t.Error("el mismo mensaje fijado en otro canal es otro elemento")
+	}
+
+	casos := []struct {
+		nombre             string
+		canalID, mensajeID uuid.UUID
+		enlace, titulo     string
+		usuarioID          uuid.UUID
+		fecha              time.Time
+		esperado           error
+	}{
+		{"canal nil", uuid.Nil, mensajeID, "", "", usuarioID, fecha, model.ErrFijadoCanalIDNil},
+		{"sin objetivo", canalID, uuid.Nil, "", "", usuarioID, fecha, model.ErrFijadoSinObjetivo},
+		{"mensaje y enlace", canalID, mensajeID, "https://ejemplo.com", "", usuarioID, fecha, model.ErrFijadoSinObjetivo},
+		{"mensaje con título", canalID, mensajeID, "", "título", usuarioID, fecha, model.ErrFijadoSinObjetivo},
+		{"url relativa", canalID, uuid.Nil, "/guia", "", usuarioID, fecha, model.ErrFijadoURLInvalida},
+		{"otro esquema", canalID, uuid.Nil, "javascript:alert(1)", "", usuarioID, fecha, model.ErrFijadoURLInvalida},
+		{"url larga", canalID, uuid.Nil, "https://ejemplo.com/" + strings.Repeat("a", model.LongitudMaximaURLFijada), "", usuarioID, fecha, model.ErrFijadoURLInvalida},
+		{"título largo", canalID, uuid.Nil, "https://ejemplo.com", strings.Repeat("a", model.LongitudMaximaTituloFijado+1), usuarioID, fecha, model.ErrFijadoTituloLargo},
+		{"usuario nil", canalID, mensajeID, "", "", uuid.Nil, fecha, model.ErrFijadoUsuarioIDNil},
+		{"fecha cero", canalID, mensajeID, "", "", usuarioID, time.Time{}, model.ErrFijadoFechaZero},
+	}
+	for _, c := range casos {
+		if _, err := model.NewFijadoCanal(c.canalID, c.mensajeID, c.enlace, c.titulo, c.usuarioID, c.fecha); err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
+
+func TestFijadoCanal_Retirar(t *testing.T) {
+	f, _ := model.NewFijadoCanal(uuid.New(), uuid.New(), "", "", uuid.New(), time.Now())
+	adminID := uuid.New()
+	retirado := time.Now().Add(time.Minute)
+
+	if err := f.Retirar(uuid.Nil, retirado); err != model.ErrFijadoUsuarioIDNil {
+		t.Errorf("esperado %v, obtuvo %v", model.ErrFijadoUsuarioIDNil, err)
+	}
+	if err := f.Retirar(adminID, retirado); err != nil {
+		t.Fatalf("Retirar: %v", err)
+	}
+	if f.Vigente() || f.RetiradoPor() != adminID || !f.UltimoCambio().Equal(retirado) {
+		t.Errorf("retirada inesperada: %v %v", f.RetiradoPor(), f.RetiradoEn())
+	}
+	if err := f.Retirar(adminID, retirado); err != model.ErrFijadoRetirado {
+		t.Errorf("esperado %v, obtuvo %v", model.ErrFijadoRetirado, err)
+	}
+	f.SetRetirada(uuid.Nil, time.Time{})
+	if !f.Vigente() {
+		t.Error("sin marca de retirada el elemento vuelve a estar fijado")
+	}
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para HLC
+var (
+	ErrHLCWallNegativo = errors.New("componente físico del HLC no puede ser negativo")
+	ErrHLCNodoIDNil    = errors.New("nodo del HLC inválido")
+	ErrHLCFormato      = errors.New("formato de HLC inválido, se esperaba wall.logico@nodo")
+)
+
+// HLC es una marca de tiempo de reloj lógico híbrido (Hybrid Logical Clock).
+// Combina el reloj físico (wall, en nanosegundos Unix) con un contador lógico
+// y el nodo que la generó, de modo que dos marcas siempre son comparables
+// y el orden es el mismo en todos los nodos del clúster.
+type HLC struct {
+	wall   int64
+	logico uint32
+	nodoID uuid.UUID
+}
+
+// NewHLC crea una marca HLC validando sus invariantes.
+func NewHLC(wall int64, logico uint32, nodoID uuid.UUID) (HLC, error) {
+	if wall < 0 {
+		return HLC{}, ErrHLCWallNegativo
+	}
+	if nodoID == uuid.Nil {
+		return HLC{}, ErrHLCNodoIDNil
+	}
+	return HLC{wall: wall, logico: logico, nodoID: nodoID}, nil
+}
+
+// Getters
+func (h HLC) Wall() int64       { return h.wall }
+func (h HLC) Logico() uint32    { return h.logico }
+func (h HLC) NodoID() uuid.UUID { return h.nodoID }
+
+// IsZero indica si la marca no ha sido asignada (entidades anteriores al versionado).
+func (h HLC) IsZero() bool {
+	return h.wall == 0 && h.logico == 0 && h.nodoID == uuid.Nil
+}
+
+// Compare devuelve -1, 0 o 1 si h es anterior, igual o posterior a otra.
+// El desempate por nodo hace que el orden sea total y determinista.
+func (h HLC) Compare(otra HLC) int {
+	switch {
+	case h.wall < otra.wall:
+		return -1
+	case h.wall > otra.wall:
+		return 1
+	case h.logico < otra.logico:
+		return -1
+	case h.logico > otra.logico:
+		return 1
+	}
+	return strings.Compare(h.nodoID.String(), otra.nodoID.String())
+}
+
+// After indica si h es estrictamente posterior a otra.
+func (h HLC) After(otra HLC) bool { return h.Compare(otra) > 0 }
+
+// String serializa la marca como "wall.logico@nodo", formato usado en persistencia y en la red.
+func (h HLC) String() string {
+	if h.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d@%s", h.wall, h.logico, h.nodoID)
+}
+
+// ParseHLC reconstruye una marca a partir de su representación String.
+// Una cadena vacía produce la marca cero.
+func ParseHLC(s string) (HLC, error) {
+	if s == "" {
+		return HLC{}, nil
+	}
+	tiempo, nodo, ok := strings.Cut(s, "@")
+	if !ok {
+		return HLC{}, ErrHLCFormato
+	}
+	wallStr, logicoStr, ok := strings.Cut(tiempo, ".")
+	if !ok {
+		return HLC{}, ErrHLCFormato
+	}
+	wall, err := strconv.ParseInt(wallStr, 10, 64)
+	if err != nil {
+		return HLC{}, ErrHLCFormato
+	}
+	logico, err := strconv.ParseUint(logicoStr, 10, 32)
+	if err != nil {
+		return HLC{}, ErrHLCFormato
+	}
+	nodoID, err := uuid.Parse(nodo)
+	if err != nil {
+		return HLC{}, ErrHLCFormato
+	}
+	return NewHLC(wall, uint32(logico), nodoID)
+}
+
+// RelojHLC genera marcas HLC monótonas para un nodo concreto.
+// Es seguro para uso concurrente.
+type RelojHLC struct {
+	mu     sync.Mutex
+	nodoID uuid.UUID
+	ultimo HLC
+	ahora  func() time.Time
+}
+
+// NewRelojHLC crea un reloj HLC para el nodo indicado usando el reloj del sistema.
+func NewRelojHLC(nodoID uuid.UUID) (*RelojHLC, error) {
+	return NewRelojHLCConFuente(nodoID, time.Now)
+}
+
+// NewRelojHLCConFuente crea un reloj HLC con una fuente de tiempo personalizada (útil en pruebas).
+func NewRelojHLCConFuente(nodoID uuid.UUID, ahora func() time.Time) (*RelojHLC, error) {
+	if nodoID == uuid.Nil {
+		return nil, ErrHLCNodoIDNil
+	}
+	return &RelojHLC{nodoID: nodoID, ahora: ahora}, nil
+}
+
+// Now devuelve una nueva marca para un evento local.
+func (r *RelojHLC) Now() HLC {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fisico := r.ahora().UnixNano()
+	if fisico > r.ultimo.wall {
+		r.ultimo = HLC{wall: fisico, logico: 0, nodoID: r.nodoID}
+	} else {
+		r.ultimo = HLC{wall: r.ultimo.wall, logico: r.ultimo.logico + 1, nodoID: r.nodoID}
+	}
+	return r.ultimo
+}
+
+// Update incorpora una marca recibida de otro nodo y devuelve la marca del evento de recepción,
+// que siempre es posterior tanto a la marca local como a la remota.
+func (r *RelojHLC) Update(remota HLC) HLC {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fisico := r.ahora().UnixNano()
+	wall := max(fisico, r.ultimo.wall, remota.wall)
+
+	var logico uint32
+	switch {
+	case wall == r.ultimo.wall && wall == remota.wall:
+		logico = max(r.ultimo.logico, remota.logico) + 1
+	case wall == r.ultimo.wall:
+		logico = r.ultimo.logico + 1
+	case wall == remota.wall:
+		logico = remota.logico + 1
+	}
+
+	r.ultimo = HLC{wall: wall, logico: logico, nodoID: r.nodoID}
+	return r.ultimo
+}
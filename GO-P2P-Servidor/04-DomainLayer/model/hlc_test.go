@@ -0,0 +1,114 @@
+package model_test
+
+import (
+    "testing"
+    "time"
+
+    "github.com/google/uuid"
+    "model"
+)
+
+func TestNewHLC_Errors(t *testing.T) {
+    if _, err := model.NewHLC(-1, 0, uuid.New()); err != model.ErrHLCWallNegativo {
+        t.Errorf("esperado ErrHLCWallNegativo, obtuvo %v", err)
+    }
+    if _, err := model.NewHLC(1, 0, uuid.Nil); err != model.ErrHLCNodoIDNil {
+        t.Errorf("esperado ErrHLCNodoIDNil, obtuvo %v", err)
+    }
+}
+
+func TestHLC_Compare(t *testing.T) {
+    nodoA := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+    nodoB := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+
+    mk := func(wall int64, logico uint32, nodo uuid.UUID) model.HLC {
+        h, err := model.NewHLC(wall, logico, nodo)
+        if err != nil {
+            t.Fatalf("NewHLC: %v", err)
+        }
+        return h
+    }
+
+    casos := []struct {
+        nombre   string
+        a, b     model.HLC
+        esperado int
+    }{
+        {"wall menor", mk(1, 5, nodoB), mk(2, 0, nodoA), -1},
+        {"wall mayor", mk(3, 0, nodoA), mk(2, 9, nodoB), 1},
+        {"logico menor", mk(2, 0, nodoB), mk(2, 1, nodoA), -1},
+        {"desempate por nodo", mk(2, 1, nodoA), mk(2, 1, nodoB), -1},
+        {"iguales", mk(2, 1, nodoA), mk(2, 1, nodoA), 0},
+    }
+    for _, c := range casos {
+        if got := c.a.Compare(c.b); got != c.esperado {
+            t.Errorf("%s: esperado %d, obtuvo %d", c.nombre, c.esperado, got)
+        }
+        if got := c.b.Compare(c.a); got != -c.esperado {
+            t.Errorf("%s (inverso): esperado %d, obtuvo %d", c.nombre, -c.esperado, got)
+        }
+    }
+}
+
+func TestHLC_StringParse(t *testing.T) {
+    h, err := model.NewHLC(1700000000000000000, 7, uuid.New())
+    if err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    parsed, err := model.ParseHLC(h.String())
+    if err != nil {
+        t.Fatalf("ParseHLC: %v", err)
+    }
+    if parsed.Compare(h) != 0 {
+        t.Errorf("esperado %v, obtuvo %v", h, parsed)
+    }
+
+    cero, err := model.ParseHLC("")
+    if err != nil || !cero.IsZero() {
+        t.Errorf("cadena vacía: esperado HLC cero, obtuvo %v (%v)", cero, err)
+    }
+
+    for _, s := range []string{"abc", "1@" + uuid.NewString(), "x.1@" + uuid.NewString(), "1.2@no-uuid"} {
+        if _, err := model.ParseHLC(s); err != model.ErrHLCFormato {
+            t.Errorf("ParseHLC(%q): esperado ErrHLCFormato, obtuvo %v", s, err)
+        }
+    }
+}
+
+func TestRelojHLC_Monotono(t *testing.T) {
+    fijo := time.Unix(100, 0)
+    reloj, err := model.NewRelojHLCConFuente(uuid.New(), func() time.Time { return fijo })
+    if err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+
+    a := reloj.Now()
+    b := reloj.Now()
+    if !b.After(a) {
+        t.Errorf("se esperaba %v posterior a %v con reloj físico detenido", b, a)
+    }
+    if b.Logico() != a.Logico()+1 {
+        t.Errorf("Logico: esperado %d, obtuvo %d", a.Logico()+1, b.Logico())
+    }
+}
+
+func TestRelojHLC_UpdateAdelantaARemota(t *testing.T) {
+    fijo := time.Unix(100, 0)
+    reloj, _ := model.NewRelojHLCConFuente(uuid.New(), func() time.Time { return fijo })
+
+    // Marca remota generada por un nodo con el reloj físico adelantado
+    remota, _ := model.NewHLC(time.Unix(200, 0).UnixNano(), 3, uuid.New())
+    recibida := reloj.Update(remota)
+    if !recibida.After(remota) {
+        t.Errorf("se esperaba %v posterior a la remota %v", recibida, remota)
+    }
+    if siguiente := reloj.Now(); !siguiente.After(recibida) {
+        t.Errorf("se esperaba %v posterior a %v", siguiente, recibida)
+    }
+}
+
+func TestNewRelojHLC_NodoNil(t *testing.T) {
+    if _, err := model.NewRelojHLC(uuid.Nil); err != model.ErrHLCNodoIDNil {
+        t.Errorf("esperado ErrHLCNodoIDNil, obtuvo %v", err)
+    }
+}
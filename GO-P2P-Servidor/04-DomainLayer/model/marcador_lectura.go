@@ -0,0 +1,108 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para MarcadorLectura
+var (
+	ErrMarcadorUsuarioIDNil      = errors.New("id del usuario del marcador de lectura inválido")
+	ErrMarcadorConversacionIDNil = errors.New("id de la conversación del marcador de lectura inválido")
+	ErrMarcadorMensajeIDNil      = errors.New("id del último mensaje leído inválido")
+	ErrMarcadorFechaZero         = errors.New("fecha del marcador de lectura no puede ser cero")
+)
+
+// MarcadorLectura es el último mensaje que un usuario ha leído en una conversación. La
+// conversación es el canal o el chat privado del mensaje o, en los mensajes directos,
+// el otro usuario. Los mensajes posteriores al marcador, en el orden (timestamp, id) de
+// los listados, son los no leídos.
+type MarcadorLectura struct {
+	usuarioID      uuid.UUID
+	conversacionID uuid.UUID
+	posicion       Cursor    // (timestamp, id) del último mensaje leído
+	actualizadoAt  time.Time // Momento en que se movió el marcador
+}
+
+// NewMarcadorLectura crea un MarcadorLectura en el mensaje indicado
+func NewMarcadorLectura(usuarioID, conversacionID uuid.UUID, mensaje *MensajeServidor, at time.Time) (*MarcadorLectura, error) {
+	if mensaje == nil {
+		return nil, ErrMarcadorMensajeIDNil
+	}
+	return RestaurarMarcadorLectura(usuarioID, conversacionID, mensaje.ID(), mensaje.Timestamp(), at)
+}
+
+// RestaurarMarcadorLectura reconstruye un marcador leído de la base de datos a partir
+// del id y el timestamp del último mensaje leído
+func RestaurarMarcadorLectura(usuarioID, conversacionID, mensajeID uuid.UUID, timestamp, at time.Time) (*MarcadorLectura, error) {
+	if usuarioID == uuid.Nil {
+		return nil, ErrMarcadorUsuarioIDNil
+	}
+	if conversacionID == uuid.Nil {
+		return nil, ErrMarcadorConversacionIDNil
+	}
+	if mensajeID == uuid.Nil {
+		return nil, ErrMarcadorMensajeIDNil
+	}
+	if timestamp.IsZero() || at.IsZero() {
+		return nil, ErrMarcadorFechaZero
+	}
+	return &MarcadorLectura{
+		usuarioID:      usuarioID,
+		conversacionID: conversacionID,
+		posicion:       NewCursor(timestamp, mensajeID),
+		actualizadoAt:  at,
+	}, nil
+}
+
+// Getters
+func (m *MarcadorLectura) UsuarioID() uuid.UUID      { return m.usuarioID }
+func (m *MarcadorLectura) ConversacionID() uuid.UUID { return m.conversacionID }
+func (m *MarcadorLectura) MensajeID() uuid.UUID      { return m.posicion.ID() }
+func (m *MarcadorLectura) Timestamp() time.Time      { return m.posicion.Timestamp() }
+func (m *MarcadorLectura) Posicion() Cursor          { return m.posicion }
+func (m *MarcadorLectura) ActualizadoAt() time.Time  { return m.actualizadoAt }
+
+// Cubre indica si el mensaje está en la posición del marcador o antes, es decir, si
+// ya se ha leído
+func (m *MarcadorLectura) Cubre(mensaje *MensajeServidor) bool {
+	ts := mensaje.Timestamp().UTC()
+	if !ts.Equal(m.posicion.Timestamp()) {
+		return ts.Before(m.posicion.Timestamp())
+	}
+	return mensaje.ID().String() <= m.posicion.ID().String()
+}
+
+// Avanzar mueve el marcador al mensaje indicado si es posterior a la posición actual.
+// Devuelve false, sin cambios, si el mensaje ya estaba leído: el marcador nunca
+// retrocede aunque los informes de lectura lleguen desordenados.
+func (m *MarcadorLectura) Avanzar(mensaje *MensajeServidor, at time.Time) (bool, error) {
+	if mensaje == nil || mensaje.ID() == uuid.Nil {
+		return false, ErrMarcadorMensajeIDNil
+	}
+	if at.IsZero() {
+		return false, ErrMarcadorFechaZero
+	}
+	if m.Cubre(mensaje) {
+		return false, nil
+	}
+	m.posicion = NewCursor(mensaje.Timestamp(), mensaje.ID())
+	m.actualizadoAt = at
+	return true, nil
+}
+
+// ConversacionMensaje devuelve la conversación del mensaje vista por el usuario: su
+// canal, su chat privado o, en un mensaje directo, el otro usuario
+func ConversacionMensaje(mensaje *MensajeServidor, usuarioID uuid.UUID) uuid.UUID {
+	switch {
+	case mensaje.CanalID() != uuid.Nil:
+		return mensaje.CanalID()
+	case mensaje.ChatPrivadoID() != uuid.Nil:
+		return mensaje.ChatPrivadoID()
+	case mensaje.RemitenteID() == usuarioID:
+		return mensaje.DestinoUsuarioID()
+	}
+	return mensaje.RemitenteID()
+}
@@ -0,0 +1,99 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewMarcadorLectura(t *testing.T) {
+	usuarioID, canalID := uuid.New(), uuid.New()
+	ts := time.Now().UTC()
+	m, _ := model.NewMensajeCanal(uuid.New(), uuid.New(), canalID, "hola", ts, uuid.Nil)
+
+	marcador, err := model.NewMarcadorLectura(usuarioID, canalID, m, ts)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if marcador.UsuarioID() != usuarioID || marcador.ConversacionID() != canalID || marcador.MensajeID() != m.ID() || !marcador.Timestamp().Equal(ts) {
+		t.Errorf("campos inesperados: %v %v %v %v", marcador.UsuarioID(), marcador.ConversacionID(), marcador.MensajeID(), marcador.Timestamp())
+	}
+	if marcador.Posicion() != model.NewCursor(ts, m.ID()) {
+		t.Errorf("la posición debe ser el cursor del mensaje, obtuvo %v", marcador.Posicion())
+	}
+
+	casos := []struct {
+		nombre                               string
+		usuarioID, conversacionID, mensajeID uuid.UUID
+		ts                                   time.Time
+		esperado                             error
+	}{
+		{"usuario nil", uuid.Nil, canalID, m.ID(), ts, model.ErrMarcadorUsuarioIDNil},
+		{"conversación nil", usuarioID, uuid.Nil, m.ID(), ts, model.ErrMarcadorConversacionIDNil},
+		{"mensaje nil", usuarioID, canalID, uuid.Nil, ts, model.ErrMarcadorMensajeIDNil},
+		{"timestamp cero", usuarioID, canalID, m.ID(), time.Time{}, model.ErrMarcadorFechaZero},
+	}
+	for _, c := range casos {
+		if _, err := model.RestaurarMarcadorLectura(c.usuarioID, c.conversacionID, c.mensajeID, c.ts, ts); err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+	if _, err := model.NewMarcadorLectura(usuarioID, canalID, nil, ts); err != model.ErrMarcadorMensajeIDNil {
+		t.Errorf("sin mensaje: esperado %v, obtuvo %v", model.ErrMarcadorMensajeIDNil, err)
+	}
+}
+
+func TestMarcadorLectura_Avanzar(t *testing.T) {
+	canalID, remitenteID := uuid.New(), uuid.New()
+	ts := time.Now().UTC()
+	mensaje := func(id uuid.UUID, t time.Time) *model.MensajeServidor {
+		m, _ := model.NewMensajeCanal(id, remitenteID, canalID, "hola", t, uuid.Nil)
+		return m
+	}
+	primero := mensaje(uuid.MustParse("00000000-0000-0000-0000-000000000002"), ts)
+	mismoInstante := mensaje(uuid.MustParse("00000000-0000-0000-0000-000000000003"), ts)
+	anterior := mensaje(uuid.MustParse("00000000-0000-0000-0000-000000000009"), ts.Add(-time.Second))
+
+	marcador, _ := model.NewMarcadorLectura(uuid.New(), canalID, primero, ts)
+	if !marcador.Cubre(primero) || !marcador.Cubre(anterior) || marcador.Cubre(mismoInstante) {
+		t.Error("el marcador debe cubrir su mensaje y los anteriores en orden (timestamp, id)")
+	}
+
+	at := ts.Add(time.Minute)
+	if cambio, err := marcador.Avanzar(mismoInstante, at); err != nil || !cambio {
+		t.Fatalf("Avanzar: cambio=%v err=%v", cambio, err)
+	}
+	if marcador.MensajeID() != mismoInstante.ID() || !marcador.ActualizadoAt().Equal(at) {
+		t.Errorf("esperado el marcador en %v, obtuvo %v", mismoInstante.ID(), marcador.MensajeID())
+	}
+	if cambio, err := marcador.Avanzar(anterior, at.Add(time.Minute)); err != nil || cambio {
+		t.Errorf("el marcador no debe retroceder: cambio=%v err=%v", cambio, err)
+	}
+	if _, err := marcador.Avanzar(nil, at); err != model.ErrMarcadorMensajeIDNil {
+		t.Errorf("sin mensaje: esperado %v, obtuvo %v", model.ErrMarcadorMensajeIDNil, err)
+	}
+}
+
+func TestConversacionMensaje(t *testing.T) {
+	ana, bob, canalID, chatID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	ts := time.Now().UTC()
+
+	enCanal, _ := model.NewMensajeCanal(uuid.New(), ana, canalID, "hola", ts, uuid.Nil)
+	enChat, _ := model.NewMensajeChatPrivado(uuid.New(), ana, chatID, "hola", ts, uuid.Nil)
+	directo, _ := model.NewMensajeDirecto(uuid.New(), ana, bob, "hola", ts, uuid.Nil)
+
+	if got := model.ConversacionMensaje(enCanal, bob); got != canalID {
+		t.Errorf("canal: esperado %v, obtuvo %v", canalID, got)
+	}
+	if got := model.ConversacionMensaje(enChat, bob); got != chatID {
+		t.Errorf("chat privado: esperado %v, obtuvo %v", chatID, got)
+	}
+	if got := model.ConversacionMensaje(directo, bob); got != ana {
+		t.Errorf("directo visto por el destinatario: esperado %v, obtuvo %v", ana, got)
+	}
+	if got := model.ConversacionMensaje(directo, ana); got != bob {
+		t.Errorf("directo visto por el remitente: esperado %v, obtuvo %v", bob, got)
+	}
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para Mencion
+var (
+	ErrMencionIDNil        = errors.New("id de mención inválido")
+	ErrMencionUsuarioIDNil = errors.New("id del usuario mencionado inválido")
+	ErrMencionMensajeIDNil = errors.New("id del mensaje de la mención inválido")
+	ErrMencionCanalIDNil   = errors.New("id del canal de la mención inválido")
+	ErrMencionTipoInvalido = errors.New("tipo de mención inválido")
+	ErrMencionFechaZero    = errors.New("fecha de mención no puede ser cero")
+)
+
+// MencionCanalReservada es el nombre que menciona a todos los miembros del canal; un
+// usuario con ese nombre no puede mencionarse individualmente
+const MencionCanalReservada = "channel"
+
+// TipoMencion indica cómo se mencionó al usuario en el mensaje
+type TipoMencion string
+
+// Constantes para los tipos de mención
+const (
+	MencionUsuario TipoMencion = "USUARIO" // Mencionado por su nombre con @usuario
+	MencionCanal   TipoMencion = "CANAL"   // Incluido en un @channel a todo el canal
+)
+
+// Valid verifica si el valor es un tipo de mención válido
+func (t TipoMencion) Valid() bool {
+	return t == MencionUsuario || t == MencionCanal
+}
+
+// Mencion es la entrada del feed de menciones de un usuario: un mensaje de canal que
+// lo menciona, con la notificación que se le envió
+type Mencion struct {
+	id             uuid.UUID
+	usuarioID      uuid.UUID // Usuario mencionado
+	mensajeID      uuid.UUID
+	canalID        uuid.UUID
+	remitenteID    uuid.UUID
+	tipo           TipoMencion
+	fecha          time.Time
+	notificacionID uuid.UUID // Opcional: uuid.Nil si no se llegó a notificar
+}
+
+// NewMencion crea una Mencion validando sus invariantes
+func NewMencion(
+	id, usuarioID, mensajeID, canalID, remitenteID uuid.UUID,
+	tipo TipoMencion,
+	fecha time.Time,
+	notificacionID uuid.UUID,
+) (*Mencion, error) {
+	if id == uuid.Nil {
+		return nil, ErrMencionIDNil
+	}
+	if usuarioID == uuid.Nil {
+		return nil, ErrMencionUsuarioIDNil
+	}
+	if mensajeID == uuid.Nil {
+		return nil, ErrMencionMensajeIDNil
+	}
+	if canalID == uuid.Nil {
+		return nil, ErrMencionCanalIDNil
+	}
+	if remitenteID == uuid.Nil {
+		return nil, ErrRemitenteIDNil
+	}
+	if !tipo.Valid() {
+		return nil, ErrMencionTipoInvalido
+	}
+	if fecha.IsZero() {
+		return nil, ErrMencionFechaZero
+	}
+	return &Mencion{
+		id:             id,
+		usuarioID:      usuarioID,
+		mensajeID:      mensajeID,
+		canalID:        canalID,
+		remitenteID:    remitenteID,
+		tipo:           tipo,
+		fecha:          fecha.UTC(),
+		notificacionID: notificacionID,
+	}, nil
+}
+
+// Getters
+func (m *Mencion) ID() uuid.UUID             { return m.id }
+func (m *Mencion) UsuarioID() uuid.UUID      { return m.usuarioID }
+func (m *Mencion) MensajeID() uuid.UUID      { return m.mensajeID }
+func (m *Mencion) CanalID() uuid.UUID        { return m.canalID }
+func (m *Mencion) RemitenteID() uuid.UUID    { return m.remitenteID }
+func (m *Mencion) Tipo() TipoMencion         { return m.tipo }
+func (m *Mencion) Fecha() time.Time          { return m.fecha }
+func (m *Mencion) NotificacionID() uuid.UUID { return m.notificacionID }
+
+// ExtraerMenciones devuelve los nombres de usuario mencionados con @ en el contenido,
+// en minúsculas, sin repetir y en orden de aparición, e indica si se mencionó a todo el
+// canal con @channel. Una @ solo abre una mención al principio del texto o tras un
+// carácter que no forma parte de un nombre, así que las direcciones de correo no
+// cuentan; el punto o el guion final de un nombre se toma como puntuación.
+func ExtraerMenciones(contenido string) (nombres []string, canal bool) {
+	runas := []rune(contenido)
+	vistos := make(map[string]bool)
+	for i := 0; i < len(runas); i++ {
+		if runas[i] != '@' || (i > 0 && caracterDeNombre(runas[i-1])) {
+			continue
+		}
+		fin := i + 1
+		for fin < len(runas) && caracterDeNombre(runas[fin]) {
+			fin++
+		}
+		nombre := strings.ToLower(strings.TrimRight(string(runas[i+1:fin]), ".-"))
+		i = fin - 1
+		switch {
+		case nombre == "":
+		case nombre == MencionCanalReservada:
+			canal = true
+		case !vistos[nombre]:
+			vistos[nombre] = true
+			nombres = append(nombres, nombre)
+		}
+	}
+	return nombres, canal
+}
+
+// caracterDeNombre indica si r puede formar parte de un nombre de usuario mencionado
+func caracterDeNombre(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
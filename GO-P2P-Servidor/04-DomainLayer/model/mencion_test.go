@@ -0,0 +1,67 @@
+package model_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewMencion(t *testing.T) {
+	id, usuarioID, mensajeID, canalID, remitenteID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	fecha := time.Now()
+
+	m, err := model.NewMencion(id, usuarioID, mensajeID, canalID, remitenteID, model.MencionCanal, fecha, uuid.Nil)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if m.Tipo() != model.MencionCanal || m.CanalID() != canalID || !m.Fecha().Equal(fecha) || m.NotificacionID() != uuid.Nil {
+		t.Errorf("campos inesperados: %s %v %v", m.Tipo(), m.CanalID(), m.Fecha())
+	}
+
+	casos := []struct {
+		nombre                                   string
+		id, usuarioID, mensajeID, canalID, remID uuid.UUID
+		tipo                                     model.TipoMencion
+		fecha                                    time.Time
+		esperado                                 error
+	}{
+		{"id nil", uuid.Nil, usuarioID, mensajeID, canalID, remitenteID, model.MencionUsuario, fecha, model.ErrMencionIDNil},
+		{"usuario nil", id, uuid.Nil, mensajeID, canalID, remitenteID, model.MencionUsuario, fecha, model.ErrMencionUsuarioIDNil},
+		{"mensaje nil", id, usuarioID, uuid.Nil, canalID, remitenteID, model.MencionUsuario, fecha, model.ErrMencionMensajeIDNil},
+		{"canal nil", id, usuarioID, mensajeID, uuid.Nil, remitenteID, model.MencionUsuario, fecha, model.ErrMencionCanalIDNil},
+		{"remitente nil", id, usuarioID, mensajeID, canalID, uuid.Nil, model.MencionUsuario, fecha, model.ErrRemitenteIDNil},
+		{"tipo inválido", id, usuarioID, mensajeID, canalID, remitenteID, "TODOS", fecha, model.ErrMencionTipoInvalido},
+		{"sin fecha", id, usuarioID, mensajeID, canalID, remitenteID, model.MencionUsuario, time.Time{}, model.ErrMencionFechaZero},
+	}
+	for _, c := range casos {
+		_, err := model.NewMencion(c.id, c.usuarioID, c.mensajeID, c.canalID, c.remID, c.tipo, c.fecha, uuid.Nil)
+		if err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
+
+func TestExtraerMenciones(t *testing.T) {
+	casos := []struct {
+		contenido string
+		nombres   []string
+		canal     bool
+	}{
+		{"sin menciones", nil, false},
+		{"@ana, mira esto", []string{"ana"}, false},
+		{"hola @Ana y @beto.ruiz. ¿Y @ANA?", []string{"ana", "beto.ruiz"}, false},
+		{"atención @channel: reunión", nil, true},
+		{"(@carla) escribe a carla@correo.com", []string{"carla"}, false},
+		{"@ solo, @- y @channel @dani_2", []string{"dani_2"}, true},
+		{"¡@Íñigo!", []string{"íñigo"}, false},
+	}
+	for _, c := range casos {
+		nombres, canal := model.ExtraerMenciones(c.contenido)
+		if !reflect.DeepEqual(nombres, c.nombres) || canal != c.canal {
+			t.Errorf("%q: esperado %v %v, obtuvo %v %v", c.contenido, c.nombres, c.canal, nombres, canal)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para MensajeProgramado
+var (
+	ErrProgramadoIDNil            = errors.New("id del mensaje programado inválido")
+	ErrProgramadoNodoIDNil        = errors.New("id del nodo del mensaje programado inválido")
+	ErrProgramadoEnviarEnZero     = errors.New("fecha de envío del mensaje programado no puede ser cero")
+	ErrProgramadoDuracionInvalida = errors.New("la duración del mensaje debe ser de al menos un segundo")
+	ErrProgramadoEstadoInvalido   = errors.New("estado de mensaje programado inválido")
+	ErrProgramadoNoPendiente      = errors.New("el mensaje programado ya no está pendiente")
+	ErrProgramadoNoEnviado        = errors.New("el mensaje programado no se ha enviado")
+)
+
+// EstadoProgramado representa el punto al que ha llegado un mensaje programado
+type EstadoProgramado string
+
+// Constantes para los estados de un mensaje programado
+const (
+	ProgramadoPendiente EstadoProgramado = "PENDIENTE" // Espera a su fecha de envío
+	ProgramadoCancelado EstadoProgramado = "CANCELADO" // El remitente lo canceló antes del envío
+	ProgramadoEnviando  EstadoProgramado = "ENVIANDO"  // El nodo responsable lo reservó para enviarlo
+	ProgramadoEnviado   EstadoProgramado = "ENVIADO"   // Se envió; si caduca, espera a su expiración
+	ProgramadoFallido   EstadoProgramado = "FALLIDO"   // El envío se rechazó o se interrumpió
+	ProgramadoExpirado  EstadoProgramado = "EXPIRADO"  // El mensaje caducó y se eliminó para todos
+)
+
+// Valid verifica si el valor es un estado de mensaje programado válido
+func (e EstadoProgramado) Valid() bool {
+	return e.orden() > 0
+}
+
+// Supera indica si el estado es posterior a otro. Solo el nodo responsable pasa de
+// PENDIENTE a ENVIANDO, así que un envío gana siempre a una cancelación concurrente
+// hecha en otro nodo, y ENVIADO y FALLIDO nunca llegan a competir.
+func (e EstadoProgramado) Supera(otro EstadoProgramado) bool {
+	return e.orden() > otro.orden()
+}
+
+// orden devuelve la posición del estado en el ciclo del mensaje; 0 si no es válido
+func (e EstadoProgramado) orden() int {
+	switch e {
+	case ProgramadoPendiente:
+		return 1
+	case ProgramadoCancelado:
+		return 2
+	case ProgramadoEnviando:
+		return 3
+	case ProgramadoEnviado, ProgramadoFallido:
+		return 4
+	case ProgramadoExpirado:
+		return 5
+	default:
+		return 0
+	}
+}
+
+// MensajeProgramado es un mensaje directo o de canal que se envía en una fecha futura
+// y, opcionalmente, caduca tras una duración desde su envío. Solo lo envía y lo
+// elimina nodoID, el nodo en que se programó; los demás guardan una copia para que el
+// remitente pueda consultarlo o cancelarlo desde cualquier nodo.
+type MensajeProgramado struct {
+	id               uuid.UUID
+	nodoID           uuid.UUID // Nodo responsable del envío y la expiración
+	remitenteID      uuid.UUID
+	destinoUsuarioID uuid.UUID // Opcional: si es mensaje directo
+	canalID          uuid.UUID // Opcional: si es mensaje de canal
+	contenido        string
+	archivoID        uuid.UUID     // Opcional: si lleva adjunto
+	enviarEn         time.Time     // Fecha a partir de la cual se envía
+	duracion         time.Duration // Vida del mensaje tras su envío; cero si no caduca
+	estado           EstadoProgramado
+	mensajeID        uuid.UUID // Mensaje enviado; uuid.Nil hasta el envío
+	expiraEn         time.Time // Envío más duración; cero si no se ha enviado o no caduca
+}
+
+// NewMensajeProgramado crea un MensajeProgramado pendiente validando sus invariantes.
+// El destino sigue las reglas de los mensajes: un usuario o un canal, no ambos.
+func NewMensajeProgramado(
+	id, nodoID, remitenteID, destinoUsuarioID, canalID uuid.UUID,
+	contenido string,
+	archivoID uuid.UUID,
+	enviarEn time.Time,
+	duracion time.Duration,
+) (*MensajeProgramado, error) {
+	if id == uuid.Nil {
+		return nil, ErrProgramadoIDNil
+	}
+	if nodoID == uuid.Nil {
+		return nil, ErrProgramadoNodoIDNil
+	}
+	if remitenteID == uuid.Nil {
+		return nil, ErrRemitenteIDNil
+	}
+	switch {
+	case destinoUsuarioID != uuid.Nil && canalID != uuid.Nil:
+		return nil, ErrDestinoYCanalAmbos
+	case destinoUsuarioID == uuid.Nil && canalID == uuid.Nil:
+		return nil, ErrSinDestinoNiCanal
+	}
+	if contenido == "" {
+		return nil, ErrContenidoVacio
+	}
+	if enviarEn.IsZero() {
+		return nil, ErrProgramadoEnviarEnZero
+	}
+	if duracion != 0 && duracion < time.Second {
+		return nil, ErrProgramadoDuracionInvalida
+	}
+	return &MensajeProgramado{
+		id:               id,
+		nodoID:           nodoID,
+		remitenteID:      remitenteID,
+		destinoUsuarioID: destinoUsuarioID,
+		canalID:          canalID,
+		contenido:        contenido,
+		archivoID:        archivoID,
+		enviarEn:         enviarEn.UTC(),
+		duracion:         duracion.Truncate(time.Second),
+		estado:           ProgramadoPendiente,
+	}, nil
+}
+
+// Getters
+func (p *MensajeProgramado) ID() uuid.UUID               { return p.id }
+func (p *MensajeProgramado) NodoID() uuid.UUID           { return p.nodoID }
+func (p *MensajeProgramado) RemitenteID() uuid.UUID      { return p.remitenteID }
+func (p *MensajeProgramado) DestinoUsuarioID() uuid.UUID { return p.destinoUsuarioID }
+func (p *MensajeProgramado) CanalID() uuid.UUID          { return p.canalID }
+func (p *MensajeProgramado) Contenido() string           { return p.contenido }
+func (p *MensajeProgramado) ArchivoID() uuid.UUID        { return p.archivoID }
+func (p *MensajeProgramado) EnviarEn() time.Time         { return p.enviarEn }
+func (p *MensajeProgramado) Duracion() time.Duration     { return p.duracion }
+func (p *MensajeProgramado) Estado() EstadoProgramado    { return p.estado }
+func (p *MensajeProgramado) MensajeID() uuid.UUID        { return p.mensajeID }
+func (p *MensajeProgramado) ExpiraEn() time.Time         { return p.expiraEn }
+
+// Caduca indica si el mensaje se elimina tras su envío
+func (p *MensajeProgramado) Caduca() bool { return p.duracion > 0 }
+
+// Reservar marca el mensaje como en envío por su nodo responsable
+func (p *MensajeProgramado) Reservar() error {
+	if p.estado != ProgramadoPendiente {
+		return ErrProgramadoNoPendiente
+	}
+	p.estado = ProgramadoEnviando
+	return nil
+}
+
+// Cancelar anula el envío de un mensaje pendiente
+func (p *MensajeProgramado) Cancelar() error {
+	if p.estado != ProgramadoPendiente {
+		return ErrProgramadoNoPendiente
+	}
+	p.estado = ProgramadoCancelado
+	return nil
+}
+
+// Enviado registra el mensaje enviado en at y calcula su expiración
+func (p *MensajeProgramado) Enviado(mensajeID uuid.UUID, at time.Time) error {
+	if p.estado != ProgramadoEnviando {
+		return ErrProgramadoNoPendiente
+	}
+	if mensajeID == uuid.Nil {
+		return ErrMensajeIDNil
+	}
+	if at.IsZero() {
+		return ErrTimestampZero
+	}
+	p.estado = ProgramadoEnviado
+	p.mensajeID = mensajeID
+	if p.Caduca() {
+		p.expiraEn = at.UTC().Add(p.duracion)
+	}
+	return nil
+}
+
+// Fallar registra que el mensaje reservado no se pudo enviar
+func (p *MensajeProgramado) Fallar() error {
+	if p.estado != ProgramadoEnviando {
+		return ErrProgramadoNoPendiente
+	}
+	p.estado = ProgramadoFallido
+	return nil
+}
+
+// Expirar registra que el mensaje enviado caducó y se eliminó
+func (p *MensajeProgramado) Expirar() error {
+	if p.estado != ProgramadoEnviado || !p.Caduca() {
+		return ErrProgramadoNoEnviado
+	}
+	p.estado = ProgramadoExpirado
+	return nil
+}
+
+// SetEstado asigna el estado, el mensaje enviado y la expiración leídos de la base
+// de datos o recibidos de otro nodo
+func (p *MensajeProgramado) SetEstado(estado EstadoProgramado, mensajeID uuid.UUID, expiraEn time.Time) error {
+	if !estado.Valid() {
+		return ErrProgramadoEstadoInvalido
+	}
+	p.estado = estado
+	p.mensajeID = mensajeID
+	p.expiraEn = expiraEn
+	return nil
+}
+
+// Fusionar adopta el estado de otra copia del mismo mensaje programado si es
+// posterior al propio. Devuelve false, sin cambios, si no lo es.
+func (p *MensajeProgramado) Fusionar(otro *MensajeProgramado) bool {
+	if otro.id != p.id || !otro.estado.Supera(p.estado) {
+		return false
+	}
+	p.estado = otro.estado
+	p.mensajeID = otro.mensajeID
+	p.expiraEn = otro.expiraEn
+	return true
+}
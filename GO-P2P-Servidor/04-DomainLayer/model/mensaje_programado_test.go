@@ -0,0 +1,142 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestEstadoProgramado(t *testing.T) {
+	orden := []model.EstadoProgramado{
+		model.ProgramadoPendiente,
+		model.ProgramadoCancelado,
+		model.ProgramadoEnviando,
+		model.ProgramadoEnviado,
+		model.ProgramadoExpirado,
+	}
+	for i := 1; i < len(orden); i++ {
+		if !orden[i].Supera(orden[i-1]) || orden[i-1].Supera(orden[i]) {
+			t.Errorf("%s debe ser posterior a %s", orden[i], orden[i-1])
+		}
+	}
+	if model.ProgramadoFallido.Supera(model.ProgramadoEnviado) || model.ProgramadoEnviado.Supera(model.ProgramadoFallido) {
+		t.Error("ENVIADO y FALLIDO son finales alternativos del envío")
+	}
+	if model.EstadoProgramado("BORRADOR").Valid() {
+		t.Error("BORRADOR no es un estado válido")
+	}
+}
+
+func TestNewMensajeProgramado(t *testing.T) {
+	id, nodoID, remitenteID, destinoID, canalID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	enviarEn := time.Now().Add(time.Hour)
+
+	p, err := model.NewMensajeProgramado(id, nodoID, remitenteID, uuid.Nil, canalID, "hola", uuid.Nil, enviarEn, 90*time.Second+time.Millisecond)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if p.Estado() != model.ProgramadoPendiente || p.CanalID() != canalID || p.Duracion() != 90*time.Second || !p.Caduca() {
+		t.Errorf("campos inesperados: %s %v %v", p.Estado(), p.CanalID(), p.Duracion())
+	}
+
+	casos := []struct {
+		nombre             string
+		id, nodoID         uuid.UUID
+		destinoID, canalID uuid.UUID
+		contenido          string
+		enviarEn           time.Time
+		duracion           time.Duration
+		esperado           error
+	}{
+		{"id nil", uuid.Nil, nodoID, destinoID, uuid.Nil, "hola", enviarEn, 0, model.ErrProgramadoIDNil},
+		{"nodo nil", id, uuid.Nil, destinoID, uuid.Nil, "hola", enviarEn, 0, model.ErrProgramadoNodoIDNil},
+		{"sin destino", id, nodoID, uuid.Nil, uuid.Nil, "hola", enviarEn, 0, model.ErrSinDestinoNiCanal},
+		{"dos destinos", id, nodoID, destinoID, canalID, "hola", enviarEn, 0, model.ErrDestinoYCanalAmbos},
+		{"sin contenido", id, nodoID, destinoID, uuid.Nil, "", enviarEn, 0, model.ErrContenidoVacio},
+		{"sin fecha", id, nodoID, destinoID, uuid.Nil, "hola", time.Time{}, 0, model.ErrProgramadoEnviarEnZero},
+		{"duración corta", id, nodoID, destinoID, uuid.Nil, "hola", enviarEn, time.Millisecond, model.ErrProgramadoDuracionInvalida},
+	}
+	for _, c := range casos {
+		_, err := model.NewMensajeProgramado(c.id, c.nodoID, remitenteID, c.destinoID, c.canalID, c.contenido, uuid.Nil, c.enviarEn, c.duracion)
+		if err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
+
+func TestMensajeProgramado_Ciclo(t *testing.T) {
+	nuevo := func(duracion time.Duration) *model.MensajeProgramado {
+		p, _ := model.NewMensajeProgramado(uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.Nil, "hola", uuid.Nil, time.Now(), duracion)
+		return p
+	}
+
+	p := nuevo(time.Minute)
+	if err := p.Enviado(uuid.New(), time.Now()); err != model.ErrProgramadoNoPendiente {
+		t.Errorf("solo se registra el envío de un mensaje reservado, obtuvo %v", err)
+	}
+	if err := p.Reservar(); err != nil {
+		t.Fatalf("Reservar: %v", err)
+	}
+	if err := p.Cancelar(); err != model.ErrProgramadoNoPendiente {
+		t.Errorf("un mensaje reservado no se cancela, obtuvo %v", err)
+	}
+	enviado := time.Now()
+	mensajeID := uuid.New()
+	if err := p.Enviado(mensajeID, enviado); err != nil {
+		t.Fatalf("Enviado: %v", err)
+	}
+	if p.MensajeID() != mensajeID || !p.ExpiraEn().Equal(enviado.UTC().Add(time.Minute)) {
+		t.Errorf("envío inesperado: %v %v", p.MensajeID(), p.ExpiraEn())
+	}
+	if err := p.Expirar(); err != nil || p.Estado() != model.ProgramadoExpirado {
+		t.Errorf("Expirar: %v, estado %s", err, p.Estado())
+	}
+
+	sinCaducar := nuevo(0)
+	sinCaducar.Reservar()
+	sinCaducar.Enviado(uuid.New(), time.Now())
+	if !sinCaducar.ExpiraEn().IsZero() {
+		t.Error("un mensaje sin duración no expira")
+	}
+	if err := sinCaducar.Expirar(); err != model.ErrProgramadoNoEnviado {
+		t.Errorf("un mensaje sin duración no se expira, obtuvo %v", err)
+	}
+
+	fallido := nuevo(0)
+	fallido.Reservar()
+	if err := fallido.Fallar(); err != nil || fallido.Estado() != model.ProgramadoFallido {
+		t.Errorf("Fallar: %v, estado %s", err, fallido.Estado())
+	}
+}
+
+func TestMensajeProgramado_Fusionar(t *testing.T) {
+	local, _ := model.NewMensajeProgramado(uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.Nil, "hola", uuid.Nil, time.Now(), time.Minute)
+	cancelado := *local
+	cancelado.Cancelar()
+
+	if !local.Fusionar(&cancelado) || local.Estado() != model.ProgramadoCancelado {
+		t.Fatalf("la cancelación remota debe aplicarse, estado %s", local.Estado())
+	}
+
+	// El envío del nodo responsable gana a la cancelación hecha en otro nodo
+	enviado, _ := model.NewMensajeProgramado(local.ID(), local.NodoID(), local.RemitenteID(), local.DestinoUsuarioID(), uuid.Nil, "hola", uuid.Nil, local.EnviarEn(), time.Minute)
+	enviado.Reservar()
+	enviado.Enviado(uuid.New(), time.Now())
+	if !local.Fusionar(enviado) || local.Estado() != model.ProgramadoEnviado || local.MensajeID() != enviado.MensajeID() || local.ExpiraEn() != enviado.ExpiraEn() {
+		t.Errorf("el envío remoto debe aplicarse: %s %v", local.Estado(), local.MensajeID())
+	}
+	if local.Fusionar(&cancelado) {
+		t.Error("un estado anterior no debe aplicarse")
+	}
+
+	otro, _ := model.NewMensajeProgramado(uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.Nil, "hola", uuid.Nil, time.Now(), 0)
+	otro.SetEstado(model.ProgramadoExpirado, uuid.New(), time.Now())
+	if local.Fusionar(otro) {
+		t.Error("no se fusionan mensajes programados distintos")
+	}
+	if err := otro.SetEstado("BORRADOR", uuid.Nil, time.Time{}); err != model.ErrProgramadoEstadoInvalido {
+		t.Errorf("esperado %v, obtuvo %v", model.ErrProgramadoEstadoInvalido, err)
+	}
+}
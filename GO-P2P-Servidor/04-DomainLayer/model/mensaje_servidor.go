@@ -16,6 +16,14 @@ var (
     ErrDestinoYCanalAmbos     = errors.New("debe elegir un destino de usuario O un canal, no ambos")
     ErrSinDestinoNiCanal      = errors.New("debe proporcionar un destino de usuario O un canal")
     ErrMensajeChatPrivadoIDNil = errors.New("id de chat privado inválido")
+    ErrMensajeEliminado       = errors.New("el mensaje está eliminado")
+    ErrEdicionSinCambios      = errors.New("el contenido editado es igual al actual")
+    ErrEdicionAnterior        = errors.New("la edición no puede ser anterior al mensaje")
+    ErrRespuestaPadreNil      = errors.New("mensaje padre inválido")
+    ErrRespuestaASiMismo      = errors.New("un mensaje no puede responderse a sí mismo")
+    ErrRespuestaARespuesta    = errors.New("solo se puede responder al mensaje raíz de un hilo")
+    ErrRespuestaOtroDestino   = errors.New("la respuesta debe ir al mismo destino que el mensaje padre")
+    ErrRespuestaAnterior      = errors.New("la respuesta no puede ser anterior al mensaje padre")
 )
 
 // MensajeServidor representa un mensaje de texto en el dominio del servidor.
@@ -28,6 +36,10 @@ type MensajeServidor struct {
     contenido        string
     timestamp        time.Time
     archivoID        uuid.UUID // Opcional: si lleva adjunto
+    version          HLC       // Versión HLC con la que se replicó el mensaje
+    editadoAt        time.Time // Última edición del contenido; cero si nunca se editó
+    eliminadoAt      time.Time // Borrado lógico; cero si el mensaje no está eliminado
+    padreID          uuid.UUID // Opcional: mensaje raíz del hilo al que responde
 }
 
 // NewMensajeDirecto crea un MensajeServidor para mensajes 1:1.
@@ -134,3 +146,113 @@ func (m *MensajeServidor) ChatPrivadoID() uuid.UUID    { return m.chatPrivadoID
 func (m *MensajeServidor) Contenido() string           { return m.contenido }
 func (m *MensajeServidor) Timestamp() time.Time        { return m.timestamp }
 func (m *MensajeServidor) ArchivoID() uuid.UUID        { return m.archivoID }
+func (m *MensajeServidor) Version() HLC                { return m.version }
+func (m *MensajeServidor) EditadoAt() time.Time        { return m.editadoAt }
+func (m *MensajeServidor) EliminadoAt() time.Time      { return m.eliminadoAt }
+func (m *MensajeServidor) PadreID() uuid.UUID          { return m.padreID }
+
+// Editado indica si el contenido del mensaje se modificó tras enviarlo
+func (m *MensajeServidor) Editado() bool { return !m.editadoAt.IsZero() }
+
+// Eliminado indica si el mensaje es una lápida: la fila se conserva para que el
+// borrado se replique y el historial lo referencie, pero no debe mostrarse
+func (m *MensajeServidor) Eliminado() bool { return !m.eliminadoAt.IsZero() }
+
+// EsRespuesta indica si el mensaje pertenece al hilo de otro mensaje
+func (m *MensajeServidor) EsRespuesta() bool { return m.padreID != uuid.Nil }
+
+// SetVersion asigna la marca HLC con la que se replicó el mensaje
+func (m *MensajeServidor) SetVersion(version HLC) {
+    m.version = version
+}
+
+// Editar sustituye el contenido del mensaje y registra el momento de la edición.
+// Un mensaje eliminado no se puede editar.
+func (m *MensajeServidor) Editar(contenido string, at time.Time) error {
+    if m.Eliminado() {
+        return ErrMensajeEliminado
+    }
+    if contenido == "" {
+        return ErrContenidoVacio
+    }
+    if contenido == m.contenido {
+        return ErrEdicionSinCambios
+    }
+    if at.IsZero() {
+        return ErrTimestampZero
+    }
+    if at.Before(m.timestamp) {
+        return ErrEdicionAnterior
+    }
+    m.contenido = contenido
+    m.editadoAt = at
+    return nil
+}
+
+// Eliminar marca el mensaje como eliminado. El contenido se conserva en la fila
+// y en el historial de ediciones; son los clientes quienes muestran la lápida.
+func (m *MensajeServidor) Eliminar(at time.Time) error {
+    if m.Eliminado() {
+        return ErrMensajeEliminado
+    }
+    if at.IsZero() {
+        return ErrTimestampZero
+    }
+    if at.Before(m.timestamp) {
+        return ErrEdicionAnterior
+    }
+    m.eliminadoAt = at
+    return nil
+}
+
+// SetEdicion asigna las marcas de edición y borrado leídas de la base de datos o
+// recibidas de otro nodo; cero significa que el mensaje no se editó o no se eliminó
+func (m *MensajeServidor) SetEdicion(editadoAt, eliminadoAt time.Time) {
+    m.editadoAt = editadoAt
+    m.eliminadoAt = eliminadoAt
+}
+
+// ResponderA convierte el mensaje en una respuesta del hilo de padre. Los hilos tienen
+// un solo nivel: padre debe ser un mensaje raíz, con el mismo destino (canal, chat
+// privado o pareja de usuarios) y no posterior a la respuesta.
+func (m *MensajeServidor) ResponderA(padre *MensajeServidor) error {
+    if padre == nil {
+        return ErrRespuestaPadreNil
+    }
+    if padre.id == m.id {
+        return ErrRespuestaASiMismo
+    }
+    if padre.EsRespuesta() {
+        return ErrRespuestaARespuesta
+    }
+    if !m.mismoDestino(padre) {
+        return ErrRespuestaOtroDestino
+    }
+    if m.timestamp.Before(padre.timestamp) {
+        return ErrRespuestaAnterior
+    }
+    m.padreID = padre.id
+    return nil
+}
+
+// SetPadreID asigna el mensaje raíz del hilo leído de la base de datos o recibido
+// de otro nodo; uuid.Nil significa que el mensaje no es una respuesta
+func (m *MensajeServidor) SetPadreID(padreID uuid.UUID) {
+    m.padreID = padreID
+}
+
+// mismoDestino indica si dos mensajes van al mismo canal, al mismo chat privado o
+// son directos entre la misma pareja de usuarios
+func (m *MensajeServidor) mismoDestino(otro *MensajeServidor) bool {
+    switch {
+    case m.canalID != uuid.Nil:
+        return m.canalID == otro.canalID
+    case m.chatPrivadoID != uuid.Nil:
+        return m.chatPrivadoID == otro.chatPrivadoID
+    }
+    if otro.destinoUsuarioID == uuid.Nil {
+        return false
+    }
+    return (m.remitenteID == otro.remitenteID && m.destinoUsuarioID == otro.destinoUsuarioID) ||
+        (m.remitenteID == otro.destinoUsuarioID && m.destinoUsuarioID == otro.remitenteID)
+}
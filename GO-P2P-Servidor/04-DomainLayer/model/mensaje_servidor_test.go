@@ -114,3 +114,97 @@ func TestNewMensaje_Errors(t *testing.T) {
         })
     }
 }
+
+func TestMensajeServidor_Editar(t *testing.T) {
+    now := time.Now().UTC().Truncate(time.Second)
+    m, err := model.NewMensajeCanal(uuid.New(), uuid.New(), uuid.New(), "original", now, uuid.Nil)
+    if err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    if m.Editado() || m.Eliminado() {
+        t.Fatalf("un mensaje nuevo no debe estar editado ni eliminado")
+    }
+
+    if err := m.Editar("original", now.Add(time.Minute)); err != model.ErrEdicionSinCambios {
+        t.Errorf("mismo contenido: esperado %v, obtuvo %v", model.ErrEdicionSinCambios, err)
+    }
+    if err := m.Editar("", now.Add(time.Minute)); err != model.ErrContenidoVacio {
+        t.Errorf("contenido vacío: esperado %v, obtuvo %v", model.ErrContenidoVacio, err)
+    }
+    if err := m.Editar("corregido", now.Add(-time.Minute)); err != model.ErrEdicionAnterior {
+        t.Errorf("edición anterior: esperado %v, obtuvo %v", model.ErrEdicionAnterior, err)
+    }
+
+    editado := now.Add(time.Minute)
+    if err := m.Editar("corregido", editado); err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    if m.Contenido() != "corregido" || !m.Editado() || !m.EditadoAt().Equal(editado) {
+        t.Errorf("edición no aplicada: %q editado=%v %v", m.Contenido(), m.Editado(), m.EditadoAt())
+    }
+}
+
+func TestMensajeServidor_Eliminar(t *testing.T) {
+    now := time.Now().UTC().Truncate(time.Second)
+    m, err := model.NewMensajeDirecto(uuid.New(), uuid.New(), uuid.New(), "hola", now, uuid.Nil)
+    if err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+
+    eliminado := now.Add(time.Hour)
+    if err := m.Eliminar(eliminado); err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    if !m.Eliminado() || !m.EliminadoAt().Equal(eliminado) {
+        t.Errorf("borrado no aplicado: eliminado=%v %v", m.Eliminado(), m.EliminadoAt())
+    }
+    if m.Contenido() != "hola" {
+        t.Errorf("el borrado lógico debe conservar el contenido, obtuvo %q", m.Contenido())
+    }
+    if err := m.Eliminar(eliminado); err != model.ErrMensajeEliminado {
+        t.Errorf("doble borrado: esperado %v, obtuvo %v", model.ErrMensajeEliminado, err)
+    }
+    if err := m.Editar("otro", eliminado); err != model.ErrMensajeEliminado {
+        t.Errorf("editar eliminado: esperado %v, obtuvo %v", model.ErrMensajeEliminado, err)
+    }
+}
+
+func TestMensajeServidor_ResponderA(t *testing.T) {
+    now := time.Now().UTC().Truncate(time.Second)
+    ana, beto, canal := uuid.New(), uuid.New(), uuid.New()
+    raiz, _ := model.NewMensajeCanal(uuid.New(), ana, canal, "raíz", now, uuid.Nil)
+
+    respuesta, _ := model.NewMensajeCanal(uuid.New(), beto, canal, "respuesta", now.Add(time.Minute), uuid.Nil)
+    if err := respuesta.ResponderA(raiz); err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    if !respuesta.EsRespuesta() || respuesta.PadreID() != raiz.ID() || raiz.EsRespuesta() {
+        t.Errorf("respuesta no enlazada: padre=%v", respuesta.PadreID())
+    }
+
+    otroCanal, _ := model.NewMensajeCanal(uuid.New(), beto, uuid.New(), "x", now.Add(time.Minute), uuid.Nil)
+    anterior, _ := model.NewMensajeCanal(uuid.New(), beto, canal, "x", now.Add(-time.Minute), uuid.Nil)
+    anidada, _ := model.NewMensajeCanal(uuid.New(), ana, canal, "x", now.Add(time.Hour), uuid.Nil)
+    directoInverso, _ := model.NewMensajeDirecto(uuid.New(), beto, ana, "x", now, uuid.Nil)
+    directoAjeno, _ := model.NewMensajeDirecto(uuid.New(), beto, uuid.New(), "x", now, uuid.Nil)
+    directoRaiz, _ := model.NewMensajeDirecto(uuid.New(), ana, beto, "hola", now, uuid.Nil)
+
+    casos := []struct {
+        nombre   string
+        m, padre *model.MensajeServidor
+        esperado error
+    }{
+        {"padre nil", otroCanal, nil, model.ErrRespuestaPadreNil},
+        {"a sí mismo", raiz, raiz, model.ErrRespuestaASiMismo},
+        {"a una respuesta", anidada, respuesta, model.ErrRespuestaARespuesta},
+        {"otro canal", otroCanal, raiz, model.ErrRespuestaOtroDestino},
+        {"anterior al padre", anterior, raiz, model.ErrRespuestaAnterior},
+        {"otra pareja", directoAjeno, directoRaiz, model.ErrRespuestaOtroDestino},
+        {"directo inverso", directoInverso, directoRaiz, nil},
+    }
+    for _, c := range casos {
+        if err := c.m.ResponderA(c.padre); err != c.esperado {
+            t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+        }
+    }
+}
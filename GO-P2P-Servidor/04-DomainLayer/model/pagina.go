@@ -0,0 +1,117 @@
+package model
+
+import "errors"
+
+// Límites del tamaño de página
+const (
+	LimitePaginaPorDefecto = 50
+	LimitePaginaMaximo     = 500
+)
+
+// Errores de validación para ConsultaPagina
+var (
+	ErrDireccionPaginaInvalida = errors.New("dirección de página inválida")
+)
+
+// DireccionPagina indica hacia dónde avanza una página respecto a su cursor
+type DireccionPagina string
+
+const (
+	// PaginaAnteriores recorre hacia las filas más antiguas; sin cursor, la página más reciente
+	PaginaAnteriores DireccionPagina = "ANTERIORES"
+	// PaginaPosteriores recorre hacia las filas más recientes; sin cursor, la más antigua
+	PaginaPosteriores DireccionPagina = "POSTERIORES"
+)
+
+// Valid comprueba si la dirección es válida
+func (d DireccionPagina) Valid() bool {
+	return d == PaginaAnteriores || d == PaginaPosteriores
+}
+
+// ConsultaPagina describe qué página de un listado ordenado por (timestamp, id) se pide.
+// El valor cero pide la página más reciente con el tamaño por defecto.
+type ConsultaPagina struct {
+	cursor    Cursor
+	direccion DireccionPagina
+	limite    int
+}
+
+// NewConsultaPagina crea una consulta a partir del cursor opaco que recibió el cliente.
+// Una dirección vacía equivale a PaginaAnteriores, un límite no positivo al límite por
+// defecto y uno mayor que LimitePaginaMaximo se recorta.
+func NewConsultaPagina(cursor string, direccion DireccionPagina, limite int) (ConsultaPagina, error) {
+	c, err := ParseCursor(cursor)
+	if err != nil {
+		return ConsultaPagina{}, err
+	}
+	if direccion != "" && !direccion.Valid() {
+		return ConsultaPagina{}, ErrDireccionPaginaInvalida
+	}
+	if limite > LimitePaginaMaximo {
+		limite = LimitePaginaMaximo
+	}
+	return ConsultaPagina{cursor: c, direccion: direccion, limite: limite}, nil
+}
+
+// Cursor devuelve la fila a partir de la cual se pide la página, excluida
+func (c ConsultaPagina) Cursor() Cursor { return c.cursor }
+
+// Direccion devuelve hacia dónde avanza la página
+func (c ConsultaPagina) Direccion() DireccionPagina {
+	if c.direccion == "" {
+		return PaginaAnteriores
+	}
+	return c.direccion
+}
+
+// Limite devuelve el número máximo de filas de la página
+func (c ConsultaPagina) Limite() int {
+	if c.limite <= 0 {
+		return LimitePaginaPorDefecto
+	}
+	return c.limite
+}
+
+// Pagina es una página de un listado ordenado por (timestamp, id). Los elementos van
+// siempre en orden cronológico ascendente, sea cual sea la dirección de la consulta.
+type Pagina[T any] struct {
+	Elementos []T
+	// Anterior y Siguiente son los cursores opacos para pedir la página contigua con
+	// PaginaAnteriores y PaginaPosteriores; vacíos si la página no tiene elementos
+	Anterior  string
+	Siguiente string
+	// HayAnteriores y HayPosteriores indican si existen más filas en cada dirección.
+	// En la dirección de la consulta es exacto; en la contraria es cierto cuando la
+	// consulta partía de un cursor, ya que la fila del cursor queda a ese lado.
+	HayAnteriores  bool
+	HayPosteriores bool
+}
+
+// NuevaPagina construye la página a partir de las filas leídas en el orden de la
+// consulta (descendente para PaginaAnteriores, ascendente para PaginaPosteriores),
+// de las que se habrá pedido una más que el límite para saber si hay más.
+func NuevaPagina[T any](filas []T, consulta ConsultaPagina, cursor func(T) Cursor) *Pagina[T] {
+	hayMas := len(filas) > consulta.Limite()
+	if hayMas {
+		filas = filas[:consulta.Limite()]
+	}
+
+	elementos := make([]T, len(filas))
+	copy(elementos, filas)
+	desdeCursor := !consulta.Cursor().IsZero()
+	pagina := &Pagina[T]{Elementos: elementos}
+	if consulta.Direccion() == PaginaAnteriores {
+		for i, j := 0, len(elementos)-1; i < j; i, j = i+1, j-1 {
+			elementos[i], elementos[j] = elementos[j], elementos[i]
+		}
+		pagina.HayAnteriores, pagina.HayPosteriores = hayMas, desdeCursor
+	} else {
+		pagina.HayAnteriores, pagina.HayPosteriores = desdeCursor, hayMas
+	}
+
+	if len(elementos) > 0 {
+		pagina.Anterior = cursor(elementos[0]).String()
+		pagina.Siguiente = cursor(elementos[len(elementos)-1]).String()
+	}
+	return pagina
+}
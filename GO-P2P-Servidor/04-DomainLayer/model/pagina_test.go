@@ -0,0 +1,99 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// fila es un elemento de prueba con su posición en el listado
+type fila struct {
+	n  int
+	id uuid.UUID
+}
+
+func cursorFila(f fila) model.Cursor {
+	return model.NewCursor(time.Unix(int64(f.n), 0), f.id)
+}
+
+func filas(ns ...int) []fila {
+	var lista []fila
+	for _, n := range ns {
+		lista = append(lista, fila{n: n, id: uuid.New()})
+	}
+	return lista
+}
+
+func numeros(p *model.Pagina[fila]) []int {
+	var ns []int
+	for _, f := range p.Elementos {
+		ns = append(ns, f.n)
+	}
+	return ns
+}
+
+func TestNewConsultaPagina(t *testing.T) {
+	consulta, err := model.NewConsultaPagina("", "", 0)
+	if err != nil {
+		t.Fatalf("NewConsultaPagina: %v", err)
+	}
+	if consulta.Direccion() != model.PaginaAnteriores || consulta.Limite() != model.LimitePaginaPorDefecto || !consulta.Cursor().IsZero() {
+		t.Errorf("valores por defecto incorrectos: %v %d %v", consulta.Direccion(), consulta.Limite(), consulta.Cursor())
+	}
+
+	consulta, err = model.NewConsultaPagina("", model.PaginaPosteriores, model.LimitePaginaMaximo+1)
+	if err != nil {
+		t.Fatalf("NewConsultaPagina: %v", err)
+	}
+	if consulta.Limite() != model.LimitePaginaMaximo {
+		t.Errorf("el límite debe recortarse a %d, obtuvo %d", model.LimitePaginaMaximo, consulta.Limite())
+	}
+
+	if _, err := model.NewConsultaPagina("", "LATERAL", 10); err != model.ErrDireccionPaginaInvalida {
+		t.Errorf("esperado ErrDireccionPaginaInvalida, obtuvo %v", err)
+	}
+	if _, err := model.NewConsultaPagina("###", "", 10); err != model.ErrCursorInvalido {
+		t.Errorf("esperado ErrCursorInvalido, obtuvo %v", err)
+	}
+}
+
+func TestNuevaPagina_Anteriores(t *testing.T) {
+	consulta, _ := model.NewConsultaPagina("", model.PaginaAnteriores, 2)
+
+	// Las filas llegan en orden descendente con una de más
+	lista := filas(5, 4, 3)
+	pagina := model.NuevaPagina(lista, consulta, cursorFila)
+
+	if got := numeros(pagina); len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Fatalf("elementos %v, esperado [4 5]", got)
+	}
+	if !pagina.HayAnteriores || pagina.HayPosteriores {
+		t.Errorf("HayAnteriores=%v HayPosteriores=%v, esperado true/false", pagina.HayAnteriores, pagina.HayPosteriores)
+	}
+	if pagina.Anterior != cursorFila(lista[1]).String() || pagina.Siguiente != cursorFila(lista[0]).String() {
+		t.Errorf("cursores incorrectos: %q %q", pagina.Anterior, pagina.Siguiente)
+	}
+}
+
+func TestNuevaPagina_PosterioresDesdeCursor(t *testing.T) {
+	cursor := model.NewCursor(time.Unix(1, 0), uuid.New())
+	consulta, _ := model.NewConsultaPagina(cursor.String(), model.PaginaPosteriores, 3)
+
+	pagina := model.NuevaPagina(filas(2, 3), consulta, cursorFila)
+
+	if got := numeros(pagina); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("elementos %v, esperado [2 3]", got)
+	}
+	if !pagina.HayAnteriores || pagina.HayPosteriores {
+		t.Errorf("HayAnteriores=%v HayPosteriores=%v, esperado true/false", pagina.HayAnteriores, pagina.HayPosteriores)
+	}
+}
+
+func TestNuevaPagina_Vacia(t *testing.T) {
+	pagina := model.NuevaPagina(nil, model.ConsultaPagina{}, cursorFila)
+	if len(pagina.Elementos) != 0 || pagina.Anterior != "" || pagina.Siguiente != "" || pagina.HayAnteriores || pagina.HayPosteriores {
+		t.Errorf("página vacía incorrecta: %+v", pagina)
+	}
+}
@@ -3,6 +3,7 @@ package model
 import (
     "errors"
     "net"
+    "time"
 
     "github.com/google/uuid"
 )
@@ -17,9 +18,10 @@ var (
 
 // Peer representa un nodo en la red P2P.
 type Peer struct {
-    idNodo    uuid.UUID
-    direccion string
-    estado    NodoEstado
+    idNodo     uuid.UUID
+    direccion  string
+    estado     NodoEstado
+    ultimaSync time.Time // Última sincronización anti-entropía completada con éxito
 }
 
 // NewPeer crea un nuevo Peer validando sus invariantes:
@@ -71,3 +73,14 @@ func (p *Peer) Direccion() string {
 func (p *Peer) Estado() NodoEstado {
     return p.estado
 }
+
+// UltimaSync retorna el momento de la última sincronización anti-entropía exitosa
+// con el peer, o el tiempo cero si nunca se ha sincronizado.
+func (p *Peer) UltimaSync() time.Time {
+    return p.ultimaSync
+}
+
+// SetUltimaSync registra una sincronización anti-entropía completada con éxito.
+func (p *Peer) SetUltimaSync(t time.Time) {
+    p.ultimaSync = t
+}
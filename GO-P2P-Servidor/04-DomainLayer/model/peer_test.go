@@ -2,6 +2,7 @@ package model
 
 import (
     "testing"
+    "time"
 
     "github.com/google/uuid"
 )
@@ -63,3 +64,18 @@ func TestNewPeer_ErrorEstadoInvalido(t *testing.T) {
         t.Errorf("esperaba ErrPeerEstadoInvalido, obtuvo %v", err)
     }
 }
+
+func TestPeer_UltimaSync(t *testing.T) {
+    p, err := NewPeer(uuid.New(), "10.0.0.5:12000", NodoConectado)
+    if err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    if !p.UltimaSync().IsZero() {
+        t.Errorf("UltimaSync: esperado tiempo cero, obtuvo %v", p.UltimaSync())
+    }
+    ahora := time.Now()
+    p.SetUltimaSync(ahora)
+    if !p.UltimaSync().Equal(ahora) {
+        t.Errorf("UltimaSync: esperado %v, obtuvo %v", ahora, p.UltimaSync())
+    }
+}
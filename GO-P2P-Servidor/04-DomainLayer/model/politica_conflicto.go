@@ -0,0 +1,46 @@
+package model
+
+// Tipos de entidad replicada, usados como entidadTipo en ReplicaEvent.
+const (
+	EntidadUsuario      = "Usuario"
+	EntidadCanal        = "Canal"
+	EntidadCanalMiembro = "CanalMiembro"
+	EntidadMensaje      = "Mensaje"
+	EntidadReaccion     = "Reaccion"
+	EntidadFijado       = "Fijado"
+)
+
+// PoliticaConflicto define cómo se fusionan dos versiones concurrentes de una entidad replicada.
+type PoliticaConflicto string
+
+const (
+	// PoliticaLWW conserva la versión con el HLC mayor (last-writer-wins).
+	PoliticaLWW PoliticaConflicto = "LWW"
+	// PoliticaUnion conserva la unión de ambos conjuntos.
+	PoliticaUnion PoliticaConflicto = "UNION"
+	// PoliticaNuncaPerder conserva todas las versiones, sin descartar ninguna.
+	PoliticaNuncaPerder PoliticaConflicto = "NUNCA_PERDER"
+)
+
+// Valid comprueba que la PoliticaConflicto sea uno de los valores admitidos.
+func (p PoliticaConflicto) Valid() bool {
+	switch p {
+	case PoliticaLWW, PoliticaUnion, PoliticaNuncaPerder:
+		return true
+	default:
+		return false
+	}
+}
+
+// PoliticaPara devuelve la política de resolución asociada a un tipo de entidad.
+// Los tipos desconocidos se tratan como NUNCA_PERDER para no descartar datos.
+func PoliticaPara(entidadTipo string) PoliticaConflicto {
+	switch entidadTipo {
+	case EntidadUsuario, EntidadCanal, EntidadReaccion, EntidadFijado:
+		return PoliticaLWW
+	case EntidadCanalMiembro:
+		return PoliticaUnion
+	default:
+		return PoliticaNuncaPerder
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import "testing"
+
+func TestPoliticaConflicto_Valid(t *testing.T) {
+    validos := []PoliticaConflicto{
+        PoliticaLWW,
+        PoliticaUnion,
+        PoliticaNuncaPerder,
+    }
+    for _, p := range validos {
+        if !p.Valid() {
+            t.Errorf("PoliticaConflicto.Valid(): se esperaba %q válido", p)
+        }
+    }
+
+    invalidos := []PoliticaConflicto{"", "lww", "MERGE"}
+    for _, p := range invalidos {
+        if p.Valid() {
+            t.Errorf("PoliticaConflicto.Valid(): se esperaba %q inválido", p)
+        }
+    }
+}
+
+func TestPoliticaPara(t *testing.T) {
+    casos := []struct {
+        entidadTipo string
+        esperado    PoliticaConflicto
+    }{
+        {EntidadUsuario, PoliticaLWW},
+        {EntidadCanal, PoliticaLWW},
+        {EntidadCanalMiembro, PoliticaUnion},
+        {EntidadMensaje, PoliticaNuncaPerder},
+        {EntidadReaccion, PoliticaLWW},
+        {EntidadFijado, PoliticaLWW},
+        {"Desconocida", PoliticaNuncaPerder},
+    }
+    for _, c := range casos {
+        if got := PoliticaPara(c.entidadTipo); got != c.esperado {
+            t.Errorf("PoliticaPara(%q): esperado %q, obtuvo %q", c.entidadTipo, c.esperado, got)
+        }
+    }
+}
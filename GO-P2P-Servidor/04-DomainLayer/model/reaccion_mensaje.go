@@ -0,0 +1,129 @@
+package model
+
+import (
+	"errors"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para ReaccionMensaje
+var (
+	ErrReaccionMensajeIDNil  = errors.New("id del mensaje de la reacción inválido")
+	ErrReaccionUsuarioIDNil  = errors.New("id del usuario de la reacción inválido")
+	ErrReaccionEmojiInvalido = errors.New("emoji de la reacción inválido")
+	ErrReaccionFechaZero     = errors.New("fecha de la reacción no puede ser cero")
+	ErrReaccionRetirada      = errors.New("la reacción ya está retirada")
+)
+
+// LongitudMaximaEmoji es el número máximo de runas de un emoji de reacción; cubre las
+// secuencias unidas con ZWJ, los modificadores de tono y las banderas
+const LongitudMaximaEmoji = 16
+
+// ReaccionMensaje es la reacción de un usuario a un mensaje con un emoji. Solo hay una
+// por (mensaje, usuario, emoji); al retirarla se conserva con la marca de retirada para
+// que la replicación no la resucite.
+type ReaccionMensaje struct {
+	mensajeID  uuid.UUID
+	usuarioID  uuid.UUID
+	emoji      string
+	fecha      time.Time // Momento en que se añadió
+	retiradaAt time.Time // Cero si la reacción está vigente
+	version    HLC       // Versión para last-writer-wins entre nodos
+}
+
+// NewReaccionMensaje crea una ReaccionMensaje vigente validando sus invariantes
+func NewReaccionMensaje(mensajeID, usuarioID uuid.UUID, emoji string, fecha time.Time) (*ReaccionMensaje, error) {
+	if mensajeID == uuid.Nil {
+		return nil, ErrReaccionMensajeIDNil
+	}
+	if usuarioID == uuid.Nil {
+		return nil, ErrReaccionUsuarioIDNil
+	}
+	if !EmojiValido(emoji) {
+		return nil, ErrReaccionEmojiInvalido
+	}
+	if fecha.IsZero() {
+		return nil, ErrReaccionFechaZero
+	}
+	return &ReaccionMensaje{
+		mensajeID: mensajeID,
+		usuarioID: usuarioID,
+		emoji:     emoji,
+		fecha:     fecha,
+	}, nil
+}
+
+// EmojiValido indica si el texto es un único emoji o secuencia de emoji: sin letras,
+// espacios ni caracteres de control, con al menos un símbolo y como mucho
+// LongitudMaximaEmoji runas
+func EmojiValido(emoji string) bool {
+	if !utf8.ValidString(emoji) {
+		return false
+	}
+	n := utf8.RuneCountInString(emoji)
+	if n == 0 || n > LongitudMaximaEmoji {
+		return false
+	}
+	simbolo := false
+	for _, r := range emoji {
+		switch {
+		case unicode.IsLetter(r), unicode.IsSpace(r), unicode.IsControl(r):
+			return false
+		case unicode.Is(unicode.So, r), unicode.Is(unicode.Me, r):
+			simbolo = true
+		}
+	}
+	return simbolo
+}
+
+// Getters
+func (r *ReaccionMensaje) MensajeID() uuid.UUID  { return r.mensajeID }
+func (r *ReaccionMensaje) UsuarioID() uuid.UUID  { return r.usuarioID }
+func (r *ReaccionMensaje) Emoji() string         { return r.emoji }
+func (r *ReaccionMensaje) Fecha() time.Time      { return r.fecha }
+func (r *ReaccionMensaje) RetiradaAt() time.Time { return r.retiradaAt }
+func (r *ReaccionMensaje) Version() HLC          { return r.version }
+
+// Vigente indica si la reacción no se ha retirado
+func (r *ReaccionMensaje) Vigente() bool { return r.retiradaAt.IsZero() }
+
+// UltimoCambio devuelve el momento en que se retiró la reacción o, si está vigente,
+// en que se añadió
+func (r *ReaccionMensaje) UltimoCambio() time.Time {
+	if r.Vigente() {
+		return r.fecha
+	}
+	return r.retiradaAt
+}
+
+// Retirar marca la reacción como retirada
+func (r *ReaccionMensaje) Retirar(at time.Time) error {
+	if !r.Vigente() {
+		return ErrReaccionRetirada
+	}
+	if at.IsZero() {
+		return ErrReaccionFechaZero
+	}
+	r.retiradaAt = at
+	return nil
+}
+
+// SetRetiradaAt restaura la marca de retirada leída de la base de datos o de otro
+// nodo; cero deja la reacción vigente
+func (r *ReaccionMensaje) SetRetiradaAt(at time.Time) {
+	r.retiradaAt = at
+}
+
+// SetVersion asigna la versión HLC del último cambio de la reacción
+func (r *ReaccionMensaje) SetVersion(version HLC) {
+	r.version = version
+}
+
+// ConteoReaccion es el número de reacciones vigentes con un emoji en un mensaje
+type ConteoReaccion struct {
+	Emoji string
+	Total int
+}
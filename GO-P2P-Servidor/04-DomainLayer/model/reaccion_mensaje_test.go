@@ -0,0 +1,82 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewReaccionMensaje(t *testing.T) {
+	mensajeID, usuarioID := uuid.New(), uuid.New()
+	fecha := time.Now().UTC()
+
+	r, err := model.NewReaccionMensaje(mensajeID, usuarioID, "👍", fecha)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if r.MensajeID() != mensajeID || r.UsuarioID() != usuarioID || r.Emoji() != "👍" {
+		t.Errorf("campos inesperados: %v %v %q", r.MensajeID(), r.UsuarioID(), r.Emoji())
+	}
+	if !r.Vigente() || !r.UltimoCambio().Equal(fecha) {
+		t.Errorf("la reacción nueva debe estar vigente desde %v, obtuvo vigente=%v cambio=%v", fecha, r.Vigente(), r.UltimoCambio())
+	}
+
+	casos := []struct {
+		nombre               string
+		mensajeID, usuarioID uuid.UUID
+		emoji                string
+		fecha                time.Time
+		esperado             error
+	}{
+		{"mensaje nil", uuid.Nil, usuarioID, "👍", fecha, model.ErrReaccionMensajeIDNil},
+		{"usuario nil", mensajeID, uuid.Nil, "👍", fecha, model.ErrReaccionUsuarioIDNil},
+		{"emoji vacío", mensajeID, usuarioID, "", fecha, model.ErrReaccionEmojiInvalido},
+		{"fecha cero", mensajeID, usuarioID, "👍", time.Time{}, model.ErrReaccionFechaZero},
+	}
+	for _, c := range casos {
+		if _, err := model.NewReaccionMensaje(c.mensajeID, c.usuarioID, c.emoji, c.fecha); err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
+
+func TestEmojiValido(t *testing.T) {
+	validos := []string{"👍", "❤️", "🇪🇸", "👍🏽", "👨‍👩‍👧", "1️⃣", "✔"}
+	for _, e := range validos {
+		if !model.EmojiValido(e) {
+			t.Errorf("EmojiValido(%q): se esperaba válido", e)
+		}
+	}
+	invalidos := []string{"", "ok", ":)", "👍 👍", "a👍", "\n", "👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍", "\xff"}
+	for _, e := range invalidos {
+		if model.EmojiValido(e) {
+			t.Errorf("EmojiValido(%q): se esperaba inválido", e)
+		}
+	}
+}
+
+func TestReaccionMensaje_Retirar(t *testing.T) {
+	fecha := time.Now().UTC()
+	r, _ := model.NewReaccionMensaje(uuid.New(), uuid.New(), "🎉", fecha)
+
+	if err := r.Retirar(time.Time{}); err != model.ErrReaccionFechaZero {
+		t.Errorf("retirada sin fecha: esperado %v, obtuvo %v", model.ErrReaccionFechaZero, err)
+	}
+	retirada := fecha.Add(time.Minute)
+	if err := r.Retirar(retirada); err != nil {
+		t.Fatalf("Retirar: %v", err)
+	}
+	if r.Vigente() || !r.UltimoCambio().Equal(retirada) {
+		t.Errorf("la reacción debe quedar retirada en %v, obtuvo vigente=%v cambio=%v", retirada, r.Vigente(), r.UltimoCambio())
+	}
+	if err := r.Retirar(retirada); err != model.ErrReaccionRetirada {
+		t.Errorf("doble retirada: esperado %v, obtuvo %v", model.ErrReaccionRetirada, err)
+	}
+
+	r.SetRetiradaAt(time.Time{})
+	if !r.Vigente() {
+		t.Error("SetRetiradaAt con cero debe dejar la reacción vigente")
+	}
+}
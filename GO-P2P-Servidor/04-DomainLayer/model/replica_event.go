@@ -14,6 +14,7 @@ var (
     ErrEntidadIDNil            = errors.New("entidadId inválido")
     ErrReplicaEventAtZero      = errors.New("eventoAt no puede ser cero")
     ErrOrigenNodoIDNil         = errors.New("origenNodoId inválido")
+    ErrReplicaEventVersionZero = errors.New("versión HLC del evento no puede ser cero")
 )
 
 // ReplicaEvent representa un evento de replicación en la red P2P.
@@ -23,6 +24,7 @@ type ReplicaEvent struct {
     entidadID    uuid.UUID
     eventoAt     time.Time
     origenNodoID uuid.UUID
+    version      HLC // Versión HLC de la entidad en el momento del evento
 }
 
 // NewReplicaEvent crea un ReplicaEvent validando sus invariantes.
@@ -63,3 +65,25 @@ func (r *ReplicaEvent) EntidadTipo() string   { return r.entidadTipo }
 func (r *ReplicaEvent) EntidadID() uuid.UUID  { return r.entidadID }
 func (r *ReplicaEvent) EventoAt() time.Time   { return r.eventoAt }
 func (r *ReplicaEvent) OrigenNodoID() uuid.UUID { return r.origenNodoID }
+func (r *ReplicaEvent) Version() HLC            { return r.version }
+
+// NewReplicaEventVersionado crea un ReplicaEvent que transporta la versión HLC
+// de la entidad replicada, necesaria para resolver conflictos en el nodo receptor.
+func NewReplicaEventVersionado(
+    id uuid.UUID,
+    entidadTipo string,
+    entidadID uuid.UUID,
+    eventoAt time.Time,
+    origenNodoID uuid.UUID,
+    version HLC,
+) (*ReplicaEvent, error) {
+    if version.IsZero() {
+        return nil, ErrReplicaEventVersionZero
+    }
+    ev, err := NewReplicaEvent(id, entidadTipo, entidadID, eventoAt, origenNodoID)
+    if err != nil {
+        return nil, err
+    }
+    ev.version = version
+    return ev, nil
+}
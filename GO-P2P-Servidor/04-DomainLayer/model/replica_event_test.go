@@ -72,3 +72,20 @@ func TestNewReplicaEvent_Errors(t *testing.T) {
         })
     }
 }
+
+func TestNewReplicaEventVersionado(t *testing.T) {
+    origenID := uuid.New()
+    version, _ := model.NewHLC(time.Now().UnixNano(), 0, origenID)
+
+    re, err := model.NewReplicaEventVersionado(uuid.New(), model.EntidadUsuario, uuid.New(), time.Now(), origenID, version)
+    if err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    if re.Version().Compare(version) != 0 {
+        t.Errorf("Version: esperado %v, obtuvo %v", version, re.Version())
+    }
+
+    if _, err := model.NewReplicaEventVersionado(uuid.New(), model.EntidadUsuario, uuid.New(), time.Now(), origenID, model.HLC{}); err != model.ErrReplicaEventVersionZero {
+        t.Errorf("esperado ErrReplicaEventVersionZero, obtuvo %v", err)
+    }
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Errores de validación para ResumenHilo
+var (
+	ErrHiloRaizNil            = errors.New("mensaje raíz del hilo inválido")
+	ErrHiloRaizEsRespuesta    = errors.New("la raíz de un hilo no puede ser una respuesta")
+	ErrHiloRespuestasNegativo = errors.New("el número de respuestas no puede ser negativo")
+	ErrHiloUltimaRespuesta    = errors.New("la última respuesta no puede ser anterior a la raíz")
+)
+
+// ResumenHilo describe un hilo de conversación: su mensaje raíz, cuántas respuestas
+// no eliminadas tiene y cuándo llegó la última
+type ResumenHilo struct {
+	raiz            *MensajeServidor
+	respuestas      int
+	ultimaRespuesta time.Time // Cero si el hilo no tiene respuestas
+}
+
+// NewResumenHilo crea un ResumenHilo validando sus invariantes
+func NewResumenHilo(raiz *MensajeServidor, respuestas int, ultimaRespuesta time.Time) (*ResumenHilo, error) {
+	if raiz == nil {
+		return nil, ErrHiloRaizNil
+	}
+	if raiz.EsRespuesta() {
+		return nil, ErrHiloRaizEsRespuesta
+	}
+	if respuestas < 0 {
+		return nil, ErrHiloRespuestasNegativo
+	}
+	if !ultimaRespuesta.IsZero() && ultimaRespuesta.Before(raiz.Timestamp()) {
+		return nil, ErrHiloUltimaRespuesta
+	}
+	return &ResumenHilo{raiz: raiz, respuestas: respuestas, ultimaRespuesta: ultimaRespuesta}, nil
+}
+
+// Getters
+func (h *ResumenHilo) Raiz() *MensajeServidor     { return h.raiz }
+func (h *ResumenHilo) Respuestas() int            { return h.respuestas }
+func (h *ResumenHilo) UltimaRespuesta() time.Time { return h.ultimaRespuesta }
@@ -0,0 +1,47 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewResumenHilo(t *testing.T) {
+	now := time.Now().UTC()
+	canal := uuid.New()
+	raiz, _ := model.NewMensajeCanal(uuid.New(), uuid.New(), canal, "raíz", now, uuid.Nil)
+	respuesta, _ := model.NewMensajeCanal(uuid.New(), uuid.New(), canal, "respuesta", now.Add(time.Minute), uuid.Nil)
+	if err := respuesta.ResponderA(raiz); err != nil {
+		t.Fatalf("ResponderA: %v", err)
+	}
+
+	h, err := model.NewResumenHilo(raiz, 2, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if h.Raiz() != raiz || h.Respuestas() != 2 || !h.UltimaRespuesta().Equal(now.Add(time.Minute)) {
+		t.Errorf("resumen inesperado: %v %d %v", h.Raiz().ID(), h.Respuestas(), h.UltimaRespuesta())
+	}
+	if _, err := model.NewResumenHilo(raiz, 0, time.Time{}); err != nil {
+		t.Errorf("un hilo sin respuestas es válido, obtuvo %v", err)
+	}
+
+	casos := []struct {
+		raiz       *model.MensajeServidor
+		respuestas int
+		ultima     time.Time
+		esperado   error
+	}{
+		{nil, 0, time.Time{}, model.ErrHiloRaizNil},
+		{respuesta, 0, time.Time{}, model.ErrHiloRaizEsRespuesta},
+		{raiz, -1, time.Time{}, model.ErrHiloRespuestasNegativo},
+		{raiz, 1, now.Add(-time.Minute), model.ErrHiloUltimaRespuesta},
+	}
+	for _, c := range casos {
+		if _, err := model.NewResumenHilo(c.raiz, c.respuestas, c.ultima); err != c.esperado {
+			t.Errorf("esperado %v, obtuvo %v", c.esperado, err)
+		}
+	}
+}
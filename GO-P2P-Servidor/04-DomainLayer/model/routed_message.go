@@ -12,11 +12,15 @@ var (
     ErrRoutedMessageMensajeIDNil   = errors.New("mensajeId inválido")
     ErrRoutedMessageDestinoIDNil   = errors.New("nodoDestinoId inválido")
     ErrRoutedMessageEnrutaAtZero   = errors.New("enrutaAt no puede ser cero")
+    ErrRoutedMessageAnteriorIDNil  = errors.New("nodoAnteriorId inválido")
+    ErrRoutedMessageSaltoCircular  = errors.New("nodoAnteriorId no puede ser el nodo destino")
 )
 
 // RoutedMessage representa el enrutamiento de un mensaje a un peer destino.
+// En rutas de varios saltos, nodoAnteriorID indica desde qué nodo llegó el mensaje.
 type RoutedMessage struct {
     mensajeID      uuid.UUID
+    nodoAnteriorID uuid.UUID
     nodoDestinoID  uuid.UUID
     enrutaAt       time.Time
 }
@@ -45,7 +49,28 @@ func NewRoutedMessage(
     }, nil
 }
 
+// NewRoutedMessageSalto crea un RoutedMessage que forma parte de una ruta de varios
+// saltos: el mensaje pasó de nodoAnteriorID a nodoDestinoID en enrutaAt.
+func NewRoutedMessageSalto(
+    mensajeID, nodoAnteriorID, nodoDestinoID uuid.UUID,
+    enrutaAt time.Time,
+) (*RoutedMessage, error) {
+    if nodoAnteriorID == uuid.Nil {
+        return nil, ErrRoutedMessageAnteriorIDNil
+    }
+    if nodoAnteriorID == nodoDestinoID {
+        return nil, ErrRoutedMessageSaltoCircular
+    }
+    r, err := NewRoutedMessage(mensajeID, nodoDestinoID, enrutaAt)
+    if err != nil {
+        return nil, err
+    }
+    r.nodoAnteriorID = nodoAnteriorID
+    return r, nil
+}
+
 // Getters
-func (r *RoutedMessage) MensajeID() uuid.UUID     { return r.mensajeID }
-func (r *RoutedMessage) NodoDestinoID() uuid.UUID { return r.nodoDestinoID }
-func (r *RoutedMessage) EnrutaAt() time.Time      { return r.enrutaAt }
+func (r *RoutedMessage) MensajeID() uuid.UUID      { return r.mensajeID }
+func (r *RoutedMessage) NodoAnteriorID() uuid.UUID { return r.nodoAnteriorID }
+func (r *RoutedMessage) NodoDestinoID() uuid.UUID  { return r.nodoDestinoID }
+func (r *RoutedMessage) EnrutaAt() time.Time       { return r.enrutaAt }
@@ -59,3 +59,38 @@ func TestNewRoutedMessage_Errors(t *testing.T) {
         })
     }
 }
+
+func TestNewRoutedMessageSalto(t *testing.T) {
+    msgID, anterior, destino := uuid.New(), uuid.New(), uuid.New()
+    now := time.Now().UTC()
+
+    rm, err := NewRoutedMessageSalto(msgID, anterior, destino, now)
+    if err != nil {
+        t.Fatalf("esperaba sin error, obtuvo %v", err)
+    }
+    if rm.NodoAnteriorID() != anterior {
+        t.Errorf("NodoAnteriorID: esperado %v, obtuvo %v", anterior, rm.NodoAnteriorID())
+    }
+    if rm.NodoDestinoID() != destino {
+        t.Errorf("NodoDestinoID: esperado %v, obtuvo %v", destino, rm.NodoDestinoID())
+    }
+
+    cases := []struct {
+        name     string
+        anterior uuid.UUID
+        destino  uuid.UUID
+        wantErr  error
+    }{
+        {"NodoAnteriorID inválido", uuid.Nil, destino, ErrRoutedMessageAnteriorIDNil},
+        {"Salto circular", destino, destino, ErrRoutedMessageSaltoCircular},
+        {"NodoDestinoID inválido", anterior, uuid.Nil, ErrRoutedMessageDestinoIDNil},
+    }
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            _, err := NewRoutedMessageSalto(msgID, c.anterior, c.destino, now)
+            if err != c.wantErr {
+                t.Errorf("%s: esperado %v, obtuvo %v", c.name, c.wantErr, err)
+            }
+        })
+    }
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errores de validación para SilencioMenciones
+var (
+	ErrSilencioUsuarioIDNil    = errors.New("id del usuario del silencio inválido")
+	ErrSilencioNivelInvalido   = errors.New("nivel de silencio de menciones inválido")
+	ErrSilencioHastaAnterior   = errors.New("el silencio no puede terminar antes de configurarse")
+	ErrSilencioActualizadoZero = errors.New("fecha de configuración del silencio no puede ser cero")
+)
+
+// NivelSilencio indica qué menciones deja de notificar un silencio
+type NivelSilencio string
+
+// Constantes para los niveles de silencio
+const (
+	SilencioNinguno NivelSilencio = "NINGUNO" // Se notifican todas las menciones
+	SilencioCanal   NivelSilencio = "CANAL"   // Solo se notifican las menciones por nombre
+	SilencioTodo    NivelSilencio = "TODO"    // No se notifica ninguna mención
+)
+
+// Valid verifica si el valor es un nivel de silencio válido
+func (n NivelSilencio) Valid() bool {
+	return n == SilencioNinguno || n == SilencioCanal || n == SilencioTodo
+}
+
+// SilencioMenciones es la preferencia de un usuario sobre las menciones de un canal o,
+// con canalID uuid.Nil, de todos sus canales. Mientras está vigente, la preferencia
+// de un canal prevalece sobre la general. Se replica a todos los nodos y las copias
+// se fusionan por su fecha de configuración, ganando la más reciente.
+type SilencioMenciones struct {
+	usuarioID   uuid.UUID
+	canalID     uuid.UUID // uuid.Nil si se aplica a todos los canales
+	nivel       NivelSilencio
+	hasta       time.Time // Fin del silencio; cero si no termina
+	actualizado time.Time // Fecha de configuración, para fusionar copias
+}
+
+// NewSilencioMenciones crea un SilencioMenciones validando sus invariantes
+func NewSilencioMenciones(
+	usuarioID, canalID uuid.UUID,
+	nivel NivelSilencio,
+	hasta, actualizado time.Time,
+) (*SilencioMenciones, error) {
+	if usuarioID == uuid.Nil {
+		return nil, ErrSilencioUsuarioIDNil
+	}
+	if !nivel.Valid() {
+		return nil, ErrSilencioNivelInvalido
+	}
+	if actualizado.IsZero() {
+		return nil, ErrSilencioActualizadoZero
+	}
+	if !hasta.IsZero() && !hasta.After(actualizado) {
+		return nil, ErrSilencioHastaAnterior
+	}
+	if !hasta.IsZero() {
+		hasta = hasta.UTC()
+	}
+	return &SilencioMenciones{
+		usuarioID:   usuarioID,
+		canalID:     canalID,
+		nivel:       nivel,
+		hasta:       hasta,
+		actualizado: actualizado.UTC(),
+	}, nil
+}
+
+// Getters
+func (s *SilencioMenciones) UsuarioID() uuid.UUID   { return s.usuarioID }
+func (s *SilencioMenciones) CanalID() uuid.UUID     { return s.canalID }
+func (s *SilencioMenciones) Nivel() NivelSilencio   { return s.nivel }
+func (s *SilencioMenciones) Hasta() time.Time       { return s.hasta }
+func (s *SilencioMenciones) Actualizado() time.Time { return s.actualizado }
+
+// Vigente indica si el silencio sigue aplicándose en at
+func (s *SilencioMenciones) Vigente(at time.Time) bool {
+	return s.hasta.IsZero() || at.Before(s.hasta)
+}
+
+// Silencia indica si el silencio impide notificar en at una mención del tipo dado. Un
+// silencio vencido equivale a SilencioNinguno.
+func (s *SilencioMenciones) Silencia(tipo TipoMencion, at time.Time) bool {
+	if !s.Vigente(at) {
+		return false
+	}
+	switch s.nivel {
+	case SilencioTodo:
+		return true
+	case SilencioCanal:
+		return tipo == MencionCanal
+	default:
+		return false
+	}
+}
+
+// Fusionar adopta otra copia de la misma preferencia si se configuró después que la
+// propia. Devuelve false, sin cambios, si no es así.
+func (s *SilencioMenciones) Fusionar(otro *SilencioMenciones) bool {
+	if otro.usuarioID != s.usuarioID || otro.canalID != s.canalID || !otro.actualizado.After(s.actualizado) {
+		return false
+	}
+	s.nivel = otro.nivel
+	s.hasta = otro.hasta
+	s.actualizado = otro.actualizado
+	return true
+}
@@ -0,0 +1,76 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestNewSilencioMenciones(t *testing.T) {
+	usuarioID := uuid.New()
+	ahora := time.Now()
+
+	if _, err := model.NewSilencioMenciones(usuarioID, uuid.Nil, model.SilencioTodo, time.Time{}, ahora); err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+
+	casos := []struct {
+		nombre             string
+		usuarioID          uuid.UUID
+		nivel              model.NivelSilencio
+		hasta, actualizado time.Time
+		esperado           error
+	}{
+		{"usuario nil", uuid.Nil, model.SilencioTodo, time.Time{}, ahora, model.ErrSilencioUsuarioIDNil},
+		{"nivel inválido", usuarioID, "ALGUNAS", time.Time{}, ahora, model.ErrSilencioNivelInvalido},
+		{"sin fecha", usuarioID, model.SilencioTodo, time.Time{}, time.Time{}, model.ErrSilencioActualizadoZero},
+		{"termina antes", usuarioID, model.SilencioTodo, ahora.Add(-time.Minute), ahora, model.ErrSilencioHastaAnterior},
+	}
+	for _, c := range casos {
+		_, err := model.NewSilencioMenciones(c.usuarioID, uuid.Nil, c.nivel, c.hasta, c.actualizado)
+		if err != c.esperado {
+			t.Errorf("%s: esperado %v, obtuvo %v", c.nombre, c.esperado, err)
+		}
+	}
+}
+
+func TestSilencioMenciones_Silencia(t *testing.T) {
+	ahora := time.Now()
+	todo, _ := model.NewSilencioMenciones(uuid.New(), uuid.New(), model.SilencioTodo, ahora.Add(time.Hour), ahora)
+	canal, _ := model.NewSilencioMenciones(uuid.New(), uuid.New(), model.SilencioCanal, time.Time{}, ahora)
+	ninguno, _ := model.NewSilencioMenciones(uuid.New(), uuid.New(), model.SilencioNinguno, time.Time{}, ahora)
+
+	if !todo.Silencia(model.MencionUsuario, ahora) || !todo.Silencia(model.MencionCanal, ahora) {
+		t.Error("TODO silencia cualquier mención mientras está vigente")
+	}
+	if todo.Silencia(model.MencionUsuario, ahora.Add(time.Hour)) {
+		t.Error("un silencio vencido no silencia")
+	}
+	if canal.Silencia(model.MencionUsuario, ahora) || !canal.Silencia(model.MencionCanal, ahora) {
+		t.Error("CANAL solo silencia las menciones a todo el canal")
+	}
+	if ninguno.Silencia(model.MencionCanal, ahora) {
+		t.Error("NINGUNO no silencia")
+	}
+}
+
+func TestSilencioMenciones_Fusionar(t *testing.T) {
+	ahora := time.Now()
+	usuarioID, canalID := uuid.New(), uuid.New()
+	local, _ := model.NewSilencioMenciones(usuarioID, canalID, model.SilencioTodo, time.Time{}, ahora)
+	posterior, _ := model.NewSilencioMenciones(usuarioID, canalID, model.SilencioCanal, ahora.Add(2*time.Hour), ahora.Add(time.Minute))
+
+	if !local.Fusionar(posterior) || local.Nivel() != model.SilencioCanal || !local.Hasta().Equal(posterior.Hasta()) {
+		t.Fatalf("la configuración posterior debe aplicarse: %s %v", local.Nivel(), local.Hasta())
+	}
+	anterior, _ := model.NewSilencioMenciones(usuarioID, canalID, model.SilencioNinguno, time.Time{}, ahora)
+	if local.Fusionar(anterior) || local.Fusionar(posterior) {
+		t.Error("una configuración anterior o igual no debe aplicarse")
+	}
+	otroCanal, _ := model.NewSilencioMenciones(usuarioID, uuid.Nil, model.SilencioNinguno, time.Time{}, ahora.Add(time.Hour))
+	if local.Fusionar(otroCanal) {
+		t.Error("no se fusionan preferencias de canales distintos")
+	}
+}
@@ -27,6 +27,7 @@ type UsuarioServidor struct {
     ipRegistrada       string
     fechaRegistro      time.Time
     isConnected        bool
+    version            HLC // Versión HLC de la última modificación replicada
 }
 
 // NewUsuarioServidor construye un nuevo UsuarioServidor garantizando invariantes.
@@ -71,8 +72,14 @@ func (u *UsuarioServidor) FotoURL() string            { return u.fotoURL }
 func (u *UsuarioServidor) IPRegistrada() string       { return u.ipRegistrada }
 func (u *UsuarioServidor) FechaRegistro() time.Time   { return u.fechaRegistro }
 func (u *UsuarioServidor) IsConnected() bool          { return u.isConnected }
+func (u *UsuarioServidor) Version() HLC               { return u.version }
 
 // SetConnected establece el estado de conexión del usuario
 func (u *UsuarioServidor) SetConnected(connected bool) {
     u.isConnected = connected
 }
+
+// SetVersion asigna la marca HLC de la última modificación del perfil
+func (u *UsuarioServidor) SetVersion(version HLC) {
+    u.version = version
+}
@@ -20,8 +20,18 @@ type IChannelRepository interface {
     AddMember(ctx context.Context, channelID, userID uuid.UUID, rol string) error
     RemoveMember(ctx context.Context, channelID, userID uuid.UUID) error
     ListMembers(ctx context.Context, channelID uuid.UUID) ([]uuid.UUID, error)
+    FindMembers(ctx context.Context, channelID uuid.UUID) ([]*model.CanalMiembro, error)
+    SaveMember(ctx context.Context, m *model.CanalMiembro) error
 
     SaveInvitation(ctx context.Context, inv *model.InvitacionCanal) error
     UpdateInvitation(ctx context.Context, inv *model.InvitacionCanal) error
     ListInvitations(ctx context.Context, channelID uuid.UUID) ([]*model.InvitacionCanal, error)
+
+    // Mensajes y enlaces fijados. Los desfijados se conservan retirados para la
+    // replicación; ListPins y CountPins solo devuelven los vigentes y FindPin
+    // devuelve nil si el elemento no existe
+    SavePin(ctx context.Context, f *model.FijadoCanal) error
+    FindPin(ctx context.Context, id uuid.UUID) (*model.FijadoCanal, error)
+    ListPins(ctx context.Context, channelID uuid.UUID) ([]*model.FijadoCanal, error)
+    CountPins(ctx context.Context, channelIDs []uuid.UUID) (map[uuid.UUID]int, error)
 }
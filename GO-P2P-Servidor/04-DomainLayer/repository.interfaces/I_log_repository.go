@@ -14,9 +14,11 @@ type ILogRepository interface {
     Delete(ctx context.Context, id uuid.UUID) error
     FindByID(ctx context.Context, id uuid.UUID) (*model.LogEntry, error)
 
-    // Consultas de auditoría
-    ListAll(ctx context.Context) ([]*model.LogEntry, error)
-    ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.LogEntry, error)
-    ListByType(ctx context.Context, t model.EventoTipo) ([]*model.LogEntry, error)
-    ListByDateRange(ctx context.Context, from, to time.Time) ([]*model.LogEntry, error)
+    // Consultas de auditoría, paginadas por (timestamp, id)
+    ListAll(ctx context.Context, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error)
+    ListByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error)
+    ListByType(ctx context.Context, t model.EventoTipo, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error)
+    ListByDateRange(ctx context.Context, from, to time.Time, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error)
+    // ListFiltered combina los criterios anteriores; los argumentos con su valor cero no filtran
+    ListFiltered(ctx context.Context, userID uuid.UUID, types []model.EventoTipo, from, to time.Time, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error)
 }
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// IMentionRepository define las operaciones para el feed de menciones y las
+// preferencias de silencio de menciones de los usuarios
+type IMentionRepository interface {
+    // Save falla si el usuario ya tiene una mención del mismo mensaje
+    Save(ctx context.Context, m *model.Mencion) error
+    // FindByKey devuelve nil si el usuario no tiene mención del mensaje
+    FindByKey(ctx context.Context, userID, messageID uuid.UUID) (*model.Mencion, error)
+    // FindByUser pagina por (fecha, id) las menciones de un usuario
+    FindByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.Mencion], error)
+
+    // SaveMute crea o sustituye la preferencia del usuario para su canal
+    SaveMute(ctx context.Context, s *model.SilencioMenciones) error
+    // FindMute devuelve nil si el usuario no ha configurado el canal; uuid.Nil es la
+    // preferencia general
+    FindMute(ctx context.Context, userID, channelID uuid.UUID) (*model.SilencioMenciones, error)
+    // FindMutes devuelve todas las preferencias de un usuario
+    FindMutes(ctx context.Context, userID uuid.UUID) ([]*model.SilencioMenciones, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 	
 	"github.com/google/uuid"
 	"model"
@@ -15,8 +16,51 @@ type IMessageRepository interface {
     Delete(ctx context.Context, id uuid.UUID) error
     FindByID(ctx context.Context, id uuid.UUID) (*model.MensajeServidor, error)
 
-    // Consultas por canal/usuario
-    FindByChannel(ctx context.Context, channelID uuid.UUID, limit, offset int) ([]*model.MensajeServidor, error)
-    FindByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*model.MensajeServidor, error)
-    FindDirect(ctx context.Context, a, b uuid.UUID, limit, offset int) ([]*model.MensajeServidor, error)
+    // Consultas por canal/usuario, paginadas por (timestamp, id)
+    FindByChannel(ctx context.Context, channelID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error)
+    FindByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error)
+    FindDirect(ctx context.Context, a, b uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error)
+    FindByPrivateChat(ctx context.Context, chatID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error)
+
+    // Participantes de un chat privado, destinatarios de sus mensajes
+    FindChatParticipants(ctx context.Context, chatID uuid.UUID) ([]uuid.UUID, error)
+
+    // Consultas por rango temporal [from, to) para anti-entropía
+    FindByTimeRange(ctx context.Context, from, to time.Time) ([]*model.MensajeServidor, error)
+
+    // Hilos: respuestas de un mensaje raíz y resúmenes con el número de respuestas y
+    // la fecha de la última. FindThreadSummary devuelve nil si el mensaje no existe
+    FindReplies(ctx context.Context, parentID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error)
+    FindThreads(ctx context.Context, channelID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.ResumenHilo], error)
+    FindThreadSummary(ctx context.Context, parentID uuid.UUID) (*model.ResumenHilo, error)
+
+    // Historial de ediciones y borrados de un mensaje
+    SaveEdit(ctx context.Context, e *model.EdicionMensaje) error
+    FindEdits(ctx context.Context, messageID uuid.UUID) ([]*model.EdicionMensaje, error)
+
+    // Reacciones por (mensaje, usuario, emoji). Las retiradas se conservan con su marca
+    // para la replicación; CountReactions solo cuenta las vigentes y FindReaction
+    // devuelve nil si el usuario nunca reaccionó con ese emoji
+    SaveReaction(ctx context.Context, r *model.ReaccionMensaje) error
+    FindReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) (*model.ReaccionMensaje, error)
+    CountReactions(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]model.ConteoReaccion, error)
+    FindReactionsByTimeRange(ctx context.Context, from, to time.Time) ([]*model.ReaccionMensaje, error)
+
+    // Acuses de entrega y lectura por (mensaje, destinatario); un destinatario sin acuse
+    // está en ENVIADO. FindReceipt devuelve nil si no hay acuse
+    SaveReceipt(ctx context.Context, a *model.AcuseMensaje) error
+    FindReceipt(ctx context.Context, messageID, userID uuid.UUID) (*model.AcuseMensaje, error)
+    FindReceipts(ctx context.Context, messageID uuid.UUID) ([]*model.AcuseMensaje, error)
+
+    // Último mensaje leído por cada usuario en cada conversación (canal, chat privado u
+    // otro usuario en los directos) y mensajes sin leer por conversación a partir de él.
+    // FindReadMarker devuelve nil si el usuario no ha leído nada en la conversación
+    SaveReadMarker(ctx context.Context, m *model.MarcadorLectura) error
+    FindReadMarker(ctx context.Context, userID, conversationID uuid.UUID) (*model.MarcadorLectura, error)
+    CountUnread(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error)
+
+    // Búsqueda de texto completo entre los mensajes no eliminados que userID puede ver:
+    // los de sus canales y chats privados y sus mensajes directos. Todos los términos
+    // de la consulta deben aparecer; paginada por (timestamp, id)
+    Search(ctx context.Context, userID uuid.UUID, query string, filter model.FiltroBusqueda, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error)
 }
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// INotificationRepository define las operaciones para las notificaciones de los usuarios
+type INotificationRepository interface {
+    Save(ctx context.Context, n *model.Notificacion) error
+    // FindByID devuelve nil si la notificación no existe
+    FindByID(ctx context.Context, id uuid.UUID) (*model.Notificacion, error)
+    // FindByUser devuelve las notificaciones de un usuario, de la más reciente a la más antigua
+    FindByUser(ctx context.Context, userID uuid.UUID) ([]*model.Notificacion, error)
+    // FindPageByUser pagina por (fecha, id) las notificaciones de un usuario
+    FindPageByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.Notificacion], error)
+    MarkRead(ctx context.Context, id uuid.UUID) error
+}
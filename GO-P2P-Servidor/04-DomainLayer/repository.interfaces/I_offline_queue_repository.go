@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// IOfflineQueueRepository define las operaciones para la cola de eventos pendientes de
+// los usuarios desconectados
+type IOfflineQueueRepository interface {
+    Enqueue(ctx context.Context, e *model.EventoPendiente) error
+    CountPending(ctx context.Context, userID uuid.UUID) (int, error)
+
+    // FindPending pagina por (fecha, id) la cola de un usuario
+    FindPending(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.EventoPendiente], error)
+    // AcknowledgeUpTo borra los eventos hasta el cursor incluido y devuelve cuántos borró
+    AcknowledgeUpTo(ctx context.Context, userID uuid.UUID, position model.Cursor) (int64, error)
+    // Trim deja solo los keep eventos más recientes y devuelve cuántos borró
+    Trim(ctx context.Context, userID uuid.UUID, keep int) (int64, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 	
 	"github.com/google/uuid"
 	"model"
@@ -15,4 +16,8 @@ type IPeerRepository interface {
     FindByID(ctx context.Context, id uuid.UUID) (*model.Peer, error)
     ListAll(ctx context.Context) ([]*model.Peer, error)
     ListByState(ctx context.Context, state model.NodoEstado) ([]*model.Peer, error)
+
+    // Anti-entropía: registra la última sincronización con el peer y, si aún no tiene
+    // fila, lo da de alta como conectado en address (host:puerto)
+    UpdateLastSync(ctx context.Context, id uuid.UUID, address string, at time.Time) error
 }
@@ -12,4 +12,7 @@ type IRoutedMessageRepository interface {
     Save(ctx context.Context, r *model.RoutedMessage) error
     FindByMessage(ctx context.Context, messageID uuid.UUID) ([]*model.RoutedMessage, error)
     DeleteByMessage(ctx context.Context, messageID uuid.UUID) error
+
+    // ListByDestination pagina por (enruta_at, mensaje) los saltos enviados a un nodo
+    ListByDestination(ctx context.Context, nodeID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.RoutedMessage], error)
 }
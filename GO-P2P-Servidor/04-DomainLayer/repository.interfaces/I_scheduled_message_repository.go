@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// IScheduledMessageRepository define las operaciones para los mensajes programados y
+// con caducidad
+type IScheduledMessageRepository interface {
+    Save(ctx context.Context, p *model.MensajeProgramado) error
+    // FindByID devuelve nil si el mensaje programado no existe
+    FindByID(ctx context.Context, id uuid.UUID) (*model.MensajeProgramado, error)
+
+    // UpdateState guarda el estado de p solo si el guardado sigue en from; devuelve
+    // false si otro cambio se adelantó
+    UpdateState(ctx context.Context, p *model.MensajeProgramado, from model.EstadoProgramado) (bool, error)
+    // FindDue devuelve hasta limit mensajes de nodeID que requieren acción en at
+    FindDue(ctx context.Context, nodeID uuid.UUID, at time.Time, limit int) ([]*model.MensajeProgramado, error)
+    // FindBySender pagina por (enviar_en, id) los mensajes programados por un usuario
+    FindBySender(ctx context.Context, senderID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeProgramado], error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+)
+
+// IUnitOfWork agrupa operaciones de varios repositorios en una sola transacción
+type IUnitOfWork interface {
+    // Do ejecuta fn en una transacción que se confirma si fn no devuelve error y se
+    // revierte en caso contrario. Los repositorios llamados con el ctx que recibe fn
+    // participan en la transacción. fn puede repetirse si la transacción se aborta por
+    // un interbloqueo, así que no debe tener efectos fuera de los repositorios.
+    Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
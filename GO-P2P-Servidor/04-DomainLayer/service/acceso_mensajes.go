@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Reglas de acceso a los mensajes compartidas por los servicios. En los chats privados
+// solo se reconoce al autor: la participación en el chat no está en los repositorios
+// del dominio.
+
+// puedeVerMensaje indica si el usuario tiene acceso a la conversación del mensaje: es su
+// autor o destinatario directo o es miembro del canal
+func puedeVerMensaje(ctx context.Context, channels repository.IChannelRepository, userID uuid.UUID, m *model.MensajeServidor) (bool, error) {
+	switch {
+	case userID == m.RemitenteID() || userID == m.DestinoUsuarioID():
+		return true, nil
+	case m.CanalID() != uuid.Nil:
+		return esMiembroCanal(ctx, channels, m.CanalID(), userID)
+	}
+	return false, nil
+}
+
+// esMiembroCanal indica si el usuario pertenece al canal
+func esMiembroCanal(ctx context.Context, channels repository.IChannelRepository, channelID, userID uuid.UUID) (bool, error) {
+	miembros, err := channels.ListMembers(ctx, channelID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range miembros {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// destinatariosMensaje devuelve los usuarios que pueden ver el mensaje, a quienes se
+// envían los eventos de cliente sobre él
+func destinatariosMensaje(ctx context.Context, channels repository.IChannelRepository, m *model.MensajeServidor) ([]uuid.UUID, error) {
+	switch {
+	case m.DestinoUsuarioID() != uuid.Nil:
+		return []uuid.UUID{m.RemitenteID(), m.DestinoUsuarioID()}, nil
+	case m.CanalID() != uuid.Nil:
+		return channels.ListMembers(ctx, m.CanalID())
+	}
+	return []uuid.UUID{m.RemitenteID()}, nil
+}
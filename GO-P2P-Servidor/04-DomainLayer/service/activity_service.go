@@ -0,0 +1,494 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de la actividad efímera
+var (
+	ErrActividadSinAcceso = errors.New("el usuario no participa en la conversación")
+)
+
+// ActivityConfig contiene los parámetros de la actividad efímera
+type ActivityConfig struct {
+	Antirrebote         time.Duration // Tiempo mínimo entre dos difusiones de una actividad en curso
+	DuracionEscribiendo time.Duration // Tiempo sin renovar tras el que vence ESCRIBIENDO
+	DuracionViendo      time.Duration // Tiempo sin renovar tras el que vence VIENDO
+	IntervaloExpiracion time.Duration // Periodo de la revisión de actividades vencidas
+	RetencionSecuencias time.Duration // Tiempo que se recuerda la última secuencia de cada actividad
+}
+
+// DefaultActivityConfig devuelve la configuración por defecto. Las duraciones superan
+// con holgura al antirrebote para que las renovaciones lleguen a los demás nodos antes
+// de que la actividad venza en ellos.
+func DefaultActivityConfig() ActivityConfig {
+	return ActivityConfig{
+		Antirrebote:         3 * time.Second,
+		DuracionEscribiendo: 8 * time.Second,
+		DuracionViendo:      60 * time.Second,
+		IntervaloExpiracion: time.Second,
+		RetencionSecuencias: 5 * time.Minute,
+	}
+}
+
+// ActivityService define la actividad efímera de los usuarios en sus conversaciones:
+// quién está escribiendo y quién tiene abierta cada conversación. Las actividades se
+// envían como evento a los demás participantes conectados y se difunden a todos los
+// nodos por inundación, pero nunca se persisten. El servidor limita la frecuencia de
+// las difusiones y da por terminada una actividad que no se renueva a tiempo, así que
+// un cliente caído no la deja colgada.
+type ActivityService interface {
+	// Report registra que el usuario empieza o sigue con la actividad en la conversación.
+	// Los informes repetidos dentro del antirrebote solo alargan la actividad.
+	Report(userID, conversationID uuid.UUID, activity model.TipoActividad) (*model.Actividad, error)
+
+	// Stop termina la actividad del usuario en la conversación; no hace nada si no estaba
+	// en curso
+	Stop(userID, conversationID uuid.UUID, activity model.TipoActividad) error
+
+	// ClearUser termina todas las actividades del usuario iniciadas en este nodo, al
+	// cerrarse su conexión
+	ClearUser(userID uuid.UUID)
+
+	// ListActive devuelve las actividades en curso de los demás participantes de la
+	// conversación, para el cliente que la abre
+	ListActive(userID, conversationID uuid.UUID) ([]*model.Actividad, error)
+
+	// Start lanza la expiración periódica de las actividades hasta que ctx termine
+	Start(ctx context.Context)
+}
+
+// actividadDifundida es el payload JSON de los frames FrameTipoActividad. Secuencia la
+// asigna el nodo en el que se originó el cambio y crece con cada uno: un nodo descarta
+// los cambios que no superan el último visto de la misma actividad, lo que detiene la
+// inundación y evita que una renovación atrasada reviva una actividad terminada.
+type actividadDifundida struct {
+	UsuarioID      uuid.UUID `json:"usuarioId"`
+	ConversacionID uuid.UUID `json:"conversacionId"`
+	Tipo           string    `json:"tipo"`
+	Activa         bool      `json:"activa"`
+	DuracionMs     int64     `json:"duracionMs"`
+	Secuencia      int64     `json:"secuencia"`
+}
+
+// avisoActividad es el evento que reciben los participantes de la conversación. En un
+// mensaje directo ConversacionID es el autor de la actividad, que es la conversación tal
+// como la ve el otro usuario.
+type avisoActividad struct {
+	UsuarioID      uuid.UUID `json:"usuarioId"`
+	ConversacionID uuid.UUID `json:"conversacionId"`
+	Tipo           string    `json:"tipo"`
+	Activa         bool      `json:"activa"`
+	ExpiraEnMs     int64     `json:"expiraEnMs,omitempty"`
+}
+
+// claveActividad identifica una actividad en curso
+type claveActividad struct {
+	usuarioID      uuid.UUID
+	conversacionID uuid.UUID
+	tipo           model.TipoActividad
+}
+
+// entradaActividad es una actividad en curso; difundida es la última vez que este nodo
+// la difundió, cero si llegó de otro nodo
+type entradaActividad struct {
+	actividad *model.Actividad
+	difundida time.Time
+}
+
+// secuenciaVista es la última secuencia conocida de una actividad
+type secuenciaVista struct {
+	secuencia int64
+	vista     time.Time
+}
+
+// activityService implementa ActivityService sobre PeerTransport y ClientTransport
+type activityService struct {
+	transport PeerTransport
+	clients   ClientTransport
+	channels  repository.IChannelRepository
+	messages  repository.IMessageRepository
+	config    ActivityConfig
+
+	mu              sync.Mutex
+	actividades     map[claveActividad]*entradaActividad
+	secuencias      map[claveActividad]secuenciaVista
+	ultimaSecuencia int64
+}
+
+// NewActivityService crea el servicio y registra su handler de frames en el transporte
+func NewActivityService(
+	transport PeerTransport,
+	clients ClientTransport,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	config ActivityConfig,
+) ActivityService {
+	s := &activityService{
+		transport:   transport,
+		clients:     clients,
+		channels:    channels,
+		messages:    messages,
+		config:      config,
+		actividades: make(map[claveActividad]*entradaActividad),
+		secuencias:  make(map[claveActividad]secuenciaVista),
+	}
+	transport.SetFrameHandler(FrameTipoActividad, s.handleActividad)
+	return s
+}
+
+// Start implementa ActivityService
+func (s *activityService) Start(ctx context.Context) {
+	go func() {
+		expiracion := time.NewTicker(s.config.IntervaloExpiracion)
+		defer expiracion.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-expiracion.C:
+				s.expirar()
+			}
+		}
+	}()
+}
+
+// Report implementa ActivityService
+func (s *activityService) Report(userID, conversationID uuid.UUID, activity model.TipoActividad) (*model.Actividad, error) {
+	ahora := time.Now()
+	duracion := s.duracion(activity)
+	nueva, err := model.NewActividad(userID, conversationID, activity, ahora.Add(duracion))
+	if err != nil {
+		return nil, err
+	}
+	clave := claveActividad{usuarioID: userID, conversacionID: conversationID, tipo: activity}
+
+	// Dentro del antirrebote la actividad ya se comprobó y se difundió: solo se alarga
+	s.mu.Lock()
+	if entrada := s.actividades[clave]; entrada != nil && ahora.Sub(entrada.difundida) < s.config.Antirrebote {
+		entrada.actividad.Renovar(nueva.Expira())
+		copia := *entrada.actividad
+		s.mu.Unlock()
+		return &copia, nil
+	}
+	s.mu.Unlock()
+
+	participantes, directa, err := s.participantes(context.Background(), userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !contieneID(participantes, userID) {
+		return nil, ErrActividadSinAcceso
+	}
+
+	s.mu.Lock()
+	s.actividades[clave] = &entradaActividad{actividad: nueva, difundida: ahora}
+	secuencia := s.secuenciaLocked(clave, ahora)
+	s.mu.Unlock()
+
+	s.difundir(actividadDifundida{
+		UsuarioID:      userID,
+		ConversacionID: conversationID,
+		Tipo:           string(activity),
+		Activa:         true,
+		DuracionMs:     duracion.Milliseconds(),
+		Secuencia:      secuencia,
+	}, uuid.Nil)
+	s.notificar(participantes, directa, avisoActividad{
+		UsuarioID:      userID,
+		ConversacionID: conversationID,
+		Tipo:           string(activity),
+		Activa:         true,
+		ExpiraEnMs:     duracion.Milliseconds(),
+	})
+	copia := *nueva
+	return &copia, nil
+}
+
+// Stop implementa ActivityService
+func (s *activityService) Stop(userID, conversationID uuid.UUID, activity model.TipoActividad) error {
+	if !activity.Valid() {
+		return model.ErrActividadTipoInvalido
+	}
+	clave := claveActividad{usuarioID: userID, conversacionID: conversationID, tipo: activity}
+
+	s.mu.Lock()
+	if s.actividades[clave] == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.actividades, clave)
+	secuencia := s.secuenciaLocked(clave, time.Now())
+	s.mu.Unlock()
+
+	s.terminar(clave, secuencia)
+	return nil
+}
+
+// ClearUser implementa ActivityService
+func (s *activityService) ClearUser(userID uuid.UUID) {
+	ahora := time.Now()
+	terminadas := make(map[claveActividad]int64)
+
+	s.mu.Lock()
+	for clave, entrada := range s.actividades {
+		// Solo las originadas aquí: las de otros nodos las termina su propio nodo
+		if clave.usuarioID != userID || entrada.difundida.IsZero() {
+			continue
+		}
+		delete(s.actividades, clave)
+		terminadas[clave] = s.secuenciaLocked(clave, ahora)
+	}
+	s.mu.Unlock()
+
+	for clave, secuencia := range terminadas {
+		s.terminar(clave, secuencia)
+	}
+}
+
+// ListActive implementa ActivityService
+func (s *activityService) ListActive(userID, conversationID uuid.UUID) ([]*model.Actividad, error) {
+	participantes, directa, err := s.participantes(context.Background(), userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !contieneID(participantes, userID) {
+		return nil, ErrActividadSinAcceso
+	}
+
+	ahora := time.Now()
+	s.mu.Lock()
+	var vigentes []*model.Actividad
+	for clave, entrada := range s.actividades {
+		// En un mensaje directo la actividad del otro usuario tiene por conversación a
+		// quien la consulta
+		enConversacion := clave.conversacionID == conversationID
+		if directa {
+			enConversacion = clave.usuarioID == conversationID && clave.conversacionID == userID
+		}
+		if !enConversacion || clave.usuarioID == userID || !entrada.actividad.Vigente(ahora) {
+			continue
+		}
+		copia := *entrada.actividad
+		vigentes = append(vigentes, &copia)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(vigentes, func(i, j int) bool {
+		if vigentes[i].UsuarioID() != vigentes[j].UsuarioID() {
+			return vigentes[i].UsuarioID().String() < vigentes[j].UsuarioID().String()
+		}
+		return vigentes[i].Tipo() < vigentes[j].Tipo()
+	})
+	return vigentes, nil
+}
+
+// handleActividad aplica un cambio de actividad recibido de otro nodo, lo envía a los
+// participantes conectados a este nodo y lo reenvía a los demás vecinos
+func (s *activityService) handleActividad(peerID uuid.UUID, payload []byte) {
+	var registro actividadDifundida
+	if err := json.Unmarshal(payload, &registro); err != nil {
+		return
+	}
+	tipo := model.TipoActividad(registro.Tipo)
+	ahora := time.Now()
+	duracion := time.Duration(registro.DuracionMs) * time.Millisecond
+	actividad, err := model.NewActividad(registro.UsuarioID, registro.ConversacionID, tipo, ahora.Add(duracion))
+	if err != nil {
+		return
+	}
+	clave := claveActividad{usuarioID: registro.UsuarioID, conversacionID: registro.ConversacionID, tipo: tipo}
+
+	s.mu.Lock()
+	if vista, ok := s.secuencias[clave]; ok && registro.Secuencia <= vista.secuencia {
+		s.mu.Unlock()
+		return
+	}
+	s.secuencias[clave] = secuenciaVista{secuencia: registro.Secuencia, vista: ahora}
+	estaba := s.actividades[clave] != nil
+	if registro.Activa {
+		s.actividades[clave] = &entradaActividad{actividad: actividad}
+	} else {
+		delete(s.actividades, clave)
+	}
+	s.mu.Unlock()
+
+	s.difundir(registro, peerID)
+	if !registro.Activa && !estaba {
+		return
+	}
+	participantes, directa, err := s.participantes(context.Background(), registro.UsuarioID, registro.ConversacionID)
+	if err != nil {
+		return
+	}
+	aviso := avisoActividad{
+		UsuarioID:      registro.UsuarioID,
+		ConversacionID: registro.ConversacionID,
+		Tipo:           registro.Tipo,
+		Activa:         registro.Activa,
+	}
+	if registro.Activa {
+		aviso.ExpiraEnMs = registro.DuracionMs
+	}
+	s.notificar(participantes, directa, aviso)
+}
+
+// expirar termina las actividades que no se renovaron a tiempo y olvida las secuencias
+// antiguas. Cada nodo vence por su cuenta las actividades que conoce, sin difundirlo,
+// de modo que también vencen las de un nodo caído.
+func (s *activityService) expirar() {
+	ahora := time.Now()
+	var vencidas []claveActividad
+
+	s.mu.Lock()
+	for clave, entrada := range s.actividades {
+		if !entrada.actividad.Vigente(ahora) {
+			delete(s.actividades, clave)
+			vencidas = append(vencidas, clave)
+		}
+	}
+	for clave, vista := range s.secuencias {
+		if s.actividades[clave] == nil && ahora.Sub(vista.vista) > s.config.RetencionSecuencias {
+			delete(s.secuencias, clave)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, clave := range vencidas {
+		participantes, directa, err := s.participantes(context.Background(), clave.usuarioID, clave.conversacionID)
+		if err != nil {
+			continue
+		}
+		s.notificar(participantes, directa, avisoActividad{
+			UsuarioID:      clave.usuarioID,
+			ConversacionID: clave.conversacionID,
+			Tipo:           string(clave.tipo),
+		})
+	}
+}
+
+// terminar difunde y notifica el fin de una actividad originada en este nodo
+func (s *activityService) terminar(clave claveActividad, secuencia int64) {
+	s.difundir(actividadDifundida{
+		UsuarioID:      clave.usuarioID,
+		ConversacionID: clave.conversacionID,
+		Tipo:           string(clave.tipo),
+		Secuencia:      secuencia,
+	}, uuid.Nil)
+
+	participantes, directa, err := s.participantes(context.Background(), clave.usuarioID, clave.conversacionID)
+	if err != nil {
+		return
+	}
+	s.notificar(participantes, directa, avisoActividad{
+		UsuarioID:      clave.usuarioID,
+		ConversacionID: clave.conversacionID,
+		Tipo:           string(clave.tipo),
+	})
+}
+
+// secuenciaLocked asigna la siguiente secuencia a un cambio originado en este nodo. Usa
+// la hora del sistema para que siga creciendo tras un reinicio. Requiere s.mu
+func (s *activityService) secuenciaLocked(clave claveActividad, ahora time.Time) int64 {
+	secuencia := ahora.UnixNano()
+	if vista, ok := s.secuencias[clave]; ok && secuencia <= vista.secuencia {
+		secuencia = vista.secuencia + 1
+	}
+	if secuencia <= s.ultimaSecuencia {
+		secuencia = s.ultimaSecuencia + 1
+	}
+	s.ultimaSecuencia = secuencia
+	s.secuencias[clave] = secuenciaVista{secuencia: secuencia, vista: ahora}
+	return secuencia
+}
+
+// duracion devuelve el tiempo que dura una actividad sin renovarse
+func (s *activityService) duracion(tipo model.TipoActividad) time.Duration {
+	if tipo == model.ActividadViendo {
+		return s.config.DuracionViendo
+	}
+	return s.config.DuracionEscribiendo
+}
+
+// participantes devuelve los usuarios de la conversación: los miembros del canal, los
+// participantes del chat privado o, si no es ninguno de los dos, el usuario y el otro
+// usuario de su conversación directa, en cuyo caso directa es true
+func (s *activityService) participantes(ctx context.Context, userID, conversationID uuid.UUID) ([]uuid.UUID, bool, error) {
+	miembros, err := s.channels.ListMembers(ctx, conversationID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(miembros) > 0 {
+		return miembros, false, nil
+	}
+	participantes, err := s.messages.FindChatParticipants(ctx, conversationID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(participantes) > 0 {
+		return participantes, false, nil
+	}
+	if conversationID == userID {
+		return nil, true, nil
+	}
+	return []uuid.UUID{userID, conversationID}, true, nil
+}
+
+// difundir envía el cambio a todos los vecinos salvo al que lo envió
+func (s *activityService) difundir(registro actividadDifundida, origen uuid.UUID) {
+	payload, err := json.Marshal(registro)
+	if err != nil {
+		return
+	}
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		if peerID == origen {
+			continue
+		}
+		// Un vecino caído no necesita ponerse al día: la actividad habrá vencido
+		_ = s.transport.SendTo(peerID, FrameTipoActividad, payload)
+	}
+}
+
+// notificar envía el aviso a los participantes salvo al autor de la actividad
+func (s *activityService) notificar(participantes []uuid.UUID, directa bool, aviso avisoActividad) {
+	if s.clients == nil {
+		return
+	}
+	if directa {
+		aviso.ConversacionID = aviso.UsuarioID
+	}
+	var destinatarios []uuid.UUID
+	for _, id := range participantes {
+		if id != aviso.UsuarioID {
+			destinatarios = append(destinatarios, id)
+		}
+	}
+	if len(destinatarios) == 0 {
+		return
+	}
+	frame, err := codificarEventoCliente(EventoClienteActividad, aviso)
+	if err != nil {
+		return
+	}
+	// Los usuarios sin socket en este nodo no necesitan la actividad: habrá vencido
+	_ = s.clients.Broadcast(destinatarios, frame)
+}
+
+// contieneID indica si el ID está en la lista
+func contieneID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nuevoNodoActividad crea un nodo simulado con su ActivityService sobre los canales y
+// mensajes indicados
+func nuevoNodoActividad(t *testing.T, red *redFalsa, canales *canalesFalsos, mensajes *mensajesFalsos, config ActivityConfig) *nodoServicio[ActivityService] {
+	t.Helper()
+	return nuevoNodoServicio(t, red, func(n *nodoFalso) ActivityService {
+		n.canales, n.mensajes = canales, mensajes
+		return NewActivityService(n.transporte, n.clientes, canales, mensajes, config)
+	})
+}
+
+func TestActivity_AntirreboteYFin(t *testing.T) {
+	ctx := context.Background()
+	canales, mensajes := nuevosCanalesFalsos(), nuevosMensajesFalsos()
+	n := nuevoNodoActividad(t, nuevaRedFalsa(), canales, mensajes, DefaultActivityConfig())
+
+	ana, bob, eva, ajeno, canalID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{ana, bob, eva} {
+		canales.AddMember(ctx, canalID, id, "member")
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := n.servicio.Report(ana, canalID, model.ActividadEscribiendo); err != nil {
+			t.Fatalf("Report: %v", err)
+		}
+	}
+	avisos := eventosCliente[avisoActividad](t, n.clientes, bob, EventoClienteActividad)
+	if len(avisos) != 1 || !avisos[0].Activa || avisos[0].UsuarioID != ana || avisos[0].ConversacionID != canalID || avisos[0].ExpiraEnMs == 0 {
+		t.Fatalf("los informes repetidos deben difundirse una vez: %+v", avisos)
+	}
+	if len(eventosCliente[avisoActividad](t, n.clientes, eva, EventoClienteActividad)) != 1 || len(eventosCliente[avisoActividad](t, n.clientes, ana, EventoClienteActividad)) != 0 {
+		t.Error("el aviso es para los demás participantes, no para el autor")
+	}
+
+	activas, err := n.servicio.ListActive(bob, canalID)
+	if err != nil {
+		t.Fatalf("ListActive: %v", err)
+	}
+	if len(activas) != 1 || activas[0].UsuarioID() != ana || activas[0].Tipo() != model.ActividadEscribiendo {
+		t.Errorf("actividades inesperadas: %v", activas)
+	}
+	if activas, _ := n.servicio.ListActive(ana, canalID); len(activas) != 0 {
+		t.Error("la propia actividad no se lista")
+	}
+	if _, err := n.servicio.Report(ajeno, canalID, model.ActividadEscribiendo); err != ErrActividadSinAcceso {
+		t.Errorf("actividad de un no miembro: esperado %v, obtuvo %v", ErrActividadSinAcceso, err)
+	}
+	if _, err := n.servicio.Report(ana, canalID, "GRABANDO"); err != model.ErrActividadTipoInvalido {
+		t.Errorf("tipo inválido: esperado %v, obtuvo %v", model.ErrActividadTipoInvalido, err)
+	}
+
+	if err := n.servicio.Stop(ana, canalID, model.ActividadEscribiendo); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := n.servicio.Stop(ana, canalID, model.ActividadEscribiendo); err != nil {
+		t.Fatalf("Stop repetido: %v", err)
+	}
+	avisos = eventosCliente[avisoActividad](t, n.clientes, bob, EventoClienteActividad)
+	if len(avisos) != 2 || avisos[1].Activa {
+		t.Errorf("esperado un único aviso de fin: %+v", avisos)
+	}
+	if activas, _ := n.servicio.ListActive(bob, canalID); len(activas) != 0 {
+		t.Errorf("no debe quedar actividad tras el fin: %v", activas)
+	}
+}
+
+func TestActivity_ExpiraSinRenovar(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config := DefaultActivityConfig()
+	config.DuracionEscribiendo = 50 * time.Millisecond
+	config.IntervaloExpiracion = 10 * time.Millisecond
+	n := nuevoNodoActividad(t, nuevaRedFalsa(), nuevosCanalesFalsos(), nuevosMensajesFalsos(), config)
+	n.servicio.Start(ctx)
+
+	// En un mensaje directo la conversación es el otro usuario
+	ana, bob := uuid.New(), uuid.New()
+	if _, err := n.servicio.Report(ana, bob, model.ActividadEscribiendo); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if activas, _ := n.servicio.ListActive(bob, ana); len(activas) != 1 {
+		t.Fatalf("bob debe ver a ana escribiendo, obtuvo %v", activas)
+	}
+
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[avisoActividad](t, n.clientes, bob, EventoClienteActividad)) == 2
+	})
+	avisos := eventosCliente[avisoActividad](t, n.clientes, bob, EventoClienteActividad)
+	if avisos[1].Activa || avisos[1].ConversacionID != ana {
+		t.Errorf("la actividad debe vencer en la conversación con ana: %+v", avisos[1])
+	}
+	if activas, _ := n.servicio.ListActive(bob, ana); len(activas) != 0 {
+		t.Errorf("no debe quedar actividad vencida: %v", activas)
+	}
+}
+
+func TestActivity_DifusionEntreNodos(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales, mensajes := nuevosCanalesFalsos(), nuevosMensajesFalsos()
+	a := nuevoNodoActividad(t, red, canales, mensajes, DefaultActivityConfig())
+	b := nuevoNodoActividad(t, red, canales, mensajes, DefaultActivityConfig())
+	c := nuevoNodoActividad(t, red, canales, mensajes, DefaultActivityConfig())
+
+	ana, bob, chatID := uuid.New(), uuid.New(), uuid.New()
+	mensajes.chats[chatID] = []uuid.UUID{ana, bob}
+
+	if _, err := a.servicio.Report(ana, chatID, model.ActividadViendo); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	for _, n := range []*nodoServicio[ActivityService]{b, c} {
+		esperarHasta(t, func() bool {
+			activas, _ := n.servicio.ListActive(bob, chatID)
+			return len(activas) == 1
+		})
+	}
+	// La inundación se detiene: cada nodo avisa una sola vez
+	time.Sleep(20 * time.Millisecond)
+	for _, n := range []*nodoServicio[ActivityService]{a, b, c} {
+		if avisos := eventosCliente[avisoActividad](t, n.clientes, bob, EventoClienteActividad); len(avisos) != 1 || !avisos[0].Activa || avisos[0].Tipo != string(model.ActividadViendo) {
+			t.Errorf("nodo %v: esperado un aviso de actividad, obtuvo %+v", n.id, avisos)
+		}
+	}
+
+	// Un cambio atrasado no revive una actividad terminada
+	atrasado, _ := json.Marshal(actividadDifundida{
+		UsuarioID: ana, ConversacionID: chatID, Tipo: string(model.ActividadViendo),
+		Activa: true, DuracionMs: 60000, Secuencia: 1,
+	})
+	a.servicio.ClearUser(ana)
+	for _, n := range []*nodoServicio[ActivityService]{b, c} {
+		esperarHasta(t, func() bool {
+			activas, _ := n.servicio.ListActive(bob, chatID)
+			return len(activas) == 0
+		})
+	}
+	b.transporte.handlers[FrameTipoActividad](a.id, atrasado)
+	if activas, _ := b.servicio.ListActive(bob, chatID); len(activas) != 0 {
+		t.Errorf("el cambio atrasado no debe aplicarse: %v", activas)
+	}
+	if avisos := eventosCliente[avisoActividad](t, b.clientes, bob, EventoClienteActividad); len(avisos) != 2 || avisos[1].Activa {
+		t.Errorf("esperado el aviso de fin en el nodo remoto: %+v", avisos)
+	}
+}
@@ -17,6 +17,7 @@ type ChannelSummary struct {
 	FechaCreacion    time.Time // Fecha de creación del canal
 	TipoCanal        string    // Tipo de canal (público, privado, etc)
 	ArchivosCompartidos int     // Número de archivos compartidos
+	NumFijados       int       // Mensajes y enlaces fijados vigentes (IChannelRepository.CountPins)
 }
 
 // AdminService define las operaciones para supervisión y estadísticas agregadas
@@ -32,4 +33,7 @@ type AdminService interface {
 	
 	// ListLogs obtiene los registros de eventos del sistema
 	ListLogs() ([]*model.LogEntry, error)
+	
+	// ListPeerSyncStatus obtiene la última sincronización anti-entropía exitosa de cada peer
+	ListPeerSyncStatus() ([]PeerSyncStatus, error)
 }
@@ -0,0 +1,751 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores del proceso anti-entropía
+var (
+	ErrAntiEntropiaTimeout    = errors.New("el peer no respondió a la sincronización a tiempo")
+	ErrAntiEntropiaRespuesta  = errors.New("respuesta de sincronización inesperada")
+	ErrAntiEntropiaEnCurso    = errors.New("ya hay una sincronización en curso con el peer")
+	ErrAntiEntropiaCubetaMala = errors.New("clave de cubeta inválida")
+)
+
+// Categorías de registros reconciliados; forman el prefijo de la clave de cubeta
+const (
+	categoriaUsuarios   = "usuarios"
+	categoriaCanales    = "canales"
+	categoriaMiembros   = "miembros"
+	categoriaMensajes   = "mensajes"
+	categoriaReacciones = "reacciones"
+)
+
+// Tipos de mensaje del protocolo anti-entropía
+const (
+	aeResumen            = "RESUMEN"             // iniciador → peer: raíz Merkle y rango de mensajes
+	aeIguales            = "IGUALES"             // peer → iniciador: las raíces coinciden
+	aeHojas              = "HOJAS"               // peer → iniciador: hashes de todas sus cubetas
+	aeRegistros          = "REGISTROS"           // iniciador → peer: cubetas distintas y sus filas
+	aeRegistrosRespuesta = "REGISTROS_RESPUESTA" // peer → iniciador: sus filas de esas cubetas
+	aeError              = "ERROR"               // cualquiera: fallo al procesar la petición
+)
+
+// AntiEntropyConfig contiene los parámetros del proceso anti-entropía
+type AntiEntropyConfig struct {
+	Intervalo      time.Duration // Periodo entre rondas de sincronización con todos los peers
+	Timeout        time.Duration // Espera máxima por cada respuesta de un peer
+	Ventana        time.Duration // Antigüedad máxima de los mensajes reconciliados (0 = todo el histórico)
+	CubetaMensajes time.Duration // Tamaño del rango temporal de cada cubeta de mensajes
+}
+
+// DefaultAntiEntropyConfig devuelve la configuración por defecto
+func DefaultAntiEntropyConfig() AntiEntropyConfig {
+	return AntiEntropyConfig{
+		Intervalo:      5 * time.Minute,
+		Timeout:        30 * time.Second,
+		Ventana:        30 * 24 * time.Hour,
+		CubetaMensajes: time.Hour,
+	}
+}
+
+// PeerSyncStatus resume el estado de la reconciliación con un peer para el dashboard
+type PeerSyncStatus struct {
+	PeerID      uuid.UUID // Nodo remoto
+	UltimaSync  time.Time // Última sincronización completada con éxito (cero si nunca)
+	UltimoError string    // Error del último intento fallido, vacío si el último tuvo éxito
+}
+
+// AntiEntropyService reconcilia periódicamente el estado con los demás nodos.
+// Compara árboles Merkle de usuarios, canales, membresías, mensajes y reacciones
+// (agrupados en cubetas, los mensajes y las reacciones por rango temporal) y solo
+// intercambia las filas de las cubetas que difieren. Las filas recibidas se fusionan con ConflictResolver.
+type AntiEntropyService interface {
+	// Start lanza rondas periódicas con todos los peers conectados hasta que ctx termine
+	Start(ctx context.Context)
+
+	// SyncWithPeer ejecuta una ronda completa de reconciliación con un peer
+	SyncWithPeer(ctx context.Context, peerID uuid.UUID) error
+
+	// Status devuelve la última sincronización exitosa y el último error por peer
+	Status() []PeerSyncStatus
+}
+
+// mensajeAntiEntropia es el payload JSON de los frames FrameTipoAntiEntropia
+type mensajeAntiEntropia struct {
+	Tipo      string            `json:"tipo"`
+	Sesion    uuid.UUID         `json:"sesion"`
+	Raiz      []byte            `json:"raiz,omitempty"`
+	Desde     time.Time         `json:"desde"`
+	Hasta     time.Time         `json:"hasta"`
+	Hojas     map[string][]byte `json:"hojas,omitempty"`
+	Cubetas   []string          `json:"cubetas,omitempty"`
+	Registros *loteRegistros    `json:"registros,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// loteRegistros agrupa las filas de un conjunto de cubetas
+type loteRegistros struct {
+	Usuarios   []registroUsuario  `json:"usuarios,omitempty"`
+	Canales    []registroCanal    `json:"canales,omitempty"`
+	Miembros   []registroMiembro  `json:"miembros,omitempty"`
+	Mensajes   []registroMensaje  `json:"mensajes,omitempty"`
+	Reacciones []registroReaccion `json:"reacciones,omitempty"`
+}
+
+// instantanea es el estado local agrupado por cubeta, listo para construir el árbol
+type instantanea struct {
+	arbol      *arbolMerkle
+	usuarios   map[string][]*model.UsuarioServidor
+	canales    map[string][]*model.CanalServidor
+	miembros   map[string][]*model.CanalMiembro
+	mensajes   map[string][]*model.MensajeServidor
+	reacciones map[string][]*model.ReaccionMensaje
+}
+
+// antiEntropyService implementa AntiEntropyService sobre PeerTransport y los repositorios
+type antiEntropyService struct {
+	transport PeerTransport
+	users     repository.IUserRepository
+	channels  repository.IChannelRepository
+	messages  repository.IMessageRepository
+	peers     repository.IPeerRepository
+	resolver  ConflictResolver
+	config    AntiEntropyConfig
+
+	mu         sync.Mutex
+	pendientes map[uuid.UUID]chan *mensajeAntiEntropia // sesión → respuesta esperada
+	enCurso    map[uuid.UUID]bool                      // peers con una ronda iniciada por este nodo
+	estado     map[uuid.UUID]*PeerSyncStatus
+}
+
+// NewAntiEntropyService crea el servicio y registra su handler de frames en el transporte
+func NewAntiEntropyService(
+	transport PeerTransport,
+	users repository.IUserRepository,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	peers repository.IPeerRepository,
+	resolver ConflictResolver,
+	config AntiEntropyConfig,
+) AntiEntropyService {
+	s := &antiEntropyService{
+		transport:  transport,
+		users:      users,
+		channels:   channels,
+		messages:   messages,
+		peers:      peers,
+		resolver:   resolver,
+		config:     config,
+		pendientes: make(map[uuid.UUID]chan *mensajeAntiEntropia),
+		enCurso:    make(map[uuid.UUID]bool),
+		estado:     make(map[uuid.UUID]*PeerSyncStatus),
+	}
+	transport.SetFrameHandler(FrameTipoAntiEntropia, s.handleFrame)
+	return s
+}
+
+// Start implementa AntiEntropyService
+func (s *antiEntropyService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Intervalo)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, peerID := range s.transport.GetAllPeerIDs() {
+					// Los errores quedan reflejados en Status
+					_ = s.SyncWithPeer(ctx, peerID)
+				}
+			}
+		}
+	}()
+}
+
+// Status implementa AntiEntropyService
+func (s *antiEntropyService) Status() []PeerSyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resultado := make([]PeerSyncStatus, 0, len(s.estado))
+	for _, st := range s.estado {
+		resultado = append(resultado, *st)
+	}
+	return resultado
+}
+
+// SyncWithPeer implementa AntiEntropyService
+func (s *antiEntropyService) SyncWithPeer(ctx context.Context, peerID uuid.UUID) error {
+	s.mu.Lock()
+	if s.enCurso[peerID] {
+		s.mu.Unlock()
+		return ErrAntiEntropiaEnCurso
+	}
+	s.enCurso[peerID] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.enCurso, peerID)
+		s.mu.Unlock()
+	}()
+
+	err := s.sincronizar(ctx, peerID)
+	s.registrarResultado(ctx, peerID, err)
+	return err
+}
+
+// sincronizar ejecuta el protocolo desde el lado iniciador
+func (s *antiEntropyService) sincronizar(ctx context.Context, peerID uuid.UUID) error {
+	hasta := time.Now().UTC().Truncate(s.config.CubetaMensajes).Add(s.config.CubetaMensajes)
+	desde := time.Unix(0, 0).UTC()
+	if s.config.Ventana > 0 {
+		desde = hasta.Add(-s.config.Ventana).Truncate(s.config.CubetaMensajes)
+	}
+
+	local, err := s.tomarInstantanea(ctx, desde, hasta)
+	if err != nil {
+		return err
+	}
+
+	sesion := uuid.New()
+	respuestas := make(chan *mensajeAntiEntropia, 1)
+	s.mu.Lock()
+	s.pendientes[sesion] = respuestas
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pendientes, sesion)
+		s.mu.Unlock()
+	}()
+
+	// 1. Comparar raíces
+	resp, err := s.intercambiar(ctx, peerID, respuestas, &mensajeAntiEntropia{
+		Tipo: aeResumen, Sesion: sesion, Raiz: local.arbol.raiz(), Desde: desde, Hasta: hasta,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Tipo == aeIguales {
+		return nil
+	}
+	if resp.Tipo != aeHojas {
+		return ErrAntiEntropiaRespuesta
+	}
+
+	// 2. Intercambiar solo las filas de las cubetas que difieren
+	distintas := local.arbol.cubetasDistintas(resp.Hojas)
+	resp, err = s.intercambiar(ctx, peerID, respuestas, &mensajeAntiEntropia{
+		Tipo: aeRegistros, Sesion: sesion, Desde: desde, Hasta: hasta,
+		Cubetas: distintas, Registros: local.lote(distintas),
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Tipo != aeRegistrosRespuesta {
+		return ErrAntiEntropiaRespuesta
+	}
+	return s.aplicar(ctx, resp.Registros)
+}
+
+// intercambiar envía un mensaje al peer y espera la respuesta de la misma sesión
+func (s *antiEntropyService) intercambiar(
+	ctx context.Context,
+	peerID uuid.UUID,
+	respuestas chan *mensajeAntiEntropia,
+	msg *mensajeAntiEntropia,
+) (*mensajeAntiEntropia, error) {
+	if err := s.enviar(peerID, msg); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(s.config.Timeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-respuestas:
+		if resp.Tipo == aeError {
+			return nil, fmt.Errorf("el peer rechazó la sincronización: %s", resp.Error)
+		}
+		return resp, nil
+	case <-timer.C:
+		return nil, ErrAntiEntropiaTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// handleFrame procesa los frames anti-entropía recibidos de un peer
+func (s *antiEntropyService) handleFrame(peerID uuid.UUID, payload []byte) {
+	var msg mensajeAntiEntropia
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return
+	}
+
+	switch msg.Tipo {
+	case aeIguales, aeHojas, aeRegistrosRespuesta, aeError:
+		// Respuesta a una sesión iniciada por este nodo
+		s.mu.Lock()
+		respuestas, ok := s.pendientes[msg.Sesion]
+		s.mu.Unlock()
+		if ok {
+			select {
+			case respuestas <- &msg:
+			default:
+			}
+		}
+	case aeResumen, aeRegistros:
+		// Petición de un peer: se atiende fuera del bucle de lectura del transporte
+		go s.responder(peerID, &msg)
+	}
+}
+
+// responder atiende una petición del lado iniciado
+func (s *antiEntropyService) responder(peerID uuid.UUID, msg *mensajeAntiEntropia) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	resp, err := s.procesarPeticion(ctx, msg)
+	if err != nil {
+		resp = &mensajeAntiEntropia{Tipo: aeError, Sesion: msg.Sesion, Error: err.Error()}
+	}
+	if err := s.enviar(peerID, resp); err != nil {
+		return
+	}
+
+	// Una ronda termina aquí para este lado cuando las raíces coinciden o tras intercambiar filas
+	if resp.Tipo == aeIguales || resp.Tipo == aeRegistrosRespuesta {
+		s.registrarResultado(ctx, peerID, nil)
+	}
+}
+
+// procesarPeticion construye la respuesta a RESUMEN o REGISTROS
+func (s *antiEntropyService) procesarPeticion(ctx context.Context, msg *mensajeAntiEntropia) (*mensajeAntiEntropia, error) {
+	switch msg.Tipo {
+	case aeResumen:
+		local, err := s.tomarInstantanea(ctx, msg.Desde, msg.Hasta)
+		if err != nil {
+			return nil, err
+		}
+		if string(local.arbol.raiz()) == string(msg.Raiz) {
+			return &mensajeAntiEntropia{Tipo: aeIguales, Sesion: msg.Sesion}, nil
+		}
+		return &mensajeAntiEntropia{Tipo: aeHojas, Sesion: msg.Sesion, Hojas: local.arbol.hojas}, nil
+
+	case aeRegistros:
+		for _, clave := range msg.Cubetas {
+			if !claveCubetaValida(clave) {
+				return nil, fmt.Errorf("%w: %q", ErrAntiEntropiaCubetaMala, clave)
+			}
+		}
+		if err := s.aplicar(ctx, msg.Registros); err != nil {
+			return nil, err
+		}
+		// Tras fusionar, se devuelve el estado resultante de las mismas cubetas
+		local, err := s.tomarInstantanea(ctx, msg.Desde, msg.Hasta)
+		if err != nil {
+			return nil, err
+		}
+		return &mensajeAntiEntropia{
+			Tipo: aeRegistrosRespuesta, Sesion: msg.Sesion, Registros: local.lote(msg.Cubetas),
+		}, nil
+	}
+	return nil, ErrAntiEntropiaRespuesta
+}
+
+// enviar serializa y envía un mensaje anti-entropía
+func (s *antiEntropyService) enviar(peerID uuid.UUID, msg *mensajeAntiEntropia) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.transport.SendTo(peerID, FrameTipoAntiEntropia, payload)
+}
+
+// registrarResultado actualiza el estado visible en el dashboard y persiste las sincronizaciones exitosas
+func (s *antiEntropyService) registrarResultado(ctx context.Context, peerID uuid.UUID, err error) {
+	ahora := time.Now().UTC()
+
+	s.mu.Lock()
+	st, ok := s.estado[peerID]
+	if !ok {
+		st = &PeerSyncStatus{PeerID: peerID}
+		s.estado[peerID] = st
+	}
+	if err != nil {
+		st.UltimoError = err.Error()
+	} else {
+		st.UltimaSync = ahora
+		st.UltimoError = ""
+	}
+	s.mu.Unlock()
+
+	if err == nil && s.peers != nil {
+		// La dirección solo hace falta para dar de alta un peer que aún no tiene fila.
+		// Si falla la persistencia el valor sigue disponible en memoria junto al error.
+		direccion, _ := s.transport.PeerAddress(peerID)
+		if err := s.peers.UpdateLastSync(ctx, peerID, direccion, ahora); err != nil {
+			s.mu.Lock()
+			st.UltimoError = fmt.Sprintf("no se pudo guardar la sincronización: %v", err)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// tomarInstantanea lee el estado local y lo agrupa por cubeta
+func (s *antiEntropyService) tomarInstantanea(ctx context.Context, desde, hasta time.Time) (*instantanea, error) {
+	inst := &instantanea{
+		usuarios:   make(map[string][]*model.UsuarioServidor),
+		canales:    make(map[string][]*model.CanalServidor),
+		miembros:   make(map[string][]*model.CanalMiembro),
+		mensajes:   make(map[string][]*model.MensajeServidor),
+		reacciones: make(map[string][]*model.ReaccionMensaje),
+	}
+	hashes := make(map[string][][]byte)
+
+	usuarios, err := s.users.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range usuarios {
+		clave := categoriaUsuarios + "/" + u.FechaRegistro().UTC().Format("20060102")
+		inst.usuarios[clave] = append(inst.usuarios[clave], u)
+		hashes[clave] = append(hashes[clave], hashUsuario(u))
+	}
+
+	canales, err := s.channels.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range canales {
+		clave := categoriaCanales + "/" + prefijoID(c.ID())
+		inst.canales[clave] = append(inst.canales[clave], c)
+		hashes[clave] = append(hashes[clave], hashCanal(c))
+
+		miembros, err := s.channels.FindMembers(ctx, c.ID())
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range miembros {
+			claveM := categoriaMiembros + "/" + prefijoID(c.ID())
+			inst.miembros[claveM] = append(inst.miembros[claveM], m)
+			hashes[claveM] = append(hashes[claveM], hashMiembro(m))
+		}
+	}
+
+	mensajes, err := s.messages.FindByTimeRange(ctx, desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mensajes {
+		clave := categoriaMensajes + "/" + m.Timestamp().UTC().Truncate(s.config.CubetaMensajes).Format("2006010215")
+		inst.mensajes[clave] = append(inst.mensajes[clave], m)
+		hashes[clave] = append(hashes[clave], hashMensaje(m))
+	}
+
+	// Las reacciones se agrupan por su último cambio, que está en la ventana aunque
+	// el mensaje sea anterior
+	reacciones, err := s.messages.FindReactionsByTimeRange(ctx, desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reacciones {
+		clave := categoriaReacciones + "/" + r.UltimoCambio().UTC().Truncate(s.config.CubetaMensajes).Format("2006010215")
+		inst.reacciones[clave] = append(inst.reacciones[clave], r)
+		hashes[clave] = append(hashes[clave], hashReaccion(r))
+	}
+
+	inst.arbol = nuevoArbolMerkle(hashes)
+	return inst, nil
+}
+
+// lote devuelve las filas locales de las cubetas indicadas
+func (i *instantanea) lote(cubetas []string) *loteRegistros {
+	lote := &loteRegistros{}
+	for _, clave := range cubetas {
+		for _, u := range i.usuarios[clave] {
+			lote.Usuarios = append(lote.Usuarios, aRegistroUsuario(u))
+		}
+		for _, c := range i.canales[clave] {
+			lote.Canales = append(lote.Canales, aRegistroCanal(c))
+		}
+		for _, m := range i.miembros[clave] {
+			lote.Miembros = append(lote.Miembros, aRegistroMiembro(m))
+		}
+		for _, m := range i.mensajes[clave] {
+			lote.Mensajes = append(lote.Mensajes, aRegistroMensaje(m))
+		}
+		for _, r := range i.reacciones[clave] {
+			lote.Reacciones = append(lote.Reacciones, aRegistroReaccion(r))
+		}
+	}
+	return lote
+}
+
+// aplicar fusiona las filas recibidas con el estado local.
+// El orden respeta las claves foráneas: usuarios y canales antes que miembros y mensajes,
+// y los mensajes antes que sus reacciones.
+func (s *antiEntropyService) aplicar(ctx context.Context, lote *loteRegistros) error {
+	if lote == nil {
+		return nil
+	}
+
+	for _, r := range lote.Usuarios {
+		remoto, err := r.aModelo()
+		if err != nil {
+			return err
+		}
+		local, err := s.users.FindByID(ctx, remoto.ID())
+		if err != nil {
+			return err
+		}
+		if local == nil {
+			if err := s.users.Save(ctx, remoto); err != nil {
+				return err
+			}
+			continue
+		}
+		ganador, err := s.resolver.ResolveUser(local, remoto)
+		if err != nil {
+			return err
+		}
+		if ganador == remoto {
+			if err := s.users.Update(ctx, remoto); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, r := range lote.Canales {
+		remoto, err := r.aModelo()
+		if err != nil {
+			return err
+		}
+		local, err := s.channels.FindByID(ctx, remoto.ID())
+		if err != nil {
+			return err
+		}
+		if local == nil {
+			if err := s.channels.Save(ctx, remoto); err != nil {
+				return err
+			}
+			continue
+		}
+		ganador, err := s.resolver.ResolveChannel(local, remoto)
+		if err != nil {
+			return err
+		}
+		if ganador == remoto {
+			if err := s.channels.Update(ctx, remoto); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.aplicarMiembros(ctx, lote.Miembros); err != nil {
+		return err
+	}
+
+	for _, r := range lote.Mensajes {
+		remoto, err := r.aModelo()
+		if err != nil {
+			return err
+		}
+		if err := s.aplicarMensaje(ctx, remoto); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range lote.Reacciones {
+		remota, err := r.aModelo()
+		if err != nil {
+			return err
+		}
+		if err := s.aplicarReaccion(ctx, remota); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aplicarMiembros fusiona por unión los miembros recibidos, canal a canal
+func (s *antiEntropyService) aplicarMiembros(ctx context.Context, registros []registroMiembro) error {
+	porCanal := make(map[uuid.UUID][]*model.CanalMiembro)
+	for _, r := range registros {
+		m, err := r.aModelo()
+		if err != nil {
+			return err
+		}
+		porCanal[m.CanalID()] = append(porCanal[m.CanalID()], m)
+	}
+
+	for canalID, remotos := range porCanal {
+		locales, err := s.channels.FindMembers(ctx, canalID)
+		if err != nil {
+			return err
+		}
+		fusion, err := s.resolver.ResolveMembers(canalID, locales, remotos)
+		if err != nil {
+			return err
+		}
+
+		esLocal := make(map[*model.CanalMiembro]bool, len(locales))
+		for _, m := range locales {
+			esLocal[m] = true
+		}
+		for _, m := range fusion {
+			if esLocal[m] {
+				continue
+			}
+			if err := s.channels.SaveMember(ctx, m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// aplicarMensaje guarda un mensaje recibido sin perder ninguna versión local
+func (s *antiEntropyService) aplicarMensaje(ctx context.Context, remoto *model.MensajeServidor) error {
+	local, err := s.messages.FindByID(ctx, remoto.ID())
+	if err != nil {
+		return err
+	}
+	if local == nil {
+		return s.messages.Save(ctx, remoto)
+	}
+
+	versiones, err := s.resolver.ResolveMessage(local, remoto)
+	if err != nil {
+		return err
+	}
+	for _, m := range versiones {
+		switch {
+		case m == local:
+			continue
+		case m.ID() == local.ID():
+			// La versión remota conserva el ID original; la local ya se guarda como copia
+			if err := s.messages.Update(ctx, m); err != nil {
+				return err
+			}
+		default:
+			existente, err := s.messages.FindByID(ctx, m.ID())
+			if err != nil {
+				return err
+			}
+			if existente == nil {
+				if err := s.messages.Save(ctx, m); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// aplicarReaccion guarda una reacción recibida si gana por last-writer-wins. Las
+// reacciones a mensajes que este nodo aún no tiene se omiten: llegarán en otra ronda
+// junto con el mensaje.
+func (s *antiEntropyService) aplicarReaccion(ctx context.Context, remota *model.ReaccionMensaje) error {
+	local, err := s.messages.FindReaction(ctx, remota.MensajeID(), remota.UsuarioID(), remota.Emoji())
+	if err != nil {
+		return err
+	}
+	if local == nil {
+		m, err := s.messages.FindByID(ctx, remota.MensajeID())
+		if err != nil || m == nil {
+			return err
+		}
+		return s.messages.SaveReaction(ctx, remota)
+	}
+
+	ganadora, err := s.resolver.ResolveReaction(local, remota)
+	if err != nil {
+		return err
+	}
+	if ganadora == remota {
+		return s.messages.SaveReaction(ctx, remota)
+	}
+	return nil
+}
+
+// prefijoID agrupa entidades sin fecha en 16 cubetas según el primer dígito hexadecimal del ID
+func prefijoID(id uuid.UUID) string {
+	return id.String()[:1]
+}
+
+// claveCubetaValida comprueba que una clave de cubeta tenga el formato categoria/rango
+func claveCubetaValida(clave string) bool {
+	categoria, rango, ok := strings.Cut(clave, "/")
+	if !ok || rango == "" {
+		return false
+	}
+	switch categoria {
+	case categoriaUsuarios, categoriaCanales, categoriaMiembros, categoriaMensajes, categoriaReacciones:
+		return true
+	}
+	return false
+}
+
+func hashUsuario(u *model.UsuarioServidor) []byte {
+	return hashCampos(u.ID().String(), u.NombreUsuario(), u.Email(), u.ContrasenaHasheada(), u.FotoURL(), u.Version().String())
+}
+
+func hashCanal(c *model.CanalServidor) []byte {
+	return hashCampos(c.ID().String(), c.Nombre(), c.Descripcion(), string(c.Tipo()), c.Version().String())
+}
+
+func hashMiembro(m *model.CanalMiembro) []byte {
+	return hashCampos(m.CanalID().String(), m.UsuarioID().String(), m.Rol(), m.Version().String())
+}
+
+// hashMensaje solo incluye las marcas de edición y borrado si existen, para que el
+// hash de los mensajes sin modificar no cambie respecto a nodos anteriores a la edición
+func hashMensaje(m *model.MensajeServidor) []byte {
+	campos := []string{
+		m.ID().String(), m.RemitenteID().String(), m.DestinoUsuarioID().String(),
+		m.CanalID().String(), m.ChatPrivadoID().String(), m.Contenido(),
+		m.Timestamp().UTC().Format(time.RFC3339Nano), m.ArchivoID().String(), m.Version().String(),
+	}
+	if modificado(m) {
+		campos = append(campos,
+			m.EditadoAt().UTC().Format(time.RFC3339Nano),
+			m.EliminadoAt().UTC().Format(time.RFC3339Nano),
+		)
+	}
+	if m.EsRespuesta() {
+		campos = append(campos, m.PadreID().String())
+	}
+	return hashCampos(campos...)
+}
+
+func hashReaccion(r *model.ReaccionMensaje) []byte {
+	return hashCampos(
+		r.MensajeID().String(), r.UsuarioID().String(), r.Emoji(),
+		r.Fecha().UTC().Format(time.RFC3339Nano), r.RetiradaAt().UTC().Format(time.RFC3339Nano),
+		r.Version().String(),
+	)
+}
+
+// hashCampos calcula el hash de un registro separando los campos para evitar ambigüedades
+func hashCampos(campos ...string) []byte {
+	partes := make([][]byte, 0, len(campos))
+	for _, c := range campos {
+		partes = append(partes, []byte(hex.EncodeToString([]byte(c))+"|"))
+	}
+	return hashDe(partes...)
+}
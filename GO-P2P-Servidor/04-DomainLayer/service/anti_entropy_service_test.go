@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nodoPrueba agrupa el estado y el servicio anti-entropía de un nodo simulado
+type nodoPrueba struct {
+	id       uuid.UUID
+	usuarios *usuariosFalsos
+	canales  *canalesFalsos
+	mensajes *mensajesFalsos
+	peers    *peersFalsos
+	servicio AntiEntropyService
+}
+
+func nuevoNodoPrueba(red *redFalsa) *nodoPrueba {
+	n := &nodoPrueba{
+		id:       uuid.New(),
+		usuarios: nuevosUsuariosFalsos(),
+		canales:  nuevosCanalesFalsos(),
+		mensajes: nuevosMensajesFalsos(),
+		peers:    nuevosPeersFalsos(),
+	}
+	config := DefaultAntiEntropyConfig()
+	config.Timeout = 2 * time.Second
+	n.servicio = NewAntiEntropyService(
+		red.nodo(n.id), n.usuarios, n.canales, n.mensajes, n.peers,
+		NewConflictResolver(&auditFalso{}), config,
+	)
+	return n
+}
+
+func TestAntiEntropy_Converge(t *testing.T) {
+	ctx := context.Background()
+	red := nuevaRedFalsa()
+	a, b := nuevoNodoPrueba(red), nuevoNodoPrueba(red)
+	ahora := time.Now().UTC().Truncate(time.Millisecond)
+
+	// Estado común
+	canalID := uuid.New()
+	for _, n := range []*nodoPrueba{a, b} {
+		c, _ := model.NewCanalServidor(canalID, "general", "canal general", model.CanalPublico)
+		n.canales.Save(ctx, c)
+	}
+
+	// Solo en A: un usuario, su membresía, un mensaje y una reacción
+	usuarioID := uuid.New()
+	u, _ := model.NewUsuarioServidor(usuarioID, "ana", "ana@x.com", "hash", "", "10.0.0.1", ahora)
+	a.usuarios.Save(ctx, u)
+	miembro, _ := model.NewCanalMiembro(canalID, usuarioID, "member")
+	a.canales.SaveMember(ctx, miembro)
+	msgA, _ := model.NewMensajeCanal(uuid.New(), usuarioID, canalID, "hola desde A", ahora.Add(-time.Minute), uuid.Nil)
+	a.mensajes.Save(ctx, msgA)
+	reaccion, _ := model.NewReaccionMensaje(msgA.ID(), usuarioID, "👋", ahora)
+	reaccion.SetVersion(hlc(t, ahora.UnixNano(), a.id))
+	a.mensajes.SaveReaction(ctx, reaccion)
+
+	// Solo en B: un mensaje y una descripción de canal más reciente
+	msgB, _ := model.NewMensajeCanal(uuid.New(), usuarioID, canalID, "hola desde B", ahora.Add(-2*time.Hour), uuid.Nil)
+	b.mensajes.Save(ctx, msgB)
+	canalB, _ := model.NewCanalServidor(canalID, "general", "descripción editada", model.CanalPublico)
+	canalB.SetVersion(hlc(t, ahora.UnixNano(), b.id))
+	b.canales.Update(ctx, canalB)
+
+	if err := a.servicio.SyncWithPeer(ctx, b.id); err != nil {
+		t.Fatalf("SyncWithPeer: %v", err)
+	}
+
+	// El iniciador recibe la respuesta después de que el peer aplique los datos
+	for _, n := range []*nodoPrueba{a, b} {
+		if m, _ := n.mensajes.FindByID(ctx, msgA.ID()); m == nil {
+			t.Errorf("nodo %s: falta el mensaje de A", n.id)
+		}
+		if m, _ := n.mensajes.FindByID(ctx, msgB.ID()); m == nil {
+			t.Errorf("nodo %s: falta el mensaje de B", n.id)
+		}
+		if r, _ := n.mensajes.FindReaction(ctx, msgA.ID(), usuarioID, "👋"); r == nil {
+			t.Errorf("nodo %s: falta la reacción de A", n.id)
+		}
+		if u, _ := n.usuarios.FindByID(ctx, usuarioID); u == nil {
+			t.Errorf("nodo %s: falta el usuario", n.id)
+		}
+		if miembros, _ := n.canales.FindMembers(ctx, canalID); len(miembros) != 1 {
+			t.Errorf("nodo %s: miembros esperado 1, obtuvo %d", n.id, len(miembros))
+		}
+		if c, _ := n.canales.FindByID(ctx, canalID); c.Descripcion() != "descripción editada" {
+			t.Errorf("nodo %s: descripción esperada %q, obtuvo %q", n.id, "descripción editada", c.Descripcion())
+		}
+	}
+
+	// Ambos lados registran la última sincronización exitosa
+	if a.peers.obtenerUltimaSync(b.id).IsZero() {
+		t.Error("A no registró la sincronización con B")
+	}
+	esperarHasta(t, func() bool { return !b.peers.obtenerUltimaSync(a.id).IsZero() })
+	// El primer registro da de alta al peer con la dirección que conoce el transporte
+	if p, _ := a.peers.FindByID(ctx, b.id); p == nil || p.Direccion() != b.id.String()[:8]+".nodo:7000" {
+		t.Errorf("A debe dar de alta a B con su dirección: %+v", p)
+	}
+
+	estado := a.servicio.Status()
+	if len(estado) != 1 || estado[0].PeerID != b.id || estado[0].UltimaSync.IsZero() || estado[0].UltimoError != "" {
+		t.Errorf("estado inesperado: %+v", estado)
+	}
+
+	// Una segunda ronda encuentra las raíces iguales
+	if err := b.servicio.SyncWithPeer(ctx, a.id); err != nil {
+		t.Fatalf("segunda ronda: %v", err)
+	}
+}
+
+func TestAntiEntropy_PeerCaido(t *testing.T) {
+	ctx := context.Background()
+	red := nuevaRedFalsa()
+	a, b := nuevoNodoPrueba(red), nuevoNodoPrueba(red)
+	red.caer(b.id, true)
+
+	if err := a.servicio.SyncWithPeer(ctx, b.id); err == nil {
+		t.Fatal("esperaba error con el peer caído")
+	}
+	estado := a.servicio.Status()
+	if len(estado) != 1 || !estado[0].UltimaSync.IsZero() || estado[0].UltimoError == "" {
+		t.Errorf("estado inesperado: %+v", estado)
+	}
+	if !a.peers.obtenerUltimaSync(b.id).IsZero() {
+		t.Error("no debe registrarse una sincronización fallida")
+	}
+}
+
+// esperarHasta reintenta la condición durante un tiempo acotado
+func esperarHasta(t *testing.T, cond func() bool) {
+	t.Helper()
+	limite := time.Now().Add(2 * time.Second)
+	for time.Now().Before(limite) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("la condición no se cumplió a tiempo")
+}
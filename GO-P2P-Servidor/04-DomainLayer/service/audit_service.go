@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
 	"model"
+	repository "repository.interfaces"
 )
 
 // LogFilter define los criterios de filtrado para consulta de logs
@@ -28,4 +30,65 @@ type AuditService interface {
 	ListLogs(
 		filtro LogFilter, // rango fechas, tipo, usuario
 	) ([]*model.LogEntry, error)
+
+	// PageLogs devuelve una página de los logs que cumplen el filtro, ordenados por
+	// (timestamp, id), a partir del cursor opaco de la consulta
+	PageLogs(
+		filtro LogFilter,
+		consulta model.ConsultaPagina,
+	) (*model.Pagina[*model.LogEntry], error)
+}
+
+// auditService implementa AuditService sobre el repositorio de logs
+type auditService struct {
+	logs repository.ILogRepository
+}
+
+// NewAuditService crea el servicio de auditoría que guarda los eventos en logs
+func NewAuditService(logs repository.ILogRepository) AuditService {
+	return &auditService{logs: logs}
+}
+
+// LogEvent implementa AuditService
+func (s *auditService) LogEvent(tipo model.EventoTipo, detalle string, usuarioID *uuid.UUID) error {
+	usuario := uuid.Nil
+	if usuarioID != nil {
+		usuario = *usuarioID
+	}
+	entrada, err := model.NewLogEntry(uuid.New(), tipo, detalle, time.Now().UTC(), usuario)
+	if err != nil {
+		return err
+	}
+	return s.logs.Save(context.Background(), entrada)
+}
+
+// ListLogs implementa AuditService recorriendo todas las páginas del filtro, de la más
+// antigua a la más reciente
+func (s *auditService) ListLogs(filtro LogFilter) ([]*model.LogEntry, error) {
+	var entradas []*model.LogEntry
+	cursor := ""
+	for {
+		consulta, err := model.NewConsultaPagina(cursor, model.PaginaPosteriores, model.LimitePaginaMaximo)
+		if err != nil {
+			return nil, err
+		}
+		pagina, err := s.PageLogs(filtro, consulta)
+		if err != nil {
+			return nil, err
+		}
+		entradas = append(entradas, pagina.Elementos...)
+		if !pagina.HayPosteriores {
+			return entradas, nil
+		}
+		cursor = pagina.Siguiente
+	}
+}
+
+// PageLogs implementa AuditService
+func (s *auditService) PageLogs(filtro LogFilter, consulta model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	usuario := uuid.Nil
+	if filtro.UsuarioID != nil {
+		usuario = *filtro.UsuarioID
+	}
+	return s.logs.ListFiltered(context.Background(), usuario, filtro.TiposEvento, filtro.Desde, filtro.Hasta, consulta)
 }
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// logsFalsos es un repositorio de logs en memoria
+type logsFalsos struct {
+	mu       sync.Mutex
+	entradas []*model.LogEntry
+}
+
+func (r *logsFalsos) Save(ctx context.Context, entry *model.LogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entradas = append(r.entradas, entry)
+	return nil
+}
+
+func (r *logsFalsos) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entradas = slices.DeleteFunc(r.entradas, func(e *model.LogEntry) bool { return e.ID() == id })
+	return nil
+}
+
+func (r *logsFalsos) FindByID(ctx context.Context, id uuid.UUID) (*model.LogEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entradas {
+		if e.ID() == id {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *logsFalsos) ListAll(ctx context.Context, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.ListFiltered(ctx, uuid.Nil, nil, time.Time{}, time.Time{}, page)
+}
+
+func (r *logsFalsos) ListByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.ListFiltered(ctx, userID, nil, time.Time{}, time.Time{}, page)
+}
+
+func (r *logsFalsos) ListByType(ctx context.Context, t model.EventoTipo, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.ListFiltered(ctx, uuid.Nil, []model.EventoTipo{t}, time.Time{}, time.Time{}, page)
+}
+
+func (r *logsFalsos) ListByDateRange(ctx context.Context, from, to time.Time, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return r.ListFiltered(ctx, uuid.Nil, nil, from, to, page)
+}
+
+// ListFiltered ignora el cursor: las pruebas caben en una página
+func (r *logsFalsos) ListFiltered(ctx context.Context, userID uuid.UUID, types []model.EventoTipo, from, to time.Time, page model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var filas []*model.LogEntry
+	for _, e := range r.entradas {
+		if (userID == uuid.Nil || e.UsuarioID() == userID) &&
+			(len(types) == 0 || slices.Contains(types, e.TipoEvento())) &&
+			(from.IsZero() || !e.Timestamp().Before(from)) &&
+			(to.IsZero() || e.Timestamp().Before(to)) {
+			filas = append(filas, e)
+		}
+	}
+	return model.NuevaPagina(filas, page, func(e *model.LogEntry) model.Cursor {
+		return model.NewCursor(e.Timestamp(), e.ID())
+	}), nil
+}
+
+func TestAuditService_ConflictoQuedaEnElLog(t *testing.T) {
+	logs := &logsFalsos{}
+	r := NewConflictResolver(NewAuditService(logs))
+	id := uuid.New()
+
+	local, _ := model.NewUsuarioServidor(id, "ana", "ana@x.com", "hash", "a.png", "10.0.0.1", time.Now())
+	local.SetVersion(hlc(t, 100, uuid.New()))
+	remoto, _ := model.NewUsuarioServidor(id, "ana", "ana@x.com", "hash", "b.png", "10.0.0.2", time.Now())
+	remoto.SetVersion(hlc(t, 200, uuid.New()))
+	if _, err := r.ResolveUser(local, remoto); err != nil {
+		t.Fatalf("ResolveUser: %v", err)
+	}
+
+	conflictos, err := NewAuditService(logs).ListLogs(LogFilter{TiposEvento: []model.EventoTipo{model.EventoConflicto}})
+	if err != nil {
+		t.Fatalf("ListLogs: %v", err)
+	}
+	if len(conflictos) != 1 {
+		t.Fatalf("se esperaba una fila de conflicto, obtuvo %d", len(conflictos))
+	}
+	if conflictos[0].UsuarioID() != uuid.Nil {
+		t.Errorf("un conflicto de replicación no tiene usuario: %v", conflictos[0].UsuarioID())
+	}
+
+	otros, _ := NewAuditService(logs).ListLogs(LogFilter{TiposEvento: []model.EventoTipo{model.EventoLogin}})
+	if len(otros) != 0 {
+		t.Errorf("el filtro por tipo no debe devolver el conflicto: %v", otros)
+	}
+}
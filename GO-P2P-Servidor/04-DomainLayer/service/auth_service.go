@@ -1,8 +1,21 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de autenticación y registro
+var (
+	ErrCredencialesInvalidas = errors.New("email o contraseña incorrectos")
+	ErrEmailRegistrado       = errors.New("el email ya está registrado")
+	ErrContrasenaVacia       = errors.New("contraseña vacía")
 )
 
 // AuthService define las operaciones para autenticación y registro de usuarios
@@ -22,3 +35,74 @@ type AuthService interface {
 		userID uuid.UUID,
 	) error
 }
+
+// authService implementa AuthService sobre el repositorio de usuarios. Las contraseñas
+// se guardan con bcrypt, como la del administrador inicial de las migraciones.
+type authService struct {
+	users repository.IUserRepository
+	reloj *model.RelojHLC
+}
+
+// NewAuthService crea el servicio de autenticación. reloj debe ser el reloj HLC del
+// propio nodo: versiona los usuarios registrados para que la anti-entropía los replique.
+func NewAuthService(users repository.IUserRepository, reloj *model.RelojHLC) AuthService {
+	return &authService{users: users, reloj: reloj}
+}
+
+// Register implementa AuthService
+func (s *authService) Register(nombre, email, password, foto, ip string) (*model.UsuarioServidor, error) {
+	if password == "" {
+		return nil, ErrContrasenaVacia
+	}
+	ctx := context.Background()
+	existente, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existente != nil {
+		return nil, ErrEmailRegistrado
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	u, err := model.NewUsuarioServidor(uuid.New(), nombre, email, string(hash), foto, ip, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	u.SetVersion(s.reloj.Now())
+	if err := s.users.Save(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Login implementa AuthService; un email desconocido y una contraseña errónea devuelven
+// el mismo error para no revelar qué emails están registrados
+func (s *authService) Login(email, password, ip string) (*model.UsuarioServidor, error) {
+	u, err := s.users.FindByEmail(context.Background(), email)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrCredencialesInvalidas
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.ContrasenaHasheada()), []byte(password)) != nil {
+		return nil, ErrCredencialesInvalidas
+	}
+	return u, nil
+}
+
+// Logout implementa AuthService. La conexión del usuario la lleva la presencia, así que
+// aquí solo se comprueba que el usuario exista.
+func (s *authService) Logout(userID uuid.UUID) error {
+	u, err := s.users.FindByID(context.Background(), userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrUsuarioNoEncontrado
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestAuth_RegisterYLogin(t *testing.T) {
+	usuarios := nuevosUsuariosFalsos()
+	reloj, _ := model.NewRelojHLC(uuid.New())
+	s := NewAuthService(usuarios, reloj)
+
+	u, err := s.Register("ana", "ana@x.com", "secreta", "", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if u.ContrasenaHasheada() == "secreta" {
+		t.Error("la contraseña no debe guardarse en claro")
+	}
+	if u.Version().IsZero() {
+		t.Error("el usuario registrado debe llevar versión para replicarse")
+	}
+	if guardado, _ := usuarios.FindByID(context.Background(), u.ID()); guardado == nil {
+		t.Fatal("el usuario registrado no está en el repositorio")
+	}
+
+	if _, err := s.Register("otra", "ana@x.com", "otra", "", "10.0.0.2"); err != ErrEmailRegistrado {
+		t.Errorf("email duplicado: esperado %v, obtuvo %v", ErrEmailRegistrado, err)
+	}
+	if _, err := s.Register("eva", "eva@x.com", "", "", "10.0.0.3"); err != ErrContrasenaVacia {
+		t.Errorf("contraseña vacía: esperado %v, obtuvo %v", ErrContrasenaVacia, err)
+	}
+
+	sesion, err := s.Login("ana@x.com", "secreta", "10.0.0.9")
+	if err != nil || sesion.ID() != u.ID() {
+		t.Fatalf("Login: %v", err)
+	}
+	for _, c := range []struct{ email, password string }{
+		{"ana@x.com", "otra"},
+		{"nadie@x.com", "secreta"},
+	} {
+		if _, err := s.Login(c.email, c.password, ""); err != ErrCredencialesInvalidas {
+			t.Errorf("Login(%s, %s): esperado %v, obtuvo %v", c.email, c.password, ErrCredencialesInvalidas, err)
+		}
+	}
+
+	if err := s.Logout(u.ID()); err != nil {
+		t.Errorf("Logout: %v", err)
+	}
+	if err := s.Logout(uuid.New()); err != ErrUsuarioNoEncontrado {
+		t.Errorf("Logout de un usuario inexistente: esperado %v, obtuvo %v", ErrUsuarioNoEncontrado, err)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Eventos enviados a los clientes conectados a este nodo
+const (
+	EventoClienteMensaje           = "MENSAJE"            // Nuevo mensaje directo o de canal
+	EventoClientePresencia         = "PRESENCIA"          // Un usuario pasa a estar en línea o fuera de línea
+	EventoClienteMensajeEditado    = "MENSAJE_EDITADO"    // Se editó el contenido de un mensaje
+	EventoClienteMensajeEliminado  = "MENSAJE_ELIMINADO"  // Se eliminó un mensaje
+	EventoClienteReaccion          = "REACCION"           // Se añadió o retiró una reacción a un mensaje
+	EventoClienteAcuse             = "ACUSE"              // Un destinatario recibió o leyó mensajes del usuario
+	EventoClienteActividad         = "ACTIVIDAD"          // Un participante empezó o dejó de escribir o de ver la conversación
+	EventoClienteMensajeProgramado = "MENSAJE_PROGRAMADO" // Cambió el estado de un mensaje programado por el usuario
+	EventoClienteMencion           = "MENCION"            // Se mencionó al usuario en un mensaje de canal
+	EventoClienteFijado            = "FIJADO"             // Se fijó o desfijó un mensaje o enlace en un canal
+	EventoClienteNotificacion      = "NOTIFICACION"       // Nueva notificación para el usuario
+)
+
+// ClientTransport abstrae los sockets de los clientes conectados a este nodo.
+// pool.SocketPool la satisface sin que el dominio dependa del pool.
+type ClientTransport interface {
+	// Broadcast envía el mismo frame a todos los usuarios indicados
+	Broadcast(ids []uuid.UUID, frame []byte) error
+}
+
+// ClientSessions indica qué usuarios tienen un socket abierto en este nodo.
+// pool.SocketPool la satisface igual que ClientTransport.
+type ClientSessions interface {
+	// Connected indica si el usuario tiene un socket registrado en este nodo
+	Connected(id uuid.UUID) bool
+}
+
+// eventoCliente es el frame JSON, terminado en salto de línea, que reciben los clientes
+type eventoCliente struct {
+	Evento string      `json:"evento"`
+	Datos  interface{} `json:"datos"`
+}
+
+// codificarEventoCliente serializa un evento para su envío por ClientTransport
+func codificarEventoCliente(evento string, datos interface{}) ([]byte, error) {
+	frame, err := json.Marshal(eventoCliente{Evento: evento, Datos: datos})
+	if err != nil {
+		return nil, err
+	}
+	return append(frame, '\n'), nil
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// Errores de resolución de conflictos
+var (
+	ErrConflictoEntidadDistinta = errors.New("las versiones en conflicto pertenecen a entidades distintas")
+	ErrConflictoMiembroCanal    = errors.New("el miembro no pertenece al canal en resolución")
+	ErrConflictoSinVersiones    = errors.New("no hay versiones que resolver")
+)
+
+// ConflictResolver define la fusión determinista de versiones concurrentes de entidades replicadas.
+// Todas las operaciones son simétricas: resolver (local, remoto) o (remoto, local) da el mismo
+// resultado en cualquier nodo. Cada conflicto real queda registrado en el log de auditoría.
+type ConflictResolver interface {
+	// ResolveUser aplica last-writer-wins por HLC sobre el perfil de usuario
+	ResolveUser(local, remoto *model.UsuarioServidor) (*model.UsuarioServidor, error)
+
+	// ResolveChannel aplica last-writer-wins por HLC sobre los datos del canal
+	ResolveChannel(local, remoto *model.CanalServidor) (*model.CanalServidor, error)
+
+	// ResolveMembers fusiona por unión los miembros de un canal
+	ResolveMembers(canalID uuid.UUID, locales, remotos []*model.CanalMiembro) ([]*model.CanalMiembro, error)
+
+	// ResolveMessage conserva todas las versiones de un mensaje; nunca descarta contenido.
+	// Las ediciones y borrados de un mismo mensaje se fusionan por last-writer-wins.
+	ResolveMessage(local, remoto *model.MensajeServidor) ([]*model.MensajeServidor, error)
+
+	// ResolveReaction aplica last-writer-wins por HLC sobre el estado de una reacción:
+	// vigente o retirada
+	ResolveReaction(local, remoto *model.ReaccionMensaje) (*model.ReaccionMensaje, error)
+
+	// ResolvePin aplica last-writer-wins por HLC sobre el estado de un elemento fijado:
+	// vigente o retirado
+	ResolvePin(local, remoto *model.FijadoCanal) (*model.FijadoCanal, error)
+}
+
+// conflictResolver es la implementación de ConflictResolver basada en HLC
+type conflictResolver struct {
+	audit AuditService
+}
+
+// NewConflictResolver crea un ConflictResolver que registra los conflictos en el AuditService dado
+func NewConflictResolver(audit AuditService) ConflictResolver {
+	return &conflictResolver{audit: audit}
+}
+
+// ResolveUser implementa ConflictResolver
+func (r *conflictResolver) ResolveUser(local, remoto *model.UsuarioServidor) (*model.UsuarioServidor, error) {
+	if local == nil || remoto == nil {
+		return noNil(local, remoto)
+	}
+	if local.ID() != remoto.ID() {
+		return nil, ErrConflictoEntidadDistinta
+	}
+
+	ganador, perdedor := local, remoto
+	if prevalece(remoto.Version(), local.Version(), claveUsuario(remoto), claveUsuario(local)) {
+		ganador, perdedor = remoto, local
+	}
+	if mismoPerfil(local, remoto) {
+		return ganador, nil
+	}
+
+	err := r.registrar(model.EntidadUsuario, local.ID(), model.PoliticaLWW, fmt.Sprintf(
+		"gana %s (%s), descartado %s (%s)",
+		ganador.Version(), ganador.NombreUsuario(), perdedor.Version(), perdedor.NombreUsuario(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return ganador, nil
+}
+
+// ResolveChannel implementa ConflictResolver
+func (r *conflictResolver) ResolveChannel(local, remoto *model.CanalServidor) (*model.CanalServidor, error) {
+	if local == nil || remoto == nil {
+		return noNil(local, remoto)
+	}
+	if local.ID() != remoto.ID() {
+		return nil, ErrConflictoEntidadDistinta
+	}
+
+	ganador, perdedor := local, remoto
+	if prevalece(remoto.Version(), local.Version(), claveCanal(remoto), claveCanal(local)) {
+		ganador, perdedor = remoto, local
+	}
+	if local.Nombre() == remoto.Nombre() &&
+		local.Descripcion() == remoto.Descripcion() &&
+		local.Tipo() == remoto.Tipo() {
+		return ganador, nil
+	}
+
+	err := r.registrar(model.EntidadCanal, local.ID(), model.PoliticaLWW, fmt.Sprintf(
+		"gana %s (%q), descartado %s (%q)",
+		ganador.Version(), ganador.Descripcion(), perdedor.Version(), perdedor.Descripcion(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return ganador, nil
+}
+
+// ResolveMembers implementa ConflictResolver.
+// Un usuario presente en cualquiera de los lados queda como miembro. Si ambos lados
+// discrepan en el rol se conserva el cambio más reciente por HLC y, a igualdad de
+// versión, el rol de mayor rango.
+func (r *conflictResolver) ResolveMembers(canalID uuid.UUID, locales, remotos []*model.CanalMiembro) ([]*model.CanalMiembro, error) {
+	porUsuario := make(map[uuid.UUID]*model.CanalMiembro, len(locales)+len(remotos))
+
+	for _, lista := range [][]*model.CanalMiembro{locales, remotos} {
+		for _, m := range lista {
+			if m.CanalID() != canalID {
+				return nil, ErrConflictoMiembroCanal
+			}
+			actual, existe := porUsuario[m.UsuarioID()]
+			if !existe {
+				porUsuario[m.UsuarioID()] = m
+				continue
+			}
+			if actual.Rol() == m.Rol() {
+				if m.Version().After(actual.Version()) {
+					porUsuario[m.UsuarioID()] = m
+				}
+				continue
+			}
+
+			ganador, perdedor := elegirMiembro(actual, m)
+			porUsuario[m.UsuarioID()] = ganador
+			err := r.registrar(model.EntidadCanalMiembro, canalID, model.PoliticaUnion, fmt.Sprintf(
+				"usuario %s: rol %q (%s) prevalece sobre %q (%s)",
+				m.UsuarioID(), ganador.Rol(), ganador.Version(), perdedor.Rol(), perdedor.Version(),
+			))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	miembros := make([]*model.CanalMiembro, 0, len(porUsuario))
+	for _, m := range porUsuario {
+		miembros = append(miembros, m)
+	}
+	sort.Slice(miembros, func(i, j int) bool {
+		return miembros[i].UsuarioID().String() < miembros[j].UsuarioID().String()
+	})
+	return miembros, nil
+}
+
+// ResolveMessage implementa ConflictResolver.
+// Si ambas versiones difieren, la de HLC menor conserva el ID original y la otra se
+// conserva como un mensaje nuevo con ID derivado de forma determinista, de modo que
+// todos los nodos convergen a los mismos dos mensajes. Si las dos son estados del mismo
+// envío y alguno es una edición o un borrado, se resuelve con resolverEdicion: el texto
+// anterior ya queda en el historial de ediciones del nodo que lo cambió.
+func (r *conflictResolver) ResolveMessage(local, remoto *model.MensajeServidor) ([]*model.MensajeServidor, error) {
+	if local == nil || remoto == nil {
+		m, err := noNil(local, remoto)
+		if err != nil {
+			return nil, err
+		}
+		return []*model.MensajeServidor{m}, nil
+	}
+	if local.ID() != remoto.ID() {
+		return nil, ErrConflictoEntidadDistinta
+	}
+	if mismoMensaje(local, remoto) {
+		return []*model.MensajeServidor{local}, nil
+	}
+	if mismoEnvio(local, remoto) && (modificado(local) || modificado(remoto)) {
+		return r.resolverEdicion(local, remoto)
+	}
+
+	original, bifurcado := local, remoto
+	if prevalece(local.Version(), remoto.Version(), local.Contenido(), remoto.Contenido()) {
+		original, bifurcado = remoto, local
+	}
+	nuevoID := uuid.NewSHA1(original.ID(), []byte(bifurcado.Version().String()+bifurcado.Contenido()))
+	copia, err := copiarMensaje(bifurcado, nuevoID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.registrar(model.EntidadMensaje, original.ID(), model.PoliticaNuncaPerder, fmt.Sprintf(
+		"versión %s conservada como mensaje %s",
+		bifurcado.Version(), nuevoID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return []*model.MensajeServidor{original, copia}, nil
+}
+
+// resolverEdicion elige entre dos estados del mismo mensaje de los que al menos uno es
+// una edición o un borrado. El borrado prevalece sobre cualquier edición; entre dos
+// ediciones o dos borrados gana el HLC mayor. Solo hay conflicto si ambos lados cambiaron
+// el mensaje: el original frente a su edición es simplemente una réplica atrasada.
+func (r *conflictResolver) resolverEdicion(local, remoto *model.MensajeServidor) ([]*model.MensajeServidor, error) {
+	ganador, perdedor := local, remoto
+	switch {
+	case local.Eliminado() != remoto.Eliminado():
+		if remoto.Eliminado() {
+			ganador, perdedor = remoto, local
+		}
+	case prevalece(remoto.Version(), local.Version(), claveMensaje(remoto), claveMensaje(local)):
+		ganador, perdedor = remoto, local
+	}
+
+	if modificado(local) && modificado(remoto) {
+		err := r.registrar(model.EntidadMensaje, local.ID(), model.PoliticaLWW, fmt.Sprintf(
+			"gana %s (%s), descartado %s (%s)",
+			ganador.Version(), estadoMensaje(ganador), perdedor.Version(), estadoMensaje(perdedor),
+		))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []*model.MensajeServidor{ganador}, nil
+}
+
+// ResolveReaction implementa ConflictResolver. Solo hay conflicto si un lado tiene la
+// reacción vigente y el otro retirada; dos altas con fechas distintas son la misma
+// reacción añadida de nuevo.
+func (r *conflictResolver) ResolveReaction(local, remoto *model.ReaccionMensaje) (*model.ReaccionMensaje, error) {
+	if local == nil || remoto == nil {
+		return noNil(local, remoto)
+	}
+	if local.MensajeID() != remoto.MensajeID() ||
+		local.UsuarioID() != remoto.UsuarioID() ||
+		local.Emoji() != remoto.Emoji() {
+		return nil, ErrConflictoEntidadDistinta
+	}
+
+	ganador, perdedor := local, remoto
+	if prevalece(remoto.Version(), local.Version(), claveEstadoReaccion(remoto), claveEstadoReaccion(local)) {
+		ganador, perdedor = remoto, local
+	}
+	if local.Vigente() == remoto.Vigente() {
+		return ganador, nil
+	}
+
+	err := r.registrar(model.EntidadReaccion, local.MensajeID(), model.PoliticaLWW, fmt.Sprintf(
+		"%s de %s: gana %s (%s), descartado %s (%s)",
+		local.Emoji(), local.UsuarioID(),
+		ganador.Version(), estadoReaccion(ganador), perdedor.Version(), estadoReaccion(perdedor),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return ganador, nil
+}
+
+// ResolvePin implementa ConflictResolver. Como en las reacciones, solo hay conflicto si
+// un lado tiene el elemento fijado y el otro retirado.
+func (r *conflictResolver) ResolvePin(local, remoto *model.FijadoCanal) (*model.FijadoCanal, error) {
+	if local == nil || remoto == nil {
+		return noNil(local, remoto)
+	}
+	if local.ID() != remoto.ID() {
+		return nil, ErrConflictoEntidadDistinta
+	}
+
+	ganador, perdedor := local, remoto
+	if prevalece(remoto.Version(), local.Version(), claveEstadoFijado(remoto), claveEstadoFijado(local)) {
+		ganador, perdedor = remoto, local
+	}
+	if local.Vigente() == remoto.Vigente() {
+		return ganador, nil
+	}
+
+	err := r.registrar(model.EntidadFijado, local.ID(), model.PoliticaLWW, fmt.Sprintf(
+		"canal %s: gana %s (%s), descartado %s (%s)",
+		local.CanalID(),
+		ganador.Version(), estadoFijado(ganador), perdedor.Version(), estadoFijado(perdedor),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return ganador, nil
+}
+
+// registrar deja constancia del conflicto en el log de auditoría
+func (r *conflictResolver) registrar(entidadTipo string, entidadID uuid.UUID, politica model.PoliticaConflicto, detalle string) error {
+	if r.audit == nil {
+		return nil
+	}
+	return r.audit.LogEvent(
+		model.EventoConflicto,
+		fmt.Sprintf("%s %s [%s]: %s", entidadTipo, entidadID, politica, detalle),
+		nil,
+	)
+}
+
+// noNil devuelve la versión que no sea nil
+func noNil[T any](local, remoto *T) (*T, error) {
+	switch {
+	case local != nil:
+		return local, nil
+	case remoto != nil:
+		return remoto, nil
+	default:
+		return nil, ErrConflictoSinVersiones
+	}
+}
+
+// prevalece indica si la versión a gana a la versión b bajo last-writer-wins.
+// Con HLC iguales (p. ej. datos anteriores al versionado) desempata por contenido
+// para que todos los nodos elijan la misma versión.
+func prevalece(a, b model.HLC, claveA, claveB string) bool {
+	if c := a.Compare(b); c != 0 {
+		return c > 0
+	}
+	return claveA > claveB
+}
+
+// claveUsuario resume los datos de perfil para el desempate determinista
+func claveUsuario(u *model.UsuarioServidor) string {
+	return strings.Join([]string{u.NombreUsuario(), u.Email(), u.FotoURL(), u.ContrasenaHasheada()}, "\x00")
+}
+
+// claveCanal resume los datos del canal para el desempate determinista
+func claveCanal(c *model.CanalServidor) string {
+	return strings.Join([]string{c.Nombre(), c.Descripcion(), string(c.Tipo())}, "\x00")
+}
+
+// mismoPerfil indica si dos versiones de un usuario tienen los mismos datos de perfil
+func mismoPerfil(a, b *model.UsuarioServidor) bool {
+	return a.NombreUsuario() == b.NombreUsuario() &&
+		a.Email() == b.Email() &&
+		a.ContrasenaHasheada() == b.ContrasenaHasheada() &&
+		a.FotoURL() == b.FotoURL()
+}
+
+// mismoMensaje indica si dos versiones de un mensaje son idénticas en contenido, destino
+// y estado de edición
+func mismoMensaje(a, b *model.MensajeServidor) bool {
+	return mismoEnvio(a, b) &&
+		a.Contenido() == b.Contenido() &&
+		a.ArchivoID() == b.ArchivoID() &&
+		a.EditadoAt().Equal(b.EditadoAt()) &&
+		a.EliminadoAt().Equal(b.EliminadoAt())
+}
+
+// mismoEnvio indica si dos versiones de un mensaje comparten remitente, destino, hilo y
+// momento de envío, los datos que una edición o un borrado no cambian
+func mismoEnvio(a, b *model.MensajeServidor) bool {
+	return a.RemitenteID() == b.RemitenteID() &&
+		a.DestinoUsuarioID() == b.DestinoUsuarioID() &&
+		a.CanalID() == b.CanalID() &&
+		a.ChatPrivadoID() == b.ChatPrivadoID() &&
+		a.PadreID() == b.PadreID() &&
+		a.Timestamp().Equal(b.Timestamp())
+}
+
+// modificado indica si el mensaje se editó o se eliminó tras enviarse
+func modificado(m *model.MensajeServidor) bool {
+	return m.Editado() || m.Eliminado()
+}
+
+// claveMensaje resume el estado de un mensaje para el desempate determinista
+func claveMensaje(m *model.MensajeServidor) string {
+	return strings.Join([]string{
+		m.Contenido(),
+		m.EditadoAt().UTC().Format(time.RFC3339Nano),
+		m.EliminadoAt().UTC().Format(time.RFC3339Nano),
+	}, "\x00")
+}
+
+// estadoMensaje describe el estado de un mensaje para el log de auditoría
+func estadoMensaje(m *model.MensajeServidor) string {
+	if m.Eliminado() {
+		return "eliminado"
+	}
+	return fmt.Sprintf("%q", m.Contenido())
+}
+
+// claveEstadoReaccion resume el estado de una reacción para el desempate determinista
+func claveEstadoReaccion(r *model.ReaccionMensaje) string {
+	return r.Fecha().UTC().Format(time.RFC3339Nano) + "\x00" + r.RetiradaAt().UTC().Format(time.RFC3339Nano)
+}
+
+// estadoReaccion describe el estado de una reacción para el log de auditoría
+func estadoReaccion(r *model.ReaccionMensaje) string {
+	if r.Vigente() {
+		return "vigente"
+	}
+	return "retirada"
+}
+
+// claveEstadoFijado resume el estado de un elemento fijado para el desempate determinista
+func claveEstadoFijado(f *model.FijadoCanal) string {
+	return strings.Join([]string{
+		f.FijadoEn().UTC().Format(time.RFC3339Nano),
+		f.RetiradoEn().UTC().Format(time.RFC3339Nano),
+		f.FijadoPor().String(),
+		f.Titulo(),
+	}, "\x00")
+}
+
+// estadoFijado describe el estado de un elemento fijado para el log de auditoría
+func estadoFijado(f *model.FijadoCanal) string {
+	if f.Vigente() {
+		return "fijado"
+	}
+	return "retirado"
+}
+
+// elegirMiembro decide qué rol prevalece entre dos altas concurrentes del mismo usuario
+func elegirMiembro(a, b *model.CanalMiembro) (ganador, perdedor *model.CanalMiembro) {
+	switch c := a.Version().Compare(b.Version()); {
+	case c > 0:
+		return a, b
+	case c < 0:
+		return b, a
+	}
+	ra, rb := rangoRol(a.Rol()), rangoRol(b.Rol())
+	if ra > rb || (ra == rb && a.Rol() > b.Rol()) {
+		return a, b
+	}
+	return b, a
+}
+
+// rangoRol ordena los roles de canal de menor a mayor privilegio
+func rangoRol(rol string) int {
+	switch strings.ToUpper(rol) {
+	case "OWNER":
+		return 3
+	case "ADMIN":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// copiarMensaje crea una copia del mensaje con otro ID, conservando destino y versión.
+// El adjunto queda en el mensaje original porque archivo_id es único por mensaje.
+func copiarMensaje(m *model.MensajeServidor, id uuid.UUID) (*model.MensajeServidor, error) {
+	var (
+		copia *model.MensajeServidor
+		err   error
+	)
+	switch {
+	case m.ChatPrivadoID() != uuid.Nil:
+		copia, err = model.NewMensajeChatPrivado(id, m.RemitenteID(), m.ChatPrivadoID(), m.Contenido(), m.Timestamp(), uuid.Nil)
+	case m.CanalID() != uuid.Nil:
+		copia, err = model.NewMensajeCanal(id, m.RemitenteID(), m.CanalID(), m.Contenido(), m.Timestamp(), uuid.Nil)
+	default:
+		copia, err = model.NewMensajeDirecto(id, m.RemitenteID(), m.DestinoUsuarioID(), m.Contenido(), m.Timestamp(), uuid.Nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	copia.SetVersion(m.Version())
+	return copia, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// auditFalso registra en memoria los eventos recibidos
+type auditFalso struct {
+	eventos []string
+}
+
+func (a *auditFalso) LogEvent(tipo model.EventoTipo, detalle string, usuarioID *uuid.UUID) error {
+	a.eventos = append(a.eventos, string(tipo)+" "+detalle)
+	return nil
+}
+
+func (a *auditFalso) ListLogs(filtro LogFilter) ([]*model.LogEntry, error) {
+	return nil, nil
+}
+
+func (a *auditFalso) PageLogs(filtro LogFilter, consulta model.ConsultaPagina) (*model.Pagina[*model.LogEntry], error) {
+	return &model.Pagina[*model.LogEntry]{}, nil
+}
+
+func hlc(t *testing.T, wall int64, nodo uuid.UUID) model.HLC {
+	t.Helper()
+	h, err := model.NewHLC(wall, 0, nodo)
+	if err != nil {
+		t.Fatalf("NewHLC: %v", err)
+	}
+	return h
+}
+
+func TestConflictResolver_ResolveUser_LWW(t *testing.T) {
+	audit := &auditFalso{}
+	r := NewConflictResolver(audit)
+	id := uuid.New()
+	nodoA, nodoB := uuid.New(), uuid.New()
+
+	local, _ := model.NewUsuarioServidor(id, "ana", "ana@x.com", "hash", "a.png", "10.0.0.1", time.Now())
+	local.SetVersion(hlc(t, 100, nodoA))
+	remoto, _ := model.NewUsuarioServidor(id, "ana", "ana@x.com", "hash", "b.png", "10.0.0.2", time.Now())
+	remoto.SetVersion(hlc(t, 200, nodoB))
+
+	ganador, err := r.ResolveUser(local, remoto)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if ganador.FotoURL() != "b.png" {
+		t.Errorf("FotoURL: esperado %q, obtuvo %q", "b.png", ganador.FotoURL())
+	}
+
+	// La resolución debe ser simétrica
+	inverso, _ := r.ResolveUser(remoto, local)
+	if inverso != ganador {
+		t.Errorf("resolución no simétrica: %v vs %v", inverso.Version(), ganador.Version())
+	}
+	if len(audit.eventos) != 2 {
+		t.Errorf("eventos de auditoría: esperado 2, obtuvo %d", len(audit.eventos))
+	}
+}
+
+func TestConflictResolver_ResolveUser_SinConflicto(t *testing.T) {
+	audit := &auditFalso{}
+	r := NewConflictResolver(audit)
+	id := uuid.New()
+
+	local, _ := model.NewUsuarioServidor(id, "ana", "ana@x.com", "hash", "a.png", "10.0.0.1", time.Now())
+	remoto, _ := model.NewUsuarioServidor(id, "ana", "ana@x.com", "hash", "a.png", "10.0.0.1", time.Now())
+	remoto.SetVersion(hlc(t, 50, uuid.New()))
+
+	if _, err := r.ResolveUser(local, remoto); err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if len(audit.eventos) != 0 {
+		t.Errorf("no se esperaban eventos de auditoría, obtuvo %v", audit.eventos)
+	}
+
+	otro, _ := model.NewUsuarioServidor(uuid.New(), "bob", "bob@x.com", "hash", "", "10.0.0.1", time.Now())
+	if _, err := r.ResolveUser(local, otro); err != ErrConflictoEntidadDistinta {
+		t.Errorf("esperado ErrConflictoEntidadDistinta, obtuvo %v", err)
+	}
+}
+
+func TestConflictResolver_ResolveChannel_DesempateSinVersion(t *testing.T) {
+	r := NewConflictResolver(&auditFalso{})
+	id := uuid.New()
+
+	a, _ := model.NewCanalServidor(id, "general", "descripción A", model.CanalPublico)
+	b, _ := model.NewCanalServidor(id, "general", "descripción B", model.CanalPublico)
+
+	g1, err := r.ResolveChannel(a, b)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	g2, _ := r.ResolveChannel(b, a)
+	if g1.Descripcion() != g2.Descripcion() {
+		t.Errorf("resolución no determinista: %q vs %q", g1.Descripcion(), g2.Descripcion())
+	}
+}
+
+func TestConflictResolver_ResolveMembers_Union(t *testing.T) {
+	audit := &auditFalso{}
+	r := NewConflictResolver(audit)
+	canalID := uuid.New()
+	u1, u2, u3 := uuid.New(), uuid.New(), uuid.New()
+
+	m1, _ := model.NewCanalMiembro(canalID, u1, "member")
+	m2, _ := model.NewCanalMiembro(canalID, u2, "member")
+	m2.SetVersion(hlc(t, 100, uuid.New()))
+	m2b, _ := model.NewCanalMiembro(canalID, u2, "ADMIN")
+	m2b.SetVersion(hlc(t, 200, uuid.New()))
+	m3, _ := model.NewCanalMiembro(canalID, u3, "member")
+
+	miembros, err := r.ResolveMembers(canalID, []*model.CanalMiembro{m1, m2}, []*model.CanalMiembro{m2b, m3})
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if len(miembros) != 3 {
+		t.Fatalf("miembros: esperado 3, obtuvo %d", len(miembros))
+	}
+	for _, m := range miembros {
+		if m.UsuarioID() == u2 && m.Rol() != "ADMIN" {
+			t.Errorf("rol de u2: esperado %q, obtuvo %q", "ADMIN", m.Rol())
+		}
+	}
+	if len(audit.eventos) != 1 {
+		t.Errorf("eventos de auditoría: esperado 1, obtuvo %d", len(audit.eventos))
+	}
+
+	ajeno, _ := model.NewCanalMiembro(uuid.New(), u1, "member")
+	if _, err := r.ResolveMembers(canalID, nil, []*model.CanalMiembro{ajeno}); err != ErrConflictoMiembroCanal {
+		t.Errorf("esperado ErrConflictoMiembroCanal, obtuvo %v", err)
+	}
+}
+
+func TestConflictResolver_ResolveMessage_NuncaPerder(t *testing.T) {
+	audit := &auditFalso{}
+	r := NewConflictResolver(audit)
+	id, remitente, canal := uuid.New(), uuid.New(), uuid.New()
+	ts := time.Now()
+
+	local, _ := model.NewMensajeCanal(id, remitente, canal, "hola", ts, uuid.Nil)
+	local.SetVersion(hlc(t, 100, uuid.New()))
+	remoto, _ := model.NewMensajeCanal(id, remitente, canal, "hola editado", ts, uuid.Nil)
+	remoto.SetVersion(hlc(t, 200, uuid.New()))
+
+	res, err := r.ResolveMessage(local, remoto)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("mensajes: esperado 2, obtuvo %d", len(res))
+	}
+	if res[0].ID() != id || res[0].Contenido() != "hola" {
+		t.Errorf("original: esperado %v/%q, obtuvo %v/%q", id, "hola", res[0].ID(), res[0].Contenido())
+	}
+	if res[1].ID() == id || res[1].Contenido() != "hola editado" || res[1].CanalID() != canal {
+		t.Errorf("copia inesperada: %v/%q", res[1].ID(), res[1].Contenido())
+	}
+
+	inverso, _ := r.ResolveMessage(remoto, local)
+	if inverso[1].ID() != res[1].ID() {
+		t.Errorf("ID derivado no determinista: %v vs %v", inverso[1].ID(), res[1].ID())
+	}
+	if len(audit.eventos) != 2 {
+		t.Errorf("eventos de auditoría: esperado 2, obtuvo %d", len(audit.eventos))
+	}
+}
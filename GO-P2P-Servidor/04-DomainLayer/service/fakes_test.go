@@ -0,0 +1,1286 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// Repositorios y transporte en memoria compartidos por las pruebas del paquete
+
+type usuariosFalsos struct {
+	mu    sync.Mutex
+	datos map[uuid.UUID]*model.UsuarioServidor
+}
+
+func nuevosUsuariosFalsos() *usuariosFalsos {
+	return &usuariosFalsos{datos: make(map[uuid.UUID]*model.UsuarioServidor)}
+}
+
+func (r *usuariosFalsos) Save(ctx context.Context, u *model.UsuarioServidor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.datos[u.ID()] = u
+	return nil
+}
+
+func (r *usuariosFalsos) Update(ctx context.Context, u *model.UsuarioServidor) error {
+	return r.Save(ctx, u)
+}
+
+func (r *usuariosFalsos) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.datos, id)
+	return nil
+}
+
+func (r *usuariosFalsos) FindByID(ctx context.Context, id uuid.UUID) (*model.UsuarioServidor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.datos[id], nil
+}
+
+func (r *usuariosFalsos) FindAll(ctx context.Context) ([]*model.UsuarioServidor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lista := make([]*model.UsuarioServidor, 0, len(r.datos))
+	for _, u := range r.datos {
+		lista = append(lista, u)
+	}
+	return lista, nil
+}
+
+func (r *usuariosFalsos) FindConnected(ctx context.Context) ([]*model.UsuarioServidor, error) {
+	return nil, nil
+}
+
+func (r *usuariosFalsos) FindByEmail(ctx context.Context, email string) (*model.UsuarioServidor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.datos {
+		if u.Email() == email {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+type canalesFalsos struct {
+	mu       sync.Mutex
+	datos    map[uuid.UUID]*model.CanalServidor
+	miembros map[uuid.UUID]map[uuid.UUID]*model.CanalMiembro
+	fijados  map[uuid.UUID]*model.FijadoCanal
+}
+
+func nuevosCanalesFalsos() *canalesFalsos {
+	return &canalesFalsos{
+		datos:    make(map[uuid.UUID]*model.CanalServidor),
+		miembros: make(map[uuid.UUID]map[uuid.UUID]*model.CanalMiembro),
+		fijados:  make(map[uuid.UUID]*model.FijadoCanal),
+	}
+}
+
+func (r *canalesFalsos) Save(ctx context.Context, c *model.CanalServidor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.datos[c.ID()] = c
+	return nil
+}
+
+func (r *canalesFalsos) Update(ctx context.Context, c *model.CanalServidor) error {
+	return r.Save(ctx, c)
+}
+
+func (r *canalesFalsos) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.datos, id)
+	delete(r.miembros, id)
+	return nil
+}
+
+func (r *canalesFalsos) FindByID(ctx context.Context, id uuid.UUID) (*model.CanalServidor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.datos[id], nil
+}
+
+func (r *canalesFalsos) FindAll(ctx context.Context) ([]*model.CanalServidor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lista := make([]*model.CanalServidor, 0, len(r.datos))
+	for _, c := range r.datos {
+		lista = append(lista, c)
+	}
+	return lista, nil
+}
+
+func (r *canalesFalsos) AddMember(ctx context.Context, channelID, userID uuid.UUID, rol string) error {
+	m, err := model.NewCanalMiembro(channelID, userID, rol)
+	if err != nil {
+		return err
+	}
+	return r.SaveMember(ctx, m)
+}
+
+func (r *canalesFalsos) RemoveMember(ctx context.Context, channelID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.miembros[channelID], userID)
+	return nil
+}
+
+func (r *canalesFalsos) ListMembers(ctx context.Context, channelID uuid.UUID) ([]uuid.UUID, error) {
+	miembros, _ := r.FindMembers(ctx, channelID)
+	ids := make([]uuid.UUID, 0, len(miembros))
+	for _, m := range miembros {
+		ids = append(ids, m.UsuarioID())
+	}
+	return ids, nil
+}
+
+func (r *canalesFalsos) FindMembers(ctx context.Context, channelID uuid.UUID) ([]*model.CanalMiembro, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lista := make([]*model.CanalMiembro, 0, len(r.miembros[channelID]))
+	for _, m := range r.miembros[channelID] {
+		lista = append(lista, m)
+	}
+	sort.Slice(lista, func(i, j int) bool {
+		return lista[i].UsuarioID().String() < lista[j].UsuarioID().String()
+	})
+	return lista, nil
+}
+
+func (r *canalesFalsos) SaveMember(ctx context.Context, m *model.CanalMiembro) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.miembros[m.CanalID()] == nil {
+		r.miembros[m.CanalID()] = make(map[uuid.UUID]*model.CanalMiembro)
+	}
+	r.miembros[m.CanalID()][m.UsuarioID()] = m
+	return nil
+}
+
+func (r *canalesFalsos) SaveInvitation(ctx context.Context, inv *model.InvitacionCanal) error {
+	return nil
+}
+
+func (r *canalesFalsos) UpdateInvitation(ctx context.Context, inv *model.InvitacionCanal) error {
+	return nil
+}
+
+func (r *canalesFalsos) ListInvitations(ctx context.Context, channelID uuid.UUID) ([]*model.InvitacionCanal, error) {
+	return nil, nil
+}
+
+func (r *canalesFalsos) SavePin(ctx context.Context, f *model.FijadoCanal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fijados[f.ID()] = f
+	return nil
+}
+
+func (r *canalesFalsos) FindPin(ctx context.Context, id uuid.UUID) (*model.FijadoCanal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fijados[id], nil
+}
+
+func (r *canalesFalsos) ListPins(ctx context.Context, channelID uuid.UUID) ([]*model.FijadoCanal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.FijadoCanal
+	for _, f := range r.fijados {
+		if f.CanalID() == channelID && f.Vigente() {
+			lista = append(lista, f)
+		}
+	}
+	sort.Slice(lista, func(i, j int) bool {
+		return lista[i].FijadoEn().After(lista[j].FijadoEn())
+	})
+	return lista, nil
+}
+
+func (r *canalesFalsos) CountPins(ctx context.Context, channelIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	conteos := make(map[uuid.UUID]int)
+	for _, id := range channelIDs {
+		if fijados, _ := r.ListPins(ctx, id); len(fijados) > 0 {
+			conteos[id] = len(fijados)
+		}
+	}
+	return conteos, nil
+}
+
+type mensajesFalsos struct {
+	mu         sync.Mutex
+	datos      map[uuid.UUID]*model.MensajeServidor
+	ediciones  map[uuid.UUID][]*model.EdicionMensaje
+	reacciones map[claveReaccion]*model.ReaccionMensaje
+	acuses     map[[2]uuid.UUID]*model.AcuseMensaje    // (mensaje, destinatario)
+	marcadores map[[2]uuid.UUID]*model.MarcadorLectura // (usuario, conversación)
+	chats      map[uuid.UUID][]uuid.UUID               // chat privado → participantes
+}
+
+// claveReaccion identifica una reacción como la clave primaria de reaccion_mensaje
+type claveReaccion struct {
+	mensajeID, usuarioID uuid.UUID
+	emoji                string
+}
+
+func nuevosMensajesFalsos() *mensajesFalsos {
+	return &mensajesFalsos{
+		datos:      make(map[uuid.UUID]*model.MensajeServidor),
+		ediciones:  make(map[uuid.UUID][]*model.EdicionMensaje),
+		reacciones: make(map[claveReaccion]*model.ReaccionMensaje),
+		acuses:     make(map[[2]uuid.UUID]*model.AcuseMensaje),
+		marcadores: make(map[[2]uuid.UUID]*model.MarcadorLectura),
+		chats:      make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+func (r *mensajesFalsos) Save(ctx context.Context, m *model.MensajeServidor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.datos[m.ID()] = m
+	return nil
+}
+
+func (r *mensajesFalsos) Update(ctx context.Context, m *model.MensajeServidor) error {
+	return r.Save(ctx, m)
+}
+
+func (r *mensajesFalsos) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.datos, id)
+	return nil
+}
+
+func (r *mensajesFalsos) FindByID(ctx context.Context, id uuid.UUID) (*model.MensajeServidor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.datos[id], nil
+}
+
+// filtrar devuelve los mensajes que cumplen la condición ordenados por (timestamp, id)
+func (r *mensajesFalsos) filtrar(cond func(m *model.MensajeServidor) bool) []*model.MensajeServidor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.MensajeServidor
+	for _, m := range r.datos {
+		if cond(m) {
+			lista = append(lista, m)
+		}
+	}
+	sort.Slice(lista, func(i, j int) bool {
+		if !lista[i].Timestamp().Equal(lista[j].Timestamp()) {
+			return lista[i].Timestamp().Before(lista[j].Timestamp())
+		}
+		return lista[i].ID().String() < lista[j].ID().String()
+	})
+	return lista
+}
+
+// paginar aplica una consulta de página por (timestamp, id) sobre una lista ya ordenada
+func paginar[T any](lista []T, consulta model.ConsultaPagina, cursor func(T) model.Cursor) *model.Pagina[T] {
+	desde := consulta.Cursor()
+	comparar := func(x T) int {
+		c := cursor(x)
+		switch {
+		case c.Timestamp().Before(desde.Timestamp()):
+			return -1
+		case c.Timestamp().After(desde.Timestamp()):
+			return 1
+		}
+		return strings.Compare(c.ID().String(), desde.ID().String())
+	}
+
+	var filas []T
+	if consulta.Direccion() == model.PaginaAnteriores {
+		for i := len(lista) - 1; i >= 0 && len(filas) <= consulta.Limite(); i-- {
+			if desde.IsZero() || comparar(lista[i]) < 0 {
+				filas = append(filas, lista[i])
+			}
+		}
+	} else {
+		for i := 0; i < len(lista) && len(filas) <= consulta.Limite(); i++ {
+			if desde.IsZero() || comparar(lista[i]) > 0 {
+				filas = append(filas, lista[i])
+			}
+		}
+	}
+	return model.NuevaPagina(filas, consulta, cursor)
+}
+
+func cursorMensaje(m *model.MensajeServidor) model.Cursor {
+	return model.NewCursor(m.Timestamp(), m.ID())
+}
+
+func (r *mensajesFalsos) FindByChannel(ctx context.Context, channelID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return paginar(r.filtrar(func(m *model.MensajeServidor) bool { return m.CanalID() == channelID }), page, cursorMensaje), nil
+}
+
+func (r *mensajesFalsos) FindByUser(ctx context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return paginar(r.filtrar(func(m *model.MensajeServidor) bool { return m.RemitenteID() == userID }), page, cursorMensaje), nil
+}
+
+func (r *mensajesFalsos) FindDirect(ctx context.Context, a, b uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return paginar(r.filtrar(func(m *model.MensajeServidor) bool {
+		return (m.RemitenteID() == a && m.DestinoUsuarioID() == b) ||
+			(m.RemitenteID() == b && m.DestinoUsuarioID() == a)
+	}), page, cursorMensaje), nil
+}
+
+func (r *mensajesFalsos) FindByPrivateChat(ctx context.Context, chatID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return paginar(r.filtrar(func(m *model.MensajeServidor) bool { return m.ChatPrivadoID() == chatID }), page, cursorMensaje), nil
+}
+
+func (r *mensajesFalsos) FindChatParticipants(ctx context.Context, chatID uuid.UUID) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.chats[chatID]), nil
+}
+
+func (r *mensajesFalsos) FindByTimeRange(ctx context.Context, from, to time.Time) ([]*model.MensajeServidor, error) {
+	return r.filtrar(func(m *model.MensajeServidor) bool {
+		return !m.Timestamp().Before(from) && m.Timestamp().Before(to)
+	}), nil
+}
+
+func (r *mensajesFalsos) SaveEdit(ctx context.Context, e *model.EdicionMensaje) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ediciones[e.MensajeID()] = append(r.ediciones[e.MensajeID()], e)
+	return nil
+}
+
+func (r *mensajesFalsos) FindEdits(ctx context.Context, messageID uuid.UUID) ([]*model.EdicionMensaje, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*model.EdicionMensaje(nil), r.ediciones[messageID]...), nil
+}
+
+func (r *mensajesFalsos) SaveReaction(ctx context.Context, reaccion *model.ReaccionMensaje) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reacciones[claveReaccion{reaccion.MensajeID(), reaccion.UsuarioID(), reaccion.Emoji()}] = reaccion
+	return nil
+}
+
+func (r *mensajesFalsos) FindReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) (*model.ReaccionMensaje, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reacciones[claveReaccion{messageID, userID, emoji}], nil
+}
+
+// CountReactions ordena como ReaccionMensajeDAO.ContarPorMensajes: de más a menos usado
+// y a igualdad por la primera aparición del emoji
+func (r *mensajesFalsos) CountReactions(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]model.ConteoReaccion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	type acumulado struct {
+		conteo  model.ConteoReaccion
+		primera time.Time
+	}
+	porMensaje := make(map[uuid.UUID]map[string]*acumulado)
+	for _, id := range messageIDs {
+		porMensaje[id] = make(map[string]*acumulado)
+	}
+	for _, reaccion := range r.reacciones {
+		emojis, ok := porMensaje[reaccion.MensajeID()]
+		if !ok || !reaccion.Vigente() {
+			continue
+		}
+		a := emojis[reaccion.Emoji()]
+		if a == nil {
+			a = &acumulado{conteo: model.ConteoReaccion{Emoji: reaccion.Emoji()}, primera: reaccion.Fecha()}
+			emojis[reaccion.Emoji()] = a
+		}
+		a.conteo.Total++
+		if reaccion.Fecha().Before(a.primera) {
+			a.primera = reaccion.Fecha()
+		}
+	}
+
+	conteos := make(map[uuid.UUID][]model.ConteoReaccion)
+	for id, emojis := range porMensaje {
+		lista := make([]*acumulado, 0, len(emojis))
+		for _, a := range emojis {
+			lista = append(lista, a)
+		}
+		sort.Slice(lista, func(i, j int) bool {
+			if lista[i].conteo.Total != lista[j].conteo.Total {
+				return lista[i].conteo.Total > lista[j].conteo.Total
+			}
+			if !lista[i].primera.Equal(lista[j].primera) {
+				return lista[i].primera.Before(lista[j].primera)
+			}
+			return lista[i].conteo.Emoji < lista[j].conteo.Emoji
+		})
+		for _, a := range lista {
+			conteos[id] = append(conteos[id], a.conteo)
+		}
+	}
+	return conteos, nil
+}
+
+func (r *mensajesFalsos) FindReactionsByTimeRange(ctx context.Context, from, to time.Time) ([]*model.ReaccionMensaje, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.ReaccionMensaje
+	for _, reaccion := range r.reacciones {
+		if cambio := reaccion.UltimoCambio(); !cambio.Before(from) && cambio.Before(to) {
+			lista = append(lista, reaccion)
+		}
+	}
+	return lista, nil
+}
+
+func (r *mensajesFalsos) FindReplies(ctx context.Context, parentID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return paginar(r.filtrar(func(m *model.MensajeServidor) bool { return m.PadreID() == parentID }), page, cursorMensaje), nil
+}
+
+func (r *mensajesFalsos) FindThreads(ctx context.Context, channelID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.ResumenHilo], error) {
+	var hilos []*model.ResumenHilo
+	for _, m := range r.filtrar(func(m *model.MensajeServidor) bool { return m.CanalID() == channelID && !m.EsRespuesta() }) {
+		if hilo, _ := r.FindThreadSummary(ctx, m.ID()); hilo.Respuestas() > 0 {
+			hilos = append(hilos, hilo)
+		}
+	}
+	return paginar(hilos, page, func(h *model.ResumenHilo) model.Cursor { return cursorMensaje(h.Raiz()) }), nil
+}
+
+func (r *mensajesFalsos) FindThreadSummary(ctx context.Context, parentID uuid.UUID) (*model.ResumenHilo, error) {
+	raiz, _ := r.FindByID(ctx, parentID)
+	if raiz == nil {
+		return nil, nil
+	}
+	var ultima time.Time
+	respuestas := r.filtrar(func(m *model.MensajeServidor) bool { return m.PadreID() == parentID && !m.Eliminado() })
+	if len(respuestas) > 0 {
+		ultima = respuestas[len(respuestas)-1].Timestamp()
+	}
+	return model.NewResumenHilo(raiz, len(respuestas), ultima)
+}
+
+func (r *mensajesFalsos) SaveReceipt(ctx context.Context, a *model.AcuseMensaje) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copia := *a
+	r.acuses[[2]uuid.UUID{a.MensajeID(), a.UsuarioID()}] = &copia
+	return nil
+}
+
+func (r *mensajesFalsos) FindReceipt(ctx context.Context, messageID, userID uuid.UUID) (*model.AcuseMensaje, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.acuses[[2]uuid.UUID{messageID, userID}]
+	if !ok {
+		return nil, nil
+	}
+	copia := *a
+	return &copia, nil
+}
+
+func (r *mensajesFalsos) FindReceipts(ctx context.Context, messageID uuid.UUID) ([]*model.AcuseMensaje, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.AcuseMensaje
+	for clave, a := range r.acuses {
+		if clave[0] == messageID {
+			copia := *a
+			lista = append(lista, &copia)
+		}
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].UsuarioID().String() < lista[j].UsuarioID().String() })
+	return lista, nil
+}
+
+func (r *mensajesFalsos) SaveReadMarker(ctx context.Context, m *model.MarcadorLectura) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copia := *m
+	r.marcadores[[2]uuid.UUID{m.UsuarioID(), m.ConversacionID()}] = &copia
+	return nil
+}
+
+func (r *mensajesFalsos) FindReadMarker(ctx context.Context, userID, conversationID uuid.UUID) (*model.MarcadorLectura, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.marcadores[[2]uuid.UUID{userID, conversationID}]
+	if !ok {
+		return nil, nil
+	}
+	copia := *m
+	return &copia, nil
+}
+
+// CountUnread cuenta los mensajes de otros usuarios posteriores a cada marcador sin
+// comprobar la pertenencia a los canales: la aplica la consulta del repositorio real
+func (r *mensajesFalsos) CountUnread(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error) {
+	candidatos := r.filtrar(func(m *model.MensajeServidor) bool {
+		if m.Eliminado() || m.RemitenteID() == userID {
+			return false
+		}
+		return m.DestinoUsuarioID() == userID || m.CanalID() != uuid.Nil ||
+			(m.ChatPrivadoID() != uuid.Nil && slices.Contains(r.chats[m.ChatPrivadoID()], userID))
+	})
+	conteos := make(map[uuid.UUID]int)
+	for _, m := range candidatos {
+		conversacionID := model.ConversacionMensaje(m, userID)
+		marcador, _ := r.FindReadMarker(ctx, userID, conversacionID)
+		if marcador == nil || !marcador.Cubre(m) {
+			conteos[conversacionID]++
+		}
+	}
+	return conteos, nil
+}
+
+// Search busca por términos y filtros sin comprobar la visibilidad: la aplica la
+// consulta del repositorio real
+func (r *mensajesFalsos) Search(ctx context.Context, userID uuid.UUID, query string, filter model.FiltroBusqueda, page model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	terminos := model.TerminosBusqueda(query)
+	if len(terminos) == 0 {
+		return nil, model.ErrBusquedaVacia
+	}
+	return paginar(r.filtrar(func(m *model.MensajeServidor) bool {
+		contenido := model.TerminosBusqueda(m.Contenido())
+		for _, t := range terminos {
+			if !slices.Contains(contenido, t) {
+				return false
+			}
+		}
+		return !m.Eliminado() &&
+			(filter.RemitenteID == uuid.Nil || m.RemitenteID() == filter.RemitenteID) &&
+			(filter.CanalID == uuid.Nil || m.CanalID() == filter.CanalID) &&
+			(filter.Desde.IsZero() || !m.Timestamp().Before(filter.Desde)) &&
+			(filter.Hasta.IsZero() || m.Timestamp().Before(filter.Hasta)) &&
+			(!filter.ConAdjunto || m.ArchivoID() != uuid.Nil)
+	}), page, cursorMensaje), nil
+}
+
+// notificacionesFalsas registra las notificaciones enviadas por usuario
+type notificacionesFalsas struct {
+	mu    sync.Mutex
+	datos map[uuid.UUID][]*model.Notificacion
+}
+
+func nuevasNotificacionesFalsas() *notificacionesFalsas {
+	return &notificacionesFalsas{datos: make(map[uuid.UUID][]*model.Notificacion)}
+}
+
+func (n *notificacionesFalsas) Notify(userID uuid.UUID, contenido string) (*model.Notificacion, error) {
+	notificacion, err := model.NewNotificacion(uuid.New(), userID, contenido, time.Now().UTC(), uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.datos[userID] = append(n.datos[userID], notificacion)
+	return notificacion, nil
+}
+
+func (n *notificacionesFalsas) List(userID uuid.UUID) ([]*model.Notificacion, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*model.Notificacion(nil), n.datos[userID]...), nil
+}
+
+func (n *notificacionesFalsas) ListPage(userID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.Notificacion], error) {
+	lista, _ := n.List(userID)
+	return paginar(lista, consulta, func(x *model.Notificacion) model.Cursor { return model.NewCursor(x.Fecha(), x.ID()) }), nil
+}
+
+func (n *notificacionesFalsas) MarkRead(notificationID uuid.UUID) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, lista := range n.datos {
+		for _, x := range lista {
+			if x.ID() == notificationID {
+				x.MarcarComoLeida()
+				return nil
+			}
+		}
+	}
+	return errors.New("notificación no encontrada")
+}
+
+// unidadFalsa ejecuta las operaciones sin transacción: los repositorios falsos no la necesitan
+type unidadFalsa struct{}
+
+func (unidadFalsa) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type peersFalsos struct {
+	mu         sync.Mutex
+	datos      map[uuid.UUID]*model.Peer
+	ultimaSync map[uuid.UUID]time.Time
+}
+
+func nuevosPeersFalsos() *peersFalsos {
+	return &peersFalsos{
+		datos:      make(map[uuid.UUID]*model.Peer),
+		ultimaSync: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (r *peersFalsos) Save(ctx context.Context, p *model.Peer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.datos[p.IDNodo()] = p
+	return nil
+}
+
+func (r *peersFalsos) Update(ctx context.Context, p *model.Peer) error {
+	return r.Save(ctx, p)
+}
+
+func (r *peersFalsos) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.datos, id)
+	return nil
+}
+
+func (r *peersFalsos) FindByID(ctx context.Context, id uuid.UUID) (*model.Peer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.datos[id], nil
+}
+
+func (r *peersFalsos) ListAll(ctx context.Context) ([]*model.Peer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lista := make([]*model.Peer, 0, len(r.datos))
+	for _, p := range r.datos {
+		lista = append(lista, p)
+	}
+	return lista, nil
+}
+
+func (r *peersFalsos) ListByState(ctx context.Context, state model.NodoEstado) ([]*model.Peer, error) {
+	todos, _ := r.ListAll(ctx)
+	var lista []*model.Peer
+	for _, p := range todos {
+		if p.Estado() == state {
+			lista = append(lista, p)
+		}
+	}
+	return lista, nil
+}
+
+func (r *peersFalsos) UpdateLastSync(ctx context.Context, id uuid.UUID, address string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.datos[id] == nil {
+		p, err := model.NewPeer(id, address, model.NodoConectado)
+		if err != nil {
+			return err
+		}
+		r.datos[id] = p
+	}
+	r.ultimaSync[id] = at
+	return nil
+}
+
+func (r *peersFalsos) obtenerUltimaSync(id uuid.UUID) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ultimaSync[id]
+}
+
+// redFalsa conecta transportes en memoria; cada frame se entrega en una goroutine
+// como haría el bucle de recepción de PeerConnectionPool
+type redFalsa struct {
+	mu      sync.Mutex
+	nodos   map[uuid.UUID]*transporteFalso
+	caidos  map[uuid.UUID]bool
+	enlaces map[uuid.UUID]map[uuid.UUID]bool // vacío = todos los nodos conectados entre sí
+}
+
+func nuevaRedFalsa() *redFalsa {
+	return &redFalsa{
+		nodos:   make(map[uuid.UUID]*transporteFalso),
+		caidos:  make(map[uuid.UUID]bool),
+		enlaces: make(map[uuid.UUID]map[uuid.UUID]bool),
+	}
+}
+
+// enlazar conecta directamente dos nodos; con algún enlace definido la red deja de ser completa
+func (r *redFalsa) enlazar(a, b uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, par := range [][2]uuid.UUID{{a, b}, {b, a}} {
+		if r.enlaces[par[0]] == nil {
+			r.enlaces[par[0]] = make(map[uuid.UUID]bool)
+		}
+		r.enlaces[par[0]][par[1]] = true
+	}
+}
+
+// enlazadosLocked indica si dos nodos tienen conexión directa; requiere r.mu
+func (r *redFalsa) enlazadosLocked(a, b uuid.UUID) bool {
+	return len(r.enlaces) == 0 || r.enlaces[a][b]
+}
+
+// nodo registra un transporte para el nodo dado en la red
+func (r *redFalsa) nodo(id uuid.UUID) *transporteFalso {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := &transporteFalso{id: id, red: r, handlers: make(map[uint16]func(uuid.UUID, []byte))}
+	r.nodos[id] = t
+	return t
+}
+
+// caer simula la desconexión de un nodo
+func (r *redFalsa) caer(id uuid.UUID, caido bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.caidos[id] = caido
+}
+
+type transporteFalso struct {
+	id       uuid.UUID
+	red      *redFalsa
+	mu       sync.RWMutex
+	handlers map[uint16]func(uuid.UUID, []byte)
+}
+
+var errPeerFalsoNoConectado = errors.New("peer no conectado")
+
+func (t *transporteFalso) GetAllPeerIDs() []uuid.UUID {
+	t.red.mu.Lock()
+	defer t.red.mu.Unlock()
+	var ids []uuid.UUID
+	for id := range t.red.nodos {
+		if id != t.id && !t.red.caidos[id] && !t.red.caidos[t.id] && t.red.enlazadosLocked(t.id, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (t *transporteFalso) SendTo(peerID uuid.UUID, frameType uint16, payload []byte) error {
+	t.red.mu.Lock()
+	destino, ok := t.red.nodos[peerID]
+	caido := t.red.caidos[peerID] || t.red.caidos[t.id] || !t.red.enlazadosLocked(t.id, peerID)
+	t.red.mu.Unlock()
+	if !ok || caido {
+		return errPeerFalsoNoConectado
+	}
+
+	destino.mu.RLock()
+	handler := destino.handlers[frameType]
+	destino.mu.RUnlock()
+	if handler != nil {
+		copia := append([]byte(nil), payload...)
+		go handler(t.id, copia)
+	}
+	return nil
+}
+
+// PeerAddress da a cada nodo de la red una dirección fija derivada de su ID
+func (t *transporteFalso) PeerAddress(peerID uuid.UUID) (string, bool) {
+	t.red.mu.Lock()
+	defer t.red.mu.Unlock()
+	if _, ok := t.red.nodos[peerID]; !ok {
+		return "", false
+	}
+	return peerID.String()[:8] + ".nodo:7000", true
+}
+
+func (t *transporteFalso) SetFrameHandler(frameType uint16, handler func(peerID uuid.UUID, payload []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[frameType] = handler
+}
+
+type rutasFalsas struct {
+	mu    sync.Mutex
+	datos []*model.RoutedMessage
+}
+
+func (r *rutasFalsas) Save(ctx context.Context, ruta *model.RoutedMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.datos = append(r.datos, ruta)
+	return nil
+}
+
+func (r *rutasFalsas) FindByMessage(ctx context.Context, messageID uuid.UUID) ([]*model.RoutedMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.RoutedMessage
+	for _, ruta := range r.datos {
+		if ruta.MensajeID() == messageID {
+			lista = append(lista, ruta)
+		}
+	}
+	return lista, nil
+}
+
+func (r *rutasFalsas) DeleteByMessage(ctx context.Context, messageID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.RoutedMessage
+	for _, ruta := range r.datos {
+		if ruta.MensajeID() != messageID {
+			lista = append(lista, ruta)
+		}
+	}
+	r.datos = lista
+	return nil
+}
+
+func (r *rutasFalsas) ListByDestination(ctx context.Context, nodeID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.RoutedMessage], error) {
+	r.mu.Lock()
+	var lista []*model.RoutedMessage
+	for _, ruta := range r.datos {
+		if ruta.NodoDestinoID() == nodeID {
+			lista = append(lista, ruta)
+		}
+	}
+	r.mu.Unlock()
+	sort.Slice(lista, func(i, j int) bool {
+		if !lista[i].EnrutaAt().Equal(lista[j].EnrutaAt()) {
+			return lista[i].EnrutaAt().Before(lista[j].EnrutaAt())
+		}
+		return lista[i].MensajeID().String() < lista[j].MensajeID().String()
+	})
+	return paginar(lista, page, func(ruta *model.RoutedMessage) model.Cursor {
+		return model.NewCursor(ruta.EnrutaAt(), ruta.MensajeID())
+	}), nil
+}
+
+// clientesFalsos registra los frames enviados a cada usuario conectado al nodo
+type clientesFalsos struct {
+	mu     sync.Mutex
+	frames map[uuid.UUID][][]byte
+}
+
+func nuevosClientesFalsos() *clientesFalsos {
+	return &clientesFalsos{frames: make(map[uuid.UUID][][]byte)}
+}
+
+func (c *clientesFalsos) Broadcast(ids []uuid.UUID, frame []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		c.frames[id] = append(c.frames[id], frame)
+	}
+	return nil
+}
+
+func (c *clientesFalsos) recibidos(id uuid.UUID) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.frames[id])
+}
+
+// eventosCliente decodifica los datos de los eventos del tipo indicado recibidos por un cliente
+func eventosCliente[T any](t *testing.T, c *clientesFalsos, id uuid.UUID, evento string) []T {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var datos []T
+	for _, frame := range c.frames[id] {
+		var recibido struct {
+			Evento string `json:"evento"`
+			Datos  T      `json:"datos"`
+		}
+		if err := json.Unmarshal(frame, &recibido); err != nil {
+			t.Fatalf("frame inválido: %v", err)
+		}
+		if recibido.Evento == evento {
+			datos = append(datos, recibido.Datos)
+		}
+	}
+	return datos
+}
+
+// sesionesFalsas indica qué usuarios tienen socket en el nodo
+type sesionesFalsas struct {
+	mu         sync.Mutex
+	conectados map[uuid.UUID]bool
+}
+
+func nuevasSesionesFalsas(ids ...uuid.UUID) *sesionesFalsas {
+	s := &sesionesFalsas{conectados: make(map[uuid.UUID]bool)}
+	for _, id := range ids {
+		s.conectados[id] = true
+	}
+	return s
+}
+
+func (s *sesionesFalsas) Connected(id uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conectados[id]
+}
+
+func (s *sesionesFalsas) conectar(id uuid.UUID, conectado bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conectados[id] = conectado
+}
+
+// colaFalsa guarda en memoria la cola de eventos pendientes de cada usuario, ordenada
+// por (fecha, id) como la tabla
+type colaFalsa struct {
+	mu    sync.Mutex
+	colas map[uuid.UUID][]*model.EventoPendiente
+}
+
+func nuevaColaFalsa() *colaFalsa {
+	return &colaFalsa{colas: make(map[uuid.UUID][]*model.EventoPendiente)}
+}
+
+func (c *colaFalsa) Enqueue(_ context.Context, e *model.EventoPendiente) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cola := c.colas[e.UsuarioID()]
+	i := sort.Search(len(cola), func(i int) bool {
+		p, q := cola[i].Posicion(), e.Posicion()
+		return p.Timestamp().After(q.Timestamp()) ||
+			(p.Timestamp().Equal(q.Timestamp()) && p.ID().String() > q.ID().String())
+	})
+	c.colas[e.UsuarioID()] = append(cola[:i], append([]*model.EventoPendiente{e}, cola[i:]...)...)
+	return nil
+}
+
+func (c *colaFalsa) CountPending(_ context.Context, userID uuid.UUID) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.colas[userID]), nil
+}
+
+func (c *colaFalsa) FindPending(_ context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.EventoPendiente], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return paginar(c.colas[userID], page, (*model.EventoPendiente).Posicion), nil
+}
+
+func (c *colaFalsa) AcknowledgeUpTo(_ context.Context, userID uuid.UUID, position model.Cursor) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var quedan []*model.EventoPendiente
+	for _, e := range c.colas[userID] {
+		p := e.Posicion()
+		if p.Timestamp().After(position.Timestamp()) ||
+			(p.Timestamp().Equal(position.Timestamp()) && p.ID().String() > position.ID().String()) {
+			quedan = append(quedan, e)
+		}
+	}
+	borrados := int64(len(c.colas[userID]) - len(quedan))
+	c.colas[userID] = quedan
+	return borrados, nil
+}
+
+func (c *colaFalsa) Trim(_ context.Context, userID uuid.UUID, keep int) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cola := c.colas[userID]
+	if len(cola) <= keep {
+		return 0, nil
+	}
+	c.colas[userID] = cola[len(cola)-keep:]
+	return int64(len(cola) - keep), nil
+}
+
+// programadosFalsos guarda en memoria copias de los mensajes programados, como una
+// base de datos, de modo que los cambios del servicio solo cuentan al guardarse
+type programadosFalsos struct {
+	mu    sync.Mutex
+	datos map[uuid.UUID]model.MensajeProgramado
+}
+
+func nuevosProgramadosFalsos() *programadosFalsos {
+	return &programadosFalsos{datos: make(map[uuid.UUID]model.MensajeProgramado)}
+}
+
+func (r *programadosFalsos) Save(_ context.Context, p *model.MensajeProgramado) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.datos[p.ID()] = *p
+	return nil
+}
+
+func (r *programadosFalsos) FindByID(_ context.Context, id uuid.UUID) (*model.MensajeProgramado, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.datos[id]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (r *programadosFalsos) UpdateState(_ context.Context, p *model.MensajeProgramado, from model.EstadoProgramado) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	guardado, ok := r.datos[p.ID()]
+	if !ok || guardado.Estado() != from {
+		return false, nil
+	}
+	r.datos[p.ID()] = *p
+	return true, nil
+}
+
+func (r *programadosFalsos) FindDue(_ context.Context, nodeID uuid.UUID, at time.Time, limit int) ([]*model.MensajeProgramado, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var vencidos []*model.MensajeProgramado
+	for _, p := range r.datos {
+		if p.NodoID() != nodeID {
+			continue
+		}
+		switch {
+		case p.Estado() == model.ProgramadoPendiente && !p.EnviarEn().After(at),
+			p.Estado() == model.ProgramadoEnviado && p.Caduca() && !p.ExpiraEn().After(at),
+			p.Estado() == model.ProgramadoEnviando:
+			copia := p
+			vencidos = append(vencidos, &copia)
+		}
+	}
+	sort.Slice(vencidos, func(i, j int) bool { return vencidos[i].EnviarEn().Before(vencidos[j].EnviarEn()) })
+	if len(vencidos) > limit {
+		vencidos = vencidos[:limit]
+	}
+	return vencidos, nil
+}
+
+func (r *programadosFalsos) FindBySender(_ context.Context, senderID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.MensajeProgramado], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.MensajeProgramado
+	for _, p := range r.datos {
+		if p.RemitenteID() == senderID {
+			copia := p
+			lista = append(lista, &copia)
+		}
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].EnviarEn().Before(lista[j].EnviarEn()) })
+	return paginar(lista, page, func(p *model.MensajeProgramado) model.Cursor {
+		return model.NewCursor(p.EnviarEn(), p.ID())
+	}), nil
+}
+
+// envioFalso implementa el envío de MessageService guardando los mensajes en el
+// repositorio del nodo; con rechazo asignado, todos los envíos fallan con él
+type envioFalso struct {
+	mu       sync.Mutex
+	mensajes *mensajesFalsos
+	enviados []*model.MensajeServidor
+	rechazo  error
+}
+
+func (e *envioFalso) SendDirect(remitenteID, destinoID uuid.UUID, contenido string, archivoID uuid.UUID) (*model.MensajeServidor, error) {
+	m, err := model.NewMensajeDirecto(uuid.New(), remitenteID, destinoID, contenido, time.Now().UTC(), archivoID)
+	if err != nil {
+		return nil, err
+	}
+	return e.guardar(m)
+}
+
+func (e *envioFalso) SendChannel(remitenteID, channelID uuid.UUID, contenido string, archivoID uuid.UUID) (*model.MensajeServidor, error) {
+	m, err := model.NewMensajeCanal(uuid.New(), remitenteID, channelID, contenido, time.Now().UTC(), archivoID)
+	if err != nil {
+		return nil, err
+	}
+	return e.guardar(m)
+}
+
+func (e *envioFalso) guardar(m *model.MensajeServidor) (*model.MensajeServidor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rechazo != nil {
+		return nil, e.rechazo
+	}
+	e.enviados = append(e.enviados, m)
+	return m, e.mensajes.Save(context.Background(), m)
+}
+
+func (e *envioFalso) totalEnviados() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.enviados)
+}
+
+func (e *envioFalso) ListChannelMessages(uuid.UUID, time.Time, time.Time) ([]*model.MensajeServidor, map[uuid.UUID][]model.ConteoReaccion, error) {
+	return nil, nil, nil
+}
+
+func (e *envioFalso) ListDirectMessages(uuid.UUID, uuid.UUID, time.Time, time.Time) ([]*model.MensajeServidor, map[uuid.UUID][]model.ConteoReaccion, error) {
+	return nil, nil, nil
+}
+
+func (e *envioFalso) PageChannelMessages(uuid.UUID, model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return nil, nil
+}
+
+func (e *envioFalso) PageDirectMessages(uuid.UUID, uuid.UUID, model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+	return nil, nil
+}
+
+// mencionesFalsas guarda en memoria el feed de menciones y las preferencias de silencio
+type mencionesFalsas struct {
+	mu        sync.Mutex
+	menciones map[uuid.UUID][]*model.Mencion
+	silencios map[[2]uuid.UUID]model.SilencioMenciones
+}
+
+func nuevasMencionesFalsas() *mencionesFalsas {
+	return &mencionesFalsas{
+		menciones: make(map[uuid.UUID][]*model.Mencion),
+		silencios: make(map[[2]uuid.UUID]model.SilencioMenciones),
+	}
+}
+
+func (r *mencionesFalsas) Save(_ context.Context, m *model.Mencion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existente := range r.menciones[m.UsuarioID()] {
+		if existente.MensajeID() == m.MensajeID() {
+			return errors.New("mención duplicada")
+		}
+	}
+	r.menciones[m.UsuarioID()] = append(r.menciones[m.UsuarioID()], m)
+	return nil
+}
+
+func (r *mencionesFalsas) FindByKey(_ context.Context, userID, messageID uuid.UUID) (*model.Mencion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.menciones[userID] {
+		if m.MensajeID() == messageID {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *mencionesFalsas) FindByUser(_ context.Context, userID uuid.UUID, page model.ConsultaPagina) (*model.Pagina[*model.Mencion], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lista := append([]*model.Mencion(nil), r.menciones[userID]...)
+	sort.Slice(lista, func(i, j int) bool { return lista[i].Fecha().Before(lista[j].Fecha()) })
+	return paginar(lista, page, func(m *model.Mencion) model.Cursor {
+		return model.NewCursor(m.Fecha(), m.ID())
+	}), nil
+}
+
+func (r *mencionesFalsas) SaveMute(_ context.Context, s *model.SilencioMenciones) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.silencios[[2]uuid.UUID{s.UsuarioID(), s.CanalID()}] = *s
+	return nil
+}
+
+func (r *mencionesFalsas) FindMute(_ context.Context, userID, channelID uuid.UUID) (*model.SilencioMenciones, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.silencios[[2]uuid.UUID{userID, channelID}]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (r *mencionesFalsas) FindMutes(_ context.Context, userID uuid.UUID) ([]*model.SilencioMenciones, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lista []*model.SilencioMenciones
+	for clave, s := range r.silencios {
+		if clave[0] == userID {
+			copia := s
+			lista = append(lista, &copia)
+		}
+	}
+	return lista, nil
+}
+
+// usuariosServicioFalso implementa la consulta de usuarios de UserService sobre un
+// repositorio de usuarios en memoria
+type usuariosServicioFalso struct {
+	usuarios *usuariosFalsos
+}
+
+func (u usuariosServicioFalso) GetAll() ([]*model.UsuarioServidor, error) {
+	return u.usuarios.FindAll(context.Background())
+}
+
+func (u usuariosServicioFalso) GetByID(userID uuid.UUID) (*model.UsuarioServidor, error) {
+	return u.usuarios.FindByID(context.Background(), userID)
+}
+
+func (u usuariosServicioFalso) UpdateProfile(uuid.UUID, string, string, string) (*model.UsuarioServidor, error) {
+	return nil, errors.New("no implementado")
+}
+
+// nodoFalso agrupa el estado de un nodo simulado: su transporte en la red, su reloj HLC,
+// sus repositorios y sus clientes conectados
+type nodoFalso struct {
+	id             uuid.UUID
+	transporte     *transporteFalso
+	reloj          *model.RelojHLC
+	canales        *canalesFalsos
+	mensajes       *mensajesFalsos
+	notificaciones *notificacionesFalsas
+	menciones      *mencionesFalsas
+	clientes       *clientesFalsos
+}
+
+// nodoServicio es un nodo simulado con el servicio S que se prueba
+type nodoServicio[S any] struct {
+	*nodoFalso
+	servicio S
+}
+
+// nuevoNodoServicio une un nodo nuevo a la red y crea su servicio con crear, que puede
+// sustituir los repositorios del nodo por otros compartidos antes de usarlos
+func nuevoNodoServicio[S any](t *testing.T, red *redFalsa, crear func(n *nodoFalso) S) *nodoServicio[S] {
+	t.Helper()
+	id := uuid.New()
+	reloj, err := model.NewRelojHLC(id)
+	if err != nil {
+		t.Fatalf("NewRelojHLC: %v", err)
+	}
+	n := &nodoFalso{
+		id:             id,
+		transporte:     red.nodo(id),
+		reloj:          reloj,
+		canales:        nuevosCanalesFalsos(),
+		mensajes:       nuevosMensajesFalsos(),
+		notificaciones: nuevasNotificacionesFalsas(),
+		menciones:      nuevasMencionesFalsas(),
+		clientes:       nuevosClientesFalsos(),
+	}
+	return &nodoServicio[S]{nodoFalso: n, servicio: crear(n)}
+}
+
+// sembrarMensajeCanal guarda en el nodo una copia propia de un mensaje de canal y la
+// membresía del canal, con el rol de cada miembro
+func (n *nodoFalso) sembrarMensajeCanal(t *testing.T, id, autorID, canalID uuid.UUID, at time.Time, miembros map[uuid.UUID]string) {
+	t.Helper()
+	ctx := context.Background()
+	m, err := model.NewMensajeCanal(id, autorID, canalID, "original", at, uuid.Nil)
+	if err != nil {
+		t.Fatalf("NewMensajeCanal: %v", err)
+	}
+	n.mensajes.Save(ctx, m)
+	for usuarioID, rol := range miembros {
+		n.canales.AddMember(ctx, canalID, usuarioID, rol)
+	}
+}
@@ -12,5 +12,8 @@ replace (
 require (
 	github.com/google/uuid v1.6.0
 	github.com/stretchr/testify v1.8.4
-	model v0.0.0-00010101000000-000000000000
+	golang.org/x/crypto v0.33.0
+	model v0.0.0
+	observer v0.0.0
+	repository.interfaces v0.0.0-00010101000000-000000000000
 )
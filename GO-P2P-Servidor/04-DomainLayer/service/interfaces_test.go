@@ -16,6 +16,16 @@ func TestInterfacesUsingDomainModels(t *testing.T) {
 		fileService       FileService
 		invitationService InvitationService
 		messageService    MessageService
+		messageEditSvc    MessageEditService
+		messageSearchSvc  MessageSearchService
+		threadService     ThreadService
+		reactionService   ReactionService
+		receiptService    ReceiptService
+		offlineQueue      OfflineQueueService
+		activityService   ActivityService
+		scheduledSvc      ScheduledMessageService
+		mentionService    MentionService
+		pinService        PinService
 		userService       UserService
 		presenceService   PresenceService
 		notificationSvc   NotificationService
@@ -30,6 +40,16 @@ func TestInterfacesUsingDomainModels(t *testing.T) {
 	_ = fileService
 	_ = invitationService
 	_ = messageService
+	_ = messageEditSvc
+	_ = messageSearchSvc
+	_ = threadService
+	_ = reactionService
+	_ = receiptService
+	_ = offlineQueue
+	_ = activityService
+	_ = scheduledSvc
+	_ = mentionService
+	_ = pinService
 	_ = userService
 	_ = presenceService
 	_ = notificationSvc
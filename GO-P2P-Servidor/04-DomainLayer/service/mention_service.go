@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// ErrMencionSinAcceso indica que el usuario no puede configurar las menciones de un
+// canal del que no es miembro
+var ErrMencionSinAcceso = errors.New("el usuario no es miembro del canal")
+
+// MentionService define las menciones con @usuario y @channel en los mensajes de canal.
+// El nodo en que se envía el mensaje resuelve los nombres con UserService, descarta a
+// quienes no son miembros del canal, notifica con NotificationService a los mencionados
+// que no lo han silenciado y difunde cada mención a todos los nodos. Cada nodo la añade
+// al feed de menciones del usuario y, si se notificó, la envía como evento a su
+// cliente si está conectado a él.
+type MentionService interface {
+	// ProcessMessage registra las menciones de un mensaje de canal recién enviado y
+	// devuelve las nuevas. El remitente no se menciona a sí mismo, y procesar otra vez
+	// el mismo mensaje no repite las menciones ya registradas. Los mensajes directos y
+	// de chats privados no tienen menciones.
+	ProcessMessage(m *model.MensajeServidor) ([]*model.Mencion, error)
+
+	// ListMentions pagina en orden cronológico el feed de menciones del usuario. Incluye
+	// las silenciadas, que se registran aunque no se notifiquen.
+	ListMentions(userID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.Mencion], error)
+
+	// SetMute configura qué menciones de un canal, o de todos con channelID uuid.Nil, se
+	// notifican al usuario hasta until; con until cero, sin fin. SilencioNinguno
+	// vuelve a notificarlas todas.
+	SetMute(
+		userID, channelID uuid.UUID,
+		nivel model.NivelSilencio,
+		until time.Time,
+	) (*model.SilencioMenciones, error)
+
+	// ListMutes devuelve las preferencias de silencio del usuario
+	ListMutes(userID uuid.UUID) ([]*model.SilencioMenciones, error)
+}
+
+// difusionMencion es el payload de FrameTipoMencion: la mención y si el nodo de origen
+// la notificó, en cuyo caso los demás la envían también a sus clientes
+type difusionMencion struct {
+	Mencion registroMencion `json:"mencion"`
+	Avisar  bool            `json:"avisar"`
+}
+
+// mentionService implementa MentionService sobre PeerTransport y ClientTransport
+type mentionService struct {
+	transport     PeerTransport
+	clients       ClientTransport
+	users         UserService
+	channels      repository.IChannelRepository
+	notifications NotificationService
+	mentions      repository.IMentionRepository
+}
+
+// NewMentionService crea el servicio y registra sus handlers de frames en el transporte
+func NewMentionService(
+	transport PeerTransport,
+	clients ClientTransport,
+	users UserService,
+	channels repository.IChannelRepository,
+	notifications NotificationService,
+	mentions repository.IMentionRepository,
+) MentionService {
+	s := &mentionService{
+		transport:     transport,
+		clients:       clients,
+		users:         users,
+		channels:      channels,
+		notifications: notifications,
+		mentions:      mentions,
+	}
+	transport.SetFrameHandler(FrameTipoMencion, s.handleMencion)
+	transport.SetFrameHandler(FrameTipoSilencioMencion, s.handleSilencio)
+	return s
+}
+
+// ProcessMessage implementa MentionService
+func (s *mentionService) ProcessMessage(m *model.MensajeServidor) ([]*model.Mencion, error) {
+	if m.CanalID() == uuid.Nil || m.Eliminado() {
+		return nil, nil
+	}
+	nombres, todoElCanal := model.ExtraerMenciones(m.Contenido())
+	if len(nombres) == 0 && !todoElCanal {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	mencionados, tipos, err := s.mencionados(ctx, m, nombres, todoElCanal)
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now().UTC()
+	var nuevas []*model.Mencion
+	for _, usuarioID := range mencionados {
+		existente, err := s.mentions.FindByKey(ctx, usuarioID, m.ID())
+		if err != nil {
+			return nuevas, err
+		}
+		if existente != nil {
+			continue
+		}
+		silenciada, err := s.silenciada(ctx, usuarioID, m.CanalID(), tipos[usuarioID], ahora)
+		if err != nil {
+			return nuevas, err
+		}
+
+		// Un fallo al notificar no impide registrar la mención en el feed
+		notificacionID := uuid.Nil
+		if !silenciada && s.notifications != nil {
+			if n, err := s.notifications.Notify(usuarioID, contenidoMencion(tipos[usuarioID], m)); err == nil {
+				notificacionID = n.ID()
+			}
+		}
+		mencion, err := model.NewMencion(uuid.New(), usuarioID, m.ID(), m.CanalID(), m.RemitenteID(), tipos[usuarioID], m.Timestamp(), notificacionID)
+		if err != nil {
+			return nuevas, err
+		}
+		if err := s.mentions.Save(ctx, mencion); err != nil {
+			return nuevas, err
+		}
+		s.difundirMencion(mencion, !silenciada, uuid.Nil)
+		if !silenciada {
+			s.avisar(mencion)
+		}
+		nuevas = append(nuevas, mencion)
+	}
+	return nuevas, nil
+}
+
+// ListMentions implementa MentionService
+func (s *mentionService) ListMentions(userID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.Mencion], error) {
+	if userID == uuid.Nil {
+		return nil, model.ErrMencionUsuarioIDNil
+	}
+	return s.mentions.FindByUser(context.Background(), userID, consulta)
+}
+
+// SetMute implementa MentionService
+func (s *mentionService) SetMute(
+	userID, channelID uuid.UUID,
+	nivel model.NivelSilencio,
+	until time.Time,
+) (*model.SilencioMenciones, error) {
+	silencio, err := model.NewSilencioMenciones(userID, channelID, nivel, until, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if channelID != uuid.Nil {
+		miembro, err := esMiembroCanal(ctx, s.channels, channelID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !miembro {
+			return nil, ErrMencionSinAcceso
+		}
+	}
+
+	guardado, cambiado, err := s.fusionarSilencio(ctx, silencio)
+	if err != nil {
+		return nil, err
+	}
+	if cambiado {
+		s.difundirSilencio(guardado, uuid.Nil)
+	}
+	return guardado, nil
+}
+
+// ListMutes implementa MentionService
+func (s *mentionService) ListMutes(userID uuid.UUID) ([]*model.SilencioMenciones, error) {
+	if userID == uuid.Nil {
+		return nil, model.ErrSilencioUsuarioIDNil
+	}
+	return s.mentions.FindMutes(context.Background(), userID)
+}
+
+// mencionados devuelve, en orden de aparición y después en el de los miembros, los
+// miembros del canal mencionados en el mensaje salvo su remitente, con el tipo de su
+// mención. Quien se menciona por su nombre y con @channel cuenta como mención por
+// nombre; los nombres que no son de ningún usuario o de ningún miembro se ignoran.
+func (s *mentionService) mencionados(
+	ctx context.Context,
+	m *model.MensajeServidor,
+	nombres []string,
+	todoElCanal bool,
+) ([]uuid.UUID, map[uuid.UUID]model.TipoMencion, error) {
+	miembros, err := s.channels.ListMembers(ctx, m.CanalID())
+	if err != nil {
+		return nil, nil, err
+	}
+	esMiembro := make(map[uuid.UUID]bool, len(miembros))
+	for _, id := range miembros {
+		esMiembro[id] = true
+	}
+
+	var ids []uuid.UUID
+	tipos := make(map[uuid.UUID]model.TipoMencion)
+	incluir := func(id uuid.UUID, tipo model.TipoMencion) {
+		if id == m.RemitenteID() || !esMiembro[id] || tipos[id] != "" {
+			return
+		}
+		ids = append(ids, id)
+		tipos[id] = tipo
+	}
+
+	if len(nombres) > 0 {
+		usuarios, err := s.users.GetAll()
+		if err != nil {
+			return nil, nil, err
+		}
+		porNombre := make(map[string]uuid.UUID, len(usuarios))
+		for _, u := range usuarios {
+			porNombre[strings.ToLower(u.NombreUsuario())] = u.ID()
+		}
+		for _, nombre := range nombres {
+			if id, ok := porNombre[nombre]; ok {
+				incluir(id, model.MencionUsuario)
+			}
+		}
+	}
+	if todoElCanal {
+		for _, id := range miembros {
+			incluir(id, model.MencionCanal)
+		}
+	}
+	return ids, tipos, nil
+}
+
+// silenciada indica si el usuario silenció en at las menciones del tipo dado en el
+// canal. Una preferencia vigente del canal prevalece sobre la general.
+func (s *mentionService) silenciada(ctx context.Context, userID, channelID uuid.UUID, tipo model.TipoMencion, at time.Time) (bool, error) {
+	silencio, err := s.mentions.FindMute(ctx, userID, channelID)
+	if err != nil {
+		return false, err
+	}
+	if silencio == nil || !silencio.Vigente(at) {
+		if silencio, err = s.mentions.FindMute(ctx, userID, uuid.Nil); err != nil || silencio == nil {
+			return false, err
+		}
+	}
+	return silencio.Silencia(tipo, at), nil
+}
+
+// fusionarSilencio guarda la preferencia si no había otra o si es posterior a la
+// guardada, y devuelve la que queda vigente e indica si cambió
+func (s *mentionService) fusionarSilencio(ctx context.Context, silencio *model.SilencioMenciones) (*model.SilencioMenciones, bool, error) {
+	guardado, err := s.mentions.FindMute(ctx, silencio.UsuarioID(), silencio.CanalID())
+	if err != nil {
+		return nil, false, err
+	}
+	if guardado == nil {
+		guardado = silencio
+	} else if !guardado.Fusionar(silencio) {
+		return guardado, false, nil
+	}
+	if err := s.mentions.SaveMute(ctx, guardado); err != nil {
+		return nil, false, err
+	}
+	return guardado, true, nil
+}
+
+// handleMencion guarda la mención recibida de otro nodo, la envía al cliente del
+// mencionado si el origen la notificó y la reenvía a los demás vecinos. Una mención
+// ya registrada no se reenvía, lo que detiene la inundación.
+func (s *mentionService) handleMencion(peerID uuid.UUID, payload []byte) {
+	var difusion difusionMencion
+	if err := json.Unmarshal(payload, &difusion); err != nil {
+		return
+	}
+	mencion, err := difusion.Mencion.aModelo()
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	existente, err := s.mentions.FindByKey(ctx, mencion.UsuarioID(), mencion.MensajeID())
+	if err != nil || existente != nil {
+		return
+	}
+	if s.mentions.Save(ctx, mencion) != nil {
+		return
+	}
+	s.difundirMencion(mencion, difusion.Avisar, peerID)
+	if difusion.Avisar {
+		s.avisar(mencion)
+	}
+}
+
+// handleSilencio adopta la preferencia recibida de otro nodo si es posterior a la
+// local y, en ese caso, la reenvía a los demás vecinos
+func (s *mentionService) handleSilencio(peerID uuid.UUID, payload []byte) {
+	var registro registroSilencio
+	if err := json.Unmarshal(payload, &registro); err != nil {
+		return
+	}
+	silencio, err := registro.aModelo()
+	if err != nil {
+		return
+	}
+	guardado, cambiado, err := s.fusionarSilencio(context.Background(), silencio)
+	if err != nil || !cambiado {
+		return
+	}
+	s.difundirSilencio(guardado, peerID)
+}
+
+// difundirMencion envía la mención a todos los vecinos salvo al que la envió
+func (s *mentionService) difundirMencion(m *model.Mencion, avisar bool, origen uuid.UUID) {
+	payload, err := json.Marshal(difusionMencion{Mencion: aRegistroMencion(m), Avisar: avisar})
+	if err != nil {
+		return
+	}
+	s.difundir(FrameTipoMencion, payload, origen)
+}
+
+// difundirSilencio envía la preferencia a todos los vecinos salvo al que la envió
+func (s *mentionService) difundirSilencio(silencio *model.SilencioMenciones, origen uuid.UUID) {
+	payload, err := json.Marshal(aRegistroSilencio(silencio))
+	if err != nil {
+		return
+	}
+	s.difundir(FrameTipoSilencioMencion, payload, origen)
+}
+
+// difundir envía un frame a todos los vecinos salvo a origen
+func (s *mentionService) difundir(tipo uint16, payload []byte, origen uuid.UUID) {
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		if peerID == origen {
+			continue
+		}
+		// Un vecino caído no recibe la mención; el usuario la tiene en sus notificaciones
+		_ = s.transport.SendTo(peerID, tipo, payload)
+	}
+}
+
+// avisar envía la mención al usuario mencionado si está conectado a este nodo
+func (s *mentionService) avisar(m *model.Mencion) {
+	if s.clients == nil {
+		return
+	}
+	frame, err := codificarEventoCliente(EventoClienteMencion, aRegistroMencion(m))
+	if err != nil {
+		return
+	}
+	_ = s.clients.Broadcast([]uuid.UUID{m.UsuarioID()}, frame)
+}
+
+// contenidoMencion es el texto de la notificación de una mención
+func contenidoMencion(tipo model.TipoMencion, m *model.MensajeServidor) string {
+	if tipo == model.MencionCanal {
+		return "Nuevo mensaje para todo un canal del que eres miembro: " + extracto(m.Contenido())
+	}
+	return "Te han mencionado en un canal: " + extracto(m.Contenido())
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nuevoNodoMenciones crea un nodo simulado con su MentionService sobre un directorio de
+// usuarios compartido
+func nuevoNodoMenciones(t *testing.T, red *redFalsa, usuarios *usuariosFalsos) *nodoServicio[MentionService] {
+	t.Helper()
+	return nuevoNodoServicio(t, red, func(n *nodoFalso) MentionService {
+		return NewMentionService(n.transporte, n.clientes, usuariosServicioFalso{usuarios}, n.canales, n.notificaciones, n.menciones)
+	})
+}
+
+// usuarioMenciones crea un usuario con el nombre dado en el directorio compartido
+func usuarioMenciones(t *testing.T, usuarios *usuariosFalsos, nombre string) uuid.UUID {
+	t.Helper()
+	u, err := model.NewUsuarioServidor(uuid.New(), nombre, nombre+"@prueba.com", "hash", "", "127.0.0.1", time.Now())
+	if err != nil {
+		t.Fatalf("NewUsuarioServidor: %v", err)
+	}
+	usuarios.Save(context.Background(), u)
+	return u.ID()
+}
+
+func TestMention_NotificaMiembrosYRespetaSilencios(t *testing.T) {
+	red := nuevaRedFalsa()
+	usuarios := nuevosUsuariosFalsos()
+	a, b := nuevoNodoMenciones(t, red, usuarios), nuevoNodoMenciones(t, red, usuarios)
+	ana, beto, carla := usuarioMenciones(t, usuarios, "ana"), usuarioMenciones(t, usuarios, "Beto"), usuarioMenciones(t, usuarios, "carla")
+	dani, eva := usuarioMenciones(t, usuarios, "dani"), usuarioMenciones(t, usuarios, "eva")
+	canalID := uuid.New()
+	for _, n := range []*nodoServicio[MentionService]{a, b} {
+		for _, id := range []uuid.UUID{ana, beto, carla, eva} {
+			n.canales.AddMember(context.Background(), canalID, id, "MIEMBRO")
+		}
+	}
+
+	// Carla silencia en otro nodo las menciones a todo el canal
+	if _, err := b.servicio.SetMute(carla, canalID, model.SilencioCanal, time.Time{}); err != nil {
+		t.Fatalf("SetMute: %v", err)
+	}
+	esperarHasta(t, func() bool {
+		s, _ := a.menciones.FindMute(context.Background(), carla, canalID)
+		return s != nil
+	})
+
+	m, _ := model.NewMensajeCanal(uuid.New(), ana, canalID, "@beto @dani @nadie @ana mirad esto, @channel", time.Now(), uuid.Nil)
+	nuevas, err := a.servicio.ProcessMessage(m)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	tipos := make(map[uuid.UUID]model.TipoMencion)
+	for _, mencion := range nuevas {
+		tipos[mencion.UsuarioID()] = mencion.Tipo()
+	}
+	esperados := map[uuid.UUID]model.TipoMencion{beto: model.MencionUsuario, carla: model.MencionCanal, eva: model.MencionCanal}
+	if len(tipos) != len(esperados) {
+		t.Fatalf("esperadas las menciones %v, obtuvo %v", esperados, tipos)
+	}
+	for id, tipo := range esperados {
+		if tipos[id] != tipo {
+			t.Errorf("mención de %v: esperado %s, obtuvo %s", id, tipo, tipos[id])
+		}
+	}
+
+	// Solo se notifica a quien no lo ha silenciado, en el nodo de origen y en los demás
+	for id, total := range map[uuid.UUID]int{beto: 1, eva: 1, carla: 0, dani: 0, ana: 0} {
+		if notificaciones, _ := a.notificaciones.List(id); len(notificaciones) != total {
+			t.Errorf("notificaciones de %v: esperadas %d, obtuvo %d", id, total, len(notificaciones))
+		}
+		if eventos := eventosCliente[registroMencion](t, a.clientes, id, EventoClienteMencion); len(eventos) != total {
+			t.Errorf("eventos de %v: esperados %d, obtuvo %d", id, total, len(eventos))
+		}
+	}
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[registroMencion](t, b.clientes, beto, EventoClienteMencion)) == 1 && len(eventosCliente[registroMencion](t, b.clientes, eva, EventoClienteMencion)) == 1
+	})
+	if notificaciones, _ := b.notificaciones.List(beto); len(notificaciones) != 0 {
+		t.Error("las notificaciones solo se crean en el nodo de origen")
+	}
+
+	// La mención silenciada queda en el feed de cualquier nodo, sin aviso
+	esperarHasta(t, func() bool {
+		pagina, _ := b.servicio.ListMentions(carla, model.ConsultaPagina{})
+		return len(pagina.Elementos) == 1
+	})
+	if len(eventosCliente[registroMencion](t, b.clientes, carla, EventoClienteMencion)) != 0 {
+		t.Error("una mención silenciada no se envía al cliente")
+	}
+
+	// Procesar otra vez el mensaje no repite las menciones
+	if repetidas, err := a.servicio.ProcessMessage(m); err != nil || len(repetidas) != 0 {
+		t.Errorf("esperadas sin menciones nuevas, obtuvo %v %v", repetidas, err)
+	}
+}
+
+func TestMention_PreferenciasDeSilencio(t *testing.T) {
+	usuarios := nuevosUsuariosFalsos()
+	n := nuevoNodoMenciones(t, nuevaRedFalsa(), usuarios)
+	ana, beto := usuarioMenciones(t, usuarios, "ana"), usuarioMenciones(t, usuarios, "beto")
+	canalID, otroCanalID := uuid.New(), uuid.New()
+	n.canales.AddMember(context.Background(), canalID, ana, "MIEMBRO")
+	n.canales.AddMember(context.Background(), canalID, beto, "MIEMBRO")
+
+	if _, err := n.servicio.SetMute(beto, otroCanalID, model.SilencioTodo, time.Time{}); err != ErrMencionSinAcceso {
+		t.Errorf("esperado %v, obtuvo %v", ErrMencionSinAcceso, err)
+	}
+	if _, err := n.servicio.SetMute(beto, canalID, "ALGUNAS", time.Time{}); err != model.ErrSilencioNivelInvalido {
+		t.Errorf("esperado %v, obtuvo %v", model.ErrSilencioNivelInvalido, err)
+	}
+
+	// El silencio general se aplica salvo donde el canal tiene su propia preferencia
+	if _, err := n.servicio.SetMute(beto, uuid.Nil, model.SilencioTodo, time.Time{}); err != nil {
+		t.Fatalf("SetMute general: %v", err)
+	}
+	enviar := func(contenido string) {
+		t.Helper()
+		m, _ := model.NewMensajeCanal(uuid.New(), ana, canalID, contenido, time.Now(), uuid.Nil)
+		if _, err := n.servicio.ProcessMessage(m); err != nil {
+			t.Fatalf("ProcessMessage: %v", err)
+		}
+	}
+	enviar("hola @beto")
+	if notificaciones, _ := n.notificaciones.List(beto); len(notificaciones) != 0 {
+		t.Fatal("el silencio general debe aplicarse")
+	}
+	if _, err := n.servicio.SetMute(beto, canalID, model.SilencioNinguno, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("SetMute canal: %v", err)
+	}
+	enviar("otra vez, @beto")
+	if notificaciones, _ := n.notificaciones.List(beto); len(notificaciones) != 1 {
+		t.Errorf("la preferencia del canal prevalece, notificaciones %d", len(notificaciones))
+	}
+
+	silencios, err := n.servicio.ListMutes(beto)
+	if err != nil || len(silencios) != 2 {
+		t.Errorf("ListMutes: %v %v", silencios, err)
+	}
+	pagina, err := n.servicio.ListMentions(beto, model.ConsultaPagina{})
+	if err != nil || len(pagina.Elementos) != 2 {
+		t.Errorf("ListMentions: %v %v", pagina, err)
+	}
+
+	// Los mensajes directos no tienen menciones
+	directo, _ := model.NewMensajeDirecto(uuid.New(), ana, beto, "@beto", time.Now(), uuid.Nil)
+	if menciones, err := n.servicio.ProcessMessage(directo); err != nil || menciones != nil {
+		t.Errorf("esperado sin menciones, obtuvo %v %v", menciones, err)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+)
+
+// arbolMerkle resume un conjunto de cubetas (rangos de registros) en un único hash raíz.
+// Cada hoja es el hash de una cubeta; cada nodo interno el hash de sus dos hijos.
+// Dos nodos con la misma raíz tienen exactamente las mismas cubetas.
+type arbolMerkle struct {
+	claves  []string          // claves de cubeta ordenadas, una por hoja
+	hojas   map[string][]byte // clave de cubeta → hash de la cubeta
+	niveles [][][]byte        // niveles[0] son las hojas, el último contiene la raíz
+}
+
+// nuevoArbolMerkle construye el árbol a partir de los hashes de cada registro agrupados por cubeta.
+// El orden de los registros dentro de una cubeta no afecta al resultado.
+func nuevoArbolMerkle(cubetas map[string][][]byte) *arbolMerkle {
+	arbol := &arbolMerkle{hojas: make(map[string][]byte, len(cubetas))}
+
+	for clave, registros := range cubetas {
+		arbol.claves = append(arbol.claves, clave)
+		arbol.hojas[clave] = hashCubeta(registros)
+	}
+	sort.Strings(arbol.claves)
+
+	nivel := make([][]byte, len(arbol.claves))
+	for i, clave := range arbol.claves {
+		// La clave forma parte de la hoja para distinguir cubetas con el mismo contenido
+		nivel[i] = hashDe([]byte(clave), arbol.hojas[clave])
+	}
+	arbol.niveles = append(arbol.niveles, nivel)
+
+	for len(nivel) > 1 {
+		siguiente := make([][]byte, 0, (len(nivel)+1)/2)
+		for i := 0; i < len(nivel); i += 2 {
+			if i+1 < len(nivel) {
+				siguiente = append(siguiente, hashDe(nivel[i], nivel[i+1]))
+			} else {
+				siguiente = append(siguiente, nivel[i])
+			}
+		}
+		arbol.niveles = append(arbol.niveles, siguiente)
+		nivel = siguiente
+	}
+	return arbol
+}
+
+// raiz devuelve el hash raíz; un árbol vacío tiene como raíz el hash de nada
+func (a *arbolMerkle) raiz() []byte {
+	ultimo := a.niveles[len(a.niveles)-1]
+	if len(ultimo) == 0 {
+		return hashDe()
+	}
+	return ultimo[0]
+}
+
+// cubetasDistintas devuelve las cubetas cuyo hash difiere de las hojas remotas,
+// incluidas las que solo existen en uno de los dos lados
+func (a *arbolMerkle) cubetasDistintas(remotas map[string][]byte) []string {
+	var distintas []string
+	for clave, hash := range a.hojas {
+		if otro, ok := remotas[clave]; !ok || !bytes.Equal(hash, otro) {
+			distintas = append(distintas, clave)
+		}
+	}
+	for clave := range remotas {
+		if _, ok := a.hojas[clave]; !ok {
+			distintas = append(distintas, clave)
+		}
+	}
+	sort.Strings(distintas)
+	return distintas
+}
+
+// hashCubeta combina los hashes de los registros de una cubeta de forma independiente del orden
+func hashCubeta(registros [][]byte) []byte {
+	ordenados := make([][]byte, len(registros))
+	copy(ordenados, registros)
+	sort.Slice(ordenados, func(i, j int) bool {
+		return bytes.Compare(ordenados[i], ordenados[j]) < 0
+	})
+	return hashDe(ordenados...)
+}
+
+// hashDe calcula SHA-256 sobre la concatenación de las partes
+func hashDe(partes ...[]byte) []byte {
+	h := sha256.New()
+	for _, p := range partes {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
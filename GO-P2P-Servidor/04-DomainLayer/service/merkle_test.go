@@ -0,0 +1,59 @@
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestArbolMerkle_RaizIndependienteDelOrden(t *testing.T) {
+	a := nuevoArbolMerkle(map[string][][]byte{
+		"usuarios/20250101": {[]byte("u1"), []byte("u2")},
+		"canales/a":         {[]byte("c1")},
+		"mensajes/20250101": {[]byte("m1")},
+	})
+	b := nuevoArbolMerkle(map[string][][]byte{
+		"mensajes/20250101": {[]byte("m1")},
+		"usuarios/20250101": {[]byte("u2"), []byte("u1")},
+		"canales/a":         {[]byte("c1")},
+	})
+	if !bytes.Equal(a.raiz(), b.raiz()) {
+		t.Errorf("raíces distintas para el mismo contenido: %x vs %x", a.raiz(), b.raiz())
+	}
+	if len(a.cubetasDistintas(b.hojas)) != 0 {
+		t.Errorf("no se esperaban cubetas distintas, obtuvo %v", a.cubetasDistintas(b.hojas))
+	}
+}
+
+func TestArbolMerkle_CubetasDistintas(t *testing.T) {
+	local := nuevoArbolMerkle(map[string][][]byte{
+		"canales/a":         {[]byte("c1")},
+		"canales/b":         {[]byte("c2")},
+		"usuarios/20250101": {[]byte("u1")},
+	})
+	remoto := nuevoArbolMerkle(map[string][][]byte{
+		"canales/a":         {[]byte("c1")},
+		"canales/b":         {[]byte("c2 editado")},
+		"mensajes/20250101": {[]byte("m1")},
+	})
+	if bytes.Equal(local.raiz(), remoto.raiz()) {
+		t.Fatal("esperaba raíces distintas")
+	}
+
+	esperado := []string{"canales/b", "mensajes/20250101", "usuarios/20250101"}
+	if obtenido := local.cubetasDistintas(remoto.hojas); !reflect.DeepEqual(obtenido, esperado) {
+		t.Errorf("esperado %v, obtuvo %v", esperado, obtenido)
+	}
+}
+
+func TestArbolMerkle_Vacio(t *testing.T) {
+	a := nuevoArbolMerkle(nil)
+	b := nuevoArbolMerkle(map[string][][]byte{})
+	if !bytes.Equal(a.raiz(), b.raiz()) {
+		t.Errorf("raíces de árboles vacíos distintas: %x vs %x", a.raiz(), b.raiz())
+	}
+	c := nuevoArbolMerkle(map[string][][]byte{"canales/a": {[]byte("c1")}})
+	if bytes.Equal(a.raiz(), c.raiz()) {
+		t.Error("un árbol vacío no debe coincidir con uno con datos")
+	}
+}
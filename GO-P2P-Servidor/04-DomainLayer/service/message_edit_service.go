@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de edición y borrado de mensajes
+var (
+	ErrEdicionMensajeNoEncontrado = errors.New("mensaje no encontrado")
+	ErrEdicionSinPermiso          = errors.New("solo el autor o un administrador del canal puede modificar el mensaje")
+)
+
+// MessageEditService define la edición y el borrado lógico de mensajes ya enviados.
+// Cada cambio se guarda junto con su entrada del historial en una transacción, se
+// difunde a todos los nodos por inundación y se envía como evento a los clientes
+// conectados que pueden ver el mensaje. Los nodos que no reciban la difusión convergen
+// por anti-entropía, que fusiona los estados con ConflictResolver.ResolveMessage.
+type MessageEditService interface {
+	// EditMessage sustituye el contenido de un mensaje. Solo puede hacerlo su autor o,
+	// en un mensaje de canal, un administrador o propietario del canal.
+	EditMessage(editorID, messageID uuid.UUID, contenido string) (*model.MensajeServidor, error)
+
+	// DeleteMessage elimina lógicamente un mensaje, con los mismos permisos que EditMessage
+	DeleteMessage(editorID, messageID uuid.UUID) (*model.MensajeServidor, error)
+
+	// ListEdits devuelve el historial de ediciones y borrados de un mensaje en orden cronológico
+	ListEdits(messageID uuid.UUID) ([]*model.EdicionMensaje, error)
+}
+
+// mensajeEdicion es el payload JSON de los frames FrameTipoEdicionMensaje: el estado
+// del mensaje tras el cambio y la entrada del historial que lo originó
+type mensajeEdicion struct {
+	Mensaje registroMensaje `json:"mensaje"`
+	Edicion registroEdicion `json:"edicion"`
+}
+
+// cambioMensaje es el evento que reciben los clientes al editarse o eliminarse un mensaje.
+// El contenido de un mensaje eliminado no se envía.
+type cambioMensaje struct {
+	Mensaje  registroMensaje `json:"mensaje"`
+	EditorID uuid.UUID       `json:"editorId"`
+}
+
+// messageEditService implementa MessageEditService sobre PeerTransport y ClientTransport
+type messageEditService struct {
+	transport PeerTransport
+	clients   ClientTransport
+	channels  repository.IChannelRepository
+	messages  repository.IMessageRepository
+	uow       repository.IUnitOfWork
+	resolver  ConflictResolver
+	reloj     *model.RelojHLC
+}
+
+// NewMessageEditService crea el servicio y registra su handler de frames en el transporte.
+// reloj debe ser el reloj HLC del propio nodo.
+func NewMessageEditService(
+	transport PeerTransport,
+	clients ClientTransport,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	uow repository.IUnitOfWork,
+	resolver ConflictResolver,
+	reloj *model.RelojHLC,
+) MessageEditService {
+	s := &messageEditService{
+		transport: transport,
+		clients:   clients,
+		channels:  channels,
+		messages:  messages,
+		uow:       uow,
+		resolver:  resolver,
+		reloj:     reloj,
+	}
+	transport.SetFrameHandler(FrameTipoEdicionMensaje, s.handleEdicion)
+	return s
+}
+
+// EditMessage implementa MessageEditService
+func (s *messageEditService) EditMessage(editorID, messageID uuid.UUID, contenido string) (*model.MensajeServidor, error) {
+	return s.modificar(editorID, messageID, model.EdicionContenido, func(m *model.MensajeServidor, at time.Time) error {
+		return m.Editar(contenido, at)
+	})
+}
+
+// DeleteMessage implementa MessageEditService
+func (s *messageEditService) DeleteMessage(editorID, messageID uuid.UUID) (*model.MensajeServidor, error) {
+	return s.modificar(editorID, messageID, model.EdicionBorrado, func(m *model.MensajeServidor, at time.Time) error {
+		return m.Eliminar(at)
+	})
+}
+
+// ListEdits implementa MessageEditService
+func (s *messageEditService) ListEdits(messageID uuid.UUID) ([]*model.EdicionMensaje, error) {
+	return s.messages.FindEdits(context.Background(), messageID)
+}
+
+// modificar aplica un cambio local a un mensaje: comprueba el permiso, versiona el
+// cambio con el HLC del nodo, lo guarda con su entrada del historial y lo difunde
+func (s *messageEditService) modificar(
+	editorID, messageID uuid.UUID,
+	tipo model.TipoEdicion,
+	cambiar func(m *model.MensajeServidor, at time.Time) error,
+) (*model.MensajeServidor, error) {
+	ctx := context.Background()
+	m, err := s.messages.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrEdicionMensajeNoEncontrado
+	}
+	if err := s.autorizar(ctx, editorID, m); err != nil {
+		return nil, err
+	}
+
+	anterior := m.Contenido()
+	ahora := time.Now().UTC()
+	if err := cambiar(m, ahora); err != nil {
+		return nil, err
+	}
+	version := s.reloj.Now()
+	m.SetVersion(version)
+
+	edicion, err := model.NewEdicionMensaje(uuid.New(), m.ID(), editorID, tipo, anterior, ahora)
+	if err != nil {
+		return nil, err
+	}
+	edicion.SetVersion(version)
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.messages.Update(ctx, m); err != nil {
+			return err
+		}
+		return s.messages.SaveEdit(ctx, edicion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.difundir(&mensajeEdicion{Mensaje: aRegistroMensaje(m), Edicion: aRegistroEdicion(edicion)}, uuid.Nil)
+	s.notificar(m, edicion)
+	return m, nil
+}
+
+// autorizar comprueba que el editor sea el autor del mensaje o, en un mensaje de
+// canal, un miembro del canal con rol de administrador o propietario
+func (s *messageEditService) autorizar(ctx context.Context, editorID uuid.UUID, m *model.MensajeServidor) error {
+	if editorID == m.RemitenteID() {
+		return nil
+	}
+	if m.CanalID() == uuid.Nil {
+		return ErrEdicionSinPermiso
+	}
+	miembros, err := s.channels.FindMembers(ctx, m.CanalID())
+	if err != nil {
+		return err
+	}
+	for _, miembro := range miembros {
+		if miembro.UsuarioID() == editorID && rangoRol(miembro.Rol()) >= rangoRol("ADMIN") {
+			return nil
+		}
+	}
+	return ErrEdicionSinPermiso
+}
+
+// handleEdicion aplica un cambio recibido de otro nodo y lo reenvía a los demás vecinos.
+// Un cambio cuya entrada de historial ya existe se ha aplicado antes y no se reenvía,
+// lo que detiene la inundación.
+func (s *messageEditService) handleEdicion(peerID uuid.UUID, payload []byte) {
+	var msg mensajeEdicion
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return
+	}
+	remoto, err := msg.Mensaje.aModelo()
+	if err != nil {
+		return
+	}
+	edicion, err := msg.Edicion.aModelo()
+	if err != nil || edicion.MensajeID() != remoto.ID() {
+		return
+	}
+	s.reloj.Update(remoto.Version())
+
+	var resultado *model.MensajeServidor
+	aplicada := false
+	err = s.uow.Do(context.Background(), func(ctx context.Context) error {
+		historial, err := s.messages.FindEdits(ctx, remoto.ID())
+		if err != nil {
+			return err
+		}
+		for _, e := range historial {
+			if e.ID() == edicion.ID() {
+				aplicada = true
+				return nil
+			}
+		}
+
+		resultado, err = s.fusionar(ctx, remoto)
+		if err != nil {
+			return err
+		}
+		return s.messages.SaveEdit(ctx, edicion)
+	})
+	if err != nil || aplicada {
+		return
+	}
+
+	s.difundir(&msg, peerID)
+	s.notificar(resultado, edicion)
+}
+
+// fusionar guarda el estado remoto de un mensaje resolviéndolo contra el local y
+// devuelve el estado resultante
+func (s *messageEditService) fusionar(ctx context.Context, remoto *model.MensajeServidor) (*model.MensajeServidor, error) {
+	local, err := s.messages.FindByID(ctx, remoto.ID())
+	if err != nil {
+		return nil, err
+	}
+	if local == nil {
+		return remoto, s.messages.Save(ctx, remoto)
+	}
+
+	versiones, err := s.resolver.ResolveMessage(local, remoto)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range versiones {
+		if m.ID() != local.ID() {
+			continue
+		}
+		if m != local {
+			if err := s.messages.Update(ctx, m); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	return local, nil
+}
+
+// difundir envía el cambio a todos los vecinos salvo al que lo envió
+func (s *messageEditService) difundir(msg *mensajeEdicion, origen uuid.UUID) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		if peerID == origen {
+			continue
+		}
+		// Un vecino caído recibirá el cambio por anti-entropía
+		_ = s.transport.SendTo(peerID, FrameTipoEdicionMensaje, payload)
+	}
+}
+
+// notificar envía el estado del mensaje a los clientes de este nodo que pueden verlo
+func (s *messageEditService) notificar(m *model.MensajeServidor, edicion *model.EdicionMensaje) {
+	if s.clients == nil {
+		return
+	}
+	destinatarios, err := destinatariosMensaje(context.Background(), s.channels, m)
+	if err != nil || len(destinatarios) == 0 {
+		return
+	}
+
+	evento := EventoClienteMensajeEditado
+	registro := aRegistroMensaje(m)
+	if m.Eliminado() {
+		evento = EventoClienteMensajeEliminado
+		registro.Contenido = ""
+	}
+	frame, err := codificarEventoCliente(evento, cambioMensaje{Mensaje: registro, EditorID: edicion.EditorID()})
+	if err != nil {
+		return
+	}
+	// Los usuarios sin socket en este nodo verán el cambio en el historial
+	_ = s.clients.Broadcast(destinatarios, frame)
+}
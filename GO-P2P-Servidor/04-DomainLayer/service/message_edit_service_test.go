@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nuevoNodoEdicion crea un nodo simulado con su MessageEditService
+func nuevoNodoEdicion(t *testing.T, red *redFalsa) *nodoServicio[MessageEditService] {
+	t.Helper()
+	return nuevoNodoServicio(t, red, func(n *nodoFalso) MessageEditService {
+		return NewMessageEditService(n.transporte, n.clientes, n.canales, n.mensajes, unidadFalsa{}, NewConflictResolver(nil), n.reloj)
+	})
+}
+
+func TestMessageEdit_Permisos(t *testing.T) {
+	n := nuevoNodoEdicion(t, nuevaRedFalsa())
+	autorID, miembroID, adminID, canalID, mensajeID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	n.sembrarMensajeCanal(t, mensajeID, autorID, canalID, time.Now().Add(-time.Minute), map[uuid.UUID]string{
+		autorID: "member", miembroID: "member", adminID: "admin",
+	})
+
+	if _, err := n.servicio.EditMessage(miembroID, mensajeID, "ajeno"); err != ErrEdicionSinPermiso {
+		t.Errorf("edición de otro miembro: esperado %v, obtuvo %v", ErrEdicionSinPermiso, err)
+	}
+	if _, err := n.servicio.EditMessage(autorID, uuid.New(), "x"); err != ErrEdicionMensajeNoEncontrado {
+		t.Errorf("mensaje inexistente: esperado %v, obtuvo %v", ErrEdicionMensajeNoEncontrado, err)
+	}
+
+	m, err := n.servicio.EditMessage(autorID, mensajeID, "corregido")
+	if err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+	if m.Contenido() != "corregido" || !m.Editado() || m.Version().IsZero() {
+		t.Errorf("edición no aplicada: %q editado=%v versión=%v", m.Contenido(), m.Editado(), m.Version())
+	}
+
+	// Un administrador del canal puede eliminar mensajes de otros
+	if _, err := n.servicio.DeleteMessage(adminID, mensajeID); err != nil {
+		t.Fatalf("DeleteMessage: %v", err)
+	}
+	if _, err := n.servicio.EditMessage(autorID, mensajeID, "tarde"); err != model.ErrMensajeEliminado {
+		t.Errorf("editar eliminado: esperado %v, obtuvo %v", model.ErrMensajeEliminado, err)
+	}
+
+	historial, err := n.servicio.ListEdits(mensajeID)
+	if err != nil {
+		t.Fatalf("ListEdits: %v", err)
+	}
+	if len(historial) != 2 {
+		t.Fatalf("historial: esperadas 2 entradas, obtuvo %d", len(historial))
+	}
+	if historial[0].Tipo() != model.EdicionContenido || historial[0].ContenidoAnterior() != "original" || historial[0].EditorID() != autorID {
+		t.Errorf("primera entrada inesperada: %v %q %v", historial[0].Tipo(), historial[0].ContenidoAnterior(), historial[0].EditorID())
+	}
+	if historial[1].Tipo() != model.EdicionBorrado || historial[1].ContenidoAnterior() != "corregido" || historial[1].EditorID() != adminID {
+		t.Errorf("segunda entrada inesperada: %v %q %v", historial[1].Tipo(), historial[1].ContenidoAnterior(), historial[1].EditorID())
+	}
+
+	// En mensajes directos solo el autor puede modificarlos
+	directoID, destinoID := uuid.New(), uuid.New()
+	directo, _ := model.NewMensajeDirecto(directoID, autorID, destinoID, "hola", time.Now().Add(-time.Minute), uuid.Nil)
+	n.mensajes.Save(context.Background(), directo)
+	if _, err := n.servicio.DeleteMessage(destinoID, directoID); err != ErrEdicionSinPermiso {
+		t.Errorf("borrado por el destinatario: esperado %v, obtuvo %v", ErrEdicionSinPermiso, err)
+	}
+}
+
+func TestMessageEdit_ReplicacionYEventos(t *testing.T) {
+	red := nuevaRedFalsa()
+	a, b, c := nuevoNodoEdicion(t, red), nuevoNodoEdicion(t, red), nuevoNodoEdicion(t, red)
+	red.enlazar(a.id, b.id)
+	red.enlazar(b.id, c.id)
+
+	autorID, lectorID, canalID, mensajeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	enviado := time.Now().Add(-time.Minute).UTC()
+	for _, n := range []*nodoServicio[MessageEditService]{a, b, c} {
+		n.sembrarMensajeCanal(t, mensajeID, autorID, canalID, enviado, map[uuid.UUID]string{autorID: "owner", lectorID: "member"})
+	}
+
+	if _, err := a.servicio.EditMessage(autorID, mensajeID, "corregido"); err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+	// La edición llega a c pasando por b
+	esperarHasta(t, func() bool {
+		m, _ := c.mensajes.FindByID(context.Background(), mensajeID)
+		return m.Contenido() == "corregido" && m.Editado()
+	})
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[cambioMensaje](t, c.clientes, lectorID, EventoClienteMensajeEditado)) == 1
+	})
+	evento := eventosCliente[cambioMensaje](t, c.clientes, lectorID, EventoClienteMensajeEditado)[0]
+	if evento.Mensaje.Contenido != "corregido" || evento.EditorID != autorID {
+		t.Errorf("evento de edición inesperado: %+v", evento)
+	}
+
+	// El borrado hecho en c vuelve hasta a, sin contenido en el evento
+	if _, err := c.servicio.DeleteMessage(autorID, mensajeID); err != nil {
+		t.Fatalf("DeleteMessage: %v", err)
+	}
+	esperarHasta(t, func() bool {
+		m, _ := a.mensajes.FindByID(context.Background(), mensajeID)
+		return m.Eliminado()
+	})
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[cambioMensaje](t, a.clientes, lectorID, EventoClienteMensajeEliminado)) == 1
+	})
+	if evento := eventosCliente[cambioMensaje](t, a.clientes, lectorID, EventoClienteMensajeEliminado)[0]; evento.Mensaje.Contenido != "" {
+		t.Errorf("el evento de borrado no debe incluir el contenido, obtuvo %q", evento.Mensaje.Contenido)
+	}
+
+	// Todos los nodos tienen el historial completo y la inundación se detuvo
+	esperarHasta(t, func() bool {
+		for _, n := range []*nodoServicio[MessageEditService]{a, b, c} {
+			historial, _ := n.mensajes.FindEdits(context.Background(), mensajeID)
+			if len(historial) != 2 {
+				return false
+			}
+		}
+		return true
+	})
+	time.Sleep(20 * time.Millisecond)
+	if n := len(eventosCliente[cambioMensaje](t, b.clientes, lectorID, EventoClienteMensajeEditado)); n != 1 {
+		t.Errorf("b debe notificar la edición una sola vez, obtuvo %d", n)
+	}
+}
+
+func TestConflictResolver_ResolveMessage_Ediciones(t *testing.T) {
+	audit := &auditFalso{}
+	r := NewConflictResolver(audit)
+	id, autorID, canalID := uuid.New(), uuid.New(), uuid.New()
+	nodoA, nodoB := uuid.New(), uuid.New()
+	enviado := time.Now().Add(-time.Hour).UTC()
+
+	nuevo := func() *model.MensajeServidor {
+		m, _ := model.NewMensajeCanal(id, autorID, canalID, "original", enviado, uuid.Nil)
+		m.SetVersion(hlc(t, 100, nodoA))
+		return m
+	}
+
+	// El original frente a su edición no es un conflicto: gana la edición
+	original, editado := nuevo(), nuevo()
+	editado.Editar("corregido", enviado.Add(time.Minute))
+	editado.SetVersion(hlc(t, 200, nodoB))
+	versiones, err := r.ResolveMessage(original, editado)
+	if err != nil {
+		t.Fatalf("esperaba sin error, obtuvo %v", err)
+	}
+	if len(versiones) != 1 || versiones[0] != editado {
+		t.Errorf("esperaba solo la edición, obtuvo %d versiones", len(versiones))
+	}
+	if len(audit.eventos) != 0 {
+		t.Errorf("no esperaba conflictos registrados, obtuvo %v", audit.eventos)
+	}
+
+	// El borrado prevalece sobre una edición concurrente más reciente
+	eliminado := nuevo()
+	eliminado.Eliminar(enviado.Add(time.Minute))
+	eliminado.SetVersion(hlc(t, 150, nodoA))
+	for _, par := range [][2]*model.MensajeServidor{{editado, eliminado}, {eliminado, editado}} {
+		versiones, err := r.ResolveMessage(par[0], par[1])
+		if err != nil {
+			t.Fatalf("esperaba sin error, obtuvo %v", err)
+		}
+		if len(versiones) != 1 || versiones[0] != eliminado {
+			t.Errorf("el borrado debe prevalecer sobre la edición")
+		}
+	}
+	if len(audit.eventos) != 2 {
+		t.Errorf("esperaba 2 conflictos registrados, obtuvo %d", len(audit.eventos))
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de la búsqueda de mensajes
+var (
+	ErrBusquedaUsuarioNoEncontrado = errors.New("usuario no encontrado")
+)
+
+// MessageSearchService define la búsqueda de texto completo en el historial de mensajes
+type MessageSearchService interface {
+	// SearchMessages devuelve una página, ordenada por (timestamp, id), de los mensajes
+	// no eliminados que el usuario puede ver y contienen todos los términos de la
+	// consulta: los de los canales de los que es miembro, los de los chats privados en
+	// los que participa y sus mensajes directos. Los filtros restringen por remitente,
+	// canal, rango de fechas y adjunto. Una consulta sin términos válidos devuelve
+	// model.ErrBusquedaVacia.
+	SearchMessages(
+		userID uuid.UUID,
+		query string,
+		filtros model.FiltroBusqueda,
+		consulta model.ConsultaPagina,
+	) (*model.Pagina[*model.MensajeServidor], error)
+}
+
+// messageSearchService implementa MessageSearchService sobre IMessageRepository, que
+// aplica la visibilidad del usuario en la propia consulta
+type messageSearchService struct {
+	users    repository.IUserRepository
+	messages repository.IMessageRepository
+}
+
+// NewMessageSearchService crea el servicio de búsqueda de mensajes
+func NewMessageSearchService(users repository.IUserRepository, messages repository.IMessageRepository) MessageSearchService {
+	return &messageSearchService{users: users, messages: messages}
+}
+
+// SearchMessages implementa MessageSearchService
+func (s *messageSearchService) SearchMessages(
+	userID uuid.UUID,
+	query string,
+	filtros model.FiltroBusqueda,
+	consulta model.ConsultaPagina,
+) (*model.Pagina[*model.MensajeServidor], error) {
+	if len(model.TerminosBusqueda(query)) == 0 {
+		return nil, model.ErrBusquedaVacia
+	}
+	if err := filtros.Validar(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	usuario, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil {
+		return nil, ErrBusquedaUsuarioNoEncontrado
+	}
+	return s.messages.Search(ctx, userID, query, filtros, consulta)
+}
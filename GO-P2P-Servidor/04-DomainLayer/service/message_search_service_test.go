@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestMessageSearch_SearchMessages(t *testing.T) {
+	ctx := context.Background()
+	usuarios, mensajes := nuevosUsuariosFalsos(), nuevosMensajesFalsos()
+	s := NewMessageSearchService(usuarios, mensajes)
+
+	usuarioID, otroID, canalID := uuid.New(), uuid.New(), uuid.New()
+	ahora := time.Now().UTC()
+	u, _ := model.NewUsuarioServidor(usuarioID, "ana", "ana@x.com", "hash", "", "10.0.0.1", ahora)
+	usuarios.Save(ctx, u)
+
+	guardar := func(remitenteID uuid.UUID, contenido string, at time.Time) *model.MensajeServidor {
+		m, err := model.NewMensajeCanal(uuid.New(), remitenteID, canalID, contenido, at, uuid.Nil)
+		if err != nil {
+			t.Fatalf("NewMensajeCanal: %v", err)
+		}
+		mensajes.Save(ctx, m)
+		return m
+	}
+	viejo := guardar(otroID, "Reunión del equipo el lunes", ahora.Add(-2*time.Hour))
+	nuevo := guardar(usuarioID, "La reunion se mueve al martes", ahora.Add(-time.Hour))
+	guardar(usuarioID, "Nada que ver", ahora)
+
+	pagina, err := s.SearchMessages(usuarioID, "REUNIÓN", model.FiltroBusqueda{}, model.ConsultaPagina{})
+	if err != nil {
+		t.Fatalf("SearchMessages: %v", err)
+	}
+	if len(pagina.Elementos) != 2 || pagina.Elementos[0] != viejo || pagina.Elementos[1] != nuevo {
+		t.Errorf("esperaba los dos mensajes sobre la reunión en orden, obtuvo %d", len(pagina.Elementos))
+	}
+
+	pagina, err = s.SearchMessages(usuarioID, "reunion", model.FiltroBusqueda{RemitenteID: otroID}, model.ConsultaPagina{})
+	if err != nil || len(pagina.Elementos) != 1 || pagina.Elementos[0] != viejo {
+		t.Errorf("el filtro por remitente debe dejar solo el mensaje antiguo: %v", err)
+	}
+
+	casos := []struct {
+		usuarioID uuid.UUID
+		query     string
+		filtros   model.FiltroBusqueda
+		esperado  error
+	}{
+		{usuarioID, "a de", model.FiltroBusqueda{}, model.ErrBusquedaVacia},
+		{usuarioID, "reunion", model.FiltroBusqueda{Desde: ahora, Hasta: ahora.Add(-time.Hour)}, model.ErrRangoBusquedaInvalido},
+		{uuid.New(), "reunion", model.FiltroBusqueda{}, ErrBusquedaUsuarioNoEncontrado},
+	}
+	for _, c := range casos {
+		if _, err := s.SearchMessages(c.usuarioID, c.query, c.filtros, model.ConsultaPagina{}); err != c.esperado {
+			t.Errorf("SearchMessages(%q): esperado %v, obtuvo %v", c.query, c.esperado, err)
+		}
+	}
+}
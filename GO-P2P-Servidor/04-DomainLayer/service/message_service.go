@@ -1,10 +1,19 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"time"
 	
 	"github.com/google/uuid"
 	"model"
+	repository "repository.interfaces"
+)
+
+// Errores del envío de mensajes
+var (
+	ErrMensajeSinAcceso       = errors.New("el usuario no es miembro del canal")
+	ErrMensajeDestinoNoExiste = errors.New("el destinatario del mensaje no existe")
 )
 
 // MessageService define las operaciones para envío y recuperación de mensajes
@@ -23,15 +32,176 @@ type MessageService interface {
 		archivoID uuid.UUID,
 	) (*model.MensajeServidor, error)
 
-	// ListChannelMessages lista los mensajes de un canal en un rango de tiempo
+	// ListChannelMessages lista los mensajes de un canal en un rango de tiempo junto con
+	// el número de reacciones vigentes por emoji de cada mensaje, indexado por su ID
 	ListChannelMessages(
 		channelID uuid.UUID,
 		since, until time.Time,
-	) ([]*model.MensajeServidor, error)
+	) ([]*model.MensajeServidor, map[uuid.UUID][]model.ConteoReaccion, error)
 
-	// ListDirectMessages lista los mensajes directos entre dos usuarios en un rango de tiempo
+	// ListDirectMessages lista los mensajes directos entre dos usuarios en un rango de
+	// tiempo junto con sus reacciones, como ListChannelMessages
 	ListDirectMessages(
 		userA, userB uuid.UUID,
 		since, until time.Time,
-	) ([]*model.MensajeServidor, error)
+	) ([]*model.MensajeServidor, map[uuid.UUID][]model.ConteoReaccion, error)
+
+	// PageChannelMessages devuelve una página del historial de un canal. La consulta
+	// lleva el cursor opaco de una página ya recibida (Anterior o Siguiente) y la
+	// dirección en que se desplaza el cliente; sin cursor devuelve la página más reciente.
+	PageChannelMessages(
+		channelID uuid.UUID,
+		consulta model.ConsultaPagina,
+	) (*model.Pagina[*model.MensajeServidor], error)
+
+	// PageDirectMessages devuelve una página de la conversación directa entre dos usuarios
+	PageDirectMessages(
+		userA, userB uuid.UUID,
+		consulta model.ConsultaPagina,
+	) (*model.Pagina[*model.MensajeServidor], error)
+}
+
+// messageService implementa MessageService: guarda cada mensaje y lo entrega a sus
+// destinatarios con RoutingService, que también lo lleva a los de otros nodos
+type messageService struct {
+	users    repository.IUserRepository
+	channels repository.IChannelRepository
+	messages repository.IMessageRepository
+	routing  RoutingService
+}
+
+// NewMessageService crea el servicio de mensajes
+func NewMessageService(
+	users repository.IUserRepository,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	routing RoutingService,
+) MessageService {
+	return &messageService{users: users, channels: channels, messages: messages, routing: routing}
+}
+
+// SendDirect implementa MessageService
+func (s *messageService) SendDirect(remitenteID, destinoID uuid.UUID, contenido string, archivoID uuid.UUID) (*model.MensajeServidor, error) {
+	destino, err := s.users.FindByID(context.Background(), destinoID)
+	if err != nil {
+		return nil, err
+	}
+	if destino == nil {
+		return nil, ErrMensajeDestinoNoExiste
+	}
+	m, err := model.NewMensajeDirecto(uuid.New(), remitenteID, destinoID, contenido, time.Now().UTC(), archivoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.enviar(m)
+}
+
+// SendChannel implementa MessageService
+func (s *messageService) SendChannel(remitenteID, channelID uuid.UUID, contenido string, archivoID uuid.UUID) (*model.MensajeServidor, error) {
+	miembro, err := esMiembroCanal(context.Background(), s.channels, channelID, remitenteID)
+	if err != nil {
+		return nil, err
+	}
+	if !miembro {
+		return nil, ErrMensajeSinAcceso
+	}
+	m, err := model.NewMensajeCanal(uuid.New(), remitenteID, channelID, contenido, time.Now().UTC(), archivoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.enviar(m)
+}
+
+// enviar guarda el mensaje y lo enruta. Un fallo al enrutar no anula el mensaje
+// guardado: el enrutamiento reintenta por su cuenta las entregas pendientes.
+func (s *messageService) enviar(m *model.MensajeServidor) (*model.MensajeServidor, error) {
+	if err := s.messages.Save(context.Background(), m); err != nil {
+		return nil, err
+	}
+	_ = s.routing.RouteMessage(m)
+	return m, nil
+}
+
+// ListChannelMessages implementa MessageService
+func (s *messageService) ListChannelMessages(
+	channelID uuid.UUID,
+	since, until time.Time,
+) ([]*model.MensajeServidor, map[uuid.UUID][]model.ConteoReaccion, error) {
+	return s.listar(since, until, func(ctx context.Context, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+		return s.messages.FindByChannel(ctx, channelID, consulta)
+	})
+}
+
+// ListDirectMessages implementa MessageService
+func (s *messageService) ListDirectMessages(
+	userA, userB uuid.UUID,
+	since, until time.Time,
+) ([]*model.MensajeServidor, map[uuid.UUID][]model.ConteoReaccion, error) {
+	return s.listar(since, until, func(ctx context.Context, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error) {
+		return s.messages.FindDirect(ctx, userA, userB, consulta)
+	})
+}
+
+// listar recorre hacia delante las páginas de la conversación desde since hasta llegar
+// a until, excluido, y devuelve los mensajes del rango con sus reacciones
+func (s *messageService) listar(
+	since, until time.Time,
+	pagina func(context.Context, model.ConsultaPagina) (*model.Pagina[*model.MensajeServidor], error),
+) ([]*model.MensajeServidor, map[uuid.UUID][]model.ConteoReaccion, error) {
+	ctx := context.Background()
+	var mensajes []*model.MensajeServidor
+	var ids []uuid.UUID
+	// El cursor es la última fila posible antes de since; sin since se empieza por el
+	// mensaje más antiguo
+	cursor := ""
+	if !since.IsZero() {
+		cursor = model.NewCursor(since.Add(-time.Nanosecond), uuid.Max).String()
+	}
+	for {
+		consulta, err := model.NewConsultaPagina(cursor, model.PaginaPosteriores, model.LimitePaginaMaximo)
+		if err != nil {
+			return nil, nil, err
+		}
+		p, err := pagina(ctx, consulta)
+		if err != nil {
+			return nil, nil, err
+		}
+		fin := !p.HayPosteriores
+		for _, m := range p.Elementos {
+			if !m.Timestamp().Before(until) {
+				fin = true
+				break
+			}
+			mensajes = append(mensajes, m)
+			ids = append(ids, m.ID())
+		}
+		if fin {
+			break
+		}
+		cursor = p.Siguiente
+	}
+	if len(ids) == 0 {
+		return mensajes, map[uuid.UUID][]model.ConteoReaccion{}, nil
+	}
+	reacciones, err := s.messages.CountReactions(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mensajes, reacciones, nil
+}
+
+// PageChannelMessages implementa MessageService
+func (s *messageService) PageChannelMessages(
+	channelID uuid.UUID,
+	consulta model.ConsultaPagina,
+) (*model.Pagina[*model.MensajeServidor], error) {
+	return s.messages.FindByChannel(context.Background(), channelID, consulta)
+}
+
+// PageDirectMessages implementa MessageService
+func (s *messageService) PageDirectMessages(
+	userA, userB uuid.UUID,
+	consulta model.ConsultaPagina,
+) (*model.Pagina[*model.MensajeServidor], error) {
+	return s.messages.FindDirect(context.Background(), userA, userB, consulta)
 }
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// TestMessageService_EnviaYEnruta comprueba que los mensajes enviados se guardan en el
+// nodo de origen y llegan a los destinatarios conectados a otro nodo
+func TestMessageService_EnviaYEnruta(t *testing.T) {
+	ctx := context.Background()
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	a := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+	b := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+
+	ana, bob, eva := uuid.New(), uuid.New(), uuid.New()
+	usuarios := nuevosUsuariosFalsos()
+	for _, id := range []uuid.UUID{ana, bob} {
+		u, _ := model.NewUsuarioServidor(id, "u"+id.String()[:8], id.String()[:8]+"@prueba.com", "hash", "", "127.0.0.1", time.Now())
+		usuarios.Save(ctx, u)
+	}
+	canalID := uuid.New()
+	canales.AddMember(ctx, canalID, ana, "member")
+	canales.AddMember(ctx, canalID, bob, "member")
+	conectar(t, b, bob, a)
+
+	s := NewMessageService(usuarios, canales, a.mensajes, a.servicio)
+
+	directo, err := s.SendDirect(ana, bob, "hola bob", uuid.Nil)
+	if err != nil {
+		t.Fatalf("SendDirect: %v", err)
+	}
+	if guardado, _ := a.mensajes.FindByID(ctx, directo.ID()); guardado == nil {
+		t.Error("el mensaje directo debe guardarse en el nodo de origen")
+	}
+	esperarHasta(t, func() bool { return b.clientes.recibidos(bob) == 1 })
+
+	if _, err := s.SendChannel(ana, canalID, "hola canal", uuid.Nil); err != nil {
+		t.Fatalf("SendChannel: %v", err)
+	}
+	esperarHasta(t, func() bool { return b.clientes.recibidos(bob) == 2 })
+
+	if _, err := s.SendDirect(ana, eva, "¿existes?", uuid.Nil); !errors.Is(err, ErrMensajeDestinoNoExiste) {
+		t.Errorf("destinatario inexistente: esperado %v, obtuvo %v", ErrMensajeDestinoNoExiste, err)
+	}
+	if _, err := s.SendChannel(eva, canalID, "intrusa", uuid.Nil); !errors.Is(err, ErrMensajeSinAcceso) {
+		t.Errorf("remitente ajeno al canal: esperado %v, obtuvo %v", ErrMensajeSinAcceso, err)
+	}
+
+	directos, _, err := s.ListDirectMessages(bob, ana, time.Now().Add(-time.Minute), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("ListDirectMessages: %v", err)
+	}
+	if len(directos) != 1 || directos[0].ID() != directo.ID() {
+		t.Errorf("la conversación directa solo debe contener el mensaje directo: %v", directos)
+	}
+}
+
+// TestMessageService_ListaRango comprueba que el listado de un canal recorre varias
+// páginas y respeta el rango [since, until)
+func TestMessageService_ListaRango(t *testing.T) {
+	ctx := context.Background()
+	mensajes := nuevosMensajesFalsos()
+	s := NewMessageService(nuevosUsuariosFalsos(), nuevosCanalesFalsos(), mensajes, nil)
+
+	canalID, otroCanal, ana := uuid.New(), uuid.New(), uuid.New()
+	base := time.Now().UTC().Truncate(time.Second)
+	for i := 0; i < model.LimitePaginaMaximo+100; i++ {
+		m, _ := model.NewMensajeCanal(uuid.New(), ana, canalID, "hola", base.Add(time.Duration(i)*time.Second), uuid.Nil)
+		mensajes.Save(ctx, m)
+		otro, _ := model.NewMensajeCanal(uuid.New(), ana, otroCanal, "hola", base.Add(time.Duration(i)*time.Second), uuid.Nil)
+		mensajes.Save(ctx, otro)
+	}
+
+	desde, hasta := base.Add(10*time.Second), base.Add(time.Duration(model.LimitePaginaMaximo+60)*time.Second)
+	lista, reacciones, err := s.ListChannelMessages(canalID, desde, hasta)
+	if err != nil {
+		t.Fatalf("ListChannelMessages: %v", err)
+	}
+	if len(lista) != model.LimitePaginaMaximo+50 {
+		t.Fatalf("esperados %d mensajes, obtuvo %d", model.LimitePaginaMaximo+50, len(lista))
+	}
+	if !lista[0].Timestamp().Equal(desde) || !lista[len(lista)-1].Timestamp().Before(hasta) {
+		t.Errorf("rango inesperado: %v..%v", lista[0].Timestamp(), lista[len(lista)-1].Timestamp())
+	}
+	for i, m := range lista {
+		if m.CanalID() != canalID {
+			t.Fatalf("mensaje de otro canal en la posición %d", i)
+		}
+		if i > 0 && m.Timestamp().Before(lista[i-1].Timestamp()) {
+			t.Fatalf("mensajes desordenados en la posición %d", i)
+		}
+	}
+	if reacciones == nil {
+		t.Error("las reacciones deben devolverse aunque no haya ninguna")
+	}
+}
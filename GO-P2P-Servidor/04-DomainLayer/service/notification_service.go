@@ -1,10 +1,18 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
 	"model"
+	repository "repository.interfaces"
 )
 
+// ErrNotificacionNoEncontrada se devuelve al marcar una notificación que no existe
+var ErrNotificacionNoEncontrada = errors.New("notificación no encontrada")
+
 // NotificationService define las operaciones para creación y gestión de notificaciones
 type NotificationService interface {
 	// Notify envía una notificación a un usuario
@@ -15,7 +23,71 @@ type NotificationService interface {
 
 	// List obtiene todas las notificaciones de un usuario
 	List(userID uuid.UUID) ([]*model.Notificacion, error)
+
+	// ListPage obtiene una página de las notificaciones de un usuario a partir del
+	// cursor opaco de la consulta
+	ListPage(userID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.Notificacion], error)
 	
 	// MarkRead marca una notificación como leída
 	MarkRead(notificationID uuid.UUID) error
 }
+
+// avisoNotificacion son los datos del evento EventoClienteNotificacion
+type avisoNotificacion struct {
+	ID        uuid.UUID `json:"id"`
+	Contenido string    `json:"contenido"`
+	Fecha     time.Time `json:"fecha"`
+}
+
+// notificationService implementa NotificationService: guarda las notificaciones y avisa
+// al usuario con EventoClienteNotificacion
+type notificationService struct {
+	clients       ClientTransport
+	notifications repository.INotificationRepository
+}
+
+// NewNotificationService crea el servicio de notificaciones
+func NewNotificationService(clients ClientTransport, notifications repository.INotificationRepository) NotificationService {
+	return &notificationService{clients: clients, notifications: notifications}
+}
+
+// Notify implementa NotificationService. Un fallo al avisar no anula la notificación
+// guardada, que el usuario verá al listar las suyas.
+func (s *notificationService) Notify(userID uuid.UUID, contenido string) (*model.Notificacion, error) {
+	n, err := model.NewNotificacion(uuid.New(), userID, contenido, time.Now().UTC(), uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.notifications.Save(context.Background(), n); err != nil {
+		return nil, err
+	}
+	if frame, err := codificarEventoCliente(EventoClienteNotificacion, avisoNotificacion{
+		ID: n.ID(), Contenido: n.Contenido(), Fecha: n.Fecha(),
+	}); err == nil {
+		_ = s.clients.Broadcast([]uuid.UUID{userID}, frame)
+	}
+	return n, nil
+}
+
+// List implementa NotificationService
+func (s *notificationService) List(userID uuid.UUID) ([]*model.Notificacion, error) {
+	return s.notifications.FindByUser(context.Background(), userID)
+}
+
+// ListPage implementa NotificationService
+func (s *notificationService) ListPage(userID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.Notificacion], error) {
+	return s.notifications.FindPageByUser(context.Background(), userID, consulta)
+}
+
+// MarkRead implementa NotificationService
+func (s *notificationService) MarkRead(notificationID uuid.UUID) error {
+	ctx := context.Background()
+	n, err := s.notifications.FindByID(ctx, notificationID)
+	if err != nil {
+		return err
+	}
+	if n == nil {
+		return ErrNotificacionNoEncontrada
+	}
+	return s.notifications.MarkRead(ctx, notificationID)
+}
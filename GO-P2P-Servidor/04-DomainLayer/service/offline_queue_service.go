@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de la cola de eventos pendientes
+var (
+	ErrColaFrameInvalido     = errors.New("frame de evento de cliente inválido")
+	ErrColaTraspasoTimeout   = errors.New("el peer no respondió a la petición de cola a tiempo")
+	ErrColaTraspasoRechazado = errors.New("el peer rechazó la petición de cola")
+)
+
+// maxEventosPendientes es el número máximo de eventos que se guardan para un usuario;
+// al superarlo se descartan los más antiguos, que el cliente recuperará del historial
+const maxEventosPendientes = 10000
+
+// loteTraspasoCola es el número de eventos que un peer envía en cada respuesta al
+// traspasar una cola, para no superar el tamaño máximo de frame
+const loteTraspasoCola = 100
+
+// esperaTraspasoCola es la espera máxima por cada respuesta de un peer al traspasar una cola
+const esperaTraspasoCola = 5 * time.Second
+
+// Tipos de mensaje del traspaso de colas
+const (
+	colaPedir   = "PEDIR"   // nodo de entrada → peer: confirma el lote anterior y pide el siguiente
+	colaEventos = "EVENTOS" // peer → nodo de entrada: lote más antiguo de la cola del usuario
+	colaError   = "ERROR"   // peer → nodo de entrada: fallo al leer o confirmar la cola
+)
+
+// eventosEfimeros son los eventos de cliente que solo tienen sentido en el momento en
+// que ocurren y no se guardan para los usuarios desconectados
+var eventosEfimeros = map[string]bool{
+	EventoClientePresencia: true,
+	EventoClienteActividad: true,
+}
+
+// OfflineQueueService entrega los eventos de cliente a los usuarios conectados y guarda
+// los de los desconectados en una cola por usuario. Envuelve el ClientTransport de este
+// nodo, así que los servicios que avisan a los clientes lo usan en su lugar sin cambios.
+// Un usuario está desconectado si no tiene socket en este nodo ni está ubicado en otro;
+// si lo está, es el servicio de ese nodo el que le entrega el evento. Al volver a
+// entrar, el cliente recorre su cola con Replay y confirma con Acknowledge lo recibido.
+// Cada nodo encola lo que no pudo entregar: los eventos que se replican a todos los
+// nodos se encolan en cada uno, pero un mensaje enrutado solo en el de su origen. Por
+// eso, al empezar a recorrer la cola, el nodo de entrada trae las colas del usuario de
+// los demás nodos, descarta las copias de los eventos replicados y solo entonces pide a
+// cada peer que borre lo traspasado.
+type OfflineQueueService interface {
+	ClientTransport
+
+	// SetPresence indica el PresenceService con el que se ubican los usuarios conectados
+	// a otros nodos. Se asigna tras crear ambos servicios, ya que la presencia avisa a los
+	// clientes a través de esta cola; sin él, todo usuario sin socket en este nodo se encola.
+	SetPresence(presence PresenceService)
+
+	// Enqueue guarda el frame en la cola de los usuarios indicados aunque estén ubicados
+	// en otro nodo. Lo usa quien no pudo hacerles llegar un evento, como el enrutamiento
+	// al agotar los reintentos hacia un nodo inalcanzable.
+	Enqueue(ids []uuid.UUID, frame []byte) error
+
+	// Replay devuelve la página de la cola del usuario posterior al cursor de la
+	// consulta, en el orden en que se encolaron los eventos. Sin cursor empieza por el
+	// evento más antiguo aún no confirmado, tras traer a este nodo lo que el usuario
+	// tenga encolado en los demás; un peer que no responde conserva su parte para la
+	// siguiente vez.
+	Replay(userID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.EventoPendiente], error)
+
+	// Acknowledge confirma la recepción de los eventos de la cola hasta la posición
+	// indicada, incluida, y los borra. Devuelve cuántos se borraron.
+	Acknowledge(userID uuid.UUID, position model.Cursor) (int64, error)
+
+	// Pending devuelve cuántos eventos tiene la cola del usuario en este nodo
+	Pending(userID uuid.UUID) (int, error)
+}
+
+// mensajeCola es el payload JSON de los frames FrameTipoColaPendiente
+type mensajeCola struct {
+	Tipo      string                    `json:"tipo"`
+	Sesion    uuid.UUID                 `json:"sesion"`
+	UsuarioID uuid.UUID                 `json:"usuarioId"`
+	Confirmar string                    `json:"confirmar,omitempty"` // Cursor del último evento traspasado
+	Eventos   []registroEventoPendiente `json:"eventos,omitempty"`
+	Error     string                    `json:"error,omitempty"`
+}
+
+// registroEventoPendiente es la forma serializada de un evento encolado; conserva id y
+// fecha para que la cola traspasada mantenga su orden
+type registroEventoPendiente struct {
+	ID     uuid.UUID       `json:"id"`
+	Evento string          `json:"evento"`
+	Datos  json.RawMessage `json:"datos"`
+	Fecha  time.Time       `json:"fecha"`
+}
+
+// offlineQueueService implementa OfflineQueueService sobre el ClientTransport y las
+// sesiones de este nodo
+type offlineQueueService struct {
+	nodoID    uuid.UUID
+	transport PeerTransport
+	clients   ClientTransport
+	sessions  ClientSessions
+	queue     repository.IOfflineQueueRepository
+	presence  PresenceService
+	espera    time.Duration
+
+	mu         sync.Mutex
+	pendientes map[uuid.UUID]chan *mensajeCola // sesión → respuesta esperada
+}
+
+// NewOfflineQueueService crea la cola de eventos pendientes alrededor de clients.
+// sessions indica qué usuarios tienen socket en este nodo; transport lleva las colas
+// de un nodo a otro cuando el usuario entra en un nodo distinto.
+func NewOfflineQueueService(
+	nodoID uuid.UUID,
+	transport PeerTransport,
+	clients ClientTransport,
+	sessions ClientSessions,
+	queue repository.IOfflineQueueRepository,
+) OfflineQueueService {
+	s := &offlineQueueService{
+		nodoID:     nodoID,
+		transport:  transport,
+		clients:    clients,
+		sessions:   sessions,
+		queue:      queue,
+		espera:     esperaTraspasoCola,
+		pendientes: make(map[uuid.UUID]chan *mensajeCola),
+	}
+	transport.SetFrameHandler(FrameTipoColaPendiente, s.handleFrame)
+	return s
+}
+
+// SetPresence implementa OfflineQueueService
+func (s *offlineQueueService) SetPresence(presence PresenceService) {
+	s.presence = presence
+}
+
+// Broadcast implementa ClientTransport: envía el frame a los usuarios conectados a este
+// nodo y lo encola para los que no están conectados en ninguno
+func (s *offlineQueueService) Broadcast(ids []uuid.UUID, frame []byte) error {
+	var conectados, desconectados []uuid.UUID
+	for _, id := range ids {
+		switch {
+		case s.sessions.Connected(id):
+			conectados = append(conectados, id)
+		case !s.ubicadoEnOtroNodo(id):
+			desconectados = append(desconectados, id)
+		}
+	}
+
+	var errs []error
+	if len(conectados) > 0 {
+		errs = append(errs, s.clients.Broadcast(conectados, frame))
+	}
+	if len(desconectados) > 0 {
+		errs = append(errs, s.encolar(desconectados, frame))
+	}
+	return errors.Join(errs...)
+}
+
+// Enqueue implementa OfflineQueueService
+func (s *offlineQueueService) Enqueue(ids []uuid.UUID, frame []byte) error {
+	return s.encolar(ids, frame)
+}
+
+// Replay implementa OfflineQueueService
+func (s *offlineQueueService) Replay(userID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.EventoPendiente], error) {
+	if userID == uuid.Nil {
+		return nil, model.ErrEventoPendienteUsuarioIDNil
+	}
+	if consulta.Cursor().IsZero() {
+		s.traspasar(userID)
+	}
+	// La cola solo se recorre hacia delante
+	if consulta.Direccion() != model.PaginaPosteriores {
+		var err error
+		consulta, err = model.NewConsultaPagina(consulta.Cursor().String(), model.PaginaPosteriores, consulta.Limite())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.queue.FindPending(context.Background(), userID, consulta)
+}
+
+// Acknowledge implementa OfflineQueueService
+func (s *offlineQueueService) Acknowledge(userID uuid.UUID, position model.Cursor) (int64, error) {
+	if userID == uuid.Nil {
+		return 0, model.ErrEventoPendienteUsuarioIDNil
+	}
+	if position.IsZero() {
+		return 0, model.ErrCursorInvalido
+	}
+	return s.queue.AcknowledgeUpTo(context.Background(), userID, position)
+}
+
+// Pending implementa OfflineQueueService
+func (s *offlineQueueService) Pending(userID uuid.UUID) (int, error) {
+	if userID == uuid.Nil {
+		return 0, model.ErrEventoPendienteUsuarioIDNil
+	}
+	return s.queue.CountPending(context.Background(), userID)
+}
+
+// ubicadoEnOtroNodo indica si el usuario está conectado a otro nodo según el directorio
+// de presencia
+func (s *offlineQueueService) ubicadoEnOtroNodo(userID uuid.UUID) bool {
+	if s.presence == nil {
+		return false
+	}
+	for _, nodo := range s.presence.LocateUser(userID) {
+		if nodo != s.nodoID {
+			return true
+		}
+	}
+	return false
+}
+
+// encolar guarda el evento del frame en la cola de cada usuario y descarta lo que
+// exceda maxEventosPendientes. Los eventos efímeros no se guardan.
+func (s *offlineQueueService) encolar(ids []uuid.UUID, frame []byte) error {
+	var evento struct {
+		Evento string          `json:"evento"`
+		Datos  json.RawMessage `json:"datos"`
+	}
+	if err := json.Unmarshal(frame, &evento); err != nil || evento.Evento == "" {
+		return ErrColaFrameInvalido
+	}
+	if eventosEfimeros[evento.Evento] {
+		return nil
+	}
+
+	ctx := context.Background()
+	ahora := time.Now().UTC()
+	var errs []error
+	for _, id := range ids {
+		// El id v7 ordena los eventos encolados en el mismo instante
+		eventoID, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		pendiente, err := model.NewEventoPendiente(eventoID, id, evento.Evento, evento.Datos, ahora)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.queue.Enqueue(ctx, pendiente); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := s.queue.Trim(ctx, id, maxEventosPendientes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// traspasar trae a este nodo los eventos del usuario encolados en los demás. Cada peer
+// entrega su cola por lotes, del más antiguo al más reciente, y borra cada lote cuando
+// este nodo lo ha guardado y pide el siguiente. Los eventos replicados a todos los nodos
+// llegan encolados en varios: de cada contenido se conservan tantas copias como tenga
+// el nodo que más tenga. Un peer que falla conserva lo que no se llegó a confirmar.
+func (s *offlineQueueService) traspasar(userID uuid.UUID) {
+	peers := s.transport.GetAllPeerIDs()
+	if len(peers) == 0 {
+		return
+	}
+	ctx := context.Background()
+	locales, err := s.todosPendientes(ctx, userID)
+	if err != nil {
+		return
+	}
+	ids := make(map[uuid.UUID]bool, len(locales))
+	presentes := make(map[string]int)
+	for _, e := range locales {
+		ids[e.ID()] = true
+		presentes[claveContenido(e.Evento(), e.Datos())]++
+	}
+
+	traspasados := false
+	for _, peerID := range peers {
+		vistos := make(map[string]int)
+		confirmar := ""
+		for {
+			lote, err := s.pedirLote(peerID, userID, confirmar)
+			if err != nil || len(lote) == 0 {
+				break
+			}
+			if err := s.fusionar(ctx, userID, lote, ids, presentes, vistos); err != nil {
+				break
+			}
+			traspasados = true
+			ultimo := lote[len(lote)-1]
+			confirmar = model.NewCursor(ultimo.Fecha, ultimo.ID).String()
+		}
+	}
+	if traspasados {
+		_, _ = s.queue.Trim(ctx, userID, maxEventosPendientes)
+	}
+}
+
+// fusionar guarda en la cola local los eventos de un lote de un peer que no estén ya en
+// ella. vistos cuenta las copias de cada contenido recibidas de ese peer.
+func (s *offlineQueueService) fusionar(
+	ctx context.Context,
+	userID uuid.UUID,
+	lote []registroEventoPendiente,
+	ids map[uuid.UUID]bool,
+	presentes, vistos map[string]int,
+) error {
+	for _, r := range lote {
+		clave := claveContenido(r.Evento, r.Datos)
+		vistos[clave]++
+		if ids[r.ID] || vistos[clave] <= presentes[clave] {
+			continue
+		}
+		e, err := model.NewEventoPendiente(r.ID, userID, r.Evento, r.Datos, r.Fecha)
+		if err != nil {
+			continue
+		}
+		if err := s.queue.Enqueue(ctx, e); err != nil {
+			return err
+		}
+		ids[r.ID] = true
+		presentes[clave]++
+	}
+	return nil
+}
+
+// pedirLote confirma al peer el lote anterior, si lo hay, y espera el siguiente
+func (s *offlineQueueService) pedirLote(peerID, userID uuid.UUID, confirmar string) ([]registroEventoPendiente, error) {
+	sesion := uuid.New()
+	respuestas := make(chan *mensajeCola, 1)
+	s.mu.Lock()
+	s.pendientes[sesion] = respuestas
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pendientes, sesion)
+		s.mu.Unlock()
+	}()
+
+	err := s.enviar(peerID, &mensajeCola{Tipo: colaPedir, Sesion: sesion, UsuarioID: userID, Confirmar: confirmar})
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(s.espera)
+	defer timer.Stop()
+
+	select {
+	case resp := <-respuestas:
+		if resp.Tipo != colaEventos {
+			return nil, ErrColaTraspasoRechazado
+		}
+		return resp.Eventos, nil
+	case <-timer.C:
+		return nil, ErrColaTraspasoTimeout
+	}
+}
+
+// handleFrame procesa los frames de traspaso de colas recibidos de un peer
+func (s *offlineQueueService) handleFrame(peerID uuid.UUID, payload []byte) {
+	var msg mensajeCola
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return
+	}
+
+	switch msg.Tipo {
+	case colaEventos, colaError:
+		// Respuesta a una petición de este nodo
+		s.mu.Lock()
+		respuestas, ok := s.pendientes[msg.Sesion]
+		s.mu.Unlock()
+		if ok {
+			select {
+			case respuestas <- &msg:
+			default:
+			}
+		}
+	case colaPedir:
+		// Petición de un peer: se atiende fuera del bucle de lectura del transporte
+		go s.responder(peerID, &msg)
+	}
+}
+
+// responder borra lo que el peer confirma haber guardado y le envía el siguiente lote
+func (s *offlineQueueService) responder(peerID uuid.UUID, msg *mensajeCola) {
+	resp, err := s.siguienteLote(msg)
+	if err != nil {
+		resp = &mensajeCola{Tipo: colaError, Sesion: msg.Sesion, UsuarioID: msg.UsuarioID, Error: err.Error()}
+	}
+	_ = s.enviar(peerID, resp)
+}
+
+// siguienteLote atiende una petición de traspaso de cola
+func (s *offlineQueueService) siguienteLote(msg *mensajeCola) (*mensajeCola, error) {
+	ctx := context.Background()
+	if msg.Confirmar != "" {
+		hasta, err := model.ParseCursor(msg.Confirmar)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.Acknowledge(msg.UsuarioID, hasta); err != nil {
+			return nil, err
+		}
+	}
+
+	consulta, err := model.NewConsultaPagina("", model.PaginaPosteriores, loteTraspasoCola)
+	if err != nil {
+		return nil, err
+	}
+	pagina, err := s.queue.FindPending(ctx, msg.UsuarioID, consulta)
+	if err != nil {
+		return nil, err
+	}
+	resp := &mensajeCola{Tipo: colaEventos, Sesion: msg.Sesion, UsuarioID: msg.UsuarioID}
+	for _, e := range pagina.Elementos {
+		resp.Eventos = append(resp.Eventos, registroEventoPendiente{
+			ID:     e.ID(),
+			Evento: e.Evento(),
+			Datos:  e.Datos(),
+			Fecha:  e.Fecha(),
+		})
+	}
+	return resp, nil
+}
+
+// enviar serializa y envía un mensaje de traspaso de cola a un peer
+func (s *offlineQueueService) enviar(peerID uuid.UUID, msg *mensajeCola) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.transport.SendTo(peerID, FrameTipoColaPendiente, payload)
+}
+
+// todosPendientes lee la cola completa del usuario en este nodo
+func (s *offlineQueueService) todosPendientes(ctx context.Context, userID uuid.UUID) ([]*model.EventoPendiente, error) {
+	var eventos []*model.EventoPendiente
+	cursor := ""
+	for {
+		consulta, err := model.NewConsultaPagina(cursor, model.PaginaPosteriores, model.LimitePaginaMaximo)
+		if err != nil {
+			return nil, err
+		}
+		pagina, err := s.queue.FindPending(ctx, userID, consulta)
+		if err != nil {
+			return nil, err
+		}
+		eventos = append(eventos, pagina.Elementos...)
+		if !pagina.HayPosteriores {
+			return eventos, nil
+		}
+		cursor = pagina.Siguiente
+	}
+}
+
+// claveContenido identifica el contenido de un evento encolado, igual en todos los
+// nodos que encolaron el mismo evento replicado
+func claveContenido(evento string, datos []byte) string {
+	return evento + "\x00" + string(datos)
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// frameEvento codifica un evento de cliente con un número para reconocerlo
+func frameEvento(t *testing.T, evento string, n int) []byte {
+	t.Helper()
+	frame, err := codificarEventoCliente(evento, map[string]int{"n": n})
+	if err != nil {
+		t.Fatalf("codificarEventoCliente: %v", err)
+	}
+	return frame
+}
+
+// numerosEventos devuelve el número de cada evento de la página, en orden
+func numerosEventos(t *testing.T, pagina *model.Pagina[*model.EventoPendiente]) []int {
+	t.Helper()
+	var numeros []int
+	for _, e := range pagina.Elementos {
+		var datos struct {
+			N int `json:"n"`
+		}
+		if err := json.Unmarshal(e.Datos(), &datos); err != nil {
+			t.Fatalf("datos inválidos: %v", err)
+		}
+		numeros = append(numeros, datos.N)
+	}
+	return numeros
+}
+
+func TestOfflineQueue_EncolaYRecupera(t *testing.T) {
+	ana, bob := uuid.New(), uuid.New()
+	clientes, sesiones, cola := nuevosClientesFalsos(), nuevasSesionesFalsas(ana), nuevaColaFalsa()
+	id := uuid.New()
+	s := NewOfflineQueueService(id, nuevaRedFalsa().nodo(id), clientes, sesiones, cola)
+
+	for i := 1; i <= 3; i++ {
+		if err := s.Broadcast([]uuid.UUID{ana, bob}, frameEvento(t, EventoClienteMensaje, i)); err != nil {
+			t.Fatalf("Broadcast: %v", err)
+		}
+	}
+	// La presencia es efímera: no se guarda para los desconectados
+	if err := s.Broadcast([]uuid.UUID{ana, bob}, frameEvento(t, EventoClientePresencia, 4)); err != nil {
+		t.Fatalf("Broadcast de presencia: %v", err)
+	}
+	if clientes.recibidos(ana) != 4 || clientes.recibidos(bob) != 0 {
+		t.Errorf("solo el conectado recibe los frames: ana=%d bob=%d", clientes.recibidos(ana), clientes.recibidos(bob))
+	}
+	if pendientes, _ := s.Pending(ana); pendientes != 0 {
+		t.Errorf("el usuario conectado no debe tener cola, tiene %d", pendientes)
+	}
+	if pendientes, _ := s.Pending(bob); pendientes != 3 {
+		t.Fatalf("esperados 3 eventos pendientes, hay %d", pendientes)
+	}
+
+	// Una consulta hacia atrás se recorre igualmente desde el más antiguo
+	consulta, _ := model.NewConsultaPagina("", model.PaginaAnteriores, 2)
+	pagina, err := s.Replay(bob, consulta)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if got := numerosEventos(t, pagina); len(got) != 2 || got[0] != 1 || got[1] != 2 || !pagina.HayPosteriores {
+		t.Fatalf("primera página inesperada: %v hayPosteriores=%v", got, pagina.HayPosteriores)
+	}
+	if pagina.Elementos[0].Evento() != EventoClienteMensaje {
+		t.Errorf("evento inesperado: %s", pagina.Elementos[0].Evento())
+	}
+
+	posicion, _ := model.ParseCursor(pagina.Siguiente)
+	if borrados, err := s.Acknowledge(bob, posicion); err != nil || borrados != 2 {
+		t.Fatalf("Acknowledge: borrados=%d err=%v", borrados, err)
+	}
+	pagina, err = s.Replay(bob, model.ConsultaPagina{})
+	if err != nil {
+		t.Fatalf("Replay tras confirmar: %v", err)
+	}
+	if got := numerosEventos(t, pagina); len(got) != 1 || got[0] != 3 || pagina.HayPosteriores {
+		t.Errorf("tras confirmar solo debe quedar el tercero: %v", got)
+	}
+
+	// Al conectarse recibe los eventos nuevos en directo
+	sesiones.conectar(bob, true)
+	if err := s.Broadcast([]uuid.UUID{bob}, frameEvento(t, EventoClienteMensaje, 5)); err != nil {
+		t.Fatalf("Broadcast: %v", err)
+	}
+	if pendientes, _ := s.Pending(bob); clientes.recibidos(bob) != 1 || pendientes != 1 {
+		t.Errorf("el evento nuevo debe entregarse sin encolarse: recibidos=%d pendientes=%d", clientes.recibidos(bob), pendientes)
+	}
+
+	if _, err := s.Acknowledge(bob, model.Cursor{}); err != model.ErrCursorInvalido {
+		t.Errorf("confirmación sin posición: esperado %v, obtuvo %v", model.ErrCursorInvalido, err)
+	}
+	if err := s.Broadcast([]uuid.UUID{bob, uuid.New()}, []byte("no es json\n")); !errors.Is(err, ErrColaFrameInvalido) {
+		t.Errorf("frame inválido: esperado %v, obtuvo %v", ErrColaFrameInvalido, err)
+	}
+}
+
+func TestOfflineQueue_RecortaLosMasAntiguos(t *testing.T) {
+	bob := uuid.New()
+	cola := nuevaColaFalsa()
+	id := uuid.New()
+	s := NewOfflineQueueService(id, nuevaRedFalsa().nodo(id), nuevosClientesFalsos(), nuevasSesionesFalsas(), cola)
+
+	for i := 1; i <= maxEventosPendientes+2; i++ {
+		if err := s.Broadcast([]uuid.UUID{bob}, frameEvento(t, EventoClienteMensaje, i)); err != nil {
+			t.Fatalf("Broadcast: %v", err)
+		}
+	}
+	if pendientes, _ := s.Pending(bob); pendientes != maxEventosPendientes {
+		t.Fatalf("esperados %d eventos pendientes, hay %d", maxEventosPendientes, pendientes)
+	}
+	consulta, _ := model.NewConsultaPagina("", model.PaginaPosteriores, 1)
+	pagina, _ := s.Replay(bob, consulta)
+	if got := numerosEventos(t, pagina); len(got) != 1 || got[0] != 3 {
+		t.Errorf("deben descartarse los dos más antiguos, el primero es %v", got)
+	}
+}
+
+// TestOfflineQueue_Ruteo comprueba que un mensaje a un usuario conectado a otro nodo se
+// entrega allí sin encolarse y que, cuando no está conectado en ningún nodo, queda en
+// la cola del nodo de origen
+func TestOfflineQueue_Ruteo(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	b := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+	NewOfflineQueueService(b.id, b.transporte, b.clientes, nuevasSesionesFalsas(), nuevaColaFalsa())
+
+	a := &nodoRuteo{
+		id:       uuid.New(),
+		canales:  canales,
+		mensajes: nuevosMensajesFalsos(),
+		rutas:    &rutasFalsas{},
+		clientes: nuevosClientesFalsos(),
+	}
+	ana, bob := uuid.New(), uuid.New()
+	cola := nuevaColaFalsa()
+	a.transporte = red.nodo(a.id)
+	colaA := NewOfflineQueueService(a.id, a.transporte, a.clientes, nuevasSesionesFalsas(ana), cola)
+	a.presencia = nuevaPresenciaRuteo(a)
+	a.servicio = NewRoutingService(a.id, a.transporte, colaA, a.canales, a.mensajes, a.rutas, a.presencia, DefaultRoutingConfig())
+	colaA.SetPresence(a.presencia)
+
+	conectar(t, a, ana, b)
+	sesionBob := conectar(t, b, bob, a)
+
+	m, _ := model.NewMensajeDirecto(uuid.New(), ana, bob, "hola bob", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	esperarHasta(t, func() bool { return b.clientes.recibidos(bob) == 1 })
+	// Un evento para bob emitido en a lo entrega el nodo en el que está conectado
+	if err := colaA.Broadcast([]uuid.UUID{bob}, frameEvento(t, EventoClienteReaccion, 1)); err != nil {
+		t.Fatalf("Broadcast: %v", err)
+	}
+	if pendientes, _ := colaA.Pending(bob); pendientes != 0 {
+		t.Fatalf("un usuario conectado a otro nodo no se encola, hay %d", pendientes)
+	}
+
+	if err := b.presencia.MarkDisconnected(bob, sesionBob); err != nil {
+		t.Fatalf("MarkDisconnected: %v", err)
+	}
+	esperarHasta(t, func() bool { return len(a.servicio.LocateUser(bob)) == 0 })
+
+	otro, _ := model.NewMensajeDirecto(uuid.New(), ana, bob, "¿sigues ahí?", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(otro); err != nil {
+		t.Fatalf("RouteMessage al desconectado: %v", err)
+	}
+	pagina, err := colaA.Replay(bob, model.ConsultaPagina{})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(pagina.Elementos) != 1 || pagina.Elementos[0].Evento() != EventoClienteMensaje {
+		t.Fatalf("el mensaje debe quedar en la cola del origen: %v", pagina.Elementos)
+	}
+	var registro registroMensaje
+	if err := json.Unmarshal(pagina.Elementos[0].Datos(), &registro); err != nil || registro.ID != otro.ID() {
+		t.Errorf("datos del mensaje encolado inesperados: %s (%v)", pagina.Elementos[0].Datos(), err)
+	}
+	if a.clientes.recibidos(bob) != 0 {
+		t.Error("el mensaje encolado no debe enviarse por el socket")
+	}
+}
+
+// TestOfflineQueue_ReintentosAgotados comprueba que un mensaje enrutado hacia un nodo
+// que no vuelve queda en la cola del origen al agotar sus reintentos
+func TestOfflineQueue_ReintentosAgotados(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	config := DefaultRoutingConfig()
+	config.IntervaloReintento = 10 * time.Millisecond
+	config.MaxReintentos = 2
+	b := nuevoNodoRuteo(red, canales, config)
+
+	a := &nodoRuteo{
+		id:       uuid.New(),
+		canales:  canales,
+		mensajes: nuevosMensajesFalsos(),
+		rutas:    &rutasFalsas{},
+		clientes: nuevosClientesFalsos(),
+	}
+	ana, bob := uuid.New(), uuid.New()
+	a.transporte = red.nodo(a.id)
+	colaA := NewOfflineQueueService(a.id, a.transporte, a.clientes, nuevasSesionesFalsas(ana), nuevaColaFalsa())
+	a.presencia = nuevaPresenciaRuteo(a)
+	a.servicio = NewRoutingService(a.id, a.transporte, colaA, a.canales, a.mensajes, a.rutas, a.presencia, config)
+	colaA.SetPresence(a.presencia)
+
+	conectar(t, a, ana, b)
+	conectar(t, b, bob, a)
+
+	red.caer(b.id, true)
+	m, _ := model.NewMensajeDirecto(uuid.New(), ana, bob, "¿estás?", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage con nodo caído debe encolar, obtuvo %v", err)
+	}
+	if pendientes, _ := colaA.Pending(bob); pendientes != 0 {
+		t.Fatalf("mientras quedan reintentos el mensaje no se encola, hay %d", pendientes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.servicio.Start(ctx)
+
+	esperarHasta(t, func() bool {
+		pendientes, _ := colaA.Pending(bob)
+		return pendientes == 1
+	})
+	// Agotados los reintentos el envío sale de la cola de reenvío: recuperar el nodo no
+	// lo entrega por segunda vez
+	red.caer(b.id, false)
+	time.Sleep(5 * config.IntervaloReintento)
+	if b.clientes.recibidos(bob) != 0 {
+		t.Errorf("un envío abandonado no debe reintentarse, bob recibió %d", b.clientes.recibidos(bob))
+	}
+	if pendientes, _ := colaA.Pending(bob); pendientes != 1 {
+		t.Errorf("el mensaje debe encolarse una sola vez, hay %d", pendientes)
+	}
+}
+
+// TestOfflineQueue_TraspasoEntreNodos comprueba que, al recorrer su cola en el nodo en
+// el que entra, el usuario recibe también lo encolado en los demás nodos, en orden, sin
+// las copias de los eventos replicados y borrado del nodo de origen
+func TestOfflineQueue_TraspasoEntreNodos(t *testing.T) {
+	red := nuevaRedFalsa()
+	nuevaCola := func() OfflineQueueService {
+		id := uuid.New()
+		return NewOfflineQueueService(id, red.nodo(id), nuevosClientesFalsos(), nuevasSesionesFalsas(), nuevaColaFalsa())
+	}
+	a, b, c := nuevaCola(), nuevaCola(), nuevaCola()
+	bob := uuid.New()
+	encolar := func(s OfflineQueueService, frame []byte) {
+		t.Helper()
+		if err := s.Broadcast([]uuid.UUID{bob}, frame); err != nil {
+			t.Fatalf("Broadcast: %v", err)
+		}
+	}
+
+	// Los mensajes enrutados solo se encolan en su origen; la reacción replicada, en todos.
+	// La cola de a ocupa más de un lote.
+	var esperados []int
+	for i := 1; i <= loteTraspasoCola+1; i++ {
+		encolar(a, frameEvento(t, EventoClienteMensaje, i))
+		esperados = append(esperados, i)
+	}
+	reaccion := frameEvento(t, EventoClienteReaccion, 0)
+	for _, s := range []OfflineQueueService{a, b, c} {
+		encolar(s, reaccion)
+	}
+	esperados = append(esperados, 0)
+	encolar(b, frameEvento(t, EventoClienteMensaje, loteTraspasoCola+2))
+	esperados = append(esperados, loteTraspasoCola+2)
+
+	consulta, _ := model.NewConsultaPagina("", model.PaginaPosteriores, model.LimitePaginaMaximo)
+	pagina, err := b.Replay(bob, consulta)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if got := numerosEventos(t, pagina); !slices.Equal(got, esperados) {
+		t.Fatalf("cola traspasada inesperada:\n got %v\nwant %v", got, esperados)
+	}
+	for nombre, s := range map[string]OfflineQueueService{"a": a, "c": c} {
+		if pendientes, _ := s.Pending(bob); pendientes != 0 {
+			t.Errorf("el nodo %s debe borrar lo traspasado, quedan %d", nombre, pendientes)
+		}
+	}
+
+	// Confirmar en el nodo de entrada vacía la cola del usuario en todo el clúster
+	posicion, _ := model.ParseCursor(pagina.Siguiente)
+	if _, err := b.Acknowledge(bob, posicion); err != nil {
+		t.Fatalf("Acknowledge: %v", err)
+	}
+	pagina, err = a.Replay(bob, model.ConsultaPagina{})
+	if err != nil || len(pagina.Elementos) != 0 {
+		t.Errorf("no debe quedar nada pendiente: %v (%v)", pagina.Elementos, err)
+	}
+}
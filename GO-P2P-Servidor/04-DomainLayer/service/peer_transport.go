@@ -0,0 +1,40 @@
+package service
+
+import (
+	"github.com/google/uuid"
+)
+
+// Tipos de frame de aplicación intercambiados entre nodos.
+// Los valores 0x0001-0x00FF están reservados para el transporte (datos, keepalive, ACK...).
+const (
+	FrameTipoAntiEntropia      uint16 = 0x0101 // Reconciliación Merkle entre nodos
+	FrameTipoMensajeEnrutado   uint16 = 0x0103 // Mensaje reenviado hacia el nodo de sus destinatarios
+	FrameTipoAdyacencia        uint16 = 0x0104 // Vecinos directos de un nodo, para la tabla de rutas
+	FrameTipoRutaConfirmada    uint16 = 0x0105 // Camino recorrido por un mensaje, de vuelta al origen
+	FrameTipoPresenciaSesiones uint16 = 0x0106 // Conjunto replicado de sesiones y latidos por nodo
+	FrameTipoEdicionMensaje    uint16 = 0x0107 // Edición o borrado de un mensaje, difundido a todos los nodos
+	FrameTipoReaccion          uint16 = 0x0108 // Reacción añadida o retirada, difundida a todos los nodos
+	FrameTipoAcuse             uint16 = 0x0109 // Acuses de entrega y lectura, enrutados hacia el nodo del remitente
+	FrameTipoActividad         uint16 = 0x010A // Actividad efímera (escribiendo, viendo), difundida sin persistirse
+	FrameTipoMensajeProgramado uint16 = 0x010B // Estado de un mensaje programado, difundido a todos los nodos
+	FrameTipoMencion           uint16 = 0x010C // Mención de un usuario en un mensaje de canal, difundida a todos los nodos
+	FrameTipoSilencioMencion   uint16 = 0x010D // Preferencia de silencio de menciones, difundida a todos los nodos
+	FrameTipoFijado            uint16 = 0x010E // Mensaje o enlace fijado o desfijado en un canal, difundido a todos los nodos
+	FrameTipoColaPendiente     uint16 = 0x010F // Cola de eventos pendientes de un usuario, traspasada al nodo en el que entra
+)
+
+// PeerTransport abstrae la red P2P para los servicios de dominio.
+// pool.PeerConnectionPool la satisface sin que el dominio dependa del pool.
+type PeerTransport interface {
+	// GetAllPeerIDs devuelve los nodos actualmente conectados
+	GetAllPeerIDs() []uuid.UUID
+
+	// SendTo envía un frame del tipo indicado a un nodo conectado
+	SendTo(peerID uuid.UUID, frameType uint16, payload []byte) error
+
+	// PeerAddress devuelve la dirección host:puerto de un nodo conectado
+	PeerAddress(peerID uuid.UUID) (string, bool)
+
+	// SetFrameHandler registra la función que procesa los frames recibidos de un tipo
+	SetFrameHandler(frameType uint16, handler func(peerID uuid.UUID, payload []byte))
+}
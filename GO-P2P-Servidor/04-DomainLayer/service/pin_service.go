@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de los elementos fijados en los canales
+var (
+	ErrFijadoMensajeNoEncontrado = errors.New("mensaje no encontrado")
+	ErrFijadoMensajeSinCanal     = errors.New("solo se pueden fijar mensajes de canal")
+	ErrFijadoSinAcceso           = errors.New("el usuario no es miembro del canal")
+	ErrFijadoSinPermiso          = errors.New("solo los administradores del canal pueden fijar y desfijar")
+	ErrFijadoNoEncontrado        = errors.New("el elemento no está fijado en el canal")
+)
+
+// PinService define los mensajes y enlaces fijados en los canales. Solo los miembros con
+// rol ADMIN u OWNER pueden fijar y desfijar; cualquier miembro puede listarlos. Cada
+// cambio se versiona con el HLC del nodo, se difunde a todos los nodos por inundación y
+// se envía como evento a los miembros del canal conectados. Las copias concurrentes se
+// fusionan con ConflictResolver.ResolvePin.
+type PinService interface {
+	// PinMessage fija un mensaje no eliminado en su canal. Fijar un mensaje ya fijado lo
+	// devuelve sin cambios.
+	PinMessage(userID, messageID uuid.UUID) (*model.FijadoCanal, error)
+
+	// PinLink fija un enlace http o https en un canal, con un título opcional. Volver a
+	// fijar un enlace fijado solo lo cambia si cambia el título.
+	PinLink(userID, channelID uuid.UUID, url, titulo string) (*model.FijadoCanal, error)
+
+	// Unpin desfija un elemento fijado del canal
+	Unpin(userID, channelID, pinID uuid.UUID) (*model.FijadoCanal, error)
+
+	// ListPins devuelve los elementos fijados del canal, los más recientes primero, con
+	// quién y cuándo los fijó
+	ListPins(userID, channelID uuid.UUID) ([]*model.FijadoCanal, error)
+}
+
+// pinService implementa PinService sobre PeerTransport y ClientTransport
+type pinService struct {
+	transport PeerTransport
+	clients   ClientTransport
+	channels  repository.IChannelRepository
+	messages  repository.IMessageRepository
+	resolver  ConflictResolver
+	reloj     *model.RelojHLC
+}
+
+// NewPinService crea el servicio y registra su handler de frames en el transporte.
+// reloj debe ser el reloj HLC del propio nodo.
+func NewPinService(
+	transport PeerTransport,
+	clients ClientTransport,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	resolver ConflictResolver,
+	reloj *model.RelojHLC,
+) PinService {
+	s := &pinService{
+		transport: transport,
+		clients:   clients,
+		channels:  channels,
+		messages:  messages,
+		resolver:  resolver,
+		reloj:     reloj,
+	}
+	transport.SetFrameHandler(FrameTipoFijado, s.handleFijado)
+	return s
+}
+
+// PinMessage implementa PinService
+func (s *pinService) PinMessage(userID, messageID uuid.UUID) (*model.FijadoCanal, error) {
+	ctx := context.Background()
+	m, err := s.messages.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrFijadoMensajeNoEncontrado
+	}
+	if m.CanalID() == uuid.Nil {
+		return nil, ErrFijadoMensajeSinCanal
+	}
+	if err := s.autorizar(ctx, m.CanalID(), userID); err != nil {
+		return nil, err
+	}
+	if m.Eliminado() {
+		return nil, model.ErrMensajeEliminado
+	}
+
+	existente, err := s.channels.FindPin(ctx, model.IDFijado(m.CanalID(), messageID, ""))
+	if err != nil {
+		return nil, err
+	}
+	if existente != nil && existente.Vigente() {
+		return existente, nil
+	}
+
+	fijado, err := model.NewFijadoCanal(m.CanalID(), messageID, "", "", userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return fijado, s.aplicarLocal(ctx, fijado)
+}
+
+// PinLink implementa PinService
+func (s *pinService) PinLink(userID, channelID uuid.UUID, url, titulo string) (*model.FijadoCanal, error) {
+	fijado, err := model.NewFijadoCanal(channelID, uuid.Nil, url, titulo, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if err := s.autorizar(ctx, channelID, userID); err != nil {
+		return nil, err
+	}
+
+	existente, err := s.channels.FindPin(ctx, fijado.ID())
+	if err != nil {
+		return nil, err
+	}
+	if existente != nil && existente.Vigente() && existente.Titulo() == fijado.Titulo() {
+		return existente, nil
+	}
+	return fijado, s.aplicarLocal(ctx, fijado)
+}
+
+// Unpin implementa PinService
+func (s *pinService) Unpin(userID, channelID, pinID uuid.UUID) (*model.FijadoCanal, error) {
+	ctx := context.Background()
+	if err := s.autorizar(ctx, channelID, userID); err != nil {
+		return nil, err
+	}
+
+	fijado, err := s.channels.FindPin(ctx, pinID)
+	if err != nil {
+		return nil, err
+	}
+	if fijado == nil || fijado.CanalID() != channelID || !fijado.Vigente() {
+		return nil, ErrFijadoNoEncontrado
+	}
+	if err := fijado.Retirar(userID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return fijado, s.aplicarLocal(ctx, fijado)
+}
+
+// ListPins implementa PinService
+func (s *pinService) ListPins(userID, channelID uuid.UUID) ([]*model.FijadoCanal, error) {
+	ctx := context.Background()
+	miembro, err := esMiembroCanal(ctx, s.channels, channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !miembro {
+		return nil, ErrFijadoSinAcceso
+	}
+	return s.channels.ListPins(ctx, channelID)
+}
+
+// autorizar comprueba que el usuario sea ADMIN u OWNER del canal
+func (s *pinService) autorizar(ctx context.Context, channelID, userID uuid.UUID) error {
+	miembros, err := s.channels.FindMembers(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	for _, miembro := range miembros {
+		if miembro.UsuarioID() != userID {
+			continue
+		}
+		if rangoRol(miembro.Rol()) < rangoRol("ADMIN") {
+			return ErrFijadoSinPermiso
+		}
+		return nil
+	}
+	return ErrFijadoSinAcceso
+}
+
+// aplicarLocal versiona un cambio hecho en este nodo, lo guarda y lo difunde
+func (s *pinService) aplicarLocal(ctx context.Context, fijado *model.FijadoCanal) error {
+	fijado.SetVersion(s.reloj.Now())
+	if err := s.channels.SavePin(ctx, fijado); err != nil {
+		return err
+	}
+	s.difundir(fijado, uuid.Nil)
+	s.notificar(ctx, fijado)
+	return nil
+}
+
+// handleFijado aplica un cambio recibido de otro nodo y lo reenvía a los demás vecinos.
+// Un cambio que no gana al local ya se había aplicado o está superado, y no se reenvía,
+// lo que detiene la inundación.
+func (s *pinService) handleFijado(peerID uuid.UUID, payload []byte) {
+	var registro registroFijado
+	if err := json.Unmarshal(payload, &registro); err != nil {
+		return
+	}
+	remoto, err := registro.aModelo()
+	if err != nil {
+		return
+	}
+	s.reloj.Update(remoto.Version())
+
+	ctx := context.Background()
+	local, err := s.channels.FindPin(ctx, remoto.ID())
+	if err != nil {
+		return
+	}
+	if local != nil {
+		ganador, err := s.resolver.ResolvePin(local, remoto)
+		if err != nil || ganador != remoto {
+			return
+		}
+	}
+	if err := s.channels.SavePin(ctx, remoto); err != nil {
+		return
+	}
+
+	s.difundir(remoto, peerID)
+	s.notificar(ctx, remoto)
+}
+
+// difundir envía el elemento fijado a todos los vecinos salvo al que lo envió
+func (s *pinService) difundir(fijado *model.FijadoCanal, origen uuid.UUID) {
+	payload, err := json.Marshal(aRegistroFijado(fijado))
+	if err != nil {
+		return
+	}
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		if peerID == origen {
+			continue
+		}
+		// Sin anti-entropía para los elementos fijados, un vecino caído no recibe el cambio
+		_ = s.transport.SendTo(peerID, FrameTipoFijado, payload)
+	}
+}
+
+// notificar envía el cambio a los miembros del canal conectados a este nodo
+func (s *pinService) notificar(ctx context.Context, fijado *model.FijadoCanal) {
+	if s.clients == nil {
+		return
+	}
+	miembros, err := s.channels.ListMembers(ctx, fijado.CanalID())
+	if err != nil || len(miembros) == 0 {
+		return
+	}
+	frame, err := codificarEventoCliente(EventoClienteFijado, aRegistroFijado(fijado))
+	if err != nil {
+		return
+	}
+	// Los miembros sin socket en este nodo verán el cambio al listar los elementos fijados
+	_ = s.clients.Broadcast(miembros, frame)
+}
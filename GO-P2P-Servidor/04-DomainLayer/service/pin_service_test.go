@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nuevoNodoFijados crea un nodo simulado con su PinService
+func nuevoNodoFijados(t *testing.T, red *redFalsa) *nodoServicio[PinService] {
+	t.Helper()
+	return nuevoNodoServicio(t, red, func(n *nodoFalso) PinService {
+		return NewPinService(n.transporte, n.clientes, n.canales, n.mensajes, NewConflictResolver(nil), n.reloj)
+	})
+}
+
+func TestPin_PermisosYListado(t *testing.T) {
+	n := nuevoNodoFijados(t, nuevaRedFalsa())
+	ctx := context.Background()
+	duenoID, adminID, miembroID, ajenoID, canalID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	n.canales.AddMember(ctx, canalID, duenoID, "OWNER")
+	n.canales.AddMember(ctx, canalID, adminID, "ADMIN")
+	n.canales.AddMember(ctx, canalID, miembroID, "MIEMBRO")
+	m, _ := model.NewMensajeCanal(uuid.New(), miembroID, canalID, "orden del día", time.Now().Add(-time.Minute), uuid.Nil)
+	n.mensajes.Save(ctx, m)
+	directo, _ := model.NewMensajeDirecto(uuid.New(), adminID, miembroID, "hola", time.Now(), uuid.Nil)
+	n.mensajes.Save(ctx, directo)
+
+	if _, err := n.servicio.PinMessage(miembroID, m.ID()); err != ErrFijadoSinPermiso {
+		t.Errorf("fijado por un miembro: esperado %v, obtuvo %v", ErrFijadoSinPermiso, err)
+	}
+	if _, err := n.servicio.PinLink(ajenoID, canalID, "https://ejemplo.com", ""); err != ErrFijadoSinAcceso {
+		t.Errorf("fijado por un no miembro: esperado %v, obtuvo %v", ErrFijadoSinAcceso, err)
+	}
+	if _, err := n.servicio.PinMessage(adminID, uuid.New()); err != ErrFijadoMensajeNoEncontrado {
+		t.Errorf("mensaje inexistente: esperado %v, obtuvo %v", ErrFijadoMensajeNoEncontrado, err)
+	}
+	if _, err := n.servicio.PinMessage(adminID, directo.ID()); err != ErrFijadoMensajeSinCanal {
+		t.Errorf("mensaje directo: esperado %v, obtuvo %v", ErrFijadoMensajeSinCanal, err)
+	}
+	if _, err := n.servicio.PinLink(adminID, canalID, "ftp://ejemplo.com", ""); err != model.ErrFijadoURLInvalida {
+		t.Errorf("enlace inválido: esperado %v, obtuvo %v", model.ErrFijadoURLInvalida, err)
+	}
+
+	mensaje, err := n.servicio.PinMessage(adminID, m.ID())
+	if err != nil {
+		t.Fatalf("PinMessage: %v", err)
+	}
+	if mensaje.Version().IsZero() || mensaje.FijadoPor() != adminID {
+		t.Errorf("fijado inesperado: versión %v, fijado por %v", mensaje.Version(), mensaje.FijadoPor())
+	}
+	// Fijar de nuevo un mensaje fijado no lo cambia
+	if repetido, err := n.servicio.PinMessage(duenoID, m.ID()); err != nil || repetido.FijadoPor() != adminID {
+		t.Errorf("fijado repetido: err=%v fijado por %v", err, repetido.FijadoPor())
+	}
+	enlace, err := n.servicio.PinLink(duenoID, canalID, "https://ejemplo.com/normas", "Normas")
+	if err != nil {
+		t.Fatalf("PinLink: %v", err)
+	}
+
+	fijados, err := n.servicio.ListPins(miembroID, canalID)
+	if err != nil || len(fijados) != 2 {
+		t.Fatalf("ListPins: %v %v", fijados, err)
+	}
+	if _, err := n.servicio.ListPins(ajenoID, canalID); err != ErrFijadoSinAcceso {
+		t.Errorf("listado de un no miembro: esperado %v, obtuvo %v", ErrFijadoSinAcceso, err)
+	}
+	if conteos, _ := n.canales.CountPins(ctx, []uuid.UUID{canalID}); conteos[canalID] != 2 {
+		t.Errorf("esperados 2 elementos fijados, obtuvo %v", conteos)
+	}
+
+	if _, err := n.servicio.Unpin(miembroID, canalID, enlace.ID()); err != ErrFijadoSinPermiso {
+		t.Errorf("desfijado por un miembro: esperado %v, obtuvo %v", ErrFijadoSinPermiso, err)
+	}
+	if _, err := n.servicio.Unpin(adminID, uuid.New(), enlace.ID()); err != ErrFijadoSinAcceso {
+		t.Errorf("desfijado en otro canal: esperado %v, obtuvo %v", ErrFijadoSinAcceso, err)
+	}
+	retirado, err := n.servicio.Unpin(adminID, canalID, enlace.ID())
+	if err != nil {
+		t.Fatalf("Unpin: %v", err)
+	}
+	if retirado.Vigente() || retirado.RetiradoPor() != adminID {
+		t.Errorf("retirada inesperada: %v", retirado.RetiradoPor())
+	}
+	if _, err := n.servicio.Unpin(adminID, canalID, enlace.ID()); err != ErrFijadoNoEncontrado {
+		t.Errorf("doble retirada: esperado %v, obtuvo %v", ErrFijadoNoEncontrado, err)
+	}
+	if fijados, _ := n.servicio.ListPins(miembroID, canalID); len(fijados) != 1 || fijados[0].ID() != mensaje.ID() {
+		t.Errorf("solo debe quedar el mensaje fijado: %v", fijados)
+	}
+
+	// Cada cambio llega a los miembros del canal y solo a ellos
+	eventos := eventosCliente[registroFijado](t, n.clientes, miembroID, EventoClienteFijado)
+	if len(eventos) != 3 || eventos[2].RetiradoEn.IsZero() || eventos[1].URL != "https://ejemplo.com/normas" {
+		t.Errorf("eventos inesperados: %+v", eventos)
+	}
+	if len(eventosCliente[registroFijado](t, n.clientes, ajenoID, EventoClienteFijado)) != 0 {
+		t.Error("un no miembro no debe recibir eventos de elementos fijados")
+	}
+}
+
+func TestPin_Replicacion(t *testing.T) {
+	red := nuevaRedFalsa()
+	a, b, c := nuevoNodoFijados(t, red), nuevoNodoFijados(t, red), nuevoNodoFijados(t, red)
+	red.enlazar(a.id, b.id)
+	red.enlazar(b.id, c.id)
+
+	adminID, lectorID, canalID := uuid.New(), uuid.New(), uuid.New()
+	for _, n := range []*nodoServicio[PinService]{a, b, c} {
+		n.canales.AddMember(context.Background(), canalID, adminID, "ADMIN")
+		n.canales.AddMember(context.Background(), canalID, lectorID, "MIEMBRO")
+	}
+
+	enlace, err := a.servicio.PinLink(adminID, canalID, "https://ejemplo.com/guia", "Guía")
+	if err != nil {
+		t.Fatalf("PinLink: %v", err)
+	}
+	// El enlace llega a c pasando por b
+	esperarHasta(t, func() bool {
+		fijados, _ := c.servicio.ListPins(lectorID, canalID)
+		return len(fijados) == 1 && fijados[0].Titulo() == "Guía"
+	})
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[registroFijado](t, c.clientes, lectorID, EventoClienteFijado)) == 1
+	})
+
+	// El desfijado hecho en c vuelve hasta a
+	if _, err := c.servicio.Unpin(adminID, canalID, enlace.ID()); err != nil {
+		t.Fatalf("Unpin: %v", err)
+	}
+	esperarHasta(t, func() bool {
+		fijados, _ := a.servicio.ListPins(lectorID, canalID)
+		return len(fijados) == 0
+	})
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[registroFijado](t, a.clientes, lectorID, EventoClienteFijado)) == 2
+	})
+
+	// Una copia antigua que llega tarde no vuelve a fijarlo y la inundación se detiene
+	viejo, _ := model.NewFijadoCanal(canalID, uuid.Nil, "https://ejemplo.com/guia", "Guía", adminID, time.Now().Add(-time.Hour))
+	viejo.SetVersion(hlc(t, 1, a.id))
+	payload, _ := json.Marshal(aRegistroFijado(viejo))
+	red.nodo(a.id).SendTo(b.id, FrameTipoFijado, payload)
+	time.Sleep(20 * time.Millisecond)
+	if f, _ := b.canales.FindPin(context.Background(), enlace.ID()); f == nil || f.Vigente() {
+		t.Error("la copia antigua no debe ganar al desfijado")
+	}
+	if n := len(eventosCliente[registroFijado](t, b.clientes, lectorID, EventoClienteFijado)); n != 2 {
+		t.Errorf("b debe notificar cada cambio una sola vez, obtuvo %d eventos", n)
+	}
+}
+
+func TestConflictResolver_ResolvePin(t *testing.T) {
+	audit := &auditFalso{}
+	r := NewConflictResolver(audit)
+	canalID, mensajeID, adminID := uuid.New(), uuid.New(), uuid.New()
+	nodoA, nodoB := uuid.New(), uuid.New()
+	fecha := time.Now().Add(-time.Hour).UTC()
+
+	vigente, _ := model.NewFijadoCanal(canalID, mensajeID, "", "", adminID, fecha)
+	vigente.SetVersion(hlc(t, 100, nodoA))
+	retirado, _ := model.NewFijadoCanal(canalID, mensajeID, "", "", adminID, fecha)
+	retirado.Retirar(adminID, fecha.Add(time.Minute))
+	retirado.SetVersion(hlc(t, 200, nodoB))
+
+	// El cambio más reciente gana en ambos órdenes y el desacuerdo queda registrado
+	for _, par := range [][2]*model.FijadoCanal{{vigente, retirado}, {retirado, vigente}} {
+		ganador, err := r.ResolvePin(par[0], par[1])
+		if err != nil {
+			t.Fatalf("esperaba sin error, obtuvo %v", err)
+		}
+		if ganador != retirado {
+			t.Error("debe ganar el desfijado, de HLC mayor")
+		}
+	}
+	if len(audit.eventos) != 2 {
+		t.Errorf("esperaba 2 conflictos registrados, obtuvo %d", len(audit.eventos))
+	}
+
+	// Volver a fijarlo no es un conflicto con el fijado anterior
+	refijado, _ := model.NewFijadoCanal(canalID, mensajeID, "", "", uuid.New(), fecha.Add(time.Hour))
+	refijado.SetVersion(hlc(t, 300, nodoA))
+	if ganador, _ := r.ResolvePin(vigente, refijado); ganador != refijado {
+		t.Error("debe ganar el fijado más reciente")
+	}
+	if len(audit.eventos) != 2 {
+		t.Errorf("no esperaba nuevos conflictos, obtuvo %d", len(audit.eventos))
+	}
+
+	otro, _ := model.NewFijadoCanal(uuid.New(), mensajeID, "", "", adminID, fecha)
+	if _, err := r.ResolvePin(vigente, otro); err != ErrConflictoEntidadDistinta {
+		t.Errorf("canal distinto: esperado %v, obtuvo %v", ErrConflictoEntidadDistinta, err)
+	}
+}
@@ -1,18 +1,516 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
 	"github.com/google/uuid"
 	"model"
+	"observer"
+	repository "repository.interfaces"
+)
+
+// Errores de presencia
+var (
+	ErrPresenciaIDNil = errors.New("usuario o sesión de presencia inválidos")
 )
 
-// PresenceService define las operaciones para gestionar la presencia de usuarios en tiempo real
+// PresenceConfig contiene los parámetros de la presencia replicada
+type PresenceConfig struct {
+	IntervaloLatido time.Duration // Periodo entre latidos con el estado completo de presencia
+	ExpiracionNodo  time.Duration // Tiempo sin latidos de un nodo tras el que se ocultan sus sesiones
+	RetencionBajas  time.Duration // Tiempo que se conservan las bajas y las sesiones de nodos caídos
+}
+
+// DefaultPresenceConfig devuelve la configuración por defecto
+func DefaultPresenceConfig() PresenceConfig {
+	return PresenceConfig{
+		IntervaloLatido: 5 * time.Second,
+		ExpiracionNodo:  30 * time.Second,
+		RetencionBajas:  10 * time.Minute,
+	}
+}
+
+// PresenceService define las operaciones para gestionar la presencia de usuarios en tiempo real.
+// La presencia se replica entre nodos como un conjunto CRDT con clave (usuario, nodo, sesión):
+// cada entrada es un registro last-writer-wins versionado con HLC, las bajas se conservan como
+// lápidas y las sesiones de un nodo dejan de contar cuando sus latidos dejan de llegar.
+// También implementa observer.IPeerObserver para ocultar al instante las sesiones de un peer caído.
 type PresenceService interface {
-	// MarkConnected marca a un usuario como conectado
-	MarkConnected(userID uuid.UUID) error
-	
-	// MarkDisconnected marca a un usuario como desconectado
-	MarkDisconnected(userID uuid.UUID) error
-	
-	// ListConnected lista todos los usuarios conectados actualmente
+	observer.IPeerObserver
+
+	// MarkConnected marca como conectada una sesión de un usuario en este nodo
+	MarkConnected(userID, sessionID uuid.UUID) error
+
+	// MarkDisconnected marca como desconectada una sesión de un usuario en este nodo
+	MarkDisconnected(userID, sessionID uuid.UUID) error
+
+	// ListConnected lista los usuarios con alguna sesión activa en cualquier nodo del clúster
 	ListConnected() ([]*model.UsuarioServidor, error)
+
+	// IsOnline indica si el usuario tiene alguna sesión activa en el clúster
+	IsOnline(userID uuid.UUID) bool
+
+	// LocateUser devuelve los nodos en los que el usuario tiene alguna sesión activa
+	LocateUser(userID uuid.UUID) []uuid.UUID
+
+	// Subscribe suscribe a un cliente de este nodo a los cambios de presencia
+	Subscribe(userID uuid.UUID)
+
+	// Unsubscribe cancela la suscripción de un cliente
+	Unsubscribe(userID uuid.UUID)
+
+	// Start lanza los latidos periódicos y la expiración hasta que el contexto se cancele
+	Start(ctx context.Context)
+}
+
+// clavePresencia identifica una sesión dentro del conjunto replicado
+type clavePresencia struct {
+	usuarioID uuid.UUID
+	nodoID    uuid.UUID
+	sesionID  uuid.UUID
+}
+
+// entradaPresencia es el registro LWW de una sesión; conectado=false es una lápida
+type entradaPresencia struct {
+	conectado bool
+	desde     time.Time
+	version   model.HLC
+}
+
+// registroPresencia es la forma serializada de una entrada
+type registroPresencia struct {
+	UsuarioID uuid.UUID `json:"usuarioId"`
+	NodoID    uuid.UUID `json:"nodoId"`
+	SesionID  uuid.UUID `json:"sesionId"`
+	Conectado bool      `json:"conectado"`
+	Desde     time.Time `json:"desde"`
+	Version   string    `json:"version"`
+}
+
+// estadoPresencia es el payload de FrameTipoPresenciaSesiones: un delta o el estado completo.
+// Latidos lleva el último latido conocido de cada nodo, marcado en el reloj de ese nodo.
+type estadoPresencia struct {
+	Latidos  map[uuid.UUID]int64 `json:"latidos"`
+	Sesiones []registroPresencia `json:"sesiones"`
+}
+
+// cambioPresencia es el evento que reciben los clientes suscritos
+type cambioPresencia struct {
+	UsuarioID uuid.UUID `json:"usuarioId"`
+	Conectado bool      `json:"conectado"`
+}
+
+type presenceService struct {
+	nodoID    uuid.UUID
+	transport PeerTransport
+	clients   ClientTransport
+	users     repository.IUserRepository
+	reloj     *model.RelojHLC
+	config    PresenceConfig
+
+	mu           sync.Mutex
+	sesiones     map[clavePresencia]*entradaPresencia
+	propias      map[clavePresencia]bool // sesiones abiertas en este nodo desde su arranque
+	latidos      map[uuid.UUID]int64     // último latido conocido por nodo
+	latidoVisto  map[uuid.UUID]time.Time // hora local en que avanzó el latido de cada nodo
+	suscriptores map[uuid.UUID]bool
+	notificados  map[uuid.UUID]bool // usuarios en línea según la última notificación
+}
+
+// NewPresenceService crea el servicio de presencia del nodo indicado.
+// reloj debe ser el reloj HLC del propio nodo.
+func NewPresenceService(
+	nodoID uuid.UUID,
+	transport PeerTransport,
+	clients ClientTransport,
+	users repository.IUserRepository,
+	reloj *model.RelojHLC,
+	config PresenceConfig,
+) PresenceService {
+	s := &presenceService{
+		nodoID:       nodoID,
+		transport:    transport,
+		clients:      clients,
+		users:        users,
+		reloj:        reloj,
+		config:       config,
+		sesiones:     make(map[clavePresencia]*entradaPresencia),
+		propias:      make(map[clavePresencia]bool),
+		latidos:      make(map[uuid.UUID]int64),
+		latidoVisto:  make(map[uuid.UUID]time.Time),
+		suscriptores: make(map[uuid.UUID]bool),
+		notificados:  make(map[uuid.UUID]bool),
+	}
+	transport.SetFrameHandler(FrameTipoPresenciaSesiones, s.handleEstado)
+	return s
+}
+
+// Start implementa PresenceService
+func (s *presenceService) Start(ctx context.Context) {
+	go func() {
+		latido := time.NewTicker(s.config.IntervaloLatido)
+		defer latido.Stop()
+
+		s.difundir(s.estadoCompleto())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-latido.C:
+				s.difundir(s.estadoCompleto())
+				s.expirar()
+			}
+		}
+	}()
+}
+
+// MarkConnected implementa PresenceService
+func (s *presenceService) MarkConnected(userID, sessionID uuid.UUID) error {
+	return s.cambiarLocal(userID, sessionID, true)
+}
+
+// MarkDisconnected implementa PresenceService
+func (s *presenceService) MarkDisconnected(userID, sessionID uuid.UUID) error {
+	return s.cambiarLocal(userID, sessionID, false)
+}
+
+// cambiarLocal registra una nueva versión de una sesión propia y difunde el delta
+func (s *presenceService) cambiarLocal(userID, sessionID uuid.UUID, conectado bool) error {
+	if userID == uuid.Nil || sessionID == uuid.Nil {
+		return ErrPresenciaIDNil
+	}
+	clave := clavePresencia{usuarioID: userID, nodoID: s.nodoID, sesionID: sessionID}
+
+	s.mu.Lock()
+	entrada := &entradaPresencia{conectado: conectado, desde: time.Now().UTC(), version: s.reloj.Now()}
+	s.sesiones[clave] = entrada
+	if conectado {
+		s.propias[clave] = true
+	} else {
+		delete(s.propias, clave)
+	}
+	delta := &estadoPresencia{
+		Latidos:  map[uuid.UUID]int64{s.nodoID: s.latirLocked()},
+		Sesiones: []registroPresencia{aRegistroPresencia(clave, entrada)},
+	}
+	cambios := s.cambiosLocked(time.Now())
+	s.mu.Unlock()
+
+	s.difundir(delta)
+	s.notificar(cambios)
+	return nil
+}
+
+// ListConnected implementa PresenceService
+func (s *presenceService) ListConnected() ([]*model.UsuarioServidor, error) {
+	s.mu.Lock()
+	enLinea := s.enLineaLocked(time.Now())
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	usuarios := make([]*model.UsuarioServidor, 0, len(enLinea))
+	for _, id := range ordenarIDs(claves(enLinea)) {
+		u, err := s.users.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// Puede que el usuario aún no haya llegado por anti-entropía
+		if u == nil {
+			continue
+		}
+		u.SetConnected(true)
+		usuarios = append(usuarios, u)
+	}
+	return usuarios, nil
+}
+
+// IsOnline implementa PresenceService
+func (s *presenceService) IsOnline(userID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enLineaLocked(time.Now())[userID]
+}
+
+// LocateUser implementa PresenceService
+func (s *presenceService) LocateUser(userID uuid.UUID) []uuid.UUID {
+	ahora := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodos := make(map[uuid.UUID]bool)
+	for clave, entrada := range s.sesiones {
+		if clave.usuarioID == userID && entrada.conectado && s.nodoVivoLocked(clave.nodoID, ahora) {
+			nodos[clave.nodoID] = true
+		}
+	}
+	return ordenarIDs(claves(nodos))
+}
+
+// Subscribe implementa PresenceService
+func (s *presenceService) Subscribe(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suscriptores[userID] = true
+}
+
+// Unsubscribe implementa PresenceService
+func (s *presenceService) Unsubscribe(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.suscriptores, userID)
+}
+
+// OnPeerConnected implementa observer.IPeerObserver; las sesiones del peer
+// vuelven a contar en cuanto llega su siguiente latido
+func (s *presenceService) OnPeerConnected(peer *model.Peer) {}
+
+// OnPeerDisconnected implementa observer.IPeerObserver
+func (s *presenceService) OnPeerDisconnected(peer *model.Peer) {
+	s.olvidarLatido(peer.IDNodo())
+}
+
+// OnPeerHeartbeatMissed implementa observer.IPeerObserver
+func (s *presenceService) OnPeerHeartbeatMissed(peer *model.Peer) {
+	s.olvidarLatido(peer.IDNodo())
+}
+
+// olvidarLatido oculta las sesiones de un nodo hasta que se reciba un latido nuevo suyo
+func (s *presenceService) olvidarLatido(nodoID uuid.UUID) {
+	if nodoID == s.nodoID {
+		return
+	}
+	s.mu.Lock()
+	delete(s.latidoVisto, nodoID)
+	cambios := s.cambiosLocked(time.Now())
+	s.mu.Unlock()
+
+	s.notificar(cambios)
+}
+
+// handleEstado fusiona un delta o estado completo recibido de un peer
+func (s *presenceService) handleEstado(peerID uuid.UUID, payload []byte) {
+	var estado estadoPresencia
+	if err := json.Unmarshal(payload, &estado); err != nil {
+		return
+	}
+	delta, cambios := s.fusionar(&estado)
+	if delta != nil {
+		// Los cambios se propagan a los vecinos para alcanzar nodos a varios saltos; el
+		// emisor también lo recibe por si incluye bajas que este nodo generó al fusionar
+		s.difundir(delta)
+	}
+	s.notificar(cambios)
+}
+
+// fusionar aplica el estado recibido y devuelve las entradas que cambiaron, listas para
+// propagarse, junto con los cambios de presencia por usuario que hay que notificar
+func (s *presenceService) fusionar(estado *estadoPresencia) (*estadoPresencia, []cambioPresencia) {
+	ahora := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delta := &estadoPresencia{Latidos: make(map[uuid.UUID]int64)}
+	for nodoID, latido := range estado.Latidos {
+		if nodoID == s.nodoID {
+			continue
+		}
+		if latido > s.latidos[nodoID] {
+			s.latidos[nodoID] = latido
+			s.latidoVisto[nodoID] = ahora
+		}
+	}
+
+	for _, r := range estado.Sesiones {
+		clave, entrada, err := r.aEntrada()
+		if err != nil {
+			continue
+		}
+		s.reloj.Update(entrada.version)
+
+		// Una sesión propia que este nodo no abrió desde su arranque es de una ejecución
+		// anterior: se responde con una baja más reciente para que el resto la olvide.
+		if clave.nodoID == s.nodoID && entrada.conectado && !s.propias[clave] {
+			entrada = &entradaPresencia{conectado: false, desde: ahora.UTC(), version: s.reloj.Now()}
+		}
+
+		actual := s.sesiones[clave]
+		if actual != nil && !entrada.version.After(actual.version) {
+			continue
+		}
+		s.sesiones[clave] = entrada
+		delta.Sesiones = append(delta.Sesiones, aRegistroPresencia(clave, entrada))
+		if latido, ok := s.latidos[clave.nodoID]; ok {
+			delta.Latidos[clave.nodoID] = latido
+		}
+	}
+
+	cambios := s.cambiosLocked(ahora)
+	if len(delta.Sesiones) == 0 {
+		return nil, cambios
+	}
+	delta.Latidos[s.nodoID] = s.latidos[s.nodoID]
+	return delta, cambios
+}
+
+// expirar notifica las sesiones de nodos sin latidos y purga lápidas y nodos caídos antiguos
+func (s *presenceService) expirar() {
+	ahora := time.Now()
+	s.mu.Lock()
+	for clave, entrada := range s.sesiones {
+		if clave.nodoID == s.nodoID {
+			if !entrada.conectado && ahora.Sub(time.Unix(0, entrada.version.Wall())) > s.config.RetencionBajas {
+				delete(s.sesiones, clave)
+			}
+			continue
+		}
+		visto := s.latidoVisto[clave.nodoID]
+		if ahora.Sub(visto) > s.config.RetencionBajas ||
+			(!entrada.conectado && ahora.Sub(time.Unix(0, entrada.version.Wall())) > s.config.RetencionBajas) {
+			delete(s.sesiones, clave)
+		}
+	}
+	cambios := s.cambiosLocked(ahora)
+	s.mu.Unlock()
+
+	s.notificar(cambios)
+}
+
+// enLineaLocked devuelve los usuarios con alguna sesión visible en el instante dado; requiere s.mu
+func (s *presenceService) enLineaLocked(ahora time.Time) map[uuid.UUID]bool {
+	enLinea := make(map[uuid.UUID]bool)
+	for clave, entrada := range s.sesiones {
+		if entrada.conectado && s.nodoVivoLocked(clave.nodoID, ahora) {
+			enLinea[clave.usuarioID] = true
+		}
+	}
+	return enLinea
+}
+
+// nodoVivoLocked indica si el latido de un nodo avanzó dentro de la ventana de expiración; requiere s.mu
+func (s *presenceService) nodoVivoLocked(nodoID uuid.UUID, ahora time.Time) bool {
+	if nodoID == s.nodoID {
+		return true
+	}
+	visto, ok := s.latidoVisto[nodoID]
+	return ok && ahora.Sub(visto) <= s.config.ExpiracionNodo
+}
+
+// cambiosLocked compara la presencia actual con la última notificada y la toma como
+// nueva referencia, de modo que también se detectan las expiraciones por tiempo; requiere s.mu
+func (s *presenceService) cambiosLocked(ahora time.Time) []cambioPresencia {
+	antes := s.notificados
+	despues := s.enLineaLocked(ahora)
+	s.notificados = despues
+	var cambios []cambioPresencia
+	for id := range antes {
+		if !despues[id] {
+			cambios = append(cambios, cambioPresencia{UsuarioID: id, Conectado: false})
+		}
+	}
+	for id := range despues {
+		if !antes[id] {
+			cambios = append(cambios, cambioPresencia{UsuarioID: id, Conectado: true})
+		}
+	}
+	return cambios
+}
+
+// latirLocked avanza el latido propio; usa la hora del sistema para que siga creciendo
+// tras un reinicio del nodo. Requiere s.mu
+func (s *presenceService) latirLocked() int64 {
+	latido := time.Now().UnixNano()
+	if latido <= s.latidos[s.nodoID] {
+		latido = s.latidos[s.nodoID] + 1
+	}
+	s.latidos[s.nodoID] = latido
+	return latido
+}
+
+// estadoCompleto devuelve el conjunto entero con un latido propio nuevo
+func (s *presenceService) estadoCompleto() *estadoPresencia {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latirLocked()
+
+	estado := &estadoPresencia{
+		Latidos:  make(map[uuid.UUID]int64, len(s.latidos)),
+		Sesiones: make([]registroPresencia, 0, len(s.sesiones)),
+	}
+	for nodoID, latido := range s.latidos {
+		estado.Latidos[nodoID] = latido
+	}
+	for clave, entrada := range s.sesiones {
+		estado.Sesiones = append(estado.Sesiones, aRegistroPresencia(clave, entrada))
+	}
+	return estado
+}
+
+// difundir envía el estado a todos los vecinos
+func (s *presenceService) difundir(estado *estadoPresencia) {
+	payload, err := json.Marshal(estado)
+	if err != nil {
+		return
+	}
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		// Un vecino caído se pondrá al día con el siguiente estado completo
+		_ = s.transport.SendTo(peerID, FrameTipoPresenciaSesiones, payload)
+	}
+}
+
+// notificar envía los cambios de presencia a los clientes suscritos
+func (s *presenceService) notificar(cambios []cambioPresencia) {
+	if len(cambios) == 0 || s.clients == nil {
+		return
+	}
+	s.mu.Lock()
+	suscriptores := claves(s.suscriptores)
+	s.mu.Unlock()
+	if len(suscriptores) == 0 {
+		return
+	}
+	for _, cambio := range cambios {
+		frame, err := codificarEventoCliente(EventoClientePresencia, cambio)
+		if err != nil {
+			continue
+		}
+		_ = s.clients.Broadcast(suscriptores, frame)
+	}
+}
+
+// aRegistroPresencia serializa una entrada del conjunto
+func aRegistroPresencia(clave clavePresencia, entrada *entradaPresencia) registroPresencia {
+	return registroPresencia{
+		UsuarioID: clave.usuarioID,
+		NodoID:    clave.nodoID,
+		SesionID:  clave.sesionID,
+		Conectado: entrada.conectado,
+		Desde:     entrada.desde,
+		Version:   entrada.version.String(),
+	}
+}
+
+// aEntrada valida y convierte un registro recibido
+func (r registroPresencia) aEntrada() (clavePresencia, *entradaPresencia, error) {
+	if r.UsuarioID == uuid.Nil || r.NodoID == uuid.Nil || r.SesionID == uuid.Nil {
+		return clavePresencia{}, nil, ErrPresenciaIDNil
+	}
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return clavePresencia{}, nil, err
+	}
+	clave := clavePresencia{usuarioID: r.UsuarioID, nodoID: r.NodoID, sesionID: r.SesionID}
+	return clave, &entradaPresencia{conectado: r.Conectado, desde: r.Desde, version: version}, nil
+}
+
+// claves devuelve las claves de un conjunto de IDs
+func claves(conjunto map[uuid.UUID]bool) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(conjunto))
+	for id := range conjunto {
+		ids = append(ids, id)
+	}
+	return ids
 }
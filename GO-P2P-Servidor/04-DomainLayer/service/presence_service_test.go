@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nodoPresencia agrupa el servicio de presencia de un nodo simulado
+type nodoPresencia struct {
+	id       uuid.UUID
+	usuarios *usuariosFalsos
+	clientes *clientesFalsos
+	servicio PresenceService
+}
+
+func nuevoNodoPresencia(t *testing.T, red *redFalsa, id uuid.UUID, config PresenceConfig) *nodoPresencia {
+	t.Helper()
+	reloj, err := model.NewRelojHLC(id)
+	if err != nil {
+		t.Fatalf("NewRelojHLC: %v", err)
+	}
+	n := &nodoPresencia{id: id, usuarios: nuevosUsuariosFalsos(), clientes: nuevosClientesFalsos()}
+	n.servicio = NewPresenceService(id, red.nodo(id), n.clientes, n.usuarios, reloj, config)
+	return n
+}
+
+// configPresenciaRapida acorta los tiempos para las pruebas de expiración
+func configPresenciaRapida() PresenceConfig {
+	config := DefaultPresenceConfig()
+	config.IntervaloLatido = 10 * time.Millisecond
+	config.ExpiracionNodo = 60 * time.Millisecond
+	return config
+}
+
+func TestPresence_ReplicacionMultiSalto(t *testing.T) {
+	ctx := context.Background()
+	red := nuevaRedFalsa()
+	a := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+	b := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+	c := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+	red.enlazar(a.id, b.id)
+	red.enlazar(b.id, c.id)
+
+	usuarioID, oyenteID := uuid.New(), uuid.New()
+	u, _ := model.NewUsuarioServidor(usuarioID, "ana", "ana@x.com", "hash", "", "10.0.0.1", time.Now())
+	a.usuarios.Save(ctx, u)
+	a.servicio.Subscribe(oyenteID)
+
+	sesion := uuid.New()
+	if err := c.servicio.MarkConnected(usuarioID, sesion); err != nil {
+		t.Fatalf("MarkConnected: %v", err)
+	}
+	esperarHasta(t, func() bool { return a.servicio.IsOnline(usuarioID) })
+
+	conectados, err := a.servicio.ListConnected()
+	if err != nil {
+		t.Fatalf("ListConnected: %v", err)
+	}
+	if len(conectados) != 1 || conectados[0].ID() != usuarioID || !conectados[0].IsConnected() {
+		t.Fatalf("conectados inesperados: %v", conectados)
+	}
+	if eventos := eventosCliente[cambioPresencia](t, a.clientes, oyenteID, EventoClientePresencia); len(eventos) != 1 || !eventos[0].Conectado {
+		t.Errorf("eventos inesperados: %+v", eventos)
+	}
+
+	if err := c.servicio.MarkDisconnected(usuarioID, sesion); err != nil {
+		t.Fatalf("MarkDisconnected: %v", err)
+	}
+	esperarHasta(t, func() bool { return !a.servicio.IsOnline(usuarioID) })
+	if eventos := eventosCliente[cambioPresencia](t, a.clientes, oyenteID, EventoClientePresencia); len(eventos) != 2 || eventos[1].Conectado {
+		t.Errorf("eventos inesperados: %+v", eventos)
+	}
+}
+
+func TestPresence_SesionesMultiples(t *testing.T) {
+	red := nuevaRedFalsa()
+	a := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+	b := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+	usuarioID := uuid.New()
+
+	// El mismo usuario conectado desde los dos nodos
+	sesionA, sesionB := uuid.New(), uuid.New()
+	a.servicio.MarkConnected(usuarioID, sesionA)
+	b.servicio.MarkConnected(usuarioID, sesionB)
+	a.servicio.MarkDisconnected(usuarioID, sesionA)
+
+	// La baja de una sesión no oculta la otra
+	time.Sleep(50 * time.Millisecond)
+	if !a.servicio.IsOnline(usuarioID) || !b.servicio.IsOnline(usuarioID) {
+		t.Fatal("el usuario sigue conectado por su otra sesión")
+	}
+	if nodos := a.servicio.LocateUser(usuarioID); len(nodos) != 1 || nodos[0] != b.id {
+		t.Errorf("el usuario solo está ubicado en el nodo B, obtuvo %v", nodos)
+	}
+
+	b.servicio.MarkDisconnected(usuarioID, sesionB)
+	esperarHasta(t, func() bool { return !a.servicio.IsOnline(usuarioID) && !b.servicio.IsOnline(usuarioID) })
+}
+
+func TestPresence_ExpiraSinLatidos(t *testing.T) {
+	ctx, cancelar := context.WithCancel(context.Background())
+	defer cancelar()
+	red := nuevaRedFalsa()
+	a := nuevoNodoPresencia(t, red, uuid.New(), configPresenciaRapida())
+	b := nuevoNodoPresencia(t, red, uuid.New(), configPresenciaRapida())
+	a.servicio.Start(ctx)
+	b.servicio.Start(ctx)
+
+	usuarioID, oyenteID := uuid.New(), uuid.New()
+	a.servicio.Subscribe(oyenteID)
+	b.servicio.MarkConnected(usuarioID, uuid.New())
+	esperarHasta(t, func() bool { return a.servicio.IsOnline(usuarioID) })
+
+	// Sin latidos de B sus sesiones dejan de contar y se notifica la salida
+	red.caer(b.id, true)
+	esperarHasta(t, func() bool { return !a.servicio.IsOnline(usuarioID) })
+	esperarHasta(t, func() bool {
+		eventos := eventosCliente[cambioPresencia](t, a.clientes, oyenteID, EventoClientePresencia)
+		return len(eventos) == 2 && !eventos[1].Conectado
+	})
+
+	// Al volver B, su estado completo restaura la sesión
+	red.caer(b.id, false)
+	esperarHasta(t, func() bool { return a.servicio.IsOnline(usuarioID) })
+}
+
+func TestPresence_PeerCaidoPorHeartbeat(t *testing.T) {
+	red := nuevaRedFalsa()
+	a := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+	b := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+
+	usuarioID := uuid.New()
+	b.servicio.MarkConnected(usuarioID, uuid.New())
+	esperarHasta(t, func() bool { return a.servicio.IsOnline(usuarioID) })
+
+	peer, err := model.NewPeer(b.id, "10.0.0.2:9000", model.NodoDesconectado)
+	if err != nil {
+		t.Fatalf("NewPeer: %v", err)
+	}
+	a.servicio.OnPeerHeartbeatMissed(peer)
+	if a.servicio.IsOnline(usuarioID) {
+		t.Error("las sesiones de un peer sin heartbeats no deben contar")
+	}
+}
+
+func TestPresence_SesionDeEjecucionAnterior(t *testing.T) {
+	ctx, cancelar := context.WithCancel(context.Background())
+	defer cancelar()
+	red := nuevaRedFalsa()
+	a := nuevoNodoPresencia(t, red, uuid.New(), configPresenciaRapida())
+	idB := uuid.New()
+	b := nuevoNodoPresencia(t, red, idB, configPresenciaRapida())
+
+	usuarioID := uuid.New()
+	b.servicio.MarkConnected(usuarioID, uuid.New())
+	esperarHasta(t, func() bool { return a.servicio.IsOnline(usuarioID) })
+
+	// B se reinicia sin sesiones; la que A recuerda debe darse de baja
+	b = nuevoNodoPresencia(t, red, idB, configPresenciaRapida())
+	a.servicio.Start(ctx)
+	b.servicio.Start(ctx)
+	esperarHasta(t, func() bool { return !a.servicio.IsOnline(usuarioID) })
+	if b.servicio.IsOnline(usuarioID) {
+		t.Error("B no debe mostrar sesiones de su ejecución anterior")
+	}
+}
+
+func TestPresence_IDsInvalidos(t *testing.T) {
+	red := nuevaRedFalsa()
+	a := nuevoNodoPresencia(t, red, uuid.New(), DefaultPresenceConfig())
+	if err := a.servicio.MarkConnected(uuid.Nil, uuid.New()); err != ErrPresenciaIDNil {
+		t.Errorf("esperado %v, obtuvo %v", ErrPresenciaIDNil, err)
+	}
+	if err := a.servicio.MarkDisconnected(uuid.New(), uuid.Nil); err != ErrPresenciaIDNil {
+		t.Errorf("esperado %v, obtuvo %v", ErrPresenciaIDNil, err)
+	}
+}
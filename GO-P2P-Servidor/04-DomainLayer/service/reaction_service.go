@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de las reacciones a mensajes
+var (
+	ErrReaccionMensajeNoEncontrado = errors.New("mensaje no encontrado")
+	ErrReaccionSinAcceso           = errors.New("el usuario no puede ver el mensaje")
+	ErrReaccionNoEncontrada        = errors.New("el usuario no ha reaccionado al mensaje con ese emoji")
+)
+
+// ReactionService define las reacciones con emoji a los mensajes. Cada cambio se versiona
+// con el HLC del nodo, se difunde a todos los nodos por inundación y se envía como
+// evento a los clientes conectados que pueden ver el mensaje. Los nodos que no reciban
+// la difusión convergen por anti-entropía, que fusiona las reacciones con
+// ConflictResolver.ResolveReaction.
+type ReactionService interface {
+	// AddReaction añade la reacción del usuario con un emoji a un mensaje no eliminado que
+	// puede ver. Añadir una reacción ya vigente la devuelve sin cambios.
+	AddReaction(userID, messageID uuid.UUID, emoji string) (*model.ReaccionMensaje, error)
+
+	// RemoveReaction retira la reacción vigente del usuario con un emoji
+	RemoveReaction(userID, messageID uuid.UUID, emoji string) (*model.ReaccionMensaje, error)
+
+	// CountReactions devuelve el número de reacciones vigentes por emoji de cada mensaje,
+	// de más a menos usado; los mensajes sin reacciones no aparecen
+	CountReactions(messageIDs []uuid.UUID) (map[uuid.UUID][]model.ConteoReaccion, error)
+}
+
+// conteoEvento es el número de reacciones con un emoji tal como lo reciben los clientes
+type conteoEvento struct {
+	Emoji string `json:"emoji"`
+	Total int    `json:"total"`
+}
+
+// cambioReaccion es el evento que reciben los clientes al añadirse o retirarse una
+// reacción, con los totales del mensaje ya actualizados
+type cambioReaccion struct {
+	Reaccion registroReaccion `json:"reaccion"`
+	Conteos  []conteoEvento   `json:"conteos"`
+}
+
+// reactionService implementa ReactionService sobre PeerTransport y ClientTransport
+type reactionService struct {
+	transport PeerTransport
+	clients   ClientTransport
+	channels  repository.IChannelRepository
+	messages  repository.IMessageRepository
+	resolver  ConflictResolver
+	reloj     *model.RelojHLC
+}
+
+// NewReactionService crea el servicio y registra su handler de frames en el transporte.
+// reloj debe ser el reloj HLC del propio nodo.
+func NewReactionService(
+	transport PeerTransport,
+	clients ClientTransport,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	resolver ConflictResolver,
+	reloj *model.RelojHLC,
+) ReactionService {
+	s := &reactionService{
+		transport: transport,
+		clients:   clients,
+		channels:  channels,
+		messages:  messages,
+		resolver:  resolver,
+		reloj:     reloj,
+	}
+	transport.SetFrameHandler(FrameTipoReaccion, s.handleReaccion)
+	return s
+}
+
+// AddReaction implementa ReactionService
+func (s *reactionService) AddReaction(userID, messageID uuid.UUID, emoji string) (*model.ReaccionMensaje, error) {
+	if !model.EmojiValido(emoji) {
+		return nil, model.ErrReaccionEmojiInvalido
+	}
+	ctx := context.Background()
+	m, err := s.mensajeVisible(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Eliminado() {
+		return nil, model.ErrMensajeEliminado
+	}
+
+	existente, err := s.messages.FindReaction(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+	if existente != nil && existente.Vigente() {
+		return existente, nil
+	}
+
+	reaccion, err := model.NewReaccionMensaje(messageID, userID, emoji, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return reaccion, s.aplicarLocal(ctx, m, reaccion)
+}
+
+// RemoveReaction implementa ReactionService
+func (s *reactionService) RemoveReaction(userID, messageID uuid.UUID, emoji string) (*model.ReaccionMensaje, error) {
+	ctx := context.Background()
+	m, err := s.mensajeVisible(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	reaccion, err := s.messages.FindReaction(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+	if reaccion == nil || !reaccion.Vigente() {
+		return nil, ErrReaccionNoEncontrada
+	}
+	if err := reaccion.Retirar(time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return reaccion, s.aplicarLocal(ctx, m, reaccion)
+}
+
+// CountReactions implementa ReactionService
+func (s *reactionService) CountReactions(messageIDs []uuid.UUID) (map[uuid.UUID][]model.ConteoReaccion, error) {
+	return s.messages.CountReactions(context.Background(), messageIDs)
+}
+
+// mensajeVisible devuelve el mensaje comprobando que el usuario pueda verlo
+func (s *reactionService) mensajeVisible(ctx context.Context, userID, messageID uuid.UUID) (*model.MensajeServidor, error) {
+	m, err := s.messages.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrReaccionMensajeNoEncontrado
+	}
+	visible, err := puedeVerMensaje(ctx, s.channels, userID, m)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrReaccionSinAcceso
+	}
+	return m, nil
+}
+
+// aplicarLocal versiona un cambio hecho en este nodo, lo guarda y lo difunde
+func (s *reactionService) aplicarLocal(ctx context.Context, m *model.MensajeServidor, reaccion *model.ReaccionMensaje) error {
+	reaccion.SetVersion(s.reloj.Now())
+	if err := s.messages.SaveReaction(ctx, reaccion); err != nil {
+		return err
+	}
+	s.difundir(reaccion, uuid.Nil)
+	s.notificar(ctx, m, reaccion)
+	return nil
+}
+
+// handleReaccion aplica una reacción recibida de otro nodo y la reenvía a los demás
+// vecinos. Una reacción que no gana a la local ya se había aplicado o está superada,
+// y no se reenvía, lo que detiene la inundación.
+func (s *reactionService) handleReaccion(peerID uuid.UUID, payload []byte) {
+	var registro registroReaccion
+	if err := json.Unmarshal(payload, &registro); err != nil {
+		return
+	}
+	remota, err := registro.aModelo()
+	if err != nil {
+		return
+	}
+	s.reloj.Update(remota.Version())
+
+	ctx := context.Background()
+	local, err := s.messages.FindReaction(ctx, remota.MensajeID(), remota.UsuarioID(), remota.Emoji())
+	if err != nil {
+		return
+	}
+	if local != nil {
+		ganadora, err := s.resolver.ResolveReaction(local, remota)
+		if err != nil || ganadora != remota {
+			return
+		}
+	}
+	// Sin el mensaje la reacción llegará por anti-entropía junto con él
+	m, err := s.messages.FindByID(ctx, remota.MensajeID())
+	if err != nil || m == nil {
+		return
+	}
+	if err := s.messages.SaveReaction(ctx, remota); err != nil {
+		return
+	}
+
+	s.difundir(remota, peerID)
+	s.notificar(ctx, m, remota)
+}
+
+// difundir envía la reacción a todos los vecinos salvo al que la envió
+func (s *reactionService) difundir(reaccion *model.ReaccionMensaje, origen uuid.UUID) {
+	payload, err := json.Marshal(aRegistroReaccion(reaccion))
+	if err != nil {
+		return
+	}
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		if peerID == origen {
+			continue
+		}
+		// Un vecino caído recibirá la reacción por anti-entropía
+		_ = s.transport.SendTo(peerID, FrameTipoReaccion, payload)
+	}
+}
+
+// notificar envía la reacción y los totales del mensaje a los clientes de este nodo que
+// pueden verlo
+func (s *reactionService) notificar(ctx context.Context, m *model.MensajeServidor, reaccion *model.ReaccionMensaje) {
+	if s.clients == nil {
+		return
+	}
+	destinatarios, err := destinatariosMensaje(ctx, s.channels, m)
+	if err != nil || len(destinatarios) == 0 {
+		return
+	}
+	conteos, err := s.messages.CountReactions(ctx, []uuid.UUID{m.ID()})
+	if err != nil {
+		return
+	}
+
+	evento := cambioReaccion{Reaccion: aRegistroReaccion(reaccion), Conteos: []conteoEvento{}}
+	for _, c := range conteos[m.ID()] {
+		evento.Conteos = append(evento.Conteos, conteoEvento{Emoji: c.Emoji, Total: c.Total})
+	}
+	frame, err := codificarEventoCliente(EventoClienteReaccion, evento)
+	if err != nil {
+		return
+	}
+	// Los usuarios sin socket en este nodo verán los totales al listar los mensajes
+	_ = s.clients.Broadcast(destinatarios, frame)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nuevoNodoReacciones crea un nodo simulado con su ReactionService
+func nuevoNodoReacciones(t *testing.T, red *redFalsa) *nodoServicio[ReactionService] {
+	t.Helper()
+	return nuevoNodoServicio(t, red, func(n *nodoFalso) ReactionService {
+		return NewReactionService(n.transporte, n.clientes, n.canales, n.mensajes, NewConflictResolver(nil), n.reloj)
+	})
+}
+
+func TestReaction_AccesoYConteos(t *testing.T) {
+	n := nuevoNodoReacciones(t, nuevaRedFalsa())
+	autorID, anaID, ajenoID, canalID, mensajeID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	n.sembrarMensajeCanal(t, mensajeID, autorID, canalID, time.Now().Add(-time.Minute), map[uuid.UUID]string{autorID: "member", anaID: "member"})
+
+	if _, err := n.servicio.AddReaction(ajenoID, mensajeID, "👍"); err != ErrReaccionSinAcceso {
+		t.Errorf("reacción de un no miembro: esperado %v, obtuvo %v", ErrReaccionSinAcceso, err)
+	}
+	if _, err := n.servicio.AddReaction(anaID, uuid.New(), "👍"); err != ErrReaccionMensajeNoEncontrado {
+		t.Errorf("mensaje inexistente: esperado %v, obtuvo %v", ErrReaccionMensajeNoEncontrado, err)
+	}
+	if _, err := n.servicio.AddReaction(anaID, mensajeID, "me gusta"); err != model.ErrReaccionEmojiInvalido {
+		t.Errorf("emoji inválido: esperado %v, obtuvo %v", model.ErrReaccionEmojiInvalido, err)
+	}
+	if _, err := n.servicio.RemoveReaction(anaID, mensajeID, "👍"); err != ErrReaccionNoEncontrada {
+		t.Errorf("retirar sin reaccionar: esperado %v, obtuvo %v", ErrReaccionNoEncontrada, err)
+	}
+
+	primera, err := n.servicio.AddReaction(anaID, mensajeID, "👍")
+	if err != nil {
+		t.Fatalf("AddReaction: %v", err)
+	}
+	if primera.Version().IsZero() {
+		t.Error("la reacción debe quedar versionada con el HLC del nodo")
+	}
+	// Repetir una reacción vigente no la duplica ni la cambia
+	if repetida, err := n.servicio.AddReaction(anaID, mensajeID, "👍"); err != nil || repetida.Version() != primera.Version() {
+		t.Errorf("reacción repetida: err=%v versión=%v, esperada %v", err, repetida.Version(), primera.Version())
+	}
+	n.servicio.AddReaction(autorID, mensajeID, "👍")
+	n.servicio.AddReaction(autorID, mensajeID, "🍕")
+
+	conteos, err := n.servicio.CountReactions([]uuid.UUID{mensajeID, uuid.New()})
+	if err != nil {
+		t.Fatalf("CountReactions: %v", err)
+	}
+	if len(conteos) != 1 || len(conteos[mensajeID]) != 2 || conteos[mensajeID][0] != (model.ConteoReaccion{Emoji: "👍", Total: 2}) {
+		t.Errorf("conteos inesperados: %v", conteos)
+	}
+
+	if _, err := n.servicio.RemoveReaction(anaID, mensajeID, "👍"); err != nil {
+		t.Fatalf("RemoveReaction: %v", err)
+	}
+	if _, err := n.servicio.RemoveReaction(anaID, mensajeID, "👍"); err != ErrReaccionNoEncontrada {
+		t.Errorf("doble retirada: esperado %v, obtuvo %v", ErrReaccionNoEncontrada, err)
+	}
+	// El evento de la retirada lleva los totales ya actualizados
+	eventos := eventosCliente[cambioReaccion](t, n.clientes, anaID, EventoClienteReaccion)
+	if len(eventos) != 4 {
+		t.Fatalf("esperados 4 eventos, obtuvo %d", len(eventos))
+	}
+	ultimo := eventos[3]
+	if ultimo.Reaccion.RetiradaAt.IsZero() || len(ultimo.Conteos) != 2 || ultimo.Conteos[0].Total != 1 {
+		t.Errorf("evento de retirada inesperado: %+v", ultimo)
+	}
+	if len(eventosCliente[cambioReaccion](t, n.clientes, ajenoID, EventoClienteReaccion)) != 0 {
+		t.Error("un no miembro no debe recibir eventos de reacción")
+	}
+
+	// No se reacciona a mensajes eliminados
+	m, _ := n.mensajes.FindByID(context.Background(), mensajeID)
+	m.Eliminar(time.Now())
+	if _, err := n.servicio.AddReaction(anaID, mensajeID, "😢"); err != model.ErrMensajeEliminado {
+		t.Errorf("reacción a un eliminado: esperado %v, obtuvo %v", model.ErrMensajeEliminado, err)
+	}
+}
+
+func TestReaction_Replicacion(t *testing.T) {
+	red := nuevaRedFalsa()
+	a, b, c := nuevoNodoReacciones(t, red), nuevoNodoReacciones(t, red), nuevoNodoReacciones(t, red)
+	red.enlazar(a.id, b.id)
+	red.enlazar(b.id, c.id)
+
+	autorID, lectorID, canalID, mensajeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	enviado := time.Now().Add(-time.Minute).UTC()
+	for _, n := range []*nodoServicio[ReactionService]{a, b, c} {
+		n.sembrarMensajeCanal(t, mensajeID, autorID, canalID, enviado, map[uuid.UUID]string{autorID: "member", lectorID: "member"})
+	}
+
+	if _, err := a.servicio.AddReaction(lectorID, mensajeID, "🎉"); err != nil {
+		t.Fatalf("AddReaction: %v", err)
+	}
+	// La reacción llega a c pasando por b
+	esperarHasta(t, func() bool {
+		r, _ := c.mensajes.FindReaction(context.Background(), mensajeID, lectorID, "🎉")
+		return r != nil && r.Vigente()
+	})
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[cambioReaccion](t, c.clientes, autorID, EventoClienteReaccion)) == 1
+	})
+
+	// La retirada hecha en c vuelve hasta a
+	if _, err := c.servicio.RemoveReaction(lectorID, mensajeID, "🎉"); err != nil {
+		t.Fatalf("RemoveReaction: %v", err)
+	}
+	esperarHasta(t, func() bool {
+		conteos, _ := a.servicio.CountReactions([]uuid.UUID{mensajeID})
+		return len(conteos) == 0
+	})
+	esperarHasta(t, func() bool {
+		return len(eventosCliente[cambioReaccion](t, a.clientes, autorID, EventoClienteReaccion)) == 2
+	})
+
+	// Una reacción antigua que llega tarde no resucita la retirada y la inundación se detiene
+	vieja, _ := model.NewReaccionMensaje(mensajeID, lectorID, "🎉", enviado)
+	vieja.SetVersion(hlc(t, 1, a.id))
+	payload, _ := json.Marshal(aRegistroReaccion(vieja))
+	red.nodo(a.id).SendTo(b.id, FrameTipoReaccion, payload)
+	time.Sleep(20 * time.Millisecond)
+	if r, _ := b.mensajes.FindReaction(context.Background(), mensajeID, lectorID, "🎉"); r == nil || r.Vigente() {
+		t.Error("la reacción antigua no debe ganar a la retirada")
+	}
+	if n := len(eventosCliente[cambioReaccion](t, b.clientes, autorID, EventoClienteReaccion)); n != 2 {
+		t.Errorf("b debe notificar cada cambio una sola vez, obtuvo %d eventos", n)
+	}
+}
+
+func TestConflictResolver_ResolveReaction(t *testing.T) {
+	audit := &auditFalso{}
+	r := NewConflictResolver(audit)
+	mensajeID, usuarioID := uuid.New(), uuid.New()
+	nodoA, nodoB := uuid.New(), uuid.New()
+	fecha := time.Now().Add(-time.Hour).UTC()
+
+	vigente, _ := model.NewReaccionMensaje(mensajeID, usuarioID, "👍", fecha)
+	vigente.SetVersion(hlc(t, 100, nodoA))
+	retirada, _ := model.NewReaccionMensaje(mensajeID, usuarioID, "👍", fecha)
+	retirada.Retirar(fecha.Add(time.Minute))
+	retirada.SetVersion(hlc(t, 200, nodoB))
+
+	// El cambio más reciente gana en ambos órdenes y el desacuerdo queda registrado
+	for _, par := range [][2]*model.ReaccionMensaje{{vigente, retirada}, {retirada, vigente}} {
+		ganadora, err := r.ResolveReaction(par[0], par[1])
+		if err != nil {
+			t.Fatalf("esperaba sin error, obtuvo %v", err)
+		}
+		if ganadora != retirada {
+			t.Error("debe ganar la retirada, de HLC mayor")
+		}
+	}
+	if len(audit.eventos) != 2 {
+		t.Errorf("esperaba 2 conflictos registrados, obtuvo %d", len(audit.eventos))
+	}
+
+	// Volver a añadir la reacción no es un conflicto con el alta anterior
+	reanadida, _ := model.NewReaccionMensaje(mensajeID, usuarioID, "👍", fecha.Add(time.Hour))
+	reanadida.SetVersion(hlc(t, 300, nodoA))
+	if ganadora, _ := r.ResolveReaction(vigente, reanadida); ganadora != reanadida {
+		t.Error("debe ganar el alta más reciente")
+	}
+	if len(audit.eventos) != 2 {
+		t.Errorf("no esperaba nuevos conflictos, obtuvo %d", len(audit.eventos))
+	}
+
+	otra, _ := model.NewReaccionMensaje(mensajeID, usuarioID, "🎉", fecha)
+	if _, err := r.ResolveReaction(vigente, otra); err != ErrConflictoEntidadDistinta {
+		t.Errorf("emoji distinto: esperado %v, obtuvo %v", ErrConflictoEntidadDistinta, err)
+	}
+}
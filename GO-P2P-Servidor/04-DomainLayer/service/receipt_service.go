@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de los acuses de entrega y lectura
+var (
+	ErrAcuseMensajeNoEncontrado = errors.New("mensaje no encontrado")
+	ErrAcuseSinAcceso           = errors.New("el usuario no participa en la conversación del mensaje")
+	ErrAcuseNoRemitente         = errors.New("solo el remitente puede consultar los acuses del mensaje")
+)
+
+// maxAcusesLectura es el número máximo de mensajes que un informe de lectura marca como
+// leídos. Los anteriores quedan cubiertos por el marcador de lectura, que es el que
+// cuenta para los no leídos, aunque su acuse no pase a LEIDO.
+const maxAcusesLectura = 500
+
+// ReceiptService define los acuses de entrega y lectura de los mensajes directos, de
+// canal y de chat privado. Cada destinatario de un mensaje pasa de ENVIADO a ENTREGADO
+// cuando un dispositivo suyo confirma la recepción y a LEIDO cuando informa de que leyó
+// la conversación hasta ese mensaje. Los acuses se envían al remitente como evento de
+// cliente en este nodo o, si está conectado a otro, enrutados hasta el suyo.
+type ReceiptService interface {
+	// AcknowledgeDelivery registra que un dispositivo del usuario recibió los mensajes y
+	// avisa a sus remitentes. Se ignoran los mensajes de los que el usuario no es
+	// destinatario y los que ya estaban entregados o leídos.
+	AcknowledgeDelivery(userID uuid.UUID, messageIDs []uuid.UUID) error
+
+	// MarkRead registra que el usuario leyó su conversación hasta messageID: mueve su
+	// marcador de lectura, pasa a LEIDO los mensajes recibidos desde el marcador anterior
+	// y avisa a sus remitentes. Un mensaje anterior al marcador no lo hace retroceder.
+	MarkRead(userID, messageID uuid.UUID) (*model.MarcadorLectura, error)
+
+	// ListReceipts devuelve el estado del mensaje para cada destinatario, ENVIADO si aún
+	// no hay acuse. Solo el remitente puede consultarlo.
+	ListReceipts(userID, messageID uuid.UUID) ([]*model.AcuseMensaje, error)
+
+	// UnreadCounts devuelve el número de mensajes sin leer de cada conversación del
+	// usuario que tiene alguno: canales, chats privados y, en los mensajes directos, el
+	// otro usuario
+	UnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+// acusesEnrutados es el payload JSON de los frames FrameTipoAcuse: acuses de mensajes de
+// Remitente que viajan hasta el nodo Destino, en el que está conectado
+type acusesEnrutados struct {
+	Origen    uuid.UUID       `json:"origen"`
+	Destino   uuid.UUID       `json:"destino"`
+	Remitente uuid.UUID       `json:"remitente"`
+	TTL       int             `json:"ttl"`
+	Acuses    []registroAcuse `json:"acuses"`
+}
+
+// avisoAcuses es el evento que recibe el remitente con los acuses nuevos de sus mensajes
+type avisoAcuses struct {
+	Acuses []registroAcuse `json:"acuses"`
+}
+
+// receiptService implementa ReceiptService sobre PeerTransport, ClientTransport y el
+// directorio de usuarios de RoutingService
+type receiptService struct {
+	nodoID    uuid.UUID
+	transport PeerTransport
+	clients   ClientTransport
+	routing   RoutingService
+	channels  repository.IChannelRepository
+	messages  repository.IMessageRepository
+	ttl       int
+}
+
+// NewReceiptService crea el servicio y registra su handler de frames en el transporte.
+// routing ubica a los remitentes conectados a otros nodos y da el siguiente salto
+// hacia ellos; sin él los acuses solo se avisan en este nodo.
+func NewReceiptService(
+	nodoID uuid.UUID,
+	transport PeerTransport,
+	clients ClientTransport,
+	routing RoutingService,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+) ReceiptService {
+	s := &receiptService{
+		nodoID:    nodoID,
+		transport: transport,
+		clients:   clients,
+		routing:   routing,
+		channels:  channels,
+		messages:  messages,
+		ttl:       DefaultRoutingConfig().TTL,
+	}
+	transport.SetFrameHandler(FrameTipoAcuse, s.handleAcuses)
+	return s
+}
+
+// AcknowledgeDelivery implementa ReceiptService
+func (s *receiptService) AcknowledgeDelivery(userID uuid.UUID, messageIDs []uuid.UUID) error {
+	ctx := context.Background()
+	ahora := time.Now().UTC()
+	nuevos := make(map[uuid.UUID][]*model.AcuseMensaje)
+	for _, id := range messageIDs {
+		m, err := s.messages.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if m == nil || m.Eliminado() || m.RemitenteID() == userID {
+			continue
+		}
+		destinatario, err := s.esDestinatario(ctx, userID, m)
+		if err != nil {
+			return err
+		}
+		if !destinatario {
+			continue
+		}
+		acuse, err := s.avanzar(ctx, m, userID, model.EntregaEntregado, ahora)
+		if err != nil {
+			return err
+		}
+		if acuse != nil {
+			nuevos[m.RemitenteID()] = append(nuevos[m.RemitenteID()], acuse)
+		}
+	}
+
+	for remitenteID, acuses := range nuevos {
+		s.avisar(remitenteID, acuses)
+	}
+	return nil
+}
+
+// MarkRead implementa ReceiptService
+func (s *receiptService) MarkRead(userID, messageID uuid.UUID) (*model.MarcadorLectura, error) {
+	ctx := context.Background()
+	m, err := s.messages.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrAcuseMensajeNoEncontrado
+	}
+	if m.RemitenteID() != userID {
+		destinatario, err := s.esDestinatario(ctx, userID, m)
+		if err != nil {
+			return nil, err
+		}
+		if !destinatario {
+			return nil, ErrAcuseSinAcceso
+		}
+	}
+
+	ahora := time.Now().UTC()
+	conversacionID := model.ConversacionMensaje(m, userID)
+	marcador, err := s.messages.FindReadMarker(ctx, userID, conversacionID)
+	if err != nil {
+		return nil, err
+	}
+	anterior := marcador
+	if marcador == nil {
+		if marcador, err = model.NewMarcadorLectura(userID, conversacionID, m, ahora); err != nil {
+			return nil, err
+		}
+	} else {
+		copia := *marcador
+		marcador = &copia
+		avanzado, err := marcador.Avanzar(m, ahora)
+		if err != nil {
+			return nil, err
+		}
+		if !avanzado {
+			return marcador, nil
+		}
+	}
+	if err := s.messages.SaveReadMarker(ctx, marcador); err != nil {
+		return nil, err
+	}
+
+	nuevos, err := s.marcarLeidos(ctx, userID, m, anterior, ahora)
+	if err != nil {
+		return nil, err
+	}
+	for remitenteID, acuses := range nuevos {
+		s.avisar(remitenteID, acuses)
+	}
+	return marcador, nil
+}
+
+// ListReceipts implementa ReceiptService
+func (s *receiptService) ListReceipts(userID, messageID uuid.UUID) ([]*model.AcuseMensaje, error) {
+	ctx := context.Background()
+	m, err := s.messages.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrAcuseMensajeNoEncontrado
+	}
+	if m.RemitenteID() != userID {
+		return nil, ErrAcuseNoRemitente
+	}
+
+	destinatarios, err := s.destinatarios(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	registrados, err := s.messages.FindReceipts(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	porUsuario := make(map[uuid.UUID]*model.AcuseMensaje, len(registrados))
+	for _, a := range registrados {
+		porUsuario[a.UsuarioID()] = a
+	}
+
+	acuses := make([]*model.AcuseMensaje, 0, len(destinatarios))
+	for _, id := range ordenarIDs(destinatarios) {
+		acuse, ok := porUsuario[id]
+		if !ok {
+			if acuse, err = model.NewAcuseMensaje(messageID, id, model.EntregaEnviado, m.Timestamp()); err != nil {
+				return nil, err
+			}
+		}
+		acuses = append(acuses, acuse)
+	}
+	return acuses, nil
+}
+
+// UnreadCounts implementa ReceiptService
+func (s *receiptService) UnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error) {
+	return s.messages.CountUnread(context.Background(), userID)
+}
+
+// destinatarios devuelve los usuarios que reciben el mensaje, sin su remitente: el
+// destinatario directo, los miembros del canal o los participantes del chat privado
+func (s *receiptService) destinatarios(ctx context.Context, m *model.MensajeServidor) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	switch {
+	case m.DestinoUsuarioID() != uuid.Nil:
+		return []uuid.UUID{m.DestinoUsuarioID()}, nil
+	case m.CanalID() != uuid.Nil:
+		miembros, err := s.channels.ListMembers(ctx, m.CanalID())
+		if err != nil {
+			return nil, err
+		}
+		ids = miembros
+	case m.ChatPrivadoID() != uuid.Nil:
+		participantes, err := s.messages.FindChatParticipants(ctx, m.ChatPrivadoID())
+		if err != nil {
+			return nil, err
+		}
+		ids = participantes
+	}
+
+	destinatarios := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id != m.RemitenteID() {
+			destinatarios = append(destinatarios, id)
+		}
+	}
+	return destinatarios, nil
+}
+
+// esDestinatario indica si el usuario recibe el mensaje
+func (s *receiptService) esDestinatario(ctx context.Context, userID uuid.UUID, m *model.MensajeServidor) (bool, error) {
+	destinatarios, err := s.destinatarios(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range destinatarios {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// avanzar lleva el acuse del destinatario al estado indicado y lo guarda. Devuelve nil
+// si ya estaba en ese estado o en uno posterior.
+func (s *receiptService) avanzar(ctx context.Context, m *model.MensajeServidor, userID uuid.UUID, estado model.EstadoEntrega, at time.Time) (*model.AcuseMensaje, error) {
+	acuse, err := s.messages.FindReceipt(ctx, m.ID(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if acuse == nil {
+		if acuse, err = model.NewAcuseMensaje(m.ID(), userID, estado, at); err != nil {
+			return nil, err
+		}
+	} else {
+		avanzado, err := acuse.Avanzar(estado, at)
+		if err != nil || !avanzado {
+			return nil, err
+		}
+	}
+	if err := s.messages.SaveReceipt(ctx, acuse); err != nil {
+		return nil, err
+	}
+	return acuse, nil
+}
+
+// marcarLeidos pasa a LEIDO los mensajes de otros usuarios de la conversación desde el
+// marcador anterior hasta hasta, incluido, recorriendo la conversación hacia atrás
+// como mucho maxAcusesLectura mensajes. Devuelve los acuses nuevos por remitente.
+func (s *receiptService) marcarLeidos(
+	ctx context.Context,
+	userID uuid.UUID,
+	hasta *model.MensajeServidor,
+	anterior *model.MarcadorLectura,
+	at time.Time,
+) (map[uuid.UUID][]*model.AcuseMensaje, error) {
+	nuevos := make(map[uuid.UUID][]*model.AcuseMensaje)
+	marcar := func(m *model.MensajeServidor) error {
+		if m.RemitenteID() == userID || m.Eliminado() {
+			return nil
+		}
+		acuse, err := s.avanzar(ctx, m, userID, model.EntregaLeido, at)
+		if acuse != nil {
+			nuevos[m.RemitenteID()] = append(nuevos[m.RemitenteID()], acuse)
+		}
+		return err
+	}
+	if err := marcar(hasta); err != nil {
+		return nil, err
+	}
+
+	cursor := model.NewCursor(hasta.Timestamp(), hasta.ID()).String()
+	for recorridos := 1; recorridos < maxAcusesLectura; {
+		consulta, err := model.NewConsultaPagina(cursor, model.PaginaAnteriores, maxAcusesLectura-recorridos)
+		if err != nil {
+			return nil, err
+		}
+		pagina, err := s.paginaConversacion(ctx, userID, hasta, consulta)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(pagina.Elementos) - 1; i >= 0; i-- {
+			m := pagina.Elementos[i]
+			if anterior != nil && anterior.Cubre(m) {
+				return nuevos, nil
+			}
+			if err := marcar(m); err != nil {
+				return nil, err
+			}
+			recorridos++
+		}
+		if !pagina.HayAnteriores {
+			break
+		}
+		cursor = pagina.Anterior
+	}
+	return nuevos, nil
+}
+
+// paginaConversacion devuelve una página de la conversación del mensaje vista por el
+// usuario
+func (s *receiptService) paginaConversacion(
+	ctx context.Context,
+	userID uuid.UUID,
+	m *model.MensajeServidor,
+	consulta model.ConsultaPagina,
+) (*model.Pagina[*model.MensajeServidor], error) {
+	switch {
+	case m.CanalID() != uuid.Nil:
+		return s.messages.FindByChannel(ctx, m.CanalID(), consulta)
+	case m.ChatPrivadoID() != uuid.Nil:
+		return s.messages.FindByPrivateChat(ctx, m.ChatPrivadoID(), consulta)
+	}
+	return s.messages.FindDirect(ctx, userID, model.ConversacionMensaje(m, userID), consulta)
+}
+
+// avisar envía los acuses nuevos al remitente: como evento a sus clientes si está
+// conectado a este nodo y enrutados hacia cada uno de los otros nodos en que lo está. Si
+// no está conectado en ningún sitio los verá al consultar los acuses del mensaje.
+func (s *receiptService) avisar(remitenteID uuid.UUID, acuses []*model.AcuseMensaje) {
+	registros := make([]registroAcuse, 0, len(acuses))
+	for _, a := range acuses {
+		registros = append(registros, aRegistroAcuse(a))
+	}
+
+	nodos := []uuid.UUID{s.nodoID}
+	if s.routing != nil {
+		nodos = s.routing.LocateUser(remitenteID)
+	}
+	for _, nodo := range nodos {
+		if nodo == s.nodoID {
+			s.notificar(remitenteID, registros)
+			continue
+		}
+		s.enviarHacia(&acusesEnrutados{
+			Origen:    s.nodoID,
+			Destino:   nodo,
+			Remitente: remitenteID,
+			TTL:       s.ttl,
+			Acuses:    registros,
+		})
+	}
+}
+
+// handleAcuses guarda y avisa los acuses dirigidos a un remitente conectado a este nodo
+// o los reenvía hacia su destino
+func (s *receiptService) handleAcuses(peerID uuid.UUID, payload []byte) {
+	var msg acusesEnrutados
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return
+	}
+	if msg.Destino != s.nodoID {
+		if msg.TTL > 1 {
+			msg.TTL--
+			s.enviarHacia(&msg)
+		}
+		return
+	}
+
+	ctx := context.Background()
+	aplicados := make([]registroAcuse, 0, len(msg.Acuses))
+	for _, registro := range msg.Acuses {
+		remoto, err := registro.aModelo()
+		if err != nil {
+			continue
+		}
+		m, err := s.messages.FindByID(ctx, remoto.MensajeID())
+		if err != nil {
+			continue
+		}
+		// Sin el mensaje en este nodo el acuse no se guarda, pero el remitente lo recibe
+		if m != nil {
+			acuse, err := s.avanzar(ctx, m, remoto.UsuarioID(), remoto.Estado(), remoto.ActualizadoAt())
+			if err != nil || acuse == nil {
+				continue
+			}
+		}
+		aplicados = append(aplicados, registro)
+	}
+	if len(aplicados) > 0 {
+		s.notificar(msg.Remitente, aplicados)
+	}
+}
+
+// enviarHacia envía los acuses al siguiente salto hacia su nodo destino, o directamente
+// si no hay ruta conocida
+func (s *receiptService) enviarHacia(msg *acusesEnrutados) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	siguiente := msg.Destino
+	if s.routing != nil {
+		if salto, ok := s.routing.RouteTable()[msg.Destino]; ok {
+			siguiente = salto
+		}
+	}
+	// Si el nodo no es alcanzable el remitente verá los acuses al consultarlos
+	_ = s.transport.SendTo(siguiente, FrameTipoAcuse, payload)
+}
+
+// notificar envía los acuses a los clientes del remitente conectados a este nodo
+func (s *receiptService) notificar(remitenteID uuid.UUID, acuses []registroAcuse) {
+	if s.clients == nil {
+		return
+	}
+	frame, err := codificarEventoCliente(EventoClienteAcuse, avisoAcuses{Acuses: acuses})
+	if err != nil {
+		return
+	}
+	_ = s.clients.Broadcast([]uuid.UUID{remitenteID}, frame)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// eventosAcuse devuelve los acuses recibidos como evento por un cliente
+func eventosAcuse(t *testing.T, c *clientesFalsos, id uuid.UUID) []registroAcuse {
+	t.Helper()
+	var acuses []registroAcuse
+	for _, aviso := range eventosCliente[avisoAcuses](t, c, id, EventoClienteAcuse) {
+		acuses = append(acuses, aviso.Acuses...)
+	}
+	return acuses
+}
+
+// estadosAcuse devuelve el estado de cada destinatario según ListReceipts
+func estadosAcuse(t *testing.T, s ReceiptService, remitenteID, mensajeID uuid.UUID) map[uuid.UUID]model.EstadoEntrega {
+	t.Helper()
+	acuses, err := s.ListReceipts(remitenteID, mensajeID)
+	if err != nil {
+		t.Fatalf("ListReceipts: %v", err)
+	}
+	estados := make(map[uuid.UUID]model.EstadoEntrega, len(acuses))
+	for _, a := range acuses {
+		estados[a.UsuarioID()] = a.Estado()
+	}
+	return estados
+}
+
+func TestReceipt_EntregaLecturaYNoLeidos(t *testing.T) {
+	ctx := context.Background()
+	canales, mensajes, clientes := nuevosCanalesFalsos(), nuevosMensajesFalsos(), nuevosClientesFalsos()
+	s := NewReceiptService(uuid.New(), nuevaRedFalsa().nodo(uuid.New()), clientes, nil, canales, mensajes)
+
+	ana, bob, eva, ajeno, canalID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{ana, bob, eva} {
+		canales.AddMember(ctx, canalID, id, "member")
+	}
+	ahora := time.Now().UTC()
+	var enCanal []*model.MensajeServidor
+	for i := 0; i < 3; i++ {
+		m, _ := model.NewMensajeCanal(uuid.New(), ana, canalID, "hola", ahora.Add(time.Duration(i)*time.Second), uuid.Nil)
+		mensajes.Save(ctx, m)
+		enCanal = append(enCanal, m)
+	}
+
+	if err := s.AcknowledgeDelivery(bob, []uuid.UUID{enCanal[0].ID(), uuid.New()}); err != nil {
+		t.Fatalf("AcknowledgeDelivery: %v", err)
+	}
+	// Los acuses de quien no es destinatario se ignoran
+	if err := s.AcknowledgeDelivery(ajeno, []uuid.UUID{enCanal[0].ID()}); err != nil {
+		t.Fatalf("AcknowledgeDelivery de un no miembro: %v", err)
+	}
+	estados := estadosAcuse(t, s, ana, enCanal[0].ID())
+	if len(estados) != 2 || estados[bob] != model.EntregaEntregado || estados[eva] != model.EntregaEnviado {
+		t.Errorf("estados inesperados tras la entrega: %v", estados)
+	}
+	if acuses := eventosAcuse(t, clientes, ana); len(acuses) != 1 || acuses[0].UsuarioID != bob || acuses[0].Estado != string(model.EntregaEntregado) {
+		t.Errorf("el remitente debe recibir el acuse de entrega, obtuvo %v", acuses)
+	}
+	if _, err := s.ListReceipts(bob, enCanal[0].ID()); err != ErrAcuseNoRemitente {
+		t.Errorf("acuses pedidos por un destinatario: esperado %v, obtuvo %v", ErrAcuseNoRemitente, err)
+	}
+
+	// Leer hasta el segundo mensaje marca como leídos el primero y el segundo
+	marcador, err := s.MarkRead(bob, enCanal[1].ID())
+	if err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if marcador.ConversacionID() != canalID || marcador.MensajeID() != enCanal[1].ID() {
+		t.Errorf("marcador inesperado: %v en %v", marcador.MensajeID(), marcador.ConversacionID())
+	}
+	for i, esperado := range []model.EstadoEntrega{model.EntregaLeido, model.EntregaLeido, model.EntregaEnviado} {
+		if got := estadosAcuse(t, s, ana, enCanal[i].ID())[bob]; got != esperado {
+			t.Errorf("mensaje %d: esperado %s, obtuvo %s", i, esperado, got)
+		}
+	}
+	if acuses := eventosAcuse(t, clientes, ana); len(acuses) != 3 {
+		t.Errorf("esperados 3 acuses para el remitente, obtuvo %d", len(acuses))
+	}
+
+	// Un informe de lectura anterior no hace retroceder el marcador
+	if marcador, err = s.MarkRead(bob, enCanal[0].ID()); err != nil || marcador.MensajeID() != enCanal[1].ID() {
+		t.Errorf("lectura anterior: err=%v marcador=%v", err, marcador.MensajeID())
+	}
+	if _, err := s.MarkRead(ajeno, enCanal[0].ID()); err != ErrAcuseSinAcceso {
+		t.Errorf("lectura de un no miembro: esperado %v, obtuvo %v", ErrAcuseSinAcceso, err)
+	}
+	if _, err := s.MarkRead(bob, uuid.New()); err != ErrAcuseMensajeNoEncontrado {
+		t.Errorf("mensaje inexistente: esperado %v, obtuvo %v", ErrAcuseMensajeNoEncontrado, err)
+	}
+
+	noLeidos, err := s.UnreadCounts(bob)
+	if err != nil {
+		t.Fatalf("UnreadCounts: %v", err)
+	}
+	if len(noLeidos) != 1 || noLeidos[canalID] != 1 {
+		t.Errorf("no leídos de bob: esperado 1 en el canal, obtuvo %v", noLeidos)
+	}
+	if noLeidos, _ := s.UnreadCounts(eva); noLeidos[canalID] != 3 {
+		t.Errorf("no leídos de eva: esperado 3 en el canal, obtuvo %v", noLeidos)
+	}
+}
+
+func TestReceipt_ChatPrivadoYDirecto(t *testing.T) {
+	ctx := context.Background()
+	mensajes := nuevosMensajesFalsos()
+	s := NewReceiptService(uuid.New(), nuevaRedFalsa().nodo(uuid.New()), nuevosClientesFalsos(), nil, nuevosCanalesFalsos(), mensajes)
+
+	ana, bob, ajeno, chatID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mensajes.chats[chatID] = []uuid.UUID{ana, bob}
+	ahora := time.Now().UTC()
+	enChat, _ := model.NewMensajeChatPrivado(uuid.New(), ana, chatID, "hola", ahora, uuid.Nil)
+	directo, _ := model.NewMensajeDirecto(uuid.New(), bob, ana, "hola ana", ahora, uuid.Nil)
+	mensajes.Save(ctx, enChat)
+	mensajes.Save(ctx, directo)
+
+	if estados := estadosAcuse(t, s, ana, enChat.ID()); len(estados) != 1 || estados[bob] != model.EntregaEnviado {
+		t.Errorf("el chat privado debe tener a bob como único destinatario, obtuvo %v", estados)
+	}
+	if _, err := s.MarkRead(ajeno, enChat.ID()); err != ErrAcuseSinAcceso {
+		t.Errorf("lectura de quien no participa: esperado %v, obtuvo %v", ErrAcuseSinAcceso, err)
+	}
+	if _, err := s.MarkRead(bob, enChat.ID()); err != nil {
+		t.Fatalf("MarkRead en el chat: %v", err)
+	}
+	if estados := estadosAcuse(t, s, ana, enChat.ID()); estados[bob] != model.EntregaLeido {
+		t.Errorf("esperado LEIDO en el chat, obtuvo %v", estados)
+	}
+
+	// En los directos la conversación de cada usuario es el otro
+	if noLeidos, _ := s.UnreadCounts(ana); len(noLeidos) != 1 || noLeidos[bob] != 1 {
+		t.Errorf("no leídos de ana: esperado 1 de bob, obtuvo %v", noLeidos)
+	}
+	marcador, err := s.MarkRead(ana, directo.ID())
+	if err != nil {
+		t.Fatalf("MarkRead en el directo: %v", err)
+	}
+	if marcador.ConversacionID() != bob {
+		t.Errorf("la conversación del directo para ana debe ser bob, obtuvo %v", marcador.ConversacionID())
+	}
+	if noLeidos, _ := s.UnreadCounts(ana); len(noLeidos) != 0 {
+		t.Errorf("ana no debe tener mensajes sin leer, obtuvo %v", noLeidos)
+	}
+	if estados := estadosAcuse(t, s, bob, directo.ID()); estados[ana] != model.EntregaLeido {
+		t.Errorf("esperado LEIDO en el directo, obtuvo %v", estados)
+	}
+}
+
+func TestReceipt_RemitenteEnOtroNodo(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	config := DefaultRoutingConfig()
+	config.IntervaloAnuncio = 20 * time.Millisecond
+	config.IntervaloReintento = 10 * time.Millisecond
+
+	// Cadena A - B - C: los acuses de C llegan a A a través de B
+	a := nuevoNodoRuteo(red, canales, config)
+	b := nuevoNodoRuteo(red, canales, config)
+	c := nuevoNodoRuteo(red, canales, config)
+	red.enlazar(a.id, b.id)
+	red.enlazar(b.id, c.id)
+	acuses := make(map[uuid.UUID]ReceiptService)
+	for _, n := range []*nodoRuteo{a, b, c} {
+		acuses[n.id] = NewReceiptService(n.id, n.transporte, n.clientes, n.servicio, n.canales, n.mensajes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, n := range []*nodoRuteo{a, b, c} {
+		n.servicio.Start(ctx)
+	}
+
+	ana, bob := uuid.New(), uuid.New()
+	conectar(t, a, ana, c)
+	conectar(t, c, bob, a)
+	esperarHasta(t, func() bool { return c.servicio.RouteTable()[a.id] == b.id })
+
+	m, _ := model.NewMensajeDirecto(uuid.New(), ana, bob, "hola desde lejos", time.Now().UTC(), uuid.Nil)
+	a.mensajes.Save(ctx, m)
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	esperarHasta(t, func() bool { return c.clientes.recibidos(bob) == 1 })
+
+	if err := acuses[c.id].AcknowledgeDelivery(bob, []uuid.UUID{m.ID()}); err != nil {
+		t.Fatalf("AcknowledgeDelivery: %v", err)
+	}
+	esperarHasta(t, func() bool { return len(eventosAcuse(t, a.clientes, ana)) == 1 })
+	if _, err := acuses[c.id].MarkRead(bob, m.ID()); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	esperarHasta(t, func() bool { return len(eventosAcuse(t, a.clientes, ana)) == 2 })
+
+	// El nodo del remitente guarda los acuses: ListReceipts responde desde A
+	if estados := estadosAcuse(t, acuses[a.id], ana, m.ID()); estados[bob] != model.EntregaLeido {
+		t.Errorf("esperado LEIDO en el nodo del remitente, obtuvo %v", estados)
+	}
+	if recibidos := eventosAcuse(t, b.clientes, ana); len(recibidos) != 0 {
+		t.Errorf("el nodo intermedio no debe avisar a nadie, obtuvo %v", recibidos)
+	}
+}
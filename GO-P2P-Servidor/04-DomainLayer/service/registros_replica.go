@@ -0,0 +1,405 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// Representaciones serializables de las entidades replicadas entre nodos.
+// Las entidades del dominio tienen campos privados, así que viajan por la red
+// con estos registros y se reconstruyen con sus constructores validados.
+
+// registroUsuario es la forma serializable de un UsuarioServidor
+type registroUsuario struct {
+	ID             uuid.UUID `json:"id"`
+	NombreUsuario  string    `json:"nombreUsuario"`
+	Email          string    `json:"email"`
+	ContrasenaHash string    `json:"contrasenaHash"`
+	FotoURL        string    `json:"fotoUrl"`
+	IPRegistrada   string    `json:"ipRegistrada"`
+	FechaRegistro  time.Time `json:"fechaRegistro"`
+	Version        string    `json:"version"`
+}
+
+// registroCanal es la forma serializable de un CanalServidor
+type registroCanal struct {
+	ID          uuid.UUID `json:"id"`
+	Nombre      string    `json:"nombre"`
+	Descripcion string    `json:"descripcion"`
+	Tipo        string    `json:"tipo"`
+	Version     string    `json:"version"`
+}
+
+// registroMiembro es la forma serializable de un CanalMiembro
+type registroMiembro struct {
+	CanalID   uuid.UUID `json:"canalId"`
+	UsuarioID uuid.UUID `json:"usuarioId"`
+	Rol       string    `json:"rol"`
+	Version   string    `json:"version"`
+}
+
+// registroMensaje es la forma serializable de un MensajeServidor
+type registroMensaje struct {
+	ID               uuid.UUID `json:"id"`
+	RemitenteID      uuid.UUID `json:"remitenteId"`
+	DestinoUsuarioID uuid.UUID `json:"destinoUsuarioId"`
+	CanalID          uuid.UUID `json:"canalId"`
+	ChatPrivadoID    uuid.UUID `json:"chatPrivadoId"`
+	Contenido        string    `json:"contenido"`
+	Timestamp        time.Time `json:"timestamp"`
+	ArchivoID        uuid.UUID `json:"archivoId"`
+	Version          string    `json:"version"`
+	EditadoAt        time.Time `json:"editadoAt"`
+	EliminadoAt      time.Time `json:"eliminadoAt"`
+	PadreID          uuid.UUID `json:"padreId"`
+}
+
+// registroEdicion es la forma serializable de una EdicionMensaje
+type registroEdicion struct {
+	ID                uuid.UUID `json:"id"`
+	MensajeID         uuid.UUID `json:"mensajeId"`
+	EditorID          uuid.UUID `json:"editorId"`
+	Tipo              string    `json:"tipo"`
+	ContenidoAnterior string    `json:"contenidoAnterior"`
+	Fecha             time.Time `json:"fecha"`
+	Version           string    `json:"version"`
+}
+
+// registroReaccion es la forma serializable de una ReaccionMensaje
+type registroReaccion struct {
+	MensajeID  uuid.UUID `json:"mensajeId"`
+	UsuarioID  uuid.UUID `json:"usuarioId"`
+	Emoji      string    `json:"emoji"`
+	Fecha      time.Time `json:"fecha"`
+	RetiradaAt time.Time `json:"retiradaAt"`
+	Version    string    `json:"version"`
+}
+
+// registroAcuse es la forma serializable de un AcuseMensaje
+type registroAcuse struct {
+	MensajeID     uuid.UUID `json:"mensajeId"`
+	UsuarioID     uuid.UUID `json:"usuarioId"`
+	Estado        string    `json:"estado"`
+	ActualizadoAt time.Time `json:"actualizadoAt"`
+}
+
+// registroProgramado es la forma serializable de un MensajeProgramado
+type registroProgramado struct {
+	ID               uuid.UUID `json:"id"`
+	NodoID           uuid.UUID `json:"nodoId"`
+	RemitenteID      uuid.UUID `json:"remitenteId"`
+	DestinoUsuarioID uuid.UUID `json:"destinoUsuarioId"`
+	CanalID          uuid.UUID `json:"canalId"`
+	Contenido        string    `json:"contenido"`
+	ArchivoID        uuid.UUID `json:"archivoId"`
+	EnviarEn         time.Time `json:"enviarEn"`
+	DuracionSegundos int64     `json:"duracionSegundos"`
+	Estado           string    `json:"estado"`
+	MensajeID        uuid.UUID `json:"mensajeId"`
+	ExpiraEn         time.Time `json:"expiraEn"`
+}
+
+// registroMencion es la forma serializable de una Mencion
+type registroMencion struct {
+	ID             uuid.UUID `json:"id"`
+	UsuarioID      uuid.UUID `json:"usuarioId"`
+	MensajeID      uuid.UUID `json:"mensajeId"`
+	CanalID        uuid.UUID `json:"canalId"`
+	RemitenteID    uuid.UUID `json:"remitenteId"`
+	Tipo           string    `json:"tipo"`
+	Fecha          time.Time `json:"fecha"`
+	NotificacionID uuid.UUID `json:"notificacionId"`
+}
+
+// registroSilencio es la forma serializable de un SilencioMenciones
+type registroSilencio struct {
+	UsuarioID   uuid.UUID `json:"usuarioId"`
+	CanalID     uuid.UUID `json:"canalId"`
+	Nivel       string    `json:"nivel"`
+	Hasta       time.Time `json:"hasta"`
+	Actualizado time.Time `json:"actualizado"`
+}
+
+// registroFijado es la forma serializable de un FijadoCanal
+type registroFijado struct {
+	ID          uuid.UUID `json:"id"`
+	CanalID     uuid.UUID `json:"canalId"`
+	MensajeID   uuid.UUID `json:"mensajeId"`
+	URL         string    `json:"url"`
+	Titulo      string    `json:"titulo"`
+	FijadoPor   uuid.UUID `json:"fijadoPor"`
+	FijadoEn    time.Time `json:"fijadoEn"`
+	RetiradoPor uuid.UUID `json:"retiradoPor"`
+	RetiradoEn  time.Time `json:"retiradoEn"`
+	Version     string    `json:"version"`
+}
+
+func aRegistroUsuario(u *model.UsuarioServidor) registroUsuario {
+	return registroUsuario{
+		ID:             u.ID(),
+		NombreUsuario:  u.NombreUsuario(),
+		Email:          u.Email(),
+		ContrasenaHash: u.ContrasenaHasheada(),
+		FotoURL:        u.FotoURL(),
+		IPRegistrada:   u.IPRegistrada(),
+		FechaRegistro:  u.FechaRegistro(),
+		Version:        u.Version().String(),
+	}
+}
+
+func (r registroUsuario) aModelo() (*model.UsuarioServidor, error) {
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return nil, err
+	}
+	u, err := model.NewUsuarioServidor(r.ID, r.NombreUsuario, r.Email, r.ContrasenaHash, r.FotoURL, r.IPRegistrada, r.FechaRegistro)
+	if err != nil {
+		return nil, err
+	}
+	u.SetVersion(version)
+	return u, nil
+}
+
+func aRegistroCanal(c *model.CanalServidor) registroCanal {
+	return registroCanal{
+		ID:          c.ID(),
+		Nombre:      c.Nombre(),
+		Descripcion: c.Descripcion(),
+		Tipo:        string(c.Tipo()),
+		Version:     c.Version().String(),
+	}
+}
+
+func (r registroCanal) aModelo() (*model.CanalServidor, error) {
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return nil, err
+	}
+	c, err := model.NewCanalServidor(r.ID, r.Nombre, r.Descripcion, model.CanalTipo(r.Tipo))
+	if err != nil {
+		return nil, err
+	}
+	c.SetVersion(version)
+	return c, nil
+}
+
+func aRegistroMiembro(m *model.CanalMiembro) registroMiembro {
+	return registroMiembro{
+		CanalID:   m.CanalID(),
+		UsuarioID: m.UsuarioID(),
+		Rol:       m.Rol(),
+		Version:   m.Version().String(),
+	}
+}
+
+func (r registroMiembro) aModelo() (*model.CanalMiembro, error) {
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return nil, err
+	}
+	m, err := model.NewCanalMiembro(r.CanalID, r.UsuarioID, r.Rol)
+	if err != nil {
+		return nil, err
+	}
+	m.SetVersion(version)
+	return m, nil
+}
+
+func aRegistroMensaje(m *model.MensajeServidor) registroMensaje {
+	return registroMensaje{
+		ID:               m.ID(),
+		RemitenteID:      m.RemitenteID(),
+		DestinoUsuarioID: m.DestinoUsuarioID(),
+		CanalID:          m.CanalID(),
+		ChatPrivadoID:    m.ChatPrivadoID(),
+		Contenido:        m.Contenido(),
+		Timestamp:        m.Timestamp(),
+		ArchivoID:        m.ArchivoID(),
+		Version:          m.Version().String(),
+		EditadoAt:        m.EditadoAt(),
+		EliminadoAt:      m.EliminadoAt(),
+		PadreID:          m.PadreID(),
+	}
+}
+
+func (r registroMensaje) aModelo() (*model.MensajeServidor, error) {
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *model.MensajeServidor
+	switch {
+	case r.ChatPrivadoID != uuid.Nil:
+		m, err = model.NewMensajeChatPrivado(r.ID, r.RemitenteID, r.ChatPrivadoID, r.Contenido, r.Timestamp, r.ArchivoID)
+	case r.CanalID != uuid.Nil:
+		m, err = model.NewMensajeCanal(r.ID, r.RemitenteID, r.CanalID, r.Contenido, r.Timestamp, r.ArchivoID)
+	default:
+		m, err = model.NewMensajeDirecto(r.ID, r.RemitenteID, r.DestinoUsuarioID, r.Contenido, r.Timestamp, r.ArchivoID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.SetVersion(version)
+	m.SetEdicion(r.EditadoAt, r.EliminadoAt)
+	m.SetPadreID(r.PadreID)
+	return m, nil
+}
+
+func aRegistroEdicion(e *model.EdicionMensaje) registroEdicion {
+	return registroEdicion{
+		ID:                e.ID(),
+		MensajeID:         e.MensajeID(),
+		EditorID:          e.EditorID(),
+		Tipo:              string(e.Tipo()),
+		ContenidoAnterior: e.ContenidoAnterior(),
+		Fecha:             e.Fecha(),
+		Version:           e.Version().String(),
+	}
+}
+
+func (r registroEdicion) aModelo() (*model.EdicionMensaje, error) {
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return nil, err
+	}
+	e, err := model.NewEdicionMensaje(r.ID, r.MensajeID, r.EditorID, model.TipoEdicion(r.Tipo), r.ContenidoAnterior, r.Fecha)
+	if err != nil {
+		return nil, err
+	}
+	e.SetVersion(version)
+	return e, nil
+}
+
+func aRegistroReaccion(r *model.ReaccionMensaje) registroReaccion {
+	return registroReaccion{
+		MensajeID:  r.MensajeID(),
+		UsuarioID:  r.UsuarioID(),
+		Emoji:      r.Emoji(),
+		Fecha:      r.Fecha(),
+		RetiradaAt: r.RetiradaAt(),
+		Version:    r.Version().String(),
+	}
+}
+
+func (r registroReaccion) aModelo() (*model.ReaccionMensaje, error) {
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return nil, err
+	}
+	reaccion, err := model.NewReaccionMensaje(r.MensajeID, r.UsuarioID, r.Emoji, r.Fecha)
+	if err != nil {
+		return nil, err
+	}
+	reaccion.SetRetiradaAt(r.RetiradaAt)
+	reaccion.SetVersion(version)
+	return reaccion, nil
+}
+
+func aRegistroAcuse(a *model.AcuseMensaje) registroAcuse {
+	return registroAcuse{
+		MensajeID:     a.MensajeID(),
+		UsuarioID:     a.UsuarioID(),
+		Estado:        string(a.Estado()),
+		ActualizadoAt: a.ActualizadoAt(),
+	}
+}
+
+func (r registroAcuse) aModelo() (*model.AcuseMensaje, error) {
+	return model.NewAcuseMensaje(r.MensajeID, r.UsuarioID, model.EstadoEntrega(r.Estado), r.ActualizadoAt)
+}
+
+func aRegistroProgramado(p *model.MensajeProgramado) registroProgramado {
+	return registroProgramado{
+		ID:               p.ID(),
+		NodoID:           p.NodoID(),
+		RemitenteID:      p.RemitenteID(),
+		DestinoUsuarioID: p.DestinoUsuarioID(),
+		CanalID:          p.CanalID(),
+		Contenido:        p.Contenido(),
+		ArchivoID:        p.ArchivoID(),
+		EnviarEn:         p.EnviarEn(),
+		DuracionSegundos: int64(p.Duracion() / time.Second),
+		Estado:           string(p.Estado()),
+		MensajeID:        p.MensajeID(),
+		ExpiraEn:         p.ExpiraEn(),
+	}
+}
+
+func (r registroProgramado) aModelo() (*model.MensajeProgramado, error) {
+	p, err := model.NewMensajeProgramado(
+		r.ID, r.NodoID, r.RemitenteID, r.DestinoUsuarioID, r.CanalID,
+		r.Contenido, r.ArchivoID, r.EnviarEn, time.Duration(r.DuracionSegundos)*time.Second,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.SetEstado(model.EstadoProgramado(r.Estado), r.MensajeID, r.ExpiraEn); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func aRegistroMencion(m *model.Mencion) registroMencion {
+	return registroMencion{
+		ID:             m.ID(),
+		UsuarioID:      m.UsuarioID(),
+		MensajeID:      m.MensajeID(),
+		CanalID:        m.CanalID(),
+		RemitenteID:    m.RemitenteID(),
+		Tipo:           string(m.Tipo()),
+		Fecha:          m.Fecha(),
+		NotificacionID: m.NotificacionID(),
+	}
+}
+
+func (r registroMencion) aModelo() (*model.Mencion, error) {
+	return model.NewMencion(r.ID, r.UsuarioID, r.MensajeID, r.CanalID, r.RemitenteID, model.TipoMencion(r.Tipo), r.Fecha, r.NotificacionID)
+}
+
+func aRegistroSilencio(s *model.SilencioMenciones) registroSilencio {
+	return registroSilencio{
+		UsuarioID:   s.UsuarioID(),
+		CanalID:     s.CanalID(),
+		Nivel:       string(s.Nivel()),
+		Hasta:       s.Hasta(),
+		Actualizado: s.Actualizado(),
+	}
+}
+
+func (r registroSilencio) aModelo() (*model.SilencioMenciones, error) {
+	return model.NewSilencioMenciones(r.UsuarioID, r.CanalID, model.NivelSilencio(r.Nivel), r.Hasta, r.Actualizado)
+}
+
+func aRegistroFijado(f *model.FijadoCanal) registroFijado {
+	return registroFijado{
+		ID:          f.ID(),
+		CanalID:     f.CanalID(),
+		MensajeID:   f.MensajeID(),
+		URL:         f.URL(),
+		Titulo:      f.Titulo(),
+		FijadoPor:   f.FijadoPor(),
+		FijadoEn:    f.FijadoEn(),
+		RetiradoPor: f.RetiradoPor(),
+		RetiradoEn:  f.RetiradoEn(),
+		Version:     f.Version().String(),
+	}
+}
+
+// aModelo reconstruye el elemento fijado; el id se deriva del canal y del elemento, así
+// que el del registro solo informa a los clientes
+func (r registroFijado) aModelo() (*model.FijadoCanal, error) {
+	version, err := model.ParseHLC(r.Version)
+	if err != nil {
+		return nil, err
+	}
+	fijado, err := model.NewFijadoCanal(r.CanalID, r.MensajeID, r.URL, r.Titulo, r.FijadoPor, r.FijadoEn)
+	if err != nil {
+		return nil, err
+	}
+	fijado.SetRetirada(r.RetiradoPor, r.RetiradoEn)
+	fijado.SetVersion(version)
+	return fijado, nil
+}
@@ -1,16 +1,684 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/google/uuid"
 	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de enrutamiento
+var (
+	ErrRutaMensajeNil  = errors.New("mensaje a enrutar inválido")
+	ErrRutaNoSoportada = errors.New("los mensajes de chat privado no se enrutan entre nodos")
+	ErrRutaColaLlena   = errors.New("cola de reenvío del nodo destino llena")
+	ErrRutaTTLAgotado  = errors.New("el mensaje agotó su TTL antes de llegar a destino")
 )
 
-// RoutingService define las operaciones para enrutamiento de mensajes entre nodos P2P
+// RoutingConfig contiene los parámetros del enrutamiento entre nodos
+type RoutingConfig struct {
+	IntervaloAnuncio     time.Duration // Periodo entre anuncios completos de adyacencia
+	ExpiracionAdyacencia time.Duration // Tiempo sin anuncios tras el que se olvida un enlace
+	IntervaloReintento   time.Duration // Periodo entre reintentos de envíos pendientes
+	MaxReintentos        int           // Reintentos antes de dejar un envío pendiente en la cola de sus destinatarios
+	MaxCola              int           // Envíos pendientes máximos por siguiente salto
+	TTL                  int           // Saltos máximos de un mensaje o anuncio
+	ExpiracionCache      time.Duration // Tiempo que se recuerdan los mensajes ya procesados
+}
+
+// DefaultRoutingConfig devuelve la configuración por defecto
+func DefaultRoutingConfig() RoutingConfig {
+	return RoutingConfig{
+		IntervaloAnuncio:     30 * time.Second,
+		ExpiracionAdyacencia: 90 * time.Second,
+		IntervaloReintento:   5 * time.Second,
+		MaxReintentos:        12,
+		MaxCola:              1000,
+		TTL:                  8,
+		ExpiracionCache:      10 * time.Minute,
+	}
+}
+
+// RoutingService define las operaciones para enrutamiento de mensajes entre nodos P2P.
+// Ubica a los destinatarios con el directorio de sesiones de PresenceService y mantiene
+// una tabla de rutas calculada a partir de las adyacencias que anuncian los peers, de
+// modo que un mensaje puede atravesar nodos intermedios hasta el nodo del destinatario.
 type RoutingService interface {
-	// RouteMessage enruta un mensaje al nodo correspondiente si el destinatario 
+	// RouteMessage enruta un mensaje al nodo correspondiente si el destinatario
 	// no está en el mismo servidor
 	RouteMessage(message *model.MensajeServidor) error
-	
-	// ListRoutes lista las rutas para un mensaje específico
+
+	// ListRoutes lista los saltos recorridos por un mensaje, en orden de envío
 	ListRoutes(messageID uuid.UUID) ([]*model.RoutedMessage, error)
+
+	// Start lanza los anuncios de adyacencia y los reintentos hasta que ctx termine
+	Start(ctx context.Context)
+
+	// LocateUser devuelve los nodos en los que el usuario está conectado según PresenceService
+	LocateUser(userID uuid.UUID) []uuid.UUID
+
+	// RouteTable devuelve el siguiente salto hacia cada nodo alcanzable
+	RouteTable() map[uuid.UUID]uuid.UUID
+}
+
+// salto registra el paso de un mensaje por un nodo
+type salto struct {
+	Nodo uuid.UUID `json:"nodo"`
+	At   time.Time `json:"at"`
+}
+
+// mensajeAdyacencia es el payload JSON de los frames FrameTipoAdyacencia.
+// Solo se acepta un anuncio de un origen si su secuencia supera a la conocida.
+type mensajeAdyacencia struct {
+	Origen    uuid.UUID   `json:"origen"`
+	Secuencia int64       `json:"secuencia"`
+	TTL       int         `json:"ttl"`
+	Vecinos   []uuid.UUID `json:"vecinos"`
+}
+
+// mensajeEnrutado es el payload JSON de los frames FrameTipoMensajeEnrutado.
+// Saltos es la lista de nodos visitados; TTL los saltos que aún puede dar.
+type mensajeEnrutado struct {
+	Mensaje       registroMensaje `json:"mensaje"`
+	Destinatarios []uuid.UUID     `json:"destinatarios"`
+	Origen        uuid.UUID       `json:"origen"`
+	TTL           int             `json:"ttl"`
+	Saltos        []salto         `json:"saltos"`
+}
+
+// mensajeRutaConfirmada es el payload JSON de los frames FrameTipoRutaConfirmada:
+// el nodo que entrega el mensaje devuelve al origen el camino recorrido
+type mensajeRutaConfirmada struct {
+	MensajeID uuid.UUID `json:"mensajeId"`
+	Origen    uuid.UUID `json:"origen"`
+	TTL       int       `json:"ttl"`
+	Saltos    []salto   `json:"saltos"`
+}
+
+// envioPendiente es un reenvío en curso o a la espera de un reintento
+type envioPendiente struct {
+	mensaje       *model.MensajeServidor
+	destinatarios []uuid.UUID
+	origen        uuid.UUID
+	ttl           int
+	saltos        []salto
+	intentos      int
+}
+
+// adyacencia es el último anuncio de vecinos recibido de un nodo
+type adyacencia struct {
+	secuencia int64
+	vecinos   []uuid.UUID
+	recibido  time.Time
+}
+
+// routingService implementa RoutingService sobre PeerTransport y ClientTransport
+type routingService struct {
+	nodoID    uuid.UUID
+	transport PeerTransport
+	clients   ClientTransport
+	channels  repository.IChannelRepository
+	messages  repository.IMessageRepository
+	routes    repository.IRoutedMessageRepository
+	presence  PresenceService
+	config    RoutingConfig
+
+	mu             sync.Mutex
+	pendientes     map[uuid.UUID][]*envioPendiente // siguiente salto → envíos pendientes
+	topologia      map[uuid.UUID]*adyacencia       // nodo → vecinos anunciados
+	tablaRutas     map[uuid.UUID]uuid.UUID         // nodo destino → siguiente salto
+	vecinos        map[uuid.UUID]bool              // vecinos incluidos en el último anuncio propio
+	secuencia      int64                           // secuencia del último anuncio de adyacencia propio
+	mensajesVistos map[uuid.UUID]*entregaVista     // mensajes ya procesados por este nodo
+}
+
+// entregaVista recuerda a qué destinatarios de un mensaje ya se atendió en este nodo
+type entregaVista struct {
+	visto    time.Time
+	usuarios map[uuid.UUID]bool
+}
+
+// NewRoutingService crea el servicio y registra sus handlers de frames en el transporte.
+// presence es el directorio de sesiones con el que se ubica a los destinatarios.
+func NewRoutingService(
+	nodoID uuid.UUID,
+	transport PeerTransport,
+	clients ClientTransport,
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	routes repository.IRoutedMessageRepository,
+	presence PresenceService,
+	config RoutingConfig,
+) RoutingService {
+	s := &routingService{
+		nodoID:         nodoID,
+		transport:      transport,
+		clients:        clients,
+		channels:       channels,
+		messages:       messages,
+		routes:         routes,
+		presence:       presence,
+		config:         config,
+		pendientes:     make(map[uuid.UUID][]*envioPendiente),
+		topologia:      make(map[uuid.UUID]*adyacencia),
+		tablaRutas:     make(map[uuid.UUID]uuid.UUID),
+		vecinos:        make(map[uuid.UUID]bool),
+		mensajesVistos: make(map[uuid.UUID]*entregaVista),
+	}
+	transport.SetFrameHandler(FrameTipoAdyacencia, s.handleAdyacencia)
+	transport.SetFrameHandler(FrameTipoMensajeEnrutado, s.handleMensaje)
+	transport.SetFrameHandler(FrameTipoRutaConfirmada, s.handleRutaConfirmada)
+	return s
+}
+
+// Start implementa RoutingService
+func (s *routingService) Start(ctx context.Context) {
+	go func() {
+		anuncio := time.NewTicker(s.config.IntervaloAnuncio)
+		reintento := time.NewTicker(s.config.IntervaloReintento)
+		defer anuncio.Stop()
+		defer reintento.Stop()
+
+		s.anunciarAdyacencia()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-anuncio.C:
+				s.anunciarAdyacencia()
+				s.expirar()
+			case <-reintento.C:
+				if s.vecinosCambiaron() {
+					s.anunciarAdyacencia()
+				}
+				s.reintentarPendientes()
+			}
+		}
+	}()
+}
+
+// LocateUser implementa RoutingService
+func (s *routingService) LocateUser(userID uuid.UUID) []uuid.UUID {
+	return s.presence.LocateUser(userID)
+}
+
+// RouteTable implementa RoutingService
+func (s *routingService) RouteTable() map[uuid.UUID]uuid.UUID {
+	vecinos := s.transport.GetAllPeerIDs()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recalcularRutasLocked(vecinos)
+
+	tabla := make(map[uuid.UUID]uuid.UUID, len(s.tablaRutas))
+	for destino, siguiente := range s.tablaRutas {
+		tabla[destino] = siguiente
+	}
+	return tabla
+}
+
+// RouteMessage implementa RoutingService
+func (s *routingService) RouteMessage(message *model.MensajeServidor) error {
+	if message == nil {
+		return ErrRutaMensajeNil
+	}
+	destinatarios, err := s.destinatarios(message)
+	if err != nil {
+		return err
+	}
+	// Enrutar dos veces el mismo mensaje no lo entrega de nuevo
+	destinatarios = s.marcarEntregados(message.ID(), destinatarios)
+	if len(destinatarios) == 0 {
+		return nil
+	}
+
+	return s.entregar(&envioPendiente{
+		mensaje:       message,
+		destinatarios: destinatarios,
+		origen:        s.nodoID,
+		ttl:           s.config.TTL,
+		saltos:        []salto{{Nodo: s.nodoID, At: time.Now().UTC()}},
+	})
+}
+
+// ListRoutes implementa RoutingService
+func (s *routingService) ListRoutes(messageID uuid.UUID) ([]*model.RoutedMessage, error) {
+	rutas, err := s.routes.FindByMessage(context.Background(), messageID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rutas, func(i, j int) bool { return rutas[i].EnrutaAt().Before(rutas[j].EnrutaAt()) })
+	return rutas, nil
+}
+
+// destinatarios devuelve los usuarios que deben recibir el mensaje
+func (s *routingService) destinatarios(m *model.MensajeServidor) ([]uuid.UUID, error) {
+	switch {
+	case m.DestinoUsuarioID() != uuid.Nil:
+		return []uuid.UUID{m.DestinoUsuarioID()}, nil
+	case m.CanalID() != uuid.Nil:
+		miembros, err := s.channels.ListMembers(context.Background(), m.CanalID())
+		if err != nil {
+			return nil, err
+		}
+		var ids []uuid.UUID
+		for _, id := range miembros {
+			if id != m.RemitenteID() {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+	return nil, ErrRutaNoSoportada
+}
+
+// entregar reparte un envío entre los clientes locales y los vecinos por los que
+// se alcanzan los nodos de los demás destinatarios. Los destinatarios sin ubicación
+// conocida están desconectados: se entregan también a ClientTransport, que los deja
+// en su cola de pendientes si es un OfflineQueueService.
+func (s *routingService) entregar(envio *envioPendiente) error {
+	locales, sinUbicar, porSalto := s.agrupar(envio)
+
+	if len(locales) > 0 || len(sinUbicar) > 0 {
+		// Un fallo de socket no impide el reenvío; el cliente lo verá en el historial
+		_ = s.entregarLocal(envio.mensaje, append(locales, sinUbicar...))
+	}
+	if len(locales) > 0 && envio.origen != s.nodoID {
+		s.confirmarRuta(envio)
+	}
+	if len(porSalto) == 0 {
+		return nil
+	}
+	if envio.ttl <= 0 {
+		return ErrRutaTTLAgotado
+	}
+
+	var errs []error
+	for siguiente, ids := range porSalto {
+		if err := s.reenviar(siguiente, envio, ids); err != nil {
+			errs = append(errs, s.encolar(siguiente, &envioPendiente{
+				mensaje:       envio.mensaje,
+				destinatarios: ids,
+				origen:        envio.origen,
+				ttl:           envio.ttl,
+				saltos:        envio.saltos,
+				intentos:      envio.intentos,
+			}))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// agrupar separa los destinatarios locales y los que no están conectados en ningún
+// nodo y agrupa los remotos por siguiente salto. Nunca devuelve un nodo ya visitado, lo
+// que evita bucles en la red.
+func (s *routingService) agrupar(envio *envioPendiente) ([]uuid.UUID, []uuid.UUID, map[uuid.UUID][]uuid.UUID) {
+	visitados := make(map[uuid.UUID]bool, len(envio.saltos))
+	for _, sl := range envio.saltos {
+		visitados[sl.Nodo] = true
+	}
+	ubicaciones := make(map[uuid.UUID][]uuid.UUID, len(envio.destinatarios))
+	for _, id := range envio.destinatarios {
+		ubicaciones[id] = s.presence.LocateUser(id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var locales, sinUbicar []uuid.UUID
+	porSalto := make(map[uuid.UUID][]uuid.UUID)
+	for _, id := range envio.destinatarios {
+		nodos := ubicaciones[id]
+		if len(nodos) == 0 {
+			sinUbicar = append(sinUbicar, id)
+			continue
+		}
+		asignado := make(map[uuid.UUID]bool)
+		for _, nodo := range nodos {
+			if nodo == s.nodoID {
+				locales = append(locales, id)
+				continue
+			}
+			if visitados[nodo] {
+				continue
+			}
+			siguiente, ok := s.tablaRutas[nodo]
+			if !ok {
+				// Sin ruta conocida se intenta el envío directo; si falla queda en cola
+				siguiente = nodo
+			}
+			if visitados[siguiente] || asignado[siguiente] {
+				continue
+			}
+			asignado[siguiente] = true
+			porSalto[siguiente] = append(porSalto[siguiente], id)
+		}
+	}
+	return locales, sinUbicar, porSalto
+}
+
+// entregarLocal envía el mensaje a los clientes conectados a este nodo
+func (s *routingService) entregarLocal(m *model.MensajeServidor, ids []uuid.UUID) error {
+	frame, err := codificarEventoCliente(EventoClienteMensaje, aRegistroMensaje(m))
+	if err != nil {
+		return err
+	}
+	return s.clients.Broadcast(ids, frame)
+}
+
+// reenviar envía el mensaje al siguiente salto y registra el salto
+func (s *routingService) reenviar(siguiente uuid.UUID, envio *envioPendiente, ids []uuid.UUID) error {
+	payload, err := json.Marshal(mensajeEnrutado{
+		Mensaje:       aRegistroMensaje(envio.mensaje),
+		Destinatarios: ids,
+		Origen:        envio.origen,
+		TTL:           envio.ttl - 1,
+		Saltos:        envio.saltos,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.transport.SendTo(siguiente, FrameTipoMensajeEnrutado, payload); err != nil {
+		return err
+	}
+
+	var ruta *model.RoutedMessage
+	if envio.origen == s.nodoID {
+		ruta, err = model.NewRoutedMessage(envio.mensaje.ID(), siguiente, time.Now().UTC())
+	} else {
+		ruta, err = model.NewRoutedMessageSalto(envio.mensaje.ID(), s.nodoID, siguiente, time.Now().UTC())
+	}
+	if err != nil {
+		return err
+	}
+	// El mensaje ya salió; un fallo al registrar el salto no debe provocar un reenvío
+	_ = s.routes.Save(context.Background(), ruta)
+	return nil
+}
+
+// encolar guarda un envío fallido para reintentarlo más tarde
+func (s *routingService) encolar(siguiente uuid.UUID, envio *envioPendiente) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pendientes[siguiente]) >= s.config.MaxCola {
+		return ErrRutaColaLlena
+	}
+	s.pendientes[siguiente] = append(s.pendientes[siguiente], envio)
+	return nil
+}
+
+// reintentarPendientes vuelve a enrutar los envíos pendientes según la ubicación actual
+// de sus destinatarios, que pueden haberse reconectado en otro nodo
+func (s *routingService) reintentarPendientes() {
+	s.mu.Lock()
+	pendientes := s.pendientes
+	s.pendientes = make(map[uuid.UUID][]*envioPendiente)
+	s.mu.Unlock()
+
+	// Se respeta el orden de envío dentro de cada siguiente salto
+	for _, cola := range pendientes {
+		for _, envio := range cola {
+			envio.intentos++
+			if envio.intentos > s.config.MaxReintentos {
+				s.abandonar(envio)
+				continue
+			}
+			_ = s.entregar(envio)
+		}
+	}
+}
+
+// abandonar deja un envío que agotó sus reintentos en la cola de pendientes de sus
+// destinatarios, que lo recuperan con Replay al volver a entrar en cualquier nodo. Sin
+// OfflineQueueService el mensaje solo queda en el historial.
+func (s *routingService) abandonar(envio *envioPendiente) {
+	cola, ok := s.clients.(OfflineQueueService)
+	if !ok {
+		return
+	}
+	frame, err := codificarEventoCliente(EventoClienteMensaje, aRegistroMensaje(envio.mensaje))
+	if err != nil {
+		return
+	}
+	_ = cola.Enqueue(envio.destinatarios, frame)
+}
+
+// marcarEntregados registra los destinatarios ya atendidos de un mensaje y devuelve
+// los que no lo estaban, para no entregar dos veces el mismo mensaje
+func (s *routingService) marcarEntregados(mensajeID uuid.UUID, destinatarios []uuid.UUID) []uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vista, ok := s.mensajesVistos[mensajeID]
+	if !ok {
+		vista = &entregaVista{usuarios: make(map[uuid.UUID]bool)}
+		s.mensajesVistos[mensajeID] = vista
+	}
+	vista.visto = time.Now()
+
+	var nuevos []uuid.UUID
+	for _, id := range destinatarios {
+		if !vista.usuarios[id] {
+			vista.usuarios[id] = true
+			nuevos = append(nuevos, id)
+		}
+	}
+	return nuevos
+}
+
+// handleMensaje entrega a los clientes locales un mensaje reenviado por otro nodo
+// y lo reenvía hacia los destinatarios que están más allá de este nodo
+func (s *routingService) handleMensaje(peerID uuid.UUID, payload []byte) {
+	var msg mensajeEnrutado
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return
+	}
+	for _, sl := range msg.Saltos {
+		if sl.Nodo == s.nodoID {
+			return
+		}
+	}
+	m, err := msg.Mensaje.aModelo()
+	if err != nil {
+		return
+	}
+	destinatarios := s.marcarEntregados(m.ID(), msg.Destinatarios)
+	if len(destinatarios) == 0 {
+		return
+	}
+
+	// El mensaje puede no haber llegado aún por anti-entropía
+	ctx := context.Background()
+	if existente, err := s.messages.FindByID(ctx, m.ID()); err == nil && existente == nil {
+		_ = s.messages.Save(ctx, m)
+	}
+
+	_ = s.entregar(&envioPendiente{
+		mensaje:       m,
+		destinatarios: destinatarios,
+		origen:        msg.Origen,
+		ttl:           msg.TTL,
+		saltos:        append(msg.Saltos, salto{Nodo: s.nodoID, At: time.Now().UTC()}),
+	})
+}
+
+// confirmarRuta envía al nodo origen el camino recorrido hasta este nodo
+func (s *routingService) confirmarRuta(envio *envioPendiente) {
+	s.enviarHacia(envio.origen, FrameTipoRutaConfirmada, &mensajeRutaConfirmada{
+		MensajeID: envio.mensaje.ID(),
+		Origen:    envio.origen,
+		TTL:       s.config.TTL,
+		Saltos:    envio.saltos,
+	})
+}
+
+// handleRutaConfirmada registra la ruta completa en el origen o la reenvía hacia él
+func (s *routingService) handleRutaConfirmada(peerID uuid.UUID, payload []byte) {
+	var msg mensajeRutaConfirmada
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return
+	}
+	if msg.Origen != s.nodoID {
+		if msg.TTL > 1 {
+			msg.TTL--
+			s.enviarHacia(msg.Origen, FrameTipoRutaConfirmada, &msg)
+		}
+		return
+	}
+
+	ctx := context.Background()
+	existentes, err := s.routes.FindByMessage(ctx, msg.MensajeID)
+	if err != nil {
+		return
+	}
+	registrados := make(map[uuid.UUID]bool, len(existentes))
+	for _, r := range existentes {
+		registrados[r.NodoDestinoID()] = true
+	}
+
+	// El primer salto ya lo registró este nodo al enviar
+	for i := 1; i < len(msg.Saltos); i++ {
+		actual := msg.Saltos[i]
+		if registrados[actual.Nodo] {
+			continue
+		}
+		ruta, err := model.NewRoutedMessageSalto(msg.MensajeID, msg.Saltos[i-1].Nodo, actual.Nodo, actual.At)
+		if err != nil {
+			return
+		}
+		if err := s.routes.Save(ctx, ruta); err != nil {
+			return
+		}
+		registrados[actual.Nodo] = true
+	}
+}
+
+// enviarHacia envía un frame al siguiente salto hacia un nodo destino
+func (s *routingService) enviarHacia(destino uuid.UUID, frameType uint16, msg interface{}) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	siguiente, ok := s.tablaRutas[destino]
+	s.mu.Unlock()
+	if !ok {
+		siguiente = destino
+	}
+	_ = s.transport.SendTo(siguiente, frameType, payload)
+}
+
+// handleAdyacencia actualiza la topología con un anuncio nuevo y lo propaga
+func (s *routingService) handleAdyacencia(peerID uuid.UUID, payload []byte) {
+	var msg mensajeAdyacencia
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Origen == s.nodoID {
+		return
+	}
+	vecinos := s.transport.GetAllPeerIDs()
+
+	s.mu.Lock()
+	if conocida, ok := s.topologia[msg.Origen]; ok && msg.Secuencia <= conocida.secuencia {
+		s.mu.Unlock()
+		return
+	}
+	s.topologia[msg.Origen] = &adyacencia{secuencia: msg.Secuencia, vecinos: msg.Vecinos, recibido: time.Now()}
+	s.recalcularRutasLocked(vecinos)
+	s.mu.Unlock()
+
+	if msg.TTL > 1 {
+		msg.TTL--
+		s.propagar(FrameTipoAdyacencia, &msg, peerID, msg.Origen)
+	}
+}
+
+// recalcularRutasLocked rehace la tabla de rutas; requiere s.mu
+func (s *routingService) recalcularRutasLocked(vecinos []uuid.UUID) {
+	adyacencias := make(map[uuid.UUID][]uuid.UUID, len(s.topologia))
+	for nodo, ad := range s.topologia {
+		adyacencias[nodo] = ad.vecinos
+	}
+	s.tablaRutas = calcularTablaRutas(s.nodoID, vecinos, adyacencias)
+}
+
+// anunciarAdyacencia difunde los vecinos actuales de este nodo
+func (s *routingService) anunciarAdyacencia() {
+	vecinos := s.transport.GetAllPeerIDs()
+
+	s.mu.Lock()
+	s.secuencia = max(time.Now().UnixNano(), s.secuencia+1)
+	s.vecinos = make(map[uuid.UUID]bool, len(vecinos))
+	for _, v := range vecinos {
+		s.vecinos[v] = true
+	}
+	s.recalcularRutasLocked(vecinos)
+	msg := &mensajeAdyacencia{Origen: s.nodoID, Secuencia: s.secuencia, TTL: s.config.TTL, Vecinos: vecinos}
+	s.mu.Unlock()
+
+	s.propagar(FrameTipoAdyacencia, msg)
+}
+
+// vecinosCambiaron indica si el transporte tiene otros vecinos que el último anuncio
+func (s *routingService) vecinosCambiaron() bool {
+	vecinos := s.transport.GetAllPeerIDs()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(vecinos) != len(s.vecinos) {
+		return true
+	}
+	for _, v := range vecinos {
+		if !s.vecinos[v] {
+			return true
+		}
+	}
+	return false
+}
+
+// propagar envía un anuncio a todos los vecinos salvo los excluidos.
+// Un vecino que no lo reciba se corrige con el siguiente anuncio periódico.
+func (s *routingService) propagar(frameType uint16, msg interface{}, excluidos ...uuid.UUID) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		omitir := false
+		for _, ex := range excluidos {
+			if peerID == ex {
+				omitir = true
+				break
+			}
+		}
+		if !omitir {
+			_ = s.transport.SendTo(peerID, frameType, payload)
+		}
+	}
+}
+
+// expirar olvida los enlaces no anunciados a tiempo y limpia la caché de mensajes vistos
+func (s *routingService) expirar() {
+	vecinos := s.transport.GetAllPeerIDs()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ahora := time.Now()
+	for nodo, ad := range s.topologia {
+		if ahora.Sub(ad.recibido) > s.config.ExpiracionAdyacencia {
+			delete(s.topologia, nodo)
+		}
+	}
+	for id, vista := range s.mensajesVistos {
+		if ahora.Sub(vista.visto) > s.config.ExpiracionCache {
+			delete(s.mensajesVistos, id)
+		}
+	}
+	s.recalcularRutasLocked(vecinos)
 }
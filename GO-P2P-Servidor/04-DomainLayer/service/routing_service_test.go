@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nodoRuteo agrupa el estado, la presencia y el RoutingService de un nodo simulado
+type nodoRuteo struct {
+	id         uuid.UUID
+	transporte *transporteFalso
+	canales    *canalesFalsos
+	mensajes   *mensajesFalsos
+	rutas      *rutasFalsas
+	clientes   *clientesFalsos
+	presencia  PresenceService
+	servicio   RoutingService
+}
+
+func nuevoNodoRuteo(red *redFalsa, canales *canalesFalsos, config RoutingConfig) *nodoRuteo {
+	n := &nodoRuteo{
+		id:       uuid.New(),
+		canales:  canales,
+		mensajes: nuevosMensajesFalsos(),
+		rutas:    &rutasFalsas{},
+		clientes: nuevosClientesFalsos(),
+	}
+	n.transporte = red.nodo(n.id)
+	n.presencia = nuevaPresenciaRuteo(n)
+	n.servicio = NewRoutingService(n.id, n.transporte, n.clientes, n.canales, n.mensajes, n.rutas, n.presencia, config)
+	return n
+}
+
+// nuevaPresenciaRuteo crea el directorio de sesiones del nodo simulado
+func nuevaPresenciaRuteo(n *nodoRuteo) PresenceService {
+	reloj, _ := model.NewRelojHLC(n.id)
+	return NewPresenceService(n.id, n.transporte, n.clientes, nuevosUsuariosFalsos(), reloj, DefaultPresenceConfig())
+}
+
+// conectar abre una sesión del usuario en el nodo, espera a que los demás nodos lo
+// ubiquen y devuelve el ID de la sesión
+func conectar(t *testing.T, n *nodoRuteo, usuarioID uuid.UUID, otros ...*nodoRuteo) uuid.UUID {
+	t.Helper()
+	sesionID := uuid.New()
+	if err := n.presencia.MarkConnected(usuarioID, sesionID); err != nil {
+		t.Fatalf("MarkConnected: %v", err)
+	}
+	for _, o := range otros {
+		esperarHasta(t, func() bool {
+			nodos := o.servicio.LocateUser(usuarioID)
+			return len(nodos) == 1 && nodos[0] == n.id
+		})
+	}
+	return sesionID
+}
+
+func TestRoutingService_MensajeDirecto(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	a := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+	b := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+
+	ana, bob := uuid.New(), uuid.New()
+	conectar(t, a, ana, b)
+	conectar(t, b, bob, a)
+
+	m, _ := model.NewMensajeDirecto(uuid.New(), ana, bob, "hola bob", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+
+	esperarHasta(t, func() bool { return b.clientes.recibidos(bob) == 1 })
+	if a.clientes.recibidos(ana) != 0 {
+		t.Error("el remitente no debe recibir su propio mensaje directo")
+	}
+	if guardado, _ := b.mensajes.FindByID(context.Background(), m.ID()); guardado == nil {
+		t.Error("el nodo destino debe guardar el mensaje recibido")
+	}
+
+	rutas, err := a.servicio.ListRoutes(m.ID())
+	if err != nil {
+		t.Fatalf("ListRoutes: %v", err)
+	}
+	if len(rutas) != 1 || rutas[0].NodoDestinoID() != b.id {
+		t.Errorf("rutas inesperadas: %v", rutas)
+	}
+}
+
+func TestRoutingService_MensajeCanal(t *testing.T) {
+	ctx := context.Background()
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	a := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+	b := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+	c := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+
+	canalID := uuid.New()
+	ana, bob, eva, dan := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	for _, u := range []uuid.UUID{ana, bob, eva, dan} {
+		canales.AddMember(ctx, canalID, u, "member")
+	}
+	conectar(t, a, ana, b, c)
+	conectar(t, a, dan, b, c)
+	conectar(t, b, bob, a, c)
+	conectar(t, c, eva, a, b)
+
+	m, _ := model.NewMensajeCanal(uuid.New(), ana, canalID, "hola canal", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+
+	esperarHasta(t, func() bool { return b.clientes.recibidos(bob) == 1 && c.clientes.recibidos(eva) == 1 })
+	if a.clientes.recibidos(dan) != 1 {
+		t.Errorf("miembro local: esperado 1 mensaje, obtuvo %d", a.clientes.recibidos(dan))
+	}
+	if a.clientes.recibidos(ana) != 0 {
+		t.Error("el remitente no debe recibir su propio mensaje de canal")
+	}
+	if rutas, _ := a.servicio.ListRoutes(m.ID()); len(rutas) != 2 {
+		t.Errorf("rutas: esperado 2, obtuvo %d", len(rutas))
+	}
+}
+
+func TestRoutingService_ReintentoNodoCaido(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	config := DefaultRoutingConfig()
+	config.IntervaloReintento = 10 * time.Millisecond
+	a := nuevoNodoRuteo(red, canales, config)
+	b := nuevoNodoRuteo(red, canales, config)
+
+	ana, bob := uuid.New(), uuid.New()
+	conectar(t, a, ana, b)
+	conectar(t, b, bob, a)
+
+	red.caer(b.id, true)
+	m, _ := model.NewMensajeDirecto(uuid.New(), ana, bob, "¿estás?", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage con nodo caído debe encolar, obtuvo %v", err)
+	}
+	if rutas, _ := a.servicio.ListRoutes(m.ID()); len(rutas) != 0 {
+		t.Errorf("no debe registrarse un salto sin envío, obtuvo %d", len(rutas))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.servicio.Start(ctx)
+
+	red.caer(b.id, false)
+	esperarHasta(t, func() bool { return b.clientes.recibidos(bob) == 1 })
+	if rutas, _ := a.servicio.ListRoutes(m.ID()); len(rutas) != 1 {
+		t.Errorf("rutas tras el reintento: esperado 1, obtuvo %d", len(rutas))
+	}
+}
+
+func TestRoutingService_ChatPrivadoNoSoportado(t *testing.T) {
+	red := nuevaRedFalsa()
+	a := nuevoNodoRuteo(red, nuevosCanalesFalsos(), DefaultRoutingConfig())
+
+	m, _ := model.NewMensajeChatPrivado(uuid.New(), uuid.New(), uuid.New(), "hola", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(m); err != ErrRutaNoSoportada {
+		t.Errorf("esperado ErrRutaNoSoportada, obtuvo %v", err)
+	}
+	if err := a.servicio.RouteMessage(nil); err != ErrRutaMensajeNil {
+		t.Errorf("esperado ErrRutaMensajeNil, obtuvo %v", err)
+	}
+}
+
+func TestRoutingService_MultiSalto(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	config := DefaultRoutingConfig()
+	config.IntervaloAnuncio = 20 * time.Millisecond
+	config.IntervaloReintento = 10 * time.Millisecond
+
+	// Cadena A - B - C: A y C no tienen conexión directa
+	a := nuevoNodoRuteo(red, canales, config)
+	b := nuevoNodoRuteo(red, canales, config)
+	c := nuevoNodoRuteo(red, canales, config)
+	red.enlazar(a.id, b.id)
+	red.enlazar(b.id, c.id)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, n := range []*nodoRuteo{a, b, c} {
+		n.servicio.Start(ctx)
+	}
+
+	ana, eva := uuid.New(), uuid.New()
+	conectar(t, a, ana, c)
+	conectar(t, c, eva, a)
+	esperarHasta(t, func() bool { return a.servicio.RouteTable()[c.id] == b.id })
+
+	m, _ := model.NewMensajeDirecto(uuid.New(), ana, eva, "hola desde lejos", time.Now(), uuid.Nil)
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	esperarHasta(t, func() bool { return c.clientes.recibidos(eva) == 1 })
+
+	// El origen recibe la ruta completa: A → B → C
+	esperarHasta(t, func() bool {
+		rutas, _ := a.servicio.ListRoutes(m.ID())
+		return len(rutas) == 2
+	})
+	rutas, _ := a.servicio.ListRoutes(m.ID())
+	if rutas[0].NodoDestinoID() != b.id || rutas[0].NodoAnteriorID() != uuid.Nil {
+		t.Errorf("primer salto: esperado → %v, obtuvo %v → %v", b.id, rutas[0].NodoAnteriorID(), rutas[0].NodoDestinoID())
+	}
+	if rutas[1].NodoDestinoID() != c.id || rutas[1].NodoAnteriorID() != b.id {
+		t.Errorf("segundo salto: esperado %v → %v, obtuvo %v → %v", b.id, c.id, rutas[1].NodoAnteriorID(), rutas[1].NodoDestinoID())
+	}
+
+	// Enrutar de nuevo el mismo mensaje no duplica la entrega
+	if err := a.servicio.RouteMessage(m); err != nil {
+		t.Fatalf("RouteMessage repetido: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if c.clientes.recibidos(eva) != 1 {
+		t.Errorf("entrega duplicada: esperado 1 mensaje, obtuvo %d", c.clientes.recibidos(eva))
+	}
+}
+
+func TestRoutingService_TTLYBucles(t *testing.T) {
+	red := nuevaRedFalsa()
+	canales := nuevosCanalesFalsos()
+	a := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+	b := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+	c := nuevoNodoRuteo(red, canales, DefaultRoutingConfig())
+
+	eva := uuid.New()
+	conectar(t, c, eva, a, b)
+	m, _ := model.NewMensajeDirecto(uuid.New(), uuid.New(), eva, "hola", time.Now(), uuid.Nil)
+	transporteA := red.nodo(uuid.New())
+
+	enviar := func(msg mensajeEnrutado) {
+		t.Helper()
+		payload, _ := json.Marshal(msg)
+		if err := transporteA.SendTo(b.id, FrameTipoMensajeEnrutado, payload); err != nil {
+			t.Fatalf("SendTo: %v", err)
+		}
+	}
+
+	// Sin TTL restante B no puede reenviar a C
+	enviar(mensajeEnrutado{
+		Mensaje: aRegistroMensaje(m), Destinatarios: []uuid.UUID{eva},
+		Origen: a.id, TTL: 0, Saltos: []salto{{Nodo: a.id, At: time.Now()}},
+	})
+	// C ya figura como visitado: B no debe devolverle el mensaje
+	m2, _ := model.NewMensajeDirecto(uuid.New(), uuid.New(), eva, "hola otra vez", time.Now(), uuid.Nil)
+	enviar(mensajeEnrutado{
+		Mensaje: aRegistroMensaje(m2), Destinatarios: []uuid.UUID{eva},
+		Origen: a.id, TTL: 5, Saltos: []salto{{Nodo: c.id, At: time.Now()}},
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	if c.clientes.recibidos(eva) != 0 {
+		t.Errorf("no se esperaban entregas, obtuvo %d", c.clientes.recibidos(eva))
+	}
+	for _, id := range []uuid.UUID{m.ID(), m2.ID()} {
+		if rutas, _ := b.servicio.ListRoutes(id); len(rutas) != 0 {
+			t.Errorf("no se esperaban saltos registrados en B, obtuvo %d", len(rutas))
+		}
+	}
+}
+
+func TestCalcularTablaRutas(t *testing.T) {
+	a, b, c, d, e := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// a - b - c - d, con e aislado y un anuncio de c que incluye a a (ignorado: a decide sus enlaces)
+	rutas := calcularTablaRutas(a, []uuid.UUID{b}, map[uuid.UUID][]uuid.UUID{
+		b: {a, c},
+		c: {b, d, a},
+		a: {e},
+	})
+
+	esperado := map[uuid.UUID]uuid.UUID{b: b, c: b, d: b}
+	if len(rutas) != len(esperado) {
+		t.Fatalf("rutas: esperado %d, obtuvo %d (%v)", len(esperado), len(rutas), rutas)
+	}
+	for destino, siguiente := range esperado {
+		if rutas[destino] != siguiente {
+			t.Errorf("siguiente salto hacia %v: esperado %v, obtuvo %v", destino, siguiente, rutas[destino])
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de los mensajes programados
+var (
+	ErrProgramadoNoEncontrado = errors.New("mensaje programado no encontrado")
+	ErrProgramadoSinPermiso   = errors.New("solo el remitente puede cancelar el mensaje programado")
+)
+
+// ScheduledMessageConfig contiene los parámetros del envío de mensajes programados
+type ScheduledMessageConfig struct {
+	Intervalo time.Duration // Periodo de la revisión de mensajes que enviar o eliminar
+	Lote      int           // Máximo de mensajes procesados en cada revisión
+}
+
+// DefaultScheduledMessageConfig devuelve la configuración por defecto
+func DefaultScheduledMessageConfig() ScheduledMessageConfig {
+	return ScheduledMessageConfig{
+		Intervalo: time.Second,
+		Lote:      100,
+	}
+}
+
+// ScheduledMessageService define los mensajes programados para su envío futuro y los
+// que se eliminan para todos tras una duración. Cada mensaje se guarda en la base de
+// datos, así que sobrevive a los reinicios, y se difunde a todos los nodos para que el
+// remitente pueda consultarlo o cancelarlo desde cualquiera; pero solo el nodo en que
+// se programó lo envía con MessageService y lo elimina con MessageEditService. Ese
+// nodo reserva cada paso con un cambio de estado condicionado, de modo que el mensaje
+// se envía y se elimina una sola vez en todo el clúster: si el nodo se reinicia con un
+// envío reservado y sin confirmar, lo da por fallido en lugar de arriesgar un duplicado.
+type ScheduledMessageService interface {
+	// ScheduleDirect programa un mensaje directo para sendAt; sin fecha, o con una ya
+	// pasada, lo envía en el acto. Con lifetime distinto de cero, el mensaje se elimina
+	// para todos cuando pasa ese tiempo desde su envío.
+	ScheduleDirect(
+		senderID, recipientID uuid.UUID,
+		contenido string,
+		archivoID uuid.UUID,
+		sendAt time.Time,
+		lifetime time.Duration,
+	) (*model.MensajeProgramado, error)
+
+	// ScheduleChannel programa un mensaje a un canal, como ScheduleDirect
+	ScheduleChannel(
+		senderID, channelID uuid.UUID,
+		contenido string,
+		archivoID uuid.UUID,
+		sendAt time.Time,
+		lifetime time.Duration,
+	) (*model.MensajeProgramado, error)
+
+	// Cancel anula un mensaje aún pendiente. Si el nodo responsable ya lo había
+	// reservado para enviarlo, el envío prevalece y el remitente recibe el estado final.
+	Cancel(senderID, scheduledID uuid.UUID) (*model.MensajeProgramado, error)
+
+	// ListScheduled pagina por fecha de envío los mensajes programados por el usuario
+	ListScheduled(senderID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeProgramado], error)
+
+	// Start lanza la revisión periódica de los mensajes de este nodo hasta que ctx
+	// termine. La primera revisión es inmediata y recupera lo que venció con el nodo parado.
+	Start(ctx context.Context)
+}
+
+// scheduledMessageService implementa ScheduledMessageService sobre PeerTransport
+type scheduledMessageService struct {
+	nodoID    uuid.UUID
+	transport PeerTransport
+	clients   ClientTransport
+	messages  MessageService
+	edits     MessageEditService
+	scheduled repository.IScheduledMessageRepository
+	config    ScheduledMessageConfig
+
+	// mu serializa el procesamiento local, de modo que un mensaje en ENVIANDO que
+	// encuentra la revisión solo puede venir de un reinicio
+	mu sync.Mutex
+}
+
+// NewScheduledMessageService crea el servicio y registra su handler de frames en el
+// transporte. nodoID es el nodo propio, responsable de los mensajes que se programan en él.
+func NewScheduledMessageService(
+	nodoID uuid.UUID,
+	transport PeerTransport,
+	clients ClientTransport,
+	messages MessageService,
+	edits MessageEditService,
+	scheduled repository.IScheduledMessageRepository,
+	config ScheduledMessageConfig,
+) ScheduledMessageService {
+	s := &scheduledMessageService{
+		nodoID:    nodoID,
+		transport: transport,
+		clients:   clients,
+		messages:  messages,
+		edits:     edits,
+		scheduled: scheduled,
+		config:    config,
+	}
+	transport.SetFrameHandler(FrameTipoMensajeProgramado, s.handleProgramado)
+	return s
+}
+
+// Start implementa ScheduledMessageService
+func (s *scheduledMessageService) Start(ctx context.Context) {
+	go func() {
+		s.procesar(time.Now().UTC())
+		ticker := time.NewTicker(s.config.Intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.procesar(time.Now().UTC())
+			}
+		}
+	}()
+}
+
+// ScheduleDirect implementa ScheduledMessageService
+func (s *scheduledMessageService) ScheduleDirect(
+	senderID, recipientID uuid.UUID,
+	contenido string,
+	archivoID uuid.UUID,
+	sendAt time.Time,
+	lifetime time.Duration,
+) (*model.MensajeProgramado, error) {
+	return s.programar(senderID, recipientID, uuid.Nil, contenido, archivoID, sendAt, lifetime)
+}
+
+// ScheduleChannel implementa ScheduledMessageService
+func (s *scheduledMessageService) ScheduleChannel(
+	senderID, channelID uuid.UUID,
+	contenido string,
+	archivoID uuid.UUID,
+	sendAt time.Time,
+	lifetime time.Duration,
+) (*model.MensajeProgramado, error) {
+	return s.programar(senderID, uuid.Nil, channelID, contenido, archivoID, sendAt, lifetime)
+}
+
+// Cancel implementa ScheduledMessageService
+func (s *scheduledMessageService) Cancel(senderID, scheduledID uuid.UUID) (*model.MensajeProgramado, error) {
+	ctx := context.Background()
+	p, err := s.scheduled.FindByID(ctx, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrProgramadoNoEncontrado
+	}
+	if p.RemitenteID() != senderID {
+		return nil, ErrProgramadoSinPermiso
+	}
+	if err := p.Cancelar(); err != nil {
+		return nil, err
+	}
+	cambiado, err := s.cambiar(ctx, p, model.ProgramadoPendiente)
+	if err != nil {
+		return nil, err
+	}
+	if !cambiado {
+		return nil, model.ErrProgramadoNoPendiente
+	}
+	return p, nil
+}
+
+// ListScheduled implementa ScheduledMessageService
+func (s *scheduledMessageService) ListScheduled(senderID uuid.UUID, consulta model.ConsultaPagina) (*model.Pagina[*model.MensajeProgramado], error) {
+	if senderID == uuid.Nil {
+		return nil, model.ErrRemitenteIDNil
+	}
+	return s.scheduled.FindBySender(context.Background(), senderID, consulta)
+}
+
+// programar guarda y difunde un mensaje programado nuevo; si su fecha ya llegó, lo
+// envía en el acto y devuelve el error del envío
+func (s *scheduledMessageService) programar(
+	senderID, recipientID, channelID uuid.UUID,
+	contenido string,
+	archivoID uuid.UUID,
+	sendAt time.Time,
+	lifetime time.Duration,
+) (*model.MensajeProgramado, error) {
+	ahora := time.Now().UTC()
+	if sendAt.IsZero() {
+		sendAt = ahora
+	}
+	p, err := model.NewMensajeProgramado(uuid.New(), s.nodoID, senderID, recipientID, channelID, contenido, archivoID, sendAt, lifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if err := s.scheduled.Save(ctx, p); err != nil {
+		return nil, err
+	}
+	s.difundir(p, uuid.Nil)
+	if p.EnviarEn().After(ahora) {
+		return p, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return p, s.enviar(ctx, p)
+}
+
+// procesar envía los mensajes de este nodo cuya fecha llegó, elimina los que
+// expiraron y da por fallidos los envíos que un reinicio dejó reservados. Un error
+// deja el mensaje en su estado para la siguiente revisión.
+func (s *scheduledMessageService) procesar(ahora time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := context.Background()
+	vencidos, err := s.scheduled.FindDue(ctx, s.nodoID, ahora, s.config.Lote)
+	if err != nil {
+		return
+	}
+	for _, p := range vencidos {
+		switch p.Estado() {
+		case model.ProgramadoPendiente:
+			_ = s.enviar(ctx, p)
+		case model.ProgramadoEnviando:
+			if p.Fallar() == nil {
+				_, _ = s.cambiar(ctx, p, model.ProgramadoEnviando)
+			}
+		case model.ProgramadoEnviado:
+			_ = s.expirar(ctx, p)
+		}
+	}
+}
+
+// enviar reserva el mensaje y lo envía con MessageService. Si la reserva no se
+// consigue es que se canceló entretanto y no se envía.
+func (s *scheduledMessageService) enviar(ctx context.Context, p *model.MensajeProgramado) error {
+	if err := p.Reservar(); err != nil {
+		return err
+	}
+	reservado, err := s.cambiar(ctx, p, model.ProgramadoPendiente)
+	if err != nil {
+		return err
+	}
+	if !reservado {
+		return model.ErrProgramadoNoPendiente
+	}
+
+	var m *model.MensajeServidor
+	if p.CanalID() != uuid.Nil {
+		m, err = s.messages.SendChannel(p.RemitenteID(), p.CanalID(), p.Contenido(), p.ArchivoID())
+	} else {
+		m, err = s.messages.SendDirect(p.RemitenteID(), p.DestinoUsuarioID(), p.Contenido(), p.ArchivoID())
+	}
+	if err != nil {
+		// El rechazo es definitivo: reintentarlo podría duplicar un envío parcial
+		if p.Fallar() == nil {
+			_, _ = s.cambiar(ctx, p, model.ProgramadoEnviando)
+		}
+		return err
+	}
+	if err := p.Enviado(m.ID(), time.Now().UTC()); err != nil {
+		return err
+	}
+	_, err = s.cambiar(ctx, p, model.ProgramadoEnviando)
+	return err
+}
+
+// expirar elimina para todos el mensaje enviado. El borrado lógico no se repite, así
+// que un mensaje ya eliminado, por su autor o en un intento interrumpido, solo se
+// marca como expirado.
+func (s *scheduledMessageService) expirar(ctx context.Context, p *model.MensajeProgramado) error {
+	_, err := s.edits.DeleteMessage(p.RemitenteID(), p.MensajeID())
+	if err != nil && !errors.Is(err, model.ErrMensajeEliminado) && !errors.Is(err, ErrEdicionMensajeNoEncontrado) {
+		return err
+	}
+	if err := p.Expirar(); err != nil {
+		return err
+	}
+	_, err = s.cambiar(ctx, p, model.ProgramadoEnviado)
+	return err
+}
+
+// cambiar guarda el estado de p si el guardado sigue en desde y, en ese caso, lo
+// difunde y avisa al remitente
+func (s *scheduledMessageService) cambiar(ctx context.Context, p *model.MensajeProgramado, desde model.EstadoProgramado) (bool, error) {
+	cambiado, err := s.scheduled.UpdateState(ctx, p, desde)
+	if err != nil || !cambiado {
+		return false, err
+	}
+	s.difundir(p, uuid.Nil)
+	s.notificar(p)
+	return true, nil
+}
+
+// handleProgramado guarda la copia de un mensaje programado recibida de otro nodo o
+// adopta su estado si es posterior al local, y la reenvía a los demás vecinos. Una
+// copia que no cambia nada ya se recibió antes y no se reenvía, lo que detiene la
+// inundación.
+func (s *scheduledMessageService) handleProgramado(peerID uuid.UUID, payload []byte) {
+	var registro registroProgramado
+	if err := json.Unmarshal(payload, &registro); err != nil {
+		return
+	}
+	remoto, err := registro.aModelo()
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	local, err := s.scheduled.FindByID(ctx, remoto.ID())
+	if err != nil {
+		return
+	}
+	if local == nil {
+		if s.scheduled.Save(ctx, remoto) != nil {
+			return
+		}
+		s.difundir(remoto, peerID)
+		s.notificar(remoto)
+		return
+	}
+
+	// Un cambio local entre la lectura y la escritura obliga a releer y fusionar de nuevo
+	for intento := 0; intento < 3; intento++ {
+		anterior := local.Estado()
+		if !local.Fusionar(remoto) {
+			return
+		}
+		cambiado, err := s.scheduled.UpdateState(ctx, local, anterior)
+		if err != nil {
+			return
+		}
+		if cambiado {
+			s.difundir(local, peerID)
+			s.notificar(local)
+			return
+		}
+		if local, err = s.scheduled.FindByID(ctx, remoto.ID()); err != nil || local == nil {
+			return
+		}
+	}
+}
+
+// difundir envía el mensaje programado a todos los vecinos salvo al que lo envió
+func (s *scheduledMessageService) difundir(p *model.MensajeProgramado, origen uuid.UUID) {
+	payload, err := json.Marshal(aRegistroProgramado(p))
+	if err != nil {
+		return
+	}
+	for _, peerID := range s.transport.GetAllPeerIDs() {
+		if peerID == origen {
+			continue
+		}
+		// Un vecino caído recibirá el estado con el siguiente cambio del mensaje
+		_ = s.transport.SendTo(peerID, FrameTipoMensajeProgramado, payload)
+	}
+}
+
+// notificar envía el estado del mensaje programado al remitente si está conectado a
+// este nodo
+func (s *scheduledMessageService) notificar(p *model.MensajeProgramado) {
+	if s.clients == nil {
+		return
+	}
+	frame, err := codificarEventoCliente(EventoClienteMensajeProgramado, aRegistroProgramado(p))
+	if err != nil {
+		return
+	}
+	_ = s.clients.Broadcast([]uuid.UUID{p.RemitenteID()}, frame)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// nodoProgramado agrupa el estado y el ScheduledMessageService de un nodo simulado
+type nodoProgramado struct {
+	id          uuid.UUID
+	mensajes    *mensajesFalsos
+	clientes    *clientesFalsos
+	envio       *envioFalso
+	programados *programadosFalsos
+	servicio    ScheduledMessageService
+}
+
+func nuevoNodoProgramado(t *testing.T, red *redFalsa) *nodoProgramado {
+	t.Helper()
+	id := uuid.New()
+	reloj, err := model.NewRelojHLC(id)
+	if err != nil {
+		t.Fatalf("NewRelojHLC: %v", err)
+	}
+	n := &nodoProgramado{
+		id:          id,
+		mensajes:    nuevosMensajesFalsos(),
+		clientes:    nuevosClientesFalsos(),
+		programados: nuevosProgramadosFalsos(),
+	}
+	n.envio = &envioFalso{mensajes: n.mensajes}
+	transporte := red.nodo(id)
+	ediciones := NewMessageEditService(transporte, n.clientes, nuevosCanalesFalsos(), n.mensajes, unidadFalsa{}, NewConflictResolver(nil), reloj)
+	n.servicio = NewScheduledMessageService(id, transporte, n.clientes, n.envio, ediciones, n.programados, DefaultScheduledMessageConfig())
+	return n
+}
+
+// procesar lanza una revisión del nodo como si fuera el instante at
+func (n *nodoProgramado) procesar(at time.Time) {
+	n.servicio.(*scheduledMessageService).procesar(at)
+}
+
+// estado devuelve el estado de la copia local del mensaje programado; vacío si no la hay
+func (n *nodoProgramado) estado(id uuid.UUID) model.EstadoProgramado {
+	p, _ := n.programados.FindByID(context.Background(), id)
+	if p == nil {
+		return ""
+	}
+	return p.Estado()
+}
+
+// estadosProgramado decodifica los estados de mensajes programados recibidos por un cliente
+func estadosProgramado(t *testing.T, c *clientesFalsos, id uuid.UUID) []string {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var estados []string
+	for _, frame := range c.frames[id] {
+		var evento struct {
+			Evento string             `json:"evento"`
+			Datos  registroProgramado `json:"datos"`
+		}
+		if err := json.Unmarshal(frame, &evento); err != nil {
+			t.Fatalf("frame inválido: %v", err)
+		}
+		if evento.Evento == EventoClienteMensajeProgramado {
+			estados = append(estados, evento.Datos.Estado)
+		}
+	}
+	return estados
+}
+
+func TestScheduled_EnvioYExpiracionUnicosEntreNodos(t *testing.T) {
+	red := nuevaRedFalsa()
+	a, b, c := nuevoNodoProgramado(t, red), nuevoNodoProgramado(t, red), nuevoNodoProgramado(t, red)
+	nodos := []*nodoProgramado{a, b, c}
+	remitenteID, canalID := uuid.New(), uuid.New()
+	enviarEn := time.Now().Add(time.Hour)
+
+	p, err := a.servicio.ScheduleChannel(remitenteID, canalID, "feliz año", uuid.Nil, enviarEn, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("ScheduleChannel: %v", err)
+	}
+	if p.Estado() != model.ProgramadoPendiente || p.NodoID() != a.id {
+		t.Fatalf("mensaje programado inesperado: %s %v", p.Estado(), p.NodoID())
+	}
+	for _, n := range nodos {
+		esperarHasta(t, func() bool { return n.estado(p.ID()) == model.ProgramadoPendiente })
+	}
+
+	// Antes de su fecha nadie lo envía; después, solo su nodo y una sola vez
+	for _, n := range nodos {
+		n.procesar(time.Now())
+	}
+	if a.envio.totalEnviados() != 0 {
+		t.Fatal("no debe enviarse antes de su fecha")
+	}
+	for i := 0; i < 2; i++ {
+		for _, n := range nodos {
+			n.procesar(enviarEn)
+		}
+	}
+	if a.envio.totalEnviados() != 1 || b.envio.totalEnviados() != 0 || c.envio.totalEnviados() != 0 {
+		t.Fatalf("esperado un único envío en el nodo responsable: %d %d %d",
+			a.envio.totalEnviados(), b.envio.totalEnviados(), c.envio.totalEnviados())
+	}
+	for _, n := range nodos {
+		esperarHasta(t, func() bool { return n.estado(p.ID()) == model.ProgramadoEnviado })
+	}
+	enviado, _ := b.programados.FindByID(context.Background(), p.ID())
+	mensajeID := a.envio.enviados[0].ID()
+	if enviado.MensajeID() != mensajeID || enviado.ExpiraEn().IsZero() {
+		t.Errorf("la copia remota debe conocer el mensaje enviado: %v %v", enviado.MensajeID(), enviado.ExpiraEn())
+	}
+
+	// Al expirar, el nodo responsable lo elimina y el borrado llega a todos
+	vence := enviado.ExpiraEn()
+	for i := 0; i < 2; i++ {
+		for _, n := range nodos {
+			n.procesar(vence)
+		}
+	}
+	for _, n := range nodos {
+		esperarHasta(t, func() bool {
+			m, _ := n.mensajes.FindByID(context.Background(), mensajeID)
+			return n.estado(p.ID()) == model.ProgramadoExpirado && m != nil && m.Eliminado()
+		})
+	}
+	ediciones, _ := a.mensajes.FindEdits(context.Background(), mensajeID)
+	if len(ediciones) != 1 || ediciones[0].Tipo() != model.EdicionBorrado || ediciones[0].EditorID() != remitenteID {
+		t.Errorf("esperado un único borrado por el remitente: %v", ediciones)
+	}
+
+	estados := estadosProgramado(t, a.clientes, remitenteID)
+	esperados := []string{"ENVIANDO", "ENVIADO", "EXPIRADO"}
+	if len(estados) != len(esperados) {
+		t.Fatalf("esperados los avisos %v, obtuvo %v", esperados, estados)
+	}
+	for i := range esperados {
+		if estados[i] != esperados[i] {
+			t.Errorf("aviso %d: esperado %s, obtuvo %s", i, esperados[i], estados[i])
+		}
+	}
+}
+
+func TestScheduled_CancelarDesdeOtroNodo(t *testing.T) {
+	red := nuevaRedFalsa()
+	a, b := nuevoNodoProgramado(t, red), nuevoNodoProgramado(t, red)
+	remitenteID, destinoID := uuid.New(), uuid.New()
+	enviarEn := time.Now().Add(time.Hour)
+
+	p, err := a.servicio.ScheduleDirect(remitenteID, destinoID, "recordatorio", uuid.Nil, enviarEn, 0)
+	if err != nil {
+		t.Fatalf("ScheduleDirect: %v", err)
+	}
+	esperarHasta(t, func() bool { return b.estado(p.ID()) == model.ProgramadoPendiente })
+
+	if _, err := b.servicio.Cancel(destinoID, p.ID()); err != ErrProgramadoSinPermiso {
+		t.Errorf("solo el remitente cancela: esperado %v, obtuvo %v", ErrProgramadoSinPermiso, err)
+	}
+	if _, err := b.servicio.Cancel(remitenteID, uuid.New()); err != ErrProgramadoNoEncontrado {
+		t.Errorf("esperado %v, obtuvo %v", ErrProgramadoNoEncontrado, err)
+	}
+	cancelado, err := b.servicio.Cancel(remitenteID, p.ID())
+	if err != nil || cancelado.Estado() != model.ProgramadoCancelado {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if _, err := b.servicio.Cancel(remitenteID, p.ID()); err != model.ErrProgramadoNoPendiente {
+		t.Errorf("cancelar dos veces: esperado %v, obtuvo %v", model.ErrProgramadoNoPendiente, err)
+	}
+
+	esperarHasta(t, func() bool { return a.estado(p.ID()) == model.ProgramadoCancelado })
+	a.procesar(enviarEn)
+	if a.envio.totalEnviados() != 0 {
+		t.Error("un mensaje cancelado no se envía")
+	}
+
+	pagina, err := b.servicio.ListScheduled(remitenteID, model.ConsultaPagina{})
+	if err != nil || len(pagina.Elementos) != 1 || pagina.Elementos[0].Estado() != model.ProgramadoCancelado {
+		t.Errorf("ListScheduled: %v %v", err, pagina)
+	}
+}
+
+func TestScheduled_EnvioInmediatoYReinicio(t *testing.T) {
+	n := nuevoNodoProgramado(t, nuevaRedFalsa())
+	remitenteID, destinoID := uuid.New(), uuid.New()
+
+	// Sin fecha se envía en el acto
+	p, err := n.servicio.ScheduleDirect(remitenteID, destinoID, "se autodestruye", uuid.Nil, time.Time{}, 10*time.Second)
+	if err != nil {
+		t.Fatalf("ScheduleDirect: %v", err)
+	}
+	if p.Estado() != model.ProgramadoEnviado || n.envio.totalEnviados() != 1 || p.ExpiraEn().IsZero() {
+		t.Fatalf("esperado un envío inmediato con expiración: %s %d", p.Estado(), n.envio.totalEnviados())
+	}
+
+	// Un envío rechazado no se reintenta
+	rechazo := errors.New("destinatario inexistente")
+	n.envio.rechazo = rechazo
+	fallido, err := n.servicio.ScheduleDirect(remitenteID, destinoID, "hola", uuid.Nil, time.Time{}, 0)
+	if err != rechazo || fallido.Estado() != model.ProgramadoFallido {
+		t.Fatalf("esperado el rechazo del envío, obtuvo %v", err)
+	}
+	n.envio.rechazo = nil
+
+	// Un envío reservado que un reinicio dejó sin confirmar se da por fallido
+	interrumpido, _ := model.NewMensajeProgramado(uuid.New(), n.id, remitenteID, destinoID, uuid.Nil, "interrumpido", uuid.Nil, time.Now().Add(-time.Minute), 0)
+	interrumpido.Reservar()
+	n.programados.Save(context.Background(), interrumpido)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n.servicio.Start(ctx)
+	esperarHasta(t, func() bool { return n.estado(interrumpido.ID()) == model.ProgramadoFallido })
+	if n.envio.totalEnviados() != 1 {
+		t.Errorf("el envío interrumpido no debe repetirse, enviados %d", n.envio.totalEnviados())
+	}
+
+	if _, err := n.servicio.ScheduleChannel(remitenteID, uuid.New(), "", uuid.Nil, time.Time{}, 0); err != model.ErrContenidoVacio {
+		t.Errorf("esperado %v, obtuvo %v", model.ErrContenidoVacio, err)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// calcularTablaRutas devuelve, para cada nodo alcanzable, el vecino directo por el que
+// sale el camino más corto desde origen. Las adyacencias anunciadas se tratan como
+// enlaces bidireccionales; los empates se resuelven por ID para que la tabla sea estable.
+func calcularTablaRutas(origen uuid.UUID, vecinos []uuid.UUID, adyacencias map[uuid.UUID][]uuid.UUID) map[uuid.UUID]uuid.UUID {
+	grafo := make(map[uuid.UUID]map[uuid.UUID]bool)
+	enlazar := func(a, b uuid.UUID) {
+		if a == b {
+			return
+		}
+		if grafo[a] == nil {
+			grafo[a] = make(map[uuid.UUID]bool)
+		}
+		if grafo[b] == nil {
+			grafo[b] = make(map[uuid.UUID]bool)
+		}
+		grafo[a][b] = true
+		grafo[b][a] = true
+	}
+	for nodo, lista := range adyacencias {
+		// Los enlaces propios los decide el transporte, no los anuncios de otros
+		if nodo == origen {
+			continue
+		}
+		for _, v := range lista {
+			if v != origen {
+				enlazar(nodo, v)
+			}
+		}
+	}
+
+	rutas := make(map[uuid.UUID]uuid.UUID)
+	cola := ordenarIDs(vecinos)
+	for _, v := range cola {
+		rutas[v] = v
+	}
+	for len(cola) > 0 {
+		actual := cola[0]
+		cola = cola[1:]
+
+		siguientes := make([]uuid.UUID, 0, len(grafo[actual]))
+		for w := range grafo[actual] {
+			siguientes = append(siguientes, w)
+		}
+		for _, w := range ordenarIDs(siguientes) {
+			if _, conocido := rutas[w]; conocido || w == origen {
+				continue
+			}
+			rutas[w] = rutas[actual]
+			cola = append(cola, w)
+		}
+	}
+	return rutas
+}
+
+// ordenarIDs devuelve una copia de los IDs ordenada
+func ordenarIDs(ids []uuid.UUID) []uuid.UUID {
+	copia := append([]uuid.UUID(nil), ids...)
+	sort.Slice(copia, func(i, j int) bool { return copia[i].String() < copia[j].String() })
+	return copia
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"model"
+	repository "repository.interfaces"
+)
+
+// Errores de los hilos de mensajes
+var (
+	ErrHiloNoEncontrado = errors.New("mensaje no encontrado")
+	ErrHiloSinAcceso    = errors.New("el usuario no puede ver la conversación del hilo")
+)
+
+// longitudExtractoRespuesta es el número máximo de caracteres de la respuesta que se
+// incluyen en la notificación a los participantes del hilo
+const longitudExtractoRespuesta = 80
+
+// ThreadService define los hilos de respuestas de los mensajes. Los hilos tienen un solo
+// nivel: una respuesta a otra respuesta se publica en el hilo de la raíz.
+type ThreadService interface {
+	// Reply publica una respuesta en el hilo de parentID, en el mismo destino que la
+	// raíz, y notifica a los participantes del hilo (el autor de la raíz y quienes ya
+	// respondieron) con NotificationService
+	Reply(
+		remitenteID, parentID uuid.UUID,
+		contenido string,
+		archivoID uuid.UUID,
+	) (*model.MensajeServidor, error)
+
+	// GetThread devuelve el resumen del hilo de parentID y una página de sus respuestas
+	// en orden cronológico
+	GetThread(
+		userID, parentID uuid.UUID,
+		consulta model.ConsultaPagina,
+	) (*model.ResumenHilo, *model.Pagina[*model.MensajeServidor], error)
+
+	// ListThreads devuelve una página de los hilos con respuestas de un canal, con el
+	// número de respuestas y la fecha de la última
+	ListThreads(
+		userID, channelID uuid.UUID,
+		consulta model.ConsultaPagina,
+	) (*model.Pagina[*model.ResumenHilo], error)
+}
+
+// threadService implementa ThreadService sobre los repositorios de mensajes y canales
+type threadService struct {
+	channels      repository.IChannelRepository
+	messages      repository.IMessageRepository
+	notifications NotificationService
+}
+
+// NewThreadService crea el servicio de hilos
+func NewThreadService(
+	channels repository.IChannelRepository,
+	messages repository.IMessageRepository,
+	notifications NotificationService,
+) ThreadService {
+	return &threadService{channels: channels, messages: messages, notifications: notifications}
+}
+
+// Reply implementa ThreadService
+func (s *threadService) Reply(
+	remitenteID, parentID uuid.UUID,
+	contenido string,
+	archivoID uuid.UUID,
+) (*model.MensajeServidor, error) {
+	ctx := context.Background()
+	raiz, err := s.raiz(ctx, remitenteID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if raiz.Eliminado() {
+		return nil, model.ErrMensajeEliminado
+	}
+
+	respuesta, err := nuevaRespuesta(remitenteID, raiz, contenido, archivoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := respuesta.ResponderA(raiz); err != nil {
+		return nil, err
+	}
+	if err := s.messages.Save(ctx, respuesta); err != nil {
+		return nil, err
+	}
+
+	s.notificar(ctx, raiz, respuesta)
+	return respuesta, nil
+}
+
+// GetThread implementa ThreadService
+func (s *threadService) GetThread(
+	userID, parentID uuid.UUID,
+	consulta model.ConsultaPagina,
+) (*model.ResumenHilo, *model.Pagina[*model.MensajeServidor], error) {
+	ctx := context.Background()
+	raiz, err := s.raiz(ctx, userID, parentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	resumen, err := s.messages.FindThreadSummary(ctx, raiz.ID())
+	if err != nil {
+		return nil, nil, err
+	}
+	if resumen == nil {
+		return nil, nil, ErrHiloNoEncontrado
+	}
+	respuestas, err := s.messages.FindReplies(ctx, raiz.ID(), consulta)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resumen, respuestas, nil
+}
+
+// ListThreads implementa ThreadService
+func (s *threadService) ListThreads(
+	userID, channelID uuid.UUID,
+	consulta model.ConsultaPagina,
+) (*model.Pagina[*model.ResumenHilo], error) {
+	ctx := context.Background()
+	miembro, err := esMiembroCanal(ctx, s.channels, channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !miembro {
+		return nil, ErrHiloSinAcceso
+	}
+	return s.messages.FindThreads(ctx, channelID, consulta)
+}
+
+// raiz devuelve el mensaje raíz del hilo de messageID, que puede ser la raíz o una de
+// sus respuestas, comprobando que el usuario pueda verlo
+func (s *threadService) raiz(ctx context.Context, userID, messageID uuid.UUID) (*model.MensajeServidor, error) {
+	m, err := s.messages.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil && m.EsRespuesta() {
+		m, err = s.messages.FindByID(ctx, m.PadreID())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m == nil {
+		return nil, ErrHiloNoEncontrado
+	}
+
+	visible, err := puedeVerMensaje(ctx, s.channels, userID, m)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrHiloSinAcceso
+	}
+	return m, nil
+}
+
+// nuevaRespuesta crea un mensaje con el mismo destino que la raíz del hilo. En los
+// mensajes directos la respuesta va al otro usuario de la conversación.
+func nuevaRespuesta(remitenteID uuid.UUID, raiz *model.MensajeServidor, contenido string, archivoID uuid.UUID) (*model.MensajeServidor, error) {
+	ahora := time.Now().UTC()
+	switch {
+	case raiz.CanalID() != uuid.Nil:
+		return model.NewMensajeCanal(uuid.New(), remitenteID, raiz.CanalID(), contenido, ahora, archivoID)
+	case raiz.ChatPrivadoID() != uuid.Nil:
+		return model.NewMensajeChatPrivado(uuid.New(), remitenteID, raiz.ChatPrivadoID(), contenido, ahora, archivoID)
+	}
+	destinoID := raiz.DestinoUsuarioID()
+	if remitenteID == destinoID {
+		destinoID = raiz.RemitenteID()
+	}
+	return model.NewMensajeDirecto(uuid.New(), remitenteID, destinoID, contenido, ahora, archivoID)
+}
+
+// notificar avisa de la respuesta a los participantes del hilo salvo a su autor. En un
+// canal solo se avisa a quienes siguen siendo miembros. Un fallo al notificar no anula
+// la respuesta ya guardada.
+func (s *threadService) notificar(ctx context.Context, raiz, respuesta *model.MensajeServidor) {
+	if s.notifications == nil {
+		return
+	}
+	participantes, err := s.participantes(ctx, raiz)
+	if err != nil {
+		return
+	}
+	var miembros map[uuid.UUID]bool
+	if raiz.CanalID() != uuid.Nil {
+		ids, err := s.channels.ListMembers(ctx, raiz.CanalID())
+		if err != nil {
+			return
+		}
+		miembros = make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			miembros[id] = true
+		}
+	}
+
+	contenido := "Nueva respuesta en un hilo en el que participas: " + extracto(respuesta.Contenido())
+	for _, usuarioID := range participantes {
+		if usuarioID == respuesta.RemitenteID() || (miembros != nil && !miembros[usuarioID]) {
+			continue
+		}
+		_, _ = s.notifications.Notify(usuarioID, contenido)
+	}
+}
+
+// participantes devuelve el autor de la raíz y los autores de las respuestas del hilo,
+// sin repetir y en orden de participación
+func (s *threadService) participantes(ctx context.Context, raiz *model.MensajeServidor) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{raiz.RemitenteID()}
+	vistos := map[uuid.UUID]bool{raiz.RemitenteID(): true}
+
+	consulta, err := model.NewConsultaPagina("", model.PaginaPosteriores, model.LimitePaginaMaximo)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pagina, err := s.messages.FindReplies(ctx, raiz.ID(), consulta)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range pagina.Elementos {
+			if !vistos[m.RemitenteID()] {
+				vistos[m.RemitenteID()] = true
+				ids = append(ids, m.RemitenteID())
+			}
+		}
+		if !pagina.HayPosteriores {
+			return ids, nil
+		}
+		if consulta, err = model.NewConsultaPagina(pagina.Siguiente, model.PaginaPosteriores, model.LimitePaginaMaximo); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// extracto recorta el contenido de una respuesta para la notificación
+func extracto(contenido string) string {
+	if utf8.RuneCountInString(contenido) <= longitudExtractoRespuesta {
+		return contenido
+	}
+	return string([]rune(contenido)[:longitudExtractoRespuesta]) + "…"
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+func TestThread_ReplyYNotificaciones(t *testing.T) {
+	ctx := context.Background()
+	canales, mensajes, notificaciones := nuevosCanalesFalsos(), nuevosMensajesFalsos(), nuevasNotificacionesFalsas()
+	s := NewThreadService(canales, mensajes, notificaciones)
+
+	autorID, anaID, betoID, exMiembroID, ajenoID, canalID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{autorID, anaID, betoID, exMiembroID} {
+		canales.AddMember(ctx, canalID, id, "member")
+	}
+	raiz, _ := model.NewMensajeCanal(uuid.New(), autorID, canalID, "¿Quién revisa el informe?", time.Now().Add(-time.Hour).UTC(), uuid.Nil)
+	mensajes.Save(ctx, raiz)
+
+	if _, err := s.Reply(ajenoID, raiz.ID(), "yo", uuid.Nil); err != ErrHiloSinAcceso {
+		t.Errorf("respuesta de un no miembro: esperado %v, obtuvo %v", ErrHiloSinAcceso, err)
+	}
+	if _, err := s.Reply(anaID, uuid.New(), "yo", uuid.Nil); err != ErrHiloNoEncontrado {
+		t.Errorf("hilo inexistente: esperado %v, obtuvo %v", ErrHiloNoEncontrado, err)
+	}
+
+	primera, err := s.Reply(exMiembroID, raiz.ID(), "Yo no puedo", uuid.Nil)
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if primera.PadreID() != raiz.ID() || primera.CanalID() != canalID {
+		t.Errorf("respuesta mal enlazada: padre=%v canal=%v", primera.PadreID(), primera.CanalID())
+	}
+	canales.RemoveMember(ctx, canalID, exMiembroID)
+
+	// Responder a una respuesta publica en el hilo de la raíz
+	segunda, err := s.Reply(anaID, primera.ID(), "Lo reviso yo", uuid.Nil)
+	if err != nil {
+		t.Fatalf("Reply a una respuesta: %v", err)
+	}
+	if segunda.PadreID() != raiz.ID() {
+		t.Errorf("la respuesta anidada debe ir al hilo de la raíz, padre=%v", segunda.PadreID())
+	}
+
+	// El autor recibe ambas respuestas; el ex miembro, ninguna; nadie se notifica a sí mismo
+	esperadas := map[uuid.UUID]int{autorID: 2, exMiembroID: 0, anaID: 0, betoID: 0}
+	for id, n := range esperadas {
+		if lista, _ := notificaciones.List(id); len(lista) != n {
+			t.Errorf("usuario %v: esperadas %d notificaciones, obtuvo %d", id, n, len(lista))
+		}
+	}
+
+	// Una respuesta de beto notifica al autor y a ana, pero no al ex miembro
+	if _, err := s.Reply(betoID, raiz.ID(), "Te ayudo", uuid.Nil); err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if lista, _ := notificaciones.List(anaID); len(lista) != 1 {
+		t.Errorf("ana debe recibir 1 notificación, obtuvo %d", len(lista))
+	}
+	if lista, _ := notificaciones.List(exMiembroID); len(lista) != 0 {
+		t.Errorf("el ex miembro no debe recibir notificaciones, obtuvo %d", len(lista))
+	}
+}
+
+func TestThread_GetThreadYListThreads(t *testing.T) {
+	ctx := context.Background()
+	canales, mensajes := nuevosCanalesFalsos(), nuevosMensajesFalsos()
+	s := NewThreadService(canales, mensajes, nil)
+
+	anaID, betoID, canalID := uuid.New(), uuid.New(), uuid.New()
+	canales.AddMember(ctx, canalID, anaID, "member")
+	canales.AddMember(ctx, canalID, betoID, "member")
+	raiz, _ := model.NewMensajeCanal(uuid.New(), anaID, canalID, "raíz", time.Now().Add(-time.Hour).UTC(), uuid.Nil)
+	suelto, _ := model.NewMensajeCanal(uuid.New(), anaID, canalID, "suelto", time.Now().Add(-time.Hour).UTC(), uuid.Nil)
+	mensajes.Save(ctx, raiz)
+	mensajes.Save(ctx, suelto)
+
+	var ultima *model.MensajeServidor
+	for _, texto := range []string{"uno", "dos", "tres"} {
+		m, err := s.Reply(betoID, raiz.ID(), texto, uuid.Nil)
+		if err != nil {
+			t.Fatalf("Reply: %v", err)
+		}
+		ultima = m
+	}
+
+	consulta, _ := model.NewConsultaPagina("", model.PaginaPosteriores, 2)
+	resumen, pagina, err := s.GetThread(anaID, ultima.ID(), consulta)
+	if err != nil {
+		t.Fatalf("GetThread: %v", err)
+	}
+	if resumen.Raiz().ID() != raiz.ID() || resumen.Respuestas() != 3 || !resumen.UltimaRespuesta().Equal(ultima.Timestamp()) {
+		t.Errorf("resumen inesperado: raíz=%v respuestas=%d última=%v", resumen.Raiz().ID(), resumen.Respuestas(), resumen.UltimaRespuesta())
+	}
+	if len(pagina.Elementos) != 2 || pagina.Elementos[0].Contenido() != "uno" || !pagina.HayPosteriores {
+		t.Errorf("primera página de respuestas inesperada: %d elementos", len(pagina.Elementos))
+	}
+	if _, _, err := s.GetThread(uuid.New(), raiz.ID(), consulta); err != ErrHiloSinAcceso {
+		t.Errorf("GetThread de un no miembro: esperado %v, obtuvo %v", ErrHiloSinAcceso, err)
+	}
+
+	hilos, err := s.ListThreads(betoID, canalID, model.ConsultaPagina{})
+	if err != nil {
+		t.Fatalf("ListThreads: %v", err)
+	}
+	if len(hilos.Elementos) != 1 || hilos.Elementos[0].Raiz().ID() != raiz.ID() {
+		t.Errorf("solo el mensaje con respuestas es un hilo, obtuvo %d", len(hilos.Elementos))
+	}
+	if _, err := s.ListThreads(uuid.New(), canalID, model.ConsultaPagina{}); err != ErrHiloSinAcceso {
+		t.Errorf("ListThreads de un no miembro: esperado %v, obtuvo %v", ErrHiloSinAcceso, err)
+	}
+}
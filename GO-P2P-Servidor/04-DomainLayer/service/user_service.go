@@ -1,10 +1,17 @@
 package service
 
 import (
+	"context"
+	"errors"
+
 	"github.com/google/uuid"
 	"model"
+	repository "repository.interfaces"
 )
 
+// ErrUsuarioNoEncontrado se devuelve al consultar o modificar un usuario que no existe
+var ErrUsuarioNoEncontrado = errors.New("usuario no encontrado")
+
 // UserService define las operaciones para gestión de perfiles y listado de usuarios
 type UserService interface {
 	// GetAll obtiene todos los usuarios del sistema
@@ -19,3 +26,52 @@ type UserService interface {
 		nombre, email, foto string,
 	) (*model.UsuarioServidor, error)
 }
+
+// userService implementa UserService sobre el repositorio de usuarios
+type userService struct {
+	users repository.IUserRepository
+	reloj *model.RelojHLC
+}
+
+// NewUserService crea el servicio de usuarios. reloj debe ser el reloj HLC del propio
+// nodo: versiona los perfiles modificados para que la anti-entropía los replique.
+func NewUserService(users repository.IUserRepository, reloj *model.RelojHLC) UserService {
+	return &userService{users: users, reloj: reloj}
+}
+
+// GetAll implementa UserService
+func (s *userService) GetAll() ([]*model.UsuarioServidor, error) {
+	return s.users.FindAll(context.Background())
+}
+
+// GetByID implementa UserService
+func (s *userService) GetByID(userID uuid.UUID) (*model.UsuarioServidor, error) {
+	u, err := s.users.FindByID(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUsuarioNoEncontrado
+	}
+	return u, nil
+}
+
+// UpdateProfile implementa UserService; la contraseña y los datos de registro no cambian
+func (s *userService) UpdateProfile(userID uuid.UUID, nombre, email, foto string) (*model.UsuarioServidor, error) {
+	actual, err := s.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := model.NewUsuarioServidor(
+		userID, nombre, email, actual.ContrasenaHasheada(), foto, actual.IPRegistrada(), actual.FechaRegistro(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	u.SetConnected(actual.IsConnected())
+	u.SetVersion(s.reloj.Now())
+	if err := s.users.Update(context.Background(), u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -1,12 +1,24 @@
 package main
 
 import (
+	"context"
+	"dao"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"migration"
 	"net"
+	"pool"
+	"repository"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"model"
+	"service"
 )
 
 // Estructura general del mensaje recibido
@@ -15,18 +27,6 @@ type Message struct {
 	Data    json.RawMessage `json:"data"`
 }
 
-// Estructura del usuario
-type User struct {
-	ID          string `json:"id"`
-	Email       string `json:"email"`
-	Nombre      string `json:"nombre"`
-	Password    string `json:"password"`
-	Foto        string `json:"foto"`
-	IP          string `json:"ip"`
-	CreatedAt   string `json:"created_at"`
-	IsConnected bool   `json:"is_connected"`
-}
-
 // Solicitudes
 type RegisterRequest struct {
 	Email    string `json:"email"`
@@ -39,6 +39,90 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// Fechas en RFC3339; los filtros vacíos no restringen
+type SearchMessagesRequest struct {
+	Query         string `json:"query"`
+	SenderID      string `json:"sender_id"`
+	ChannelID     string `json:"channel_id"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	HasAttachment bool   `json:"has_attachment"`
+	Cursor        string `json:"cursor"`
+	Direction     string `json:"direction"`
+	Limit         int    `json:"limit"`
+}
+
+// MessageID puede ser la raíz del hilo o cualquiera de sus respuestas
+type GetThreadRequest struct {
+	MessageID string `json:"message_id"`
+	Cursor    string `json:"cursor"`
+	Direction string `json:"direction"`
+	Limit     int    `json:"limit"`
+}
+
+// Emoji es el emoji de la reacción que se añade o se retira
+type ReactionRequest struct {
+	MessageID string `json:"message_id"`
+	Emoji     string `json:"emoji"`
+}
+
+// MessageIDs son los mensajes recibidos en ack-delivery; MessageID el mensaje hasta el
+// que se leyó en mark-read o cuyos acuses se piden en get-receipts
+type ReceiptRequest struct {
+	MessageID  string   `json:"message_id"`
+	MessageIDs []string `json:"message_ids"`
+}
+
+// Cursor es la posición de un evento de la cola: replay-queue devuelve los posteriores y
+// ack-queue confirma hasta ella, incluida
+type QueueRequest struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+// ConversationID es el canal, el chat privado o, en un mensaje directo, el otro usuario;
+// Type es ESCRIBIENDO o VIENDO
+type ActivityRequest struct {
+	ConversationID string `json:"conversation_id"`
+	Type           string `json:"type"`
+}
+
+// Para schedule-message: RecipientID o ChannelID, SendAt en RFC3339 (vacío envía en el
+// acto) y LifetimeSeconds, si no es cero, el tiempo tras el envío en que se elimina.
+// ScheduledID es el mensaje programado de cancel-scheduled; Cursor y Limit paginan
+// list-scheduled
+type ScheduleRequest struct {
+	RecipientID     string `json:"recipient_id"`
+	ChannelID       string `json:"channel_id"`
+	Content         string `json:"content"`
+	SendAt          string `json:"send_at"`
+	LifetimeSeconds int    `json:"lifetime_seconds"`
+	ScheduledID     string `json:"scheduled_id"`
+	Cursor          string `json:"cursor"`
+	Limit           int    `json:"limit"`
+}
+
+// Para mute-mentions: ChannelID vacío configura todos los canales, Level es NINGUNO,
+// CANAL o TODO y Until, en RFC3339, el fin del silencio (vacío, sin fin). Cursor y Limit
+// paginan list-mentions
+type MentionRequest struct {
+	ChannelID string `json:"channel_id"`
+	Level     string `json:"level"`
+	Until     string `json:"until"`
+	Cursor    string `json:"cursor"`
+	Limit     int    `json:"limit"`
+}
+
+// MessageID es el mensaje de pin-message; URL y Title, opcional, el enlace de pin-link;
+// PinID el elemento que se desfija en unpin
+type PinRequest struct {
+	ChannelID string `json:"channel_id"`
+	MessageID string `json:"message_id"`
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	PinID     string `json:"pin_id"`
+}
+
 // Estructura de respuesta
 type GenericResponse struct {
 	Status  string      `json:"status"`
@@ -46,28 +130,108 @@ type GenericResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// Usuarios simulados (iniciales)
-var users = []User{
-	{
-		ID:          uuid.New().String(),
-		Email:       "juan@example.com",
-		Nombre:      "juan123",
-		Password:    "1234",
-		Foto:        "No disponible",
-		IP:          "127.0.0.1",
-		CreatedAt:   "2025-04-28T10:30:00",
-		IsConnected: true,
-	},
-	{
-		ID:          uuid.New().String(),
-		Email:       "ana@example.com",
-		Nombre:      "ana456",
-		Password:    "5678",
-		Foto:        "No disponible",
-		IP:          "127.0.0.2",
-		CreatedAt:   "2025-04-28T11:00:00",
-		IsConnected: false,
-	},
+// Servicio de búsqueda de mensajes; se asigna en iniciarServicios antes de aceptar conexiones
+var messageSearch service.MessageSearchService
+
+// Servicio de hilos de mensajes; se asigna igual que messageSearch
+var threads service.ThreadService
+
+// Servicio de reacciones a mensajes; se asigna igual que messageSearch
+var reactions service.ReactionService
+
+// Servicio de acuses de entrega y lectura; se asigna igual que messageSearch
+var receipts service.ReceiptService
+
+// Cola de eventos pendientes de los usuarios desconectados; se asigna igual que messageSearch
+var offlineQueue service.OfflineQueueService
+
+// Actividad efímera de los usuarios (escribiendo, viendo); se asigna igual que messageSearch
+var activities service.ActivityService
+
+// Mensajes programados y con caducidad; se asigna igual que messageSearch
+var scheduled service.ScheduledMessageService
+
+// Menciones y preferencias de silencio de menciones; se asigna igual que messageSearch
+var mentions service.MentionService
+
+// Mensajes y enlaces fijados en los canales; se asigna igual que messageSearch
+var pins service.PinService
+
+// Registro y autenticación contra el repositorio de usuarios; se asigna igual que messageSearch
+var auth service.AuthService
+
+// Perfiles de los usuarios registrados; se asigna igual que messageSearch
+var profiles service.UserService
+
+// Presencia replicada de los usuarios del clúster; se asigna igual que messageSearch
+var presence service.PresenceService
+
+// Sockets de los clientes de este nodo; se asigna igual que messageSearch
+var sockets clientesNodo
+
+// Sesión abierta en una conexión: el usuario autenticado y el ID de la sesión de presencia
+type sesionCliente struct {
+	usuario uuid.UUID
+	id      uuid.UUID
+}
+
+// Sesión de cada conexión; handleLogin la abre y los demás manejadores actúan siempre en
+// nombre de su usuario
+var (
+	sessionsMu sync.Mutex
+	sessions   = map[net.Conn]sesionCliente{}
+)
+
+// Asocia el usuario autenticado a la conexión: abre una sesión de presencia propia de la
+// conexión y registra el socket para que le lleguen los eventos en vivo. Un nuevo login
+// en la misma conexión cierra antes la sesión anterior.
+func startSession(conn net.Conn, userID uuid.UUID) error {
+	endSession(conn)
+
+	sesion := sesionCliente{usuario: userID, id: uuid.New()}
+	if err := presence.MarkConnected(userID, sesion.id); err != nil {
+		return err
+	}
+	if err := sockets.Register(userID, conn); err != nil {
+		presence.MarkDisconnected(userID, sesion.id)
+		return err
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	sessions[conn] = sesion
+	return nil
+}
+
+// Cierra la sesión de la conexión: libera su socket, si otra conexión del mismo usuario
+// no lo ha reemplazado, y su sesión de presencia
+func endSession(conn net.Conn) {
+	sessionsMu.Lock()
+	sesion, ok := sessions[conn]
+	delete(sessions, conn)
+	sessionsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	if sockets.Unregister(sesion.usuario, conn) {
+		presence.Unsubscribe(sesion.usuario)
+	}
+	if err := presence.MarkDisconnected(sesion.usuario, sesion.id); err != nil {
+		fmt.Println("[ERROR] No se pudo cerrar la sesión de presencia:", err)
+	}
+}
+
+// Devuelve el usuario autenticado en la conexión; si no hay ninguno responde con un
+// error y devuelve false
+func sessionUser(conn net.Conn) (uuid.UUID, bool) {
+	sessionsMu.Lock()
+	sesion, ok := sessions[conn]
+	sessionsMu.Unlock()
+	if !ok {
+		sendResponse(conn, GenericResponse{"error", "Debe iniciar sesión", nil})
+	}
+	return sesion.usuario, ok
 }
 
 // Enviar respuestas al cliente
@@ -90,33 +254,35 @@ func handleLogin(conn net.Conn, msg Message) {
 		return
 	}
 
-	var user *User
-	for i := range users {
-		if users[i].Email == request.Email && users[i].Password == request.Password {
-			user = &users[i]
-			break
-		}
-	}
-
-	if user == nil {
+	user, err := auth.Login(request.Email, request.Password, remoteIP(conn))
+	if errors.Is(err, service.ErrCredencialesInvalidas) {
 		fmt.Println("[DEBUG] Usuario no encontrado o credenciales incorrectas")
 		sendResponse(conn, GenericResponse{"error", "Email o contraseña incorrectos", nil})
 		return
 	}
+	if err != nil {
+		fmt.Println("[ERROR] Login:", err)
+		sendResponse(conn, GenericResponse{"error", "No se pudo iniciar sesión", nil})
+		return
+	}
 
-	fmt.Printf("[DEBUG] Usuario encontrado: %v\n", user) // Debug: usuario encontrado
+	fmt.Printf("[DEBUG] Usuario encontrado: %v\n", user.ID()) // Debug: usuario encontrado
+	if err := startSession(conn, user.ID()); err != nil {
+		fmt.Println("[ERROR] No se pudo abrir la sesión:", err)
+		sendResponse(conn, GenericResponse{"error", "No se pudo iniciar sesión", nil})
+		return
+	}
+	data := userData(user)
+	// Primera página de lo recibido sin conexión; el resto se pide con replay-queue
+	if pagina, err := offlineQueue.Replay(user.ID(), model.ConsultaPagina{}); err == nil {
+		data["pending"] = queueData(pagina)
+	} else {
+		fmt.Println("[DEBUG] Error al leer la cola de eventos pendientes:", err)
+	}
 	sendResponse(conn, GenericResponse{
 		Status:  "success",
 		Message: "Inicio de sesión exitoso",
-		Data: map[string]interface{}{
-			"id":           user.ID,
-			"nombre":       user.Nombre,
-			"email":        user.Email,
-			"photo":        user.Foto,
-			"ip":           user.IP,
-			"created_at":   user.CreatedAt,
-			"is_connected": user.IsConnected,
-		},
+		Data:    data,
 	})
 }
 
@@ -129,79 +295,870 @@ func handleRegister(conn net.Conn, msg Message) {
 		return
 	}
 
-	// Verifica email duplicado
-	for _, user := range users {
-		if user.Email == request.Email {
-			fmt.Println("[DEBUG] Email duplicado detectado:", request.Email)
-			sendResponse(conn, GenericResponse{"error", "El email ya está registrado", nil})
-			return
-		}
-	}
-
-	// Crear nuevo usuario
-	newUser := User{
-		ID:          uuid.New().String(),
-		Email:       request.Email,
-		Nombre:      request.Nombre,
-		Password:    request.Password,
-		Foto:        "No disponible",
-		IP:          "127.0.0.1", // podrías obtenerla desde conn.RemoteAddr()
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		IsConnected: false,
+	newUser, err := auth.Register(request.Nombre, request.Email, request.Password, "", remoteIP(conn))
+	switch {
+	case errors.Is(err, service.ErrEmailRegistrado):
+		fmt.Println("[DEBUG] Email duplicado detectado:", request.Email)
+		sendResponse(conn, GenericResponse{"error", "El email ya está registrado", nil})
+		return
+	case errors.Is(err, service.ErrContrasenaVacia), errors.Is(err, model.ErrNombreVacio), errors.Is(err, model.ErrEmailInvalido):
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	case err != nil:
+		fmt.Println("[ERROR] Registro:", err)
+		sendResponse(conn, GenericResponse{"error", "No se pudo completar el registro", nil})
+		return
 	}
-	users = append(users, newUser)
 
-	fmt.Printf("[DEBUG] Usuario registrado: %v\n", newUser) // Debug: nuevo usuario registrado
+	fmt.Printf("[DEBUG] Usuario registrado: %v\n", newUser.ID()) // Debug: nuevo usuario registrado
 
 	sendResponse(conn, GenericResponse{
 		Status:  "success",
 		Message: "Registro exitoso",
-		Data: map[string]interface{}{
-			"id":           newUser.ID,
-			"nombre":       newUser.Nombre,
-			"email":        newUser.Email,
-			"photo":        newUser.Foto,
-			"ip":           newUser.IP,
-			"created_at":   newUser.CreatedAt,
-			"is_connected": newUser.IsConnected,
-		},
+		Data:    userData(newUser),
 	})
 }
 
+// La lista sale de la presencia replicada: los usuarios con alguna sesión abierta en
+// cualquier nodo del clúster
 func handleListUsers(conn net.Conn) {
-    // Si no hay usuarios, enviar error
-	if len(users) == 0 {
+	conectados, err := presence.ListConnected()
+	if err != nil {
+		fmt.Println("[ERROR] Listar usuarios conectados:", err)
 		sendResponse(conn, GenericResponse{
 			Status:  "error",
-			Message: "No se pudieron obtener los usuarios registrados",
+			Message: "No se pudieron obtener los usuarios conectados",
 			Data:    nil,
 		})
 		return
 	}
 
-    // Si hay usuarios, construir la lista de usuarios
-	var userList []map[string]interface{}
-	for _, user := range users {
+	userList := []map[string]interface{}{}
+	for _, user := range conectados {
 		userList = append(userList, map[string]interface{}{
-			"id":           user.ID,
-			"nombre":       user.Nombre,
-			"email":        user.Email,
-			"is_connected": user.IsConnected,
+			"id":           user.ID().String(),
+			"nombre":       user.NombreUsuario(),
+			"email":        user.Email(),
+			"is_connected": true,
 		})
 	}
 
-    // Enviar la respuesta con la lista de usuarios
 	sendResponse(conn, GenericResponse{
 		Status:  "success",
-		Message: "Usuarios registrados obtenidos correctamente",
+		Message: "Usuarios conectados obtenidos correctamente",
 		Data:    userList,
 	})
 }
 
+// Manejador de subscribe-presence y unsubscribe-presence: con la suscripción el cliente
+// recibe un evento PRESENCIA cada vez que un usuario se conecta o se desconecta
+func handlePresenceSubscription(conn net.Conn, msg Message) {
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+
+	if msg.Command == "subscribe-presence" {
+		presence.Subscribe(userID)
+		sendResponse(conn, GenericResponse{"success", "Suscrito a la presencia", nil})
+		return
+	}
+	presence.Unsubscribe(userID)
+	sendResponse(conn, GenericResponse{"success", "Suscripción a la presencia cancelada", nil})
+}
+
+// Manejador de búsqueda de mensajes
+func handleSearchMessages(conn net.Conn, msg Message) {
+	var request SearchMessagesRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar mensaje de búsqueda:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos de búsqueda", nil})
+		return
+	}
+	if messageSearch == nil {
+		sendResponse(conn, GenericResponse{"error", "La búsqueda de mensajes no está disponible", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+	filtros, err := parseSearchFilters(request)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+	consulta, err := model.NewConsultaPagina(request.Cursor, model.DireccionPagina(request.Direction), request.Limit)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+
+	pagina, err := messageSearch.SearchMessages(userID, request.Query, filtros, consulta)
+	if err != nil {
+		fmt.Println("[DEBUG] Error en la búsqueda de mensajes:", err)
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+
+	messageList := []map[string]interface{}{}
+	for _, m := range pagina.Elementos {
+		messageList = append(messageList, messageData(m))
+	}
+
+	sendResponse(conn, GenericResponse{
+		Status:  "success",
+		Message: "Búsqueda de mensajes completada",
+		Data: map[string]interface{}{
+			"messages":     messageList,
+			"previous":     pagina.Anterior,
+			"next":         pagina.Siguiente,
+			"has_previous": pagina.HayAnteriores,
+			"has_next":     pagina.HayPosteriores,
+		},
+	})
+}
+
+// Manejador de la consulta de un hilo: resumen de la raíz y una página de respuestas
+func handleGetThread(conn net.Conn, msg Message) {
+	var request GetThreadRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de hilo:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos del hilo", nil})
+		return
+	}
+	if threads == nil {
+		sendResponse(conn, GenericResponse{"error", "Los hilos de mensajes no están disponibles", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+	messageID, err := uuid.Parse(request.MessageID)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", "ID de mensaje inválido", nil})
+		return
+	}
+	consulta, err := model.NewConsultaPagina(request.Cursor, model.DireccionPagina(request.Direction), request.Limit)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+
+	resumen, pagina, err := threads.GetThread(userID, messageID, consulta)
+	if err != nil {
+		fmt.Println("[DEBUG] Error al obtener el hilo:", err)
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+
+	replyList := []map[string]interface{}{}
+	for _, m := range pagina.Elementos {
+		replyList = append(replyList, messageData(m))
+	}
+	lastReply := ""
+	if resumen.Respuestas() > 0 {
+		lastReply = resumen.UltimaRespuesta().Format(time.RFC3339)
+	}
+
+	sendResponse(conn, GenericResponse{
+		Status:  "success",
+		Message: "Hilo obtenido correctamente",
+		Data: map[string]interface{}{
+			"root":          messageData(resumen.Raiz()),
+			"reply_count":   resumen.Respuestas(),
+			"last_reply_at": lastReply,
+			"replies":       replyList,
+			"previous":      pagina.Anterior,
+			"next":          pagina.Siguiente,
+			"has_previous":  pagina.HayAnteriores,
+			"has_next":      pagina.HayPosteriores,
+		},
+	})
+}
+
+// Manejador de las reacciones: add-reaction la añade y remove-reaction la retira
+func handleReaction(conn net.Conn, msg Message, add bool) {
+	var request ReactionRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de reacción:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos de la reacción", nil})
+		return
+	}
+	if reactions == nil {
+		sendResponse(conn, GenericResponse{"error", "Las reacciones no están disponibles", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+	messageID, err := uuid.Parse(request.MessageID)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", "ID de mensaje inválido", nil})
+		return
+	}
+
+	reaction := reactions.AddReaction
+	message := "Reacción añadida"
+	if !add {
+		reaction = reactions.RemoveReaction
+		message = "Reacción retirada"
+	}
+	if _, err := reaction(userID, messageID, request.Emoji); err != nil {
+		fmt.Println("[DEBUG] Error en la reacción:", err)
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+
+	conteos, err := reactions.CountReactions([]uuid.UUID{messageID})
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+	sendResponse(conn, GenericResponse{
+		Status:  "success",
+		Message: message,
+		Data: map[string]interface{}{
+			"message_id": messageID.String(),
+			"reactions":  reactionData(conteos[messageID]),
+		},
+	})
+}
+
+// Manejador de los acuses: ack-delivery confirma la recepción de mensajes, mark-read
+// informa de la posición de lectura, get-receipts devuelve el estado de un mensaje
+// propio para cada destinatario y unread-counts los no leídos por conversación
+func handleReceipt(conn net.Conn, msg Message) {
+	var request ReceiptRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de acuse:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos del acuse", nil})
+		return
+	}
+	if receipts == nil {
+		sendResponse(conn, GenericResponse{"error", "Los acuses no están disponibles", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+	var messageID uuid.UUID
+	var err error
+	if msg.Command == "mark-read" || msg.Command == "get-receipts" {
+		if messageID, err = uuid.Parse(request.MessageID); err != nil {
+			sendResponse(conn, GenericResponse{"error", "ID de mensaje inválido", nil})
+			return
+		}
+	}
+
+	switch msg.Command {
+	case "ack-delivery":
+		var messageIDs []uuid.UUID
+		for _, id := range request.MessageIDs {
+			parsed, err := uuid.Parse(id)
+			if err != nil {
+				sendResponse(conn, GenericResponse{"error", "ID de mensaje inválido", nil})
+				return
+			}
+			messageIDs = append(messageIDs, parsed)
+		}
+		if err := receipts.AcknowledgeDelivery(userID, messageIDs); err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Recepción confirmada", nil})
+
+	case "mark-read":
+		marcador, err := receipts.MarkRead(userID, messageID)
+		if err != nil {
+			fmt.Println("[DEBUG] Error al marcar como leído:", err)
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{
+			Status:  "success",
+			Message: "Lectura registrada",
+			Data: map[string]interface{}{
+				"conversation_id":      marcador.ConversacionID().String(),
+				"last_read_message_id": marcador.MensajeID().String(),
+			},
+		})
+
+	case "get-receipts":
+		acuses, err := receipts.ListReceipts(userID, messageID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		receiptList := []map[string]interface{}{}
+		for _, a := range acuses {
+			receiptList = append(receiptList, map[string]interface{}{
+				"user_id":    a.UsuarioID().String(),
+				"state":      string(a.Estado()),
+				"updated_at": a.ActualizadoAt(),
+			})
+		}
+		sendResponse(conn, GenericResponse{
+			Status:  "success",
+			Message: "Acuses del mensaje",
+			Data:    map[string]interface{}{"message_id": messageID.String(), "receipts": receiptList},
+		})
+
+	default:
+		conteos, err := receipts.UnreadCounts(userID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		unread := map[string]int{}
+		for conversacionID, total := range conteos {
+			unread[conversacionID.String()] = total
+		}
+		sendResponse(conn, GenericResponse{"success", "Mensajes sin leer", map[string]interface{}{"unread": unread}})
+	}
+}
+
+// Manejador de la cola de eventos pendientes: replay-queue devuelve la página siguiente
+// al cursor y ack-queue confirma lo recibido hasta el cursor para que no se repita
+func handleQueue(conn net.Conn, msg Message) {
+	var request QueueRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de la cola:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos de la cola", nil})
+		return
+	}
+	if offlineQueue == nil {
+		sendResponse(conn, GenericResponse{"error", "La cola de eventos pendientes no está disponible", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+
+	if msg.Command == "ack-queue" {
+		posicion, err := model.ParseCursor(request.Cursor)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		borrados, err := offlineQueue.Acknowledge(userID, posicion)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		pendientes, err := offlineQueue.Pending(userID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{
+			Status:  "success",
+			Message: "Eventos confirmados",
+			Data:    map[string]interface{}{"acknowledged": borrados, "remaining": pendientes},
+		})
+		return
+	}
+
+	consulta, err := model.NewConsultaPagina(request.Cursor, model.PaginaPosteriores, request.Limit)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+	pagina, err := offlineQueue.Replay(userID, consulta)
+	if err != nil {
+		fmt.Println("[DEBUG] Error al leer la cola de eventos pendientes:", err)
+		sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+		return
+	}
+	sendResponse(conn, GenericResponse{"success", "Eventos pendientes", queueData(pagina)})
+}
+
+// Manejador de la actividad efímera: activity-start la informa o la renueva,
+// activity-stop la termina y list-activity devuelve la de los demás participantes
+func handleActivity(conn net.Conn, msg Message) {
+	var request ActivityRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de actividad:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos de la actividad", nil})
+		return
+	}
+	if activities == nil {
+		sendResponse(conn, GenericResponse{"error", "La actividad no está disponible", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+	conversationID, err := uuid.Parse(request.ConversationID)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", "ID de conversación inválido", nil})
+		return
+	}
+	tipo := model.TipoActividad(request.Type)
+
+	switch msg.Command {
+	case "activity-start":
+		actividad, err := activities.Report(userID, conversationID, tipo)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Actividad informada", activityData(actividad)})
+	case "activity-stop":
+		if err := activities.Stop(userID, conversationID, tipo); err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Actividad terminada", nil})
+	case "list-activity":
+		actividades, err := activities.ListActive(userID, conversationID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		activityList := []map[string]interface{}{}
+		for _, a := range actividades {
+			activityList = append(activityList, activityData(a))
+		}
+		sendResponse(conn, GenericResponse{"success", "Actividad de la conversación", map[string]interface{}{"activities": activityList}})
+	}
+}
+
+// Datos de una actividad que se envían al cliente
+func activityData(a *model.Actividad) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":         a.UsuarioID().String(),
+		"conversation_id": a.ConversacionID().String(),
+		"type":            string(a.Tipo()),
+		"expires_at":      a.Expira().Format(time.RFC3339),
+	}
+}
+
+// Manejador de los mensajes programados: schedule-message programa un mensaje directo
+// o de canal, cancel-scheduled lo cancela y list-scheduled pagina los del usuario
+func handleScheduled(conn net.Conn, msg Message) {
+	var request ScheduleRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de mensaje programado:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos del mensaje programado", nil})
+		return
+	}
+	if scheduled == nil {
+		sendResponse(conn, GenericResponse{"error", "Los mensajes programados no están disponibles", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+
+	switch msg.Command {
+	case "cancel-scheduled":
+		scheduledID, err := uuid.Parse(request.ScheduledID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", "ID de mensaje programado inválido", nil})
+			return
+		}
+		p, err := scheduled.Cancel(userID, scheduledID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Mensaje programado cancelado", scheduledData(p)})
+	case "list-scheduled":
+		consulta, err := model.NewConsultaPagina(request.Cursor, model.PaginaPosteriores, request.Limit)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		pagina, err := scheduled.ListScheduled(userID, consulta)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		scheduledList := []map[string]interface{}{}
+		for _, p := range pagina.Elementos {
+			scheduledList = append(scheduledList, scheduledData(p))
+		}
+		sendResponse(conn, GenericResponse{
+			Status:  "success",
+			Message: "Mensajes programados",
+			Data: map[string]interface{}{
+				"scheduled": scheduledList,
+				"next":      pagina.Siguiente,
+				"has_next":  pagina.HayPosteriores,
+			},
+		})
+	default:
+		var err error
+		var sendAt time.Time
+		if request.SendAt != "" {
+			if sendAt, err = time.Parse(time.RFC3339, request.SendAt); err != nil {
+				sendResponse(conn, GenericResponse{"error", "Fecha de envío inválida", nil})
+				return
+			}
+		}
+		lifetime := time.Duration(request.LifetimeSeconds) * time.Second
+
+		var p *model.MensajeProgramado
+		if request.ChannelID != "" {
+			var channelID uuid.UUID
+			if channelID, err = uuid.Parse(request.ChannelID); err != nil {
+				sendResponse(conn, GenericResponse{"error", "ID de canal inválido", nil})
+				return
+			}
+			p, err = scheduled.ScheduleChannel(userID, channelID, request.Content, uuid.Nil, sendAt, lifetime)
+		} else {
+			var recipientID uuid.UUID
+			if recipientID, err = uuid.Parse(request.RecipientID); err != nil {
+				sendResponse(conn, GenericResponse{"error", "ID de destinatario inválido", nil})
+				return
+			}
+			p, err = scheduled.ScheduleDirect(userID, recipientID, request.Content, uuid.Nil, sendAt, lifetime)
+		}
+		if err != nil {
+			fmt.Println("[DEBUG] Error al programar el mensaje:", err)
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Mensaje programado", scheduledData(p)})
+	}
+}
+
+// Datos de un mensaje programado que se envían al cliente
+func scheduledData(p *model.MensajeProgramado) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":               p.ID().String(),
+		"recipient_id":     optionalID(p.DestinoUsuarioID()),
+		"channel_id":       optionalID(p.CanalID()),
+		"content":          p.Contenido(),
+		"send_at":          p.EnviarEn().Format(time.RFC3339),
+		"lifetime_seconds": int64(p.Duracion() / time.Second),
+		"state":            string(p.Estado()),
+		"message_id":       optionalID(p.MensajeID()),
+	}
+	if !p.ExpiraEn().IsZero() {
+		data["expires_at"] = p.ExpiraEn().Format(time.RFC3339)
+	}
+	return data
+}
+
+// Manejador de las menciones: list-mentions pagina el feed de menciones del usuario,
+// mute-mentions configura su silencio en un canal o en todos y list-mutes lo devuelve
+func handleMentions(conn net.Conn, msg Message) {
+	var request MentionRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de menciones:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos de menciones", nil})
+		return
+	}
+	if mentions == nil {
+		sendResponse(conn, GenericResponse{"error", "Las menciones no están disponibles", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+
+	switch msg.Command {
+	case "mute-mentions":
+		var err error
+		channelID := uuid.Nil
+		if request.ChannelID != "" {
+			if channelID, err = uuid.Parse(request.ChannelID); err != nil {
+				sendResponse(conn, GenericResponse{"error", "ID de canal inválido", nil})
+				return
+			}
+		}
+		var until time.Time
+		if request.Until != "" {
+			if until, err = time.Parse(time.RFC3339, request.Until); err != nil {
+				sendResponse(conn, GenericResponse{"error", "Fecha de fin del silencio inválida", nil})
+				return
+			}
+		}
+		silencio, err := mentions.SetMute(userID, channelID, model.NivelSilencio(request.Level), until)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Silencio de menciones configurado", muteData(silencio)})
+	case "list-mutes":
+		silencios, err := mentions.ListMutes(userID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		muteList := []map[string]interface{}{}
+		for _, s := range silencios {
+			muteList = append(muteList, muteData(s))
+		}
+		sendResponse(conn, GenericResponse{"success", "Silencios de menciones", muteList})
+	default:
+		consulta, err := model.NewConsultaPagina(request.Cursor, model.PaginaPosteriores, request.Limit)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		pagina, err := mentions.ListMentions(userID, consulta)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		mentionList := []map[string]interface{}{}
+		for _, m := range pagina.Elementos {
+			mentionList = append(mentionList, map[string]interface{}{
+				"id":              m.ID().String(),
+				"message_id":      m.MensajeID().String(),
+				"channel_id":      m.CanalID().String(),
+				"sender_id":       m.RemitenteID().String(),
+				"type":            string(m.Tipo()),
+				"date":            m.Fecha().Format(time.RFC3339),
+				"notification_id": optionalID(m.NotificacionID()),
+			})
+		}
+		sendResponse(conn, GenericResponse{
+			Status:  "success",
+			Message: "Menciones",
+			Data: map[string]interface{}{
+				"mentions": mentionList,
+				"next":     pagina.Siguiente,
+				"has_next": pagina.HayPosteriores,
+			},
+		})
+	}
+}
+
+// Datos de una preferencia de silencio de menciones que se envían al cliente
+func muteData(s *model.SilencioMenciones) map[string]interface{} {
+	data := map[string]interface{}{
+		"channel_id": optionalID(s.CanalID()),
+		"level":      string(s.Nivel()),
+		"updated_at": s.Actualizado().Format(time.RFC3339),
+	}
+	if !s.Hasta().IsZero() {
+		data["until"] = s.Hasta().Format(time.RFC3339)
+	}
+	return data
+}
+
+// Manejador de los elementos fijados: pin-message fija un mensaje en su canal, pin-link
+// un enlace, unpin desfija cualquiera de los dos y list-pins devuelve los del canal
+func handlePins(conn net.Conn, msg Message) {
+	var request PinRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		fmt.Println("[DEBUG] Error al deserializar solicitud de fijados:", err)
+		sendResponse(conn, GenericResponse{"error", "Datos inválidos del elemento fijado", nil})
+		return
+	}
+	if pins == nil {
+		sendResponse(conn, GenericResponse{"error", "Los elementos fijados no están disponibles", nil})
+		return
+	}
+
+	userID, ok := sessionUser(conn)
+	if !ok {
+		return
+	}
+	if msg.Command == "pin-message" {
+		messageID, err := uuid.Parse(request.MessageID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", "ID de mensaje inválido", nil})
+			return
+		}
+		fijado, err := pins.PinMessage(userID, messageID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Mensaje fijado", pinData(fijado)})
+		return
+	}
+
+	channelID, err := uuid.Parse(request.ChannelID)
+	if err != nil {
+		sendResponse(conn, GenericResponse{"error", "ID de canal inválido", nil})
+		return
+	}
+	switch msg.Command {
+	case "pin-link":
+		fijado, err := pins.PinLink(userID, channelID, request.URL, request.Title)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Enlace fijado", pinData(fijado)})
+	case "unpin":
+		pinID, err := uuid.Parse(request.PinID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", "ID de elemento fijado inválido", nil})
+			return
+		}
+		fijado, err := pins.Unpin(userID, channelID, pinID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		sendResponse(conn, GenericResponse{"success", "Elemento desfijado", pinData(fijado)})
+	default:
+		fijados, err := pins.ListPins(userID, channelID)
+		if err != nil {
+			sendResponse(conn, GenericResponse{"error", err.Error(), nil})
+			return
+		}
+		pinList := []map[string]interface{}{}
+		for _, f := range fijados {
+			pinList = append(pinList, pinData(f))
+		}
+		sendResponse(conn, GenericResponse{"success", "Elementos fijados", pinList})
+	}
+}
+
+// Datos de un mensaje o enlace fijado que se envían al cliente, con quién y cuándo lo
+// fijó y, si se desfijó, quién y cuándo
+func pinData(f *model.FijadoCanal) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":         f.ID().String(),
+		"channel_id": f.CanalID().String(),
+		"pinned_by":  f.FijadoPor().String(),
+		"pinned_at":  f.FijadoEn().Format(time.RFC3339),
+	}
+	if f.EsEnlace() {
+		data["url"] = f.URL()
+		data["title"] = f.Titulo()
+	} else {
+		data["message_id"] = f.MensajeID().String()
+	}
+	if !f.Vigente() {
+		data["unpinned_by"] = f.RetiradoPor().String()
+		data["unpinned_at"] = f.RetiradoEn().Format(time.RFC3339)
+	}
+	return data
+}
+
+// Página de la cola de eventos pendientes que se envía al cliente; cada evento lleva
+// los mismos evento y datos que habría recibido conectado y su posición para ack-queue
+func queueData(pagina *model.Pagina[*model.EventoPendiente]) map[string]interface{} {
+	eventList := []map[string]interface{}{}
+	for _, e := range pagina.Elementos {
+		eventList = append(eventList, map[string]interface{}{
+			"position":  e.Posicion().String(),
+			"evento":    e.Evento(),
+			"datos":     json.RawMessage(e.Datos()),
+			"queued_at": e.Fecha(),
+		})
+	}
+	return map[string]interface{}{
+		"events":   eventList,
+		"next":     pagina.Siguiente,
+		"has_next": pagina.HayPosteriores,
+	}
+}
+
+// Totales por emoji de las reacciones de un mensaje que se envían al cliente
+func reactionData(conteos []model.ConteoReaccion) []map[string]interface{} {
+	reactionList := []map[string]interface{}{}
+	for _, c := range conteos {
+		reactionList = append(reactionList, map[string]interface{}{
+			"emoji": c.Emoji,
+			"count": c.Total,
+		})
+	}
+	return reactionList
+}
+
+// Perfil de un usuario tal como lo reciben login y register; nunca incluye la contraseña
+func userData(u *model.UsuarioServidor) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           u.ID().String(),
+		"nombre":       u.NombreUsuario(),
+		"email":        u.Email(),
+		"photo":        u.FotoURL(),
+		"ip":           u.IPRegistrada(),
+		"created_at":   u.FechaRegistro().Format(time.RFC3339),
+		"is_connected": u.IsConnected(),
+	}
+}
+
+// IP del cliente de la conexión, sin el puerto
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// Datos de un mensaje que se envían al cliente
+func messageData(m *model.MensajeServidor) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              m.ID().String(),
+		"sender_id":       m.RemitenteID().String(),
+		"recipient_id":    optionalID(m.DestinoUsuarioID()),
+		"channel_id":      optionalID(m.CanalID()),
+		"private_chat_id": optionalID(m.ChatPrivadoID()),
+		"parent_id":       optionalID(m.PadreID()),
+		"content":         m.Contenido(),
+		"timestamp":       m.Timestamp().Format(time.RFC3339),
+		"file_id":         optionalID(m.ArchivoID()),
+		"edited":          m.Editado(),
+	}
+}
+
+// Construye los filtros de búsqueda a partir de la solicitud
+func parseSearchFilters(request SearchMessagesRequest) (model.FiltroBusqueda, error) {
+	var filtros model.FiltroBusqueda
+	var err error
+	if request.SenderID != "" {
+		if filtros.RemitenteID, err = uuid.Parse(request.SenderID); err != nil {
+			return filtros, errors.New("ID de remitente inválido")
+		}
+	}
+	if request.ChannelID != "" {
+		if filtros.CanalID, err = uuid.Parse(request.ChannelID); err != nil {
+			return filtros, errors.New("ID de canal inválido")
+		}
+	}
+	if request.From != "" {
+		if filtros.Desde, err = time.Parse(time.RFC3339, request.From); err != nil {
+			return filtros, errors.New("fecha inicial inválida")
+		}
+	}
+	if request.To != "" {
+		if filtros.Hasta, err = time.Parse(time.RFC3339, request.To); err != nil {
+			return filtros, errors.New("fecha final inválida")
+		}
+	}
+	filtros.ConAdjunto = request.HasAttachment
+	return filtros, nil
+}
+
+// Devuelve el ID como texto, o vacío si no está asignado
+func optionalID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
 
 // Manejador de conexión
 func handleConnection(conn net.Conn) {
 	defer conn.Close()
+	defer endSession(conn)
 
 	fmt.Println("[DEBUG] Nueva conexión aceptada desde:", conn.RemoteAddr())
 
@@ -230,6 +1187,28 @@ func handleConnection(conn net.Conn) {
 			handleRegister(conn, msg)
 		case "list-users":
 			handleListUsers(conn)
+		case "subscribe-presence", "unsubscribe-presence":
+			handlePresenceSubscription(conn, msg)
+		case "search-messages":
+			handleSearchMessages(conn, msg)
+		case "get-thread":
+			handleGetThread(conn, msg)
+		case "add-reaction":
+			handleReaction(conn, msg, true)
+		case "remove-reaction":
+			handleReaction(conn, msg, false)
+		case "ack-delivery", "mark-read", "get-receipts", "unread-counts":
+			handleReceipt(conn, msg)
+		case "replay-queue", "ack-queue":
+			handleQueue(conn, msg)
+		case "activity-start", "activity-stop", "list-activity":
+			handleActivity(conn, msg)
+		case "schedule-message", "cancel-scheduled", "list-scheduled":
+			handleScheduled(conn, msg)
+		case "list-mentions", "mute-mentions", "list-mutes":
+			handleMentions(conn, msg)
+		case "pin-message", "pin-link", "unpin", "list-pins":
+			handlePins(conn, msg)
 		default:
 			fmt.Println("[DEBUG] Comando no reconocido:", msg.Command)
 			sendResponse(conn, GenericResponse{"error", "Comando no reconocido", nil})
@@ -237,8 +1216,139 @@ func handleConnection(conn net.Conn) {
 	}
 }
 
+// Clientes del nodo: los sockets a los que se envían los eventos, quién tiene uno abierto
+// y el registro de los sockets de las sesiones. pool.SocketPool la satisface.
+type clientesNodo interface {
+	service.ClientTransport
+	service.ClientSessions
+	Register(id uuid.UUID, conn net.Conn) error
+	Unregister(id uuid.UUID, conn net.Conn) bool
+}
+
+// Crea los repositorios y los servicios del nodo sobre el pool de base de datos, la red
+// P2P y los sockets de los clientes, asigna los servicios que usan los manejadores y
+// lanza sus tareas periódicas hasta que ctx termine
+func iniciarServicios(ctx context.Context, nodoID uuid.UUID, dbPool *pool.DBConnectionPool, peers service.PeerTransport, clientes clientesNodo) error {
+	reloj, err := model.NewRelojHLC(nodoID)
+	if err != nil {
+		return err
+	}
+
+	users := repository.NewUserRepository(dbPool, dao.NuevoUsuarioDAO())
+	channels := repository.NewChannelRepository(dbPool, dao.NuevoCanalDAO(), dao.NuevoInvitacionCanalDAO(), dao.NuevoCanalMiembroDAO())
+	messages := repository.NewMessageRepository(dbPool, dao.NuevoMensajeDAO())
+	routes := repository.NewRoutedMessageRepository(dbPool, dao.NuevoMensajeEnrutadoDAO())
+	queue := repository.NewOfflineQueueRepository(dbPool, dao.NuevoEventoPendienteDAO())
+	programados := repository.NewScheduledMessageRepository(dbPool, dao.NuevoMensajeProgramadoDAO())
+	menciones := repository.NewMentionRepository(dbPool, dao.NuevoMencionDAO(), dao.NuevoSilencioMencionDAO())
+	notificaciones := repository.NewNotificationRepository(dbPool, dao.NuevoNotificacionDAO())
+	logs := repository.NewLogRepository(dbPool)
+	nodos := repository.NewPeerRepository(dbPool, dao.NuevoNodoDAO())
+	uow := repository.NewUnitOfWork(dbPool)
+
+	// Todos los servicios avisan a los clientes a través de la cola, que guarda lo que
+	// no puede entregar a los desconectados
+	cola := service.NewOfflineQueueService(nodoID, peers, clientes, clientes, queue)
+	presence = service.NewPresenceService(nodoID, peers, cola, users, reloj, service.DefaultPresenceConfig())
+	cola.SetPresence(presence)
+	routing := service.NewRoutingService(nodoID, peers, cola, channels, messages, routes, presence, service.DefaultRoutingConfig())
+	resolver := service.NewConflictResolver(service.NewAuditService(logs))
+	notifications := service.NewNotificationService(cola, notificaciones)
+	edits := service.NewMessageEditService(peers, cola, channels, messages, uow, resolver, reloj)
+	antiEntropy := service.NewAntiEntropyService(peers, users, channels, messages, nodos, resolver, service.DefaultAntiEntropyConfig())
+
+	sockets = clientes
+	auth = service.NewAuthService(users, reloj)
+	profiles = service.NewUserService(users, reloj)
+	messageSearch = service.NewMessageSearchService(users, messages)
+	threads = service.NewThreadService(channels, messages, notifications)
+	reactions = service.NewReactionService(peers, cola, channels, messages, resolver, reloj)
+	receipts = service.NewReceiptService(nodoID, peers, cola, routing, channels, messages)
+	offlineQueue = cola
+	activities = service.NewActivityService(peers, cola, channels, messages, service.DefaultActivityConfig())
+	scheduled = service.NewScheduledMessageService(
+		nodoID, peers, cola, service.NewMessageService(users, channels, messages, routing),
+		edits, programados, service.DefaultScheduledMessageConfig(),
+	)
+	mentions = service.NewMentionService(peers, cola, profiles, channels, notifications, menciones)
+	pins = service.NewPinService(peers, cola, channels, messages, resolver, reloj)
+
+	go presence.Start(ctx)
+	go routing.Start(ctx)
+	go antiEntropy.Start(ctx)
+	go activities.Start(ctx)
+	go scheduled.Start(ctx)
+	return nil
+}
+
+// Abre la base de datos y la lleva a la última versión del esquema. Sin archivo de
+// configuración usa una base SQLite en memoria, que se pierde al cerrar el servidor.
+func abrirBaseDatos(ctx context.Context, configPath string) (*pool.DBConnectionPool, error) {
+	var dbPool *pool.DBConnectionPool
+	var err error
+	if configPath == "" {
+		dbPool, err = pool.NewSQLiteMemoryPool()
+	} else {
+		dbPool, err = pool.NewDBConnectionPool(configPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	migrator := migration.NewMigrator(dbPool).WithLogger(log)
+	if err := migrator.LoadEmbeddedMigrations(); err != nil {
+		dbPool.Close()
+		return nil, err
+	}
+	if err := migrator.Run(ctx); err != nil {
+		dbPool.Close()
+		return nil, err
+	}
+	return dbPool, nil
+}
+
 // Función principal del servidor
 func main() {
+	dbConfig := flag.String("db-config", "", "Configuración de la base de datos (vacío = SQLite en memoria)")
+	peerConfig := flag.String("peer-config", "../../GO-P2P-Servidor/03-InfraestructureLayer/pool/peer_config.yaml", "Configuración del pool de conexiones P2P")
+	socketConfig := flag.String("socket-config", "../../GO-P2P-Servidor/03-InfraestructureLayer/pool/socket_config.yaml", "Configuración del pool de sockets de clientes")
+	nodeID := flag.String("node-id", "", "ID de este nodo en la red P2P (vacío = uno nuevo)")
+	flag.Parse()
+
+	nodoID := uuid.New()
+	if *nodeID != "" {
+		var err error
+		if nodoID, err = uuid.Parse(*nodeID); err != nil {
+			panic(fmt.Errorf("ID de nodo inválido: %w", err))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbPool, err := abrirBaseDatos(ctx, *dbConfig)
+	if err != nil {
+		panic(err)
+	}
+	defer dbPool.Close()
+	peers, err := pool.NewPeerConnectionPool(*peerConfig)
+	if err != nil {
+		panic(err)
+	}
+	defer peers.CloseAll()
+	socketPool, err := pool.NewSocketPool(*socketConfig)
+	if err != nil {
+		panic(err)
+	}
+	defer socketPool.Close()
+
+	if err := iniciarServicios(ctx, nodoID, dbPool, peers, socketPool); err != nil {
+		panic(err)
+	}
+	fmt.Println("[INFO] Servicios del nodo iniciados:", nodoID)
+
 	listener, err := net.Listen("tcp", ":9000")
 	if err != nil {
 		panic(err)
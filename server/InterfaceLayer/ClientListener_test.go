@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"dao"
+	"encoding/json"
+	"errors"
+	"net"
+	"pool"
+	"repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"model"
+)
+
+// sinPeers es una red P2P sin otros nodos
+type sinPeers struct{}
+
+func (sinPeers) GetAllPeerIDs() []uuid.UUID { return nil }
+
+func (sinPeers) SendTo(uuid.UUID, uint16, []byte) error { return errors.New("sin peers") }
+
+func (sinPeers) PeerAddress(uuid.UUID) (string, bool) { return "", false }
+
+func (sinPeers) SetFrameHandler(uint16, func(uuid.UUID, []byte)) {}
+
+// rutaConfigSockets es la configuración del pool de sockets que usa main por defecto
+const rutaConfigSockets = "../../GO-P2P-Servidor/03-InfraestructureLayer/pool/socket_config.yaml"
+
+// nuevoNodoPrueba abre una base SQLite en memoria con el esquema completo e inicia sobre
+// ella y un pool de sockets real los servicios del nodo, que se retiran al terminar la prueba
+func nuevoNodoPrueba(t *testing.T) *pool.DBConnectionPool {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	dbPool, err := abrirBaseDatos(ctx, "")
+	if err != nil {
+		t.Fatalf("abrirBaseDatos: %v", err)
+	}
+	socketPool, err := pool.NewSocketPool(rutaConfigSockets)
+	if err != nil {
+		t.Fatalf("NewSocketPool: %v", err)
+	}
+	if err := iniciarServicios(ctx, uuid.New(), dbPool, sinPeers{}, socketPool); err != nil {
+		t.Fatalf("iniciarServicios: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		messageSearch, threads, reactions, receipts, offlineQueue = nil, nil, nil, nil, nil
+		activities, scheduled, mentions, pins, auth, profiles = nil, nil, nil, nil, nil, nil
+		presence, sockets = nil, nil
+		socketPool.Close()
+		dbPool.Close()
+	})
+	return dbPool
+}
+
+// clientePrueba es un cliente conectado al listener. Un lector en segundo plano separa
+// las respuestas a sus comandos de los eventos que el nodo le envía en vivo.
+type clientePrueba struct {
+	conn       net.Conn
+	respuestas chan []byte
+	eventos    chan map[string]interface{}
+}
+
+// conectarCliente abre una conexión atendida por handleConnection; con un usuario
+// distinto de uuid.Nil la conexión empieza con su sesión iniciada
+func conectarCliente(t *testing.T, userID uuid.UUID) *clientePrueba {
+	t.Helper()
+	cliente, servidor := net.Pipe()
+	if userID != uuid.Nil {
+		if err := startSession(servidor, userID); err != nil {
+			t.Fatalf("startSession: %v", err)
+		}
+	}
+	atendida := make(chan struct{})
+	go func() {
+		handleConnection(servidor)
+		close(atendida)
+	}()
+
+	c := &clientePrueba{
+		conn:       cliente,
+		respuestas: make(chan []byte, 64),
+		eventos:    make(chan map[string]interface{}, 64),
+	}
+	go func() {
+		defer close(c.respuestas)
+		defer close(c.eventos)
+		lector := bufio.NewReader(cliente)
+		for {
+			linea, err := lector.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var evento map[string]interface{}
+			if json.Unmarshal(linea, &evento) == nil && evento["evento"] != nil {
+				c.eventos <- evento
+				continue
+			}
+			c.respuestas <- linea
+		}
+	}()
+	// La sesión se cierra antes de que el nodo retire los servicios
+	t.Cleanup(func() {
+		cliente.Close()
+		<-atendida
+	})
+	return c
+}
+
+// enviarComando envía un comando y decodifica la respuesta
+func enviarComando(t *testing.T, c *clientePrueba, command string, data interface{}) GenericResponse {
+	t.Helper()
+	datos, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	mensaje, _ := json.Marshal(Message{Command: command, Data: datos})
+	c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.conn.Write(mensaje); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var linea []byte
+	select {
+	case linea = <-c.respuestas:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sin respuesta a %s", command)
+	}
+	var respuesta GenericResponse
+	if err := json.Unmarshal(linea, &respuesta); err != nil {
+		t.Fatalf("respuesta inválida %s: %v", linea, err)
+	}
+	return respuesta
+}
+
+// esperarEvento devuelve los datos del siguiente evento del tipo indicado que recibe el
+// cliente en vivo; los de otros tipos se descartan
+func esperarEvento(t *testing.T, c *clientePrueba, tipo string) map[string]interface{} {
+	t.Helper()
+	limite := time.After(5 * time.Second)
+	for {
+		select {
+		case evento := <-c.eventos:
+			if evento["evento"] == tipo {
+				datos, _ := evento["datos"].(map[string]interface{})
+				return datos
+			}
+		case <-limite:
+			t.Fatalf("no llegó ningún evento %s", tipo)
+			return nil
+		}
+	}
+}
+
+// crearUsuario guarda un usuario en la base del nodo
+func crearUsuario(t *testing.T, dbPool *pool.DBConnectionPool, nombre string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	u, err := model.NewUsuarioServidor(id, nombre, nombre+"@example.com", "hash", "", "127.0.0.1", time.Now())
+	if err != nil {
+		t.Fatalf("NewUsuarioServidor: %v", err)
+	}
+	if err := repository.NewUserRepository(dbPool, dao.NuevoUsuarioDAO()).Save(context.Background(), u); err != nil {
+		t.Fatalf("Save usuario: %v", err)
+	}
+	return id
+}
+
+func TestSearchMessages(t *testing.T) {
+	dbPool := nuevoNodoPrueba(t)
+	ana, bob, eva := crearUsuario(t, dbPool, "ana"), crearUsuario(t, dbPool, "bob"), crearUsuario(t, dbPool, "eva")
+
+	m, err := model.NewMensajeDirecto(uuid.New(), ana, bob, "hola bob, ¿quedamos mañana?", time.Now().UTC(), uuid.Nil)
+	if err != nil {
+		t.Fatalf("NewMensajeDirecto: %v", err)
+	}
+	if err := repository.NewMessageRepository(dbPool, dao.NuevoMensajeDAO()).Save(context.Background(), m); err != nil {
+		t.Fatalf("Save mensaje: %v", err)
+	}
+
+	conn := conectarCliente(t, bob)
+	respuesta := enviarComando(t, conn, "search-messages", SearchMessagesRequest{Query: "quedamos"})
+	if respuesta.Status != "success" {
+		t.Fatalf("búsqueda fallida: %s", respuesta.Message)
+	}
+	datos := respuesta.Data.(map[string]interface{})
+	mensajes := datos["messages"].([]interface{})
+	if len(mensajes) != 1 || mensajes[0].(map[string]interface{})["id"] != m.ID().String() {
+		t.Fatalf("resultados inesperados: %v", mensajes)
+	}
+	if datos["has_next"] != false {
+		t.Errorf("una sola coincidencia no debe tener página siguiente: %v", datos)
+	}
+
+	respuesta = enviarComando(t, conn, "search-messages", SearchMessagesRequest{Query: "quedamos", From: "ayer"})
+	if respuesta.Status != "error" || respuesta.Message != "fecha inicial inválida" {
+		t.Errorf("fecha inválida: %v", respuesta)
+	}
+
+	// Cada conexión busca como su propio usuario: quien no participa en la conversación
+	// no la encuentra, y sin sesión no se busca
+	otra := conectarCliente(t, eva)
+	respuesta = enviarComando(t, otra, "search-messages", SearchMessagesRequest{Query: "quedamos"})
+	if respuesta.Status != "success" || len(respuesta.Data.(map[string]interface{})["messages"].([]interface{})) != 0 {
+		t.Errorf("un usuario ajeno no debe ver el mensaje: %v", respuesta)
+	}
+	anonima := conectarCliente(t, uuid.Nil)
+	respuesta = enviarComando(t, anonima, "search-messages", SearchMessagesRequest{Query: "quedamos"})
+	if respuesta.Status != "error" || respuesta.Message != "Debe iniciar sesión" {
+		t.Errorf("búsqueda sin sesión: %v", respuesta)
+	}
+}
+
+func TestRegisterYLogin(t *testing.T) {
+	nuevoNodoPrueba(t)
+	conn := conectarCliente(t, uuid.Nil)
+
+	registro := RegisterRequest{Email: "ana@example.com", Password: "secreta", Nombre: "ana"}
+	respuesta := enviarComando(t, conn, "register", registro)
+	if respuesta.Status != "success" {
+		t.Fatalf("registro fallido: %s", respuesta.Message)
+	}
+	ana, err := uuid.Parse(respuesta.Data.(map[string]interface{})["id"].(string))
+	if err != nil {
+		t.Fatalf("ID de usuario inválido: %v", err)
+	}
+	if _, ok := respuesta.Data.(map[string]interface{})["password"]; ok {
+		t.Error("el registro no debe devolver la contraseña")
+	}
+	if respuesta = enviarComando(t, conn, "register", registro); respuesta.Message != "El email ya está registrado" {
+		t.Errorf("email duplicado: %v", respuesta)
+	}
+
+	// Lo que le llega a ana sin conexión se le entrega al iniciar sesión
+	if err := offlineQueue.Broadcast([]uuid.UUID{ana}, []byte(`{"evento":"NOTIFICACION","datos":{"texto":"hola"}}`)); err != nil {
+		t.Fatalf("Broadcast: %v", err)
+	}
+
+	respuesta = enviarComando(t, conn, "login", LoginRequest{Email: "ana@example.com", Password: "otra"})
+	if respuesta.Status != "error" || respuesta.Message != "Email o contraseña incorrectos" {
+		t.Errorf("contraseña errónea: %v", respuesta)
+	}
+	if respuesta = enviarComando(t, conn, "search-messages", SearchMessagesRequest{Query: "hola"}); respuesta.Message != "Debe iniciar sesión" {
+		t.Errorf("un login fallido no debe abrir sesión: %v", respuesta)
+	}
+
+	respuesta = enviarComando(t, conn, "login", LoginRequest{Email: "ana@example.com", Password: "secreta"})
+	if respuesta.Status != "success" {
+		t.Fatalf("login fallido: %s", respuesta.Message)
+	}
+	datos := respuesta.Data.(map[string]interface{})
+	if datos["id"] != ana.String() {
+		t.Errorf("login de otro usuario: %v", datos)
+	}
+	eventos := datos["pending"].(map[string]interface{})["events"].([]interface{})
+	if len(eventos) != 1 || eventos[0].(map[string]interface{})["evento"] != "NOTIFICACION" {
+		t.Errorf("cola pendiente inesperada: %v", eventos)
+	}
+	if respuesta = enviarComando(t, conn, "search-messages", SearchMessagesRequest{Query: "hola"}); respuesta.Status != "success" {
+		t.Errorf("búsqueda tras el login: %v", respuesta)
+	}
+
+	// La lista sale de la presencia: el administrador de las migraciones no está conectado
+	respuesta = enviarComando(t, conn, "list-users", nil)
+	if lista, _ := respuesta.Data.([]interface{}); respuesta.Status != "success" || len(lista) != 1 || lista[0].(map[string]interface{})["id"] != ana.String() {
+		t.Errorf("lista de usuarios inesperada: %v", respuesta)
+	}
+}
+
+func TestPresenciaDeLasSesiones(t *testing.T) {
+	dbPool := nuevoNodoPrueba(t)
+	ana, bob := crearUsuario(t, dbPool, "ana"), crearUsuario(t, dbPool, "bob")
+
+	conexionBob := conectarCliente(t, bob)
+	if respuesta := enviarComando(t, conexionBob, "subscribe-presence", nil); respuesta.Status != "success" {
+		t.Fatalf("suscripción fallida: %v", respuesta)
+	}
+
+	// La sesión de ana registra su socket y su presencia, y bob lo ve en vivo
+	conexionAna := conectarCliente(t, ana)
+	if !sockets.Connected(ana) {
+		t.Error("la sesión de ana debe registrar su socket")
+	}
+	if cambio := esperarEvento(t, conexionBob, "PRESENCIA"); cambio["usuarioId"] != ana.String() || cambio["conectado"] != true {
+		t.Errorf("cambio de presencia inesperado: %v", cambio)
+	}
+	respuesta := enviarComando(t, conexionBob, "list-users", nil)
+	if lista, _ := respuesta.Data.([]interface{}); respuesta.Status != "success" || len(lista) != 2 {
+		t.Errorf("deben figurar ana y bob: %v", respuesta)
+	}
+
+	// Al cerrarse la conexión se liberan el socket y la sesión de presencia
+	conexionAna.conn.Close()
+	if cambio := esperarEvento(t, conexionBob, "PRESENCIA"); cambio["usuarioId"] != ana.String() || cambio["conectado"] != false {
+		t.Errorf("cambio de presencia inesperado: %v", cambio)
+	}
+	if sockets.Connected(ana) {
+		t.Error("el socket de ana debe liberarse al cerrar la conexión")
+	}
+	respuesta = enviarComando(t, conexionBob, "list-users", nil)
+	if lista, _ := respuesta.Data.([]interface{}); respuesta.Status != "success" || len(lista) != 1 || lista[0].(map[string]interface{})["id"] != bob.String() {
+		t.Errorf("solo debe figurar bob: %v", respuesta)
+	}
+
+	if respuesta = enviarComando(t, conexionBob, "unsubscribe-presence", nil); respuesta.Status != "success" {
+		t.Errorf("cancelar la suscripción: %v", respuesta)
+	}
+}
+
+func TestActividadEnVivo(t *testing.T) {
+	dbPool := nuevoNodoPrueba(t)
+	ana, bob := crearUsuario(t, dbPool, "ana"), crearUsuario(t, dbPool, "bob")
+	conexionAna, conexionBob := conectarCliente(t, ana), conectarCliente(t, bob)
+
+	// La actividad es efímera: solo le llega a bob porque su sesión tiene socket
+	solicitud := ActivityRequest{ConversationID: bob.String(), Type: string(model.ActividadEscribiendo)}
+	if respuesta := enviarComando(t, conexionAna, "activity-start", solicitud); respuesta.Status != "success" {
+		t.Fatalf("activity-start: %v", respuesta)
+	}
+	aviso := esperarEvento(t, conexionBob, "ACTIVIDAD")
+	if aviso["usuarioId"] != ana.String() || aviso["conversacionId"] != ana.String() || aviso["tipo"] != "ESCRIBIENDO" || aviso["activa"] != true {
+		t.Errorf("aviso de actividad inesperado: %v", aviso)
+	}
+
+	if respuesta := enviarComando(t, conexionAna, "activity-stop", solicitud); respuesta.Status != "success" {
+		t.Fatalf("activity-stop: %v", respuesta)
+	}
+	if aviso = esperarEvento(t, conexionBob, "ACTIVIDAD"); aviso["usuarioId"] != ana.String() || aviso["activa"] != false {
+		t.Errorf("fin de actividad inesperado: %v", aviso)
+	}
+}
+
+func TestReaccionEnVivo(t *testing.T) {
+	dbPool := nuevoNodoPrueba(t)
+	ana, bob := crearUsuario(t, dbPool, "ana"), crearUsuario(t, dbPool, "bob")
+	m, err := model.NewMensajeDirecto(uuid.New(), ana, bob, "¿comemos juntos?", time.Now().UTC(), uuid.Nil)
+	if err != nil {
+		t.Fatalf("NewMensajeDirecto: %v", err)
+	}
+	if err := repository.NewMessageRepository(dbPool, dao.NuevoMensajeDAO()).Save(context.Background(), m); err != nil {
+		t.Fatalf("Save mensaje: %v", err)
+	}
+	conexionAna, conexionBob := conectarCliente(t, ana), conectarCliente(t, bob)
+
+	if respuesta := enviarComando(t, conexionBob, "add-reaction", ReactionRequest{MessageID: m.ID().String(), Emoji: "👍"}); respuesta.Status != "success" {
+		t.Fatalf("add-reaction: %v", respuesta)
+	}
+	cambio := esperarEvento(t, conexionAna, "REACCION")
+	conteos, _ := cambio["conteos"].([]interface{})
+	if len(conteos) != 1 || conteos[0].(map[string]interface{})["emoji"] != "👍" || conteos[0].(map[string]interface{})["total"] != float64(1) {
+		t.Errorf("totales inesperados: %v", cambio)
+	}
+
+	// Entregado en vivo, así que no queda nada en la cola de ana
+	respuesta := enviarComando(t, conexionAna, "replay-queue", QueueRequest{})
+	if respuesta.Status != "success" || len(respuesta.Data.(map[string]interface{})["events"].([]interface{})) != 0 {
+		t.Errorf("la reacción no debe encolarse para un usuario conectado: %v", respuesta)
+	}
+}
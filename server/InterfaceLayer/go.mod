@@ -2,4 +2,34 @@ module interfacesLayer
 
 go 1.24.1
 
-require github.com/google/uuid v1.6.0 // indirect
+require (
+	dao v0.0.0
+	github.com/google/uuid v1.6.0
+	github.com/sirupsen/logrus v1.9.3
+	migration v0.0.0
+	model v0.0.0
+	pool v0.0.0
+	repository v0.0.0
+	service v0.0.0
+)
+
+require (
+	github.com/go-sql-driver/mysql v1.7.1 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	observer v0.0.0 // indirect
+	repository.interfaces v0.0.0-00010101000000-000000000000 // indirect
+)
+
+replace (
+	dao => ../../GO-P2P-Servidor/03-InfraestructureLayer/dao
+	migration => ../../GO-P2P-Servidor/03-InfraestructureLayer/migration
+	model => ../../GO-P2P-Servidor/04-DomainLayer/model
+	observer => ../../GO-P2P-Servidor/04-DomainLayer/observer
+	pool => ../../GO-P2P-Servidor/03-InfraestructureLayer/pool
+	repository => ../../GO-P2P-Servidor/03-InfraestructureLayer/repository
+	repository.interfaces => ../../GO-P2P-Servidor/04-DomainLayer/repository.interfaces
+	service => ../../GO-P2P-Servidor/04-DomainLayer/service
+)
@@ -1,3 +1,6 @@
+//go:build ignore
+
+// Cliente TCP de prueba para el listener; se ejecuta aparte con go run main.go
 package main
 
 import (
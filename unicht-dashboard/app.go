@@ -4,13 +4,41 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 	"time"
+
+	"dao"
+	"model"
+	"pool"
+	"repository"
+	interfaces "repository.interfaces"
+	"service"
 )
 
+// dbConfigEnv es la variable de entorno con el db_config.yaml del nodo que muestra el dashboard
+const dbConfigEnv = "UNICHT_DB_CONFIG"
+
+// auditPageSize es el número de entradas del log de auditoría que se cargan cada vez
+const auditPageSize = 100
+
+// AuditLogPage es una página del log de auditoría del nodo, en orden cronológico. Older
+// es el cursor con el que se pide la página anterior, si HasOlder.
+type AuditLogPage struct {
+	Lines    []string `json:"lines"`
+	Older    string   `json:"older"`
+	HasOlder bool     `json:"has_older"`
+}
+
 // App struct
 type App struct {
-	ctx context.Context
-	logs []string
+	ctx    context.Context
+	logs   []string
+	dbPool *pool.DBConnectionPool
+	// Sin base de datos configurada son nil. Leen de las réplicas si las hay: el
+	// dashboard tolera el retraso de la replicación y así no compite con el chat.
+	peers    interfaces.IPeerRepository
+	channels interfaces.IChannelRepository
+	audit    service.AuditService
 }
 
 // NewApp creates a new App application struct
@@ -22,6 +50,30 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	configPath := os.Getenv(dbConfigEnv)
+	if configPath == "" {
+		a.AddLog("Sin " + dbConfigEnv + ": no se mostrará el estado de sincronización de los peers")
+		return
+	}
+	dbPool, err := pool.NewDBConnectionPool(configPath)
+	if err != nil {
+		a.AddLog("Error abriendo la base de datos del nodo: " + err.Error())
+		return
+	}
+	a.dbPool = dbPool
+	a.peers = repository.NewPeerRepository(dbPool, dao.NuevoNodoDAO()).WithReplicaReads()
+	a.channels = repository.NewChannelRepository(
+		dbPool, dao.NuevoCanalDAO(), dao.NuevoInvitacionCanalDAO(), dao.NuevoCanalMiembroDAO(),
+	).WithReplicaReads()
+	a.audit = service.NewAuditService(repository.NewLogRepository(dbPool).WithReplicaReads())
+}
+
+// shutdown cierra la base de datos del nodo al salir
+func (a *App) shutdown(ctx context.Context) {
+	if a.dbPool != nil {
+		a.dbPool.Close()
+	}
 }
 
 func (a *App) Greet(name string) string {
@@ -34,9 +86,17 @@ func (a *App) GetActiveUsers() int {
 	return 42
 }
 
+// GetGroupCount devuelve el número de canales del nodo
 func (a *App) GetGroupCount() int {
-	//TODO: Add logic
-	return 7
+	if a.channels == nil {
+		return 0
+	}
+	canales, err := a.channels.FindAll(a.ctx)
+	if err != nil {
+		a.AddLog("Error leyendo los canales: " + err.Error())
+		return 0
+	}
+	return len(canales)
 }
 
 func (a *App) GetServerIP() string {
@@ -62,6 +122,53 @@ func (a *App) GetOtherServers() []string {
 	//Example
 	return []string{"192.168.1.2", "192.168.1.3"}
 }
+
+// GetPeerSyncStatus devuelve, por dirección de peer, la fecha de la última sincronización
+// anti-entropía exitosa, que el nodo guarda en peer.ultima_sync_at. Los peers que nunca
+// se han sincronizado no aparecen.
+func (a *App) GetPeerSyncStatus() map[string]string {
+	estado := map[string]string{}
+	if a.peers == nil {
+		return estado
+	}
+	peers, err := a.peers.ListAll(a.ctx)
+	if err != nil {
+		a.AddLog("Error leyendo los peers: " + err.Error())
+		return estado
+	}
+	for _, p := range peers {
+		if !p.UltimaSync().IsZero() {
+			estado[p.Direccion()] = p.UltimaSync().Local().Format("2006-01-02 15:04:05")
+		}
+	}
+	return estado
+}
+
+// GetAuditLogs devuelve una página del log de auditoría del nodo: sin cursor la más
+// reciente y con el cursor Older de una página la anterior a ella
+func (a *App) GetAuditLogs(cursor string) AuditLogPage {
+	pagina := AuditLogPage{Lines: []string{}}
+	if a.audit == nil {
+		return pagina
+	}
+	consulta, err := model.NewConsultaPagina(cursor, model.PaginaAnteriores, auditPageSize)
+	if err != nil {
+		a.AddLog("Cursor del log de auditoría inválido: " + err.Error())
+		return pagina
+	}
+	entradas, err := a.audit.PageLogs(service.LogFilter{}, consulta)
+	if err != nil {
+		a.AddLog("Error leyendo el log de auditoría: " + err.Error())
+		return pagina
+	}
+	for _, e := range entradas.Elementos {
+		timestamp := e.Timestamp().Local().Format("2006-01-02 15:04:05")
+		pagina.Lines = append(pagina.Lines, "["+timestamp+"] "+string(e.TipoEvento())+" "+e.Detalle())
+	}
+	pagina.Older, pagina.HasOlder = entradas.Anterior, entradas.HayAnteriores
+	return pagina
+}
+
 func (a *App) GenerateTestLogs() {
 	testLogs := []string{
 		"Usuario admin ha iniciado sesión",
@@ -1,5 +1,6 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {main} from '../models';
 
 export function AddLog(arg1:string):Promise<void>;
 
@@ -7,12 +8,16 @@ export function GenerateTestLogs():Promise<void>;
 
 export function GetActiveUsers():Promise<number>;
 
+export function GetAuditLogs(arg1:string):Promise<main.AuditLogPage>;
+
 export function GetGroupCount():Promise<number>;
 
 export function GetLogs():Promise<string>;
 
 export function GetOtherServers():Promise<Array<string>>;
 
+export function GetPeerSyncStatus():Promise<{[key: string]: string}>;
+
 export function GetServerIP():Promise<string>;
 
 export function Greet(arg1:string):Promise<string>;
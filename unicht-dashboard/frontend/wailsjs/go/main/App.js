@@ -14,6 +14,10 @@ export function GetActiveUsers() {
   return window['go']['main']['App']['GetActiveUsers']();
 }
 
+export function GetAuditLogs(arg1) {
+  return window['go']['main']['App']['GetAuditLogs'](arg1);
+}
+
 export function GetGroupCount() {
   return window['go']['main']['App']['GetGroupCount']();
 }
@@ -26,6 +30,10 @@ export function GetOtherServers() {
   return window['go']['main']['App']['GetOtherServers']();
 }
 
+export function GetPeerSyncStatus() {
+  return window['go']['main']['App']['GetPeerSyncStatus']();
+}
+
 export function GetServerIP() {
   return window['go']['main']['App']['GetServerIP']();
 }
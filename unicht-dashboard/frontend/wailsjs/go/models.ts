@@ -0,0 +1,21 @@
+export namespace main {
+	
+	export class AuditLogPage {
+	    lines: string[];
+	    older: string;
+	    has_older: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditLogPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lines = source["lines"];
+	        this.older = source["older"];
+	        this.has_older = source["has_older"];
+	    }
+	}
+
+}
+
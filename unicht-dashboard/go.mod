@@ -2,11 +2,20 @@ module unicht-dashboard
 
 go 1.23
 
-require github.com/wailsapp/wails/v2 v2.10.1
+require (
+	dao v0.0.0-00010101000000-000000000000
+	github.com/wailsapp/wails/v2 v2.10.1
+	model v0.0.0
+	pool v0.0.0-00010101000000-000000000000
+	repository v0.0.0-00010101000000-000000000000
+	repository.interfaces v0.0.0-00010101000000-000000000000
+	service v0.0.0-00010101000000-000000000000
+)
 
 require (
 	github.com/bep/debounce v1.2.1 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
+	github.com/go-sql-driver/mysql v1.7.1 // indirect
 	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/jchv/go-winloader v0.0.0-20210711035445-715c2860da7e // indirect
@@ -18,10 +27,12 @@ require (
 	github.com/leaanthony/u v1.1.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
 	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/samber/lo v1.49.1 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
 	github.com/tkrajina/go-reflector v0.5.8 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
@@ -31,6 +42,18 @@ require (
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	observer v0.0.0 // indirect
+)
+
+replace (
+	dao => ../GO-P2P-Servidor/03-InfraestructureLayer/dao
+	model => ../GO-P2P-Servidor/04-DomainLayer/model
+	observer => ../GO-P2P-Servidor/04-DomainLayer/observer
+	pool => ../GO-P2P-Servidor/03-InfraestructureLayer/pool
+	repository => ../GO-P2P-Servidor/03-InfraestructureLayer/repository
+	repository.interfaces => ../GO-P2P-Servidor/04-DomainLayer/repository.interfaces
+	service => ../GO-P2P-Servidor/04-DomainLayer/service
 )
 
 // replace github.com/wailsapp/wails/v2 v2.10.1 => C:\Users\juand\go\pkg\mod